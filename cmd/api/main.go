@@ -3,10 +3,15 @@ package main
 import (
 	"log"
 	"os"
+	"strings"
 
 	"github.com/donbarrigon/new-project/config"
 	"github.com/donbarrigon/new-project/internal/app"
 	"github.com/donbarrigon/new-project/internal/orm"
+	"github.com/donbarrigon/new-project/internal/request"
+	"github.com/donbarrigon/new-project/internal/upload"
+	"github.com/donbarrigon/new-project/lib/crypt"
+	"github.com/donbarrigon/new-project/lib/validation"
 )
 
 func main() {
@@ -14,9 +19,36 @@ func main() {
 	// Carga las variables del archivo .env
 	config.Load()
 
+	// Proxies de confianza para resolver la ip real del cliente (X-Forwarded-For)
+	if err := request.SetTrustedProxies(strings.Split(os.Getenv("TRUSTED_PROXIES"), ",")...); err != nil {
+		log.Fatal(err)
+	}
+
+	// Llave para cifrar los campos encrypted, las anteriores solo se usan para descifrar mientras se rotan
+	if key := os.Getenv("APP_KEY"); key != "" {
+		if err := crypt.Configure(key, strings.Split(os.Getenv("APP_PREVIOUS_KEYS"), ",")...); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Antivirus para los archivos subidos (clamd), sin configurar no se revisan
+	if addr := os.Getenv("CLAMAV_ADDRESS"); addr != "" {
+		upload.DefaultScanner = upload.NewClamAV(addr)
+	}
+
+	// Proveedor de captcha (recaptcha, hcaptcha, turnstile, pass o fail para pruebas)
+	if err := validation.ConfigureCaptcha(os.Getenv("CAPTCHA_DRIVER"), os.Getenv("CAPTCHA_SECRET")); err != nil {
+		log.Fatal(err)
+	}
+
 	// Conecta con la base de datos
 	orm.Connect()
 
+	// Prepara las reglas de validación de los requests antes de recibir trafico
+	if err := request.Warmup(&request.User{}); err != nil {
+		log.Fatal(err)
+	}
+
 	// Configura el logger
 	log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
 
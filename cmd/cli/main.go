@@ -0,0 +1,257 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/donbarrigon/new-project/internal/debug"
+	"github.com/donbarrigon/new-project/internal/maintenance"
+	"github.com/donbarrigon/new-project/internal/mock"
+	"github.com/donbarrigon/new-project/internal/request"
+	_ "github.com/donbarrigon/new-project/internal/upload" // registra los codigos de los archivos
+	"github.com/donbarrigon/new-project/lib/crypt"
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+const usage = `uso: go run ./cmd/cli <comando> [opciones]
+
+comandos:
+  down   pone la aplicacion en modo mantenimiento
+  up     saca la aplicacion del modo mantenimiento
+  key    genera una llave nueva para APP_KEY
+  rules  exporta las reglas de validacion para el frontend (json o typescript)
+  replay reproduce una solicitud capturada con middleware.Record por su FormRequest
+  codes  exporta el catalogo de codigos de error en json
+  mock   servidor de pruebas que valida con los FormRequest y responde datos generados
+`
+
+// forms FormRequest que se exportan con el comando rules (el nombre es el de la constante en typescript),
+// que puede reproducir el comando replay y que usa el servidor de pruebas del comando mock
+var forms = map[string]func() request.FormRequest{
+	"user": func() request.FormRequest { return &request.User{} },
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Print(usage)
+		os.Exit(1)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "down":
+		err = down(os.Args[2:])
+	case "up":
+		err = up(os.Args[2:])
+	case "key":
+		fmt.Println(crypt.GenerateKey())
+	case "rules":
+		err = rules(os.Args[2:])
+	case "replay":
+		err = replay(os.Args[2:])
+	case "codes":
+		err = codes(os.Args[2:])
+	case "mock":
+		err = mockServer(os.Args[2:])
+	default:
+		fmt.Print(usage)
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+// down activa el modo mantenimiento
+func down(args []string) error {
+	fs := flag.NewFlagSet("down", flag.ExitOnError)
+	file := fs.String("file", maintenance.DefaultPath, "archivo del modo mantenimiento")
+	message := fs.String("message", "", "mensaje para los clientes")
+	retry := fs.Int("retry", 0, "segundos de la cabecera Retry-After")
+	secret := fs.String("secret", "", "token para saltarse el mantenimiento con la cabecera Maintenance-Bypass")
+	allow := fs.String("allow", "", "rutas que siguen funcionando separadas por coma (/health,/status)")
+	fs.Parse(args)
+
+	state := maintenance.State{
+		Message: *message,
+		Retry:   *retry,
+		Secret:  *secret,
+	}
+	for _, path := range strings.Split(*allow, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			state.Allow = append(state.Allow, path)
+		}
+	}
+
+	if err := maintenance.NewFileStore(*file).Down(state); err != nil {
+		return err
+	}
+	fmt.Println("La aplicación está en modo mantenimiento.")
+	return nil
+}
+
+// up desactiva el modo mantenimiento
+func up(args []string) error {
+	fs := flag.NewFlagSet("up", flag.ExitOnError)
+	file := fs.String("file", maintenance.DefaultPath, "archivo del modo mantenimiento")
+	fs.Parse(args)
+
+	if err := maintenance.NewFileStore(*file).Up(); err != nil {
+		return err
+	}
+	fmt.Println("La aplicación está arriba.")
+	return nil
+}
+
+// rules exporta las reglas de los FormRequest de forms
+func rules(args []string) error {
+	fs := flag.NewFlagSet("rules", flag.ExitOnError)
+	format := fs.String("format", "ts", "formato del archivo: ts o json")
+	out := fs.String("out", "", "archivo de salida, vacio escribe en la consola")
+	fs.Parse(args)
+
+	values := make(map[string]any, len(forms))
+	for name, form := range forms {
+		values[name] = form()
+	}
+
+	var data []byte
+	var err error
+	switch *format {
+	case "ts":
+		data, err = validation.ExportTypeScript(values)
+	case "json":
+		data, err = validation.ExportJSON(values)
+	default:
+		return fmt.Errorf("el formato '%s' no existe, use ts o json", *format)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		fmt.Println(string(data))
+		return nil
+	}
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		return err
+	}
+	fmt.Println("Reglas exportadas en", *out)
+	return nil
+}
+
+// codes exporta los codigos de error con su descripcion para que los clientes programen contra ellos
+func codes(args []string) error {
+	fs := flag.NewFlagSet("codes", flag.ExitOnError)
+	out := fs.String("out", "", "archivo de salida, vacio escribe en la consola")
+	fs.Parse(args)
+
+	data, err := json.MarshalIndent(validation.Catalog(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		fmt.Println(string(data))
+		return nil
+	}
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		return err
+	}
+	fmt.Println("Códigos exportados en", *out)
+	return nil
+}
+
+// replay reproduce la captura por el FormRequest que la valido cuando se guardo
+func replay(args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	form := fs.String("form", "", "nombre del FormRequest en forms, sin el se usa el de la captura")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		return fmt.Errorf("falta el archivo de la captura")
+	}
+
+	entry, err := debug.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	newForm, err := replayForm(entry, *form)
+	if err != nil {
+		return err
+	}
+	req, err := entry.Request()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s %s (respondió %d)\n", entry.Method, entry.Path, entry.Status)
+	if len(entry.Validations) > 0 {
+		recorded, _ := json.MarshalIndent(entry.Validations[0].Errors, "", "  ")
+		fmt.Println("errores capturados:", string(recorded))
+	}
+	err = request.Validate(newForm(), req)
+	var verrs validation.ValidationErrors
+	switch {
+	case err == nil:
+		fmt.Println("resultado: la solicitud es válida")
+	case errors.As(err, &verrs):
+		current, _ := json.MarshalIndent(verrs, "", "  ")
+		fmt.Println("resultado:", string(current))
+	default:
+		fmt.Println("resultado:", err)
+	}
+	return nil
+}
+
+// replayForm busca el FormRequest por el nombre o por el tipo que quedo en la captura (request.User)
+func replayForm(entry debug.Entry, name string) (func() request.FormRequest, error) {
+	if name != "" {
+		newForm, ok := forms[name]
+		if !ok {
+			return nil, fmt.Errorf("el FormRequest '%s' no está en forms", name)
+		}
+		return newForm, nil
+	}
+	if len(entry.Validations) == 0 {
+		return nil, fmt.Errorf("la captura no dice qué FormRequest la validó, use -form")
+	}
+	for _, newForm := range forms {
+		if strings.TrimPrefix(fmt.Sprintf("%T", newForm()), "*") == entry.Validations[0].Request {
+			return newForm, nil
+		}
+	}
+	return nil, fmt.Errorf("el FormRequest %s no está en forms, use -form", entry.Validations[0].Request)
+}
+
+// mockServer inicia el servidor de pruebas con los FormRequest de forms
+func mockServer(args []string) error {
+	fs := flag.NewFlagSet("mock", flag.ExitOnError)
+	addr := fs.String("addr", ":8081", "direccion del servidor")
+	config := fs.String("config", "", "archivo json con los endpoints, sin el se crean POST, GET y GET /{id} por FormRequest")
+	fs.Parse(args)
+
+	var endpoints []mock.Endpoint
+	if *config != "" {
+		var err error
+		if endpoints, err = mock.LoadEndpoints(*config); err != nil {
+			return err
+		}
+	}
+	server := mock.NewServer(forms, endpoints)
+	handler, err := server.Handler()
+	if err != nil {
+		return err
+	}
+
+	for _, e := range server.Endpoints {
+		fmt.Printf("  %-6s %s\n", e.Method, e.Path)
+	}
+	fmt.Println("Servidor de pruebas en", *addr)
+	return http.ListenAndServe(*addr, handler)
+}
@@ -0,0 +1,248 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/internal/request"
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// Entry registro de auditoria de una mutacion
+type Entry struct {
+	Time    time.Time         `json:"time"`
+	Actor   string            `json:"actor,omitempty"` // usuario autenticado
+	IP      string            `json:"ip"`
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Status  int               `json:"status"`
+	Request string            `json:"request"` // tipo del FormRequest (request.User)
+	Changes map[string]Change `json:"changes"`
+}
+
+// Change valor anterior y nuevo de un campo, Old es nil si no se conocia el estado anterior
+type Change struct {
+	Old any `json:"old,omitempty"`
+	New any `json:"new"`
+}
+
+// Sink destino de los registros de auditoria (archivo, tabla, servicio http)
+type Sink interface {
+	Write(ctx context.Context, entry Entry) error
+}
+
+// Mask valor con el que se reemplazan los campos sensibles
+const Mask = validation.Mask
+
+// RedactedFields campos que nunca se guardan en claro, se compara el ultimo segmento sin importar mayusculas
+// ademas se enmascaran los campos con el tag sensitive o que declara el metodo Hidden del FormRequest
+var RedactedFields = []string{"password", "password_confirmation", "token", "secret", "api_key", "access_token", "refresh_token"}
+
+// Actor retorna el identificador del usuario autenticado, reemplacela si el usuario se guarda de otra forma
+var Actor = func(ctx *controller.Context) string {
+	if ctx.User == nil || len(ctx.User.Data) == 0 {
+		return ""
+	}
+	for _, key := range []string{"id", "_id"} {
+		if id, ok := ctx.User.Data[0][key]; ok {
+			return fmt.Sprint(id)
+		}
+	}
+	return ""
+}
+
+// recorderKey llave del contexto donde el middleware guarda lo que se valida en el request
+type recorderKey struct{}
+
+// recorder FormRequests validados durante el request y el estado anterior de cada uno
+type recorder struct {
+	mu       sync.Mutex
+	requests []request.FormRequest
+	before   map[request.FormRequest]any
+}
+
+var registerHook sync.Once
+
+// WithRecorder prepara el request para que se registre lo que se valide en el
+// lo usa el middleware Audit, registra el hook de request.OnValidated la primera vez
+func WithRecorder(req *http.Request) *http.Request {
+	registerHook.Do(func() {
+		request.OnValidated(func(r *http.Request, fr request.FormRequest) {
+			if rec, ok := r.Context().Value(recorderKey{}).(*recorder); ok {
+				rec.mu.Lock()
+				rec.requests = append(rec.requests, fr)
+				rec.mu.Unlock()
+			}
+		})
+	})
+	return req.WithContext(context.WithValue(req.Context(), recorderKey{}, &recorder{}))
+}
+
+// Before guarda el estado anterior del recurso que modifica el FormRequest
+// asi el registro tiene el valor anterior y el nuevo de cada campo que cambio
+//
+//	audit.Before(ctx.Request, &req, user)
+func Before(req *http.Request, fr request.FormRequest, previous any) {
+	rec, ok := req.Context().Value(recorderKey{}).(*recorder)
+	if !ok {
+		return
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if rec.before == nil {
+		rec.before = make(map[request.FormRequest]any)
+	}
+	rec.before[fr] = previous
+}
+
+// Entries construye los registros de los FormRequests validados en el request
+func Entries(ctx *controller.Context, status int) []Entry {
+	rec, ok := ctx.Request.Context().Value(recorderKey{}).(*recorder)
+	if !ok {
+		return nil
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	entries := make([]Entry, 0, len(rec.requests))
+	for _, fr := range rec.requests {
+		entries = append(entries, Entry{
+			Time:    time.Now().UTC(),
+			Actor:   Actor(ctx),
+			IP:      request.ClientIP(ctx.Request),
+			Method:  ctx.Request.Method,
+			Path:    ctx.Request.URL.Path,
+			Status:  status,
+			Request: typeName(fr),
+			Changes: Diff(rec.before[fr], fr),
+		})
+	}
+	return entries
+}
+
+// Diff compara el estado anterior con el nuevo y retorna los campos que cambiaron
+// los dos se serializan con encoding/json, los campos sensibles se enmascaran
+func Diff(before any, after any) map[string]Change {
+	oldValues := flatten(toMap(before))
+	newValues := flatten(toMap(after))
+	sensitive := append(validation.SensitiveFields(before), validation.SensitiveFields(after)...)
+
+	changes := make(map[string]Change)
+	for field, value := range newValues {
+		old, existed := oldValues[field]
+		if existed && reflect.DeepEqual(old, value) {
+			continue
+		}
+		change := Change{New: value}
+		if existed {
+			change.Old = old
+		}
+		if IsRedacted(field, sensitive) {
+			change.New = Mask
+			if existed {
+				change.Old = Mask
+			}
+		}
+		changes[field] = change
+	}
+	return changes
+}
+
+// toMap serializa el valor y lo convierte en un map
+func toMap(v any) map[string]any {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var m map[string]any
+	json.Unmarshal(data, &m)
+	return m
+}
+
+// flatten convierte los objetos anidados a notacion de puntos
+func flatten(m map[string]any) map[string]any {
+	out := make(map[string]any, len(m))
+	var walk func(prefix string, m map[string]any)
+	walk = func(prefix string, m map[string]any) {
+		for k, v := range m {
+			if nested, ok := v.(map[string]any); ok && len(nested) > 0 {
+				walk(prefix+k+".", nested)
+				continue
+			}
+			out[prefix+k] = v
+		}
+	}
+	walk("", m)
+	return out
+}
+
+// MaskJSON enmascara en el body json los campos de RedactedFields en cualquier nivel y los campos sensibles
+// en notacion de puntos (validation.SensitiveFields del FormRequest), si el body no es json lo retorna igual
+// lo usan el inspector de depuracion y el log de solicitudes
+func MaskJSON(body []byte, sensitive []string) []byte {
+	var data any
+	if err := json.Unmarshal(body, &data); err != nil {
+		return body
+	}
+	masked, err := json.Marshal(maskValue(data, "", sensitive))
+	if err != nil {
+		return body
+	}
+	return masked
+}
+
+// maskValue recorre el json y enmascara los campos sensibles, los elementos de un array usan la ruta del array
+func maskValue(value any, prefix string, sensitive []string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, inner := range v {
+			if IsRedacted(prefix+key, sensitive) {
+				v[key] = Mask
+				continue
+			}
+			v[key] = maskValue(inner, prefix+key+".", sensitive)
+		}
+	case []any:
+		for i, inner := range v {
+			v[i] = maskValue(inner, prefix, sensitive)
+		}
+	}
+	return value
+}
+
+// IsRedacted indica si el campo es sensible, por nombre o porque esta dentro de un campo sensible
+func IsRedacted(field string, sensitive []string) bool {
+	for _, s := range sensitive {
+		if field == s || strings.HasPrefix(field, s+".") {
+			return true
+		}
+	}
+	if i := strings.LastIndexByte(field, '.'); i >= 0 {
+		field = field[i+1:]
+	}
+	for _, r := range RedactedFields {
+		if strings.EqualFold(field, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// typeName nombre del tipo sin punteros (request.User)
+func typeName(v any) string {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.String()
+}
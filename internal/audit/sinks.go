@@ -0,0 +1,122 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileSink guarda un registro por linea en formato json (json lines)
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink abre (o crea) el archivo en modo append
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{file: file}, nil
+}
+
+func (s *FileSink) Write(ctx context.Context, entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.file.Write(append(line, '\n'))
+	return err
+}
+
+// Close cierra el archivo
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
+
+// SQLSink guarda los registros en una tabla
+//
+//	CREATE TABLE audit_logs (
+//		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+//		created_at DATETIME, actor VARCHAR(255), ip VARCHAR(45), method VARCHAR(10),
+//		path VARCHAR(2048), status INT, request VARCHAR(255), changes JSON
+//	)
+type SQLSink struct {
+	DB    *sql.DB
+	Table string
+}
+
+// NewSQLSink crea el sink sobre la tabla indicada
+func NewSQLSink(db *sql.DB, table string) *SQLSink {
+	return &SQLSink{DB: db, Table: table}
+}
+
+func (s *SQLSink) Write(ctx context.Context, entry Entry) error {
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf("INSERT INTO %s (created_at, actor, ip, method, path, status, request, changes) VALUES (?, ?, ?, ?, ?, ?, ?, ?)", s.Table)
+	_, err = s.DB.ExecContext(ctx, query, entry.Time, entry.Actor, entry.IP, entry.Method, entry.Path, entry.Status, entry.Request, changes)
+	return err
+}
+
+// HTTPSink envia cada registro con un POST json a un servicio externo
+type HTTPSink struct {
+	URL    string
+	Header http.Header // cabeceras extra, por ejemplo Authorization
+	Client *http.Client
+}
+
+// NewHTTPSink crea el sink con un timeout de 5 segundos
+func NewHTTPSink(url string) *HTTPSink {
+	return &HTTPSink{URL: url, Header: http.Header{}, Client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+func (s *HTTPSink) Write(ctx context.Context, entry Entry) error {
+	body, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range s.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("audit: el servicio respondió %d", res.StatusCode)
+	}
+	return nil
+}
+
+// MultiSink envia cada registro a varios sinks, retorna todos los errores
+type MultiSink []Sink
+
+func (m MultiSink) Write(ctx context.Context, entry Entry) error {
+	var errs []error
+	for _, sink := range m {
+		if err := sink.Write(ctx, entry); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
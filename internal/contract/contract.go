@@ -0,0 +1,134 @@
+package contract
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// Contratos de respuesta: en desarrollo y en las pruebas se valida lo que responde cada controlador contra
+// el esquema que declara la ruta, asi un campo que se renombra o cambia de tipo se detecta antes de que
+// lo note el cliente. en produccion no se revisa nada (Enabled es false) y la respuesta no se guarda en memoria
+//
+//	type UserResource struct {
+//		ID    string `json:"id" rules:"required|ulid"`
+//		Email string `json:"email" rules:"required|email"`
+//		Name  string `json:"name" rules:"required|max:80"`
+//	}
+//
+//	{Path: "/{id}", Methods: AllowMethods(GET), Handler: Show, Contract: contract.Rules(&UserResource{})}
+//	{Path: "/", Methods: AllowMethods(GET), Handler: Index, Contract: contract.MustSchema(usersSchema)}
+//
+// el router lo activa con APP_DEBUG=true, las pruebas con contract.Enabled = true
+
+// Enabled activa la revision de los contratos, se debe definir al iniciar la aplicacion
+var Enabled bool
+
+// Strict con true una respuesta que no cumple el contrato se reemplaza por un 500 con los errores
+// con false se responde igual y solo se avisa a OnViolation (o al log) y se agrega la cabecera ViolationHeader
+var Strict = true
+
+// ViolationHeader cabecera que se agrega a la respuesta que no cumple el contrato cuando Strict es false
+var ViolationHeader = "X-Contract-Violation"
+
+// ErrViolation la respuesta del controlador no cumple el contrato de la ruta
+var ErrViolation = errors.New("la respuesta no cumple el contrato")
+
+// CodeViolation codigo del error en las respuestas
+const CodeViolation = "CONTRACT_VIOLATION"
+
+func init() {
+	validation.RegisterErrorCode(ErrViolation, CodeViolation, "la respuesta del controlador no cumple el contrato de la ruta (solo en desarrollo)")
+}
+
+// Contract revisa el body json de una respuesta exitosa (2xx)
+// retorna validation.ValidationErrors con los campos que no cumplen o el error de json
+type Contract interface {
+	Check(ctx context.Context, body []byte) error
+}
+
+// Violation detalle de una respuesta que no cumple el contrato
+type Violation struct {
+	Method string
+	Path   string
+	Status int
+	Err    error
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("%s %s respondió %d: %v: %v", v.Method, v.Path, v.Status, ErrViolation, v.Err)
+}
+
+// Unwrap permite errors.Is(err, ErrViolation) y errors.As con los ValidationErrors
+func (v *Violation) Unwrap() []error {
+	return []error{ErrViolation, v.Err}
+}
+
+// violationHooks funciones que reciben las respuestas que no cumplen el contrato
+var violationHooks []func(req *http.Request, v *Violation)
+
+// OnViolation registra una funcion que recibe cada respuesta que no cumple el contrato
+// sin hooks las violaciones se escriben en el log. se debe llamar al iniciar la aplicacion
+func OnViolation(hook func(req *http.Request, v *Violation)) {
+	violationHooks = append(violationHooks, hook)
+}
+
+// Report avisa a OnViolation o escribe en el log, lo llama el middleware Contract
+func Report(req *http.Request, v *Violation) {
+	if len(violationHooks) == 0 {
+		log.Printf("contract_violation %v", v)
+		return
+	}
+	for _, hook := range violationHooks {
+		hook(req, v)
+	}
+}
+
+// rules contrato con las reglas del tag rules de un struct
+type rules struct {
+	typ reflect.Type
+}
+
+// Rules contrato con las reglas del struct (o slice de structs) v, el body se deserializa en un valor nuevo
+// del mismo tipo y se valida como un FormRequest. un campo de la respuesta que no existe en el struct
+// tambien es una violacion, el contrato debe declarar todo lo que se responde
+//
+//	contract.Rules(&UserResource{})    // {"id": ..., "email": ..., "name": ...}
+//	contract.Rules([]UserResource{})   // [{...}, {...}]
+func Rules(v any) Contract {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || (t.Kind() != reflect.Struct && t.Kind() != reflect.Slice) {
+		panic(fmt.Sprintf("contract.Rules: %T no es un struct ni un slice", v))
+	}
+	return &rules{typ: t}
+}
+
+func (r *rules) Check(ctx context.Context, body []byte) error {
+	out := reflect.New(r.typ)
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(out.Interface()); err != nil {
+		return err
+	}
+	if r.typ.Kind() == reflect.Slice {
+		elem := r.typ.Elem()
+		for elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		if elem.Kind() != reflect.Struct {
+			return nil
+		}
+		return validation.SliceContext(ctx, out.Interface())
+	}
+	return validation.StructContext(ctx, out.Interface())
+}
@@ -0,0 +1,369 @@
+package contract
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// Contrato con JSON Schema para las respuestas que ya tienen el esquema publicado (OpenAPI, otro equipo)
+// se soporta el subconjunto que se usa para describir respuestas:
+//
+//	type (string o lista, "null" para los nulos), properties, required, additionalProperties (bool o esquema)
+//	items, enum, const, minLength, maxLength, pattern, format (date-time, date, email, uuid)
+//	minimum, maximum, exclusiveMinimum, exclusiveMaximum, minItems, maxItems
+//
+// las demas palabras ($ref, oneOf, allOf...) se ignoran
+//
+//	//go:embed schemas/users.json
+//	var usersSchema []byte
+//
+//	Contract: contract.MustSchema(usersSchema)
+
+// rawSchema esquema como viene en el json
+type rawSchema struct {
+	Type                 any                   `json:"type"`
+	Properties           map[string]*rawSchema `json:"properties"`
+	Required             []string              `json:"required"`
+	AdditionalProperties json.RawMessage       `json:"additionalProperties"`
+	Items                *rawSchema            `json:"items"`
+	Enum                 []any                 `json:"enum"`
+	Const                *any                  `json:"const"`
+	MinLength            *int                  `json:"minLength"`
+	MaxLength            *int                  `json:"maxLength"`
+	Pattern              string                `json:"pattern"`
+	Format               string                `json:"format"`
+	Minimum              *float64              `json:"minimum"`
+	Maximum              *float64              `json:"maximum"`
+	ExclusiveMinimum     *float64              `json:"exclusiveMinimum"`
+	ExclusiveMaximum     *float64              `json:"exclusiveMaximum"`
+	MinItems             *int                  `json:"minItems"`
+	MaxItems             *int                  `json:"maxItems"`
+}
+
+// schema esquema compilado, las expresiones regulares se compilan una vez
+type schema struct {
+	raw        *rawSchema
+	types      []string
+	properties map[string]*schema
+	additional *schema
+	noExtra    bool
+	items      *schema
+	pattern    *regexp.Regexp
+}
+
+// Schema compila el JSON Schema, retorna error si el esquema no es json o un pattern no compila
+func Schema(data []byte) (Contract, error) {
+	var raw rawSchema
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("contract: el esquema no es json válido: %w", err)
+	}
+	return compile(&raw, "")
+}
+
+// MustSchema es Schema para los esquemas que se definen al iniciar, hace panic si el esquema no compila
+func MustSchema(data []byte) Contract {
+	c, err := Schema(data)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// compile convierte el esquema crudo, path es la ruta del esquema para los mensajes de error
+func compile(raw *rawSchema, path string) (*schema, error) {
+	s := &schema{raw: raw, properties: map[string]*schema{}}
+	switch t := raw.Type.(type) {
+	case string:
+		s.types = []string{t}
+	case []any:
+		for _, v := range t {
+			name, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("contract: %s: type debe ser un texto o una lista de textos", label(path))
+			}
+			s.types = append(s.types, name)
+		}
+	case nil:
+	default:
+		return nil, fmt.Errorf("contract: %s: type debe ser un texto o una lista de textos", label(path))
+	}
+	for name, prop := range raw.Properties {
+		child, err := compile(prop, join(path, name))
+		if err != nil {
+			return nil, err
+		}
+		s.properties[name] = child
+	}
+	if ap := bytes.TrimSpace(raw.AdditionalProperties); len(ap) > 0 {
+		switch string(ap) {
+		case "false":
+			s.noExtra = true
+		case "true":
+		default:
+			var extra rawSchema
+			if err := json.Unmarshal(ap, &extra); err != nil {
+				return nil, fmt.Errorf("contract: %s: additionalProperties: %w", label(path), err)
+			}
+			child, err := compile(&extra, join(path, "*"))
+			if err != nil {
+				return nil, err
+			}
+			s.additional = child
+		}
+	}
+	if raw.Items != nil {
+		child, err := compile(raw.Items, join(path, "*"))
+		if err != nil {
+			return nil, err
+		}
+		s.items = child
+	}
+	if raw.Pattern != "" {
+		re, err := regexp.Compile(raw.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("contract: %s: pattern: %w", label(path), err)
+		}
+		s.pattern = re
+	}
+	return s, nil
+}
+
+func (s *schema) Check(ctx context.Context, body []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return err
+	}
+	errs := validation.ValidationErrors{}
+	s.validate(value, "", errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validate agrega a errs los errores del valor, path es el campo en notacion de puntos
+func (s *schema) validate(value any, path string, errs validation.ValidationErrors) {
+	add := func(format string, args ...any) {
+		key := label(path)
+		errs[key] = append(errs[key], fmt.Sprintf(format, args...))
+	}
+
+	kind := typeOf(value)
+	if len(s.types) > 0 && !s.allows(kind, value) {
+		add("debe ser de tipo %s y es %s", joinTypes(s.types), kind)
+		return
+	}
+	if len(s.raw.Enum) > 0 && !contains(s.raw.Enum, value) {
+		add("no es uno de los valores permitidos")
+	}
+	if s.raw.Const != nil && !equal(*s.raw.Const, value) {
+		add("debe ser %v", *s.raw.Const)
+	}
+
+	switch v := value.(type) {
+	case string:
+		n := utf8.RuneCountInString(v)
+		if s.raw.MinLength != nil && n < *s.raw.MinLength {
+			add("debe tener al menos %d caracteres", *s.raw.MinLength)
+		}
+		if s.raw.MaxLength != nil && n > *s.raw.MaxLength {
+			add("debe tener máximo %d caracteres", *s.raw.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			add("no cumple el patrón %s", s.raw.Pattern)
+		}
+		if s.raw.Format != "" && !validFormat(s.raw.Format, v) {
+			add("no tiene el formato %s", s.raw.Format)
+		}
+	case json.Number:
+		f, _ := v.Float64()
+		if s.raw.Minimum != nil && f < *s.raw.Minimum {
+			add("debe ser mayor o igual a %v", *s.raw.Minimum)
+		}
+		if s.raw.Maximum != nil && f > *s.raw.Maximum {
+			add("debe ser menor o igual a %v", *s.raw.Maximum)
+		}
+		if s.raw.ExclusiveMinimum != nil && f <= *s.raw.ExclusiveMinimum {
+			add("debe ser mayor a %v", *s.raw.ExclusiveMinimum)
+		}
+		if s.raw.ExclusiveMaximum != nil && f >= *s.raw.ExclusiveMaximum {
+			add("debe ser menor a %v", *s.raw.ExclusiveMaximum)
+		}
+	case []any:
+		if s.raw.MinItems != nil && len(v) < *s.raw.MinItems {
+			add("debe tener al menos %d elementos", *s.raw.MinItems)
+		}
+		if s.raw.MaxItems != nil && len(v) > *s.raw.MaxItems {
+			add("debe tener máximo %d elementos", *s.raw.MaxItems)
+		}
+		if s.items != nil {
+			for i, item := range v {
+				s.items.validate(item, join(path, strconv.Itoa(i)), errs)
+			}
+		}
+	case map[string]any:
+		for _, name := range s.raw.Required {
+			if _, ok := v[name]; !ok {
+				key := join(path, name)
+				errs[key] = append(errs[key], "el campo es obligatorio")
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			child := join(path, name)
+			if prop, ok := s.properties[name]; ok {
+				prop.validate(v[name], child, errs)
+			} else if s.additional != nil {
+				s.additional.validate(v[name], child, errs)
+			} else if s.noExtra {
+				errs[child] = append(errs[child], "el campo no está en el contrato")
+			}
+		}
+	}
+}
+
+// allows indica si el tipo del valor es uno de los del esquema, un integer tambien es number
+func (s *schema) allows(kind string, value any) bool {
+	for _, t := range s.types {
+		if t == kind || (t == "number" && kind == "integer") {
+			return true
+		}
+		if t == "integer" && kind == "number" {
+			// 1.0 es un integer en JSON Schema
+			if n, ok := value.(json.Number); ok {
+				if f, err := n.Float64(); err == nil && f == math.Trunc(f) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// typeOf nombre del tipo JSON Schema del valor
+func typeOf(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// validFormat revisa los formatos que se usan en las respuestas, los demas se aceptan
+func validFormat(format string, value string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "date":
+		_, err := time.Parse(time.DateOnly, value)
+		return err == nil
+	case "email":
+		addr, err := mail.ParseAddress(value)
+		return err == nil && addr.Address == value
+	case "uuid":
+		return uuidPattern.MatchString(value)
+	}
+	return true
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// contains indica si el valor esta en el enum
+func contains(enum []any, value any) bool {
+	for _, e := range enum {
+		if equal(e, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// equal compara un valor del esquema (float64) con uno de la respuesta (json.Number)
+func equal(expected any, value any) bool {
+	if n, ok := value.(json.Number); ok {
+		f, err := n.Float64()
+		e, isNumber := expected.(float64)
+		return err == nil && isNumber && e == f
+	}
+	return reflect.DeepEqual(expected, normalize(value))
+}
+
+// normalize convierte los json.Number anidados a float64 para compararlos con el esquema
+func normalize(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = normalize(item)
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, item := range v {
+			out[k] = normalize(item)
+		}
+		return out
+	}
+	return value
+}
+
+func join(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// label nombre del campo en los errores, la raiz de la respuesta es "$"
+func label(path string) string {
+	if path == "" {
+		return "$"
+	}
+	return path
+}
+
+func joinTypes(types []string) string {
+	if len(types) == 1 {
+		return types[0]
+	}
+	out := types[0]
+	for _, t := range types[1:] {
+		out += " o " + t
+	}
+	return out
+}
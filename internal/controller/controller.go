@@ -1,10 +1,13 @@
 package controller
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
-	"strconv"
 
 	"github.com/donbarrigon/new-project/internal/orm"
+	"github.com/donbarrigon/new-project/lib/formatter"
+	"github.com/donbarrigon/new-project/lib/validation"
 )
 
 type ControllerFunc func(ctx *Context)
@@ -22,10 +25,32 @@ type Context struct {
 }
 
 type ErrorResponse struct {
-	Status     string      `json:"status"`
-	Message    string      `json:"message"`
-	StatusCode int         `json:"status_code"`
-	Errors     interface{} `json:"errors"`
+	Status     string              `json:"status"`
+	Message    string              `json:"message"`
+	StatusCode int                 `json:"status_code"`
+	Code       string              `json:"code,omitempty"`
+	Errors     interface{}         `json:"errors"`
+	Codes      map[string][]string `json:"codes,omitempty"`
+}
+
+// NewErrorResponse arma la respuesta de err con su codigo (validation.ErrorCode) y los codigos de cada campo
+// errors solo lleva los errores de validacion (ValidationErrors o ErrorBag), los demas van en el mensaje
+func NewErrorResponse(statusCode int, message string, err error) ErrorResponse {
+	res := ErrorResponse{
+		Status:     "error",
+		Message:    message,
+		StatusCode: statusCode,
+		Code:       validation.ErrorCode(err),
+		Codes:      validation.FieldCodes(err),
+	}
+	var verrs validation.ValidationErrors
+	var bag validation.ErrorBag
+	if errors.As(err, &verrs) {
+		res.Errors = verrs
+	} else if errors.As(err, &bag) {
+		res.Errors = bag
+	}
+	return res
 }
 
 func NewContext(w http.ResponseWriter, r *http.Request) *Context {
@@ -42,7 +67,7 @@ func (c *Context) ParseQuery(key ...string) any {
 	queryParams := c.Query(key...)
 
 	if len(key) == 1 {
-		return c.parseValue(queryParams.(string))
+		return parseValue(queryParams.(string))
 	}
 
 	// Inicializar el mapa Query a retornar
@@ -50,26 +75,54 @@ func (c *Context) ParseQuery(key ...string) any {
 
 	// Iterar sobre los parámetros de la URL
 	for k, value := range queryParams.(map[string]string) {
-		query[k] = c.parseValue(value)
+		query[k] = parseValue(value)
 	}
 	return query
 }
 
-// Suggested code may be subject to a license. Learn more: ~LicenseLog:2687351136.
-func (c *Context) parseValue(value string) any {
-	if intValue, err := strconv.Atoi(value); err == nil {
-		// Es un int
-		return intValue
-	} else if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
-		// Es un float
-		return floatValue
-	} else if boolValue, err := strconv.ParseBool(value); err == nil {
-		// Es un bool
-		return boolValue
-	} else {
-		// Es un string (por defecto)
-		return value
+// parseValue adivina el tipo del valor [int | float64 | bool | string]
+func parseValue(value string) any {
+	return formatter.ParseValue(value)
+}
+
+// QuerySchema define el tipo de cada parametro de la url para TypedQuery
+// los tipos son los de formatter.Coerce: auto, string, int, float, bool
+//
+//	query, err := ctx.TypedQuery(controller.QuerySchema{
+//		"code":   "string", // "0123" se queda como "0123"
+//		"page":   "int",
+//		"active": "bool",
+//	})
+type QuerySchema map[string]string
+
+// TypedQuery parsea los parametros de la url con el tipo que indica el schema en lugar de adivinarlo
+// los parametros que no estan en el schema se adivinan igual que ParseQuery
+// los parametros que no vienen en la url no se incluyen en el map
+func (c *Context) TypedQuery(schema QuerySchema) (map[string]any, error) {
+	query := make(map[string]any)
+	if c.Request == nil {
+		return query, nil
+	}
+
+	errs := make(FieldsError)
+	for k, value := range c.queryToMap() {
+		v, err := formatter.Coerce(value, schema[k])
+		if err != nil {
+			errs[k] = append(errs[k], err.Error())
+			continue
+		}
+		query[k] = v
 	}
+
+	if len(errs) > 0 {
+		return query, errs
+	}
+	return query, nil
+}
+
+// Error implementa la interfaz error para poder retornar los errores por campo
+func (e FieldsError) Error() string {
+	return fmt.Sprintf("errores en %d campos", len(e))
 }
 
 // Query es una forma menos verbosa de tomar los valores de la cabecera y los retorna en un map o string si es solo 1
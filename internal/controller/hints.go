@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Preload recurso que el navegador puede empezar a descargar antes de recibir la pagina
+// se envia como cabecera Link en la respuesta 103 Early Hints y en la respuesta final
+//
+//	ctx.EarlyHints(
+//		controller.Preload{URL: "/assets/app.css", As: "style"},
+//		controller.Preload{URL: "/assets/app.js", As: "script"},
+//		controller.Preload{URL: "/fonts/inter.woff2", As: "font", Type: "font/woff2", CrossOrigin: "anonymous"},
+//		controller.Preload{URL: "https://cdn.example.com", Rel: "preconnect"},
+//	)
+type Preload struct {
+	URL         string
+	Rel         string // preload si es "", tambien preconnect, modulepreload o dns-prefetch
+	As          string // style, script, font, image, fetch
+	Type        string // tipo mime, el navegador no descarga los tipos que no soporta
+	CrossOrigin string // anonymous o use-credentials, las fuentes siempre lo necesitan
+}
+
+// String valor de la cabecera Link: </assets/app.css>; rel=preload; as=style
+func (p Preload) String() string {
+	rel := p.Rel
+	if rel == "" {
+		rel = "preload"
+	}
+	var b strings.Builder
+	b.WriteString("<" + p.URL + ">; rel=" + rel)
+	if p.As != "" {
+		b.WriteString("; as=" + p.As)
+	}
+	if p.Type != "" {
+		b.WriteString(`; type="` + p.Type + `"`)
+	}
+	if p.CrossOrigin != "" {
+		b.WriteString("; crossorigin=" + p.CrossOrigin)
+	}
+	return b.String()
+}
+
+// ServerPush con true EarlyHints tambien hace push de los recursos preload en HTTP/2 (http.Pusher)
+// los navegadores actuales ignoran el push, queda para clientes que aun lo usan
+var ServerPush = false
+
+// EarlyHints agrega las cabeceras Link y envia la respuesta 103 para que el navegador descargue los recursos
+// mientras el controlador arma la pagina. se debe llamar antes de escribir la respuesta, las cabeceras
+// tambien quedan en la respuesta final para los clientes que ignoran el 103
+// a los clientes HTTP/1.0 no se les envia el 103, no lo entienden
+//
+// normalmente se define por ruta con el campo Preload de routesmaker.Route (ver middleware.EarlyHints)
+func (c *Context) EarlyHints(links ...Preload) {
+	if len(links) == 0 {
+		return
+	}
+	header := c.Writer.Header()
+	for _, link := range links {
+		header.Add("Link", link.String())
+	}
+	if ServerPush {
+		c.push(links)
+	}
+	if c.Request != nil && !c.Request.ProtoAtLeast(1, 1) {
+		return
+	}
+	c.Writer.WriteHeader(http.StatusEarlyHints)
+}
+
+// push hace push de los recursos preload del mismo sitio si la conexion es HTTP/2
+func (c *Context) push(links []Preload) {
+	pusher, ok := findPusher(c.Writer)
+	if !ok {
+		return
+	}
+	for _, link := range links {
+		if (link.Rel != "" && link.Rel != "preload") || !strings.HasPrefix(link.URL, "/") || strings.HasPrefix(link.URL, "//") {
+			continue
+		}
+		if err := pusher.Push(link.URL, nil); err != nil {
+			// el cliente desactivo el push, no tiene sentido seguir
+			return
+		}
+	}
+}
+
+// findPusher busca el http.Pusher en los writers que envuelven al original (middlewares con Unwrap)
+func findPusher(w http.ResponseWriter) (http.Pusher, bool) {
+	for w != nil {
+		if p, ok := w.(http.Pusher); ok {
+			return p, true
+		}
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return nil, false
+		}
+		w = u.Unwrap()
+	}
+	return nil, false
+}
@@ -0,0 +1,332 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// Encoder serializa la respuesta en un formato (json, xml, csv)
+type Encoder func(w io.Writer, data any) error
+
+// registeredEncoder formato registrado con su media type
+type registeredEncoder struct {
+	mediaType string
+	encode    Encoder
+}
+
+// encoders formatos de respuesta disponibles, el primero es el que se usa cuando el cliente acepta cualquiera
+var (
+	encoders = []registeredEncoder{
+		{mediaType: "application/json", encode: encodeJSON},
+		{mediaType: "application/xml", encode: encodeXML},
+		{mediaType: "text/csv", encode: encodeCSV},
+	}
+	encodersMu sync.RWMutex
+)
+
+// RegisterEncoder registra o reemplaza el formato de respuesta de un media type
+// se debe llamar al iniciar la aplicacion
+//
+//	controller.RegisterEncoder("application/x-yaml", func(w io.Writer, data any) error {
+//		return yaml.NewEncoder(w).Encode(data)
+//	})
+func RegisterEncoder(mediaType string, encode Encoder) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	mediaType = strings.ToLower(mediaType)
+	for i := range encoders {
+		if encoders[i].mediaType == mediaType {
+			encoders[i].encode = encode
+			return
+		}
+	}
+	encoders = append(encoders, registeredEncoder{mediaType: mediaType, encode: encode})
+}
+
+// Negotiate elige el formato de respuesta segun la cabecera Accept (con sus pesos q)
+// sin Accept o con */* se usa json, ok es false si el cliente no acepta ningun formato registrado
+func Negotiate(accept string) (mediaType string, encode Encoder, ok bool) {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+	if strings.TrimSpace(accept) == "" {
+		return encoders[0].mediaType, encoders[0].encode, true
+	}
+
+	best, bestQ := -1, 0.0
+	for _, part := range strings.Split(accept, ",") {
+		accepted, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if s, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(s, 64); err != nil {
+				continue
+			}
+		}
+		if q <= bestQ {
+			continue
+		}
+		for i, enc := range encoders {
+			if acceptsMediaType(accepted, enc.mediaType) {
+				best, bestQ = i, q
+				break
+			}
+		}
+	}
+	if best < 0 {
+		return "", nil, false
+	}
+	return encoders[best].mediaType, encoders[best].encode, true
+}
+
+// acceptsMediaType indica si el rango de Accept (text/*, */*) incluye el media type
+func acceptsMediaType(accepted string, mediaType string) bool {
+	if accepted == "*/*" || accepted == mediaType {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(accepted, "/*")
+	return ok && strings.HasPrefix(mediaType, prefix+"/")
+}
+
+// Respond escribe data en el formato que pide la cabecera Accept del cliente
+// si el cliente no acepta ningun formato registrado responde 406 en json
+//
+//	ctx.Respond(http.StatusOK, users) // Accept: text/csv -> una fila por usuario
+func (c *Context) Respond(statusCode int, data any) {
+	header := c.Writer.Header()
+	header.Add("Vary", "Accept")
+
+	mediaType, encode, ok := Negotiate(c.Request.Header.Get("Accept"))
+	if !ok {
+		c.ResponseError(http.StatusNotAcceptable, "el formato solicitado en Accept no está disponible", AvailableMediaTypes())
+		return
+	}
+
+	// se serializa antes de escribir el status para poder responder 500 si falla
+	var buf bytes.Buffer
+	if err := encode(&buf, data); err != nil {
+		log.Printf("Error encoding response as %s: %v", mediaType, err)
+		c.ResponseError(http.StatusInternalServerError, "no se pudo generar la respuesta", nil)
+		return
+	}
+	if strings.HasPrefix(mediaType, "text/") {
+		mediaType += "; charset=utf-8"
+	}
+	header.Set("Content-Type", mediaType)
+	c.Writer.WriteHeader(statusCode)
+	c.Writer.Write(buf.Bytes())
+}
+
+// RespondError escribe un ErrorResponse en el formato que pide el cliente
+func (c *Context) RespondError(statusCode int, message string, errors any) {
+	c.Respond(statusCode, ErrorResponse{
+		Status:     "error",
+		Message:    message,
+		StatusCode: statusCode,
+		Errors:     errors,
+	})
+}
+
+// Returning adapta un handler que retorna el valor de la respuesta en lugar de escribirla
+// el valor se serializa segun Accept, los errores de validacion (ValidationErrors o ErrorBag) responden 422 y los demas 500
+//
+//	{Path: "/users", Methods: AllowMethods(GET), Handler: controller.Returning(user.Index)}
+func Returning(handle func(ctx *Context) (any, error)) ControllerFunc {
+	return func(ctx *Context) {
+		data, err := handle(ctx)
+		if err != nil {
+			var verrs validation.ValidationErrors
+			var bag validation.ErrorBag
+			if errors.As(err, &verrs) || errors.As(err, &bag) {
+				ctx.Respond(http.StatusUnprocessableEntity, NewErrorResponse(http.StatusUnprocessableEntity, "los datos no son válidos", err))
+				return
+			}
+			log.Printf("Error in handler %s %s: %v", ctx.Request.Method, ctx.Request.URL.Path, err)
+			ctx.RespondError(http.StatusInternalServerError, "error interno del servidor", nil)
+			return
+		}
+		if data == nil {
+			ctx.ResponseNoContent()
+			return
+		}
+		ctx.Respond(http.StatusOK, data)
+	}
+}
+
+// AvailableMediaTypes retorna los formatos de respuesta registrados
+func AvailableMediaTypes() []string {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+	types := make([]string, len(encoders))
+	for i, enc := range encoders {
+		types[i] = enc.mediaType
+	}
+	return types
+}
+
+func encodeJSON(w io.Writer, data any) error {
+	return json.NewEncoder(w).Encode(data)
+}
+
+// toGeneric convierte data en maps, slices y valores simples pasando por json
+// asi xml y csv respetan los tags json y los MarshalJSON de los tipos
+func toGeneric(data any) (any, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// encodeXML escribe data dentro de <response>, los objetos usan sus llaves json como elementos
+// y los elementos de los arrays se llaman <item>
+func encodeXML(w io.Writer, data any) error {
+	value, err := toGeneric(data)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	if err := writeXMLElement(enc, "response", value); err != nil {
+		return err
+	}
+	return enc.Flush()
+}
+
+func writeXMLElement(enc *xml.Encoder, name string, value any) error {
+	start := xml.StartElement{Name: xml.Name{Local: xmlName(name)}}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := writeXMLElement(enc, k, v[k]); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if err := writeXMLElement(enc, "item", item); err != nil {
+				return err
+			}
+		}
+	case nil:
+	default:
+		if err := enc.EncodeToken(xml.CharData(fmt.Sprint(v))); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// xmlName reemplaza los caracteres que no se permiten en el nombre de un elemento
+func xmlName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+		case i > 0 && (r == '-' || r == '.' || r >= '0' && r <= '9'):
+		default:
+			r = '_'
+		}
+		b.WriteRune(r)
+	}
+	if b.Len() == 0 {
+		return "_"
+	}
+	return b.String()
+}
+
+// encodeCSV escribe una fila por elemento (o una sola fila si data es un objeto)
+// las columnas son las llaves json ordenadas y los valores anidados se escriben como json
+func encodeCSV(w io.Writer, data any) error {
+	value, err := toGeneric(data)
+	if err != nil {
+		return err
+	}
+	var rows []map[string]any
+	switch v := value.(type) {
+	case []any:
+		for _, item := range v {
+			row, ok := item.(map[string]any)
+			if !ok {
+				row = map[string]any{"value": item}
+			}
+			rows = append(rows, row)
+		}
+	case map[string]any:
+		rows = []map[string]any{v}
+	default:
+		rows = []map[string]any{{"value": v}}
+	}
+
+	columnSet := map[string]bool{}
+	var columns []string
+	for _, row := range rows {
+		for k := range row {
+			if !columnSet[k] {
+				columnSet[k] = true
+				columns = append(columns, k)
+			}
+		}
+	}
+	sort.Strings(columns)
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	record := make([]string, len(columns))
+	for _, row := range rows {
+		for i, col := range columns {
+			record[i] = csvValue(row[col])
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func csvValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case map[string]any, []any:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// LazyQuery guarda la query de la url tal cual llega (sin parsear)
+// no crea ningun map y los valores solo se convierten cuando se piden con un getter tipado
+// si los valores no tienen caracteres escapados ( %20 o + ) leerlos no hace allocations
+//
+//	q := ctx.LazyQuery()
+//	page := q.Int("page", 1)
+//	search := q.String("search")
+type LazyQuery string
+
+// LazyQuery retorna la query de la url en modo lazy
+func (c *Context) LazyQuery() LazyQuery {
+	if c.Request == nil {
+		return ""
+	}
+	return LazyQuery(c.Request.URL.RawQuery)
+}
+
+// Get retorna el primer valor de la key y si existe
+func (q LazyQuery) Get(key string) (string, bool) {
+	query := string(q)
+	for query != "" {
+		var pair string
+		pair, query, _ = strings.Cut(query, "&")
+		if pair == "" {
+			continue
+		}
+		k, v, _ := strings.Cut(pair, "=")
+		if !keyMatches(k, key) {
+			continue
+		}
+		return unescape(v), true
+	}
+	return "", false
+}
+
+// Has indica si la key existe en la query
+func (q LazyQuery) Has(key string) bool {
+	_, ok := q.Get(key)
+	return ok
+}
+
+// String retorna el valor de la key o "" si no existe
+func (q LazyQuery) String(key string) string {
+	v, _ := q.Get(key)
+	return v
+}
+
+// Int retorna el valor de la key como int o def si no existe o no es un numero
+func (q LazyQuery) Int(key string, def int) int {
+	v, ok := q.Get(key)
+	if !ok {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+// Float retorna el valor de la key como float64 o def si no existe o no es un numero
+func (q LazyQuery) Float(key string, def float64) float64 {
+	v, ok := q.Get(key)
+	if !ok {
+		return def
+	}
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+// Bool retorna el valor de la key como bool o def si no existe o no es un booleano
+func (q LazyQuery) Bool(key string, def bool) bool {
+	v, ok := q.Get(key)
+	if !ok {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return def
+	}
+	return b
+}
+
+// Value retorna el valor de la key adivinando el tipo igual que ParseQuery [int | float64 | bool | string]
+// retorna nil si la key no existe
+func (q LazyQuery) Value(key string) any {
+	v, ok := q.Get(key)
+	if !ok {
+		return nil
+	}
+	return parseValue(v)
+}
+
+// keyMatches compara la key cruda de la url con la key buscada
+// solo se desescapa cuando la key cruda lo necesita
+func keyMatches(raw string, key string) bool {
+	if !strings.ContainsAny(raw, "%+") {
+		return raw == key
+	}
+	return unescape(raw) == key
+}
+
+// unescape desescapa el valor solo si tiene caracteres escapados
+func unescape(s string) string {
+	if !strings.ContainsAny(s, "%+") {
+		return s
+	}
+	if u, err := url.QueryUnescape(s); err == nil {
+		return u
+	}
+	return s
+}
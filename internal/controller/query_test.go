@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const benchQuery = "/users?page=2&per_page=25&active=true&search=juan&sort=name&order=asc&price=19.99"
+
+func newQueryContext(target string) *Context {
+	return NewContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+}
+
+func TestLazyQueryGetters(t *testing.T) {
+	q := newQueryContext("/users?page=2&active=true&price=19.99&name=juan+perez&tag%5B%5D=a%26b&empty=").LazyQuery()
+
+	if got := q.Int("page", 1); got != 2 {
+		t.Errorf("Int(page) = %d, se esperaba 2", got)
+	}
+	if got := q.Int("missing", 1); got != 1 {
+		t.Errorf("Int(missing) = %d, se esperaba el valor por defecto 1", got)
+	}
+	if got := q.Bool("active", false); !got {
+		t.Errorf("Bool(active) = %v, se esperaba true", got)
+	}
+	if got := q.Float("price", 0); got != 19.99 {
+		t.Errorf("Float(price) = %v, se esperaba 19.99", got)
+	}
+	if got := q.String("name"); got != "juan perez" {
+		t.Errorf("String(name) = %q, se esperaba %q", got, "juan perez")
+	}
+	if got := q.String("tag[]"); got != "a&b" {
+		t.Errorf("String(tag[]) = %q, se esperaba %q", got, "a&b")
+	}
+	if !q.Has("empty") || q.Has("missing") {
+		t.Errorf("Has no detecta correctamente las keys")
+	}
+	if got := q.Value("page"); got != 2 {
+		t.Errorf("Value(page) = %v, se esperaba el int 2", got)
+	}
+}
+
+func TestLazyQueryZeroAllocs(t *testing.T) {
+	ctx := newQueryContext(benchQuery)
+	allocs := testing.AllocsPerRun(100, func() {
+		q := ctx.LazyQuery()
+		_ = q.Int("page", 1)
+		_ = q.Int("per_page", 15)
+		_ = q.Bool("active", false)
+		_ = q.String("search")
+		_ = q.Float("price", 0)
+	})
+	if allocs != 0 {
+		t.Errorf("LazyQuery hizo %.0f allocations, se esperaban 0", allocs)
+	}
+}
+
+func BenchmarkParseQuery(b *testing.B) {
+	ctx := newQueryContext(benchQuery)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		query := ctx.ParseQuery().(map[string]any)
+		_ = query["page"]
+		_ = query["per_page"]
+		_ = query["active"]
+		_ = query["search"]
+		_ = query["price"]
+	}
+}
+
+func BenchmarkLazyQuery(b *testing.B) {
+	ctx := newQueryContext(benchQuery)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		q := ctx.LazyQuery()
+		_ = q.Int("page", 1)
+		_ = q.Int("per_page", 15)
+		_ = q.Bool("active", false)
+		_ = q.String("search")
+		_ = q.Float("price", 0)
+	}
+}
@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/donbarrigon/new-project/lib/jsonapi"
+)
+
+// ResponseJSON escribe data como JSON con el status indicado
+func (c *Context) ResponseJSON(statusCode int, data any) {
+	c.Writer.Header().Set("Content-Type", "application/json")
+	c.Writer.WriteHeader(statusCode)
+	if err := json.NewEncoder(c.Writer).Encode(data); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// ResponseError escribe un ErrorResponse con el status indicado
+// errors es opcional, se usa para los errores de validacion por campo
+func (c *Context) ResponseError(statusCode int, message string, errors any) {
+	c.ResponseJSON(statusCode, ErrorResponse{
+		Status:     "error",
+		Message:    message,
+		StatusCode: statusCode,
+		Errors:     errors,
+	})
+}
+
+// ResponseErr escribe el ErrorResponse de err con los codigos para que el cliente no dependa de los mensajes
+//
+//	{"status": "error", "message": "...", "code": "REQ_TOO_MANY_REQUESTS", "errors": null}
+func (c *Context) ResponseErr(statusCode int, message string, err error) {
+	c.ResponseJSON(statusCode, NewErrorResponse(statusCode, message, err))
+}
+
+// ResponseNoContent responde 204 sin body
+func (c *Context) ResponseNoContent() {
+	c.Writer.WriteHeader(http.StatusNoContent)
+}
+
+// ResponseJSONAPI escribe data dentro de un documento JSON:API
+// data puede ser un jsonapi.Resource, un []jsonapi.Resource o un jsonapi.Document completo
+func (c *Context) ResponseJSONAPI(statusCode int, data any) {
+	doc, ok := data.(jsonapi.Document)
+	if !ok {
+		doc = jsonapi.Document{Data: data}
+	}
+	c.writeJSONAPI(statusCode, doc)
+}
+
+// ResponseJSONAPIError escribe el error como error objects de JSON:API
+// los errores de validacion incluyen el pointer del campo (/data/attributes/name)
+func (c *Context) ResponseJSONAPIError(statusCode int, err error) {
+	c.writeJSONAPI(statusCode, jsonapi.Document{Errors: jsonapi.ErrorsFrom(statusCode, err)})
+}
+
+func (c *Context) writeJSONAPI(statusCode int, doc jsonapi.Document) {
+	c.Writer.Header().Set("Content-Type", jsonapi.MediaType)
+	c.Writer.WriteHeader(statusCode)
+	if err := json.NewEncoder(c.Writer).Encode(doc); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
@@ -0,0 +1,19 @@
+package controller
+
+import (
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// WarningsResponse respuesta con los datos y las advertencias de validacion (reglas del tag warn)
+type WarningsResponse struct {
+	Data     any                         `json:"data"`
+	Warnings validation.ValidationErrors `json:"warnings,omitempty"`
+}
+
+// ResponseWithWarnings responde los datos dentro de data y las advertencias en warnings
+// en el formato que pide el cliente, sin advertencias la seccion warnings no se incluye
+//
+//	ctx.ResponseWithWarnings(http.StatusCreated, customer, req.Warnings())
+func (c *Context) ResponseWithWarnings(statusCode int, data any, warnings validation.ValidationErrors) {
+	c.Respond(statusCode, WarningsResponse{Data: data, Warnings: warnings})
+}
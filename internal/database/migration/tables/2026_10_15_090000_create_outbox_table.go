@@ -0,0 +1,26 @@
+package tables
+
+import (
+	. "github.com/donbarrigon/new-project/internal/database/migration"
+)
+
+// create_outbox_table eventos pendientes de publicar, ver internal/outbox
+func create_outbox_table() *Table {
+
+	table := NewTable(
+		"outbox",
+		BigIncrements(),
+		Char("event_id", "26", "required", "unique"),
+		String("topic", "required"),
+		String("event_key"),
+		Json("payload", "required"),
+		Json("correlation"),
+		Integer("attempts", "required", "default:0"),
+		Text("last_error"),
+		CreatedAt(),
+		DateTime("available_at", "required", "index"),
+		DateTime("published_at", "index"),
+		DateTime("failed_at"),
+	)
+	return table
+}
@@ -14,6 +14,7 @@ func NewMigration() {
 
 	schema.AddTables(
 		create_user_table(),
+		create_outbox_table(),
 		// aqui agrege las demas funciones de creacion de tablas
 	)
 
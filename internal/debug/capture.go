@@ -0,0 +1,94 @@
+package debug
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/donbarrigon/new-project/internal/audit"
+	"github.com/donbarrigon/new-project/internal/request"
+)
+
+// Captura y reproduccion de solicitudes: en produccion se guardan en archivos las solicitudes que fallaron
+// (con el body y las cabeceras enmascarados) y luego se reproducen por el mismo FormRequest en local o en las pruebas
+// para encontrar el error de validacion que reporto el usuario
+//
+//	recorder := debug.NewFileRecorder("storage/captures")
+//	HandleFuncs("/users", user.PrivateRoutes(), middleware.Record(recorder, nil))
+//
+//	err := debug.Replay("storage/captures/20261015-072936-3e42b6a6.json", &request.User{})
+//
+// o desde la consola: go run ./cmd/cli replay storage/captures/20261015-072936-3e42b6a6.json
+// los campos enmascarados llegan como [REDACTED] y el body se corta en MaxBodySize, si el error depende de ellos
+// hay que completar el archivo a mano
+
+// FileRecorder guarda cada captura en un archivo json dentro de un directorio
+type FileRecorder struct {
+	dir string
+}
+
+// NewFileRecorder crea el FileRecorder, el directorio se crea al guardar la primera captura
+func NewFileRecorder(dir string) *FileRecorder {
+	return &FileRecorder{dir: dir}
+}
+
+// Save guarda la captura y retorna la ruta del archivo
+func (r *FileRecorder) Save(entry Entry) (string, error) {
+	if err := os.MkdirAll(r.dir, 0o755); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(r.dir, entry.Time.UTC().Format("20060102-150405")+"-"+entry.ID+".json")
+	return path, os.WriteFile(path, data, 0o600)
+}
+
+// Load lee una captura guardada con FileRecorder
+func Load(path string) (Entry, error) {
+	var entry Entry
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return entry, err
+	}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return entry, fmt.Errorf("la captura %s no es válida: %w", path, err)
+	}
+	return entry, nil
+}
+
+// Request reconstruye la solicitud de la captura, las cabeceras enmascaradas no se envian
+func (e Entry) Request() (*http.Request, error) {
+	target := e.Path
+	if len(e.Query) > 0 {
+		target += "?" + e.Query.Encode()
+	}
+	req, err := http.NewRequest(e.Method, target, strings.NewReader(e.Body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range e.Headers {
+		if len(values) == 1 && values[0] == audit.Mask {
+			continue
+		}
+		req.Header[name] = values
+	}
+	return req, nil
+}
+
+// Replay reproduce la captura por el FormRequest y retorna el resultado de request.Validate
+func Replay(path string, fr request.FormRequest) error {
+	entry, err := Load(path)
+	if err != nil {
+		return err
+	}
+	req, err := entry.Request()
+	if err != nil {
+		return err
+	}
+	return request.Validate(fr, req)
+}
@@ -0,0 +1,305 @@
+package debug
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/donbarrigon/new-project/internal/audit"
+	"github.com/donbarrigon/new-project/internal/orm"
+	"github.com/donbarrigon/new-project/internal/request"
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// Inspector de solicitudes para desarrollo: guarda las ultimas solicitudes con el body, la url, las reglas
+// que se validaron y su resultado, las consultas a la base de datos y los tiempos, y se consultan en /_debug
+// Nunca se debe activar en produccion, el body y las cabeceras se enmascaran pero igual contienen datos de los usuarios
+//
+//	store := debug.NewStore(50)
+//	router.HandleFunc("/_debug", HandlerAdapter(debug.Handler(store), http.MethodGet))
+//	HandleFuncs("/users", user.PrivateRoutes(), middleware.Debug(store), middleware.Logger)
+//
+// las consultas se asocian a la solicitud por el contexto del modelo: user.WithContext(ctx.Request.Context()).Find(id)
+// y el controlador puede medir sus propias partes con debug.Measure
+
+// MaxBodySize bytes del body que se guardan, el resto se corta
+var MaxBodySize = 64 << 10
+
+// RedactedHeaders cabeceras que se enmascaran
+var RedactedHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "X-Api-Key", "Proxy-Authorization"}
+
+// Entry una solicitud registrada
+type Entry struct {
+	ID          string       `json:"id"`
+	Time        time.Time    `json:"time"`
+	Method      string       `json:"method"`
+	Path        string       `json:"path"`
+	Query       url.Values   `json:"query,omitempty"`
+	Headers     http.Header  `json:"headers"`
+	Body        string       `json:"body,omitempty"`
+	Status      int          `json:"status"`
+	Duration    Duration     `json:"duration"`
+	Validations []Validation `json:"validations,omitempty"`
+	Queries     []Query      `json:"queries,omitempty"`
+	Timings     []Timing     `json:"timings,omitempty"`
+}
+
+// Validation un FormRequest que se valido durante la solicitud
+type Validation struct {
+	Request  string                      `json:"request"`
+	Rules    map[string][]string         `json:"rules"`
+	Valid    bool                        `json:"valid"`
+	Errors   validation.ValidationErrors `json:"errors,omitempty"`
+	Error    string                      `json:"error,omitempty"`
+	Duration Duration                    `json:"duration"`
+}
+
+// Query una consulta a la base de datos
+type Query struct {
+	SQL      string   `json:"sql"`
+	Args     []any    `json:"args,omitempty"`
+	Error    string   `json:"error,omitempty"`
+	Duration Duration `json:"duration"`
+}
+
+// Timing una parte de la solicitud medida con Measure
+type Timing struct {
+	Name     string   `json:"name"`
+	Duration Duration `json:"duration"`
+}
+
+// Duration se muestra en milisegundos
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(float64(d) / float64(time.Millisecond))
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var ms float64
+	if err := json.Unmarshal(data, &ms); err != nil {
+		return err
+	}
+	*d = Duration(ms * float64(time.Millisecond))
+	return nil
+}
+
+// recorderKey llave del contexto donde se guarda lo que pasa durante la solicitud
+type recorderKey struct{}
+
+type recorder struct {
+	mu    sync.Mutex
+	entry Entry
+}
+
+var registerHooks sync.Once
+
+// Begin prepara la solicitud para registrar lo que pase en ella, lo usan los middlewares Debug y Record
+// la primera vez registra los hooks de request.OnValidation y orm.OnQuery
+func Begin(req *http.Request, body []byte) *http.Request {
+	registerHooks.Do(func() {
+		request.OnValidation(recordValidation)
+		orm.OnQuery(recordQuery)
+	})
+
+	// Debug y Record en la misma ruta comparten el registro
+	if _, ok := req.Context().Value(recorderKey{}).(*recorder); ok {
+		return req
+	}
+	rec := &recorder{entry: Entry{
+		ID:      newID(),
+		Time:    time.Now(),
+		Method:  req.Method,
+		Path:    req.URL.Path,
+		Query:   req.URL.Query(),
+		Headers: maskHeaders(req.Header),
+		Body:    maskBody(body),
+	}}
+	return req.WithContext(context.WithValue(req.Context(), recorderKey{}, rec))
+}
+
+// Finish retorna el registro de la solicitud con el status y la duracion
+func Finish(req *http.Request, status int, elapsed time.Duration) (Entry, bool) {
+	rec, ok := req.Context().Value(recorderKey{}).(*recorder)
+	if !ok {
+		return Entry{}, false
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.entry.Status = status
+	rec.entry.Duration = Duration(elapsed)
+	return rec.entry, true
+}
+
+// Measure mide una parte de la solicitud, se llama con defer
+//
+//	defer debug.Measure(ctx.Request.Context(), "generar pdf")()
+func Measure(ctx context.Context, name string) func() {
+	rec, ok := ctx.Value(recorderKey{}).(*recorder)
+	if !ok {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		rec.mu.Lock()
+		rec.entry.Timings = append(rec.entry.Timings, Timing{Name: name, Duration: Duration(time.Since(start))})
+		rec.mu.Unlock()
+	}
+}
+
+// recordValidation hook de request.OnValidation
+func recordValidation(req *http.Request, fr request.FormRequest, err error, elapsed time.Duration) {
+	rec, ok := req.Context().Value(recorderKey{}).(*recorder)
+	if !ok {
+		return
+	}
+	v := Validation{Request: typeName(fr), Rules: rulesOf(fr), Valid: err == nil, Duration: Duration(elapsed)}
+	if err != nil {
+		var verrs validation.ValidationErrors
+		if errors.As(err, &verrs) {
+			v.Errors = verrs
+		} else {
+			v.Error = err.Error()
+		}
+	}
+	rec.mu.Lock()
+	rec.entry.Validations = append(rec.entry.Validations, v)
+	rec.mu.Unlock()
+}
+
+// recordQuery hook de orm.OnQuery
+func recordQuery(ctx context.Context, query string, args []any, elapsed time.Duration, err error) {
+	rec, ok := ctx.Value(recorderKey{}).(*recorder)
+	if !ok {
+		return
+	}
+	q := Query{SQL: query, Args: args, Duration: Duration(elapsed)}
+	if err != nil {
+		q.Error = err.Error()
+	}
+	rec.mu.Lock()
+	rec.entry.Queries = append(rec.entry.Queries, q)
+	rec.mu.Unlock()
+}
+
+// rulesOf reglas del FormRequest por campo (name: [required, min:3])
+func rulesOf(fr request.FormRequest) map[string][]string {
+	fields, err := validation.Export(fr)
+	if err != nil {
+		return nil
+	}
+	out := make(map[string][]string, len(fields))
+	for _, f := range fields {
+		rules := make([]string, 0, len(f.Rules))
+		for _, r := range f.Rules {
+			if len(r.Params) > 0 {
+				rules = append(rules, r.Rule+":"+strings.Join(r.Params, ","))
+				continue
+			}
+			rules = append(rules, r.Rule)
+		}
+		out[f.Name] = rules
+	}
+	return out
+}
+
+// maskHeaders copia las cabeceras con las de RedactedHeaders enmascaradas
+func maskHeaders(h http.Header) http.Header {
+	out := h.Clone()
+	for _, name := range RedactedHeaders {
+		if _, ok := out[http.CanonicalHeaderKey(name)]; ok {
+			out.Set(name, audit.Mask)
+		}
+	}
+	return out
+}
+
+// maskBody enmascara los campos de audit.RedactedFields si el body es json y lo corta en MaxBodySize
+func maskBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	body = audit.MaskJSON(body, nil)
+	if len(body) > MaxBodySize {
+		return string(body[:MaxBodySize]) + "..."
+	}
+	return string(body)
+}
+
+// typeName nombre del tipo del FormRequest sin punteros (request.User)
+func typeName(v any) string {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return ""
+	}
+	return t.String()
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Store guarda las ultimas solicitudes en memoria
+type Store struct {
+	mu      sync.RWMutex
+	entries []Entry
+	next    int
+	full    bool
+}
+
+// NewStore crea un Store que guarda las ultimas size solicitudes
+func NewStore(size int) *Store {
+	if size < 1 {
+		size = 1
+	}
+	return &Store{entries: make([]Entry, size)}
+}
+
+// Add guarda la solicitud, si esta lleno reemplaza la mas vieja
+func (s *Store) Add(entry Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[s.next] = entry
+	s.next = (s.next + 1) % len(s.entries)
+	if s.next == 0 {
+		s.full = true
+	}
+}
+
+// Entries retorna las solicitudes guardadas, la mas reciente primero
+func (s *Store) Entries() []Entry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	n := s.next
+	if s.full {
+		n = len(s.entries)
+	}
+	out := make([]Entry, 0, n)
+	for i := 0; i < n; i++ {
+		out = append(out, s.entries[(s.next-1-i+len(s.entries))%len(s.entries)])
+	}
+	return out
+}
+
+// Get busca una solicitud por el id
+func (s *Store) Get(id string) (Entry, bool) {
+	for _, e := range s.Entries() {
+		if e.ID == id {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
@@ -0,0 +1,70 @@
+package debug
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+)
+
+// Handler muestra las solicitudes guardadas, en html para el navegador y en json para los demas clientes
+//
+//	GET /_debug           ultimas solicitudes
+//	GET /_debug?id=abc123 una solicitud
+func Handler(store *Store) controller.ControllerFunc {
+	return func(ctx *controller.Context) {
+		var data any = store.Entries()
+		if id := ctx.Request.URL.Query().Get("id"); id != "" {
+			entry, ok := store.Get(id)
+			if !ok {
+				ctx.ResponseError(http.StatusNotFound, "la solicitud ya no está guardada", nil)
+				return
+			}
+			data = []Entry{entry}
+		}
+
+		if !strings.Contains(ctx.Request.Header.Get("Accept"), "text/html") {
+			ctx.ResponseJSON(http.StatusOK, data)
+			return
+		}
+		ctx.Writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := page.Execute(ctx.Writer, data); err != nil {
+			http.Error(ctx.Writer, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
+
+// page pagina del navegador, cada solicitud se despliega con su detalle en json
+var page = template.Must(template.New("debug").Funcs(template.FuncMap{
+	"json": func(v any) string {
+		b, _ := json.MarshalIndent(v, "", "  ")
+		return string(b)
+	},
+}).Parse(`<!doctype html>
+<html lang="es">
+<head>
+<meta charset="utf-8">
+<title>Depuración</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem; }
+details { border-bottom: 1px solid #ddd; padding: .5rem 0; }
+summary { cursor: pointer; font-family: monospace; }
+.error { color: #b00020; }
+pre { background: #f6f6f6; padding: 1rem; overflow: auto; }
+</style>
+</head>
+<body>
+<h1>Últimas solicitudes</h1>
+{{range .}}
+<details>
+<summary{{if ge .Status 400}} class="error"{{end}}>{{.Time.Format "15:04:05"}} {{.Method}} {{.Path}} {{.Status}} · {{len .Validations}} validaciones · {{len .Queries}} consultas · <a href="?id={{.ID}}">{{.ID}}</a></summary>
+<pre>{{json .}}</pre>
+</details>
+{{else}}
+<p>No hay solicitudes registradas.</p>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,139 @@
+package hal
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	routesmaker "github.com/donbarrigon/new-project/internal/routes/maker"
+)
+
+// BaseURL si se define los links son absolutos (https://api.example.com), si no son relativos
+var BaseURL = ""
+
+// PageParam y PerPageParam parametros de la url que usan los links de paginacion
+var (
+	PageParam    = "page"
+	PerPageParam = "per_page"
+)
+
+// Link enlace HAL
+type Link struct {
+	Href      string `json:"href"`
+	Templated bool   `json:"templated,omitempty"`
+	Title     string `json:"title,omitempty"`
+}
+
+// Links enlaces por relacion (self, next, user, ...)
+type Links map[string]Link
+
+// Builder construye los links de una respuesta a partir de las rutas con nombre
+// los errores (ruta que no existe, parametro faltante) se acumulan y se retornan en Build
+//
+//	links, err := hal.NewBuilder(ctx.Request).
+//		Self().
+//		Route("orders", "user-orders", "id", user.ID).
+//		Pagination(page, perPage, total).
+//		Build()
+type Builder struct {
+	req   *http.Request
+	links Links
+	errs  []error
+}
+
+// NewBuilder crea el builder para el request actual
+func NewBuilder(req *http.Request) *Builder {
+	return &Builder{req: req, links: Links{}}
+}
+
+// Self agrega el link a la url actual
+func (b *Builder) Self() *Builder {
+	b.links["self"] = Link{Href: absolute(b.req.URL.RequestURI())}
+	return b
+}
+
+// Route agrega el link rel a la ruta con nombre, los parametros van en pares nombre, valor
+func (b *Builder) Route(rel string, name string, params ...string) *Builder {
+	href, err := routesmaker.URL(name, params...)
+	if err != nil {
+		b.errs = append(b.errs, err)
+		return b
+	}
+	b.links[rel] = Link{Href: absolute(href)}
+	return b
+}
+
+// Add agrega un link con la url escrita a mano
+func (b *Builder) Add(rel string, link Link) *Builder {
+	b.links[rel] = link
+	return b
+}
+
+// Pagination agrega first, prev, next y last sobre la url actual cambiando el parametro page
+func (b *Builder) Pagination(page int, perPage int, total int) *Builder {
+	if perPage <= 0 {
+		b.errs = append(b.errs, errors.New("per_page debe ser mayor que cero"))
+		return b
+	}
+	last := (total + perPage - 1) / perPage
+	if last < 1 {
+		last = 1
+	}
+	b.links["first"] = Link{Href: b.pageURL(1, perPage)}
+	b.links["last"] = Link{Href: b.pageURL(last, perPage)}
+	if page > 1 {
+		b.links["prev"] = Link{Href: b.pageURL(min(page-1, last), perPage)}
+	}
+	if page < last {
+		b.links["next"] = Link{Href: b.pageURL(page+1, perPage)}
+	}
+	return b
+}
+
+// Build retorna los links o el primer error
+func (b *Builder) Build() (Links, error) {
+	if len(b.errs) > 0 {
+		return nil, errors.Join(b.errs...)
+	}
+	return b.links, nil
+}
+
+// pageURL url actual con la pagina indicada
+func (b *Builder) pageURL(page int, perPage int) string {
+	query := b.req.URL.Query()
+	query.Set(PageParam, strconv.Itoa(page))
+	query.Set(PerPageParam, strconv.Itoa(perPage))
+	u := url.URL{Path: b.req.URL.Path, RawQuery: query.Encode()}
+	return absolute(u.String())
+}
+
+// absolute agrega BaseURL al link si esta definida
+func absolute(href string) string {
+	if BaseURL == "" {
+		return href
+	}
+	return BaseURL + href
+}
+
+// Resource une los campos del recurso con sus links (_links) y recursos embebidos (_embedded)
+//
+//	ctx.ResponseJSON(http.StatusOK, hal.Resource(user, links, nil))
+func Resource(data any, links Links, embedded map[string]any) (map[string]any, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	resource := map[string]any{}
+	if err := json.Unmarshal(raw, &resource); err != nil {
+		return nil, errors.New("hal: el recurso debe serializarse como un objeto json")
+	}
+	if len(links) > 0 {
+		resource["_links"] = links
+	}
+	if len(embedded) > 0 {
+		resource["_embedded"] = embedded
+	}
+	return resource, nil
+}
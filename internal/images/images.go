@@ -0,0 +1,253 @@
+package images
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/donbarrigon/new-project/internal/upload"
+	"github.com/donbarrigon/new-project/lib/storage"
+)
+
+// Procesamiento de las imagenes que ya se subieron (avatares, fotos de productos)
+// al abrir la imagen se aplica la orientacion EXIF de las fotos de los celulares y al guardarla
+// se vuelve a codificar, asi la imagen guardada nunca conserva los metadatos (ubicacion GPS, camara)
+//
+//	img, err := images.Open(ctx, form.File("avatar"))
+//	if err != nil { ... }
+//	thumb, err := img.Thumbnail(256, 256).Save(ctx, disk, "avatars/thumbs", images.JPEG)
+//
+// los formatos soportados son jpeg, png y gif
+
+// Formatos de salida
+const (
+	JPEG = "jpeg"
+	PNG  = "png"
+	GIF  = "gif"
+)
+
+// JPEGQuality calidad con la que se guardan los jpeg (1 a 100)
+var JPEGQuality = 85
+
+// MaxPixels cantidad maxima de pixeles (ancho x alto) que se aceptan al abrir una imagen
+// evita que un archivo pequeño que declara dimensiones enormes consuma toda la memoria
+var MaxPixels = 40_000_000
+
+// ErrTooLarge la imagen tiene mas pixeles que MaxPixels
+var ErrTooLarge = errors.New("la imagen tiene dimensiones demasiado grandes")
+
+// ErrUnsupported el archivo no es una imagen en un formato soportado
+var ErrUnsupported = errors.New("el formato de la imagen no es soportado")
+
+// Image imagen decodificada y el formato en que venia
+type Image struct {
+	img    image.Image
+	Format string
+}
+
+// Open decodifica el archivo subido y aplica su orientacion EXIF
+func Open(ctx context.Context, file *upload.UploadedFile) (*Image, error) {
+	r, err := file.Open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return Decode(r)
+}
+
+// Decode decodifica la imagen verificando sus dimensiones antes de cargarla en memoria
+func Decode(r io.Reader) (*Image, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > MaxPixels {
+		return nil, ErrTooLarge
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer la imagen: %w", err)
+	}
+	if format == JPEG {
+		img = orient(img, exifOrientation(data))
+	}
+	return &Image{img: img, Format: format}, nil
+}
+
+// New envuelve una imagen ya decodificada
+func New(img image.Image, format string) *Image {
+	return &Image{img: img, Format: format}
+}
+
+// Image retorna la imagen para procesarla con otros paquetes
+func (i *Image) Image() image.Image {
+	return i.img
+}
+
+// Width ancho en pixeles
+func (i *Image) Width() int {
+	return i.img.Bounds().Dx()
+}
+
+// Height alto en pixeles
+func (i *Image) Height() int {
+	return i.img.Bounds().Dy()
+}
+
+// Resize cambia el tamaño a width x height, si uno es 0 se calcula manteniendo la proporcion
+func (i *Image) Resize(width, height int) *Image {
+	w, h := i.Width(), i.Height()
+	switch {
+	case width <= 0 && height <= 0:
+		return i
+	case width <= 0:
+		width = max(1, w*height/h)
+	case height <= 0:
+		height = max(1, h*width/w)
+	}
+	return &Image{img: resample(i.img, width, height), Format: i.Format}
+}
+
+// Fit reduce la imagen para que quepa en width x height manteniendo la proporcion, nunca la agranda
+func (i *Image) Fit(width, height int) *Image {
+	w, h := i.Width(), i.Height()
+	if w <= width && h <= height {
+		return i
+	}
+	if w*height > h*width {
+		return i.Resize(width, 0)
+	}
+	return i.Resize(0, height)
+}
+
+// Crop recorta el rectangulo (en coordenadas desde la esquina superior izquierda)
+func (i *Image) Crop(rect image.Rectangle) *Image {
+	b := i.img.Bounds()
+	rect = rect.Add(b.Min).Intersect(b)
+	if rect.Empty() {
+		return i
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(dst, dst.Bounds(), i.img, rect.Min, draw.Src)
+	return &Image{img: dst, Format: i.Format}
+}
+
+// Thumbnail llena exactamente width x height: escala la imagen para cubrir el tamaño y recorta el centro
+func (i *Image) Thumbnail(width, height int) *Image {
+	w, h := i.Width(), i.Height()
+	var scaled *Image
+	if w*height > h*width {
+		scaled = i.Resize(0, height)
+	} else {
+		scaled = i.Resize(width, 0)
+	}
+	x := (scaled.Width() - width) / 2
+	y := (scaled.Height() - height) / 2
+	return scaled.Crop(image.Rect(x, y, x+width, y+height))
+}
+
+// Encode escribe la imagen en el formato indicado, vacio usa el formato original
+func (i *Image) Encode(w io.Writer, format string) error {
+	if format == "" {
+		format = i.Format
+	}
+	switch strings.ToLower(format) {
+	case JPEG, "jpg":
+		return jpeg.Encode(w, i.img, &jpeg.Options{Quality: JPEGQuality})
+	case PNG:
+		return png.Encode(w, i.img)
+	case GIF:
+		return gif.Encode(w, i.img, nil)
+	}
+	return ErrUnsupported
+}
+
+// Save codifica la imagen y la guarda en el disco con un nombre aleatorio dentro de dir
+func (i *Image) Save(ctx context.Context, disk storage.Disk, dir string, format string) (*upload.UploadedFile, error) {
+	if format == "" {
+		format = i.Format
+	}
+	if disk == nil {
+		disk = storage.Default
+	}
+	var buf bytes.Buffer
+	if err := i.Encode(&buf, format); err != nil {
+		return nil, err
+	}
+	mimeType := "image/" + normalizeFormat(format)
+	file := &upload.UploadedFile{Name: upload.RandomName(), MimeType: mimeType, Disk: disk}
+	file.Name += file.Extension()
+	file.Path = path.Join(dir, file.Name)
+	size, err := disk.Put(ctx, file.Path, &buf)
+	if err != nil {
+		return nil, err
+	}
+	file.Size = size
+	return file, nil
+}
+
+// Thumbnail crea la miniatura del archivo subido y la guarda en el mismo disco
+//
+//	thumb, err := images.Thumbnail(ctx, avatar, "avatars/thumbs", 128, 128)
+func Thumbnail(ctx context.Context, file *upload.UploadedFile, dir string, width, height int) (*upload.UploadedFile, error) {
+	img, err := Open(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	thumb, err := img.Thumbnail(width, height).Save(ctx, file.Disk, dir, "")
+	if err != nil {
+		return nil, err
+	}
+	thumb.Field = file.Field
+	return thumb, nil
+}
+
+// StripEXIF vuelve a codificar el archivo en su formato para quitar los metadatos, aplica antes la orientacion
+// el archivo se reemplaza en la misma ruta y se actualiza su tamaño
+func StripEXIF(ctx context.Context, file *upload.UploadedFile) error {
+	return Convert(ctx, file, "")
+}
+
+// Convert vuelve a codificar el archivo en otro formato (png -> jpeg) en la misma ruta
+// el nombre original no cambia pero MimeType y Size se actualizan
+func Convert(ctx context.Context, file *upload.UploadedFile, format string) error {
+	img, err := Open(ctx, file)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := img.Encode(&buf, format); err != nil {
+		return err
+	}
+	if format == "" {
+		format = img.Format
+	}
+	size, err := file.Disk.Put(ctx, file.Path, &buf)
+	if err != nil {
+		return err
+	}
+	file.Size = size
+	file.MimeType = "image/" + normalizeFormat(format)
+	return nil
+}
+
+func normalizeFormat(format string) string {
+	format = strings.ToLower(format)
+	if format == "jpg" {
+		return JPEG
+	}
+	return format
+}
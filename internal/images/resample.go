@@ -0,0 +1,100 @@
+package images
+
+import (
+	"image"
+	"image/draw"
+)
+
+// resample cambia el tamaño de la imagen: al reducir promedia los pixeles que caen en cada pixel nuevo
+// (evita el aliasing de tomar un solo pixel) y al agrandar interpola entre los cuatro mas cercanos
+func resample(src image.Image, width, height int) *image.RGBA {
+	in := toRGBA(src)
+	sw, sh := in.Bounds().Dx(), in.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	if width >= sw && height >= sh {
+		bilinear(in, dst)
+		return dst
+	}
+
+	for y := 0; y < height; y++ {
+		y0 := y * sh / height
+		y1 := max((y+1)*sh/height, y0+1)
+		for x := 0; x < width; x++ {
+			x0 := x * sw / width
+			x1 := max((x+1)*sw/width, x0+1)
+			var r, g, b, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				row := in.Pix[sy*in.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r += uint32(p[0])
+					g += uint32(p[1])
+					b += uint32(p[2])
+					a += uint32(p[3])
+					n++
+				}
+			}
+			o := dst.PixOffset(x, y)
+			dst.Pix[o] = uint8(r / n)
+			dst.Pix[o+1] = uint8(g / n)
+			dst.Pix[o+2] = uint8(b / n)
+			dst.Pix[o+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
+
+// bilinear interpola cada pixel de dst con los cuatro pixeles mas cercanos de src
+func bilinear(src *image.RGBA, dst *image.RGBA) {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := dst.Bounds().Dx(), dst.Bounds().Dy()
+	for y := 0; y < dh; y++ {
+		fy := (float64(y)+0.5)*float64(sh)/float64(dh) - 0.5
+		y0 := clamp(int(fy), sh)
+		y1 := clamp(y0+1, sh)
+		wy := fy - float64(y0)
+		if wy < 0 {
+			wy = 0
+		}
+		for x := 0; x < dw; x++ {
+			fx := (float64(x)+0.5)*float64(sw)/float64(dw) - 0.5
+			x0 := clamp(int(fx), sw)
+			x1 := clamp(x0+1, sw)
+			wx := fx - float64(x0)
+			if wx < 0 {
+				wx = 0
+			}
+			o := dst.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				p00 := float64(src.Pix[y0*src.Stride+x0*4+c])
+				p01 := float64(src.Pix[y0*src.Stride+x1*4+c])
+				p10 := float64(src.Pix[y1*src.Stride+x0*4+c])
+				p11 := float64(src.Pix[y1*src.Stride+x1*4+c])
+				top := p00 + (p01-p00)*wx
+				bottom := p10 + (p11-p10)*wx
+				dst.Pix[o+c] = uint8(top + (bottom-top)*wy + 0.5)
+			}
+		}
+	}
+}
+
+func clamp(v, n int) int {
+	if v < 0 {
+		return 0
+	}
+	if v >= n {
+		return n - 1
+	}
+	return v
+}
+
+// toRGBA convierte la imagen a RGBA con el origen en 0,0 para recorrer sus pixeles directamente
+func toRGBA(src image.Image) *image.RGBA {
+	if rgba, ok := src.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) {
+		return rgba
+	}
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+	return dst
+}
@@ -0,0 +1,107 @@
+package maintenance
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultPath archivo que indica que la aplicacion esta en mantenimiento
+const DefaultPath = "storage/framework/down"
+
+// State datos del modo mantenimiento
+type State struct {
+	Message string    `json:"message,omitempty"` // mensaje para los clientes
+	Retry   int       `json:"retry,omitempty"`   // segundos para la cabecera Retry-After
+	Secret  string    `json:"secret,omitempty"`  // token para saltarse el mantenimiento
+	Allow   []string  `json:"allow,omitempty"`   // rutas (prefijos) que siguen funcionando
+	Time    time.Time `json:"time"`              // cuando se activo
+}
+
+// Store guarda el estado del modo mantenimiento
+// el FileStore sirve cuando todas las instancias comparten el disco
+// implemente esta interfaz para guardarlo en redis, la base de datos, etc
+type Store interface {
+	// State retorna el estado actual o nil si la aplicacion esta arriba
+	State() (*State, error)
+	// Down activa el modo mantenimiento
+	Down(state State) error
+	// Up desactiva el modo mantenimiento
+	Up() error
+}
+
+// FileStore guarda el estado en un archivo json, si el archivo existe la aplicacion esta abajo
+// el archivo solo se vuelve a leer cuando cambia su fecha de modificacion
+type FileStore struct {
+	Path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	state   *State
+}
+
+// NewFileStore crea el store en el archivo indicado, si path es "" se usa DefaultPath
+func NewFileStore(path string) *FileStore {
+	if path == "" {
+		path = DefaultPath
+	}
+	return &FileStore{Path: path}
+}
+
+func (s *FileStore) State() (*State, error) {
+	info, err := os.Stat(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state != nil && info.ModTime().Equal(s.modTime) {
+		return s.state, nil
+	}
+
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	state := &State{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, state); err != nil {
+			return nil, err
+		}
+	}
+	s.state = state
+	s.modTime = info.ModTime()
+	return state, nil
+}
+
+func (s *FileStore) Down(state State) error {
+	if state.Time.IsZero() {
+		state.Time = time.Now()
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.Path, data, 0o644)
+}
+
+func (s *FileStore) Up() error {
+	err := os.Remove(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/donbarrigon/new-project/internal/audit"
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/internal/request"
+)
+
+// FloodMode que hacer con un envio repetido
+type FloodMode int
+
+const (
+	// RejectDuplicates responde 409 a los envios repetidos
+	RejectDuplicates FloodMode = iota
+	// CoalesceDuplicates los envios repetidos esperan a que termine el primero y reciben su misma respuesta
+	CoalesceDuplicates
+)
+
+// FloodIdentity identifica a quien envia el formulario: el usuario autenticado o su ip
+// reemplacela si los usuarios se identifican de otra forma (api key, sesion)
+var FloodIdentity = func(ctx *controller.Context) string {
+	if actor := audit.Actor(ctx); actor != "" {
+		return "user:" + actor
+	}
+	return "ip:" + request.ClientIP(ctx.Request)
+}
+
+// floodPoll cada cuanto revisa un envio repetido si el primero ya termino
+const floodPoll = 50 * time.Millisecond
+
+// Antiflood protege los endpoints que no son idempotentes del doble clic: si el mismo usuario envia
+// exactamente el mismo body al mismo endpoint dentro de window, el segundo envio se rechaza o recibe
+// la respuesta del primero segun mode. Los envios distintos pasan normal
+// el store puede ser el mismo de Idempotency, las llaves no se mezclan. las reservas no se liberan,
+// vencen con window y el store debe eliminarlas (MemoryIdempotencyStore las limpia al crecer)
+//
+//	HandleFuncs("/orders", order.PrivateRoutes(), middleware.Antiflood(store, 5*time.Second, middleware.CoalesceDuplicates))
+func Antiflood(store IdempotencyStore, window time.Duration, mode FloodMode) MiddlewareFunc {
+	return func(next controller.ControllerFunc) controller.ControllerFunc {
+		return func(ctx *controller.Context) {
+			if isSafeMethod(ctx.Request.Method) {
+				next(ctx)
+				return
+			}
+			body, err := request.ReadBody(ctx.Request)
+			if err != nil {
+				ctx.ResponseError(http.StatusBadRequest, "No se pudo leer el body", nil)
+				return
+			}
+			fingerprint := requestFingerprint(ctx.Request, body)
+			key := "flood " + FloodIdentity(ctx) + " " + fingerprint
+
+			// el lock dura toda la ventana, asi el mismo envio se detecta aunque el primero ya haya terminado
+			if store.Lock(key, window) {
+				if mode == RejectDuplicates {
+					next(ctx)
+					return
+				}
+				recorder := newResponseRecorder(ctx.Writer)
+				ctx.Writer = recorder
+				next(ctx)
+				ctx.Writer = recorder.ResponseWriter
+				store.Set(key, &StoredResponse{
+					StatusCode:  recorder.Status(),
+					Header:      recorder.Header().Clone(),
+					Body:        recorder.body.Bytes(),
+					Fingerprint: fingerprint,
+				}, window)
+				return
+			}
+
+			if mode == CoalesceDuplicates && waitFlood(ctx, store, key, window, fingerprint) {
+				return
+			}
+			ctx.Writer.Header().Set("Retry-After", strconv.Itoa(max(1, int(window.Seconds()))))
+			ctx.ResponseError(http.StatusConflict, "La misma solicitud se envió hace un momento", nil)
+		}
+	}
+}
+
+// waitFlood espera la respuesta del primer envio y la repite, retorna false si no llega a tiempo
+func waitFlood(ctx *controller.Context, store IdempotencyStore, key string, window time.Duration, fingerprint string) bool {
+	deadline := time.NewTimer(window)
+	defer deadline.Stop()
+	ticker := time.NewTicker(floodPoll)
+	defer ticker.Stop()
+	for {
+		if stored, ok := store.Get(key); ok {
+			replay(ctx, stored, fingerprint)
+			return true
+		}
+		select {
+		case <-ctx.Request.Context().Done():
+			return false
+		case <-deadline.C:
+			return false
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+)
+
+func TestAntifloodLocksExpire(t *testing.T) {
+	store := NewMemoryIdempotencyStore()
+	handler := Antiflood(store, 5*time.Millisecond, RejectDuplicates)(func(ctx *controller.Context) {
+		ctx.Writer.WriteHeader(http.StatusCreated)
+	})
+	send := func(body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		handler(controller.NewContext(rec, req))
+		return rec.Code
+	}
+
+	if send(`{"n":0}`) != http.StatusCreated || send(`{"n":0}`) != http.StatusConflict {
+		t.Fatalf("el envio repetido dentro de la ventana debe responder 409")
+	}
+	// un body distinto por solicitud, cada una deja su reserva en el store
+	for i := 1; i <= memoryStorePrune; i++ {
+		send(fmt.Sprintf(`{"n":%d}`, i))
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	if send(`{"n":0}`) != http.StatusCreated {
+		t.Errorf("pasada la ventana el mismo envio debe procesarse")
+	}
+	if len(store.locks) != 1 {
+		t.Errorf("las reservas vencidas de Antiflood se deben eliminar, quedan %d", len(store.locks))
+	}
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/donbarrigon/new-project/internal/audit"
+	"github.com/donbarrigon/new-project/internal/controller"
+)
+
+// Audit registra quien, que y cuando de las solicitudes que modifican datos (POST, PUT, PATCH, DELETE)
+// se guarda un registro por cada FormRequest que se valido con exito y solo si la respuesta no es un error
+// los cambios se calculan contra el estado anterior que el controlador indique con audit.Before
+//
+//	sink, _ := audit.NewFileSink("storage/logs/audit.log")
+//	HandleFuncs("/users", user.PrivateRoutes(), middleware.Audit(sink))
+func Audit(sink audit.Sink) MiddlewareFunc {
+	return func(next controller.ControllerFunc) controller.ControllerFunc {
+		return func(ctx *controller.Context) {
+			if isSafeMethod(ctx.Request.Method) {
+				next(ctx)
+				return
+			}
+
+			ctx.Request = audit.WithRecorder(ctx.Request)
+			recorder := newResponseRecorder(ctx.Writer)
+			ctx.Writer = recorder
+			next(ctx)
+			ctx.Writer = recorder.ResponseWriter
+
+			if recorder.Status() >= http.StatusBadRequest {
+				return
+			}
+			// el contexto del request ya puede estar cancelado, el registro no debe perderse por eso
+			writeCtx := context.WithoutCancel(ctx.Request.Context())
+			for _, entry := range audit.Entries(ctx, recorder.Status()) {
+				if err := sink.Write(writeCtx, entry); err != nil {
+					log.Printf("Error guardando la auditoria: %v", err)
+				}
+			}
+		}
+	}
+}
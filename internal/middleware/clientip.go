@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/internal/request"
+)
+
+// ClientIP resuelve la ip real del cliente una vez por request segun los proxies de confianza
+// los siguientes middlewares (logger, limites de peticiones) la leen con request.ClientIP
+// los proxies se configuran al iniciar con request.SetTrustedProxies
+func ClientIP(next controller.ControllerFunc) controller.ControllerFunc {
+	return func(ctx *controller.Context) {
+		ctx.Request = request.WithClientIP(ctx.Request)
+		next(ctx)
+	}
+}
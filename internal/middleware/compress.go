@@ -0,0 +1,262 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/lib/brotli"
+)
+
+// Encoder compresor que se puede reutilizar con Reset
+// gzip.Writer y brotli.Writer lo cumplen, para otro compresor registrelo con RegisterEncoder
+type Encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// encoding compresor registrado con su pool
+type encoding struct {
+	name string
+	pool *sync.Pool
+}
+
+var (
+	// encodings en orden de preferencia cuando el cliente acepta varios con la misma q
+	encodings   []encoding
+	encodingsMu sync.RWMutex
+)
+
+func init() {
+	RegisterEncoder("gzip", func(w io.Writer) Encoder {
+		gz, _ := gzip.NewWriterLevel(w, gzip.DefaultCompression)
+		return gz
+	})
+	RegisterEncoder("br", func(w io.Writer) Encoder {
+		return brotli.NewWriter(w)
+	})
+}
+
+// RegisterEncoder registra un compresor para el Content-Encoding name
+// los registrados despues tienen prioridad, asi br se prefiere sobre gzip cuando el cliente acepta los dos
+// registrar un nombre que ya existe lo reemplaza (otra implementacion de br) sin cambiar su prioridad
+//
+//	middleware.RegisterEncoder("zstd", func(w io.Writer) middleware.Encoder {
+//		enc, _ := zstd.NewWriter(w)
+//		return enc
+//	})
+func RegisterEncoder(name string, newEncoder func(w io.Writer) Encoder) {
+	encodingsMu.Lock()
+	defer encodingsMu.Unlock()
+	e := encoding{name: name, pool: &sync.Pool{New: func() any { return newEncoder(io.Discard) }}}
+	for i := range encodings {
+		if encodings[i].name == name {
+			encodings[i] = e
+			return
+		}
+	}
+	encodings = append([]encoding{e}, encodings...)
+}
+
+// DefaultCompressTypes tipos de contenido que se comprimen si no se indican otros
+var DefaultCompressTypes = []string{"application/json", "application/problem+json", "text/"}
+
+// Compress comprime las respuestas con el encoding que acepte el cliente (Accept-Encoding)
+// solo se comprimen las respuestas de minSize bytes o mas y cuyo Content-Type empiece por uno de types
+// (por defecto DefaultCompressTypes), las pequeñas se envian tal cual porque comprimirlas no ahorra nada
+// el encoding se elige por la q de Accept-Encoding entre los registrados (br y gzip),
+// q=0 lo rechaza y * aplica a los que no se nombran
+//
+//	HandleFuncs("/users", user.PublicRoutes(), middleware.Compress(1024))
+func Compress(minSize int, types ...string) MiddlewareFunc {
+	if len(types) == 0 {
+		types = DefaultCompressTypes
+	}
+	return func(next controller.ControllerFunc) controller.ControllerFunc {
+		return func(ctx *controller.Context) {
+			ctx.Writer.Header().Add("Vary", "Accept-Encoding")
+
+			enc, ok := negotiateEncoding(ctx.Request.Header.Get("Accept-Encoding"))
+			if !ok || ctx.Request.Method == http.MethodHead {
+				next(ctx)
+				return
+			}
+
+			cw := &compressWriter{ResponseWriter: ctx.Writer, enc: enc, minSize: minSize, types: types}
+			ctx.Writer = cw
+			defer func() {
+				cw.close()
+				ctx.Writer = cw.ResponseWriter
+			}()
+			next(ctx)
+		}
+	}
+}
+
+// negotiateEncoding elige el encoding registrado con mayor q en Accept-Encoding
+func negotiateEncoding(accept string) (encoding, bool) {
+	if accept == "" {
+		return encoding{}, false
+	}
+	q := make(map[string]float64)
+	for _, part := range strings.Split(accept, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		value := 1.0
+		if p, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(p, 64); err == nil {
+				value = f
+			}
+		}
+		q[strings.ToLower(strings.TrimSpace(name))] = value
+	}
+
+	encodingsMu.RLock()
+	defer encodingsMu.RUnlock()
+	var best encoding
+	bestQ := 0.0
+	for _, e := range encodings {
+		value, ok := q[e.name]
+		if !ok {
+			value, ok = q["*"]
+		}
+		if ok && value > bestQ {
+			best, bestQ = e, value
+		}
+	}
+	return best, bestQ > 0
+}
+
+// compressWriter guarda los primeros bytes hasta saber si vale la pena comprimir
+type compressWriter struct {
+	http.ResponseWriter
+	enc     encoding
+	minSize int
+	types   []string
+
+	status  int
+	buf     []byte
+	decided bool
+	encoder Encoder
+}
+
+func (c *compressWriter) WriteHeader(statusCode int) {
+	if informational(statusCode) {
+		c.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+	if c.status == 0 {
+		c.status = statusCode
+	}
+}
+
+func (c *compressWriter) Write(b []byte) (int, error) {
+	if c.status == 0 {
+		c.status = http.StatusOK
+	}
+	if !c.decided {
+		c.buf = append(c.buf, b...)
+		if len(c.buf) >= c.minSize {
+			if err := c.decide(); err != nil {
+				return 0, err
+			}
+		}
+		return len(b), nil
+	}
+	if c.encoder != nil {
+		return c.encoder.Write(b)
+	}
+	return c.ResponseWriter.Write(b)
+}
+
+// Flush envia lo que hay hasta el momento, sirve para respuestas en streaming
+func (c *compressWriter) Flush() {
+	if !c.decided {
+		c.decide()
+	}
+	if c.encoder != nil {
+		c.encoder.Flush()
+	}
+	http.NewResponseController(c.ResponseWriter).Flush()
+}
+
+// Unwrap permite a http.ResponseController llegar al writer original
+func (c *compressWriter) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
+
+// decide escribe las cabeceras y decide si la respuesta se comprime
+func (c *compressWriter) decide() error {
+	c.decided = true
+	if c.status == 0 {
+		c.status = http.StatusOK
+	}
+
+	header := c.ResponseWriter.Header()
+	if header.Get("Content-Type") == "" && len(c.buf) > 0 {
+		header.Set("Content-Type", http.DetectContentType(c.buf))
+	}
+
+	if c.shouldCompress(header) {
+		header.Set("Content-Encoding", c.enc.name)
+		header.Del("Content-Length")
+		// el body comprimido ya no es identico byte a byte al que describe un ETag fuerte
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			header.Set("ETag", "W/"+etag)
+		}
+		c.ResponseWriter.WriteHeader(c.status)
+		c.encoder = c.enc.pool.Get().(Encoder)
+		c.encoder.Reset(c.ResponseWriter)
+		_, err := c.encoder.Write(c.buf)
+		c.buf = nil
+		return err
+	}
+
+	c.ResponseWriter.WriteHeader(c.status)
+	_, err := c.ResponseWriter.Write(c.buf)
+	c.buf = nil
+	return err
+}
+
+// shouldCompress revisa el tamaño, el status y el Content-Type de la respuesta. Las respuestas
+// parciales (206 o con Content-Range) no se comprimen: el rango se refiere al body sin comprimir
+func (c *compressWriter) shouldCompress(header http.Header) bool {
+	if len(c.buf) < c.minSize || header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+	if c.status < http.StatusOK || c.status == http.StatusNoContent || c.status == http.StatusPartialContent || c.status == http.StatusNotModified {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	for _, t := range c.types {
+		if strings.HasPrefix(mediaType, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// close termina la respuesta y devuelve el encoder al pool
+func (c *compressWriter) close() {
+	if !c.decided {
+		if c.status == 0 && len(c.buf) == 0 {
+			// el controlador no escribio nada
+			return
+		}
+		c.decide()
+	}
+	if c.encoder != nil {
+		c.encoder.Close()
+		c.encoder.Reset(io.Discard)
+		c.enc.pool.Put(c.encoder)
+		c.encoder = nil
+	}
+}
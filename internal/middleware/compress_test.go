@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+)
+
+// fakeEncoder encoder de prueba que no comprime
+type fakeEncoder struct{ w io.Writer }
+
+func (f *fakeEncoder) Write(b []byte) (int, error) { return f.w.Write(b) }
+func (f *fakeEncoder) Close() error                { return nil }
+func (f *fakeEncoder) Flush() error                { return nil }
+func (f *fakeEncoder) Reset(w io.Writer)           { f.w = w }
+
+func TestNegotiateEncodingQValues(t *testing.T) {
+	cases := []struct {
+		accept string
+		want   string // "" sin comprimir
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"GZIP", "gzip"},
+		{"gzip;q=0", ""},
+		{"gzip; q=0.5", "gzip"},
+		{"*", "br"},
+		{"*;q=0", ""},
+		{"gzip;q=0, *", "br"},
+		{"gzip;q=0, br;q=0, *", ""},
+		{"identity", ""},
+		{"br", "br"},
+		{"gzip, br", "br"},
+		{"br;q=1, gzip;q=0.2", "br"},
+		{"br;q=0, gzip", "gzip"},
+		{"gzip;q=abc", "gzip"},
+	}
+	for _, c := range cases {
+		enc, ok := negotiateEncoding(c.accept)
+		if got := map[bool]string{true: enc.name}[ok]; got != c.want {
+			t.Errorf("Accept-Encoding %q: se obtuvo %q, se esperaba %q", c.accept, got, c.want)
+		}
+	}
+}
+
+func TestNegotiateEncodingPrefersRegisteredOnTies(t *testing.T) {
+	previous := slices.Clone(encodings)
+	t.Cleanup(func() { encodings = previous })
+	RegisterEncoder("zz", func(w io.Writer) Encoder { return &fakeEncoder{w: w} })
+
+	cases := []struct {
+		accept string
+		want   string
+	}{
+		{"gzip, zz", "zz"},
+		{"zz;q=0.5, gzip", "gzip"},
+		{"zz;q=0, br;q=0, *", "gzip"},
+		{"*", "zz"},
+	}
+	for _, c := range cases {
+		enc, ok := negotiateEncoding(c.accept)
+		if !ok || enc.name != c.want {
+			t.Errorf("Accept-Encoding %q: se obtuvo %q (%v), se esperaba %q", c.accept, enc.name, ok, c.want)
+		}
+	}
+}
+
+func TestCompress(t *testing.T) {
+	body := strings.Repeat(`{"name":"ana"}`, 100)
+	cases := []struct {
+		name     string
+		accept   string
+		body     string
+		encoding string // "" sin comprimir
+	}{
+		{"gzip", "gzip", body, "gzip"},
+		{"rechazado con q=0", "gzip;q=0", body, ""},
+		{"br", "br", body, "br"},
+		{"br antes que gzip", "gzip, br", body, "br"},
+		{"pequeña", "gzip", `{"name":"ana"}`, ""},
+	}
+	for _, c := range cases {
+		handler := Compress(1024)(func(ctx *controller.Context) {
+			ctx.Writer.Header().Set("Content-Type", "application/json")
+			ctx.Writer.Write([]byte(c.body))
+		})
+		req := httptest.NewRequest(http.MethodGet, "/users", nil)
+		req.Header.Set("Accept-Encoding", c.accept)
+		rec := httptest.NewRecorder()
+		handler(controller.NewContext(rec, req))
+
+		got := rec.Body.String()
+		switch c.encoding {
+		case "br":
+			// la descompresion de br se prueba en lib/brotli
+			if rec.Header().Get("Content-Encoding") != "br" || rec.Body.Len() >= len(c.body)/10 {
+				t.Fatalf("%s: se esperaba el body comprimido con br, se obtuvo %v %d bytes", c.name, rec.Header(), rec.Body.Len())
+			}
+			got = c.body
+		case "gzip":
+			if rec.Header().Get("Content-Encoding") != "gzip" {
+				t.Fatalf("%s: se esperaba Content-Encoding gzip, se obtuvo %v", c.name, rec.Header())
+			}
+			gz, err := gzip.NewReader(rec.Body)
+			if err != nil {
+				t.Fatalf("%s: %v", c.name, err)
+			}
+			b, _ := io.ReadAll(gz)
+			got = string(b)
+		default:
+			if rec.Header().Get("Content-Encoding") != "" {
+				t.Errorf("%s: no se esperaba Content-Encoding, se obtuvo %v", c.name, rec.Header())
+			}
+		}
+		if got != c.body {
+			t.Errorf("%s: el body no coincide", c.name)
+		}
+		if rec.Header().Get("Vary") != "Accept-Encoding" {
+			t.Errorf("%s: falta Vary: Accept-Encoding", c.name)
+		}
+	}
+}
+
+func TestCompressPartialContentAndETag(t *testing.T) {
+	body := strings.Repeat(`{"name":"ana"}`, 100)
+	cases := []struct {
+		name       string
+		status     int
+		header     map[string]string
+		compressed bool
+		etag       string
+	}{
+		{"206", http.StatusPartialContent, map[string]string{"Content-Range": "bytes 0-1399/5000"}, false, ""},
+		{"200 con Content-Range", http.StatusOK, map[string]string{"Content-Range": "bytes 0-1399/1400"}, false, ""},
+		{"ETag fuerte", http.StatusOK, map[string]string{"ETag": `"v1"`}, true, `W/"v1"`},
+		{"ETag debil", http.StatusOK, map[string]string{"ETag": `W/"v1"`}, true, `W/"v1"`},
+	}
+	for _, c := range cases {
+		handler := Compress(1024)(func(ctx *controller.Context) {
+			ctx.Writer.Header().Set("Content-Type", "application/json")
+			for k, v := range c.header {
+				ctx.Writer.Header().Set(k, v)
+			}
+			ctx.Writer.WriteHeader(c.status)
+			ctx.Writer.Write([]byte(body))
+		})
+		req := httptest.NewRequest(http.MethodGet, "/users", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		rec := httptest.NewRecorder()
+		handler(controller.NewContext(rec, req))
+
+		if compressed := rec.Header().Get("Content-Encoding") != ""; compressed != c.compressed {
+			t.Errorf("%s: se esperaba comprimido=%v, se obtuvo %v", c.name, c.compressed, rec.Header())
+		}
+		if !c.compressed && rec.Body.String() != body {
+			t.Errorf("%s: el body parcial se debe enviar sin cambios", c.name)
+		}
+		if c.etag != "" && rec.Header().Get("ETag") != c.etag {
+			t.Errorf("%s: se esperaba ETag %s, se obtuvo %s", c.name, c.etag, rec.Header().Get("ETag"))
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/donbarrigon/new-project/internal/contract"
+	"github.com/donbarrigon/new-project/internal/controller"
+)
+
+// Contract valida las respuestas exitosas (2xx) en json del controlador contra el contrato de la ruta
+// solo con contract.Enabled (desarrollo y pruebas), si no el controlador escribe directo y no se revisa nada
+// con contract.Strict la respuesta que no cumple se reemplaza por un 500 con los errores del contrato
+//
+//	HandleFuncs("/users", user.PrivateRoutes(), middleware.Contract(contract.Rules(&UserResource{})))
+//
+// normalmente se define por ruta con el campo Contract de routesmaker.Route
+func Contract(c contract.Contract) MiddlewareFunc {
+	return func(next controller.ControllerFunc) controller.ControllerFunc {
+		return func(ctx *controller.Context) {
+			if !contract.Enabled {
+				next(ctx)
+				return
+			}
+
+			// la respuesta se guarda en memoria hasta revisarla
+			w := ctx.Writer
+			buffered := newTimeoutWriter(w)
+			ctx.Writer = buffered
+			next(ctx)
+			ctx.Writer = w
+
+			status := buffered.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			if status < 200 || status > 299 || buffered.body.Len() == 0 || !isJSON(buffered.header.Get("Content-Type")) {
+				buffered.flush()
+				return
+			}
+			err := c.Check(ctx.Request.Context(), buffered.body.Bytes())
+			if err == nil {
+				buffered.flush()
+				return
+			}
+
+			violation := &contract.Violation{Method: ctx.Request.Method, Path: ctx.Request.URL.Path, Status: status, Err: err}
+			contract.Report(ctx.Request, violation)
+			if contract.Strict {
+				ctx.ResponseErr(http.StatusInternalServerError, violation.Error(), violation)
+				return
+			}
+			buffered.header.Set(contract.ViolationHeader, "true")
+			buffered.flush()
+		}
+	}
+}
+
+// isJSON indica si el Content-Type es json (application/json, application/vnd.api+json, application/problem+json)
+func isJSON(contentType string) bool {
+	media, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return media == "application/json" || strings.HasSuffix(media, "+json")
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/internal/debug"
+	"github.com/donbarrigon/new-project/internal/request"
+)
+
+// Debug registra cada solicitud en el store de la barra de depuracion (ver debug.Handler en /_debug)
+// solo para desarrollo, en el router se activa con APP_DEBUG=true
+//
+//	HandleFuncs("/users", user.PrivateRoutes(), middleware.Debug(store), middleware.Logger)
+func Debug(store *debug.Store) MiddlewareFunc {
+	return func(next controller.ControllerFunc) controller.ControllerFunc {
+		return func(ctx *controller.Context) {
+			start := time.Now()
+			// el body queda disponible para que el controlador lo vuelva a leer
+			body, _ := request.ReadBody(ctx.Request)
+			ctx.Request = debug.Begin(ctx.Request, body)
+
+			recorder := newResponseRecorder(ctx.Writer)
+			ctx.Writer = recorder
+			next(ctx)
+			ctx.Writer = recorder.ResponseWriter
+
+			if entry, ok := debug.Finish(ctx.Request, recorder.Status(), time.Since(start)); ok {
+				store.Add(entry)
+			}
+		}
+	}
+}
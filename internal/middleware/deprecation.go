@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/internal/request"
+)
+
+// Deprecation agrega las cabeceras Deprecation y Sunset (RFC 8594) a las respuestas de las solicitudes
+// que enviaron campos obsoletos (tag deprecated de los FormRequest), Sunset es la fecha mas cercana de los campos.
+// Los campos se listan en la cabecera Warning para que el desarrollador del cliente sepa que cambiar
+//
+//	HandleFuncs("/users", user.PrivateRoutes(), middleware.Deprecation)
+func Deprecation(next controller.ControllerFunc) controller.ControllerFunc {
+	registerDeprecationHook.Do(func() {
+		request.OnDeprecated(func(req *http.Request, fr request.FormRequest, field request.DeprecatedField) {
+			if rec, ok := req.Context().Value(deprecationKey{}).(*deprecationRecorder); ok {
+				rec.mu.Lock()
+				rec.fields = append(rec.fields, field)
+				rec.mu.Unlock()
+			}
+		})
+	})
+	return func(ctx *controller.Context) {
+		rec := &deprecationRecorder{}
+		ctx.Request = ctx.Request.WithContext(context.WithValue(ctx.Request.Context(), deprecationKey{}, rec))
+		writer := &deprecationWriter{ResponseWriter: ctx.Writer, rec: rec}
+		ctx.Writer = writer
+		next(ctx)
+		ctx.Writer = writer.ResponseWriter
+	}
+}
+
+var registerDeprecationHook sync.Once
+
+// deprecationKey llave del contexto con los campos obsoletos de la solicitud
+type deprecationKey struct{}
+
+type deprecationRecorder struct {
+	mu     sync.Mutex
+	fields []request.DeprecatedField
+}
+
+// deprecationWriter escribe las cabeceras antes de la primera escritura del controlador
+type deprecationWriter struct {
+	http.ResponseWriter
+	rec     *deprecationRecorder
+	written bool
+}
+
+func (w *deprecationWriter) WriteHeader(statusCode int) {
+	if !informational(statusCode) {
+		w.setHeaders()
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *deprecationWriter) Write(b []byte) (int, error) {
+	w.setHeaders()
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap permite a http.ResponseController llegar al writer original
+func (w *deprecationWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *deprecationWriter) setHeaders() {
+	if w.written {
+		return
+	}
+	w.written = true
+	w.rec.mu.Lock()
+	defer w.rec.mu.Unlock()
+	if len(w.rec.fields) == 0 {
+		return
+	}
+
+	header := w.Header()
+	header.Set("Deprecation", "true")
+	var sunset time.Time
+	names := make([]string, 0, len(w.rec.fields))
+	for _, f := range w.rec.fields {
+		names = append(names, f.Name+": "+f.Message)
+		if !f.Sunset.IsZero() && (sunset.IsZero() || f.Sunset.Before(sunset)) {
+			sunset = f.Sunset
+		}
+	}
+	if !sunset.IsZero() {
+		header.Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+	}
+	header.Set("Warning", `299 - "campos obsoletos: `+strings.ReplaceAll(strings.Join(names, "; "), `"`, `'`)+`"`)
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/internal/request"
+)
+
+// DryRun responde las solicitudes de solo validacion (?dry_run=1, X-Validate-Only: true o Precognition: true) sin llegar al controlador:
+// ejecuta Authorize, el llenado de los campos y las reglas del FormRequest de la ruta y responde
+// 204 si los datos son validos, 422 con los errores, 403 si no esta autorizado, 429 si supero el limite
+// o 500 si algo entro en panico.
+// Las demas solicitudes pasan al controlador sin cambios
+//
+//	{Path: "/users", Methods: AllowMethods(POST),
+//		Handler: middleware.DryRun(func() request.FormRequest { return &CreateUser{} })(user.Store)}
+func DryRun(factory func() request.FormRequest) MiddlewareFunc {
+	return func(next controller.ControllerFunc) controller.ControllerFunc {
+		return func(ctx *controller.Context) {
+			if !request.WantsDryRun(ctx.Request) {
+				next(ctx)
+				return
+			}
+			ctx.Request = request.WithDryRun(ctx.Request)
+
+			precognitive := request.IsPrecognitive(ctx.Request)
+			if precognitive {
+				ctx.Request = request.WithPrecognition(ctx.Request)
+				header := ctx.Writer.Header()
+				header.Set(request.PrecognitionHeader, "true")
+				header.Add("Vary", request.PrecognitionHeader)
+			}
+			err := request.Validate(factory(), ctx.Request)
+			if err == nil {
+				if precognitive {
+					ctx.Writer.Header().Set(request.PrecognitionSuccessHeader, "true")
+				}
+				ctx.ResponseNoContent()
+				return
+			}
+			var terr *request.ThrottleError
+			status := request.ErrorStatus(err)
+			switch {
+			case status == http.StatusUnprocessableEntity:
+				ctx.ResponseErr(status, "los datos no son válidos", err)
+			case status >= http.StatusInternalServerError:
+				log.Printf("DryRun %s %s: %v", ctx.Request.Method, ctx.Request.URL.Path, err)
+				ctx.ResponseErr(status, "error interno del servidor", err)
+			default:
+				if errors.As(err, &terr) {
+					ctx.Writer.Header().Set("Retry-After", strconv.Itoa(terr.RetryAfterSeconds()))
+				}
+				ctx.ResponseErr(status, err.Error(), err)
+			}
+		}
+	}
+}
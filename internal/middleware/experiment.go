@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/internal/pkg/experiment"
+	"github.com/donbarrigon/new-project/internal/request"
+	"github.com/donbarrigon/new-project/lib/ids"
+)
+
+// ExperimentCookie cookie con el id del visitante para los experimentos por cookie
+var ExperimentCookie = "ab_id"
+
+// Experiments guarda en el contexto las llaves del visitante (usuario, ip y cookie) para repartir los experimentos
+// los controladores y las reglas piden la variante con experiment.Variant, si el visitante no tiene la cookie
+// se le crea una que dura un año. debe ir despues de ClientIP y AuthPlaceholders
+//
+//	HandleFuncs("/checkout", checkout.Routes(), middleware.ClientIP, middleware.AuthPlaceholders, middleware.Experiments)
+func Experiments(next controller.ControllerFunc) controller.ControllerFunc {
+	return func(ctx *controller.Context) {
+		identity := experiment.Identity{IP: request.ClientIP(ctx.Request), User: request.AuthID(ctx.Request.Context())}
+		if cookie, err := ctx.Request.Cookie(ExperimentCookie); err == nil && cookie.Value != "" {
+			identity.Cookie = cookie.Value
+		} else {
+			identity.Cookie = ids.NewKSUID().String()
+			http.SetCookie(ctx.Writer, &http.Cookie{
+				Name:     ExperimentCookie,
+				Value:    identity.Cookie,
+				Path:     "/",
+				MaxAge:   365 * 24 * 60 * 60,
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+		ctx.Request = ctx.Request.WithContext(experiment.WithIdentity(ctx.Request.Context(), identity))
+		next(ctx)
+	}
+}
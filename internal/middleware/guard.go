@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/internal/request"
+)
+
+// IPAllowlist solo deja pasar las solicitudes de las redes indicadas, CIDRs (10.0.0.0/8) o ips sueltas
+// la ip es la de request.ClientIP, con proxies delante se debe configurar request.SetTrustedProxies
+// entra en panico si alguna red es invalida, es un error de configuracion que se debe ver al iniciar
+//
+//	HandleFuncs("/admin", admin.Routes(), middleware.ClientIP, middleware.IPAllowlist("10.0.0.0/8", "127.0.0.1"))
+func IPAllowlist(networks ...string) MiddlewareFunc {
+	nets, err := request.ParseNetworks(networks...)
+	if err != nil {
+		panic("middleware.IPAllowlist: " + err.Error())
+	}
+	return func(next controller.ControllerFunc) controller.ControllerFunc {
+		return func(ctx *controller.Context) {
+			ip := net.ParseIP(request.ClientIP(ctx.Request))
+			for _, n := range nets {
+				if ip != nil && n.Contains(ip) {
+					next(ctx)
+					return
+				}
+			}
+			ctx.ResponseError(http.StatusForbidden, "Acceso no permitido desde esta dirección", nil)
+		}
+	}
+}
+
+// BearerToken exige la cabecera Authorization: Bearer con alguno de los tokens, sirve para rutas de operacion
+// que usan herramientas sin sesion (go tool pprof, curl). con varios tokens se pueden rotar sin cortar el acceso
+// sin tokens (la variable de entorno vacia) ninguna solicitud pasa
+//
+//	HandleFuncs("/_ops", ops, middleware.BearerToken(os.Getenv("OPS_TOKEN")))
+func BearerToken(tokens ...string) MiddlewareFunc {
+	return func(next controller.ControllerFunc) controller.ControllerFunc {
+		return func(ctx *controller.Context) {
+			given, ok := strings.CutPrefix(ctx.Request.Header.Get("Authorization"), "Bearer ")
+			if !ok || given == "" {
+				ctx.Writer.Header().Set("WWW-Authenticate", "Bearer")
+				ctx.ResponseError(http.StatusUnauthorized, "Se requiere un token de acceso", nil)
+				return
+			}
+			for _, token := range tokens {
+				if token != "" && subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1 {
+					next(ctx)
+					return
+				}
+			}
+			ctx.ResponseError(http.StatusForbidden, "El token de acceso no es válido", nil)
+		}
+	}
+}
+
+// Authorize deja pasar la solicitud si allow retorna true, responde 401 si no hay usuario autenticado
+// (request.WithAuth o ctx.User) y 403 si lo hay pero allow lo rechaza. debe ir despues del middleware que autentica
+//
+//	middleware.Authorize(func(ctx *controller.Context) bool { return isAdmin(ctx.User) })
+func Authorize(allow func(ctx *controller.Context) bool) MiddlewareFunc {
+	return func(next controller.ControllerFunc) controller.ControllerFunc {
+		return func(ctx *controller.Context) {
+			if _, ok := request.Auth(ctx.Request.Context()); !ok && ctx.User == nil {
+				ctx.ResponseError(http.StatusUnauthorized, "Se requiere iniciar sesión", nil)
+				return
+			}
+			if !allow(ctx) {
+				ctx.ResponseError(http.StatusForbidden, "No tiene permiso para esta acción", nil)
+				return
+			}
+			next(ctx)
+		}
+	}
+}
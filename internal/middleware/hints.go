@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+)
+
+// EarlyHints envia 103 Early Hints con los recursos de la pagina antes de ejecutar el controlador
+// solo en GET y HEAD, las demas solicitudes no cargan paginas
+//
+//	HandleFuncs("/", web.Routes(), middleware.EarlyHints(
+//		controller.Preload{URL: "/assets/app.css", As: "style"},
+//		controller.Preload{URL: "/assets/app.js", As: "script"},
+//	))
+//
+// tambien se puede definir por ruta con el campo Preload de routesmaker.Route
+func EarlyHints(links ...controller.Preload) MiddlewareFunc {
+	return func(next controller.ControllerFunc) controller.ControllerFunc {
+		return func(ctx *controller.Context) {
+			if ctx.Request.Method == http.MethodGet || ctx.Request.Method == http.MethodHead {
+				ctx.EarlyHints(links...)
+			}
+			next(ctx)
+		}
+	}
+}
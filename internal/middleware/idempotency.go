@@ -0,0 +1,214 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/internal/request"
+	"github.com/donbarrigon/new-project/internal/tenant"
+)
+
+// IdempotencyHeader cabecera con la que el cliente identifica la operacion
+const IdempotencyHeader = "Idempotency-Key"
+
+// StoredResponse respuesta guardada para una Idempotency-Key
+type StoredResponse struct {
+	StatusCode  int
+	Header      http.Header
+	Body        []byte
+	Fingerprint string // hash del metodo, la ruta y el body con el que se creo la respuesta
+}
+
+// IdempotencyStore almacenamiento de las respuestas por key
+// implemente esta interfaz para guardar las respuestas en redis, la base de datos, etc
+type IdempotencyStore interface {
+	// Get retorna la respuesta guardada para la key
+	Get(key string) (*StoredResponse, bool)
+	// Set guarda la respuesta de la key por el tiempo ttl
+	Set(key string, res *StoredResponse, ttl time.Duration)
+	// Lock reserva la key mientras se procesa la primera solicitud, retorna false si ya estaba reservada
+	Lock(key string, ttl time.Duration) bool
+	// Unlock libera la reserva de la key
+	Unlock(key string)
+}
+
+// IdempotencyIdentity dueño de las keys: el tenant del request y el usuario o la ip (FloodIdentity)
+// asi otro usuario que envie la misma key no recibe la respuesta guardada del primero
+var IdempotencyIdentity = func(ctx *controller.Context) string {
+	identity := FloodIdentity(ctx)
+	if t, ok := tenant.FromRequest(ctx.Request); ok {
+		identity = "tenant:" + t.ID + " " + identity
+	}
+	return identity
+}
+
+// Idempotency middleware que hace seguros los reintentos de los POST
+// la primera solicitud con una Idempotency-Key se procesa normal y se guarda su respuesta
+// los reintentos con la misma key del mismo usuario (IdempotencyIdentity) reciben la respuesta guardada sin volver a ejecutar el controlador
+// si la key se reutiliza con otro body se responde 422 y si la primera aun se esta procesando 409
+// las respuestas 5xx no se guardan para que el cliente pueda reintentar
+//
+//	HandleFuncs("/orders", order.PrivateRoutes(), middleware.Idempotency(middleware.NewMemoryIdempotencyStore(), 24*time.Hour))
+func Idempotency(store IdempotencyStore, ttl time.Duration) MiddlewareFunc {
+	return func(next controller.ControllerFunc) controller.ControllerFunc {
+		return func(ctx *controller.Context) {
+			key := ctx.Request.Header.Get(IdempotencyHeader)
+			if key == "" || isSafeMethod(ctx.Request.Method) {
+				next(ctx)
+				return
+			}
+
+			body, err := request.ReadBody(ctx.Request)
+			if err != nil {
+				ctx.ResponseError(http.StatusBadRequest, "No se pudo leer el body", nil)
+				return
+			}
+			fingerprint := requestFingerprint(ctx.Request, body)
+			storeKey := "idempotency " + IdempotencyIdentity(ctx) + " " + ctx.Request.Method + " " + ctx.Request.URL.Path + " " + key
+
+			if stored, ok := store.Get(storeKey); ok {
+				replay(ctx, stored, fingerprint)
+				return
+			}
+
+			if !store.Lock(storeKey, ttl) {
+				// puede que la primera haya terminado entre el Get y el Lock
+				if stored, ok := store.Get(storeKey); ok {
+					replay(ctx, stored, fingerprint)
+					return
+				}
+				ctx.ResponseError(http.StatusConflict, "Una solicitud con esta Idempotency-Key se está procesando", nil)
+				return
+			}
+			defer store.Unlock(storeKey)
+
+			recorder := newResponseRecorder(ctx.Writer)
+			ctx.Writer = recorder
+			next(ctx)
+			ctx.Writer = recorder.ResponseWriter
+
+			if recorder.Status() < http.StatusInternalServerError {
+				store.Set(storeKey, &StoredResponse{
+					StatusCode:  recorder.Status(),
+					Header:      recorder.Header().Clone(),
+					Body:        recorder.body.Bytes(),
+					Fingerprint: fingerprint,
+				}, ttl)
+			}
+		}
+	}
+}
+
+// replay responde con la respuesta guardada
+func replay(ctx *controller.Context, stored *StoredResponse, fingerprint string) {
+	if stored.Fingerprint != fingerprint {
+		ctx.ResponseError(http.StatusUnprocessableEntity, "La Idempotency-Key ya se usó con una solicitud diferente", nil)
+		return
+	}
+	header := ctx.Writer.Header()
+	for k, v := range stored.Header {
+		header[k] = v
+	}
+	header.Set("Idempotent-Replayed", "true")
+	ctx.Writer.WriteHeader(stored.StatusCode)
+	ctx.Writer.Write(stored.Body)
+}
+
+// requestFingerprint hash del metodo, la ruta y el body
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method))
+	h.Write([]byte(r.URL.Path))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// isSafeMethod los metodos que no modifican nada no necesitan idempotency key
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// MemoryIdempotencyStore almacenamiento en memoria, sirve para una sola instancia de la aplicacion
+// las respuestas y reservas vencidas se limpian al guardar cuando el store crece (ver prune)
+type MemoryIdempotencyStore struct {
+	mu        sync.Mutex
+	responses map[string]memoryEntry
+	locks     map[string]time.Time
+}
+
+type memoryEntry struct {
+	res     *StoredResponse
+	expires time.Time
+}
+
+// NewMemoryIdempotencyStore crea el almacenamiento en memoria
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		responses: make(map[string]memoryEntry),
+		locks:     make(map[string]time.Time),
+	}
+}
+
+func (s *MemoryIdempotencyStore) Get(key string) (*StoredResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.responses[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(s.responses, key)
+		return nil, false
+	}
+	return entry.res, true
+}
+
+func (s *MemoryIdempotencyStore) Set(key string, res *StoredResponse, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune()
+	s.responses[key] = memoryEntry{res: res, expires: time.Now().Add(ttl)}
+}
+
+func (s *MemoryIdempotencyStore) Lock(key string, ttl time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if expires, ok := s.locks[key]; ok && time.Now().Before(expires) {
+		return false
+	}
+	s.prune()
+	s.locks[key] = time.Now().Add(ttl)
+	return true
+}
+
+func (s *MemoryIdempotencyStore) Unlock(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.locks, key)
+}
+
+// memoryStorePrune cantidad de entradas a partir de la cual se limpian las vencidas
+const memoryStorePrune = 10000
+
+// prune elimina las respuestas y reservas vencidas para que los maps no crezcan sin limite
+// (Antiflood nunca libera sus reservas, vencen con la ventana), se llama con el mutex tomado
+func (s *MemoryIdempotencyStore) prune() {
+	if len(s.responses)+len(s.locks) <= memoryStorePrune {
+		return
+	}
+	now := time.Now()
+	for k, entry := range s.responses {
+		if now.After(entry.expires) {
+			delete(s.responses, k)
+		}
+	}
+	for k, expires := range s.locks {
+		if now.After(expires) {
+			delete(s.locks, k)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/internal/tenant"
+)
+
+func TestIdempotencyKeyIsPerCaller(t *testing.T) {
+	calls := 0
+	handler := Idempotency(NewMemoryIdempotencyStore(), time.Hour)(func(ctx *controller.Context) {
+		calls++
+		ctx.Writer.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(ctx.Writer, "orden %d", calls)
+	})
+	send := func(ip string, tenants ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(`{"total":10}`))
+		req.RemoteAddr = ip + ":1234"
+		if len(tenants) > 0 {
+			req = req.WithContext(tenant.WithTenant(req.Context(), &tenant.Tenant{ID: tenants[0]}))
+		}
+		req.Header.Set(IdempotencyHeader, "abc")
+		rec := httptest.NewRecorder()
+		handler(controller.NewContext(rec, req))
+		return rec
+	}
+
+	first := send("10.0.0.1")
+	retry := send("10.0.0.1")
+	if retry.Body.String() != first.Body.String() || retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("el reintento del mismo cliente debe recibir la respuesta guardada, se obtuvo %q", retry.Body.String())
+	}
+	other := send("10.0.0.2")
+	if other.Body.String() == first.Body.String() || other.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("otro cliente con la misma key no debe recibir la respuesta del primero, se obtuvo %q", other.Body.String())
+	}
+	acme := send("10.0.0.1", "acme")
+	if acme.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("la misma key en otro tenant no debe recibir la respuesta guardada, se obtuvo %q", acme.Body.String())
+	}
+	if calls != 3 {
+		t.Errorf("se esperaban 3 llamadas al controlador, se obtuvieron %d", calls)
+	}
+}
+
+func TestMemoryIdempotencyStorePrunes(t *testing.T) {
+	s := NewMemoryIdempotencyStore()
+	for i := 0; i <= memoryStorePrune; i++ {
+		key := fmt.Sprintf("flood %d", i)
+		s.Lock(key, time.Nanosecond)
+		s.Set(key, &StoredResponse{StatusCode: http.StatusOK}, time.Nanosecond)
+	}
+	time.Sleep(time.Millisecond)
+
+	s.Lock("nueva", time.Hour)
+	s.Set("nueva", &StoredResponse{StatusCode: http.StatusOK}, time.Hour)
+	if len(s.locks) != 1 || len(s.responses) != 1 {
+		t.Errorf("las entradas vencidas se deben eliminar, quedan %d reservas y %d respuestas", len(s.locks), len(s.responses))
+	}
+	if _, ok := s.Get("nueva"); !ok {
+		t.Errorf("la entrada vigente no se debe eliminar")
+	}
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/internal/request"
+	"github.com/donbarrigon/new-project/lib/ctxkey"
+)
+
+// LatencyBudget mide cada solicitud contra su presupuesto de latencia: el que declara el FormRequest
+// (request.LatencyBudgetRequest) o budget si no declara ninguno, 0 solo mide los FormRequest con presupuesto.
+// al terminar avisa a request.OnLatency con los tiempos de lectura, validacion y controlador
+// y la respuesta lleva la cabecera Server-Timing con los tiempos de lectura y validacion
+//
+//	HandleFuncs("/search", search.Routes(), middleware.LatencyBudget(500*time.Millisecond))
+func LatencyBudget(budget time.Duration) MiddlewareFunc {
+	return func(next controller.ControllerFunc) controller.ControllerFunc {
+		return func(ctx *controller.Context) {
+			rec := request.NewLatencyRecorder(budget)
+			ctx.Request = ctxkey.SetRequest(ctx.Request, request.LatencyKey, rec)
+			writer := &latencyWriter{ResponseWriter: ctx.Writer, rec: rec}
+			ctx.Writer = writer
+			next(ctx)
+			ctx.Writer = writer.ResponseWriter
+			rec.Finish(ctx.Request)
+		}
+	}
+}
+
+// latencyWriter escribe Server-Timing antes de la primera escritura del controlador
+type latencyWriter struct {
+	http.ResponseWriter
+	rec     *request.LatencyRecorder
+	written bool
+}
+
+func (w *latencyWriter) WriteHeader(statusCode int) {
+	if !informational(statusCode) {
+		w.setHeaders()
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *latencyWriter) Write(b []byte) (int, error) {
+	w.setHeaders()
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap permite a http.ResponseController llegar al writer original
+func (w *latencyWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *latencyWriter) setHeaders() {
+	if w.written {
+		return
+	}
+	w.written = true
+	t := w.rec.Timings()
+	if t.Decode == 0 && t.Validate == 0 {
+		return
+	}
+	w.Header().Add("Server-Timing", fmt.Sprintf("decode;dur=%.2f, validate;dur=%.2f", millis(t.Decode), millis(t.Validate)))
+}
+
+// millis duracion en milisegundos con decimales, el formato de Server-Timing
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/internal/request"
+	"golang.org/x/text/language"
+)
+
+// Locale negocia el idioma de la solicitud con Accept-Language entre los idiomas soportados
+// (request.SupportedLocales si no se indican) y lo guarda con request.WithLocale: los mensajes de validacion
+// (validation.RegisterMessages), RulesFor y el formato de las respuestas lo leen con request.Locale(ctx)
+// responde el idioma elegido en Content-Language, si ninguno coincide se usa request.DefaultLocale
+//
+//	HandleFuncs("/users", user.Routes(), middleware.Locale(language.Spanish, language.English))
+func Locale(supported ...language.Tag) MiddlewareFunc {
+	return func(next controller.ControllerFunc) controller.ControllerFunc {
+		return func(ctx *controller.Context) {
+			locales := supported
+			if len(locales) == 0 {
+				locales = request.SupportedLocales
+			}
+			locale, ok := request.NegotiateLocale(ctx.Request.Header.Get("Accept-Language"), locales)
+			if !ok {
+				locale = request.DefaultLocale
+			}
+			ctx.Request = request.WithLocale(ctx.Request, locale)
+			header := ctx.Writer.Header()
+			header.Set("Content-Language", locale.String())
+			header.Add("Vary", "Accept-Language")
+			next(ctx)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/internal/maintenance"
+)
+
+// MaintenanceBypassHeader cabecera con el token para saltarse el modo mantenimiento
+const MaintenanceBypassHeader = "Maintenance-Bypass"
+
+// Maintenance responde 503 con Retry-After mientras la aplicacion esta en mantenimiento
+// las rutas de allow (prefijos) y las rutas que se pasaron con --allow al comando down siguen funcionando
+// quien envie el secret de down en la cabecera Maintenance-Bypass puede usar toda la aplicacion
+//
+//	go run ./cmd/cli down --retry=60 --secret=mi-token --allow=/health
+//	go run ./cmd/cli up
+func Maintenance(store maintenance.Store, allow ...string) MiddlewareFunc {
+	return func(next controller.ControllerFunc) controller.ControllerFunc {
+		return func(ctx *controller.Context) {
+			state, err := store.State()
+			if err != nil {
+				// si no se puede leer el estado se deja pasar, es peor tumbar la aplicacion
+				log.Printf("Error leyendo el modo mantenimiento: %v", err)
+				next(ctx)
+				return
+			}
+			if state == nil || canBypass(ctx.Request, state, allow) {
+				next(ctx)
+				return
+			}
+
+			if state.Retry > 0 {
+				ctx.Writer.Header().Set("Retry-After", strconv.Itoa(state.Retry))
+			}
+			message := state.Message
+			if message == "" {
+				message = "La aplicación está en mantenimiento"
+			}
+			ctx.ResponseError(http.StatusServiceUnavailable, message, nil)
+		}
+	}
+}
+
+// canBypass indica si el request puede pasar aunque la aplicacion este en mantenimiento
+func canBypass(r *http.Request, state *maintenance.State, allow []string) bool {
+	if state.Secret != "" {
+		token := r.Header.Get(MaintenanceBypassHeader)
+		if subtle.ConstantTimeCompare([]byte(token), []byte(state.Secret)) == 1 {
+			return true
+		}
+	}
+	for _, list := range [][]string{allow, state.Allow} {
+		for _, prefix := range list {
+			if prefix != "" && strings.HasPrefix(r.URL.Path, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -2,17 +2,21 @@ package middleware
 
 import (
 	"fmt"
+	"log"
+	"time"
 
 	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/internal/request"
 )
 
 type MiddlewareFunc func(controller.ControllerFunc) controller.ControllerFunc
 
+// Logger registra cada solicitud con la ip del cliente (request.ClientIP) y lo que tardo
 func Logger(next controller.ControllerFunc) controller.ControllerFunc {
 	return func(ctx *controller.Context) {
-		fmt.Printf("Before %s %s\n", ctx.Request.Method, ctx.Request.URL.Path)
+		start := time.Now()
 		next(ctx)
-		fmt.Printf("After %s %s\n", ctx.Request.Method, ctx.Request.URL.Path)
+		log.Printf("%s %s %s %s", request.ClientIP(ctx.Request), ctx.Request.Method, ctx.Request.URL.Path, time.Since(start))
 	}
 }
 
@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/internal/request"
+)
+
+// AuthPlaceholders deja el usuario autenticado (ctx.User) disponible en las reglas como {auth.campo}
+// debe ir despues del middleware que autentica, sin usuario los marcadores quedan vacios
+func AuthPlaceholders(next controller.ControllerFunc) controller.ControllerFunc {
+	return func(ctx *controller.Context) {
+		if ctx.User != nil && len(ctx.User.Data) > 0 {
+			ctx.Request = request.WithAuth(ctx.Request, ctx.User.Data[0])
+		}
+		next(ctx)
+	}
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/internal/debug"
+	"github.com/donbarrigon/new-project/internal/request"
+)
+
+// Record guarda en archivos las solicitudes de las rutas donde se usa para reproducirlas despues (ver debug.Replay)
+// when decide que solicitudes se guardan, nil guarda solo las que fallaron la validacion (422).
+// Se activa a proposito en las rutas donde los usuarios reportan errores, el body y las cabeceras se enmascaran
+//
+//	HandleFuncs("/orders", order.Routes(), middleware.Record(debug.NewFileRecorder("storage/captures"), nil))
+func Record(recorder *debug.FileRecorder, when func(entry debug.Entry) bool) MiddlewareFunc {
+	if when == nil {
+		when = func(entry debug.Entry) bool { return entry.Status == http.StatusUnprocessableEntity }
+	}
+	return func(next controller.ControllerFunc) controller.ControllerFunc {
+		return func(ctx *controller.Context) {
+			start := time.Now()
+			body, _ := request.ReadBody(ctx.Request)
+			ctx.Request = debug.Begin(ctx.Request, body)
+
+			writer := newResponseRecorder(ctx.Writer)
+			ctx.Writer = writer
+			next(ctx)
+			ctx.Writer = writer.ResponseWriter
+
+			entry, ok := debug.Finish(ctx.Request, writer.Status(), time.Since(start))
+			if !ok || !when(entry) {
+				return
+			}
+			if _, err := recorder.Save(entry); err != nil {
+				log.Printf("Error guardando la captura de %s %s: %v", entry.Method, entry.Path, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/internal/request"
+	"github.com/donbarrigon/new-project/lib/ids"
+)
+
+// RequestID asigna un id a cada solicitud y lo responde en la cabecera X-Request-Id
+// si el proxy o el cliente ya envian uno valido (hasta 128 caracteres imprimibles) se usa ese para seguir la solicitud
+// entre servicios, si no se crea un ULID. los demas lo leen con request.RequestID(ctx)
+// tambien guarda la cabecera traceparent (W3C Trace Context) si es valida, ver request.CorrelationFrom
+func RequestID(next controller.ControllerFunc) controller.ControllerFunc {
+	return func(ctx *controller.Context) {
+		id := ctx.Request.Header.Get(request.RequestIDHeader)
+		if !validRequestID(id) {
+			id = ids.NewULID().String()
+		}
+		ctx.Request = request.WithRequestID(ctx.Request, id)
+		if tp := ctx.Request.Header.Get(request.TraceParentHeader); validTraceParent(tp) {
+			ctx.Request = request.WithTraceParent(ctx.Request, tp)
+		}
+		ctx.Writer.Header().Set(request.RequestIDHeader, id)
+		next(ctx)
+	}
+}
+
+// validRequestID acepta ids cortos sin espacios ni caracteres de control para no ensuciar los logs
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// validTraceParent revisa el formato version-trace_id-parent_id-flags (00-<32 hex>-<16 hex>-<2 hex>)
+// sin ids en cero, que la especificacion declara invalidos
+func validTraceParent(tp string) bool {
+	if len(tp) != 55 || tp[2] != '-' || tp[35] != '-' || tp[52] != '-' {
+		return false
+	}
+	zero := func(s string) bool { return strings.Trim(s, "0") == "" }
+	for i, part := range []string{tp[:2], tp[3:35], tp[36:52], tp[53:]} {
+		for j := 0; j < len(part); j++ {
+			c := part[j]
+			if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+				return false
+			}
+		}
+		if (i == 1 || i == 2) && zero(part) {
+			return false
+		}
+	}
+	return tp[:2] != "ff"
+}
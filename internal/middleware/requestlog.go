@@ -0,0 +1,219 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/donbarrigon/new-project/internal/audit"
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/internal/debug"
+	"github.com/donbarrigon/new-project/internal/request"
+	"github.com/donbarrigon/new-project/lib/ctxkey"
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// RequestLogConfig configuracion del log de solicitudes
+type RequestLogConfig struct {
+	Output  io.Writer // donde se escriben las lineas json, os.Stdout si es nil
+	Percent float64   // porcentaje de solicitudes que se registran (0 a 100)
+	// Errors registra siempre las respuestas 5xx aunque no caigan en la muestra (sin los bodies)
+	Errors bool
+	// Bodies registra el body de la solicitud y de la respuesta de las solicitudes de la muestra
+	Bodies bool
+	// MaxBody bytes de cada body que se escriben en el log, 2 KB si es cero
+	MaxBody int
+	// MaxCapture bytes que se leen de cada body para enmascararlo, 64 KB si es cero
+	// un body json mas grande no se puede enmascarar completo y no se registra
+	MaxCapture int
+	// Headers cabeceras de la solicitud que se registran, User-Agent, Referer y Content-Type si es nil
+	// las de debug.RedactedHeaders se enmascaran
+	Headers []string
+}
+
+// RequestLogEntry linea del log de solicitudes
+type RequestLogEntry struct {
+	Time         time.Time         `json:"time"`
+	RequestID    string            `json:"request_id,omitempty"`
+	Method       string            `json:"method"`
+	Path         string            `json:"path"`
+	Query        string            `json:"query,omitempty"`
+	Status       int               `json:"status"`
+	DurationMS   float64           `json:"duration_ms"`
+	IP           string            `json:"ip"`
+	User         string            `json:"user,omitempty"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	RequestBody  string            `json:"request_body,omitempty"`
+	ResponseBody string            `json:"response_body,omitempty"`
+	ResponseSize int64             `json:"response_size"`
+	Sampled      bool              `json:"sampled"`
+}
+
+// sensitiveKey llave del contexto con los campos sensibles de los FormRequest de la solicitud
+var sensitiveKey = ctxkey.New[*sensitiveRecorder]("request_log_sensitive")
+
+type sensitiveRecorder struct {
+	mu     sync.Mutex
+	fields []string
+}
+
+var registerSensitiveHook sync.Once
+
+// RequestLog escribe una linea json por solicitud para el sistema de logs (Loki, Elasticsearch, CloudWatch)
+// con una muestra de Percent% del trafico, opcionalmente con los bodies cortados en MaxBody
+// los bodies pasan por la misma politica de datos sensibles del audit: audit.RedactedFields en cualquier nivel
+// y los campos sensitive o Hidden de los FormRequest de la solicitud, se hayan validado con exito o no
+// (un 422 o una solicitud de solo validar tambien se enmascaran). solo se registran bodies json
+//
+//	HandleFuncs("/orders", order.Routes(), middleware.RequestID, middleware.RequestLog(middleware.RequestLogConfig{
+//		Percent: 5, Errors: true, Bodies: true,
+//	}))
+func RequestLog(cfg RequestLogConfig) MiddlewareFunc {
+	if cfg.Output == nil {
+		cfg.Output = os.Stdout
+	}
+	if cfg.MaxBody <= 0 {
+		cfg.MaxBody = 2 << 10
+	}
+	if cfg.MaxCapture <= 0 {
+		cfg.MaxCapture = 64 << 10
+	}
+	if cfg.Headers == nil {
+		cfg.Headers = []string{"User-Agent", "Referer", "Content-Type"}
+	}
+	var mu sync.Mutex
+
+	return func(next controller.ControllerFunc) controller.ControllerFunc {
+		return func(ctx *controller.Context) {
+			sampled := cfg.Percent >= 100 || (cfg.Percent > 0 && rand.Float64()*100 < cfg.Percent)
+			if !sampled && !cfg.Errors {
+				next(ctx)
+				return
+			}
+
+			start := time.Now()
+			var body []byte
+			if sampled && cfg.Bodies {
+				if isJSON(ctx.Request.Header.Get("Content-Type")) {
+					body, _ = request.ReadBody(ctx.Request)
+				}
+				registerSensitiveHook.Do(func() {
+					request.OnValidation(func(r *http.Request, fr request.FormRequest, _ error, _ time.Duration) {
+						if rec, ok := ctxkey.Get(r.Context(), sensitiveKey); ok {
+							rec.mu.Lock()
+							rec.fields = append(rec.fields, validation.SensitiveFields(fr)...)
+							rec.mu.Unlock()
+						}
+					})
+				})
+				ctx.Request = ctxkey.SetRequest(ctx.Request, sensitiveKey, &sensitiveRecorder{})
+			}
+
+			writer := newResponseRecorder(ctx.Writer)
+			writer.limit = cfg.MaxCapture
+			if !sampled || !cfg.Bodies {
+				writer.limit = -1
+			}
+			ctx.Writer = writer
+			next(ctx)
+			ctx.Writer = writer.ResponseWriter
+
+			status := writer.Status()
+			if !sampled && status < 500 {
+				return
+			}
+
+			entry := RequestLogEntry{
+				Time:         start.UTC(),
+				RequestID:    request.RequestID(ctx.Request.Context()),
+				Method:       ctx.Request.Method,
+				Path:         ctx.Request.URL.Path,
+				Query:        maskQuery(ctx.Request.URL.Query()),
+				Status:       status,
+				DurationMS:   float64(time.Since(start).Microseconds()) / 1000,
+				IP:           request.ClientIP(ctx.Request),
+				User:         request.AuthID(ctx.Request.Context()),
+				Headers:      logHeaders(ctx.Request.Header, cfg.Headers),
+				ResponseSize: writer.size,
+				Sampled:      sampled,
+			}
+			if sampled && cfg.Bodies {
+				var sensitive []string
+				if rec, ok := ctxkey.Get(ctx.Request.Context(), sensitiveKey); ok {
+					rec.mu.Lock()
+					sensitive = rec.fields
+					rec.mu.Unlock()
+				}
+				if len(body) <= cfg.MaxCapture {
+					entry.RequestBody = logBody(body, ctx.Request.Header.Get("Content-Type"), sensitive, cfg.MaxBody)
+				}
+				if writer.size <= int64(cfg.MaxCapture) {
+					entry.ResponseBody = logBody(writer.body.Bytes(), writer.Header().Get("Content-Type"), sensitive, cfg.MaxBody)
+				}
+			}
+
+			line, err := json.Marshal(entry)
+			if err != nil {
+				log.Printf("request_log: %v", err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			cfg.Output.Write(append(line, '\n'))
+		}
+	}
+}
+
+// logBody enmascara el body json y lo corta en max, los bodies que no son json no se registran
+func logBody(body []byte, contentType string, sensitive []string, max int) string {
+	if len(body) == 0 {
+		return ""
+	}
+	if !isJSON(contentType) {
+		return ""
+	}
+	masked := audit.MaskJSON(body, sensitive)
+	if len(masked) > max {
+		return string(masked[:max]) + "..."
+	}
+	return string(masked)
+}
+
+// maskQuery parametros de la url con los sensibles enmascarados (?token=...)
+func maskQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	for key := range query {
+		if audit.IsRedacted(key, nil) {
+			query[key] = []string{audit.Mask}
+		}
+	}
+	return query.Encode()
+}
+
+// logHeaders cabeceras que se registran, las de debug.RedactedHeaders enmascaradas
+func logHeaders(header http.Header, names []string) map[string]string {
+	out := make(map[string]string, len(names))
+	for _, name := range names {
+		value := header.Get(name)
+		if value == "" {
+			continue
+		}
+		for _, redacted := range debug.RedactedHeaders {
+			if strings.EqualFold(name, redacted) {
+				value = audit.Mask
+				break
+			}
+		}
+		out[name] = value
+	}
+	return out
+}
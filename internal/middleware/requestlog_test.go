@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/internal/request"
+)
+
+type pinForm struct {
+	request.Request
+	Name string `json:"name" rules:"required"`
+	Pin  string `json:"pin" rules:"required|digits:4" sensitive:""`
+}
+
+func (f *pinForm) PrepareForValidation() error { return nil }
+func (f *pinForm) WithValidator() error        { return nil }
+
+func TestRequestLogMasksSensitiveFieldsOfFailedValidations(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+	}{
+		{"valido", `{"name":"ana","pin":"1234"}`},
+		{"invalido", `{"name":"","pin":"1234"}`},
+	}
+	for _, c := range cases {
+		var out bytes.Buffer
+		handler := RequestLog(RequestLogConfig{Output: &out, Percent: 100, Bodies: true})(func(ctx *controller.Context) {
+			if err := request.Validate(&pinForm{}, ctx.Request); err != nil {
+				ctx.ResponseError(http.StatusUnprocessableEntity, "Los datos no son válidos", nil)
+				return
+			}
+			ctx.ResponseJSON(http.StatusOK, map[string]any{"ok": true})
+		})
+		req := httptest.NewRequest(http.MethodPost, "/pins", strings.NewReader(c.body))
+		req.Header.Set("Content-Type", "application/json")
+		handler(controller.NewContext(httptest.NewRecorder(), req))
+
+		var entry RequestLogEntry
+		if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if strings.Contains(entry.RequestBody, "1234") || !strings.Contains(entry.RequestBody, "pin") {
+			t.Errorf("%s: el pin se debe enmascarar, se registro %s", c.name, entry.RequestBody)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/internal/request"
+)
+
+// RewriteBody normaliza el body de la ruta antes de que request.Validate lo deserialice
+// se usa en las rutas que reciben clientes viejos con payloads distintos (booleanos como texto, sobres)
+//
+//	{Path: "/legacy/users", Methods: AllowMethods(POST), Handler: middleware.RewriteBody(
+//		request.UnwrapEnvelope("user"),
+//		request.StringBooleans("active"),
+//	)(user.Create)}
+func RewriteBody(rewriters ...request.BodyRewriter) MiddlewareFunc {
+	return func(next controller.ControllerFunc) controller.ControllerFunc {
+		return func(ctx *controller.Context) {
+			ctx.Request = request.WithBodyRewriter(ctx.Request, rewriters...)
+			next(ctx)
+		}
+	}
+}
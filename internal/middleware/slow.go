@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"context"
+	"runtime/pprof"
+	"time"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/internal/request"
+	"github.com/donbarrigon/new-project/lib/ctxkey"
+)
+
+// SlowRequests mide las fases de cada solicitud (lectura del body, hooks del FormRequest, reglas de cada campo
+// y controlador) y avisa a request.OnSlowRequest con el detalle de las que tardan threshold o mas
+//
+// la solicitud tambien corre con etiquetas de pprof (method, path, request_id): en un perfil de CPU tomado
+// con /debug/pprof/profile mientras hay solicitudes lentas se puede filtrar por ruta con -tagfocus
+//
+//	HandleFuncs("/reports", report.Routes(), middleware.RequestID, middleware.SlowRequests(2*time.Second))
+//
+//	go tool pprof -tagfocus=path=/reports/sales http://localhost:6060/debug/pprof/profile
+func SlowRequests(threshold time.Duration) MiddlewareFunc {
+	return func(next controller.ControllerFunc) controller.ControllerFunc {
+		return func(ctx *controller.Context) {
+			profile := request.NewProfile()
+			req := ctxkey.SetRequest(ctx.Request, request.ProfileKey, profile)
+			labels := pprof.Labels("method", req.Method, "path", req.URL.Path, "request_id", request.RequestID(req.Context()))
+			pprof.Do(req.Context(), labels, func(c context.Context) {
+				ctx.Request = req.WithContext(c)
+				next(ctx)
+			})
+			profile.Finish(ctx.Request, threshold)
+		}
+	}
+}
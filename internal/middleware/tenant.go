@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/internal/request"
+	"github.com/donbarrigon/new-project/internal/tenant"
+)
+
+// Tenant resuelve el tenant del request (subdominio, cabecera o ruta) y lo guarda en el contexto
+// los controladores lo leen con tenant.FromRequest, las reglas con tenant.FromContext(f.Context) y los
+// marcadores {tenant.id} de las reglas quedan disponibles para unique y exists por tenant:
+//
+//	Slug string `json:"slug" rules:"unique:projects,slug,tenant_id:{tenant.id}"`
+//
+// responde 400 si el request no indica el tenant, 404 si no existe y 500 si falla un hook de tenant.OnResolve
+//
+//	HandleFuncs("/projects", project.Routes(), middleware.Tenant(&tenant.Resolver{
+//		Sources: []tenant.Source{tenant.Subdomain("example.com"), tenant.Header("X-Tenant")},
+//		Store:   store,
+//	}))
+func Tenant(resolver *tenant.Resolver) MiddlewareFunc {
+	return func(next controller.ControllerFunc) controller.ControllerFunc {
+		return func(ctx *controller.Context) {
+			t, err := resolver.Resolve(ctx.Request)
+			switch {
+			case errors.Is(err, tenant.ErrMissing):
+				ctx.ResponseErr(http.StatusBadRequest, "No se indicó el tenant", err)
+				return
+			case errors.Is(err, tenant.ErrNotFound):
+				ctx.ResponseErr(http.StatusNotFound, "El tenant no existe", err)
+				return
+			case err != nil:
+				log.Printf("Error resolviendo el tenant: %v", err)
+				ctx.ResponseErr(http.StatusInternalServerError, "No se pudo resolver el tenant", err)
+				return
+			case t == nil:
+				next(ctx)
+				return
+			}
+
+			c, err := tenant.Prepare(ctx.Request.Context(), t)
+			if err != nil {
+				log.Printf("Error preparando el tenant %s: %v", t.ID, err)
+				ctx.ResponseErr(http.StatusInternalServerError, "No se pudo preparar el tenant", err)
+				return
+			}
+			ctx.Request = request.WithPlaceholder(ctx.Request.WithContext(c), "tenant", t)
+			next(ctx)
+		}
+	}
+}
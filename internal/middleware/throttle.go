@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/internal/request"
+)
+
+// Throttle aplica el limite que declara el FormRequest de la ruta (request.ThrottledRequest) antes de llegar
+// al controlador, responde 429 con Retry-After si se supero. Sin el middleware el limite lo revisa request.Validate
+// y el controlador debe responder el error, con el middleware la solicitud no se cuenta dos veces
+//
+//	{Path: "/password/forgot", Methods: AllowMethods(POST),
+//		Handler: middleware.Throttle(func() request.FormRequest { return &ForgotPassword{} })(auth.Forgot)}
+func Throttle(factory func() request.FormRequest) MiddlewareFunc {
+	return func(next controller.ControllerFunc) controller.ControllerFunc {
+		return func(ctx *controller.Context) {
+			req, err := request.CheckThrottle(factory(), ctx.Request)
+			var terr *request.ThrottleError
+			if errors.As(err, &terr) {
+				header := ctx.Writer.Header()
+				header.Set("Retry-After", strconv.Itoa(terr.RetryAfterSeconds()))
+				header.Set("X-RateLimit-Limit", strconv.Itoa(terr.Limit))
+				header.Set("X-RateLimit-Remaining", "0")
+				ctx.ResponseErr(http.StatusTooManyRequests, terr.Error(), err)
+				return
+			}
+			ctx.Request = req
+			next(ctx)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"log"
+	"maps"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+)
+
+// Timeout limita el tiempo que tiene el controlador para responder
+// el request recibe un contexto con deadline, Validate y las consultas que lo usen se cancelan al vencer
+// si el tiempo se agota mientras aun se lee el body se responde 408, si no 504
+// al vencer el contexto del request queda cancelado (context.Cause es http.ErrHandlerTimeout) y lo que el
+// controlador escriba despues se descarta: Write retorna http.ErrHandlerTimeout y las cabeceras y el status
+// ya no llegan al cliente. el controlador sigue corriendo hasta que revise el contexto, no se puede detener
+// la respuesta se guarda en memoria hasta que el controlador termina, si llama Flush (exportaciones) o pasa de 1 MB
+// (archivos) se envia lo que hay y el resto pasa directo; si el tiempo vence despues de eso la respuesta queda cortada
+//
+//	HandleFuncs("/reports", report.Routes(), middleware.Timeout(5*time.Second))
+//
+// tambien se puede definir por ruta con el campo Timeout de routesmaker.Route
+func Timeout(d time.Duration) MiddlewareFunc {
+	return func(next controller.ControllerFunc) controller.ControllerFunc {
+		return func(ctx *controller.Context) {
+			deadlineCtx, cancel := context.WithTimeoutCause(ctx.Request.Context(), d, http.ErrHandlerTimeout)
+			defer cancel()
+
+			// el deadline de lectura de la conexion desbloquea un Read que espera a un cliente lento
+			rc := http.NewResponseController(ctx.Writer)
+			rc.SetReadDeadline(time.Now().Add(d))
+			// se quita al salir, tambien con timeout o panico, para que no afecte el siguiente request de la conexion
+			defer rc.SetReadDeadline(time.Time{})
+
+			req := ctx.Request.WithContext(deadlineCtx)
+			body := &trackedBody{ReadCloser: req.Body}
+			if req.Body == nil || req.Body == http.NoBody || req.ContentLength == 0 {
+				body.done.Store(true)
+			} else {
+				req.Body = body
+			}
+
+			tw := newTimeoutWriter(ctx.Writer)
+			tw.stream = true
+			sub := *ctx
+			sub.Request = req
+			sub.Writer = tw
+			// el controlador puede seguir escribiendo Errors despues del timeout, no comparte el map
+			sub.Errors = maps.Clone(ctx.Errors)
+
+			done := make(chan struct{})
+			panicked := make(chan any, 1)
+			go func() {
+				defer func() {
+					if p := recover(); p != nil {
+						panicked <- p
+					}
+				}()
+				next(&sub)
+				close(done)
+			}()
+
+			select {
+			case p := <-panicked:
+				panic(p)
+			case <-done:
+				ctx.User = sub.User
+				ctx.Errors = sub.Errors
+				tw.flush()
+			case <-deadlineCtx.Done():
+				streamed := tw.timeout()
+				cancel()
+				switch {
+				case streamed:
+					// la respuesta ya se empezo a enviar, solo queda cortarla
+					log.Printf("timeout: %s %s vencio despues de enviar parte de la respuesta", ctx.Request.Method, ctx.Request.URL.Path)
+				case body.done.Load():
+					ctx.ResponseError(http.StatusGatewayTimeout, "El servidor tardó demasiado en responder", nil)
+				default:
+					ctx.ResponseError(http.StatusRequestTimeout, "Se agotó el tiempo para recibir la solicitud", nil)
+				}
+			}
+		}
+	}
+}
+
+// trackedBody marca cuando el body se termino de leer
+type trackedBody struct {
+	io.ReadCloser
+	done atomic.Bool
+}
+
+func (b *trackedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.done.Store(true)
+	}
+	return n, err
+}
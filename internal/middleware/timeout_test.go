@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+)
+
+func TestTimeoutDropsLateWrites(t *testing.T) {
+	type late struct {
+		cause    error
+		writeErr error
+	}
+	finished := make(chan late, 1)
+	handler := Timeout(10 * time.Millisecond)(func(ctx *controller.Context) {
+		<-ctx.Request.Context().Done()
+		// el controlador no revisa el contexto a tiempo y sigue escribiendo
+		time.Sleep(5 * time.Millisecond)
+		ctx.Writer.Header().Set("X-Late", "1")
+		ctx.Errors = map[string]string{"tarde": "si"}
+		ctx.Writer.WriteHeader(http.StatusEarlyHints)
+		ctx.Writer.WriteHeader(http.StatusCreated)
+		_, err := ctx.Writer.Write([]byte("respuesta tardia"))
+		finished <- late{cause: context.Cause(ctx.Request.Context()), writeErr: err}
+	})
+
+	rec := httptest.NewRecorder()
+	handler(controller.NewContext(rec, httptest.NewRequest(http.MethodGet, "/reports", nil)))
+	got := <-finished
+
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Errorf("se esperaba 504, se obtuvo %d", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "tardia") || rec.Header().Get("X-Late") != "" {
+		t.Errorf("lo escrito despues del timeout no debe llegar al cliente: %v %s", rec.Header(), rec.Body.String())
+	}
+	if !errors.Is(got.cause, http.ErrHandlerTimeout) {
+		t.Errorf("el contexto del controlador debe quedar cancelado por el timeout, causa %v", got.cause)
+	}
+	if !errors.Is(got.writeErr, http.ErrHandlerTimeout) {
+		t.Errorf("Write despues del timeout debe fallar con http.ErrHandlerTimeout, se obtuvo %v", got.writeErr)
+	}
+}
+
+func TestTimeoutSendsResponseInTime(t *testing.T) {
+	handler := Timeout(time.Second)(func(ctx *controller.Context) {
+		ctx.Writer.Header().Set("X-Report", "1")
+		ctx.Writer.WriteHeader(http.StatusCreated)
+		ctx.Writer.Write([]byte("listo"))
+	})
+	rec := httptest.NewRecorder()
+	handler(controller.NewContext(rec, httptest.NewRequest(http.MethodGet, "/reports", nil)))
+	if rec.Code != http.StatusCreated || rec.Body.String() != "listo" || rec.Header().Get("X-Report") != "1" {
+		t.Errorf("se esperaba la respuesta del controlador, se obtuvo %d %v %q", rec.Code, rec.Header(), rec.Body.String())
+	}
+}
+
+func TestTimeoutStreamsAfterFlush(t *testing.T) {
+	release := make(chan struct{})
+	handler := Timeout(time.Second)(func(ctx *controller.Context) {
+		ctx.Writer.Header().Set("Content-Type", "text/csv")
+		ctx.Writer.Write([]byte("id,nombre\n"))
+		ctx.Writer.(http.Flusher).Flush()
+		<-release
+		ctx.Writer.Write([]byte("1,ana\n"))
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler(controller.NewContext(w, r))
+	}))
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/export")
+	if err != nil {
+		close(release)
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	first := make(chan string, 1)
+	go func() {
+		buf := make([]byte, len("id,nombre\n"))
+		n, _ := io.ReadFull(res.Body, buf)
+		first <- string(buf[:n])
+	}()
+	select {
+	case got := <-first:
+		if got != "id,nombre\n" {
+			t.Errorf("se esperaba la cabecera del csv, se obtuvo %q", got)
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Errorf("lo escrito antes de Flush debe llegar al cliente sin esperar al controlador")
+	}
+	close(release)
+	rest, _ := io.ReadAll(res.Body)
+	if string(rest) != "1,ana\n" || res.Header.Get("Content-Type") != "text/csv" {
+		t.Errorf("se esperaba el resto del csv, se obtuvo %q %v", rest, res.Header)
+	}
+}
+
+func TestTimeoutAfterStreamDoesNotAppendError(t *testing.T) {
+	finished := make(chan struct{})
+	handler := Timeout(10 * time.Millisecond)(func(ctx *controller.Context) {
+		ctx.Writer.Write([]byte("parte"))
+		ctx.Writer.(http.Flusher).Flush()
+		<-ctx.Request.Context().Done()
+		ctx.Writer.Write([]byte("tarde"))
+		close(finished)
+	})
+	rec := httptest.NewRecorder()
+	handler(controller.NewContext(rec, httptest.NewRequest(http.MethodGet, "/export", nil)))
+	<-finished
+	if rec.Code != http.StatusOK || rec.Body.String() != "parte" {
+		t.Errorf("la respuesta enviada solo se corta, se obtuvo %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestTimeoutLargeBodyIsNotBuffered(t *testing.T) {
+	chunk := bytes.Repeat([]byte("a"), 64<<10)
+	var buffered int
+	var tw *timeoutWriter
+	handler := Timeout(time.Second)(func(ctx *controller.Context) {
+		tw = ctx.Writer.(*timeoutWriter)
+		for i := 0; i < 32; i++ {
+			ctx.Writer.Write(chunk)
+			buffered = max(buffered, tw.body.Len())
+		}
+	})
+	rec := httptest.NewRecorder()
+	handler(controller.NewContext(rec, httptest.NewRequest(http.MethodGet, "/file", nil)))
+	if buffered > streamBufferLimit {
+		t.Errorf("no se deben guardar mas de %d bytes en memoria, se guardaron %d", streamBufferLimit, buffered)
+	}
+	if rec.Body.Len() != 32*len(chunk) {
+		t.Errorf("se esperaban %d bytes, se obtuvieron %d", 32*len(chunk), rec.Body.Len())
+	}
+}
+
+func TestTimeoutKeepAliveAfterTimeout(t *testing.T) {
+	handler := Timeout(20 * time.Millisecond)(func(ctx *controller.Context) {
+		io.ReadAll(ctx.Request.Body)
+		if ctx.Request.URL.Path == "/lento" {
+			<-ctx.Request.Context().Done()
+			return
+		}
+		ctx.Writer.Write([]byte("ok"))
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler(controller.NewContext(w, r))
+	}))
+	defer server.Close()
+
+	client := server.Client()
+	post := func(path string) (*http.Response, error) {
+		// POST sin Idempotency-Key, el cliente no lo reintenta en otra conexion si la primera falla
+		req, _ := http.NewRequest(http.MethodPost, server.URL+path, strings.NewReader("{}"))
+		req.GetBody = nil
+		return client.Do(req)
+	}
+	res, err := post("/lento")
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+	if res.StatusCode != http.StatusGatewayTimeout {
+		t.Fatalf("se esperaba 504, se obtuvo %d", res.StatusCode)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	res, err = post("/rapido")
+	if err != nil {
+		t.Fatalf("el siguiente request en la misma conexion no debe fallar: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	if string(body) != "ok" {
+		t.Errorf("se esperaba ok, se obtuvo %d %q", res.StatusCode, body)
+	}
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/internal/orm"
+)
+
+// UnitOfWork ejecuta el controlador dentro de una transaccion: la confirma si la respuesta es exitosa (menor a 400)
+// y la revierte si es un error o si el controlador entra en panico (el panico sigue su camino)
+// la transaccion queda en el contexto (orm.Conn) para los modelos y las reglas como unique
+// la respuesta se guarda en memoria y se envia despues del commit, si el commit falla el cliente recibe 500
+// y no un 200 de datos que no se guardaron. opts nil usa el aislamiento por defecto de la base de datos
+//
+//	HandleFuncs("/orders", order.Routes(), middleware.UnitOfWork(nil))
+//
+// con mongodb (DB_DRIVER) no hay transacciones sql y el controlador se ejecuta sin transaccion
+func UnitOfWork(opts *sql.TxOptions) MiddlewareFunc {
+	return func(next controller.ControllerFunc) controller.ControllerFunc {
+		return func(ctx *controller.Context) {
+			tx, err := orm.Begin(ctx.Request.Context(), opts)
+			if errors.Is(err, orm.ErrNoSQL) {
+				next(ctx)
+				return
+			}
+			if err != nil {
+				ctx.ResponseErr(http.StatusServiceUnavailable, "No se pudo iniciar la transacción", err)
+				return
+			}
+
+			finished := false
+			defer func() {
+				if !finished {
+					tx.Rollback()
+				}
+			}()
+
+			w := ctx.Writer
+			buffered := newTimeoutWriter(w)
+			ctx.Writer = buffered
+			ctx.Request = ctx.Request.WithContext(orm.WithTx(ctx.Request.Context(), tx))
+			next(ctx)
+			ctx.Writer = w
+			finished = true
+
+			status := buffered.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			if status >= http.StatusBadRequest {
+				if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+					log.Printf("unit_of_work: rollback %s %s: %v", ctx.Request.Method, ctx.Request.URL.Path, err)
+				}
+				buffered.flush()
+				return
+			}
+			if err := tx.Commit(); err != nil {
+				ctx.ResponseErr(http.StatusInternalServerError, "No se pudieron guardar los cambios", err)
+				return
+			}
+			buffered.flush()
+		}
+	}
+}
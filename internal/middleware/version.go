@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/internal/versioning"
+)
+
+// APIVersion resuelve la version que pide el cliente y la guarda en el request
+// la respuesta indica la version con la cabecera Api-Version y, si la version esta obsoleta,
+// las cabeceras Deprecation, Sunset y Link que se configuran con versioning.Deprecate
+//
+//	HandleFuncs("/v1/users", user.PublicRoutes(), middleware.APIVersion)
+func APIVersion(next controller.ControllerFunc) controller.ControllerFunc {
+	return func(ctx *controller.Context) {
+		version := versioning.Extract(ctx.Request)
+		ctx.Request = versioning.WithVersion(ctx.Request, version)
+
+		header := ctx.Writer.Header()
+		header.Set(versioning.Header, version)
+		versioning.WriteDeprecationHeaders(header, version)
+		next(ctx)
+	}
+}
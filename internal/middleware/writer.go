@@ -0,0 +1,175 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+)
+
+// responseRecorder envuelve el http.ResponseWriter y guarda una copia de lo que se responde
+// se usa en los middlewares que necesitan saber que respondio el controlador
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+	limit  int   // bytes del body que se guardan, 0 sin limite y negativo ninguno
+	size   int64 // bytes que se escribieron en total
+}
+
+func newResponseRecorder(w http.ResponseWriter) *responseRecorder {
+	return &responseRecorder{ResponseWriter: w}
+}
+
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	if r.status == 0 && !informational(statusCode) {
+		r.status = statusCode
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	r.size += int64(len(b))
+	if r.limit == 0 {
+		r.body.Write(b)
+	} else if room := r.limit - r.body.Len(); room > 0 {
+		r.body.Write(b[:min(room, len(b))])
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Status retorna el status que se respondio, 200 si el controlador no lo definio
+func (r *responseRecorder) Status() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
+
+// Unwrap permite a http.ResponseController llegar al writer original (Flush, SetWriteDeadline, etc)
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// timeoutWriter guarda la respuesta del controlador en memoria hasta que termina
+// si antes vence el timeout la respuesta se descarta y las escrituras posteriores fallan
+// Contract lo usa sin timeout para revisar la respuesta antes de enviarla
+// con stream (Timeout) el primer Flush del controlador o pasar de streamBufferLimit envia las cabeceras y lo
+// escrito y desde ahi las escrituras pasan directo al cliente, asi las exportaciones y los archivos no se guardan en memoria
+type timeoutWriter struct {
+	w         http.ResponseWriter
+	mu        sync.Mutex
+	header    http.Header
+	status    int
+	body      bytes.Buffer
+	timedOut  bool
+	stream    bool
+	committed bool
+}
+
+// streamBufferLimit bytes que se guardan en memoria con stream antes de empezar a enviar la respuesta
+const streamBufferLimit = 1 << 20
+
+func newTimeoutWriter(w http.ResponseWriter) *timeoutWriter {
+	return &timeoutWriter{w: w, header: make(http.Header)}
+}
+
+// Header despues del timeout retorna un map aparte, las cabeceras del controlador ya no se envian
+func (t *timeoutWriter) Header() http.Header {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.timedOut {
+		return make(http.Header)
+	}
+	return t.header
+}
+
+func (t *timeoutWriter) WriteHeader(statusCode int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if informational(statusCode) {
+		// los 1xx (103 Early Hints) se envian de una vez con las cabeceras que hay hasta ahora
+		if !t.timedOut && t.status == 0 {
+			header := t.w.Header()
+			for k, v := range t.header {
+				header[k] = v
+			}
+			t.w.WriteHeader(statusCode)
+		}
+		return
+	}
+	if t.status == 0 && !t.timedOut {
+		t.status = statusCode
+	}
+}
+
+func (t *timeoutWriter) Write(b []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if t.status == 0 {
+		t.status = http.StatusOK
+	}
+	if !t.committed && t.stream && t.body.Len()+len(b) > streamBufferLimit {
+		t.commit()
+	}
+	if t.committed {
+		return t.w.Write(b)
+	}
+	return t.body.Write(b)
+}
+
+// Flush con stream envia la respuesta hasta el momento, sin stream no hace nada (la respuesta se revisa al final)
+func (t *timeoutWriter) Flush() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.stream || t.timedOut {
+		return
+	}
+	if !t.committed {
+		t.commit()
+	}
+	http.NewResponseController(t.w).Flush()
+}
+
+// timeout descarta la respuesta del controlador, retorna true si ya se habia enviado parte (stream)
+// y por eso no se puede responder otro status
+func (t *timeoutWriter) timeout() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.timedOut = true
+	return t.committed
+}
+
+// flush envia al cliente la respuesta que escribio el controlador
+func (t *timeoutWriter) flush() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.committed {
+		t.commit()
+	}
+}
+
+// commit envia las cabeceras, el status y el body guardado, se llama con el mutex tomado
+func (t *timeoutWriter) commit() {
+	t.committed = true
+	header := t.w.Header()
+	for k, v := range t.header {
+		header[k] = v
+	}
+	if t.status == 0 {
+		t.status = http.StatusOK
+	}
+	t.w.WriteHeader(t.status)
+	t.w.Write(t.body.Bytes())
+	t.body.Reset()
+}
+
+// informational indica si el status es 1xx (103 Early Hints), se envia antes de la respuesta y no es el status final
+func informational(statusCode int) bool {
+	return statusCode >= 100 && statusCode < 200 && statusCode != http.StatusSwitchingProtocols
+}
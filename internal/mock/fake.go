@@ -0,0 +1,342 @@
+package mock
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/donbarrigon/new-project/lib/ids"
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// Fill llena los campos vacios del struct v (puntero) con valores plausibles segun el tipo, las reglas del tag rules,
+// el tag options y el nombre del campo. la misma semilla genera los mismos datos, asi el frontend ve siempre
+// la misma respuesta para la misma url. los campos que ya tienen valor no se cambian
+//
+//	var u request.User
+//	mock.Fill(&u, 1) // {Name: "Camila", Email: "camila1@example.com", Age: 34}
+func Fill(v any, seed uint64) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("mock: Fill requiere un puntero y recibió %T", v)
+	}
+	f := &faker{rnd: rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))}
+	f.value(rv.Elem(), "", nil, "", 0)
+	return nil
+}
+
+// faker genera los valores de un Fill
+type faker struct {
+	rnd *rand.Rand
+}
+
+// maxDepth profundidad maxima de los structs anidados, evita ciclos con punteros al mismo tipo
+const maxDepth = 4
+
+var (
+	firstNames = []string{"Camila", "Santiago", "Valentina", "Mateo", "Isabella", "Sebastián", "Mariana", "Nicolás", "Lucía", "Samuel"}
+	lastNames  = []string{"García", "Rodríguez", "Martínez", "López", "Gómez", "Díaz", "Torres", "Ramírez", "Vargas", "Rojas"}
+	words      = []string{"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit", "sed", "tempor"}
+	cities     = []string{"Bogotá", "Medellín", "Cali", "Barranquilla", "Cartagena", "Lima", "Quito", "Santiago"}
+)
+
+// value llena v si esta vacio, name es el nombre json del campo para las heuristicas
+func (f *faker) value(v reflect.Value, name string, rules []validation.Rule, options string, depth int) {
+	if !v.CanSet() {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			if depth >= maxDepth {
+				return
+			}
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		f.value(v.Elem(), name, rules, options, depth)
+		return
+	case reflect.Struct:
+		if v.Type() == reflect.TypeOf(time.Time{}) {
+			if v.IsZero() {
+				v.Set(reflect.ValueOf(f.date()))
+			}
+			return
+		}
+		if depth >= maxDepth {
+			return
+		}
+		f.structFields(v, depth+1)
+		return
+	case reflect.Slice:
+		if v.Len() > 0 || depth >= maxDepth {
+			return
+		}
+		n := 1 + f.rnd.IntN(3)
+		if lo, hi, ok := bounds(rules); ok {
+			n = clamp(n, int(lo), int(hi))
+		}
+		slice := reflect.MakeSlice(v.Type(), n, n)
+		for i := 0; i < n; i++ {
+			f.value(slice.Index(i), singular(name), nil, "", depth+1)
+		}
+		v.Set(slice)
+		return
+	}
+	if !v.IsZero() {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(f.text(name, rules, options))
+	case reflect.Bool:
+		v.SetBool(f.rnd.IntN(2) == 1)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		lo, hi := f.numberRange(name, rules)
+		v.SetInt(int64(lo) + f.rnd.Int64N(int64(hi-lo)+1))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		lo, hi := f.numberRange(name, rules)
+		lo = max(lo, 0)
+		v.SetUint(uint64(lo) + f.rnd.Uint64N(uint64(max(hi-lo, 0))+1))
+	case reflect.Float32, reflect.Float64:
+		lo, hi := f.numberRange(name, rules)
+		n := lo + f.rnd.Float64()*(hi-lo)
+		v.SetFloat(float64(int64(n*100)) / 100)
+	}
+}
+
+// structFields llena los campos exportados del struct con sus tags
+func (f *faker) structFields(v reflect.Value, depth int) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		var rules []validation.Rule
+		if tag := field.Tag.Get("rules"); tag != "" {
+			rules = validation.ParseRules(tag)
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			f.structFields(v.Field(i), depth)
+			continue
+		}
+		f.value(v.Field(i), name, rules, field.Tag.Get("options"), depth)
+	}
+}
+
+// text genera un texto con la regla de formato del campo o con el nombre, y lo ajusta a min y max
+func (f *faker) text(name string, rules []validation.Rule, options string) string {
+	if options != "" {
+		list := strings.Split(options, ",")
+		value, _, _ := strings.Cut(list[f.rnd.IntN(len(list))], ":")
+		return strings.TrimSpace(value)
+	}
+	first := firstNames[f.rnd.IntN(len(firstNames))]
+	last := lastNames[f.rnd.IntN(len(lastNames))]
+	n := 1 + f.rnd.IntN(99)
+
+	value := ""
+	for _, r := range rules {
+		switch r.Name {
+		case "email":
+			value = fmt.Sprintf("%s%d@example.com", ascii(first), n)
+		case "ulid":
+			value = f.ulid()
+		case "ksuid":
+			value = f.ksuid()
+		case "url", "active_url":
+			value = fmt.Sprintf("https://example.com/%s", words[f.rnd.IntN(len(words))])
+		case "fqdn", "hostname":
+			value = words[f.rnd.IntN(len(words))] + ".example.com"
+		case "slug":
+			value = words[f.rnd.IntN(len(words))] + "-" + words[f.rnd.IntN(len(words))]
+		case "username", "identifier":
+			value = fmt.Sprintf("%s_%d", ascii(first), n)
+		case "hex_color":
+			value = fmt.Sprintf("#%06x", f.rnd.IntN(1<<24))
+		case "phone":
+			value = fmt.Sprintf("+57300%07d", f.rnd.IntN(10000000))
+		case "date", "after", "before":
+			value = f.date().Format(time.DateOnly)
+		case "currency":
+			value = []string{"COP", "USD", "EUR", "MXN"}[f.rnd.IntN(4)]
+		case "country_code":
+			value = []string{"CO", "MX", "PE", "CL", "AR"}[f.rnd.IntN(5)]
+		case "language":
+			value = []string{"es", "en", "pt"}[f.rnd.IntN(3)]
+		case "timezone":
+			value = "America/Bogota"
+		case "semver":
+			value = fmt.Sprintf("%d.%d.%d", 1+f.rnd.IntN(3), f.rnd.IntN(10), f.rnd.IntN(10))
+		case "postal_code":
+			value = fmt.Sprintf("11%04d", f.rnd.IntN(10000))
+		case "latitude":
+			value = strconv.FormatFloat(-4+f.rnd.Float64()*16, 'f', 6, 64)
+		case "longitude":
+			value = strconv.FormatFloat(-79+f.rnd.Float64()*12, 'f', 6, 64)
+		case "decimal":
+			value = strconv.FormatFloat(f.rnd.Float64()*1000, 'f', 2, 64)
+		}
+		if value != "" {
+			return value
+		}
+	}
+
+	switch {
+	case strings.Contains(name, "email"):
+		value = fmt.Sprintf("%s%d@example.com", ascii(first), n)
+	case name == "id" || strings.HasSuffix(name, "_id"):
+		value = f.ulid()
+	case strings.Contains(name, "first_name"):
+		value = first
+	case strings.Contains(name, "last_name"):
+		value = last
+	case strings.Contains(name, "name"):
+		value = first + " " + last
+	case strings.Contains(name, "city"):
+		value = cities[f.rnd.IntN(len(cities))]
+	case strings.Contains(name, "phone"):
+		value = fmt.Sprintf("+57300%07d", f.rnd.IntN(10000000))
+	case strings.Contains(name, "url"), strings.Contains(name, "link"):
+		value = fmt.Sprintf("https://example.com/%s", words[f.rnd.IntN(len(words))])
+	case strings.HasSuffix(name, "_at"), strings.Contains(name, "date"):
+		value = f.date().Format(time.RFC3339)
+	default:
+		value = f.sentence(3 + f.rnd.IntN(4))
+	}
+	return fitLength(value, rules)
+}
+
+// sentence genera n palabras
+func (f *faker) sentence(n int) string {
+	out := make([]string, n)
+	for i := range out {
+		out[i] = words[f.rnd.IntN(len(words))]
+	}
+	return strings.Join(out, " ")
+}
+
+// numberRange rango del numero con min y max, sin ellos un rango segun el nombre del campo
+func (f *faker) numberRange(name string, rules []validation.Rule) (float64, float64) {
+	lo, hi := 1.0, 100.0
+	switch {
+	case strings.Contains(name, "age"):
+		lo, hi = 18, 80
+	case strings.Contains(name, "price"), strings.Contains(name, "amount"), strings.Contains(name, "total"):
+		lo, hi = 1, 1000
+	case strings.Contains(name, "year"):
+		lo, hi = 2000, float64(time.Now().Year())
+	}
+	for _, r := range rules {
+		if len(r.Params) == 0 {
+			continue
+		}
+		n, err := strconv.ParseFloat(r.Params[0], 64)
+		if err != nil {
+			continue
+		}
+		switch r.Name {
+		case "min":
+			lo = n
+			if hi < lo {
+				hi = lo + 100
+			}
+		case "max":
+			hi = n
+			if lo > hi {
+				lo = hi
+			}
+		}
+	}
+	return lo, hi
+}
+
+// date fecha de los ultimos dos años, se cuenta desde el inicio del dia para que no cambie en cada solicitud
+func (f *faker) date() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour).Add(-time.Duration(f.rnd.IntN(730*24)) * time.Hour)
+}
+
+// ulid ULID con la parte aleatoria de la semilla
+func (f *faker) ulid() string {
+	id := ids.NewULIDAt(f.date())
+	for i := 6; i < len(id); i++ {
+		id[i] = byte(f.rnd.UintN(256))
+	}
+	return id.String()
+}
+
+// ksuid KSUID con la parte aleatoria de la semilla
+func (f *faker) ksuid() string {
+	id := ids.NewKSUIDAt(f.date())
+	for i := 4; i < len(id); i++ {
+		id[i] = byte(f.rnd.UintN(256))
+	}
+	return id.String()
+}
+
+// bounds cantidad de elementos con min y max (o count:min,max)
+func bounds(rules []validation.Rule) (float64, float64, bool) {
+	lo, hi, found := 0.0, 1e9, false
+	for _, r := range rules {
+		switch r.Name {
+		case "min", "max", "count":
+			for i, p := range r.Params {
+				n, err := strconv.ParseFloat(p, 64)
+				if err != nil {
+					continue
+				}
+				found = true
+				if r.Name == "max" || (r.Name == "count" && i == 1) {
+					hi = n
+				} else {
+					lo = n
+				}
+			}
+		}
+	}
+	return lo, hi, found
+}
+
+// fitLength ajusta el texto a las reglas min y max de longitud
+func fitLength(value string, rules []validation.Rule) string {
+	lo, hi, ok := bounds(rules)
+	if !ok {
+		return value
+	}
+	runes := []rune(value)
+	for len(runes) < int(lo) {
+		runes = append(runes, []rune(" "+words[len(runes)%len(words)])...)
+	}
+	if hi < 1e9 && len(runes) > int(hi) {
+		runes = []rune(strings.TrimSpace(string(runes[:int(hi)])))
+	}
+	for len(runes) < int(lo) {
+		runes = append(runes, 'x')
+	}
+	return string(runes)
+}
+
+func clamp(n int, lo int, hi int) int {
+	return min(max(n, lo), hi)
+}
+
+// singular nombre de los elementos de un slice para las heuristicas (emails -> email)
+func singular(name string) string {
+	return strings.TrimSuffix(name, "s")
+}
+
+// ascii quita las tildes de los nombres para los emails
+func ascii(s string) string {
+	return strings.ToLower(strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "Á", "A", "É", "E", "Í", "I", "Ó", "O", "Ú", "U", "ñ", "n").Replace(s))
+}
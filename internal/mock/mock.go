@@ -0,0 +1,211 @@
+package mock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/internal/request"
+)
+
+// Servidor de pruebas para el frontend: valida las solicitudes con los FormRequest registrados (mismos errores
+// y codigos que el servidor real) y responde datos plausibles generados con Fill, asi se puede desarrollar
+// contra el contrato antes de que existan los controladores
+//
+//	go run ./cmd/cli mock -addr :8081 -config storage/mock.json
+//
+// storage/mock.json describe los endpoints, form y response son nombres de los FormRequest registrados
+//
+//	[
+//		{"method": "POST", "path": "/users", "form": "user", "status": 201},
+//		{"method": "GET", "path": "/users", "response": "user", "list": 10},
+//		{"method": "GET", "path": "/users/{id}", "response": "user"}
+//	]
+//
+// sin el archivo cada FormRequest tiene POST /name, GET /name y GET /name/{id} (ver DefaultEndpoints)
+
+// Endpoint ruta del servidor de pruebas
+type Endpoint struct {
+	Method string `json:"method"`
+	Path   string `json:"path"` // patron de http.ServeMux, los parametros {id} llenan el campo id de la respuesta
+	// Form FormRequest que valida el body, vacio no valida nada
+	Form string `json:"form,omitempty"`
+	// Response FormRequest con la forma de la respuesta, vacio responde el Form validado completando lo que falte
+	Response string `json:"response,omitempty"`
+	// List responde un array con List elementos
+	List int `json:"list,omitempty"`
+	// Status de la respuesta exitosa, 201 para POST y 200 para los demas si es cero
+	Status int `json:"status,omitempty"`
+}
+
+// Server servidor de pruebas con sus FormRequest y endpoints
+type Server struct {
+	Forms     map[string]func() request.FormRequest
+	Endpoints []Endpoint
+}
+
+// NewServer crea el servidor, sin endpoints usa DefaultEndpoints
+func NewServer(forms map[string]func() request.FormRequest, endpoints []Endpoint) *Server {
+	if len(endpoints) == 0 {
+		endpoints = DefaultEndpoints(forms)
+	}
+	return &Server{Forms: forms, Endpoints: endpoints}
+}
+
+// DefaultEndpoints crea POST /name, GET /name y GET /name/{id} para cada FormRequest
+func DefaultEndpoints(forms map[string]func() request.FormRequest) []Endpoint {
+	names := make([]string, 0, len(forms))
+	for name := range forms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	endpoints := make([]Endpoint, 0, len(names)*3)
+	for _, name := range names {
+		endpoints = append(endpoints,
+			Endpoint{Method: http.MethodPost, Path: "/" + name, Form: name},
+			Endpoint{Method: http.MethodGet, Path: "/" + name, Response: name, List: 3},
+			Endpoint{Method: http.MethodGet, Path: "/" + name + "/{id}", Response: name},
+		)
+	}
+	return endpoints
+}
+
+// LoadEndpoints lee los endpoints de un archivo json
+func LoadEndpoints(path string) ([]Endpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var endpoints []Endpoint
+	if err := json.Unmarshal(data, &endpoints); err != nil {
+		return nil, fmt.Errorf("mock: %s no es un json válido: %w", path, err)
+	}
+	return endpoints, nil
+}
+
+// Handler registra los endpoints, retorna error si un endpoint usa un FormRequest que no esta en Forms
+func (s *Server) Handler() (http.Handler, error) {
+	mux := http.NewServeMux()
+	for _, e := range s.Endpoints {
+		for _, name := range []string{e.Form, e.Response} {
+			if _, ok := s.Forms[name]; name != "" && !ok {
+				return nil, fmt.Errorf("mock: %s %s usa el FormRequest '%s' que no está registrado", e.Method, e.Path, name)
+			}
+		}
+		mux.HandleFunc(e.Method+" "+e.Path, s.handle(e))
+	}
+	return mux, nil
+}
+
+// handle valida el body con el Form y responde los datos generados
+func (s *Server) handle(e Endpoint) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := controller.NewContext(w, r)
+
+		var form request.FormRequest
+		if e.Form != "" {
+			form = s.Forms[e.Form]()
+			if err := request.Validate(form, r); err != nil {
+				status, message := errorStatus(err)
+				ctx.ResponseErr(status, message, err)
+				return
+			}
+		}
+
+		status := e.Status
+		if status == 0 {
+			status = http.StatusOK
+			if r.Method == http.MethodPost {
+				status = http.StatusCreated
+			}
+		}
+		if status == http.StatusNoContent {
+			ctx.ResponseNoContent()
+			return
+		}
+
+		// la semilla depende de la url para que la misma url responda los mismos datos
+		seed := hash(r.Method + " " + r.URL.Path)
+		newValue := func(i int) any {
+			var v any
+			switch {
+			case e.Response != "":
+				v = s.Forms[e.Response]()
+			case form != nil:
+				v = form
+			default:
+				// sin Form ni Response no hay forma que llenar
+				return map[string]any{}
+			}
+			Fill(v, seed+uint64(i))
+			setID(v, r.PathValue("id"))
+			return v
+		}
+
+		if e.List > 0 {
+			items := make([]any, e.List)
+			for i := range items {
+				items[i] = newValue(i)
+			}
+			ctx.ResponseJSON(status, items)
+			return
+		}
+		ctx.ResponseJSON(status, newValue(0))
+	}
+}
+
+// errorStatus status y mensaje de un error de Validate, los mismos del servidor real
+func errorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, request.ErrValidation):
+		return http.StatusUnprocessableEntity, "Los datos no son válidos"
+	case errors.Is(err, request.ErrDecode):
+		return http.StatusBadRequest, "El body no se pudo leer"
+	case errors.Is(err, request.ErrUnauthorized):
+		return http.StatusForbidden, "No tiene permiso para realizar esta acción"
+	case errors.Is(err, request.ErrTooManyRequests):
+		return http.StatusTooManyRequests, "Demasiadas solicitudes"
+	case errors.Is(err, request.ErrStaleVersion):
+		var stale *request.StaleVersionError
+		if errors.As(err, &stale) {
+			return stale.Status(), "El recurso cambió desde que se leyó"
+		}
+		return http.StatusConflict, "El recurso cambió desde que se leyó"
+	}
+	return http.StatusInternalServerError, "Error validando la solicitud"
+}
+
+// setID copia el parametro {id} de la ruta al campo json "id" de la respuesta si es un texto
+func setID(v any, id string) {
+	if id == "" {
+		return
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return
+	}
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "id" && rv.Field(i).Kind() == reflect.String {
+			rv.Field(i).SetString(id)
+			return
+		}
+	}
+}
+
+func hash(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
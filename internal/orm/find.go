@@ -57,7 +57,8 @@ func (m *Model) findMySQL(id any) error {
 	)
 
 	// Ejecutar la consulta
-	row := db.QueryRow(query, id)
+	start := time.Now()
+	row := Conn(m.context()).QueryRowContext(m.context(), query, id)
 
 	// Crear un slice de punteros a interfaces para almacenar los valores
 	values := make([]any, len(m.selectedColumns))
@@ -77,7 +78,9 @@ func (m *Model) findMySQL(id any) error {
 	// 	valuePtrs = append(valuePtrs, &v)
 	// }
 
-	if err := row.Scan(valuePtrs...); err != nil {
+	err := row.Scan(valuePtrs...)
+	recordQuery(m.context(), query, []any{id}, start, err)
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return fmt.Errorf("registro no encontrado")
 		}
@@ -96,7 +99,7 @@ func (m *Model) findMySQL(id any) error {
 // findMongoDB hace la busqueda en mongodb
 func (m *Model) findMongoDB(id any) error {
 	// Timeout en el contexto
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(m.context(), 10*time.Second)
 	defer cancel()
 
 	// Convertir el ID a ObjectID si es necesario
@@ -121,7 +124,10 @@ func (m *Model) findMongoDB(id any) error {
 
 	var result map[string]any
 	// realizar la consulta
-	if err := collection.FindOne(ctx, filter, opts).Decode(&result); err != nil {
+	start := time.Now()
+	err = collection.FindOne(ctx, filter, opts).Decode(&result)
+	recordQuery(m.context(), fmt.Sprintf("db.%s.findOne(%v, %v)", m.tableName, filter, projection), []any{id}, start, err)
+	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return fmt.Errorf("documento no encontrado")
 		}
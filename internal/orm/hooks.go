@@ -0,0 +1,51 @@
+package orm
+
+import (
+	"context"
+	"time"
+)
+
+// queryHooks funciones que se llaman despues de cada consulta (barra de depuracion, metricas, consultas lentas)
+var queryHooks []func(ctx context.Context, query string, args []any, elapsed time.Duration, err error)
+
+// OnQuery registra una funcion que se llama despues de cada consulta a la base de datos
+// ctx es el del modelo (ver WithContext), asi la consulta se puede asociar a la solicitud que la hizo
+// se debe llamar al iniciar la aplicacion
+//
+//	orm.OnQuery(func(ctx context.Context, query string, args []any, elapsed time.Duration, err error) {
+//		if elapsed > time.Second {
+//			log.Printf("consulta lenta (%s): %s", elapsed, query)
+//		}
+//	})
+func OnQuery(hook func(ctx context.Context, query string, args []any, elapsed time.Duration, err error)) {
+	queryHooks = append(queryHooks, hook)
+}
+
+// recordQuery avisa a los hooks que termino una consulta
+func recordQuery(ctx context.Context, query string, args []any, start time.Time, err error) {
+	if len(queryHooks) == 0 {
+		return
+	}
+	elapsed := time.Since(start)
+	for _, hook := range queryHooks {
+		hook(ctx, query, args, elapsed, err)
+	}
+}
+
+// WithContext asigna el contexto de las consultas del modelo, normalmente el de la solicitud
+// las consultas se cancelan si el cliente se desconecta y los hooks de OnQuery saben de que solicitud vienen
+//
+//	user := &model.User{}
+//	user.WithContext(ctx.Request.Context()).Find(id)
+func (m *Model) WithContext(ctx context.Context) *Model {
+	m.ctx = ctx
+	return m
+}
+
+// context retorna el contexto del modelo o context.Background si no se asigno
+func (m *Model) context() context.Context {
+	if m.ctx == nil {
+		return context.Background()
+	}
+	return m.ctx
+}
@@ -1,6 +1,7 @@
 package orm
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/donbarrigon/new-project/internal/cache"
@@ -63,6 +64,7 @@ type Model struct {
 	guarded         []string         // Guarded establece los atributos que no deben ser asignados de manera masiva.
 	Data            []map[string]any // variable donde se guarda los resultados de los query
 	selectedColumns []string         // selectedColumns almacena las columnas que se usaran para la consulta
+	ctx             context.Context  // contexto de las consultas, ver WithContext
 	// variables que se usaran al construir la consulta
 
 	// where         string
@@ -0,0 +1,183 @@
+package orm
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/donbarrigon/new-project/lib/search"
+	"github.com/donbarrigon/new-project/lib/validation"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// SearchColumns como se traduce la busqueda (lib/search) a la base de datos
+//
+//	cols := orm.SearchColumns{
+//		Fields: map[string]string{"status": "status", "price": "price", "brand": "brands.name"},
+//		Text:   []string{"name", "description"},
+//	}
+//	where, args, err := orm.SearchSQL(req.Q, cols)
+//	rows, err := orm.Conn(ctx).QueryContext(ctx, "SELECT id, name FROM products WHERE "+where, args...)
+//
+// los campos que no estan en Fields son un error, restrinjalos antes con la regla search_fields
+type SearchColumns struct {
+	Fields map[string]string // campo de la busqueda -> columna
+	Text   []string          // columnas donde se buscan las palabras y frases sin campo
+}
+
+// SearchSQL traduce la busqueda a una condicion WHERE con sus argumentos, "1 = 1" si esta vacia
+// los terminos con campo se comparan con =, las palabras sin campo con LIKE (ILIKE en postgresql)
+// en cualquiera de las columnas de Text
+func SearchSQL(q search.Query, cols SearchColumns) (string, []any, error) {
+	if q.IsZero() {
+		return "1 = 1", nil, nil
+	}
+	var args []any
+	where, err := searchSQL(q.Root, cols, &args)
+	return where, args, err
+}
+
+func searchSQL(n *search.Node, cols SearchColumns, args *[]any) (string, error) {
+	switch n.Kind {
+	case search.And, search.Or:
+		parts := make([]string, len(n.Children))
+		for i, child := range n.Children {
+			part, err := searchSQL(child, cols, args)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = part
+		}
+		return "(" + strings.Join(parts, " "+strings.ToUpper(string(n.Kind))+" ") + ")", nil
+	case search.Not:
+		part, err := searchSQL(n.Children[0], cols, args)
+		if err != nil {
+			return "", err
+		}
+		return "NOT (" + part + ")", nil
+	case search.Term:
+		if n.Field == "" {
+			return textSQL(n.Value, cols.Text, args)
+		}
+		col, err := searchColumn(n.Field, cols)
+		if err != nil {
+			return "", err
+		}
+		*args = append(*args, n.Value)
+		return col + " = ?", nil
+	case search.Range:
+		col, err := searchColumn(n.Field, cols)
+		if err != nil {
+			return "", err
+		}
+		var parts []string
+		if n.Min != "" {
+			parts = append(parts, col+map[bool]string{false: " >= ?", true: " > ?"}[n.MinOpen])
+			*args = append(*args, n.Min)
+		}
+		if n.Max != "" {
+			parts = append(parts, col+map[bool]string{false: " <= ?", true: " < ?"}[n.MaxOpen])
+			*args = append(*args, n.Max)
+		}
+		return "(" + strings.Join(parts, " AND ") + ")", nil
+	}
+	return "", fmt.Errorf("orm: nodo de búsqueda desconocido '%s'", n.Kind)
+}
+
+// textSQL busca el valor en cualquiera de las columnas de texto
+func textSQL(value string, text []string, args *[]any) (string, error) {
+	if len(text) == 0 {
+		return "", fmt.Errorf("orm: la búsqueda no tiene columnas de texto para '%s'", value)
+	}
+	like := "LIKE"
+	if dbDriver == "postgresql" {
+		like = "ILIKE"
+	}
+	pattern := "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value) + "%"
+	parts := make([]string, len(text))
+	for i, col := range text {
+		if validation.Identifier(col) != nil {
+			return "", fmt.Errorf("orm: nombre inválido '%s'", col)
+		}
+		parts[i] = col + " " + like + " ?"
+		*args = append(*args, pattern)
+	}
+	return "(" + strings.Join(parts, " OR ") + ")", nil
+}
+
+// searchColumn columna del campo, error si el campo no se puede buscar
+func searchColumn(field string, cols SearchColumns) (string, error) {
+	col, ok := cols.Fields[field]
+	if !ok {
+		return "", fmt.Errorf("orm: no se puede buscar por el campo '%s'", field)
+	}
+	if validation.Identifier(col) != nil {
+		return "", fmt.Errorf("orm: nombre inválido '%s'", col)
+	}
+	return col, nil
+}
+
+// SearchFilter traduce la busqueda a un filtro de mongodb, vacio si no hay busqueda
+// las palabras sin campo son expresiones regulares sin distinguir mayusculas en las columnas de Text
+// y los valores numericos de los rangos se comparan como numeros
+func SearchFilter(q search.Query, cols SearchColumns) (bson.M, error) {
+	if q.IsZero() {
+		return bson.M{}, nil
+	}
+	return searchFilter(q.Root, cols)
+}
+
+func searchFilter(n *search.Node, cols SearchColumns) (bson.M, error) {
+	switch n.Kind {
+	case search.And, search.Or, search.Not:
+		children := make(bson.A, len(n.Children))
+		for i, child := range n.Children {
+			filter, err := searchFilter(child, cols)
+			if err != nil {
+				return nil, err
+			}
+			children[i] = filter
+		}
+		op := map[search.Kind]string{search.And: "$and", search.Or: "$or", search.Not: "$nor"}[n.Kind]
+		return bson.M{op: children}, nil
+	case search.Term:
+		if n.Field == "" {
+			if len(cols.Text) == 0 {
+				return nil, fmt.Errorf("orm: la búsqueda no tiene columnas de texto para '%s'", n.Value)
+			}
+			or := make(bson.A, len(cols.Text))
+			for i, col := range cols.Text {
+				or[i] = bson.M{col: bson.M{"$regex": regexp.QuoteMeta(n.Value), "$options": "i"}}
+			}
+			return bson.M{"$or": or}, nil
+		}
+		col, ok := cols.Fields[n.Field]
+		if !ok {
+			return nil, fmt.Errorf("orm: no se puede buscar por el campo '%s'", n.Field)
+		}
+		return bson.M{col: n.Value}, nil
+	case search.Range:
+		col, ok := cols.Fields[n.Field]
+		if !ok {
+			return nil, fmt.Errorf("orm: no se puede buscar por el campo '%s'", n.Field)
+		}
+		bounds := bson.M{}
+		if n.Min != "" {
+			bounds[map[bool]string{false: "$gte", true: "$gt"}[n.MinOpen]] = rangeValue(n.Min)
+		}
+		if n.Max != "" {
+			bounds[map[bool]string{false: "$lte", true: "$lt"}[n.MaxOpen]] = rangeValue(n.Max)
+		}
+		return bson.M{col: bounds}, nil
+	}
+	return nil, fmt.Errorf("orm: nodo de búsqueda desconocido '%s'", n.Kind)
+}
+
+// rangeValue los limites numericos como numero, los demas (fechas) como texto
+func rangeValue(value string) any {
+	if n, err := strconv.ParseFloat(value, 64); err == nil {
+		return n
+	}
+	return value
+}
@@ -0,0 +1,107 @@
+package orm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/donbarrigon/new-project/lib/search"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var productColumns = SearchColumns{
+	Fields: map[string]string{"status": "status", "price": "price", "brand": "brands.name"},
+	Text:   []string{"name", "description"},
+}
+
+func TestSearchSQL(t *testing.T) {
+	previous := dbDriver
+	t.Cleanup(func() { dbDriver = previous })
+	dbDriver = "mysql"
+
+	cases := []struct {
+		q     string
+		where string
+		args  []any
+	}{
+		{"", "1 = 1", nil},
+		{"status:active", "status = ?", []any{"active"}},
+		{"brand:acme", "brands.name = ?", []any{"acme"}},
+		{"laptop", "(name LIKE ? OR description LIKE ?)", []any{"%laptop%", "%laptop%"}},
+		{`"50%_off"`, "(name LIKE ? OR description LIKE ?)", []any{`%50\%\_off%`, `%50\%\_off%`}},
+		{"price:10..20", "(price >= ? AND price <= ?)", []any{"10", "20"}},
+		{"price:>10", "(price > ?)", []any{"10"}},
+		{"price:..20", "(price <= ?)", []any{"20"}},
+		{
+			"status:active (brand:acme OR brand:globex) -status:draft",
+			"(status = ? AND (brands.name = ? OR brands.name = ?) AND NOT (status = ?))",
+			[]any{"active", "acme", "globex", "draft"},
+		},
+	}
+	for _, c := range cases {
+		where, args, err := SearchSQL(search.MustParse(c.q), productColumns)
+		if err != nil {
+			t.Errorf("%q: %v", c.q, err)
+			continue
+		}
+		if where != c.where || !reflect.DeepEqual(args, c.args) {
+			t.Errorf("%q: se obtuvo %s %v, se esperaba %s %v", c.q, where, args, c.where, c.args)
+		}
+	}
+
+	dbDriver = "postgresql"
+	if where, _, _ := SearchSQL(search.MustParse("laptop"), productColumns); where != "(name ILIKE ? OR description ILIKE ?)" {
+		t.Errorf("postgresql debe usar ILIKE, se obtuvo %s", where)
+	}
+}
+
+func TestSearchSQLErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		q    string
+		cols SearchColumns
+	}{
+		{"campo no permitido", "owner:ana", productColumns},
+		{"rango de campo no permitido", "stock:>1", productColumns},
+		{"sin columnas de texto", "laptop", SearchColumns{Fields: productColumns.Fields}},
+		{"columna invalida", "status:a", SearchColumns{Fields: map[string]string{"status": "status; DROP TABLE x"}}},
+		{"columna de texto invalida", "laptop", SearchColumns{Text: []string{"name--"}}},
+	}
+	for _, c := range cases {
+		if _, _, err := SearchSQL(search.MustParse(c.q), c.cols); err == nil {
+			t.Errorf("%s: se esperaba un error", c.name)
+		}
+	}
+}
+
+func TestSearchFilter(t *testing.T) {
+	cases := []struct {
+		q    string
+		want bson.M
+	}{
+		{"", bson.M{}},
+		{"status:active", bson.M{"status": "active"}},
+		{"a.b", bson.M{"$or": bson.A{
+			bson.M{"name": bson.M{"$regex": `a\.b`, "$options": "i"}},
+			bson.M{"description": bson.M{"$regex": `a\.b`, "$options": "i"}},
+		}}},
+		{"price:10..20", bson.M{"price": bson.M{"$gte": 10.0, "$lte": 20.0}}},
+		{"price:>2026-01-01", bson.M{"price": bson.M{"$gt": "2026-01-01"}}},
+		{"status:a OR -brand:b", bson.M{"$or": bson.A{
+			bson.M{"status": "a"},
+			bson.M{"$nor": bson.A{bson.M{"brands.name": "b"}}},
+		}}},
+	}
+	for _, c := range cases {
+		got, err := SearchFilter(search.MustParse(c.q), productColumns)
+		if err != nil {
+			t.Errorf("%q: %v", c.q, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%q: se obtuvo %v, se esperaba %v", c.q, got, c.want)
+		}
+	}
+	if _, err := SearchFilter(search.MustParse("owner:ana"), productColumns); err == nil {
+		t.Errorf("se esperaba un error por el campo no permitido")
+	}
+}
@@ -0,0 +1,65 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/donbarrigon/new-project/lib/ctxkey"
+)
+
+// Transacciones por contexto: el middleware UnitOfWork abre una transaccion por solicitud y la guarda
+// en el contexto, los modelos (WithContext) y las reglas que consultan la base de datos la usan sin recibirla
+// (las reglas unique, exists y current_version de request ya lo hacen)
+//
+//	validation.RegisterRule("in_stock", func(f *validation.Field) error {
+//		var stock int
+//		err := orm.Conn(f.Context).QueryRowContext(f.Context, "SELECT stock FROM products WHERE id = ?", f.Value).Scan(&stock)
+//		...
+//	})
+//
+// asi la regla ve las filas que la misma solicitud ya inserto y no las de otra transaccion sin confirmar
+
+// ErrNoSQL el driver configurado (DB_DRIVER) no es una base de datos sql, no hay transacciones
+var ErrNoSQL = errors.New("orm: el driver de base de datos no soporta transacciones sql")
+
+// Querier lo implementan *sql.DB y *sql.Tx
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// txKey llave del contexto con la transaccion de la solicitud
+var txKey = ctxkey.New[*sql.Tx]("orm_tx")
+
+// WithTx guarda la transaccion en el contexto
+func WithTx(ctx context.Context, tx *sql.Tx) context.Context {
+	return ctxkey.Set(ctx, txKey, tx)
+}
+
+// Tx retorna la transaccion del contexto
+func Tx(ctx context.Context) (*sql.Tx, bool) {
+	return ctxkey.Get(ctx, txKey)
+}
+
+// Conn retorna la transaccion del contexto o la conexion si no hay transaccion
+func Conn(ctx context.Context) Querier {
+	if tx, ok := Tx(ctx); ok {
+		return tx
+	}
+	return db
+}
+
+// Begin abre una transaccion, retorna ErrNoSQL con mongodb o sin conexion
+func Begin(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	if !IsSQL() {
+		return nil, ErrNoSQL
+	}
+	return db.BeginTx(ctx, opts)
+}
+
+// IsSQL indica si hay una conexion sql (mysql o postgresql) para Conn y Begin
+func IsSQL() bool {
+	return db != nil && dbDriver != "mongodb"
+}
@@ -0,0 +1,229 @@
+package orm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// UpsertAction lo que paso con una fila de Upsert
+type UpsertAction string
+
+const (
+	Created UpsertAction = "created"
+	Updated UpsertAction = "updated"
+)
+
+// Upsert inserta las filas o actualiza las que ya existen segun las columnas keys (deben tener un indice unico)
+// en lotes de chunk filas por consulta, retorna la accion de cada fila en el mismo orden. usa Conn (la transaccion del contexto)
+// las filas pueden tener columnas distintas (un campo omitempty): se guardan agrupadas por sus columnas
+// y al actualizar solo se cambian las columnas que tiene la fila, todas deben tener las keys
+// la tabla puede llevar el esquema (public.products), las columnas no llevan la tabla
+//
+//	actions, err := orm.Upsert(ctx, "products", []string{"sku"}, rows, 500)
+//
+// MySQL usa ON DUPLICATE KEY UPDATE y PostgreSQL ON CONFLICT, las filas existentes se buscan antes de cada lote
+// para saber cuales se crean y cuales se actualizan
+func Upsert(ctx context.Context, table string, keys []string, rows []map[string]any, chunk int) ([]UpsertAction, error) {
+	if !IsSQL() {
+		return nil, ErrNoSQL
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("orm: upsert necesita las columnas que identifican cada fila")
+	}
+	if validation.Identifier(table) != nil {
+		return nil, fmt.Errorf("orm: nombre inválido '%s'", table)
+	}
+
+	// agrupar las filas por sus columnas, en el orden en que aparece cada grupo
+	var groups [][]int
+	var groupColumns [][]string
+	bySignature := map[string]int{}
+	for i, row := range rows {
+		columns := make([]string, 0, len(row))
+		for column := range row {
+			columns = append(columns, column)
+		}
+		sort.Strings(columns)
+		signature := strings.Join(columns, ",")
+		g, ok := bySignature[signature]
+		if !ok {
+			for _, name := range columns {
+				// las columnas del INSERT no llevan la tabla
+				if validation.Identifier(name) != nil || strings.Contains(name, ".") {
+					return nil, fmt.Errorf("orm: nombre inválido '%s'", name)
+				}
+			}
+			for _, key := range keys {
+				if !slices.Contains(columns, key) {
+					return nil, fmt.Errorf("orm: la fila %d no tiene la columna '%s'", i, key)
+				}
+			}
+			g = len(groups)
+			bySignature[signature] = g
+			groups = append(groups, nil)
+			groupColumns = append(groupColumns, columns)
+		}
+		groups[g] = append(groups[g], i)
+	}
+	if chunk <= 0 {
+		chunk = 500
+	}
+
+	actions := make([]UpsertAction, len(rows))
+	for g, indexes := range groups {
+		for start := 0; start < len(indexes); start += chunk {
+			batchIndexes := indexes[start:min(start+chunk, len(indexes))]
+			batch := make([]map[string]any, len(batchIndexes))
+			for j, i := range batchIndexes {
+				batch[j] = rows[i]
+			}
+			existing, err := existingKeys(ctx, table, keys, batch)
+			if err != nil {
+				return nil, err
+			}
+			if err := upsertChunk(ctx, table, keys, groupColumns[g], batch); err != nil {
+				return nil, err
+			}
+			for j, row := range batch {
+				if existing[rowKey(row, keys)] {
+					actions[batchIndexes[j]] = Updated
+				} else {
+					actions[batchIndexes[j]] = Created
+				}
+			}
+		}
+	}
+	return actions, nil
+}
+
+// RowKey identificador de la fila segun sus columnas keys, sirve para quitar duplicados antes de Upsert
+// los numeros se comparan por su valor: 1234567 es la misma llave como int64, float64 (1.234567e+06) o json.Number
+func RowKey(row map[string]any, keys []string) string {
+	return rowKey(row, keys)
+}
+
+func rowKey(row map[string]any, keys []string) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = keyPart(row[key])
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// keyPart texto de un valor de la llave, los numeros sin notacion cientifica ni ceros de mas
+func keyPart(value any) string {
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return strconv.FormatInt(n, 10)
+		}
+		if f, err := v.Float64(); err == nil {
+			return strconv.FormatFloat(f, 'f', -1, 64)
+		}
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case []byte:
+		return string(v)
+	}
+	return fmt.Sprint(value)
+}
+
+// existingKeys busca cuales filas del lote ya existen: WHERE (sku) IN ((?), (?))
+func existingKeys(ctx context.Context, table string, keys []string, rows []map[string]any) (map[string]bool, error) {
+	tuple := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(keys)), ", ") + ")"
+	tuples := make([]string, len(rows))
+	args := make([]any, 0, len(rows)*len(keys))
+	for i, row := range rows {
+		tuples[i] = tuple
+		for _, key := range keys {
+			args = append(args, row[key])
+		}
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE (%s) IN (%s)", strings.Join(keys, ", "), table, strings.Join(keys, ", "), strings.Join(tuples, ", "))
+
+	start := time.Now()
+	result, err := Conn(ctx).QueryContext(ctx, query, args...)
+	recordQuery(ctx, query, args, start, err)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	existing := map[string]bool{}
+	values := make([]any, len(keys))
+	ptrs := make([]any, len(keys))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	for result.Next() {
+		if err := result.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		row := make(map[string]any, len(keys))
+		for i, key := range keys {
+			if b, ok := values[i].([]byte); ok {
+				values[i] = string(b)
+			}
+			row[key] = values[i]
+		}
+		existing[rowKey(row, keys)] = true
+	}
+	return existing, result.Err()
+}
+
+// upsertChunk inserta o actualiza un lote con una sola consulta
+func upsertChunk(ctx context.Context, table string, keys []string, columns []string, rows []map[string]any) error {
+	tuple := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+	tuples := make([]string, len(rows))
+	args := make([]any, 0, len(rows)*len(columns))
+	for i, row := range rows {
+		tuples[i] = tuple
+		for _, column := range columns {
+			args = append(args, row[column])
+		}
+	}
+
+	var sets []string
+	for _, column := range columns {
+		if slices.Contains(keys, column) {
+			continue
+		}
+		if dbDriver == "postgresql" {
+			sets = append(sets, column+" = EXCLUDED."+column)
+		} else {
+			sets = append(sets, column+" = VALUES("+column+")")
+		}
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", table, strings.Join(columns, ", "), strings.Join(tuples, ", "))
+	switch {
+	case dbDriver == "postgresql" && len(sets) == 0:
+		query += " ON CONFLICT (" + strings.Join(keys, ", ") + ") DO NOTHING"
+	case dbDriver == "postgresql":
+		query += " ON CONFLICT (" + strings.Join(keys, ", ") + ") DO UPDATE SET " + strings.Join(sets, ", ")
+	case len(sets) == 0:
+		// sin columnas que actualizar la fila existente se deja igual
+		query += " ON DUPLICATE KEY UPDATE " + keys[0] + " = " + keys[0]
+	default:
+		query += " ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", ")
+	}
+
+	start := time.Now()
+	_, err := Conn(ctx).ExecContext(ctx, query, args...)
+	recordQuery(ctx, query, args, start, err)
+	return err
+}
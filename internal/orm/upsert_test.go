@@ -0,0 +1,151 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeDriver base de datos de prueba: las consultas SELECT retornan las llaves de existing
+// y las demas se guardan en execs para revisarlas
+type fakeDriver struct {
+	mu       sync.Mutex
+	existing []int64
+	execs    []string
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return &fakeConn{d: d}, nil }
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return nil, driver.ErrSkip }
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	c.d.execs = append(c.d.execs, query)
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	return &fakeRows{values: append([]int64{}, c.d.existing...)}, nil
+}
+
+type fakeRows struct{ values []int64 }
+
+func (r *fakeRows) Columns() []string { return []string{"id"} }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	dest[0], r.values = r.values[0], r.values[1:]
+	return nil
+}
+
+var (
+	fake         = &fakeDriver{}
+	registerFake sync.Once
+)
+
+// useFakeDB conecta el paquete a fakeDriver como postgresql mientras dura la prueba
+func useFakeDB(t *testing.T, existing ...int64) *fakeDriver {
+	registerFake.Do(func() { sql.Register("orm_fake", fake) })
+	fake.mu.Lock()
+	fake.existing, fake.execs = existing, nil
+	fake.mu.Unlock()
+	conn, err := sql.Open("orm_fake", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	previousDB, previousDriver := db, dbDriver
+	db, dbDriver = conn, "postgresql"
+	t.Cleanup(func() {
+		db, dbDriver = previousDB, previousDriver
+		conn.Close()
+	})
+	return fake
+}
+
+func TestRowKeyNormalizesNumbers(t *testing.T) {
+	want := RowKey(map[string]any{"id": int64(1234567)}, []string{"id"})
+	for _, value := range []any{1234567, float64(1234567), float32(1234567), json.Number("1234567"), json.Number("1.234567e6"), []byte("1234567")} {
+		if got := RowKey(map[string]any{"id": value}, []string{"id"}); got != want {
+			t.Errorf("RowKey(%T %v) = %q, se esperaba %q", value, value, got, want)
+		}
+	}
+	if RowKey(map[string]any{"id": 1.5}, []string{"id"}) == RowKey(map[string]any{"id": 1}, []string{"id"}) {
+		t.Errorf("1.5 y 1 no son la misma llave")
+	}
+}
+
+func TestUpsertActions(t *testing.T) {
+	d := useFakeDB(t, 1234567)
+	rows := []map[string]any{
+		{"id": float64(1234567), "name": "existe"}, // el json lo deja en float64 y la base de datos en int64
+		{"id": float64(2), "name": "nuevo", "price": 10.5},
+		{"id": float64(3), "name": "otro"},
+	}
+	actions, err := Upsert(context.Background(), "products", []string{"id"}, rows, 500)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []UpsertAction{Updated, Created, Created}
+	for i := range want {
+		if actions[i] != want[i] {
+			t.Errorf("fila %d: %s, se esperaba %s", i, actions[i], want[i])
+		}
+	}
+
+	// una consulta por grupo de columnas, la fila sin price no pone price en NULL
+	if len(d.execs) != 2 {
+		t.Fatalf("se esperaban 2 consultas, se obtuvieron %d: %v", len(d.execs), d.execs)
+	}
+	for _, query := range d.execs {
+		if strings.Contains(query, "(id, name)") && strings.Contains(query, "price") {
+			t.Errorf("la consulta de las filas sin price no debe tocar price: %s", query)
+		}
+	}
+}
+
+func TestUpsertRejectsRowsWithoutKeys(t *testing.T) {
+	useFakeDB(t)
+	rows := []map[string]any{{"id": 1, "name": "a"}, {"name": "b"}}
+	if _, err := Upsert(context.Background(), "products", []string{"id"}, rows, 500); err == nil {
+		t.Errorf("se esperaba un error por la fila sin id")
+	}
+	rows = []map[string]any{{"id": 1, "name; DROP TABLE products": "a"}}
+	if _, err := Upsert(context.Background(), "products", []string{"id"}, rows, 500); err == nil {
+		t.Errorf("se esperaba un error por el nombre de columna inválido")
+	}
+}
+
+func TestUpsertIdentifiers(t *testing.T) {
+	useFakeDB(t)
+	cases := []struct {
+		name  string
+		table string
+		row   map[string]any
+		ok    bool
+	}{
+		{"tabla", "products", map[string]any{"id": 1}, true},
+		{"tabla con esquema", "public.products", map[string]any{"id": 1}, true},
+		{"tabla inválida", "products;", map[string]any{"id": 1}, false},
+		{"columna con tabla", "products", map[string]any{"id": 1, "products.name": "a"}, false},
+	}
+	for _, c := range cases {
+		_, err := Upsert(context.Background(), c.table, []string{"id"}, []map[string]any{c.row}, 500)
+		if (err == nil) != c.ok {
+			t.Errorf("%s: error = %v, se esperaba ok %v", c.name, err, c.ok)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/donbarrigon/new-project/internal/request"
+	"github.com/donbarrigon/new-project/lib/ids"
+)
+
+// Outbox transaccional: los eventos de una mutacion se guardan en la misma transaccion que los datos,
+// si la transaccion falla no queda ningun evento y si se confirma el evento no se pierde aunque el broker
+// (cola, webhook, bus de eventos) este caido. Relay los lee despues y los publica, al menos una vez
+//
+//	err := box.Tx(ctx.Request.Context(), func(tx *sql.Tx) error {
+//		if _, err := tx.ExecContext(ctx, "UPDATE orders SET status = 'paid' WHERE id = ?", id); err != nil {
+//			return err
+//		}
+//		return box.Add(ctx.Request.Context(), tx, "order.paid", OrderPaid{OrderID: id})
+//	})
+//
+// con el middleware UnitOfWork la transaccion de la solicitud esta en el contexto:
+//
+//	tx, _ := orm.Tx(ctx.Request.Context())
+//	err := box.Add(ctx.Request.Context(), tx, "order.paid", OrderPaid{OrderID: id})
+//
+// cada evento lleva la correlacion de la solicitud (request.Correlation) para unir los logs del consumidor
+// con los de la solicitud que hizo el cambio
+//
+//	CREATE TABLE outboxes (
+//		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+//		event_id CHAR(26) NOT NULL UNIQUE, topic VARCHAR(255) NOT NULL, event_key VARCHAR(255),
+//		payload JSON NOT NULL, correlation JSON, attempts INT NOT NULL DEFAULT 0, last_error TEXT,
+//		created_at DATETIME NOT NULL, available_at DATETIME NOT NULL, published_at DATETIME, failed_at DATETIME,
+//		INDEX outbox_pending (published_at, failed_at, available_at)
+//	)
+//
+// la migracion esta en internal/database/migration/tables
+
+// Event evento guardado en el outbox
+type Event struct {
+	ID          string              `json:"id"` // ULID, el consumidor lo usa para descartar duplicados
+	Topic       string              `json:"topic"`
+	Key         string              `json:"key,omitempty"` // clave de particion (id del agregado)
+	Payload     json.RawMessage     `json:"payload"`
+	Correlation request.Correlation `json:"correlation"`
+	CreatedAt   time.Time           `json:"created_at"`
+	Attempts    int                 `json:"attempts"` // intentos fallidos anteriores
+}
+
+// Execer lo implementan *sql.Tx y *sql.DB, Add debe recibir la transaccion de la mutacion
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// Outbox tabla de eventos pendientes
+type Outbox struct {
+	DB    *sql.DB
+	Table string
+}
+
+// New crea el outbox sobre la tabla indicada, "" usa outboxes (la tabla de la migracion)
+func New(db *sql.DB, table string) *Outbox {
+	if table == "" {
+		table = "outboxes"
+	}
+	return &Outbox{DB: db, Table: table}
+}
+
+// Option configura un evento al agregarlo
+type Option func(e *Event, availableAt *time.Time)
+
+// WithKey clave de particion del evento, los brokers con particiones (Kafka) mantienen el orden por clave
+func WithKey(key string) Option {
+	return func(e *Event, _ *time.Time) { e.Key = key }
+}
+
+// Delay publica el evento despues de d (recordatorios, reintentos programados)
+func Delay(d time.Duration) Option {
+	return func(_ *Event, availableAt *time.Time) { *availableAt = availableAt.Add(d) }
+}
+
+// Add guarda el evento con la transaccion tx, payload se serializa en json
+func (o *Outbox) Add(ctx context.Context, tx Execer, topic string, payload any, options ...Option) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("outbox: %w", err)
+	}
+	now := time.Now().UTC()
+	event := Event{ID: ids.NewULIDAt(now).String(), Topic: topic, Payload: data, Correlation: request.CorrelationFrom(ctx), CreatedAt: now}
+	availableAt := now
+	for _, option := range options {
+		option(&event, &availableAt)
+	}
+	correlation, err := json.Marshal(event.Correlation)
+	if err != nil {
+		return fmt.Errorf("outbox: %w", err)
+	}
+	query := fmt.Sprintf("INSERT INTO %s (event_id, topic, event_key, payload, correlation, created_at, available_at) VALUES (?, ?, ?, ?, ?, ?, ?)", o.Table)
+	_, err = tx.ExecContext(ctx, query, event.ID, event.Topic, event.Key, []byte(event.Payload), correlation, event.CreatedAt, availableAt)
+	return err
+}
+
+// Tx ejecuta fn en una transaccion, la confirma si fn no retorna error y la revierte si retorna error o entra en panico
+func (o *Outbox) Tx(ctx context.Context, fn func(tx *sql.Tx) error) (err error) {
+	tx, err := o.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+	if err := fn(tx); err != nil {
+		return errors.Join(err, ignoreDone(tx.Rollback()))
+	}
+	return tx.Commit()
+}
+
+// Prune borra los eventos publicados hace mas de olderThan, retorna cuantos borro
+func (o *Outbox) Prune(ctx context.Context, olderThan time.Duration) (int64, error) {
+	query := fmt.Sprintf("DELETE FROM %s WHERE published_at IS NOT NULL AND published_at < ?", o.Table)
+	res, err := o.DB.ExecContext(ctx, query, time.Now().UTC().Add(-olderThan))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// ignoreDone el rollback de una transaccion que ya termino no es un error
+func ignoreDone(err error) error {
+	if errors.Is(err, sql.ErrTxDone) {
+		return nil
+	}
+	return err
+}
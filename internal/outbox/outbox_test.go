@@ -0,0 +1,271 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/donbarrigon/new-project/internal/request"
+)
+
+// fakeRow fila de la tabla outboxes en fakeDriver
+type fakeRow struct {
+	id          int64
+	eventID     string
+	topic       string
+	key         string
+	payload     []byte
+	correlation []byte
+	attempts    int64
+	lastError   string
+	createdAt   time.Time
+	availableAt time.Time
+	publishedAt time.Time
+	failedAt    time.Time
+}
+
+// fakeDriver tabla outboxes en memoria: entiende solo las consultas que hacen Outbox y Relay
+// la transaccion guarda una copia de las filas y Rollback la restaura
+type fakeDriver struct {
+	mu       sync.Mutex
+	rows     []*fakeRow
+	snapshot []fakeRow
+	inTx     bool
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return &fakeConn{d: d}, nil }
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("sin prepare") }
+func (c *fakeConn) Close() error                        { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	c.d.snapshot = c.d.snapshot[:0]
+	for _, r := range c.d.rows {
+		c.d.snapshot = append(c.d.snapshot, *r)
+	}
+	c.d.inTx = true
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	c.d.inTx = false
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	c.d.rows = c.d.rows[:0]
+	for i := range c.d.snapshot {
+		r := c.d.snapshot[i]
+		c.d.rows = append(c.d.rows, &r)
+	}
+	c.d.inTx = false
+	return nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	v := func(i int) any { return args[i].Value }
+	switch {
+	case strings.HasPrefix(query, "INSERT"):
+		c.d.rows = append(c.d.rows, &fakeRow{
+			id: int64(len(c.d.rows) + 1), eventID: v(0).(string), topic: v(1).(string), key: v(2).(string),
+			payload: v(3).([]byte), correlation: v(4).([]byte), createdAt: v(5).(time.Time), availableAt: v(6).(time.Time),
+		})
+		return driver.RowsAffected(1), nil
+	case strings.HasPrefix(query, "DELETE"):
+		var n int64
+		kept := c.d.rows[:0]
+		for _, r := range c.d.rows {
+			if !r.publishedAt.IsZero() && r.publishedAt.Before(v(0).(time.Time)) {
+				n++
+				continue
+			}
+			kept = append(kept, r)
+		}
+		c.d.rows = kept
+		return driver.RowsAffected(n), nil
+	}
+	r := c.d.find(args[len(args)-1].Value.(string))
+	if r == nil {
+		return driver.RowsAffected(0), nil
+	}
+	switch {
+	case strings.Contains(query, "SET published_at"):
+		r.publishedAt = v(0).(time.Time)
+	case strings.Contains(query, "failed_at = ?"):
+		r.attempts, r.lastError, r.failedAt = v(0).(int64), v(1).(string), v(2).(time.Time)
+	case strings.Contains(query, "available_at = ?"):
+		r.attempts, r.lastError, r.availableAt = v(0).(int64), v(1).(string), v(2).(time.Time)
+	default:
+		return nil, errors.New("consulta no soportada: " + query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	now, limit := args[0].Value.(time.Time), args[1].Value.(int64)
+	rows := &fakeRows{}
+	for _, r := range c.d.rows {
+		if r.publishedAt.IsZero() && r.failedAt.IsZero() && !r.availableAt.After(now) && int64(len(rows.values)) < limit {
+			var key any
+			if r.key != "" {
+				key = r.key
+			}
+			rows.values = append(rows.values, []driver.Value{r.eventID, r.topic, key, r.payload, r.correlation, r.attempts, r.createdAt})
+		}
+	}
+	return rows, nil
+}
+
+func (d *fakeDriver) find(eventID string) *fakeRow {
+	for _, r := range d.rows {
+		if r.eventID == eventID {
+			return r
+		}
+	}
+	return nil
+}
+
+type fakeRows struct{ values [][]driver.Value }
+
+func (r *fakeRows) Columns() []string {
+	return []string{"event_id", "topic", "event_key", "payload", "correlation", "attempts", "created_at"}
+}
+func (r *fakeRows) Close() error { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+// fakeConnector conecta sql.OpenDB con la fakeDriver de cada prueba
+type fakeConnector struct{ d *fakeDriver }
+
+func (c *fakeConnector) Connect(context.Context) (driver.Conn, error) { return c.d.Open("") }
+func (c *fakeConnector) Driver() driver.Driver                        { return c.d }
+
+// newFakeOutbox outbox sobre una fakeDriver nueva, con una sola conexion como una transaccion real
+func newFakeOutbox(t *testing.T) (*Outbox, *fakeDriver) {
+	d := &fakeDriver{}
+	db := sql.OpenDB(&fakeConnector{d: d})
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return New(db, ""), d
+}
+
+type orderPaid struct {
+	OrderID int `json:"order_id"`
+}
+
+func TestAdd(t *testing.T) {
+	box, d := newFakeOutbox(t)
+	ctx := request.Correlation{RequestID: "req-1"}.Context(context.Background())
+	before := time.Now().UTC()
+
+	cases := []struct {
+		name    string
+		options []Option
+		key     string
+		delay   time.Duration
+	}{
+		{"sin opciones", nil, "", 0},
+		{"con clave", []Option{WithKey("order-7")}, "order-7", 0},
+		{"con espera", []Option{WithKey("order-8"), Delay(time.Hour)}, "order-8", time.Hour},
+	}
+	for i, c := range cases {
+		if err := box.Add(ctx, box.DB, "order.paid", orderPaid{OrderID: 7}, c.options...); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		r := d.rows[i]
+		if r.topic != "order.paid" || r.key != c.key || string(r.payload) != `{"order_id":7}` || len(r.eventID) != 26 {
+			t.Errorf("%s: fila inesperada %+v", c.name, r)
+		}
+		if wait := r.availableAt.Sub(r.createdAt); wait != c.delay || r.createdAt.Before(before) {
+			t.Errorf("%s: se esperaba disponible %v despues de crearse, se obtuvo %v", c.name, c.delay, wait)
+		}
+		var correlation request.Correlation
+		if json.Unmarshal(r.correlation, &correlation); correlation.RequestID != "req-1" {
+			t.Errorf("%s: el evento debe llevar la correlacion de la solicitud, se obtuvo %s", c.name, r.correlation)
+		}
+	}
+	if d.rows[0].eventID == d.rows[1].eventID || d.rows[1].eventID == d.rows[2].eventID {
+		t.Errorf("cada evento debe tener su propio id")
+	}
+
+	if err := box.Add(ctx, box.DB, "order.paid", func() {}); err == nil {
+		t.Errorf("se esperaba error con un payload que no se puede serializar")
+	}
+}
+
+func TestTx(t *testing.T) {
+	box, d := newFakeOutbox(t)
+	ctx := context.Background()
+	failing := errors.New("fallo la mutacion")
+
+	err := box.Tx(ctx, func(tx *sql.Tx) error { return box.Add(ctx, tx, "order.paid", orderPaid{1}) })
+	if err != nil || len(d.rows) != 1 {
+		t.Fatalf("la transaccion confirmada debe guardar el evento: %v", err)
+	}
+
+	err = box.Tx(ctx, func(tx *sql.Tx) error {
+		box.Add(ctx, tx, "order.paid", orderPaid{2})
+		return failing
+	})
+	if !errors.Is(err, failing) || len(d.rows) != 1 {
+		t.Errorf("con error no debe quedar el evento, se obtuvo %v y %d filas", err, len(d.rows))
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("el panico se debe propagar")
+			}
+		}()
+		box.Tx(ctx, func(tx *sql.Tx) error {
+			box.Add(ctx, tx, "order.paid", orderPaid{3})
+			panic("roto")
+		})
+	}()
+	if len(d.rows) != 1 || d.inTx {
+		t.Errorf("con panico la transaccion se debe revertir, quedan %d filas", len(d.rows))
+	}
+}
+
+func TestPrune(t *testing.T) {
+	box, d := newFakeOutbox(t)
+	now := time.Now().UTC()
+	d.rows = []*fakeRow{
+		{eventID: "viejo", publishedAt: now.Add(-48 * time.Hour)},
+		{eventID: "reciente", publishedAt: now.Add(-time.Hour)},
+		{eventID: "pendiente"},
+		{eventID: "fallido", failedAt: now.Add(-48 * time.Hour)},
+	}
+	n, err := box.Prune(context.Background(), 24*time.Hour)
+	if err != nil || n != 1 {
+		t.Fatalf("se esperaba 1 borrado, se obtuvo %d %v", n, err)
+	}
+	if d.find("viejo") != nil || len(d.rows) != 3 {
+		t.Errorf("solo se deben borrar los publicados antes del limite")
+	}
+}
@@ -0,0 +1,142 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Publisher entrega los eventos al broker, si retorna error el evento se reintenta mas tarde
+// debe ser idempotente o el consumidor debe descartar duplicados por Event.ID: si el proceso muere despues
+// de publicar y antes de marcar el evento, se publica otra vez
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// PublisherFunc permite usar una funcion como Publisher
+type PublisherFunc func(ctx context.Context, event Event) error
+
+func (f PublisherFunc) Publish(ctx context.Context, event Event) error {
+	return f(ctx, event)
+}
+
+// Relay publica los eventos pendientes del outbox, se ejecuta en un proceso aparte o en una goroutine
+// varios relays pueden correr a la vez, cada lote se bloquea con FOR UPDATE SKIP LOCKED (MySQL 8, PostgreSQL)
+//
+//	relay := outbox.NewRelay(box, outbox.PublisherFunc(func(ctx context.Context, e outbox.Event) error {
+//		return queue.Send(ctx, e.Topic, e.Payload)
+//	}))
+//	go relay.Run(ctx)
+type Relay struct {
+	Outbox      *Outbox
+	Publisher   Publisher
+	Batch       int           // eventos por lote
+	Interval    time.Duration // espera cuando no hay eventos pendientes
+	MaxAttempts int           // intentos antes de marcar el evento como fallido, 0 sin limite
+	// Backoff espera antes del siguiente intento, attempt empieza en 1
+	Backoff func(attempt int) time.Duration
+}
+
+// NewRelay crea el relay con lotes de 100, revisa cada segundo y hasta 10 intentos con espera exponencial
+func NewRelay(o *Outbox, p Publisher) *Relay {
+	return &Relay{Outbox: o, Publisher: p, Batch: 100, Interval: time.Second, MaxAttempts: 10, Backoff: ExponentialBackoff}
+}
+
+// ExponentialBackoff 2, 4, 8 segundos... hasta una hora
+func ExponentialBackoff(attempt int) time.Duration {
+	return min(time.Second<<min(attempt, 12), time.Hour)
+}
+
+// Run publica lotes hasta que se cancele ctx, los errores de la base de datos se escriben en el log
+// y se reintenta despues de Interval
+func (r *Relay) Run(ctx context.Context) error {
+	for {
+		n, err := r.RelayOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("outbox: %v", err)
+		}
+		if n > 0 && err == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(r.Interval):
+		}
+	}
+}
+
+// RelayOnce publica un lote de eventos pendientes, retorna cuantos leyo
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	var count int
+	err := r.Outbox.Tx(ctx, func(tx *sql.Tx) error {
+		events, err := r.pending(ctx, tx)
+		if err != nil {
+			return err
+		}
+		count = len(events)
+		for _, event := range events {
+			if err := r.publish(ctx, tx, event); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return count, err
+}
+
+// pending lee y bloquea el siguiente lote de eventos
+func (r *Relay) pending(ctx context.Context, tx *sql.Tx) ([]Event, error) {
+	query := fmt.Sprintf(`SELECT event_id, topic, event_key, payload, correlation, attempts, created_at FROM %s
+		WHERE published_at IS NULL AND failed_at IS NULL AND available_at <= ?
+		ORDER BY id LIMIT ? FOR UPDATE SKIP LOCKED`, r.Outbox.Table)
+	rows, err := tx.QueryContext(ctx, query, time.Now().UTC(), max(r.Batch, 1))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		var e Event
+		var key sql.NullString
+		var payload, correlation []byte
+		if err := rows.Scan(&e.ID, &e.Topic, &key, &payload, &correlation, &e.Attempts, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		e.Key = key.String
+		e.Payload = payload
+		if len(correlation) > 0 {
+			json.Unmarshal(correlation, &e.Correlation)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// publish entrega el evento y lo marca como publicado, o guarda el error y programa el siguiente intento
+// el contexto del Publisher lleva la correlacion de la solicitud que creo el evento
+func (r *Relay) publish(ctx context.Context, tx *sql.Tx, event Event) error {
+	now := time.Now().UTC()
+	if pubErr := r.Publisher.Publish(event.Correlation.Context(ctx), event); pubErr != nil {
+		attempt := event.Attempts + 1
+		if r.MaxAttempts > 0 && attempt >= r.MaxAttempts {
+			log.Printf("outbox: el evento %s (%s) fallo %d veces request_id=%s: %v", event.ID, event.Topic, attempt, event.Correlation.RequestID, pubErr)
+			_, err := tx.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET attempts = ?, last_error = ?, failed_at = ? WHERE event_id = ?", r.Outbox.Table),
+				attempt, pubErr.Error(), now, event.ID)
+			return err
+		}
+		backoff := ExponentialBackoff
+		if r.Backoff != nil {
+			backoff = r.Backoff
+		}
+		_, err := tx.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET attempts = ?, last_error = ?, available_at = ? WHERE event_id = ?", r.Outbox.Table),
+			attempt, pubErr.Error(), now.Add(backoff(attempt)), event.ID)
+		return err
+	}
+	_, err := tx.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET published_at = ? WHERE event_id = ?", r.Outbox.Table), now, event.ID)
+	return err
+}
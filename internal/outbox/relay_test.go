@@ -0,0 +1,130 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/donbarrigon/new-project/internal/request"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	cases := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 2 * time.Second},
+		{2, 4 * time.Second},
+		{3, 8 * time.Second},
+		{11, 2048 * time.Second},
+		{12, time.Hour},
+		{100, time.Hour},
+	}
+	for _, c := range cases {
+		if got := ExponentialBackoff(c.attempt); got != c.want {
+			t.Errorf("intento %d: se obtuvo %v, se esperaba %v", c.attempt, got, c.want)
+		}
+	}
+}
+
+func TestRelayOnce(t *testing.T) {
+	box, d := newFakeOutbox(t)
+	ctx := request.Correlation{RequestID: "req-1"}.Context(context.Background())
+	for _, topic := range []string{"order.paid", "order.broken", "order.late"} {
+		var options []Option
+		if topic == "order.late" {
+			options = append(options, Delay(time.Hour))
+		}
+		if err := box.Add(ctx, box.DB, topic, orderPaid{1}, options...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var published []string
+	var requestIDs []string
+	relay := NewRelay(box, PublisherFunc(func(ctx context.Context, e Event) error {
+		requestIDs = append(requestIDs, request.RequestID(ctx))
+		if e.Topic == "order.broken" {
+			return errors.New("broker caido")
+		}
+		published = append(published, e.Topic)
+		return nil
+	}))
+	relay.MaxAttempts = 2
+	relay.Backoff = func(attempt int) time.Duration { return time.Duration(attempt) * time.Minute }
+
+	n, err := relay.RelayOnce(context.Background())
+	if err != nil || n != 2 {
+		t.Fatalf("se esperaban 2 eventos disponibles, se obtuvo %d %v", n, err)
+	}
+	if len(published) != 1 || published[0] != "order.paid" {
+		t.Errorf("se esperaba publicado order.paid, se obtuvo %v", published)
+	}
+	for _, id := range requestIDs {
+		if id != "req-1" {
+			t.Errorf("el Publisher debe recibir la correlacion del evento, se obtuvo %q", id)
+		}
+	}
+
+	paid, broken, late := d.rows[0], d.rows[1], d.rows[2]
+	if paid.publishedAt.IsZero() {
+		t.Errorf("el evento publicado se debe marcar")
+	}
+	if broken.attempts != 1 || broken.lastError != "broker caido" || !broken.failedAt.IsZero() {
+		t.Errorf("el evento con error se debe reintentar, se obtuvo %+v", broken)
+	}
+	if wait := time.Until(broken.availableAt); wait < 50*time.Second || wait > time.Minute {
+		t.Errorf("el reintento se debe programar con Backoff, se obtuvo %v", wait)
+	}
+	if !late.publishedAt.IsZero() || late.attempts != 0 {
+		t.Errorf("el evento con Delay no debe publicarse antes de tiempo")
+	}
+
+	// sin eventos disponibles no se publica nada
+	if n, err := relay.RelayOnce(context.Background()); err != nil || n != 0 {
+		t.Errorf("no se esperaban eventos, se obtuvo %d %v", n, err)
+	}
+
+	// el segundo intento llega a MaxAttempts y el evento queda fallido
+	broken.availableAt = time.Now().UTC().Add(-time.Second)
+	if _, err := relay.RelayOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if broken.attempts != 2 || broken.failedAt.IsZero() {
+		t.Errorf("se esperaba el evento fallido despues de 2 intentos, se obtuvo %+v", broken)
+	}
+	if n, _ := relay.RelayOnce(context.Background()); n != 0 {
+		t.Errorf("un evento fallido no se vuelve a leer")
+	}
+}
+
+func TestRelayRunStopsWithContext(t *testing.T) {
+	box, _ := newFakeOutbox(t)
+	box.Add(context.Background(), box.DB, "order.paid", orderPaid{1})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	published := make(chan Event, 1)
+	relay := NewRelay(box, PublisherFunc(func(ctx context.Context, e Event) error {
+		published <- e
+		return nil
+	}))
+	relay.Interval = time.Millisecond
+
+	done := make(chan error, 1)
+	go func() { done <- relay.Run(ctx) }()
+	select {
+	case <-published:
+	case <-time.After(time.Second):
+		t.Fatal("Run no publico el evento pendiente")
+	}
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("se esperaba context.Canceled, se obtuvo %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run no termino al cancelar el contexto")
+	}
+}
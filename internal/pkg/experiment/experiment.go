@@ -0,0 +1,250 @@
+package experiment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// Experimentos A/B: cada visitante cae siempre en la misma variante (el reparto es un hash de la llave)
+// las variantes se asignan la primera vez que se piden, asi solo se registran los que vieron el experimento
+//
+//	experiment.Define(experiment.Experiment{Name: "checkout_button", Variants: []string{"control", "green"}})
+//	file, _ := os.OpenFile("storage/logs/experiments.jsonl", os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+//	experiment.OnAssign(experiment.JSONLogger(file))
+//
+//	if experiment.Variant(ctx.Request.Context(), "checkout_button") == "green" { ... }
+//
+// el middleware Experiments resuelve las llaves (usuario, ip, cookie) de cada request
+
+// Unit con que se identifica al visitante para repartirlo
+type Unit string
+
+const (
+	UnitUser   Unit = "user"   // id del usuario autenticado (request.AuthID), si no hay se usa la cookie
+	UnitIP     Unit = "ip"     // ip del cliente
+	UnitCookie Unit = "cookie" // id aleatorio guardado en la cookie ab_id
+)
+
+// Experiment definicion de un experimento, la primera variante es el control
+// Weights reparte el trafico (90, 10), vacio lo reparte por igual
+type Experiment struct {
+	Name     string   `json:"name"`
+	Variants []string `json:"variants"`
+	Weights  []int    `json:"weights,omitempty"`
+	By       Unit     `json:"by,omitempty"` // UnitCookie si es ""
+}
+
+// Assignment variante que le toco a un visitante, se entrega a los hooks de OnAssign
+type Assignment struct {
+	Experiment string    `json:"experiment"`
+	Variant    string    `json:"variant"`
+	Unit       Unit      `json:"unit"`
+	Key        string    `json:"key"`
+	Time       time.Time `json:"time"`
+}
+
+var (
+	mu          sync.RWMutex
+	experiments = map[string]Experiment{}
+	assignHooks []func(ctx context.Context, a Assignment)
+)
+
+// Define registra el experimento, se debe llamar al iniciar la aplicacion
+func Define(e Experiment) error {
+	if len(e.Variants) == 0 {
+		return fmt.Errorf("el experimento %s no tiene variantes", e.Name)
+	}
+	if len(e.Weights) > 0 && len(e.Weights) != len(e.Variants) {
+		return fmt.Errorf("el experimento %s tiene %d pesos para %d variantes", e.Name, len(e.Weights), len(e.Variants))
+	}
+	if e.By == "" {
+		e.By = UnitCookie
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	experiments[e.Name] = e
+	return nil
+}
+
+// Experiments retorna los experimentos definidos ordenados por nombre
+func Experiments() []Experiment {
+	mu.RLock()
+	defer mu.RUnlock()
+	out := make([]Experiment, 0, len(experiments))
+	for _, e := range experiments {
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// OnAssign registra una funcion que se llama la primera vez que un request pide la variante de un experimento
+// sirve para guardar las asignaciones y cruzarlas con las conversiones. se debe llamar al iniciar la aplicacion
+func OnAssign(hook func(ctx context.Context, a Assignment)) {
+	assignHooks = append(assignHooks, hook)
+}
+
+// JSONLogger retorna un hook para OnAssign que escribe cada asignacion como una linea json
+// el archivo se puede cargar en la herramienta de analisis y cruzar con las conversiones por Key
+func JSONLogger(w io.Writer) func(ctx context.Context, a Assignment) {
+	var mu sync.Mutex
+	return func(ctx context.Context, a Assignment) {
+		line, err := json.Marshal(a)
+		if err != nil {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if _, err := w.Write(append(line, '\n')); err != nil {
+			log.Printf("experiment: no se pudo registrar la asignación: %v", err)
+		}
+	}
+}
+
+// Identity llaves del visitante con las que se reparten las variantes
+type Identity struct {
+	User   string
+	IP     string
+	Cookie string
+}
+
+// key retorna la llave de la unidad, el usuario sin sesion usa la cookie
+func (id Identity) key(unit Unit) (Unit, string) {
+	switch unit {
+	case UnitUser:
+		if id.User != "" {
+			return UnitUser, id.User
+		}
+		return UnitCookie, id.Cookie
+	case UnitIP:
+		return UnitIP, id.IP
+	}
+	return UnitCookie, id.Cookie
+}
+
+// assigner variantes de un request, se asignan la primera vez que se piden
+type assigner struct {
+	identity Identity
+	mu       sync.Mutex
+	assigned map[string]string
+}
+
+// assignerKey llave del contexto con las variantes del request
+type assignerKey struct{}
+
+// WithIdentity guarda las llaves del visitante en el contexto, lo usa el middleware Experiments
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, assignerKey{}, &assigner{identity: identity})
+}
+
+// Variant retorna la variante del experimento para el visitante del contexto
+// el control (la primera variante) si el contexto no tiene la llave del visitante y "" si el experimento no existe
+func Variant(ctx context.Context, name string) string {
+	mu.RLock()
+	e, ok := experiments[name]
+	mu.RUnlock()
+	if !ok {
+		return ""
+	}
+	a, _ := ctx.Value(assignerKey{}).(*assigner)
+	if a == nil {
+		return e.Variants[0]
+	}
+	unit, key := a.identity.key(e.By)
+	if key == "" {
+		return e.Variants[0]
+	}
+
+	a.mu.Lock()
+	if variant, ok := a.assigned[name]; ok {
+		a.mu.Unlock()
+		return variant
+	}
+	variant := e.Variants[bucket(e, key)]
+	if a.assigned == nil {
+		a.assigned = map[string]string{}
+	}
+	a.assigned[name] = variant
+	a.mu.Unlock()
+
+	assignment := Assignment{Experiment: name, Variant: variant, Unit: unit, Key: key, Time: time.Now()}
+	for _, hook := range assignHooks {
+		hook(ctx, assignment)
+	}
+	return variant
+}
+
+// In indica si al visitante le toco la variante, para las reglas y los hooks del FormRequest
+//
+//	if experiment.In(r.Context(), "checkout_button", "green") { ... }
+func In(ctx context.Context, name string, variant string) bool {
+	return Variant(ctx, name) == variant
+}
+
+// Assigned retorna las variantes que ya se asignaron en el request (para la respuesta o los logs)
+func Assigned(ctx context.Context) map[string]string {
+	a, _ := ctx.Value(assignerKey{}).(*assigner)
+	if a == nil {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	out := make(map[string]string, len(a.assigned))
+	for k, v := range a.assigned {
+		out[k] = v
+	}
+	return out
+}
+
+// bucket elige la variante con el hash del experimento y la llave, la misma llave siempre da la misma variante
+func bucket(e Experiment, key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(e.Name))
+	h.Write([]byte{':'})
+	h.Write([]byte(key))
+	sum := h.Sum32()
+	if len(e.Weights) == 0 {
+		return int(sum % uint32(len(e.Variants)))
+	}
+	total := 0
+	for _, w := range e.Weights {
+		total += w
+	}
+	if total <= 0 {
+		return 0
+	}
+	point := int(sum % uint32(total))
+	for i, w := range e.Weights {
+		if point < w {
+			return i
+		}
+		point -= w
+	}
+	return 0
+}
+
+func init() {
+	validation.RegisterRule("variant", variantRule)
+}
+
+// variantRule el campo solo se acepta si al visitante le toco la variante (variant:checkout_button,green)
+// para cambiar las reglas segun la variante use RulesFor con experiment.Variant
+func variantRule(f *validation.Field) error {
+	if len(f.Params) < 2 {
+		return errors.New("la regla variant requiere el experimento y la variante (variant:checkout_button,green)")
+	}
+	if !In(f.Context, f.Params[0], f.Params[1]) {
+		return errors.New("el campo no está disponible")
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package experiment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// resetExperiments deja los experimentos y hooks vacios mientras dura la prueba
+func resetExperiments(t *testing.T) {
+	mu.Lock()
+	previous, previousHooks := experiments, assignHooks
+	experiments, assignHooks = map[string]Experiment{}, nil
+	mu.Unlock()
+	t.Cleanup(func() {
+		mu.Lock()
+		experiments, assignHooks = previous, previousHooks
+		mu.Unlock()
+	})
+}
+
+func TestDefine(t *testing.T) {
+	resetExperiments(t)
+	cases := []struct {
+		name string
+		e    Experiment
+		ok   bool
+	}{
+		{"valido", Experiment{Name: "a", Variants: []string{"control", "green"}}, true},
+		{"con pesos", Experiment{Name: "b", Variants: []string{"control", "green"}, Weights: []int{90, 10}}, true},
+		{"sin variantes", Experiment{Name: "c"}, false},
+		{"pesos de mas", Experiment{Name: "d", Variants: []string{"control"}, Weights: []int{50, 50}}, false},
+	}
+	for _, c := range cases {
+		if err := Define(c.e); (err == nil) != c.ok {
+			t.Errorf("%s: se obtuvo %v, se esperaba ok=%v", c.name, err, c.ok)
+		}
+	}
+	list := Experiments()
+	if len(list) != 2 || list[0].Name != "a" || list[0].By != UnitCookie {
+		t.Errorf("se esperaban a y b con la cookie por defecto, se obtuvo %+v", list)
+	}
+}
+
+func TestBucketWeights(t *testing.T) {
+	cases := []struct {
+		name string
+		e    Experiment
+		want []int // asignaciones esperadas de 10000, con 3% de margen
+	}{
+		{"por igual", Experiment{Name: "a", Variants: []string{"x", "y"}}, []int{5000, 5000}},
+		{"tres por igual", Experiment{Name: "b", Variants: []string{"x", "y", "z"}}, []int{3333, 3333, 3333}},
+		{"90/10", Experiment{Name: "c", Variants: []string{"x", "y"}, Weights: []int{90, 10}}, []int{9000, 1000}},
+		{"peso 0", Experiment{Name: "d", Variants: []string{"x", "y"}, Weights: []int{100, 0}}, []int{10000, 0}},
+		{"pesos en 0", Experiment{Name: "e", Variants: []string{"x", "y"}, Weights: []int{0, 0}}, []int{10000, 0}},
+	}
+	for _, c := range cases {
+		got := make([]int, len(c.e.Variants))
+		for i := 0; i < 10000; i++ {
+			got[bucket(c.e, fmt.Sprint("visitante-", i))]++
+		}
+		for i := range got {
+			if diff := got[i] - c.want[i]; diff > 300 || diff < -300 {
+				t.Errorf("%s: se obtuvo %v, se esperaba cerca de %v", c.name, got, c.want)
+				break
+			}
+		}
+	}
+}
+
+func TestIdentityKey(t *testing.T) {
+	full := Identity{User: "42", IP: "10.0.0.1", Cookie: "c1"}
+	cases := []struct {
+		name     string
+		identity Identity
+		by       Unit
+		unit     Unit
+		key      string
+	}{
+		{"usuario", full, UnitUser, UnitUser, "42"},
+		{"usuario sin sesion", Identity{IP: "10.0.0.1", Cookie: "c1"}, UnitUser, UnitCookie, "c1"},
+		{"ip", full, UnitIP, UnitIP, "10.0.0.1"},
+		{"cookie", full, UnitCookie, UnitCookie, "c1"},
+		{"unidad desconocida", full, Unit("otra"), UnitCookie, "c1"},
+	}
+	for _, c := range cases {
+		if unit, key := c.identity.key(c.by); unit != c.unit || key != c.key {
+			t.Errorf("%s: se obtuvo %s %q, se esperaba %s %q", c.name, unit, key, c.unit, c.key)
+		}
+	}
+}
+
+func TestVariant(t *testing.T) {
+	resetExperiments(t)
+	Define(Experiment{Name: "checkout_button", Variants: []string{"control", "green"}})
+	Define(Experiment{Name: "pricing", Variants: []string{"control", "annual"}, By: UnitUser})
+	var assignments []Assignment
+	OnAssign(func(ctx context.Context, a Assignment) { assignments = append(assignments, a) })
+
+	if got := Variant(context.Background(), "checkout_button"); got != "control" {
+		t.Errorf("sin visitante se esperaba el control, se obtuvo %q", got)
+	}
+	if got := Variant(WithIdentity(context.Background(), Identity{}), "checkout_button"); got != "control" {
+		t.Errorf("sin llave se esperaba el control, se obtuvo %q", got)
+	}
+	if got := Variant(context.Background(), "no_existe"); got != "" {
+		t.Errorf("un experimento que no existe no tiene variante, se obtuvo %q", got)
+	}
+	if len(assignments) != 0 {
+		t.Errorf("sin llave no se registran asignaciones, se obtuvieron %v", assignments)
+	}
+
+	ctx := WithIdentity(context.Background(), Identity{User: "42", Cookie: "c1"})
+	first := Variant(ctx, "checkout_button")
+	for i := 0; i < 3; i++ {
+		if Variant(ctx, "checkout_button") != first || !In(ctx, "checkout_button", first) {
+			t.Fatalf("el visitante debe recibir siempre la misma variante")
+		}
+	}
+	if again := Variant(WithIdentity(context.Background(), Identity{Cookie: "c1"}), "checkout_button"); again != first {
+		t.Errorf("la misma cookie en otro request debe dar la misma variante, se obtuvo %q y %q", first, again)
+	}
+	Variant(ctx, "pricing")
+
+	// una asignacion por experimento y request: dos de ctx y una del otro request
+	if len(assignments) != 3 {
+		t.Fatalf("se esperaban 3 asignaciones, se obtuvieron %v", assignments)
+	}
+	if a := assignments[0]; a.Experiment != "checkout_button" || a.Unit != UnitCookie || a.Key != "c1" || a.Variant != first || a.Time.IsZero() {
+		t.Errorf("asignacion inesperada %+v", a)
+	}
+	if a := assignments[2]; a.Unit != UnitUser || a.Key != "42" {
+		t.Errorf("pricing se reparte por usuario, se obtuvo %+v", a)
+	}
+
+	assigned := Assigned(ctx)
+	if len(assigned) != 2 || assigned["checkout_button"] != first {
+		t.Errorf("se esperaban las dos variantes del request, se obtuvo %v", assigned)
+	}
+	if Assigned(context.Background()) != nil {
+		t.Errorf("sin visitante no hay asignaciones")
+	}
+}
+
+func TestJSONLogger(t *testing.T) {
+	var out bytes.Buffer
+	hook := JSONLogger(&out)
+	hook(context.Background(), Assignment{Experiment: "a", Variant: "green", Unit: UnitCookie, Key: "c1"})
+	hook(context.Background(), Assignment{Experiment: "b", Variant: "control", Unit: UnitUser, Key: "42"})
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("se esperaba una linea por asignacion, se obtuvo %q", out.String())
+	}
+	var a Assignment
+	if err := json.Unmarshal([]byte(lines[1]), &a); err != nil || a.Experiment != "b" || a.Key != "42" {
+		t.Errorf("linea inesperada %s: %v", lines[1], err)
+	}
+}
+
+func TestVariantRule(t *testing.T) {
+	resetExperiments(t)
+	Define(Experiment{Name: "checkout_button", Variants: []string{"control", "green"}, Weights: []int{0, 100}})
+	type checkout struct {
+		Color string `json:"color" rules:"variant:checkout_button,green"`
+	}
+	type broken struct {
+		Color string `json:"color" rules:"variant:checkout_button"`
+	}
+	visitor := WithIdentity(context.Background(), Identity{Cookie: "c1"})
+	cases := []struct {
+		name string
+		ctx  context.Context
+		v    any
+		ok   bool
+	}{
+		{"en la variante", visitor, &checkout{Color: "green"}, true},
+		{"sin visitante cae en el control", context.Background(), &checkout{Color: "green"}, false},
+		{"sin el campo", context.Background(), &checkout{}, true},
+		{"regla sin variante", visitor, &broken{Color: "green"}, false},
+	}
+	for _, c := range cases {
+		if err := validation.StructContext(c.ctx, c.v); (err == nil) != c.ok {
+			t.Errorf("%s: se obtuvo %v, se esperaba ok=%v", c.name, err, c.ok)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package feature
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/donbarrigon/new-project/internal/request"
+	"github.com/donbarrigon/new-project/internal/tenant"
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// Feature flags: activan campos, reglas o comportamientos nuevos de a poco (por porcentaje, por usuario o tenant)
+// sin desplegar otra version. los flags se definen al iniciar y los proveedores (env, archivo, servicio remoto)
+// cambian su estado sin reiniciar
+//
+//	feature.Define(feature.Flag{Name: "checkout_v2", Description: "nuevo formulario de pago", Percent: 10})
+//	feature.Use(feature.NewEnvProvider("FEATURE_"), feature.NewFileProvider("storage/framework/features.json"))
+//
+//	func (r *Checkout) Authorize(req *http.Request) bool {
+//		return feature.Request(req, "checkout_v2")
+//	}
+//
+//	func (r *Checkout) WithValidator() error {
+//		if feature.Enabled(r.Context(), "checkout_v2") && r.Installments > 12 {
+//			return validation.ValidationErrors{"installments": {"máximo 12 cuotas"}}
+//		}
+//		return nil
+//	}
+//
+// un campo nuevo se rechaza mientras el flag esta apagado con la regla feature:
+//
+//	Installments int `json:"installments" rules:"feature:checkout_v2|min:1"`
+
+// Flag estado de un feature flag
+// se activa si Enabled es true, si la llave del request (usuario o tenant) esta en Allow
+// o si cae dentro del porcentaje Percent (la misma llave siempre cae del mismo lado)
+type Flag struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Enabled     bool     `json:"enabled"`
+	Percent     int      `json:"percent,omitempty"` // 0 a 100
+	Allow       []string `json:"allow,omitempty"`   // ids de usuarios o tenants que siempre lo tienen activo
+}
+
+// Provider lee el estado de los flags de una fuente externa
+// retorna false si la fuente no tiene el flag, asi se consulta el siguiente proveedor o la definicion
+type Provider interface {
+	Flag(ctx context.Context, name string) (Flag, bool, error)
+}
+
+var (
+	mu        sync.RWMutex
+	flags     = map[string]Flag{}
+	providers []Provider
+)
+
+// KeyFunc retorna la llave con la que se reparte el porcentaje y se revisa Allow
+// por defecto el id del usuario autenticado (request.AuthID) y si no hay el id del tenant
+var KeyFunc = func(ctx context.Context) string {
+	if id := request.AuthID(ctx); id != "" {
+		return id
+	}
+	if t, ok := tenant.FromContext(ctx); ok {
+		return t.ID
+	}
+	return ""
+}
+
+// Define registra el flag con su estado por defecto, se debe llamar al iniciar la aplicacion
+func Define(flag Flag) {
+	mu.Lock()
+	defer mu.Unlock()
+	flags[flag.Name] = flag
+}
+
+// Definitions retorna los flags definidos ordenados por nombre
+func Definitions() []Flag {
+	mu.RLock()
+	defer mu.RUnlock()
+	out := make([]Flag, 0, len(flags))
+	for _, f := range flags {
+		out = append(out, f)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Use reemplaza los proveedores, se consultan en orden y el primero que tenga el flag gana
+func Use(p ...Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+	providers = p
+}
+
+// Lookup retorna el estado del flag: el del primer proveedor que lo tenga o el de Define
+// si un proveedor falla se registra el error y se sigue con el siguiente
+func Lookup(ctx context.Context, name string) (Flag, bool) {
+	mu.RLock()
+	list := providers
+	def, defined := flags[name]
+	mu.RUnlock()
+	for _, p := range list {
+		flag, ok, err := p.Flag(ctx, name)
+		if err != nil {
+			log.Printf("feature: error leyendo el flag %s: %v", name, err)
+			continue
+		}
+		if ok {
+			flag.Name = name
+			if flag.Description == "" {
+				flag.Description = def.Description
+			}
+			return flag, true
+		}
+	}
+	return def, defined
+}
+
+// Enabled indica si el flag esta activo para el contexto, un flag que no existe esta apagado
+func Enabled(ctx context.Context, name string) bool {
+	flag, ok := Lookup(ctx, name)
+	if !ok {
+		return false
+	}
+	return flag.activeFor(KeyFunc(ctx))
+}
+
+// Request indica si el flag esta activo para el request, para usar en Authorize
+func Request(req *http.Request, name string) bool {
+	return Enabled(req.Context(), name)
+}
+
+// activeFor evalua el flag para la llave
+func (f Flag) activeFor(key string) bool {
+	if f.Enabled || f.Percent >= 100 {
+		return true
+	}
+	if key == "" {
+		return false
+	}
+	for _, allowed := range f.Allow {
+		if allowed == key {
+			return true
+		}
+	}
+	return f.Percent > 0 && bucket(f.Name, key) < f.Percent
+}
+
+// bucket reparte la llave en 0-99, depende del flag para que los mismos usuarios no reciban todos los flags primero
+func bucket(name string, key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	h.Write([]byte{':'})
+	h.Write([]byte(key))
+	return int(h.Sum32() % 100)
+}
+
+func init() {
+	validation.RegisterRule("feature", featureRule)
+}
+
+// featureRule rechaza el campo si el flag esta apagado (feature:checkout_v2)
+func featureRule(f *validation.Field) error {
+	if len(f.Params) == 0 {
+		return errors.New("la regla feature requiere el nombre del flag (feature:checkout_v2)")
+	}
+	if !Enabled(f.Context, f.Params[0]) {
+		return errors.New("el campo no está disponible")
+	}
+	return nil
+}
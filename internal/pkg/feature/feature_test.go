@@ -0,0 +1,166 @@
+package feature
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/donbarrigon/new-project/internal/request"
+	"github.com/donbarrigon/new-project/internal/tenant"
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// resetFlags deja los flags y proveedores vacios mientras dura la prueba
+func resetFlags(t *testing.T) {
+	mu.Lock()
+	previousFlags, previousProviders := flags, providers
+	flags, providers = map[string]Flag{}, nil
+	mu.Unlock()
+	t.Cleanup(func() {
+		mu.Lock()
+		flags, providers = previousFlags, previousProviders
+		mu.Unlock()
+	})
+}
+
+// staticProvider proveedor con flags fijos o con un error
+type staticProvider struct {
+	flags map[string]Flag
+	err   error
+}
+
+func (p staticProvider) Flag(ctx context.Context, name string) (Flag, bool, error) {
+	f, ok := p.flags[name]
+	return f, ok, p.err
+}
+
+func TestActiveFor(t *testing.T) {
+	cases := []struct {
+		name string
+		flag Flag
+		key  string
+		want bool
+	}{
+		{"apagado", Flag{Name: "f"}, "42", false},
+		{"encendido", Flag{Name: "f", Enabled: true}, "", true},
+		{"100%", Flag{Name: "f", Percent: 100}, "", true},
+		{"0%", Flag{Name: "f", Percent: 0}, "42", false},
+		{"permitido", Flag{Name: "f", Allow: []string{"7", "42"}}, "42", true},
+		{"no permitido", Flag{Name: "f", Allow: []string{"7"}}, "42", false},
+		{"porcentaje sin llave", Flag{Name: "f", Percent: 99}, "", false},
+		{"allow sin llave", Flag{Name: "f", Allow: []string{""}}, "", false},
+	}
+	for _, c := range cases {
+		if got := c.flag.activeFor(c.key); got != c.want {
+			t.Errorf("%s: se obtuvo %v, se esperaba %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestPercentIsStableAndProportional(t *testing.T) {
+	flag := Flag{Name: "checkout_v2", Percent: 25}
+	active := 0
+	for i := 0; i < 10000; i++ {
+		key := fmt.Sprint(i)
+		got := flag.activeFor(key)
+		if got != flag.activeFor(key) {
+			t.Fatalf("la llave %s debe caer siempre del mismo lado", key)
+		}
+		if got {
+			active++
+		}
+	}
+	if active < 2200 || active > 2800 {
+		t.Errorf("con 25%% se activaron %d de 10000", active)
+	}
+
+	// cada flag reparte distinto: no son siempre los mismos usuarios
+	same := 0
+	other := Flag{Name: "export_xlsx", Percent: 25}
+	for i := 0; i < 10000; i++ {
+		if flag.activeFor(fmt.Sprint(i)) && other.activeFor(fmt.Sprint(i)) {
+			same++
+		}
+	}
+	if same > 1000 {
+		t.Errorf("los dos flags activaron a los mismos %d usuarios", same)
+	}
+}
+
+func TestLookupOrder(t *testing.T) {
+	resetFlags(t)
+	Define(Flag{Name: "a", Description: "definido", Enabled: false})
+	Define(Flag{Name: "b", Enabled: true})
+	Use(
+		staticProvider{err: errors.New("servicio caido")},
+		staticProvider{flags: map[string]Flag{"a": {Enabled: true}}},
+		staticProvider{flags: map[string]Flag{"a": {Enabled: false}, "c": {Percent: 100}}},
+	)
+	cases := []struct {
+		name    string
+		enabled bool
+		found   bool
+	}{
+		{"a", true, true},   // el primer proveedor que lo tiene gana
+		{"b", true, true},   // ningun proveedor lo tiene, se usa Define
+		{"c", true, true},   // solo lo tiene un proveedor
+		{"d", false, false}, // no existe
+	}
+	ctx := context.Background()
+	for _, c := range cases {
+		flag, ok := Lookup(ctx, c.name)
+		if ok != c.found || Enabled(ctx, c.name) != c.enabled {
+			t.Errorf("%s: se obtuvo %+v %v", c.name, flag, ok)
+		}
+		if ok && flag.Name != c.name {
+			t.Errorf("%s: el flag debe tener su nombre, se obtuvo %q", c.name, flag.Name)
+		}
+	}
+	if flag, _ := Lookup(ctx, "a"); flag.Description != "definido" {
+		t.Errorf("la descripcion de Define se debe conservar, se obtuvo %q", flag.Description)
+	}
+	if defs := Definitions(); len(defs) != 2 || defs[0].Name != "a" || defs[1].Name != "b" {
+		t.Errorf("se esperaban las definiciones a y b ordenadas, se obtuvo %v", defs)
+	}
+}
+
+func TestEnabledKey(t *testing.T) {
+	resetFlags(t)
+	Define(Flag{Name: "beta", Allow: []string{"42", "acme"}})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	if Request(req, "beta") {
+		t.Errorf("sin usuario ni tenant el flag esta apagado")
+	}
+	if !Request(request.WithAuth(req, map[string]any{"id": 42}), "beta") {
+		t.Errorf("el usuario 42 esta permitido")
+	}
+	if Request(request.WithAuth(req, map[string]any{"id": 7}), "beta") {
+		t.Errorf("el usuario 7 no esta permitido")
+	}
+	ctx := tenant.WithTenant(req.Context(), &tenant.Tenant{ID: "acme"})
+	if !Enabled(ctx, "beta") {
+		t.Errorf("sin usuario se usa el tenant")
+	}
+}
+
+func TestFeatureRule(t *testing.T) {
+	resetFlags(t)
+	Define(Flag{Name: "checkout_v2"})
+	type checkout struct {
+		Installments *int `json:"installments" rules:"feature:checkout_v2|min:1"`
+	}
+	ctx, three := context.Background(), 3
+	if err := validation.StructContext(ctx, &checkout{}); err != nil {
+		t.Errorf("sin el campo no hay error, se obtuvo %v", err)
+	}
+	if err := validation.StructContext(ctx, &checkout{Installments: &three}); err == nil {
+		t.Errorf("con el flag apagado el campo se debe rechazar")
+	}
+	Define(Flag{Name: "checkout_v2", Enabled: true})
+	if err := validation.StructContext(ctx, &checkout{Installments: &three}); err != nil {
+		t.Errorf("con el flag encendido se esperaba nil, se obtuvo %v", err)
+	}
+}
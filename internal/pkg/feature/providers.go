@@ -0,0 +1,180 @@
+package feature
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EnvProvider lee los flags de variables de entorno con el nombre en mayusculas (FEATURE_CHECKOUT_V2)
+// el valor puede ser true/false (on/off, 1/0), un porcentaje (25%) o una lista de ids (user:1,user:2)
+type EnvProvider struct {
+	Prefix string
+}
+
+// NewEnvProvider crea el proveedor con el prefijo de las variables, "" usa FEATURE_
+func NewEnvProvider(prefix string) *EnvProvider {
+	if prefix == "" {
+		prefix = "FEATURE_"
+	}
+	return &EnvProvider{Prefix: prefix}
+}
+
+func (p *EnvProvider) Flag(ctx context.Context, name string) (Flag, bool, error) {
+	value, ok := os.LookupEnv(p.Prefix + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(name)))
+	if !ok {
+		return Flag{}, false, nil
+	}
+	value = strings.TrimSpace(value)
+	switch strings.ToLower(value) {
+	case "true", "on", "1", "yes":
+		return Flag{Enabled: true}, true, nil
+	case "false", "off", "0", "no", "":
+		return Flag{}, true, nil
+	}
+	if percent, ok := strings.CutSuffix(value, "%"); ok {
+		n, err := strconv.Atoi(percent)
+		if err != nil || n < 0 || n > 100 {
+			return Flag{}, false, fmt.Errorf("porcentaje inválido en %s: %s", name, value)
+		}
+		return Flag{Percent: n}, true, nil
+	}
+	var allow []string
+	for _, id := range strings.Split(value, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			allow = append(allow, id)
+		}
+	}
+	return Flag{Allow: allow}, true, nil
+}
+
+// FileProvider lee los flags de un archivo json con la lista de flags
+// el archivo solo se vuelve a leer cuando cambia su fecha de modificacion, si no existe no tiene flags
+//
+//	[{"name": "checkout_v2", "percent": 25}, {"name": "export_xlsx", "allow": ["42"]}]
+type FileProvider struct {
+	Path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	flags   map[string]Flag
+}
+
+// NewFileProvider crea el proveedor con el archivo indicado
+func NewFileProvider(path string) *FileProvider {
+	return &FileProvider{Path: path}
+}
+
+func (p *FileProvider) Flag(ctx context.Context, name string) (Flag, bool, error) {
+	info, err := os.Stat(p.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Flag{}, false, nil
+	}
+	if err != nil {
+		return Flag{}, false, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.flags == nil || !info.ModTime().Equal(p.modTime) {
+		data, err := os.ReadFile(p.Path)
+		if err != nil {
+			return Flag{}, false, err
+		}
+		flags, err := decodeFlags(data)
+		if err != nil {
+			return Flag{}, false, fmt.Errorf("%s: %w", p.Path, err)
+		}
+		p.flags = flags
+		p.modTime = info.ModTime()
+	}
+	flag, ok := p.flags[name]
+	return flag, ok, nil
+}
+
+// RemoteProvider lee los flags de un servicio que responde la lista de flags en json (igual que el archivo)
+// la respuesta se guarda TTL, si el servicio falla se siguen usando los ultimos flags que se leyeron
+type RemoteProvider struct {
+	URL    string
+	Header http.Header // cabeceras extra, por ejemplo Authorization
+	TTL    time.Duration
+	Client *http.Client
+
+	mu      sync.Mutex
+	fetched time.Time
+	flags   map[string]Flag
+}
+
+// NewRemoteProvider crea el proveedor con un TTL de 30 segundos y un timeout de 5 segundos
+func NewRemoteProvider(url string) *RemoteProvider {
+	return &RemoteProvider{URL: url, Header: http.Header{}, TTL: 30 * time.Second, Client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+func (p *RemoteProvider) Flag(ctx context.Context, name string) (Flag, bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.flags == nil || time.Since(p.fetched) >= p.TTL {
+		flags, err := p.fetch(ctx)
+		// con error se reintenta en el siguiente TTL y mientras tanto se usan los flags anteriores
+		p.fetched = time.Now()
+		if err != nil && p.flags == nil {
+			return Flag{}, false, err
+		}
+		if err == nil {
+			p.flags = flags
+		}
+	}
+	flag, ok := p.flags[name]
+	return flag, ok, nil
+}
+
+// fetch descarga la lista de flags, la cancelacion del request no la interrumpe para que un cliente
+// que se desconecta no deje la cache vacia
+func (p *RemoteProvider) fetch(ctx context.Context) (map[string]Flag, error) {
+	req, err := http.NewRequestWithContext(context.WithoutCancel(ctx), http.MethodGet, p.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range p.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Accept", "application/json")
+	res, err := p.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 300 {
+		return nil, fmt.Errorf("feature: el servicio respondió %d", res.StatusCode)
+	}
+	var list []Flag
+	if err := json.NewDecoder(res.Body).Decode(&list); err != nil {
+		return nil, err
+	}
+	return indexFlags(list), nil
+}
+
+// decodeFlags lee la lista json de flags
+func decodeFlags(data []byte) (map[string]Flag, error) {
+	var list []Flag
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	return indexFlags(list), nil
+}
+
+// indexFlags indexa los flags por nombre
+func indexFlags(list []Flag) map[string]Flag {
+	flags := make(map[string]Flag, len(list))
+	for _, f := range list {
+		flags[f.Name] = f
+	}
+	return flags
+}
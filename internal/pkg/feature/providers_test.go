@@ -0,0 +1,149 @@
+package feature
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEnvProvider(t *testing.T) {
+	cases := []struct {
+		value string
+		want  Flag
+		found bool
+		err   bool
+	}{
+		{"true", Flag{Enabled: true}, true, false},
+		{"ON", Flag{Enabled: true}, true, false},
+		{"1", Flag{Enabled: true}, true, false},
+		{"yes", Flag{Enabled: true}, true, false},
+		{"false", Flag{}, true, false},
+		{"off", Flag{}, true, false},
+		{"", Flag{}, true, false},
+		{"25%", Flag{Percent: 25}, true, false},
+		{" 100% ", Flag{Percent: 100}, true, false},
+		{"101%", Flag{}, false, true},
+		{"-1%", Flag{}, false, true},
+		{"x%", Flag{}, false, true},
+		{"user:1, user:2,", Flag{Allow: []string{"user:1", "user:2"}}, true, false},
+	}
+	p := NewEnvProvider("")
+	for _, c := range cases {
+		t.Setenv("FEATURE_CHECKOUT_V2", c.value)
+		flag, found, err := p.Flag(context.Background(), "checkout-v2")
+		if (err != nil) != c.err || found != c.found || !reflect.DeepEqual(flag, c.want) {
+			t.Errorf("%q: se obtuvo %+v %v %v, se esperaba %+v %v", c.value, flag, found, err, c.want, c.found)
+		}
+	}
+
+	os.Unsetenv("FEATURE_CHECKOUT_V2")
+	if _, found, err := p.Flag(context.Background(), "checkout.v2"); found || err != nil {
+		t.Errorf("sin la variable el proveedor no tiene el flag")
+	}
+	t.Setenv("APP_CHECKOUT_V2", "on")
+	if flag, _, _ := NewEnvProvider("APP_").Flag(context.Background(), "checkout.v2"); !flag.Enabled {
+		t.Errorf("se esperaba el flag con el prefijo APP_")
+	}
+}
+
+func TestFileProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "features.json")
+	p := NewFileProvider(path)
+	ctx := context.Background()
+
+	if _, found, err := p.Flag(ctx, "checkout_v2"); found || err != nil {
+		t.Errorf("sin archivo no hay flags, se obtuvo %v %v", found, err)
+	}
+
+	os.WriteFile(path, []byte(`[{"name": "checkout_v2", "percent": 25}, {"name": "export_xlsx", "allow": ["42"]}]`), 0o644)
+	if flag, found, err := p.Flag(ctx, "checkout_v2"); !found || err != nil || flag.Percent != 25 {
+		t.Errorf("se esperaba checkout_v2 al 25%%, se obtuvo %+v %v %v", flag, found, err)
+	}
+	if flag, _, _ := p.Flag(ctx, "export_xlsx"); len(flag.Allow) != 1 {
+		t.Errorf("se esperaba export_xlsx con allow, se obtuvo %+v", flag)
+	}
+
+	// el archivo se vuelve a leer cuando cambia su fecha
+	os.WriteFile(path, []byte(`[{"name": "checkout_v2", "enabled": true}]`), 0o644)
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(path, later, later)
+	if flag, _, _ := p.Flag(ctx, "checkout_v2"); !flag.Enabled {
+		t.Errorf("se esperaba el flag del archivo nuevo, se obtuvo %+v", flag)
+	}
+	if _, found, _ := p.Flag(ctx, "export_xlsx"); found {
+		t.Errorf("export_xlsx ya no esta en el archivo")
+	}
+
+	os.WriteFile(path, []byte(`{roto`), 0o644)
+	os.Chtimes(path, later.Add(time.Minute), later.Add(time.Minute))
+	if _, _, err := p.Flag(ctx, "checkout_v2"); err == nil {
+		t.Errorf("se esperaba error con un archivo invalido")
+	}
+}
+
+func TestRemoteProvider(t *testing.T) {
+	var calls atomic.Int32
+	var failing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`[{"name": "checkout_v2", "percent": 50}]`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	unauthorized := NewRemoteProvider(server.URL)
+	if _, found, err := unauthorized.Flag(ctx, "checkout_v2"); found || err == nil {
+		t.Errorf("sin flags leidos el error del servicio se debe retornar")
+	}
+
+	p := authorizedProvider(server.URL)
+	p.TTL = time.Hour
+	for i := 0; i < 3; i++ {
+		if flag, found, err := p.Flag(ctx, "checkout_v2"); !found || err != nil || flag.Percent != 50 {
+			t.Fatalf("se esperaba checkout_v2 al 50%%, se obtuvo %+v %v %v", flag, found, err)
+		}
+	}
+	// una llamada del proveedor sin autorizacion y una de p
+	if calls.Load() != 2 {
+		t.Errorf("dentro del TTL no se debe volver a llamar al servicio, se hicieron %d llamadas", calls.Load())
+	}
+
+	// con el servicio caido se siguen usando los ultimos flags
+	failing.Store(true)
+	p.TTL = 0
+	if flag, found, err := p.Flag(ctx, "checkout_v2"); !found || err != nil || flag.Percent != 50 {
+		t.Errorf("se esperaban los flags anteriores, se obtuvo %+v %v %v", flag, found, err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("vencido el TTL se debe consultar el servicio")
+	}
+
+	// la cancelacion del request no deja la cache vacia
+	failing.Store(false)
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, found, err := authorizedProvider(server.URL).Flag(canceled, "checkout_v2"); !found || err != nil {
+		t.Errorf("la descarga no se debe cancelar con el request, se obtuvo %v %v", found, err)
+	}
+}
+
+// authorizedProvider proveedor con la cabecera que espera el servidor de prueba
+func authorizedProvider(url string) *RemoteProvider {
+	p := NewRemoteProvider(url)
+	p.Header.Set("Authorization", "Bearer token")
+	return p
+}
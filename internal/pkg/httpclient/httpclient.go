@@ -0,0 +1,310 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/donbarrigon/new-project/internal/request"
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// Cliente para llamar a otros servicios: timeouts por defecto, reintentos con backoff para las llamadas
+// idempotentes, propaga el X-Request-Id de la solicitud que lo origina y valida las respuestas json
+// con las mismas reglas de los FormRequest
+//
+//	var payments = httpclient.New("https://payments.internal")
+//
+//	type Charge struct {
+//		ID     string `json:"id" rules:"required"`
+//		Status string `json:"status" rules:"required|slug"`
+//	}
+//
+//	var charge Charge
+//	err := payments.GetJSON(ctx.Request.Context(), "/charges/"+id, &charge)
+
+// MaxResponseSize tamaño maximo de una respuesta json que se deserializa
+var MaxResponseSize int64 = 10 << 20
+
+var (
+	// ErrInvalidResponse la respuesta no es json o no cumple las reglas del struct
+	ErrInvalidResponse = errors.New("httpclient: la respuesta del servicio no es válida")
+)
+
+// StatusError el servicio respondio con un estado que no es 2xx, Body tiene el inicio de la respuesta
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       []byte
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("httpclient: %s %s respondió %d", e.Method, e.URL, e.StatusCode)
+}
+
+// Client cliente http con reintentos, se puede compartir entre goroutines
+type Client struct {
+	BaseURL    string
+	Header     http.Header   // cabeceras de todas las llamadas, por ejemplo Authorization
+	HTTP       *http.Client  // timeout de 10 segundos por defecto
+	Retries    int           // reintentos de las llamadas idempotentes, 2 por defecto
+	Backoff    time.Duration // espera antes del primer reintento, se duplica en cada uno (con jitter)
+	MaxBackoff time.Duration // espera maxima entre reintentos, tambien limita Retry-After
+}
+
+// New crea el cliente con 10 segundos de timeout, 2 reintentos y backoff desde 200ms hasta 5s
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		Header:     http.Header{},
+		HTTP:       &http.Client{Timeout: 10 * time.Second},
+		Retries:    2,
+		Backoff:    200 * time.Millisecond,
+		MaxBackoff: 5 * time.Second,
+	}
+}
+
+// requestHooks funciones que reciben cada intento (trazas, metricas, barra de depuracion)
+var requestHooks []func(req *http.Request, res *http.Response, err error, elapsed time.Duration, attempt int)
+
+// OnRequest registra una funcion que se llama despues de cada intento, attempt empieza en 0
+// se debe llamar al iniciar la aplicacion
+//
+//	httpclient.OnRequest(func(req *http.Request, res *http.Response, err error, elapsed time.Duration, attempt int) {
+//		log.Printf("%s %s %s intento=%d", request.RequestID(req.Context()), req.Method, req.URL, attempt)
+//	})
+func OnRequest(hook func(req *http.Request, res *http.Response, err error, elapsed time.Duration, attempt int)) {
+	requestHooks = append(requestHooks, hook)
+}
+
+// Do envia la solicitud con las cabeceras del cliente y el X-Request-Id y traceparent del contexto
+// reintenta las llamadas idempotentes (GET, HEAD, OPTIONS, PUT, DELETE o con Idempotency-Key)
+// si falla la conexion o el servicio responde 429, 502, 503 o 504, respetando Retry-After
+// el body se vuelve a enviar con req.GetBody (http.NewRequest lo llena con bytes.Reader y strings.Reader)
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	for k, v := range c.Header {
+		if _, ok := req.Header[k]; !ok {
+			req.Header[k] = v
+		}
+	}
+	if id := request.RequestID(req.Context()); id != "" && req.Header.Get(request.RequestIDHeader) == "" {
+		req.Header.Set(request.RequestIDHeader, id)
+	}
+	if tp := request.TraceParent(req.Context()); tp != "" && req.Header.Get(request.TraceParentHeader) == "" {
+		req.Header.Set(request.TraceParentHeader, tp)
+	}
+
+	retries := 0
+	if retryable(req) {
+		retries = c.Retries
+	}
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+		start := time.Now()
+		res, err := c.client().Do(req)
+		for _, hook := range requestHooks {
+			hook(req, res, err, time.Since(start), attempt)
+		}
+		if attempt >= retries || !shouldRetry(res, err) || req.Context().Err() != nil {
+			return res, err
+		}
+
+		wait := c.backoff(attempt, res)
+		if res != nil {
+			// se descarta el body para reutilizar la conexion
+			io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+			res.Body.Close()
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// JSON envia in como json (nil sin body) y deserializa la respuesta en out (nil la descarta)
+// si out es un struct con reglas se valida, un error de validacion retorna ErrInvalidResponse
+// un estado que no es 2xx retorna *StatusError
+func (c *Client) JSON(ctx context.Context, method string, path string, in any, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.url(path), body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		head, _ := io.ReadAll(io.LimitReader(res.Body, 4<<10))
+		return &StatusError{Method: method, URL: req.URL.String(), StatusCode: res.StatusCode, Body: head}
+	}
+	if out == nil || res.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return Decode(ctx, res.Body, out)
+}
+
+// GetJSON hace un GET y deserializa la respuesta en out
+func (c *Client) GetJSON(ctx context.Context, path string, out any) error {
+	return c.JSON(ctx, http.MethodGet, path, nil, out)
+}
+
+// PostJSON hace un POST con in como json y deserializa la respuesta en out
+// los POST no se reintentan salvo que lleven la cabecera Idempotency-Key (ver c.Header)
+func (c *Client) PostJSON(ctx context.Context, path string, in any, out any) error {
+	return c.JSON(ctx, http.MethodPost, path, in, out)
+}
+
+// Decode deserializa el json en out y lo valida con las reglas del tag rules si es un struct o un slice de structs
+// los campos que no existen en out se ignoran, el servicio puede agregar campos sin romper al cliente
+func Decode(ctx context.Context, r io.Reader, out any) error {
+	data, err := io.ReadAll(io.LimitReader(r, MaxResponseSize+1))
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) > MaxResponseSize {
+		return fmt.Errorf("%w: supera %d bytes", ErrInvalidResponse, MaxResponseSize)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidResponse, err)
+	}
+	if err := validateResponse(ctx, out); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidResponse, err)
+	}
+	return nil
+}
+
+// validateResponse valida la respuesta si es un struct o un slice de structs, otros tipos no tienen reglas
+func validateResponse(ctx context.Context, out any) error {
+	t := reflect.TypeOf(out)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		return validation.StructContext(ctx, out)
+	case reflect.Slice:
+		elem := t.Elem()
+		for elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		if elem.Kind() == reflect.Struct {
+			return validation.SliceContext(ctx, out)
+		}
+	}
+	return nil
+}
+
+// url une la ruta con BaseURL, una url completa se usa tal cual
+func (c *Client) url(path string) string {
+	if c.BaseURL == "" || strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	return c.BaseURL + "/" + strings.TrimLeft(path, "/")
+}
+
+func (c *Client) client() *http.Client {
+	if c.HTTP == nil {
+		return http.DefaultClient
+	}
+	return c.HTTP
+}
+
+// backoff espera antes del reintento: Retry-After si el servicio lo envia o Backoff*2^attempt con jitter
+func (c *Client) backoff(attempt int, res *http.Response) time.Duration {
+	max := c.MaxBackoff
+	if max <= 0 {
+		max = 5 * time.Second
+	}
+	if res != nil {
+		if after := retryAfter(res.Header.Get("Retry-After")); after > 0 {
+			return min(after, max)
+		}
+	}
+	base := c.Backoff
+	if base <= 0 {
+		base = 200 * time.Millisecond
+	}
+	wait := base << attempt
+	if wait <= 0 || wait > max {
+		wait = max
+	}
+	// jitter entre 50% y 100% para que los clientes no reintenten todos al tiempo
+	return wait/2 + time.Duration(rand.Int64N(int64(wait/2)+1))
+}
+
+// retryAfter lee Retry-After en segundos o como fecha
+func retryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// retryable indica si la llamada se puede repetir sin efectos duplicados
+func retryable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// shouldRetry indica si el error o el estado son temporales
+func shouldRetry(res *http.Response, err error) bool {
+	if err != nil {
+		var urlErr *url.Error
+		return errors.As(err, &urlErr) && !errors.Is(err, context.Canceled)
+	}
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,251 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/donbarrigon/new-project/internal/request"
+)
+
+// newTestClient cliente sobre el servidor con esperas cortas para que los reintentos no demoren las pruebas
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	c := New(server.URL + "/")
+	c.Backoff, c.MaxBackoff = time.Millisecond, 5*time.Millisecond
+	return c
+}
+
+func TestDoRetries(t *testing.T) {
+	cases := []struct {
+		name     string
+		method   string
+		status   int
+		idemKey  bool
+		attempts int32
+	}{
+		{"GET con 503", http.MethodGet, http.StatusServiceUnavailable, false, 3},
+		{"GET con 429", http.MethodGet, http.StatusTooManyRequests, false, 3},
+		{"GET con 502", http.MethodGet, http.StatusBadGateway, false, 3},
+		{"GET con 504", http.MethodGet, http.StatusGatewayTimeout, false, 3},
+		{"GET con 500", http.MethodGet, http.StatusInternalServerError, false, 1},
+		{"GET con 404", http.MethodGet, http.StatusNotFound, false, 1},
+		{"PUT con 503", http.MethodPut, http.StatusServiceUnavailable, false, 3},
+		{"DELETE con 503", http.MethodDelete, http.StatusServiceUnavailable, false, 3},
+		{"POST con 503", http.MethodPost, http.StatusServiceUnavailable, false, 1},
+		{"POST con Idempotency-Key", http.MethodPost, http.StatusServiceUnavailable, true, 3},
+		{"PATCH con 503", http.MethodPatch, http.StatusServiceUnavailable, false, 1},
+	}
+	for _, c := range cases {
+		var attempts atomic.Int32
+		var bodies []string
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			b, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(b))
+			w.WriteHeader(c.status)
+		})
+		req, _ := http.NewRequest(c.method, client.url("/orders"), strings.NewReader(`{"total":10}`))
+		if c.idemKey {
+			req.Header.Set("Idempotency-Key", "abc")
+		}
+		res, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		res.Body.Close()
+		if res.StatusCode != c.status || attempts.Load() != c.attempts {
+			t.Errorf("%s: %d intentos con %d, se esperaban %d", c.name, attempts.Load(), res.StatusCode, c.attempts)
+		}
+		for i, b := range bodies {
+			if b != `{"total":10}` {
+				t.Errorf("%s: el intento %d debe reenviar el body, se recibio %q", c.name, i, b)
+			}
+		}
+	}
+}
+
+func TestDoRecoversAfterRetry(t *testing.T) {
+	var attempts atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"id":"ch_1","status":"paid"}`))
+	})
+	var out struct {
+		ID string `json:"id" rules:"required"`
+	}
+	if err := client.GetJSON(context.Background(), "charges/1", &out); err != nil || out.ID != "ch_1" || attempts.Load() != 2 {
+		t.Errorf("se esperaba la respuesta del segundo intento, se obtuvo %+v %v en %d intentos", out, err, attempts.Load())
+	}
+}
+
+func TestDoPropagatesHeaders(t *testing.T) {
+	var got http.Header
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	})
+	client.Header.Set("Authorization", "Bearer cliente")
+	ctx := request.Correlation{RequestID: "req-1", TraceParent: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"}.Context(context.Background())
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, client.url("/ping"), nil)
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if got.Get("Authorization") != "Bearer cliente" || got.Get(request.RequestIDHeader) != "req-1" || got.Get(request.TraceParentHeader) == "" {
+		t.Errorf("se esperaban las cabeceras del cliente y la correlacion, se obtuvo %v", got)
+	}
+
+	// las cabeceras del request ganan sobre las del cliente
+	req, _ = http.NewRequestWithContext(ctx, http.MethodGet, client.url("/ping"), nil)
+	req.Header.Set("Authorization", "Bearer propio")
+	req.Header.Set(request.RequestIDHeader, "otro")
+	res, _ = client.Do(req)
+	res.Body.Close()
+	if got.Get("Authorization") != "Bearer propio" || got.Get(request.RequestIDHeader) != "otro" {
+		t.Errorf("no se deben reemplazar las cabeceras del request, se obtuvo %v", got)
+	}
+}
+
+func TestDoStopsWhenContextIsCanceled(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	client.Backoff, client.MaxBackoff = time.Hour, time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, client.url("/slow"), nil)
+	if _, err := client.Do(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("se esperaba context.DeadlineExceeded durante la espera, se obtuvo %v", err)
+	}
+}
+
+type charge struct {
+	ID     string `json:"id" rules:"required"`
+	Status string `json:"status" rules:"required|slug"`
+}
+
+func TestJSON(t *testing.T) {
+	cases := []struct {
+		name   string
+		status int
+		body   string
+		out    any
+		err    error
+	}{
+		{"valido", http.StatusOK, `{"id":"ch_1","status":"paid","extra":1}`, &charge{}, nil},
+		{"slice valido", http.StatusOK, `[{"id":"ch_1","status":"paid"}]`, &[]charge{}, nil},
+		{"sin reglas", http.StatusOK, `{"a":1}`, &map[string]any{}, nil},
+		{"no cumple las reglas", http.StatusOK, `{"status":"Pagado!"}`, &charge{}, ErrInvalidResponse},
+		{"slice que no cumple", http.StatusOK, `[{"id":"ch_1"}]`, &[]charge{}, ErrInvalidResponse},
+		{"no es json", http.StatusOK, `<html>`, &charge{}, ErrInvalidResponse},
+		{"sin contenido", http.StatusNoContent, ``, &charge{}, nil},
+		{"descartada", http.StatusOK, `<html>`, nil, nil},
+	}
+	for _, c := range cases {
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(c.status)
+			io.WriteString(w, c.body)
+		})
+		err := client.JSON(context.Background(), http.MethodGet, "/charges", nil, c.out)
+		if !errors.Is(err, c.err) || (c.err == nil && err != nil) {
+			t.Errorf("%s: se obtuvo %v, se esperaba %v", c.name, err, c.err)
+		}
+	}
+}
+
+func TestJSONStatusError(t *testing.T) {
+	var gotBody, gotType string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		gotBody, gotType = string(b), r.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		io.WriteString(w, `{"error":"monto invalido"}`)
+	})
+	err := client.PostJSON(context.Background(), "/charges", map[string]int{"amount": -1}, &charge{})
+	var status *StatusError
+	if !errors.As(err, &status) || status.StatusCode != http.StatusUnprocessableEntity || string(status.Body) != `{"error":"monto invalido"}` {
+		t.Fatalf("se esperaba *StatusError con el body, se obtuvo %v", err)
+	}
+	if gotBody != `{"amount":-1}` || gotType != "application/json" {
+		t.Errorf("se esperaba el body json, se recibio %q %q", gotBody, gotType)
+	}
+}
+
+func TestDecodeMaxResponseSize(t *testing.T) {
+	previous := MaxResponseSize
+	MaxResponseSize = 10
+	t.Cleanup(func() { MaxResponseSize = previous })
+	var out map[string]any
+	if err := Decode(context.Background(), strings.NewReader(`{"a":"12345678"}`), &out); !errors.Is(err, ErrInvalidResponse) {
+		t.Errorf("se esperaba ErrInvalidResponse con una respuesta grande, se obtuvo %v", err)
+	}
+	if err := Decode(context.Background(), strings.NewReader(`{"a":1}`), &out); err != nil {
+		t.Errorf("se esperaba nil, se obtuvo %v", err)
+	}
+}
+
+func TestURL(t *testing.T) {
+	cases := []struct {
+		base string
+		path string
+		want string
+	}{
+		{"https://payments.internal/", "/charges/1", "https://payments.internal/charges/1"},
+		{"https://payments.internal", "charges/1", "https://payments.internal/charges/1"},
+		{"https://payments.internal", "https://other.internal/x", "https://other.internal/x"},
+		{"", "/charges", "/charges"},
+	}
+	for _, c := range cases {
+		if got := New(c.base).url(c.path); got != c.want {
+			t.Errorf("%q + %q: se obtuvo %q, se esperaba %q", c.base, c.path, got, c.want)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	c := &Client{Backoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	cases := []struct {
+		attempt    int
+		retryAfter string
+		min, max   time.Duration
+	}{
+		{0, "", 50 * time.Millisecond, 100 * time.Millisecond},
+		{1, "", 100 * time.Millisecond, 200 * time.Millisecond},
+		{3, "", 400 * time.Millisecond, 800 * time.Millisecond},
+		{10, "", 500 * time.Millisecond, time.Second},
+		{100, "", 500 * time.Millisecond, time.Second},
+		{0, "1", time.Second, time.Second},
+		{0, "120", time.Second, time.Second},
+		{0, "abc", 50 * time.Millisecond, 100 * time.Millisecond},
+	}
+	for _, tc := range cases {
+		res := &http.Response{Header: http.Header{}}
+		if tc.retryAfter != "" {
+			res.Header.Set("Retry-After", tc.retryAfter)
+		}
+		for i := 0; i < 20; i++ {
+			if got := c.backoff(tc.attempt, res); got < tc.min || got > tc.max {
+				t.Errorf("intento %d Retry-After %q: %v fuera de [%v, %v]", tc.attempt, tc.retryAfter, got, tc.min, tc.max)
+				break
+			}
+		}
+	}
+
+	date := time.Now().Add(3 * time.Second).UTC().Format(http.TimeFormat)
+	if got := retryAfter(date); got <= time.Second || got > 3*time.Second {
+		t.Errorf("Retry-After como fecha: se obtuvo %v", got)
+	}
+}
@@ -0,0 +1,460 @@
+package resource
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/lib/crypt"
+	"github.com/donbarrigon/new-project/lib/formatter"
+)
+
+// IncludeParam parametro de la url con las relaciones a incluir (?include=posts,author.profile)
+var IncludeParam = "include"
+
+// FieldsParam parametro de la url con los campos a enviar (?fields=id,name&fields[posts]=title)
+var FieldsParam = "fields"
+
+// Transformer lo implementan los tipos que definen a mano la forma de su respuesta
+//
+//	type UserResource struct{ *model.User }
+//
+//	func (u UserResource) Transform(c *resource.Context) any {
+//		return resource.Map{
+//			"id":    u.ID,
+//			"name":  u.Name,
+//			"posts": c.WhenIncluded("posts", func() any { return resource.Collection(c.Nested("posts"), u.Posts) }),
+//		}
+//	}
+type Transformer interface {
+	Transform(c *Context) any
+}
+
+// Selectable lo implementa el transformer (o el struct) que permite elegir campos con ?fields=
+// solo se aceptan los campos de la lista, pedir otro responde 400
+// si no se implementa se aceptan todas las llaves que el recurso envia
+//
+//	func (u UserResource) SelectableFields() []string { return []string{"id", "name", "email"} }
+type Selectable interface {
+	SelectableFields() []string
+}
+
+// Map respuesta de un Transformer, las llaves con valor Missing no se envian
+type Map map[string]any
+
+// missing valor de las llaves que no se deben enviar
+type missing struct{}
+
+// Missing se usa en un Map para omitir la llave (ver WhenIncluded y When)
+var Missing any = missing{}
+
+func (missing) MarshalJSON() ([]byte, error) {
+	return []byte("null"), nil
+}
+
+// Context datos del request que usan los transformers
+type Context struct {
+	Request  *http.Request
+	includes map[string]bool
+	fields   map[string][]string // campos pedidos por relacion, "" es el recurso principal
+	errs     *[]error
+	prefix   string
+	inner    bool // el valor es un campo del recurso (un struct o mapa anidado), ?fields= no se le aplica
+}
+
+// NewContext crea el contexto leyendo ?include= y ?fields= del request
+// include=author.profile incluye author y author.profile
+func NewContext(req *http.Request) *Context {
+	c := &Context{Request: req, includes: map[string]bool{}, fields: map[string][]string{}, errs: &[]error{}}
+	if req == nil {
+		return c
+	}
+	query := req.URL.Query()
+	for key, values := range query {
+		rel := ""
+		if key != FieldsParam {
+			inner, ok := strings.CutPrefix(key, FieldsParam+"[")
+			if !ok || !strings.HasSuffix(inner, "]") {
+				continue
+			}
+			rel = strings.TrimSuffix(inner, "]")
+		}
+		for _, value := range values {
+			for _, field := range strings.Split(value, ",") {
+				if field = strings.TrimSpace(field); field != "" {
+					c.fields[rel] = append(c.fields[rel], field)
+				}
+			}
+		}
+	}
+	for _, value := range query[IncludeParam] {
+		for _, inc := range strings.Split(value, ",") {
+			inc = strings.TrimSpace(inc)
+			for inc != "" {
+				c.includes[inc] = true
+				i := strings.LastIndexByte(inc, '.')
+				if i < 0 {
+					break
+				}
+				inc = inc[:i]
+			}
+		}
+	}
+	return c
+}
+
+// Includes indica si el cliente pidio incluir la relacion
+func (c *Context) Includes(name string) bool {
+	return c.includes[c.prefix+name]
+}
+
+// Nested retorna el contexto para transformar la relacion name, sus includes son relativos a ella
+func (c *Context) Nested(name string) *Context {
+	return &Context{Request: c.Request, includes: c.includes, fields: c.fields, errs: c.errs, prefix: c.prefix + name + "."}
+}
+
+// value retorna el contexto para los campos del recurso actual, ?fields= solo se aplica al recurso y a sus relaciones
+func (c *Context) value() *Context {
+	if c.inner {
+		return c
+	}
+	return &Context{Request: c.Request, includes: c.includes, fields: c.fields, errs: c.errs, prefix: c.prefix, inner: true}
+}
+
+// Err retorna los errores de la transformacion (campos de ?fields= que no estan permitidos)
+func (c *Context) Err() error {
+	return errors.Join(*c.errs...)
+}
+
+// WhenIncluded retorna el valor solo si el cliente pidio la relacion, si no Missing
+// value solo se ejecuta si se incluye, asi no se consulta la base de datos sin necesidad
+func (c *Context) WhenIncluded(name string, value func() any) any {
+	if !c.Includes(name) {
+		return Missing
+	}
+	return value()
+}
+
+// When retorna el valor solo si cond es true, si no Missing
+func When(cond bool, value func() any) any {
+	if !cond {
+		return Missing
+	}
+	return value()
+}
+
+// Item transforma un valor para la respuesta
+// si implementa Transformer se usa su Transform, si es un struct se convierte segun el tag resource:
+//
+//	Password string `resource:"-"`                 // nunca se envia
+//	Email    string `resource:"correo"`            // se envia con otra llave
+//	Posts    []Post `resource:"posts,include"`     // solo con ?include=posts
+//	Bio      string `resource:"bio,omitempty"`     // no se envia si esta vacio
+//	Card     string `json:"card" encrypted:""`     // se envia cifrado con crypt.Default (ver crypt.FieldContext)
+//
+// sin tag resource se usa el nombre del tag json (o snake_case) y se respeta json:"-"
+func Item(c *Context, v any) any {
+	if v == nil {
+		return nil
+	}
+	if t, ok := v.(Transformer); ok {
+		return c.selectFields(v, Item(c, t.Transform(c)))
+	}
+	switch m := v.(type) {
+	case Map:
+		return transformMap(c, m)
+	case map[string]any:
+		return transformMap(c, m)
+	}
+	return transformValue(c, reflect.ValueOf(v))
+}
+
+// Collection transforma cada elemento de un slice
+func Collection(c *Context, items any) []any {
+	rv := reflect.ValueOf(items)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return []any{}
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return []any{Item(c, items)}
+	}
+	out := make([]any, rv.Len())
+	for i := range out {
+		out[i] = Item(c, rv.Index(i).Interface())
+	}
+	return out
+}
+
+// Respond transforma v y lo envia dentro de {"data": ...}
+// los slices se transforman como coleccion
+//
+//	resource.Respond(ctx, http.StatusOK, users)
+func Respond(ctx *controller.Context, statusCode int, v any) {
+	c := NewContext(ctx.Request)
+	var data any
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		data = Collection(c, v)
+	} else {
+		data = Item(c, v)
+	}
+	if err := c.Err(); err != nil {
+		ctx.ResponseError(http.StatusBadRequest, "Campos inválidos en "+FieldsParam, err.Error())
+		return
+	}
+	ctx.ResponseJSON(statusCode, map[string]any{"data": data})
+}
+
+// transformMap quita las llaves Missing y transforma los valores
+func transformMap(c *Context, m map[string]any) map[string]any {
+	out := make(map[string]any, len(m))
+	inner := c.value()
+	for k, v := range m {
+		if v == Missing {
+			continue
+		}
+		out[k] = Item(inner, v)
+	}
+	return out
+}
+
+// resourceField campo del struct que se envia
+type resourceField struct {
+	index     []int
+	key       string
+	include   bool
+	omitempty bool
+	encrypted string // nombre del campo en el contexto del cifrado, vacio si no se cifra
+}
+
+var (
+	fieldsCache   sync.Map
+	timeType      = reflect.TypeOf(time.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// transformValue convierte structs, slices y mapas recursivamente, lo demas se deja igual
+func transformValue(c *Context, rv reflect.Value) any {
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil
+		}
+		if t, ok := rv.Interface().(Transformer); ok {
+			return c.selectFields(t, Item(c, t.Transform(c)))
+		}
+		rv = rv.Elem()
+	}
+	if rv.Type().Implements(marshalerType) || rv.Type() == timeType {
+		return rv.Interface()
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		return c.selectFields(rv.Interface(), transformStruct(c, rv))
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil
+		}
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			// []byte se serializa en base64 como siempre
+			return rv.Interface()
+		}
+		return Collection(c, rv.Interface())
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return rv.Interface()
+		}
+		out := make(map[string]any, rv.Len())
+		inner := c.value()
+		iter := rv.MapRange()
+		for iter.Next() {
+			out[iter.Key().String()] = Item(inner, iter.Value().Interface())
+		}
+		return out
+	}
+	return rv.Interface()
+}
+
+// transformStruct convierte el struct en un map segun los tags
+func transformStruct(c *Context, rv reflect.Value) map[string]any {
+	fields := getFields(rv.Type())
+	out := make(map[string]any, len(fields))
+	inner := c.value()
+	for _, f := range fields {
+		if f.include && !c.Includes(f.key) {
+			continue
+		}
+		fv, err := rv.FieldByIndexErr(f.index)
+		if err != nil {
+			continue
+		}
+		if f.omitempty && fv.IsZero() {
+			continue
+		}
+		if f.encrypted != "" {
+			if value, ok := encrypt(fv, crypt.FieldContext(owner(rv), f.encrypted)); ok {
+				out[f.key] = value
+			}
+			continue
+		}
+		nested := inner
+		if f.include {
+			nested = c.Nested(f.key)
+		}
+		out[f.key] = Item(nested, fv.Interface())
+	}
+	return out
+}
+
+// owner retorna el struct para crypt.FieldContext, con puntero si se puede para los metodos RecordID con puntero
+func owner(rv reflect.Value) any {
+	if rv.CanAddr() {
+		return rv.Addr().Interface()
+	}
+	return rv.Interface()
+}
+
+// encrypt cifra el valor del campo, si no se puede (no hay llave configurada) el campo no se envia
+// para no filtrar el texto plano
+func encrypt(fv reflect.Value, context []string) (any, bool) {
+	for fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			return nil, true
+		}
+		fv = fv.Elem()
+	}
+	if fv.Kind() != reflect.String || crypt.Default == nil {
+		return nil, false
+	}
+	if fv.String() == "" {
+		return "", true
+	}
+	value, err := crypt.Default.EncryptString(fv.String(), context...)
+	if err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// jsonName nombre del campo en el json, el que usa request para los campos encrypted
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return formatter.ToSnakeCase(field.Name)
+	}
+	return name
+}
+
+// getFields retorna los campos del tipo desde el cache o los calcula
+func getFields(t reflect.Type) []resourceField {
+	if cached, ok := fieldsCache.Load(t); ok {
+		return cached.([]resourceField)
+	}
+	fields := buildFields(t, nil)
+	fieldsCache.Store(t, fields)
+	return fields
+}
+
+// buildFields lee los tags resource y json de los campos incluidos los embebidos
+func buildFields(t reflect.Type, index []int) []resourceField {
+	var fields []resourceField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldIndex := append(append([]int{}, index...), i)
+
+		tag, hasTag := field.Tag.Lookup("resource")
+		if !hasTag {
+			tag = field.Tag.Get("json")
+		}
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			fields = append(fields, buildFields(field.Type, fieldIndex)...)
+			continue
+		}
+		if name == "" {
+			name = formatter.ToSnakeCase(field.Name)
+		}
+
+		rf := resourceField{index: fieldIndex, key: name}
+		if encrypted, ok := field.Tag.Lookup("encrypted"); ok {
+			// el mismo nombre que usa request al descifrar: el del tag o el del json
+			rf.encrypted = encrypted
+			if rf.encrypted == "" {
+				rf.encrypted = jsonName(field)
+			}
+		}
+		for _, opt := range strings.Split(opts, ",") {
+			switch opt {
+			case "include":
+				rf.include = hasTag
+			case "omitempty":
+				rf.omitempty = true
+			}
+		}
+		fields = append(fields, rf)
+	}
+	return fields
+}
+
+// selectFields deja solo los campos pedidos con ?fields= para la relacion actual
+// source es el valor original, se usa para leer la lista de campos permitidos
+// los structs anidados que no son relaciones (una direccion dentro del usuario) se envian completos
+func (c *Context) selectFields(source any, transformed any) any {
+	if c.inner {
+		return transformed
+	}
+	requested, ok := c.fields[strings.TrimSuffix(c.prefix, ".")]
+	if !ok {
+		return transformed
+	}
+	m, ok := transformed.(map[string]any)
+	if !ok {
+		return transformed
+	}
+
+	var allowed map[string]bool
+	if s, ok := source.(Selectable); ok {
+		allowed = make(map[string]bool)
+		for _, f := range s.SelectableFields() {
+			allowed[f] = true
+		}
+	}
+
+	out := make(map[string]any, len(requested))
+	for _, field := range requested {
+		value, exists := m[field]
+		if allowed != nil && !allowed[field] || allowed == nil && !exists {
+			c.addError(fmt.Errorf("el campo '%s' no se puede seleccionar", c.prefix+field))
+			continue
+		}
+		if exists {
+			out[field] = value
+		}
+	}
+	return out
+}
+
+// addError guarda el error una sola vez aunque el campo se repita en una coleccion
+func (c *Context) addError(err error) {
+	for _, e := range *c.errs {
+		if e.Error() == err.Error() {
+			return
+		}
+	}
+	*c.errs = append(*c.errs, err)
+}
@@ -0,0 +1,72 @@
+package resource
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type testAddress struct {
+	City string `json:"city"`
+	Zip  string `json:"zip"`
+}
+
+type testPost struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+type testUser struct {
+	ID      int         `json:"id"`
+	Name    string      `json:"name"`
+	Address testAddress `json:"address"`
+	Posts   []testPost  `resource:"posts,include"`
+}
+
+func TestSparseFieldsets(t *testing.T) {
+	user := testUser{
+		ID:      1,
+		Name:    "ana",
+		Address: testAddress{City: "Bogotá", Zip: "110111"},
+		Posts:   []testPost{{ID: 7, Title: "hola", Body: "..."}},
+	}
+	cases := []struct {
+		name  string
+		query string
+		want  map[string]any
+		err   bool
+	}{
+		{
+			name:  "sin fields",
+			query: "",
+			want:  map[string]any{"id": 1, "name": "ana", "address": map[string]any{"city": "Bogotá", "zip": "110111"}},
+		},
+		{
+			name:  "el struct anidado se envia completo",
+			query: "?fields=id,address",
+			want:  map[string]any{"id": 1, "address": map[string]any{"city": "Bogotá", "zip": "110111"}},
+		},
+		{
+			name:  "fields de la relacion",
+			query: "?fields=id,posts&include=posts&fields[posts]=title",
+			want:  map[string]any{"id": 1, "posts": []any{map[string]any{"title": "hola"}}},
+		},
+		{
+			name:  "un campo del struct anidado no es del recurso",
+			query: "?fields=id,city",
+			want:  map[string]any{"id": 1},
+			err:   true,
+		},
+	}
+	for _, c := range cases {
+		ctx := NewContext(httptest.NewRequest("GET", "/users/1"+c.query, nil))
+		got := Item(ctx, user)
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: se obtuvo %v, se esperaba %v", c.name, got, c.want)
+		}
+		if err := ctx.Err(); (err != nil) != c.err {
+			t.Errorf("%s: error = %v, se esperaba error %v", c.name, err, c.err)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package webhook
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// GitHub verifica la cabecera X-Hub-Signature-256 (sha256=<hmac del body>)
+// GitHub no firma un timestamp asi que no se puede validar la tolerancia
+type GitHub struct {
+	Secret string
+}
+
+// NewGitHub crea el verificador para los webhooks de GitHub, ErrEmptySecret si el secreto esta vacio
+func NewGitHub(secret string) (*GitHub, error) {
+	if secret == "" {
+		return nil, ErrEmptySecret
+	}
+	return &GitHub{Secret: secret}, nil
+}
+
+func (g *GitHub) Verify(r *http.Request, body []byte) error {
+	if g.Secret == "" {
+		return ErrEmptySecret
+	}
+	header := r.Header.Get("X-Hub-Signature-256")
+	if header == "" {
+		return ErrMissingSignature
+	}
+	signature, ok := strings.CutPrefix(header, "sha256=")
+	if !ok || !equal(signature, sign(g.Secret, body)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Stripe verifica la cabecera Stripe-Signature (t=<timestamp>,v1=<firma>,v1=<firma>)
+// la firma es el hmac de "<timestamp>.<body>", puede venir mas de una v1 cuando se esta rotando el secreto
+type Stripe struct {
+	Secret    string
+	Tolerance time.Duration // si es 0 se usa DefaultTolerance
+}
+
+// NewStripe crea el verificador para los webhooks de Stripe, ErrEmptySecret si el secreto esta vacio
+func NewStripe(secret string) (*Stripe, error) {
+	if secret == "" {
+		return nil, ErrEmptySecret
+	}
+	return &Stripe{Secret: secret, Tolerance: DefaultTolerance}, nil
+}
+
+func (s *Stripe) Verify(r *http.Request, body []byte) error {
+	if s.Secret == "" {
+		return ErrEmptySecret
+	}
+	header := r.Header.Get("Stripe-Signature")
+	if header == "" {
+		return ErrMissingSignature
+	}
+
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if timestamp == "" || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+
+	if err := checkTimestamp(timestamp, s.Tolerance); err != nil {
+		return err
+	}
+
+	expected := sign(s.Secret, []byte(timestamp), []byte("."), body)
+	for _, signature := range signatures {
+		if equal(signature, expected) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// Slack verifica las cabeceras X-Slack-Request-Timestamp y X-Slack-Signature (v0=<firma>)
+// la firma es el hmac de "v0:<timestamp>:<body>"
+type Slack struct {
+	Secret    string
+	Tolerance time.Duration // si es 0 se usa DefaultTolerance
+}
+
+// NewSlack crea el verificador para las solicitudes firmadas de Slack, ErrEmptySecret si el secreto esta vacio
+func NewSlack(secret string) (*Slack, error) {
+	if secret == "" {
+		return nil, ErrEmptySecret
+	}
+	return &Slack{Secret: secret, Tolerance: DefaultTolerance}, nil
+}
+
+func (s *Slack) Verify(r *http.Request, body []byte) error {
+	if s.Secret == "" {
+		return ErrEmptySecret
+	}
+	timestamp := r.Header.Get("X-Slack-Request-Timestamp")
+	header := r.Header.Get("X-Slack-Signature")
+	if timestamp == "" || header == "" {
+		return ErrMissingSignature
+	}
+
+	if err := checkTimestamp(timestamp, s.Tolerance); err != nil {
+		return err
+	}
+
+	signature, ok := strings.CutPrefix(header, "v0=")
+	if !ok || !equal(signature, sign(s.Secret, []byte("v0:"+timestamp+":"), body)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
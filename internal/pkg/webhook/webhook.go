@@ -0,0 +1,109 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/internal/middleware"
+	"github.com/donbarrigon/new-project/internal/request"
+)
+
+// DefaultTolerance diferencia maxima permitida entre el timestamp firmado y la hora del servidor
+// evita que un atacante reenvie un webhook capturado (replay attack)
+const DefaultTolerance = 5 * time.Minute
+
+var (
+	ErrMissingSignature = errors.New("el webhook no tiene firma")
+	ErrInvalidSignature = errors.New("la firma del webhook no es válida")
+	ErrTimestamp        = errors.New("el timestamp del webhook está fuera del rango permitido")
+	// ErrEmptySecret el verificador no tiene secreto, con un secreto vacio cualquiera podria firmar
+	ErrEmptySecret = errors.New("el secreto del webhook está vacío")
+)
+
+// now se usa en lugar de time.Now para poder controlar la hora en las pruebas
+var now = time.Now
+
+// Verifier verifica que el webhook lo haya enviado el proveedor
+// body son los bytes exactos que se recibieron
+type Verifier interface {
+	Verify(r *http.Request, body []byte) error
+}
+
+// VerifierFunc permite usar una funcion como Verifier para proveedores que no estan incluidos
+type VerifierFunc func(r *http.Request, body []byte) error
+
+func (f VerifierFunc) Verify(r *http.Request, body []byte) error {
+	return f(r, body)
+}
+
+// Verify middleware que rechaza con 401 los webhooks que no pasan la verificacion
+// se ejecuta antes del controlador asi que el FormRequest nunca recibe un payload sin verificar
+// el body queda disponible para que Validate lo vuelva a leer. sin secreto responde 500 y no deja pasar nada
+//
+//	github, err := webhook.NewGitHub(os.Getenv("GITHUB_WEBHOOK_SECRET"))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	HandleFuncs("/webhooks/github", webhooks.Routes(), webhook.Verify(github))
+func Verify(v Verifier) middleware.MiddlewareFunc {
+	return func(next controller.ControllerFunc) controller.ControllerFunc {
+		return func(ctx *controller.Context) {
+			body, err := request.ReadBody(ctx.Request)
+			if err != nil {
+				ctx.ResponseError(http.StatusBadRequest, "No se pudo leer el body del webhook", nil)
+				return
+			}
+			if err := v.Verify(ctx.Request, body); err != nil {
+				if errors.Is(err, ErrEmptySecret) {
+					log.Printf("webhook: %v", err)
+					ctx.ResponseError(http.StatusInternalServerError, "El webhook no está configurado", nil)
+					return
+				}
+				ctx.ResponseError(http.StatusUnauthorized, err.Error(), nil)
+				return
+			}
+			next(ctx)
+		}
+	}
+}
+
+// sign calcula el HMAC-SHA256 en hexadecimal
+func sign(secret string, parts ...[]byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	for _, p := range parts {
+		mac.Write(p)
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// equal compara las firmas en tiempo constante para no filtrar informacion por el tiempo de respuesta
+func equal(a, b string) bool {
+	return hmac.Equal([]byte(a), []byte(b))
+}
+
+// checkTimestamp valida que el timestamp (segundos unix) este dentro de la tolerancia
+func checkTimestamp(ts string, tolerance time.Duration) error {
+	seconds, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrTimestamp, ts)
+	}
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+	diff := now().Sub(time.Unix(seconds, 0))
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > tolerance {
+		return ErrTimestamp
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+)
+
+const secret = "whsec_prueba"
+
+var body = []byte(`{"event":"paid","id":42}`)
+
+// fixedNow deja la hora del paquete en t mientras dura la prueba
+func fixedNow(t *testing.T, at time.Time) {
+	previous := now
+	now = func() time.Time { return at }
+	t.Cleanup(func() { now = previous })
+}
+
+func TestEmptySecret(t *testing.T) {
+	if _, err := NewGitHub(""); !errors.Is(err, ErrEmptySecret) {
+		t.Errorf("NewGitHub: se esperaba ErrEmptySecret, se obtuvo %v", err)
+	}
+	if _, err := NewStripe(""); !errors.Is(err, ErrEmptySecret) {
+		t.Errorf("NewStripe: se esperaba ErrEmptySecret, se obtuvo %v", err)
+	}
+	if _, err := NewSlack(""); !errors.Is(err, ErrEmptySecret) {
+		t.Errorf("NewSlack: se esperaba ErrEmptySecret, se obtuvo %v", err)
+	}
+
+	// firmado con el secreto vacio, el verificador creado a mano no lo debe aceptar
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", nil)
+	req.Header.Set("X-Hub-Signature-256", "sha256="+sign("", body))
+	if err := (&GitHub{}).Verify(req, body); !errors.Is(err, ErrEmptySecret) {
+		t.Errorf("GitHub sin secreto: se esperaba ErrEmptySecret, se obtuvo %v", err)
+	}
+}
+
+func TestGitHub(t *testing.T) {
+	v, err := NewGitHub(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name   string
+		header string
+		body   []byte
+		want   error
+	}{
+		{"valida", "sha256=" + sign(secret, body), body, nil},
+		{"sin firma", "", body, ErrMissingSignature},
+		{"sin prefijo", sign(secret, body), body, ErrInvalidSignature},
+		{"otro secreto", "sha256=" + sign("otro", body), body, ErrInvalidSignature},
+		{"body modificado", "sha256=" + sign(secret, body), []byte(`{"event":"paid","id":43}`), ErrInvalidSignature},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/github", nil)
+		if c.header != "" {
+			req.Header.Set("X-Hub-Signature-256", c.header)
+		}
+		if err := v.Verify(req, c.body); !errors.Is(err, c.want) {
+			t.Errorf("%s: se obtuvo %v, se esperaba %v", c.name, err, c.want)
+		}
+	}
+}
+
+func TestStripe(t *testing.T) {
+	at := time.Unix(1_700_000_000, 0)
+	fixedNow(t, at)
+	v, err := NewStripe(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stripeHeader := func(ts time.Time, signatures ...string) string {
+		t := strconv.FormatInt(ts.Unix(), 10)
+		parts := []string{"t=" + t}
+		for _, s := range signatures {
+			parts = append(parts, "v1="+s)
+		}
+		return strings.Join(parts, ",")
+	}
+	signed := func(ts time.Time) string {
+		return sign(secret, []byte(strconv.FormatInt(ts.Unix(), 10)+"."), body)
+	}
+	cases := []struct {
+		name   string
+		header string
+		want   error
+	}{
+		{"valida", stripeHeader(at, signed(at)), nil},
+		{"rotando el secreto", stripeHeader(at, sign("viejo", body), signed(at)), nil},
+		{"dentro de la tolerancia", stripeHeader(at.Add(-4*time.Minute), signed(at.Add(-4*time.Minute))), nil},
+		{"reenviada despues de la tolerancia", stripeHeader(at.Add(-6*time.Minute), signed(at.Add(-6*time.Minute))), ErrTimestamp},
+		{"del futuro", stripeHeader(at.Add(6*time.Minute), signed(at.Add(6*time.Minute))), ErrTimestamp},
+		{"timestamp cambiado", stripeHeader(at, signed(at.Add(-time.Minute))), ErrInvalidSignature},
+		{"sin v1", stripeHeader(at), ErrInvalidSignature},
+		{"timestamp invalido", "t=ayer,v1=" + signed(at), ErrTimestamp},
+		{"sin firma", "", ErrMissingSignature},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/stripe", nil)
+		if c.header != "" {
+			req.Header.Set("Stripe-Signature", c.header)
+		}
+		if err := v.Verify(req, body); !errors.Is(err, c.want) {
+			t.Errorf("%s: se obtuvo %v, se esperaba %v", c.name, err, c.want)
+		}
+	}
+}
+
+func TestSlackReplay(t *testing.T) {
+	at := time.Unix(1_700_000_000, 0)
+	v, err := NewSlack(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := strconv.FormatInt(at.Unix(), 10)
+	req := httptest.NewRequest(http.MethodPost, "/slack/commands", nil)
+	req.Header.Set("X-Slack-Request-Timestamp", ts)
+	req.Header.Set("X-Slack-Signature", "v0="+sign(secret, []byte("v0:"+ts+":"), body))
+
+	fixedNow(t, at.Add(time.Minute))
+	if err := v.Verify(req, body); err != nil {
+		t.Fatalf("la solicitud firmada hace un minuto debe pasar: %v", err)
+	}
+	// la misma solicitud capturada y reenviada una hora despues
+	fixedNow(t, at.Add(time.Hour))
+	if err := v.Verify(req, body); !errors.Is(err, ErrTimestamp) {
+		t.Errorf("se esperaba ErrTimestamp al reenviar la solicitud, se obtuvo %v", err)
+	}
+}
+
+func TestVerifyMiddleware(t *testing.T) {
+	cases := []struct {
+		name   string
+		v      Verifier
+		header string
+		status int
+	}{
+		{"valida", &GitHub{Secret: secret}, "sha256=" + sign(secret, body), http.StatusOK},
+		{"invalida", &GitHub{Secret: secret}, "sha256=" + sign("otro", body), http.StatusUnauthorized},
+		{"sin secreto", &GitHub{}, "sha256=" + sign("", body), http.StatusInternalServerError},
+	}
+	for _, c := range cases {
+		called := false
+		handler := Verify(c.v)(func(ctx *controller.Context) {
+			called = true
+			ctx.Writer.WriteHeader(http.StatusOK)
+		})
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/github", strings.NewReader(string(body)))
+		req.Header.Set("X-Hub-Signature-256", c.header)
+		rec := httptest.NewRecorder()
+		handler(controller.NewContext(rec, req))
+		if rec.Code != c.status {
+			t.Errorf("%s: status %d, se esperaba %d", c.name, rec.Code, c.status)
+		}
+		if called != (c.status == http.StatusOK) {
+			t.Errorf("%s: el controlador se llamo = %v", c.name, called)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// Alias: un campo puede llegar con los nombres que tuvo antes, asi se renombra un campo sin romper a los clientes viejos
+//
+//	type CreateUser struct {
+//		FullName string `json:"full_name" alias:"name,nombre" rules:"required"`
+//		Address  struct {
+//			PostalCode string `json:"postal_code" alias:"zip"`
+//		} `json:"address"`
+//	}
+//
+// antes de deserializar el body las llaves de los alias se cambian por el nombre del campo.
+// si el cliente envia varios nombres del mismo campo con valores distintos se responde un error de validacion,
+// con StrictAliases en false gana el nombre del campo y luego los alias en el orden del tag.
+// en los FormRequest que son un slice se revisa cada elemento
+
+// StrictAliases si es true enviar el campo y su alias con valores distintos es un error
+var StrictAliases = true
+
+// aliasField un campo con sus nombres anteriores, parent es la ruta del objeto que lo contiene
+type aliasField struct {
+	parent  []string
+	name    string
+	aliases []string
+}
+
+// aliasCache campos con alias por tipo de struct
+var aliasCache sync.Map
+
+// getAliasFields retorna los campos con el tag alias del struct, incluidos los anidados
+func getAliasFields(t reflect.Type) []aliasField {
+	if cached, ok := aliasCache.Load(t); ok {
+		return cached.([]aliasField)
+	}
+	fields := buildAliasFields(t, nil, map[reflect.Type]bool{})
+	aliasCache.Store(t, fields)
+	return fields
+}
+
+func buildAliasFields(t reflect.Type, parent []string, visiting map[reflect.Type]bool) []aliasField {
+	visiting[t] = true
+	defer delete(visiting, t)
+	var fields []aliasField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if tag := field.Tag.Get("alias"); tag != "" {
+			af := aliasField{parent: parent, name: fieldName(field)}
+			for _, alias := range strings.Split(tag, ",") {
+				if alias = strings.TrimSpace(alias); alias != "" {
+					af.aliases = append(af.aliases, alias)
+				}
+			}
+			fields = append(fields, af)
+		}
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() != reflect.Struct || fieldType == dateTimeType || visiting[fieldType] {
+			continue
+		}
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			fields = append(fields, buildAliasFields(fieldType, parent, visiting)...)
+		} else {
+			nested := append(append([]string{}, parent...), fieldName(field))
+			fields = append(fields, buildAliasFields(fieldType, nested, visiting)...)
+		}
+	}
+	return fields
+}
+
+// applyAliases cambia las llaves de los alias por el nombre del campo
+// retorna el mismo body si el FormRequest no tiene alias o si ninguno vino
+func applyAliases(request FormRequest, body []byte) ([]byte, error) {
+	st, ok := structType(reflect.TypeOf(request))
+	if !ok {
+		return body, nil
+	}
+	fields := getAliasFields(st)
+	trimmed := bytes.TrimSpace(body)
+	if len(fields) == 0 || len(trimmed) == 0 {
+		return body, nil
+	}
+
+	// el error de sintaxis lo reporta la deserializacion normal
+	if trimmed[0] == '[' {
+		var elements []json.RawMessage
+		if json.Unmarshal(trimmed, &elements) != nil {
+			return body, nil
+		}
+		var errs validation.CodedErrors
+		changed := false
+		for i, element := range elements {
+			renamed, elementChanged, err := renameObject(element, fields, fmt.Sprintf("%d.", i))
+			if err != nil {
+				mergeErrors(&errs, err)
+				continue
+			}
+			if elementChanged {
+				elements[i] = renamed
+				changed = true
+			}
+		}
+		if err := errs.Err(); err != nil {
+			return nil, err
+		}
+		if !changed {
+			return body, nil
+		}
+		return json.Marshal(elements)
+	}
+
+	renamed, changed, err := renameObject(trimmed, fields, "")
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return body, nil
+	}
+	return renamed, nil
+}
+
+// renameObject aplica los alias a un objeto json, prefix es el nombre del elemento para los errores (2.)
+func renameObject(raw json.RawMessage, fields []aliasField, prefix string) (json.RawMessage, bool, error) {
+	var object map[string]json.RawMessage
+	if json.Unmarshal(raw, &object) != nil || object == nil {
+		return raw, false, nil
+	}
+	var errs validation.CodedErrors
+	changed := false
+	for _, field := range fields {
+		fieldChanged, err := renameField(object, field.parent, field)
+		if err != nil {
+			errs.Add(prefix+strings.Join(append(append([]string{}, field.parent...), field.name), "."), CodeAliasConflict, err.Error())
+			continue
+		}
+		changed = changed || fieldChanged
+	}
+	if err := errs.Err(); err != nil {
+		return nil, false, err
+	}
+	if !changed {
+		return raw, false, nil
+	}
+	out, err := json.Marshal(object)
+	return out, true, err
+}
+
+// renameField baja por la ruta del objeto padre y cambia el alias por el nombre del campo
+func renameField(object map[string]json.RawMessage, path []string, field aliasField) (bool, error) {
+	if len(path) > 0 {
+		var nested map[string]json.RawMessage
+		if json.Unmarshal(object[path[0]], &nested) != nil || nested == nil {
+			return false, nil
+		}
+		changed, err := renameField(nested, path[1:], field)
+		if err != nil || !changed {
+			return false, err
+		}
+		object[path[0]], err = json.Marshal(nested)
+		return true, err
+	}
+
+	value, found := object[field.name]
+	sentAs := field.name
+	changed := false
+	for _, alias := range field.aliases {
+		aliasValue, ok := object[alias]
+		if !ok {
+			continue
+		}
+		delete(object, alias)
+		changed = true
+		if !found {
+			value, found, sentAs = aliasValue, true, alias
+			continue
+		}
+		if StrictAliases && !sameJSON(value, aliasValue) {
+			return false, fmt.Errorf("se enviaron %s y %s con valores distintos, envíe solo %s", sentAs, alias, field.name)
+		}
+	}
+	if changed {
+		object[field.name] = value
+	}
+	return changed, nil
+}
+
+// sameJSON compara dos valores json sin tener en cuenta los espacios
+func sameJSON(a, b json.RawMessage) bool {
+	var ca, cb bytes.Buffer
+	if json.Compact(&ca, a) != nil || json.Compact(&cb, b) != nil {
+		return bytes.Equal(a, b)
+	}
+	return bytes.Equal(ca.Bytes(), cb.Bytes())
+}
+
+// mergeErrors agrega los errores de validacion de err (con sus codigos) a errs
+func mergeErrors(errs *validation.CodedErrors, err error) {
+	var more *validation.CodedErrors
+	if !errors.As(err, &more) {
+		return
+	}
+	for field, msgs := range more.Errors {
+		for i, msg := range msgs {
+			errs.Add(field, more.Codes[field][i], msg)
+		}
+	}
+}
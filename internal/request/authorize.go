@@ -0,0 +1,28 @@
+package request
+
+import (
+	"errors"
+	"net/http"
+)
+
+// AuthorizedRequest lo implementa el FormRequest que decide si el usuario puede hacer la solicitud
+// (como el authorize de laravel), se revisa antes de leer el body y si retorna false Validate retorna ErrForbidden
+//
+//	func (r *UpdatePost) Authorize(req *http.Request) bool {
+//		role, _ := validation.Placeholder(req.Context(), "auth.role")
+//		return role == "editor"
+//	}
+type AuthorizedRequest interface {
+	Authorize(req *http.Request) bool
+}
+
+// ErrForbidden el FormRequest no autorizo la solicitud, se responde 403 (errors.Is con ErrUnauthorized tambien)
+var ErrForbidden = errors.New("no tiene permiso para realizar esta acción")
+
+// checkAuthorize ejecuta Authorize si el FormRequest la implementa
+func checkAuthorize(request FormRequest, req *http.Request) error {
+	if a, ok := request.(AuthorizedRequest); ok && !a.Authorize(req) {
+		return classify(ErrUnauthorized, ErrForbidden)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// MaxBatchItems cantidad maxima de elementos que acepta un lote
+var MaxBatchItems = 100
+
+// BatchMode como se procesa un lote cuando algun elemento falla
+type BatchMode int
+
+const (
+	// BestEffort procesa los elementos validos y reporta los errores de los demas
+	BestEffort BatchMode = iota
+	// Atomic no procesa nada si algun elemento es invalido y se detiene en el primer error del handler
+	// para que las escrituras tambien sean todo o nada use una transaccion dentro del handler
+	Atomic
+)
+
+// BatchResult resultado de un elemento del lote
+type BatchResult struct {
+	Index  int    `json:"index"`
+	Status int    `json:"status"`
+	Data   any    `json:"data,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Code   string `json:"code,omitempty"` // validation.ErrorCode del error, el mismo de una solicitud sola
+	Errors any    `json:"errors,omitempty"`
+}
+
+// BatchErrors errores de validacion de un lote por indice del elemento
+type BatchErrors map[int]error
+
+func (e BatchErrors) Error() string {
+	return fmt.Sprintf("%d elementos del lote no son válidos", len(e))
+}
+
+// ValidateBatch valida un body con un array de elementos, cada uno con su propio FormRequest
+// retorna un FormRequest por elemento (en el mismo orden) y los errores de los que no son validos
+// el error final solo se retorna si el body no es un array o supera MaxBatchItems, si el FormRequest supera
+// su Throttle (el lote cuenta como una solicitud) o si no autoriza la solicitud
+//
+//	items, invalid, err := request.ValidateBatch(ctx.Request, func() *request.User { return &request.User{} })
+func ValidateBatch[T FormRequest](req *http.Request, factory func() T) (_ []T, _ BatchErrors, err error) {
+	defer recoverPanic(&err)
+	// el limite se cuenta una vez por lote y no por elemento, igual que en Validate antes de autorizar
+	request := factory()
+	if err := checkThrottle(request, req); err != nil {
+		return nil, nil, err
+	}
+	if err := checkAuthorize(request, req); err != nil {
+		return nil, nil, err
+	}
+	body, err := ReadBody(req)
+	if err != nil {
+		return nil, nil, classify(ErrDecode, err)
+	}
+	if body, err = rewriteBody(req, body); err != nil {
+		return nil, nil, classify(ErrDecode, err)
+	}
+
+	var raws []json.RawMessage
+	if err := json.Unmarshal(body, &raws); err != nil {
+		return nil, nil, classify(ErrDecode, validation.WithCode(CodeBodyShape, errors.New("el body debe ser un array de elementos")))
+	}
+	if len(raws) == 0 {
+		return nil, nil, classify(ErrDecode, validation.WithCode(CodeEmptyBatch, errors.New("el lote no tiene elementos")))
+	}
+	if len(raws) > MaxBatchItems {
+		return nil, nil, classify(ErrDecode, validation.WithCode(CodeTooManyItems, fmt.Errorf("el lote no puede tener más de %d elementos", MaxBatchItems)))
+	}
+
+	items := make([]T, len(raws))
+	var invalid BatchErrors
+	for i, raw := range raws {
+		items[i] = factory()
+		raw = bytes.TrimSpace(raw)
+		if holder, ok := any(items[i]).(rawBodyHolder); ok {
+			holder.setRawBody(raw)
+		}
+		if err := validateBody(items[i], raw, req); err != nil {
+			if invalid == nil {
+				invalid = make(BatchErrors)
+			}
+			invalid[i] = err
+		}
+	}
+	return items, invalid, nil
+}
+
+// Batch adapta un handler de un elemento a un endpoint que recibe un lote
+// cada elemento se valida con su FormRequest y se procesa con handle, la respuesta tiene el resultado por indice
+// responde 200 si todo salio bien, 207 si en BestEffort algun elemento fallo y 422 si en Atomic hay invalidos
+//
+//	{Path: "/batch", Methods: AllowMethods(POST), Handler: request.Batch(request.BestEffort,
+//		func() *request.User { return &request.User{} },
+//		func(ctx *controller.Context, i int, u *request.User) (any, error) { return user.Create(u) },
+//	)}
+func Batch[T FormRequest](mode BatchMode, factory func() T, handle func(ctx *controller.Context, index int, item T) (any, error)) controller.ControllerFunc {
+	return func(ctx *controller.Context) {
+		items, invalid, err := ValidateBatch(ctx.Request, factory)
+		if err != nil {
+			responseBatchErr(ctx, err)
+			return
+		}
+
+		results := make([]BatchResult, 0, len(items))
+		if mode == Atomic && len(invalid) > 0 {
+			for i := range items {
+				if err, ok := invalid[i]; ok {
+					results = append(results, batchError(i, err))
+				}
+			}
+			ctx.ResponseError(http.StatusUnprocessableEntity, invalid.Error(), results)
+			return
+		}
+
+		failed := false
+		for i, item := range items {
+			if err, ok := invalid[i]; ok {
+				results = append(results, batchError(i, err))
+				failed = true
+				continue
+			}
+			data, err := handle(ctx, i, item)
+			if err != nil {
+				results = append(results, batchError(i, err))
+				failed = true
+				if mode == Atomic {
+					ctx.ResponseError(http.StatusUnprocessableEntity, fmt.Sprintf("el elemento %d del lote falló", i), results)
+					return
+				}
+				continue
+			}
+			results = append(results, BatchResult{Index: i, Status: http.StatusOK, Data: data})
+		}
+
+		status := http.StatusOK
+		if failed {
+			status = http.StatusMultiStatus
+		}
+		ctx.ResponseJSON(status, map[string]any{"results": results})
+	}
+}
+
+// batchError convierte el error de un elemento en su resultado con el status de ErrorStatus
+// los errores del servidor (5xx) van al log y el cliente solo recibe un mensaje generico
+func batchError(index int, err error) BatchResult {
+	err = classify(nil, err)
+	status := ErrorStatus(err)
+	var verrs validation.ValidationErrors
+	if errors.As(err, &verrs) {
+		return BatchResult{Index: index, Status: status, Error: "los datos no son válidos", Code: validation.ErrorCode(err), Errors: verrs}
+	}
+	if status >= http.StatusInternalServerError {
+		log.Printf("batch: elemento %d: %v", index, err)
+		return BatchResult{Index: index, Status: status, Error: "error interno del servidor", Code: validation.ErrorCode(err)}
+	}
+	return BatchResult{Index: index, Status: status, Error: err.Error(), Code: validation.ErrorCode(err)}
+}
+
+// responseBatchErr responde el error de ValidateBatch con el status de ErrorStatus y su codigo
+// (400 si el body no es un lote valido, 403 si el FormRequest no autorizo, 429 con Retry-After)
+func responseBatchErr(ctx *controller.Context, err error) {
+	status := ErrorStatus(err)
+	var throttle *ThrottleError
+	if errors.As(err, &throttle) {
+		ctx.Writer.Header().Set("Retry-After", strconv.Itoa(throttle.RetryAfterSeconds()))
+	}
+	message := err.Error()
+	if status >= http.StatusInternalServerError {
+		log.Printf("batch %s %s: %v", ctx.Request.Method, ctx.Request.URL.Path, err)
+		message = "error interno del servidor"
+	}
+	ctx.ResponseErr(status, message, err)
+}
@@ -0,0 +1,139 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// statusError error del handler de Batch con su propio status
+type statusError struct{ status int }
+
+func (e statusError) Error() string { return fmt.Sprintf("status %d", e.status) }
+func (e statusError) Status() int   { return e.status }
+
+func TestBatchErrorStatus(t *testing.T) {
+	verrs := validation.ValidationErrors{"name": {"el campo es obligatorio"}}
+	cases := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"validacion", verrs, http.StatusUnprocessableEntity},
+		{"decode", classify(ErrDecode, errors.New("json inválido")), http.StatusBadRequest},
+		{"hook", classify(ErrHook, errors.New("falló")), http.StatusBadRequest},
+		{"prohibido", classify(ErrUnauthorized, ErrForbidden), http.StatusForbidden},
+		{"no autorizado", classify(ErrUnauthorized, errors.New("sin sesión")), http.StatusUnauthorized},
+		{"limite", &ThrottleError{Limit: 10, RetryAfter: time.Second}, http.StatusTooManyRequests},
+		{"panico", &PanicError{Value: "boom"}, http.StatusInternalServerError},
+		{"regla sin base de datos", validation.Hard(errors.New("conexión rechazada")), http.StatusInternalServerError},
+		{"version", &StaleVersionError{Given: "1", Current: "2"}, http.StatusConflict},
+		{"handler con status", statusError{http.StatusNotFound}, http.StatusNotFound},
+	}
+	for _, c := range cases {
+		result := batchError(3, c.err)
+		if result.Index != 3 || result.Status != c.status {
+			t.Errorf("%s: status %d, se esperaba %d", c.name, result.Status, c.status)
+		}
+		if c.status >= http.StatusInternalServerError && result.Error != "error interno del servidor" {
+			t.Errorf("%s: un error del servidor no debe llegar al cliente, se obtuvo %q", c.name, result.Error)
+		}
+	}
+}
+
+func TestBatchResponseCodes(t *testing.T) {
+	handler := Batch(BestEffort, func() *dryRunForm { return &dryRunForm{} }, func(ctx *controller.Context, i int, item *dryRunForm) (any, error) {
+		return item.Name, nil
+	})
+	cases := []struct {
+		name   string
+		body   string
+		status int
+		code   string
+	}{
+		{"no es un array", `{"name":"ana"}`, http.StatusBadRequest, CodeBodyShape},
+		{"vacio", `[]`, http.StatusBadRequest, CodeEmptyBatch},
+		{"un elemento invalido", `[{"name":"ana"},{"name":""}]`, http.StatusMultiStatus, ""},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/users/batch", strings.NewReader(c.body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler(controller.NewContext(rec, req))
+		if rec.Code != c.status {
+			t.Errorf("%s: status %d, se esperaba %d", c.name, rec.Code, c.status)
+		}
+		var res struct {
+			Code    string        `json:"code"`
+			Results []BatchResult `json:"results"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if res.Code != c.code {
+			t.Errorf("%s: codigo %q, se esperaba %q", c.name, res.Code, c.code)
+		}
+		if c.status == http.StatusMultiStatus {
+			if len(res.Results) != 2 || res.Results[1].Status != http.StatusUnprocessableEntity || res.Results[1].Code != validation.CodeInvalid {
+				t.Errorf("%s: resultados %+v", c.name, res.Results)
+			}
+		}
+	}
+}
+
+type throttledBatchForm struct {
+	hooks
+	Name string `json:"name" rules:"required"`
+}
+
+func (f *throttledBatchForm) Throttle() Throttle {
+	return Throttle{Limit: 1, Window: time.Hour, Key: func(req *http.Request) string { return "batch-test" }}
+}
+
+func TestValidateBatchCountsThrottleOncePerBatch(t *testing.T) {
+	previous := DefaultRateLimiter
+	t.Cleanup(func() { DefaultRateLimiter = previous })
+	DefaultRateLimiter = NewMemoryRateLimiter()
+
+	newReq := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/users/batch", strings.NewReader(`[{"name":"ana"},{"name":"eva"},{"name":"luz"}]`))
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+	factory := func() *throttledBatchForm { return &throttledBatchForm{} }
+
+	items, invalid, err := ValidateBatch(newReq(), factory)
+	if err != nil || len(invalid) != 0 || len(items) != 3 {
+		t.Fatalf("el primer lote cuenta como una sola solicitud: %d elementos, %v, %v", len(items), invalid, err)
+	}
+	_, _, err = ValidateBatch(newReq(), factory)
+	var throttle *ThrottleError
+	if !errors.As(err, &throttle) || !errors.Is(err, ErrTooManyRequests) {
+		t.Fatalf("el segundo lote supera el limite, se obtuvo %v", err)
+	}
+
+	// la solicitud que ya conto el middleware no se vuelve a contar
+	DefaultRateLimiter = NewMemoryRateLimiter()
+	req, err := CheckThrottle(factory(), newReq())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ValidateBatch(req, factory); err != nil {
+		t.Errorf("la solicitud contada por el middleware no se debe contar otra vez: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	handler := Batch(BestEffort, factory, func(ctx *controller.Context, i int, item *throttledBatchForm) (any, error) { return nil, nil })
+	handler(controller.NewContext(rec, newReq()))
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("Batch debe responder 429 con Retry-After, se obtuvo %d %v", rec.Code, rec.Header())
+	}
+}
@@ -0,0 +1,294 @@
+package request
+
+import (
+	"encoding"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/donbarrigon/new-project/lib/formatter"
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// bindField campo del FormRequest que se llena con un valor de la url
+type bindField struct {
+	index  []int  // posicion del campo dentro del struct
+	name   string // nombre del campo para los errores (segun el tag json)
+	key    string // nombre del parametro en la url
+	source string // query, path o header
+	coerce string // tipo al que se convierte si el campo es any (tag coerce)
+}
+
+// ParamUnmarshaler lo implementan los tipos del dominio (Money, PhoneNumber, UserID) que se llenan
+// directamente desde un valor de la url (tags query y path), se usa antes que encoding.TextUnmarshaler
+// el error se reporta como error de validacion del campo
+//
+//	type UserID int64
+//
+//	func (id *UserID) FromRequestValue(value string) error {
+//		n, err := strconv.ParseInt(strings.TrimPrefix(value, "usr_"), 10, 64)
+//		if err != nil {
+//			return fmt.Errorf("el id '%s' no es válido", value)
+//		}
+//		*id = UserID(n)
+//		return nil
+//	}
+type ParamUnmarshaler interface {
+	FromRequestValue(value string) error
+}
+
+// bindCache cache de los campos a llenar por tipo de FormRequest
+var bindCache sync.Map
+
+// bindParams llena los campos con tag `query`, `path` o `header` con los valores de la url y las cabeceras
+// asi el FormRequest funciona igual para los endpoints de lectura que no tienen body
+//
+//	type ShowUser struct {
+//		ID      int    `json:"id" path:"id" rules:"required|min:1"`
+//		Include string `json:"include" query:"include"`
+//		Code    any    `json:"code" query:"code" coerce:"string"` // sin coerce "0123" seria el int 123
+//		Version string `json:"version" header:"If-Match"`         // "7" o W/"7" queda 7
+//	}
+func bindParams(request FormRequest, req *http.Request) error {
+	rv := reflect.ValueOf(request).Elem()
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fields := getBindFields(rv.Type())
+	if len(fields) == 0 {
+		return nil
+	}
+
+	var errs validation.CodedErrors
+	query := req.URL.Query()
+	for _, bf := range fields {
+		var values []string
+		switch bf.source {
+		case "path":
+			if v := req.PathValue(bf.key); v != "" {
+				values = []string{v}
+			}
+		case "header":
+			values = headerValues(req.Header, bf.key)
+		default:
+			values = query[bf.key]
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		if err := setField(rv.FieldByIndex(bf.index), values, bf.coerce); err != nil {
+			errs.Add(bf.name, validation.CodeType, err.Error())
+		}
+	}
+	return errs.Err()
+}
+
+// getBindFields retorna los campos a llenar desde el cache o los calcula
+func getBindFields(t reflect.Type) []bindField {
+	if cached, ok := bindCache.Load(t); ok {
+		return cached.([]bindField)
+	}
+	fields := buildBindFields(t, nil)
+	bindCache.Store(t, fields)
+	return fields
+}
+
+// buildBindFields busca los campos con tag query o path incluidos los de structs embebidos
+func buildBindFields(t reflect.Type, index []int) []bindField {
+	var fields []bindField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldIndex := append(append([]int{}, index...), i)
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			fields = append(fields, buildBindFields(field.Type, fieldIndex)...)
+			continue
+		}
+
+		name := fieldName(field)
+		coerce := field.Tag.Get("coerce")
+		if key := field.Tag.Get("path"); key != "" {
+			fields = append(fields, bindField{index: fieldIndex, name: name, key: key, source: "path", coerce: coerce})
+		} else if key := field.Tag.Get("query"); key != "" {
+			fields = append(fields, bindField{index: fieldIndex, name: name, key: key, source: "query", coerce: coerce})
+		} else if key := field.Tag.Get("header"); key != "" {
+			fields = append(fields, bindField{index: fieldIndex, name: name, key: key, source: "header", coerce: coerce})
+		}
+	}
+	return fields
+}
+
+// headerValues valores de la cabecera, las de precondicion (If-Match, If-None-Match) se leen como listas
+// de ETags sin comillas ni W/ y el comodin * se ignora
+func headerValues(header http.Header, key string) []string {
+	values := header.Values(key)
+	if !strings.EqualFold(key, "If-Match") && !strings.EqualFold(key, "If-None-Match") {
+		return values
+	}
+	var tags []string
+	for _, value := range values {
+		for _, tag := range strings.Split(value, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "" || tag == "*" {
+				continue
+			}
+			tags = append(tags, strings.Trim(tag, `"`))
+		}
+	}
+	return tags
+}
+
+// fieldName nombre del campo para los errores segun el tag json o en snake_case
+func fieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		name = formatter.ToSnakeCase(field.Name)
+	}
+	return name
+}
+
+// setField convierte los valores de la url al tipo del campo y los asigna
+func setField(fv reflect.Value, values []string, coerce string) error {
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		fv = fv.Elem()
+	}
+
+	// un tipo del dominio que es un slice (type Tags []string) se llena con el primer valor
+	if u, ok := paramUnmarshaler(fv); ok {
+		return u.FromRequestValue(values[0])
+	}
+
+	if fv.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(fv.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setValue(slice.Index(i), value, coerce); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+		return nil
+	}
+
+	return setValue(fv, values[0], coerce)
+}
+
+// setValue convierte un valor de la url al tipo del campo
+// los campos any se convierten segun coerce (auto si esta vacio)
+func setValue(fv reflect.Value, value string, coerce string) error {
+	if u, ok := paramUnmarshaler(fv); ok {
+		return u.FromRequestValue(value)
+	}
+	if fv.Kind() == reflect.Struct && fv.CanAddr() {
+		if u, ok := fv.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(value))
+		}
+	}
+
+	switch fv.Kind() {
+	case reflect.Interface:
+		v, err := formatter.Coerce(value, coerce)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(v))
+	case reflect.String:
+		fv.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, fv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("el valor '%s' debe ser un número entero", value)
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, fv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("el valor '%s' debe ser un número entero positivo", value)
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, fv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("el valor '%s' debe ser numérico", value)
+		}
+		fv.SetFloat(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("el valor '%s' debe ser verdadero o falso", value)
+		}
+		fv.SetBool(b)
+	default:
+		return fmt.Errorf("el tipo %s no se puede llenar desde la url", fv.Type())
+	}
+	return nil
+}
+
+// paramUnmarshaler retorna el ParamUnmarshaler del campo si su tipo lo implementa
+func paramUnmarshaler(fv reflect.Value) (ParamUnmarshaler, bool) {
+	if !fv.CanAddr() {
+		return nil, false
+	}
+	u, ok := fv.Addr().Interface().(ParamUnmarshaler)
+	return u, ok
+}
+
+// tagFieldsKey llave del cache de campos por tipo y tag
+type tagFieldsKey struct {
+	t   reflect.Type
+	tag string
+}
+
+var tagFieldsCache sync.Map
+
+// getTagFields retorna los campos de texto que tienen el tag (encrypted, sanitize), el valor del tag queda en key
+// se buscan tambien en los structs embebidos y anidados, el cache es por tipo y tag
+func getTagFields(t reflect.Type, tag string) []bindField {
+	k := tagFieldsKey{t: t, tag: tag}
+	if cached, ok := tagFieldsCache.Load(k); ok {
+		return cached.([]bindField)
+	}
+	fields := buildTagFields(t, tag, nil, "")
+	tagFieldsCache.Store(k, fields)
+	return fields
+}
+
+// buildTagFields busca los campos string o *string con el tag
+func buildTagFields(t reflect.Type, tag string, index []int, prefix string) []bindField {
+	var fields []bindField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldIndex := append(append([]int{}, index...), i)
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		if value, ok := field.Tag.Lookup(tag); ok && fieldType.Kind() == reflect.String {
+			fields = append(fields, bindField{index: fieldIndex, name: prefix + fieldName(field), key: value})
+			continue
+		}
+		if fieldType.Kind() != reflect.Struct || field.Type.Kind() == reflect.Ptr || fieldType == dateTimeType {
+			continue
+		}
+		if field.Anonymous {
+			fields = append(fields, buildTagFields(fieldType, tag, fieldIndex, prefix)...)
+		} else {
+			fields = append(fields, buildTagFields(fieldType, tag, fieldIndex, prefix+fieldName(field)+".")...)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,71 @@
+package request
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+type showForm struct {
+	hooks
+	ID      int    `json:"id" path:"id" query:"id" rules:"required|min:1"`
+	Include string `json:"include" query:"include"`
+	Name    string `json:"name"`
+}
+
+func TestValidateBodyAndParams(t *testing.T) {
+	cases := []struct {
+		name          string
+		method        string
+		target        string
+		path          string // valor de {id}
+		body          string
+		contentLength int64 // -1 deja el que calcula httptest
+		id            int
+		include       string
+		fieldErr      string // campo con error de validacion
+	}{
+		{name: "sin body", method: http.MethodGet, target: "/users/7?include=posts", path: "7", contentLength: -1, id: 7, include: "posts"},
+		{name: "body vacio", method: http.MethodPost, target: "/users/7", path: "7", body: "", contentLength: -1, id: 7},
+		{name: "body con solo espacios", method: http.MethodPost, target: "/users/7", path: "7", body: " \n ", contentLength: -1, id: 7},
+		{name: "Content-Length 0 no lee el body", method: http.MethodPost, target: "/users/7", path: "7", body: `{"id":"x"}`, contentLength: 0, id: 7},
+		{name: "path gana sobre query", method: http.MethodGet, target: "/users/7?id=9", path: "7", contentLength: -1, id: 7},
+		{name: "query no llena un campo con path", method: http.MethodGet, target: "/users?id=9", contentLength: -1, fieldErr: "id"},
+		{name: "path gana sobre el body", method: http.MethodPut, target: "/users/7", path: "7", body: `{"id":3,"name":"ana"}`, contentLength: -1, id: 7},
+		{name: "path invalido es error del campo", method: http.MethodGet, target: "/users/abc", path: "abc", contentLength: -1, fieldErr: "id"},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(c.method, c.target, strings.NewReader(c.body))
+		if c.body == "" {
+			req = httptest.NewRequest(c.method, c.target, nil)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if c.contentLength >= 0 {
+			req.ContentLength = c.contentLength
+		}
+		if c.path != "" {
+			req.SetPathValue("id", c.path)
+		}
+
+		form := &showForm{}
+		err := Validate(form, req)
+		switch {
+		case c.fieldErr != "":
+			var verrs validation.ValidationErrors
+			if !errors.As(err, &verrs) || !verrs.Has(c.fieldErr) {
+				t.Errorf("%s: se esperaba error en %s, se obtuvo %v", c.name, c.fieldErr, err)
+			}
+			continue
+		case err != nil:
+			t.Errorf("%s: no se esperaba error, se obtuvo %v", c.name, err)
+			continue
+		}
+		if form.ID != c.id || form.Include != c.include {
+			t.Errorf("%s: se obtuvo id=%d include=%q, se esperaba id=%d include=%q", c.name, form.ID, form.Include, c.id, c.include)
+		}
+	}
+}
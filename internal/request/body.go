@@ -0,0 +1,67 @@
+package request
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// ErrBodyTimeout el contexto del request termino (timeout o cliente desconectado) antes de leer todo el body
+var ErrBodyTimeout = errors.New("se agotó el tiempo para leer el body")
+
+// contextReader deja de leer cuando el contexto termina
+// asi un cliente que envia el body muy lento no mantiene ocupado el handler despues del timeout
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+// replayBody body que ya se leyo y se puede volver a leer desde el principio
+type replayBody struct {
+	*bytes.Reader
+	data []byte
+}
+
+func (b *replayBody) Close() error {
+	return nil
+}
+
+// ReadBody lee el body completo y lo reemplaza por una copia que se puede volver a leer
+// asi un middleware (ej: verificar la firma de un webhook) y Validate pueden leer el mismo body
+// si el body ya se habia leido con ReadBody no se vuelve a copiar
+func ReadBody(req *http.Request) ([]byte, error) {
+	if rb, ok := req.Body.(*replayBody); ok {
+		rb.Reset(rb.data)
+		return rb.data, nil
+	}
+	if !hasBody(req) {
+		return nil, nil
+	}
+
+	data, err := io.ReadAll(&contextReader{ctx: req.Context(), r: req.Body})
+	req.Body.Close()
+	if err != nil {
+		if req.Context().Err() != nil {
+			return nil, ErrBodyTimeout
+		}
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, validation.WithCode(CodeBodyTooLarge, err)
+		}
+		return nil, err
+	}
+
+	req.Body = &replayBody{Reader: bytes.NewReader(data), data: data}
+	return data, nil
+}
@@ -0,0 +1,144 @@
+package request
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// trustedProxies redes de los proxies (balanceadores, cdn) en los que se confia
+// solo se leen X-Forwarded-For y X-Real-IP si la conexion viene de una de estas redes
+var (
+	trustedProxies   []*net.IPNet
+	trustedProxiesMu sync.RWMutex
+)
+
+// clientIPKey llave del contexto donde el middleware ClientIP guarda la ip resuelta
+type clientIPKey struct{}
+
+// SetTrustedProxies configura los proxies de confianza, acepta CIDRs (10.0.0.0/8) o ips sueltas
+// los valores vacios se ignoran, asi se puede pasar directo strings.Split(os.Getenv("TRUSTED_PROXIES"), ",")
+// sin proxies de confianza ClientIP siempre retorna la ip de la conexion
+func SetTrustedProxies(proxies ...string) error {
+	nets, err := ParseNetworks(proxies...)
+	if err != nil {
+		return fmt.Errorf("proxy de confianza inválido: %w", err)
+	}
+
+	trustedProxiesMu.Lock()
+	trustedProxies = nets
+	trustedProxiesMu.Unlock()
+	return nil
+}
+
+// ParseNetworks convierte CIDRs (10.0.0.0/8) o ips sueltas en redes, los valores vacios se ignoran
+// lo usan los proxies de confianza y las listas de ips permitidas (middleware.IPAllowlist)
+func ParseNetworks(values ...string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, p := range values {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if !strings.Contains(p, "/") {
+			ip := net.ParseIP(p)
+			if ip == nil {
+				return nil, fmt.Errorf("ip inválida: %s", p)
+			}
+			if ip.To4() != nil {
+				p += "/32"
+			} else {
+				p += "/128"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(p)
+		if err != nil {
+			return nil, fmt.Errorf("red inválida: %s", p)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// ClientIP retorna la ip real del cliente
+// si la conexion viene de un proxy de confianza se recorre X-Forwarded-For de derecha a izquierda
+// y se toma la primera ip que no es de un proxy de confianza, asi el cliente no puede falsificarla
+// agregando ips al inicio de la cabecera. Si no hay X-Forwarded-For se usa X-Real-IP
+func ClientIP(req *http.Request) string {
+	if ip, ok := req.Context().Value(clientIPKey{}).(string); ok {
+		return ip
+	}
+	return resolveClientIP(req)
+}
+
+// WithClientIP resuelve la ip del cliente una sola vez y la guarda en el contexto del request
+func WithClientIP(req *http.Request) *http.Request {
+	if _, ok := req.Context().Value(clientIPKey{}).(string); ok {
+		return req
+	}
+	return req.WithContext(context.WithValue(req.Context(), clientIPKey{}, resolveClientIP(req)))
+}
+
+// resolveClientIP calcula la ip del cliente a partir de la conexion y las cabeceras del proxy
+func resolveClientIP(req *http.Request) string {
+	remote := remoteIP(req.RemoteAddr)
+	if !isTrustedProxy(remote) {
+		return remote
+	}
+
+	if forwarded := req.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		ips := strings.Split(strings.Join(forwarded, ","), ",")
+		client := ""
+		for i := len(ips) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(ips[i]))
+			if ip == nil {
+				// una ip invalida rompe la cadena, no se confia en lo que esta a su izquierda
+				break
+			}
+			client = ip.String()
+			if !isTrustedProxy(client) {
+				return client
+			}
+		}
+		if client != "" {
+			// todas las ips son de proxies de confianza, la mas lejana es el cliente
+			return client
+		}
+	}
+
+	if ip := net.ParseIP(strings.TrimSpace(req.Header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+	return remote
+}
+
+// remoteIP quita el puerto de RemoteAddr
+func remoteIP(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.String()
+	}
+	return host
+}
+
+// isTrustedProxy indica si la ip pertenece a un proxy de confianza
+func isTrustedProxy(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	trustedProxiesMu.RLock()
+	defer trustedProxiesMu.RUnlock()
+	for _, n := range trustedProxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
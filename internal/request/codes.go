@@ -0,0 +1,55 @@
+package request
+
+import "github.com/donbarrigon/new-project/lib/validation"
+
+// Codigos de los errores que produce Validate antes de las reglas (validation.ErrorCode los retorna)
+// los de las reglas son los de validation.RuleCode
+//
+//	switch validation.ErrorCode(err) {
+//	case request.CodeBodyTooLarge: // 413
+//	case request.CodeTooManyRequests: // 429
+//	}
+const (
+	CodeForbidden            = "REQ_FORBIDDEN"
+	CodeTooManyRequests      = "REQ_TOO_MANY_REQUESTS"
+	CodeBodyTooLarge         = "REQ_BODY_TOO_LARGE"
+	CodeBodyTimeout          = "REQ_BODY_TIMEOUT"
+	CodeInvalidJSON          = "REQ_INVALID_JSON"
+	CodeBodyShape            = "REQ_BODY_SHAPE"
+	CodeTooManyItems         = "REQ_TOO_MANY_ITEMS"
+	CodeEmptyBatch           = "REQ_EMPTY_BATCH"
+	CodeUnsupportedMediaType = "REQ_UNSUPPORTED_MEDIA_TYPE"
+	CodeRewriteFailed        = "REQ_REWRITE_FAILED"
+	CodeSpam                 = "REQ_SPAM"
+	CodeCryptNotConfigured   = "REQ_CRYPT_NOT_CONFIGURED"
+	CodeImportInvalid        = "REQ_IMPORT_INVALID"
+	CodeImportTooLarge       = "REQ_IMPORT_TOO_LARGE"
+	CodeImportNoColumns      = "REQ_IMPORT_NO_COLUMNS"
+	CodeAliasConflict        = "VAL_ALIAS_CONFLICT"
+	CodeDecrypt              = "VAL_DECRYPT"
+	CodeDateTime             = "VAL_DATETIME"
+	CodeTimezone             = "VAL_TIMEZONE"
+)
+
+func init() {
+	validation.RegisterErrorCode(ErrForbidden, CodeForbidden, "el FormRequest no autorizó la solicitud")
+	validation.RegisterErrorCode(ErrTooManyRequests, CodeTooManyRequests, "se superó el límite de solicitudes, ver Retry-After")
+	validation.RegisterErrorCode(ErrBodyTimeout, CodeBodyTimeout, "se agotó el tiempo para leer el body")
+	validation.RegisterErrorCode(ErrUnsupportedPatch, CodeUnsupportedMediaType, "el Content-Type no es un formato soportado")
+	validation.RegisterErrorCode(ErrSpam, CodeSpam, "la solicitud parece de un bot (honeypot o tiempo de llenado)")
+	validation.RegisterErrorCode(ErrCryptNotConfigured, CodeCryptNotConfigured, "no se configuró la llave para los campos encrypted")
+	validation.RegisterErrorCode(ErrImportNoColumns, CodeImportNoColumns, "el archivo no tiene ninguna de las columnas esperadas")
+
+	validation.RegisterCode(CodeBodyTooLarge, "el body supera el tamaño máximo")
+	validation.RegisterCode(CodeInvalidJSON, "el body no es un json válido")
+	validation.RegisterCode(CodeBodyShape, "el body no tiene la forma esperada (objeto o array)")
+	validation.RegisterCode(CodeTooManyItems, "el body tiene más elementos de los permitidos")
+	validation.RegisterCode(CodeEmptyBatch, "el lote no tiene elementos")
+	validation.RegisterCode(CodeRewriteFailed, "no se pudo normalizar el body")
+	validation.RegisterCode(CodeImportInvalid, "el archivo de la importación no es válido")
+	validation.RegisterCode(CodeImportTooLarge, "el archivo de la importación supera el tamaño o las filas permitidas")
+	validation.RegisterCode(CodeAliasConflict, "se envió el campo con dos nombres y valores distintos")
+	validation.RegisterCode(CodeDecrypt, "el valor cifrado no se pudo descifrar")
+	validation.RegisterCode(CodeDateTime, "la fecha no es válida")
+	validation.RegisterCode(CodeTimezone, "la zona horaria no existe")
+}
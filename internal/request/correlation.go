@@ -0,0 +1,100 @@
+package request
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/donbarrigon/new-project/lib/ctxkey"
+)
+
+// Correlacion del trabajo asincrono: los eventos y trabajos en cola que dispara una solicitud llevan su id
+// y su contexto de traza (W3C traceparent) para que sus logs se puedan unir con los de la solicitud
+//
+//	// al encolar, la correlacion viaja en el payload del trabajo
+//	job := SendInvoice{InvoiceID: id, Correlation: request.CorrelationFrom(ctx.Request.Context())}
+//
+//	// en el worker, el contexto vuelve a tener el id y la traza de la solicitud original
+//	ctx := job.Correlation.Context(context.Background())
+//	log.Printf("request_id=%s enviando factura", request.RequestID(ctx))
+//
+// para trabajo en la misma aplicacion (eventos, notificaciones) request.Go lo hace automaticamente
+
+// TraceParentHeader cabecera W3C Trace Context que se recibe y se reenvia a los otros servicios
+var TraceParentHeader = "traceparent"
+
+// TraceParentKey llave del contexto con el traceparent de la solicitud
+var TraceParentKey = ctxkey.New[string]("traceparent")
+
+// WithTraceParent guarda el traceparent en el contexto, lo usa el middleware RequestID
+func WithTraceParent(req *http.Request, traceParent string) *http.Request {
+	return ctxkey.SetRequest(req, TraceParentKey, traceParent)
+}
+
+// TraceParent retorna el traceparent de la solicitud, "" si el cliente no lo envio
+func TraceParent(ctx context.Context) string {
+	return ctxkey.Value(ctx, TraceParentKey)
+}
+
+// Correlation datos que unen el trabajo asincrono con la solicitud que lo disparo
+// se serializa en json junto con el payload del evento o del trabajo
+type Correlation struct {
+	RequestID   string `json:"request_id,omitempty"`
+	TraceParent string `json:"traceparent,omitempty"`
+	UserID      string `json:"user_id,omitempty"` // usuario autenticado que hizo la solicitud
+}
+
+// CorrelationFrom toma la correlacion del contexto de la solicitud, o la restaurada en el worker
+// asi un trabajo que encola otro trabajo le pasa la misma correlacion
+func CorrelationFrom(ctx context.Context) Correlation {
+	if c, ok := ctxkey.Get(ctx, correlationKey); ok {
+		return c
+	}
+	return Correlation{RequestID: RequestID(ctx), TraceParent: TraceParent(ctx), UserID: AuthID(ctx)}
+}
+
+// IsZero indica si no hay nada que correlacionar
+func (c Correlation) IsZero() bool {
+	return c == Correlation{}
+}
+
+// Context agrega la correlacion al contexto del worker, RequestID y TraceParent la vuelven a leer
+// el usuario no se restaura como autenticado, solo queda en la correlacion para los logs
+func (c Correlation) Context(parent context.Context) context.Context {
+	ctx := parent
+	if c.RequestID != "" {
+		ctx = ctxkey.Set(ctx, RequestIDKey, c.RequestID)
+	}
+	if c.TraceParent != "" {
+		ctx = ctxkey.Set(ctx, TraceParentKey, c.TraceParent)
+	}
+	return ctxkey.Set(ctx, correlationKey, c)
+}
+
+// correlationKey llave del contexto con la correlacion restaurada en el worker
+var correlationKey = ctxkey.New[Correlation]("correlation")
+
+// Detach contexto para trabajo que sigue despues de responder: conserva la correlacion pero no se cancela
+// cuando termina la solicitud ni arrastra el resto de sus valores (usuario, tenant, registros de depuracion)
+func Detach(ctx context.Context) context.Context {
+	return CorrelationFrom(ctx).Context(context.Background())
+}
+
+// Go ejecuta fn en una goroutine con el contexto de Detach, los panicos se escriben en el log con el id
+// de la solicitud en lugar de tumbar el servidor
+//
+//	request.Go(ctx.Request.Context(), func(ctx context.Context) {
+//		mailer.SendWelcome(ctx, user)
+//	})
+func Go(ctx context.Context, fn func(ctx context.Context)) {
+	detached := Detach(ctx)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("async_panic request_id=%s traceparent=%s: %v\n%s", RequestID(detached), TraceParent(detached), r, debug.Stack())
+			}
+		}()
+		fn(detached)
+	}()
+}
@@ -0,0 +1,250 @@
+package request
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/donbarrigon/new-project/lib/ctxkey"
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// TimezoneHeader cabecera con la zona horaria del cliente (America/Bogota)
+var TimezoneHeader = "Time-Zone"
+
+// TimezoneQuery parametro de la url con la zona horaria, se usa si no viene la cabecera
+var TimezoneQuery = "tz"
+
+// DefaultTimezone zona horaria de las fechas sin zona cuando el cliente no envia ninguna
+var DefaultTimezone = time.UTC
+
+// TimezoneResolver retorna el nombre de la zona horaria del request ("" usa DefaultTimezone)
+// reemplacela para leer la zona del perfil del usuario autenticado, se llama en cada validacion
+// porque las reglas today, tomorrow y yesterday tambien usan la zona del request
+var TimezoneResolver = func(req *http.Request) string {
+	if tz := req.Header.Get(TimezoneHeader); tz != "" {
+		return tz
+	}
+	// la url solo se interpreta si trae el parametro, se llama en cada validacion
+	if !strings.Contains(req.URL.RawQuery, TimezoneQuery+"=") {
+		return ""
+	}
+	return req.URL.Query().Get(TimezoneQuery)
+}
+
+// timezoneKey zona horaria del request para las reglas de fechas (today, tomorrow, yesterday)
+var timezoneKey = ctxkey.New[*time.Location]("timezone")
+
+func init() {
+	// today y tomorrow empiezan a la medianoche del cliente y no a la de UTC
+	validation.LocationResolver = func(ctx context.Context) *time.Location {
+		if loc, ok := ctxkey.Get(ctx, timezoneKey); ok {
+			return loc
+		}
+		return DefaultTimezone
+	}
+}
+
+// withTimezone guarda la zona horaria del request para las reglas de fechas
+// si la zona no existe se usa DefaultTimezone, el error lo reportan los campos DateTime
+func withTimezone(ctx context.Context, request FormRequest, req *http.Request) context.Context {
+	loc, err := requestLocation(request, req)
+	if err != nil || loc == DefaultTimezone {
+		return ctx
+	}
+	return ctxkey.Set(ctx, timezoneKey, loc)
+}
+
+// TimezoneRequest lo implementa el FormRequest que decide su propia zona horaria
+//
+//	func (e *Event) Timezone(req *http.Request) string { return auth.User(req).Timezone }
+type TimezoneRequest interface {
+	Timezone(req *http.Request) string
+}
+
+// DateTime fecha que se interpreta en la zona horaria del request y se normaliza a UTC
+// las fechas con zona ("2026-10-15T10:00:00-05:00") se respetan, las que no tienen
+// ("2026-10-15 10:00") se interpretan en la zona del cliente, asi after:now compara bien
+//
+//	type CreateEvent struct {
+//		StartsAt request.DateTime `json:"starts_at" rules:"required|after:now"`
+//	}
+type DateTime struct {
+	time.Time
+	raw string // valor original, se vuelve a interpretar cuando se conoce la zona horaria
+}
+
+// UnmarshalJSON guarda el valor original y lo interpreta en UTC mientras se conoce la zona
+func (d *DateTime) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*d = DateTime{}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("la fecha debe ser un texto")
+	}
+	return d.UnmarshalText([]byte(s))
+}
+
+// UnmarshalText permite llenar el campo desde la url con los tags query y path
+func (d *DateTime) UnmarshalText(text []byte) error {
+	s := string(text)
+	t, err := parseDateTime(s, DefaultTimezone)
+	if err != nil {
+		return err
+	}
+	*d = DateTime{Time: t, raw: s}
+	return nil
+}
+
+// MarshalJSON serializa la fecha en RFC3339
+func (d DateTime) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return d.Time.MarshalJSON()
+}
+
+// In vuelve a interpretar el valor original en la zona horaria y lo deja en UTC
+func (d *DateTime) In(loc *time.Location) error {
+	if d.raw == "" {
+		return nil
+	}
+	t, err := parseDateTime(d.raw, loc)
+	if err != nil {
+		return err
+	}
+	d.Time = t
+	return nil
+}
+
+// parseDateTime interpreta el valor con zona horaria o en loc si no la tiene y retorna UTC
+func parseDateTime(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t.UTC(), nil
+	}
+	for _, layout := range validation.DateLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("el valor '%s' no es una fecha válida", value)
+}
+
+var (
+	dateTimeType  = reflect.TypeOf(DateTime{})
+	dateTimeCache sync.Map
+	locations     sync.Map
+)
+
+// resolveDateTimes interpreta los campos DateTime en la zona horaria del request
+// solo se busca la zona si el FormRequest tiene campos DateTime
+func resolveDateTimes(request FormRequest, req *http.Request) error {
+	var loc *time.Location
+	var errs validation.CodedErrors
+	err := eachTarget(request, func(rv reflect.Value, prefix string) error {
+		fields := getDateTimeFields(rv.Type())
+		if len(fields) == 0 {
+			return nil
+		}
+		if loc == nil {
+			var err error
+			if loc, err = requestLocation(request, req); err != nil {
+				var tzErrs validation.CodedErrors
+				tzErrs.Add("timezone", CodeTimezone, err.Error())
+				return &tzErrs
+			}
+		}
+
+		for _, f := range fields {
+			fv, err := rv.FieldByIndexErr(f.index)
+			if err != nil {
+				continue
+			}
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			if err := fv.Addr().Interface().(*DateTime).In(loc); err != nil {
+				errs.Add(prefix+f.name, CodeDateTime, err.Error())
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return errs.Err()
+}
+
+// requestLocation obtiene la zona horaria del FormRequest, del resolver o la de por defecto
+func requestLocation(request FormRequest, req *http.Request) (*time.Location, error) {
+	var name string
+	if tr, ok := request.(TimezoneRequest); ok {
+		name = tr.Timezone(req)
+	} else if TimezoneResolver != nil {
+		name = TimezoneResolver(req)
+	}
+	if name == "" {
+		return DefaultTimezone, nil
+	}
+
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("la zona horaria '%s' no existe", name)
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
+
+// getDateTimeFields retorna los campos DateTime del tipo desde el cache o los calcula
+func getDateTimeFields(t reflect.Type) []bindField {
+	if cached, ok := dateTimeCache.Load(t); ok {
+		return cached.([]bindField)
+	}
+	fields := buildDateTimeFields(t, nil, "")
+	dateTimeCache.Store(t, fields)
+	return fields
+}
+
+// buildDateTimeFields busca los campos DateTime incluidos los de structs embebidos y anidados
+func buildDateTimeFields(t reflect.Type, index []int, prefix string) []bindField {
+	var fields []bindField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldIndex := append(append([]int{}, index...), i)
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		if fieldType == dateTimeType {
+			fields = append(fields, bindField{index: fieldIndex, name: prefix + fieldName(field)})
+			continue
+		}
+		if fieldType.Kind() != reflect.Struct || field.Type.Kind() == reflect.Ptr {
+			continue
+		}
+		if field.Anonymous {
+			fields = append(fields, buildDateTimeFields(fieldType, fieldIndex, prefix)...)
+		} else if fieldType != reflect.TypeOf(time.Time{}) {
+			fields = append(fields, buildDateTimeFields(fieldType, fieldIndex, prefix+fieldName(field)+".")...)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,57 @@
+package request
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+type timezoneForm struct {
+	hooks
+	Due string `json:"due" rules:"test_timezone"`
+}
+
+// ruleLocation zona que vio la regla test_timezone en la ultima validacion
+var ruleLocation *time.Location
+
+func init() {
+	validation.RegisterRule("test_timezone", func(f *validation.Field) error {
+		ruleLocation = validation.LocationResolver(f.Context)
+		return nil
+	})
+}
+
+func TestDateRulesUseRequestTimezone(t *testing.T) {
+	if _, err := time.LoadLocation("America/Bogota"); err != nil {
+		t.Skip("no hay base de zonas horarias:", err)
+	}
+	cases := []struct {
+		name   string
+		target string
+		header string
+		want   string
+	}{
+		{"sin zona", "/tasks", "", "UTC"},
+		{"cabecera", "/tasks", "America/Bogota", "America/Bogota"},
+		{"parametro", "/tasks?tz=America/Bogota", "", "America/Bogota"},
+		{"zona invalida", "/tasks", "Marte/Olympus", "UTC"},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodPost, c.target, strings.NewReader(`{"due":"2026-10-15"}`))
+		req.Header.Set("Content-Type", "application/json")
+		if c.header != "" {
+			req.Header.Set(TimezoneHeader, c.header)
+		}
+		ruleLocation = nil
+		if err := Validate(&timezoneForm{}, req); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if ruleLocation == nil || ruleLocation.String() != c.want {
+			t.Errorf("%s: la regla vio la zona %v, se esperaba %s", c.name, ruleLocation, c.want)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/donbarrigon/new-project/internal/cache"
+	"github.com/donbarrigon/new-project/internal/orm"
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// Reglas unique y exists sobre la base de datos sql (orm.Conn, la transaccion de la solicitud si la hay)
+//
+//	Email   string `json:"email" rules:"required|email|unique:users,email,except:{auth.id}"`
+//	Slug    string `json:"slug" rules:"unique:projects,slug,tenant_id:{tenant.id}"`
+//	Country int    `json:"country_id" rules:"required|exists:countries,id"`
+//
+// parametros: tabla, columna (el nombre del campo si se omite) y modificadores
+//
+//	except:valor   ignora la fila con ese id (el propio registro al editarlo)
+//	columna:valor  solo cuenta las filas donde columna = valor (tenant, cuenta)
+//	withTrashed    cuenta tambien las filas borradas con soft delete
+//	onlyActive     solo cuenta las filas sin borrar, es lo que se hace por defecto
+//
+// las filas con SoftDeleteColumn no nulo estan borradas: un email de un usuario borrado se puede volver
+// a registrar y un pais borrado ya no existe. si la tabla tiene un indice unico que incluye las filas borradas
+// use withTrashed en unique para que la regla coincida con la base de datos
+
+// SoftDeleteColumn columna con la fecha de borrado de las tablas con soft delete
+var SoftDeleteColumn = "deleted_at"
+
+// SoftDeletes indica si la tabla usa soft delete, por defecto si su migracion tiene SoftDeleteColumn
+// reemplacela si las tablas no tienen migracion
+//
+//	request.SoftDeletes = func(table string) bool { return table != "countries" }
+var SoftDeletes = func(table string) bool {
+	t := cache.GetTable(table)
+	if t == nil {
+		return false
+	}
+	for _, column := range t.Columns {
+		if column.Name == SoftDeleteColumn {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	validation.RegisterRule("unique", uniqueRule)
+	validation.RegisterRule("exists", existsRule)
+	validation.ServerRules["unique"] = true
+	validation.ServerRules["exists"] = true
+}
+
+// uniqueRule falla si otra fila ya tiene el valor
+func uniqueRule(f *validation.Field) error {
+	q, err := parseDBRule("unique", f)
+	if err != nil {
+		return validation.Hard(err)
+	}
+	n, err := q.count(f, []any{f.Value})
+	if err != nil {
+		return validation.Hard(err)
+	}
+	if n > 0 {
+		return errors.New("el valor ya está en uso")
+	}
+	return nil
+}
+
+// existsRule falla si no hay una fila con el valor, con un slice cada elemento debe existir
+func existsRule(f *validation.Field) error {
+	q, err := parseDBRule("exists", f)
+	if err != nil {
+		return validation.Hard(err)
+	}
+	values := []any{f.Value}
+	if rv := reflect.ValueOf(f.Value); rv.Kind() == reflect.Slice {
+		values = make([]any, rv.Len())
+		for i := range values {
+			values[i] = rv.Index(i).Interface()
+		}
+	}
+	for _, value := range values {
+		n, err := q.count(f, []any{value})
+		if err != nil {
+			return validation.Hard(err)
+		}
+		if n == 0 {
+			return fmt.Errorf("el valor %v no existe", value)
+		}
+	}
+	return nil
+}
+
+// dbRule consulta de unique o exists ya interpretada
+type dbRule struct {
+	table   string
+	column  string
+	except  string
+	wheres  []string
+	args    []any
+	trashed bool
+}
+
+// parseDBRule interpreta tabla, columna y modificadores
+func parseDBRule(rule string, f *validation.Field) (*dbRule, error) {
+	if !orm.IsSQL() {
+		return nil, orm.ErrNoSQL
+	}
+	if len(f.Params) == 0 {
+		return nil, fmt.Errorf("la regla %s requiere la tabla (%s:users,email)", rule, rule)
+	}
+	name := f.Name
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	q := &dbRule{table: f.Params[0], column: name}
+	for i, p := range f.Params[1:] {
+		key, value, ok := strings.Cut(p, ":")
+		switch {
+		case p == "withTrashed":
+			q.trashed = true
+		case p == "onlyActive":
+			q.trashed = false
+		case ok && key == "except":
+			q.except = value
+		case ok:
+			if validation.Identifier(key) != nil {
+				return nil, fmt.Errorf("regla %s: columna inválida '%s'", rule, key)
+			}
+			q.wheres = append(q.wheres, key+" = ?")
+			q.args = append(q.args, value)
+		case i == 0:
+			q.column = p
+		default:
+			return nil, fmt.Errorf("regla %s: parámetro desconocido '%s'", rule, p)
+		}
+	}
+	// nunca vienen del cliente pero se revisan igual
+	if validation.Identifier(q.table) != nil || validation.Identifier(q.column) != nil {
+		return nil, fmt.Errorf("regla %s: tabla o columna inválida (%s.%s)", rule, q.table, q.column)
+	}
+	return q, nil
+}
+
+// count cuenta las filas con el valor y los modificadores
+func (q *dbRule) count(f *validation.Field, value []any) (int, error) {
+	query := "SELECT COUNT(*) FROM " + q.table + " WHERE " + q.column + " = ?"
+	args := append(value, q.args...)
+	for _, where := range q.wheres {
+		query += " AND " + where
+	}
+	if q.except != "" {
+		query += " AND id <> ?"
+		args = append(args, q.except)
+	}
+	if !q.trashed && SoftDeletes(q.table) {
+		query += " AND " + SoftDeleteColumn + " IS NULL"
+	}
+	var n int
+	err := orm.Conn(f.Context).QueryRowContext(f.Context, query, args...).Scan(&n)
+	return n, err
+}
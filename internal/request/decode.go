@@ -0,0 +1,171 @@
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// decodeError convierte el error de tipo de encoding/json en un error de validacion del campo (codigo VAL_TYPE)
+// "json: cannot unmarshal string into Go struct field User.age of type int" queda como
+//
+//	{"age": ["debe ser un número entero (se recibió texto)"]}
+//
+// la ruta se calcula con el offset del error, asi los elementos de los arrays llevan su indice (items.2.quantity)
+// el json mal formado tiene el codigo REQ_INVALID_JSON, los demas errores (UnmarshalJSON de los tipos propios)
+// se retornan sin cambios
+func decodeError(body []byte, err error) error {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return validation.WithCode(CodeInvalidJSON, err)
+	}
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		return err
+	}
+	field := pathAtOffset(body, typeErr.Offset)
+	if field == "" {
+		field = typeErr.Field
+	}
+	if field == "" {
+		return err
+	}
+	var errs validation.CodedErrors
+	errs.Add(field, validation.CodeType, typeMessage(typeErr))
+	return &errs
+}
+
+// pathFrame es un nivel del recorrido, un objeto guarda la clave actual y un array el indice
+type pathFrame struct {
+	array     bool
+	index     int
+	key       string
+	expectKey bool
+}
+
+// pathAtOffset retorna la ruta con notacion de puntos del valor que termina (o abre) en el offset
+func pathAtOffset(body []byte, offset int64) string {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var stack []pathFrame
+
+	path := func() string {
+		parts := make([]string, len(stack))
+		for i, frame := range stack {
+			if frame.array {
+				parts[i] = strconv.Itoa(frame.index)
+			} else {
+				parts[i] = frame.key
+			}
+		}
+		return strings.Join(parts, ".")
+	}
+	// startValue avanza el indice del array antes de cada elemento
+	startValue := func() {
+		if n := len(stack); n > 0 && stack[n-1].array {
+			stack[n-1].index++
+		}
+	}
+	// endValue deja al objeto esperando la siguiente clave
+	endValue := func() {
+		if n := len(stack); n > 0 && !stack[n-1].array {
+			stack[n-1].expectKey = true
+		}
+	}
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return ""
+		}
+		switch t := token.(type) {
+		case json.Delim:
+			switch t {
+			case '{', '[':
+				startValue()
+				// los errores de objetos y arrays apuntan justo despues de abrir
+				if decoder.InputOffset() >= offset {
+					return path()
+				}
+				stack = append(stack, pathFrame{array: t == '[', index: -1, expectKey: t == '{'})
+			case '}', ']':
+				stack = stack[:len(stack)-1]
+				if decoder.InputOffset() >= offset {
+					return path()
+				}
+				endValue()
+			}
+		default:
+			if n := len(stack); n > 0 && stack[n-1].expectKey {
+				stack[n-1].key = t.(string)
+				stack[n-1].expectKey = false
+				continue
+			}
+			startValue()
+			if decoder.InputOffset() >= offset {
+				return path()
+			}
+			endValue()
+		}
+	}
+}
+
+// typeMessage describe el tipo esperado y el recibido
+func typeMessage(err *json.UnmarshalTypeError) string {
+	t := derefType(err.Type)
+	if t.Implements(weakTyperType) {
+		t = derefType(reflect.Zero(t).Interface().(weakTyper).weakType())
+	}
+	expected := expectedType(t)
+
+	if number, ok := strings.CutPrefix(err.Value, "number "); ok {
+		// el valor es un numero pero no cabe en el tipo
+		if strings.ContainsAny(number, ".eE") && expected == "un número entero" {
+			return "debe ser un número entero"
+		}
+		return "el número está fuera de rango"
+	}
+	received := map[string]string{
+		"string": "texto",
+		"number": "un número",
+		"bool":   "un booleano",
+		"array":  "una lista",
+		"object": "un objeto",
+	}[err.Value]
+	if received == "" {
+		return "debe ser " + expected
+	}
+	return fmt.Sprintf("debe ser %s (se recibió %s)", expected, received)
+}
+
+func expectedType(t reflect.Type) string {
+	switch {
+	case t == jsonNumberType:
+		return "un número"
+	case t == timeType:
+		return "una fecha"
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "un número entero"
+	case reflect.Float32, reflect.Float64:
+		return "un número"
+	case reflect.String:
+		return "texto"
+	case reflect.Bool:
+		return "verdadero o falso"
+	case reflect.Slice, reflect.Array:
+		return "una lista"
+	case reflect.Map, reflect.Struct:
+		return "un objeto"
+	}
+	return "un valor de tipo " + t.String()
+}
@@ -0,0 +1,133 @@
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Campos obsoletos: los clientes que todavia los envian quedan en el log y en los hooks de OnDeprecated (metricas)
+// y con middleware.Deprecation reciben las cabeceras Deprecation y Sunset, asi se sabe quien falta por migrar
+//
+//	type CreateUser struct {
+//		FullName string `json:"full_name" rules:"required"`
+//		Name     string `json:"name" deprecated:"use full_name" sunset:"2027-01-01"`
+//	}
+//
+// el campo se sigue llenando y validando como siempre, solo se avisa. sunset es la fecha (2006-01-02)
+// en que el campo deja de funcionar. En los FormRequest que son un slice se revisan todos los elementos
+
+// LogDeprecated si es true cada campo obsoleto que llega se escribe en el log
+var LogDeprecated = true
+
+// DeprecatedField un campo obsoleto que envio el cliente
+type DeprecatedField struct {
+	Name    string    // nombre en notacion de puntos (address.zip)
+	Message string    // texto del tag deprecated, normalmente que usar en su lugar
+	Sunset  time.Time // fecha del tag sunset, cero si no tiene
+}
+
+// deprecatedHooks funciones que se llaman por cada campo obsoleto que llega
+var deprecatedHooks []func(req *http.Request, request FormRequest, field DeprecatedField)
+
+// OnDeprecated registra una funcion que se llama por cada campo obsoleto que envia un cliente
+// se debe llamar al iniciar la aplicacion, sirve para llevar metricas por cliente o por version
+//
+//	request.OnDeprecated(func(req *http.Request, fr request.FormRequest, field request.DeprecatedField) {
+//		metrics.Inc("deprecated_field", field.Name, req.Header.Get("User-Agent"))
+//	})
+func OnDeprecated(hook func(req *http.Request, request FormRequest, field DeprecatedField)) {
+	deprecatedHooks = append(deprecatedHooks, hook)
+}
+
+// deprecatedCache campos obsoletos por tipo de struct
+var deprecatedCache sync.Map
+
+// getDeprecatedFields retorna los campos con el tag deprecated del struct, incluidos los anidados
+func getDeprecatedFields(t reflect.Type) []DeprecatedField {
+	if cached, ok := deprecatedCache.Load(t); ok {
+		return cached.([]DeprecatedField)
+	}
+	fields := buildDeprecatedFields(t, "", map[reflect.Type]bool{})
+	deprecatedCache.Store(t, fields)
+	return fields
+}
+
+func buildDeprecatedFields(t reflect.Type, prefix string, visiting map[reflect.Type]bool) []DeprecatedField {
+	visiting[t] = true
+	defer delete(visiting, t)
+	var fields []DeprecatedField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if message, ok := field.Tag.Lookup("deprecated"); ok {
+			df := DeprecatedField{Name: prefix + fieldName(field), Message: message}
+			if sunset := field.Tag.Get("sunset"); sunset != "" {
+				df.Sunset, _ = time.Parse(time.DateOnly, sunset)
+			}
+			fields = append(fields, df)
+		}
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() != reflect.Struct || fieldType == dateTimeType || visiting[fieldType] {
+			continue
+		}
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			fields = append(fields, buildDeprecatedFields(fieldType, prefix, visiting)...)
+		} else {
+			fields = append(fields, buildDeprecatedFields(fieldType, prefix+fieldName(field)+".", visiting)...)
+		}
+	}
+	return fields
+}
+
+// checkDeprecated avisa de los campos obsoletos que vienen en el body
+func checkDeprecated(request FormRequest, body []byte, req *http.Request) {
+	st, ok := structType(reflect.TypeOf(request))
+	if !ok {
+		return
+	}
+	fields := getDeprecatedFields(st)
+	if len(fields) == 0 {
+		return
+	}
+	sent := sentFields(body)
+	for _, field := range fields {
+		if !sent[field.Name] {
+			continue
+		}
+		if LogDeprecated {
+			log.Printf("Campo obsoleto %s en %s %s: %s", field.Name, req.Method, req.URL.Path, field.Message)
+		}
+		for _, hook := range deprecatedHooks {
+			hook(req, request, field)
+		}
+	}
+}
+
+// sentFields campos que vienen en el body, si es un array los de todos sus elementos
+func sentFields(body []byte) map[string]bool {
+	sent := map[string]bool{}
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var elements []map[string]json.RawMessage
+		json.Unmarshal(body, &elements)
+		for _, object := range elements {
+			collectProvided(sent, "", object)
+		}
+		return sent
+	}
+	var object map[string]json.RawMessage
+	if json.Unmarshal(body, &object) == nil {
+		collectProvided(sent, "", object)
+	}
+	return sent
+}
@@ -0,0 +1,57 @@
+package request
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/donbarrigon/new-project/lib/ctxkey"
+)
+
+// Las solicitudes de solo validacion ejecutan Authorize, el llenado de los campos y las reglas
+// pero no el controlador: el frontend valida un formulario complejo con las reglas reales del servidor
+// y recibe 204 o 422 sin crear ni modificar nada (ver middleware.DryRun)
+//
+//	POST /users?dry_run=1
+//	POST /users con X-Validate-Only: true
+//
+// en estas solicitudes no se llaman los hooks de OnValidated (la auditoria no registra nada).
+// las cabeceras solo cuentan en las rutas con middleware.DryRun, que marca el contexto con WithDryRun:
+// en una ruta sin el middleware el controlador se ejecuta y la solicitud es normal
+
+var (
+	// DryRunHeader cabecera que marca la solicitud como de solo validacion
+	DryRunHeader = "X-Validate-Only"
+	// DryRunParam parametro de la url que marca la solicitud como de solo validacion
+	DryRunParam = "dry_run"
+)
+
+// dryRunKey marca la solicitud que atiende middleware.DryRun
+var dryRunKey = ctxkey.New[bool]("dry_run")
+
+// WantsDryRun indica si el cliente pidio solo validar, las solicitudes precognitivas tambien son de solo validacion
+// la usa middleware.DryRun para decidir, el resto del codigo debe usar IsDryRun
+func WantsDryRun(req *http.Request) bool {
+	if IsPrecognitive(req) {
+		return true
+	}
+	if v := req.Header.Get(DryRunHeader); v != "" {
+		ok, _ := strconv.ParseBool(v)
+		return ok
+	}
+	if req.URL == nil {
+		return false
+	}
+	ok, _ := strconv.ParseBool(req.URL.Query().Get(DryRunParam))
+	return ok
+}
+
+// WithDryRun marca la solicitud como de solo validacion, lo hace middleware.DryRun antes de validar
+func WithDryRun(req *http.Request) *http.Request {
+	return ctxkey.SetRequest(req, dryRunKey, true)
+}
+
+// IsDryRun indica si la solicitud es de solo validacion segun la marca de WithDryRun
+// no lee las cabeceras: en una ruta sin middleware.DryRun el cliente no puede saltarse los hooks de OnValidated
+func IsDryRun(req *http.Request) bool {
+	return ctxkey.Value(req.Context(), dryRunKey)
+}
@@ -0,0 +1,78 @@
+package request
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+type dryRunForm struct {
+	hooks
+	Name string `json:"name" rules:"required"`
+}
+
+// dryRunHooks cuenta las llamadas de OnValidated con dryRunForm, el hook se registra una sola vez
+var dryRunHooks atomic.Int32
+
+func init() {
+	OnValidated(func(r *http.Request, fr FormRequest) {
+		if _, ok := fr.(*dryRunForm); ok {
+			dryRunHooks.Add(1)
+		}
+	})
+}
+
+func TestDryRunIgnoresClientHeadersWithoutMiddleware(t *testing.T) {
+	cases := []struct {
+		name   string
+		target string
+		header string
+		marked bool
+		hooks  int32
+	}{
+		{"normal", "/users", "", false, 1},
+		{"cabecera sin middleware", "/users", "true", false, 1},
+		{"parametro sin middleware", "/users?dry_run=1", "", false, 1},
+		{"marcada por el middleware", "/users", "true", true, 0},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodPost, c.target, strings.NewReader(`{"name":"ana"}`))
+		req.Header.Set("Content-Type", "application/json")
+		if c.header != "" {
+			req.Header.Set(DryRunHeader, c.header)
+		}
+		if c.marked {
+			req = WithDryRun(req)
+		}
+		before := dryRunHooks.Load()
+		if err := Validate(&dryRunForm{}, req); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if got := dryRunHooks.Load() - before; got != c.hooks {
+			t.Errorf("%s: OnValidated se llamo %d veces, se esperaban %d", c.name, got, c.hooks)
+		}
+		if IsDryRun(req) != c.marked {
+			t.Errorf("%s: IsDryRun = %v, se esperaba %v", c.name, IsDryRun(req), c.marked)
+		}
+	}
+}
+
+func TestWantsDryRun(t *testing.T) {
+	cases := map[string]bool{
+		"/users":           false,
+		"/users?dry_run=1": true,
+		"/users?dry_run=0": false,
+	}
+	for target, want := range cases {
+		if got := WantsDryRun(httptest.NewRequest(http.MethodPost, target, nil)); got != want {
+			t.Errorf("WantsDryRun(%s) = %v, se esperaba %v", target, got, want)
+		}
+	}
+	req := httptest.NewRequest(http.MethodPost, "/users", nil)
+	req.Header.Set(PrecognitionHeader, "true")
+	if !WantsDryRun(req) {
+		t.Errorf("una solicitud precognitiva debe pedir solo validar")
+	}
+}
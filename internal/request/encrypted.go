@@ -0,0 +1,85 @@
+package request
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/donbarrigon/new-project/lib/crypt"
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// ErrCryptNotConfigured el FormRequest tiene campos encrypted y no se llamo crypt.Configure
+var ErrCryptNotConfigured = errors.New("crypt: no se configuró la llave para los campos encrypted")
+
+// decryptFields descifra los campos con el tag encrypted antes de validar
+// el cliente envia el valor cifrado (por ejemplo el que recibio en una respuesta) y las reglas ven el texto plano
+// el valor esta atado al nombre del campo (el del tag o el del json) y al id del registro si el struct
+// implementa crypt.Record, igual que lo cifra resource
+//
+//	type UpdateCard struct {
+//		ID     string `path:"id"`
+//		Number string `json:"number" rules:"required|max_digits:19" encrypted:"" sensitive:""`
+//	}
+//
+//	func (r *UpdateCard) RecordID() string { return r.ID }
+func decryptFields(request FormRequest) error {
+	var errs validation.CodedErrors
+	err := eachTarget(request, func(rv reflect.Value, prefix string) error {
+		fields := getTagFields(rv.Type(), "encrypted")
+		if len(fields) == 0 {
+			return nil
+		}
+		if crypt.Default == nil {
+			return ErrCryptNotConfigured
+		}
+
+		for _, f := range fields {
+			fv, err := rv.FieldByIndexErr(f.index)
+			if err != nil {
+				continue
+			}
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			if fv.String() == "" {
+				continue
+			}
+			plaintext, err := crypt.Default.DecryptString(fv.String(), encryptionContext(rv, f)...)
+			if err != nil {
+				errs.Add(prefix+f.name, CodeDecrypt, err.Error())
+				continue
+			}
+			fv.SetString(plaintext)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return errs.Err()
+}
+
+// encryptionContext contexto del cifrado del campo, el registro es el struct que tiene el campo
+// (los embebidos cuentan como parte del que los embebe, como en resource)
+func encryptionContext(rv reflect.Value, f bindField) []string {
+	owner := rv
+	for _, i := range f.index[:len(f.index)-1] {
+		field := rv.Type().Field(i)
+		rv = rv.Field(i)
+		if !field.Anonymous {
+			owner = rv
+		}
+	}
+	name := f.key
+	if name == "" {
+		name = f.name[strings.LastIndexByte(f.name, '.')+1:]
+	}
+	if owner.CanAddr() {
+		return crypt.FieldContext(owner.Addr().Interface(), name)
+	}
+	return crypt.FieldContext(owner.Interface(), name)
+}
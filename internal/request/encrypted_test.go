@@ -0,0 +1,59 @@
+package request
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/donbarrigon/new-project/internal/pkg/resource"
+	"github.com/donbarrigon/new-project/lib/crypt"
+)
+
+type cardModel struct {
+	ID     string `json:"id"`
+	Number string `json:"number" encrypted:""`
+}
+
+func (c *cardModel) RecordID() string { return c.ID }
+
+type updateCardForm struct {
+	hooks
+	ID     string `json:"id" rules:"required"`
+	Number string `json:"number" rules:"required" encrypted:""`
+}
+
+func (r *updateCardForm) RecordID() string { return r.ID }
+
+func TestEncryptedFieldsAreBoundToFieldAndRecord(t *testing.T) {
+	previous := crypt.Default
+	t.Cleanup(func() { crypt.Default = previous })
+	if err := crypt.Configure(crypt.GenerateKey()); err != nil {
+		t.Fatal(err)
+	}
+
+	// el valor que envia resource es el que el cliente devuelve
+	out := resource.Item(resource.NewContext(httptest.NewRequest(http.MethodGet, "/cards/1", nil)), &cardModel{ID: "1", Number: "4111"})
+	token := out.(map[string]any)["number"].(string)
+
+	cases := []struct {
+		name string
+		body string
+		ok   bool
+	}{
+		{"mismo registro", `{"id":"1","number":"` + token + `"}`, true},
+		{"otro registro", `{"id":"2","number":"` + token + `"}`, false},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodPut, "/cards", strings.NewReader(c.body))
+		req.Header.Set("Content-Type", "application/json")
+		form := &updateCardForm{}
+		err := Validate(form, req)
+		if c.ok && (err != nil || form.Number != "4111") {
+			t.Errorf("%s: se esperaba el numero descifrado, se obtuvo %q %v", c.name, form.Number, err)
+		}
+		if !c.ok && err == nil {
+			t.Errorf("%s: el valor de otro registro no se debe poder descifrar", c.name)
+		}
+	}
+}
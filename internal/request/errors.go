@@ -0,0 +1,142 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// Clases de error: todo error de Validate, ValidatePatch y ValidateBatch es de una clase (errors.Is) ademas de
+// conservar su tipo (errors.As con validation.ValidationErrors, *ThrottleError) y su codigo
+//
+//	switch err := request.Validate(&form, ctx.Request); {
+//	case err == nil:
+//	case errors.Is(err, request.ErrValidation): // 422, los errores por campo con errors.As
+//	case errors.Is(err, request.ErrDecode): // 400 json mal formado, body muy grande, forma incorrecta
+//	case errors.Is(err, request.ErrUnauthorized): // 403 Authorize retorno false
+//	case errors.Is(err, request.ErrTooManyRequests): // 429
+//	case errors.Is(err, request.ErrStaleVersion): // 409 o 412, ver *StaleVersionError
+//	case errors.Is(err, request.ErrHook): // PrepareForValidation o WithValidator fallaron
+//	default: // 500 (ErrPanic, HardError de una regla)
+//	}
+//
+// Validate no entra en panico: un panico del FormRequest, de un hook o de una regla se retorna como *PanicError
+var (
+	// ErrDecode el body no se pudo leer o deserializar
+	ErrDecode = errors.New("el body no se pudo leer")
+	// ErrUnauthorized el FormRequest no autorizo la solicitud (ErrForbidden es de esta clase)
+	ErrUnauthorized = errors.New("la solicitud no está autorizada")
+	// ErrValidation los datos no cumplen las reglas, el error tambien es validation.ValidationErrors
+	ErrValidation = errors.New("los datos no son válidos")
+	// ErrHook PrepareForValidation o WithValidator retornaron un error que no es de validacion
+	ErrHook = errors.New("falló un hook del FormRequest")
+	// ErrPanic algo entro en panico durante la validacion, ver *PanicError (o validation.ErrRulePanic si fue una regla)
+	ErrPanic = errors.New("la validación entró en pánico")
+)
+
+// errorClasses clases que ya tiene un error para no volver a clasificarlo
+var errorClasses = []error{ErrDecode, ErrUnauthorized, ErrValidation, ErrHook, ErrPanic}
+
+// classError agrega la clase al error sin cambiar el mensaje
+type classError struct {
+	class error
+	err   error
+}
+
+func (e *classError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap permite errors.Is con la clase y errors.Is/As con el error original
+func (e *classError) Unwrap() []error {
+	return []error{e.class, e.err}
+}
+
+// classify agrega la clase al error, los errores de validacion son ErrValidation sin importar de donde vengan
+// con class nil solo se clasifican los errores de validacion, el resto se retorna sin cambios
+func classify(class error, err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, c := range errorClasses {
+		if errors.Is(err, c) {
+			return err
+		}
+	}
+	var verrs validation.ValidationErrors
+	if errors.As(err, &verrs) {
+		class = ErrValidation
+	} else if errors.Is(err, validation.ErrRulePanic) {
+		class = ErrPanic
+	}
+	if class == nil {
+		return err
+	}
+	return &classError{class: class, err: err}
+}
+
+// ErrorStatus status http del error de Validate, ValidatePatch o ValidateBatch, el mismo para una solicitud
+// y para cada elemento de un lote. los errores con el metodo Status() int (*StaleVersionError o los del handler
+// de Batch) usan su status y los errores sin clase (un HardError de una regla, la base de datos) son 500
+//
+//	if err := request.Validate(&form, ctx.Request); err != nil {
+//		ctx.ResponseErr(request.ErrorStatus(err), err.Error(), err)
+//	}
+func ErrorStatus(err error) int {
+	var status interface{ Status() int }
+	switch {
+	case err == nil:
+		return http.StatusOK
+	case errors.Is(err, ErrValidation):
+		return http.StatusUnprocessableEntity
+	case errors.As(err, &status):
+		return status.Status()
+	case errors.Is(err, ErrTooManyRequests):
+		return http.StatusTooManyRequests
+	case errors.Is(err, ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, ErrDecode), errors.Is(err, ErrHook):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// PanicError panico recuperado durante la validacion, Stack es la pila donde ocurrio
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%s: %v", ErrPanic.Error(), e.Value)
+}
+
+func (e *PanicError) Is(target error) bool {
+	return target == ErrPanic
+}
+
+// recoverPanic convierte el panico en *PanicError, se usa con defer en las funciones que retornan error
+//
+//	func validateRequest(...) (err error) {
+//		defer recoverPanic(&err)
+func recoverPanic(err *error) {
+	if r := recover(); r != nil {
+		*err = &PanicError{Value: r, Stack: debug.Stack()}
+	}
+}
+
+// safeHook ejecuta un hook que solo observa (OnValidation), si entra en panico se registra y se sigue
+func safeHook(name string, hook func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("request: el hook %s entró en pánico: %v\n%s", name, r, debug.Stack())
+		}
+	}()
+	hook()
+}
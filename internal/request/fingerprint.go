@@ -0,0 +1,75 @@
+package request
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// FingerprintExcluder lo implementa el FormRequest que no quiere incluir algunos campos en su huella
+// por ejemplo marcas de tiempo del cliente o nonces que cambian en cada reintento
+//
+//	func (o *CreateOrder) FingerprintExclude() []string { return []string{"client_time", "meta.nonce"} }
+type FingerprintExcluder interface {
+	FingerprintExclude() []string
+}
+
+// Fingerprint retorna un hash sha256 estable del metodo, la ruta y los datos ya validados del request
+// dos requests con los mismos datos producen la misma huella sin importar el orden de las llaves
+// ni los espacios del body original, sirve para deduplicar, correlacionar auditorias y llaves de cache
+// exclude (y FingerprintExclude) quitan campos en notacion de puntos
+//
+//	if err := request.Validate(&req, ctx.Request); err != nil { ... }
+//	key := request.Fingerprint(&req, ctx.Request, "password")
+func Fingerprint(request FormRequest, req *http.Request, exclude ...string) string {
+	if e, ok := request.(FingerprintExcluder); ok {
+		exclude = append(exclude, e.FingerprintExclude()...)
+	}
+
+	h := sha256.New()
+	h.Write([]byte(req.Method))
+	h.Write([]byte{'\n'})
+	h.Write([]byte(req.URL.Path))
+	h.Write([]byte{'\n'})
+	h.Write(canonicalJSON(request, exclude))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// canonicalJSON serializa el request con las llaves ordenadas y sin los campos excluidos
+// encoding/json ordena las llaves de los map, por eso se pasa por un map antes de serializar
+func canonicalJSON(v any, exclude []string) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc any
+	if err := decoder.Decode(&doc); err != nil {
+		return data
+	}
+	for _, field := range exclude {
+		removePath(doc, strings.Split(field, "."))
+	}
+	canonical, err := json.Marshal(doc)
+	if err != nil {
+		return data
+	}
+	return canonical
+}
+
+// removePath quita el campo del documento siguiendo la notacion de puntos
+func removePath(doc any, path []string) {
+	m, ok := doc.(map[string]any)
+	if !ok || len(path) == 0 {
+		return
+	}
+	if len(path) == 1 {
+		delete(m, path[0])
+		return
+	}
+	removePath(m[path[0]], path[1:])
+}
@@ -0,0 +1,274 @@
+package request
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// Importacion de archivos CSV: cada fila se convierte en un FormRequest y se valida con las reglas del tag rules
+// las columnas se buscan por el tag csv o por el nombre json del campo (sin importar mayusculas)
+//
+//	type UserRow struct {
+//		Name  string `json:"name" csv:"Nombre" rules:"required|min:3"`
+//		Email string `json:"email" csv:"Correo" rules:"required|email"`
+//		Age   int    `json:"age" rules:"min:18"`
+//	}
+//
+//	func (u *UserRow) PrepareForValidation() error { return nil }
+//	func (u *UserRow) WithValidator() error        { return nil }
+//
+//	rows, report, err := request.ImportUpload(ctx.Request, "file", func() *UserRow { return &UserRow{} })
+//	if err != nil { ... } // el archivo no se pudo leer
+//	ctx.ResponseJSON(http.StatusOK, report)
+//
+// las filas se numeran como en la hoja de calculo: la cabecera es la fila 1 y los datos empiezan en la 2
+// si la fila implementa AuthorizedRequest se revisa Authorize una vez antes de leer el archivo (ErrForbidden)
+
+// MaxImportRows cantidad maxima de filas que acepta una importacion
+var MaxImportRows = 10000
+
+// MaxImportSize tamaño maximo en bytes del archivo que recibe ImportUpload
+var MaxImportSize int64 = 10 << 20
+
+// importFormOverhead bytes del formulario multipart que se aceptan ademas del archivo (cabeceras, otros campos)
+const importFormOverhead = 1 << 20
+
+// ErrImportNoColumns ninguna columna del archivo corresponde a un campo de la fila
+var ErrImportNoColumns = errors.New("el archivo no tiene ninguna de las columnas esperadas")
+
+// RowError errores de una fila del archivo
+type RowError struct {
+	Row    int                         `json:"row"`
+	Error  string                      `json:"error,omitempty"`
+	Errors validation.ValidationErrors `json:"errors,omitempty"`
+	Codes  map[string][]string         `json:"codes,omitempty"`
+}
+
+// ImportReport resumen de la importacion para responder al cliente
+type ImportReport struct {
+	Total   int        `json:"total"`
+	Valid   int        `json:"valid"`
+	Invalid int        `json:"invalid"`
+	Errors  []RowError `json:"errors"`
+}
+
+// importField campo de la fila y el nombre de la columna que lo llena
+type importField struct {
+	index  []int
+	name   string // nombre del campo para los errores
+	column string // nombre de la columna en minusculas
+}
+
+var importCache sync.Map
+
+// ImportCSV lee el CSV y retorna las filas validas y el reporte con los errores de las invalidas
+// las reglas usan el contexto de req. el error solo se retorna si la fila no autoriza la solicitud,
+// si el archivo no se puede leer, no tiene columnas conocidas o supera MaxImportRows
+func ImportCSV[T FormRequest](req *http.Request, r io.Reader, factory func() T) ([]T, *ImportReport, error) {
+	if err := checkAuthorize(factory(), req); err != nil {
+		return nil, nil, err
+	}
+	return importCSV(req.Context(), r, factory)
+}
+
+func importCSV[T FormRequest](ctx context.Context, r io.Reader, factory func() T) ([]T, *ImportReport, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, validation.WithCode(CodeImportInvalid, errors.New("el archivo está vacío"))
+	}
+	if err != nil {
+		return nil, nil, validation.WithCode(CodeImportInvalid, fmt.Errorf("el archivo no es un CSV válido: %w", err))
+	}
+
+	t := reflect.TypeOf(factory()).Elem()
+	if t.Kind() != reflect.Struct {
+		return nil, nil, errors.New("la fila de la importacion debe ser un struct")
+	}
+	columns := importColumns(getImportFields(t), header)
+	if len(columns) == 0 {
+		return nil, nil, ErrImportNoColumns
+	}
+
+	var rows []T
+	report := &ImportReport{Errors: []RowError{}}
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			var perr *csv.ParseError
+			if !errors.As(err, &perr) {
+				return nil, nil, err
+			}
+			report.add(RowError{Row: line, Error: "la fila no es un CSV válido"})
+			continue
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+		if report.Total >= MaxImportRows {
+			return nil, nil, validation.WithCode(CodeImportTooLarge, fmt.Errorf("el archivo no puede tener más de %d filas", MaxImportRows))
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		row := factory()
+		if rowErr, err := importRow(ctx, row, columns, record); err != nil {
+			return nil, nil, err
+		} else if rowErr != nil {
+			rowErr.Row = line
+			report.add(*rowErr)
+			continue
+		}
+		report.Total++
+		report.Valid++
+		rows = append(rows, row)
+	}
+	return rows, report, nil
+}
+
+// ImportUpload lee el CSV del campo del formulario multipart y lo importa con ImportCSV
+// el body se corta en MaxImportSize (mas las cabeceras del formulario) antes de leerlo
+func ImportUpload[T FormRequest](req *http.Request, field string, factory func() T) ([]T, *ImportReport, error) {
+	if err := checkAuthorize(factory(), req); err != nil {
+		return nil, nil, err
+	}
+	req.Body = http.MaxBytesReader(nil, req.Body, MaxImportSize+importFormOverhead)
+	if err := req.ParseMultipartForm(MaxImportSize); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, nil, validation.WithCode(CodeImportTooLarge, fmt.Errorf("el archivo no puede pesar más de %d bytes", MaxImportSize))
+		}
+		return nil, nil, validation.WithCode(CodeImportInvalid, fmt.Errorf("no se pudo leer el formulario: %w", err))
+	}
+	file, fh, err := req.FormFile(field)
+	if err != nil {
+		return nil, nil, validation.WithCode(CodeImportInvalid, fmt.Errorf("el campo %s debe ser un archivo", field))
+	}
+	defer file.Close()
+	if fh.Size > MaxImportSize {
+		return nil, nil, validation.WithCode(CodeImportTooLarge, fmt.Errorf("el archivo no puede pesar más de %d bytes", MaxImportSize))
+	}
+	return importCSV(req.Context(), file, factory)
+}
+
+func (r *ImportReport) add(rowErr RowError) {
+	r.Total++
+	r.Invalid++
+	r.Errors = append(r.Errors, rowErr)
+}
+
+// importRow llena la fila con las columnas y la valida, retorna el error de la fila si no es valida
+// el error final es un error grave de las reglas (HardError, contexto cancelado)
+func importRow(ctx context.Context, row FormRequest, columns map[int]importField, record []string) (*RowError, error) {
+	rv := reflect.ValueOf(row).Elem()
+	var errs validation.CodedErrors
+	for i, value := range record {
+		f, ok := columns[i]
+		if !ok {
+			continue
+		}
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		if err := setField(rv.FieldByIndex(f.index), []string{value}, ""); err != nil {
+			errs.Add(f.name, validation.CodeType, err.Error())
+		}
+	}
+	if len(errs.Errors) > 0 {
+		return &RowError{Errors: errs.Errors, Codes: errs.Codes}, nil
+	}
+
+	sanitizeFields(row)
+	if err := row.PrepareForValidation(); err != nil {
+		return &RowError{Error: err.Error()}, nil
+	}
+	if err := row.WithValidator(); err != nil {
+		return &RowError{Error: err.Error()}, nil
+	}
+	err := validation.StructContext(ctx, row)
+	var verrs validation.ValidationErrors
+	if errors.As(err, &verrs) {
+		return &RowError{Errors: verrs, Codes: validation.FieldCodes(err)}, nil
+	}
+	return nil, err
+}
+
+// importColumns relaciona la posicion de cada columna de la cabecera con su campo
+func importColumns(fields []importField, header []string) map[int]importField {
+	columns := make(map[int]importField)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\uFEFF")))
+		for _, f := range fields {
+			if f.column == name {
+				columns[i] = f
+				break
+			}
+		}
+	}
+	return columns
+}
+
+// getImportFields retorna los campos de la fila desde el cache o los calcula
+func getImportFields(t reflect.Type) []importField {
+	if cached, ok := importCache.Load(t); ok {
+		return cached.([]importField)
+	}
+	fields := buildImportFields(t, nil)
+	importCache.Store(t, fields)
+	return fields
+}
+
+// buildImportFields busca los campos que se pueden llenar desde una columna incluidos los de structs embebidos
+func buildImportFields(t reflect.Type, index []int) []importField {
+	var fields []importField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldIndex := append(append([]int{}, index...), i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			fields = append(fields, buildImportFields(field.Type, fieldIndex)...)
+			continue
+		}
+		column := field.Tag.Get("csv")
+		if column == "-" {
+			continue
+		}
+		name := fieldName(field)
+		if column == "" {
+			column = name
+		}
+		fields = append(fields, importField{index: fieldIndex, name: name, column: strings.ToLower(column)})
+	}
+	return fields
+}
+
+// isBlankRecord indica si todas las celdas de la fila estan vacias (filas vacias al final de un excel)
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+package request
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+type importRowForm struct {
+	hooks
+	Name  string `json:"name" csv:"Nombre" rules:"required|min:3"`
+	Email string `json:"email" csv:"Correo" rules:"required|email"`
+}
+
+type deniedRowForm struct {
+	importRowForm
+}
+
+func (d *deniedRowForm) Authorize(req *http.Request) bool { return false }
+
+// uploadRequest formulario multipart con el archivo en el campo file
+func uploadRequest(t *testing.T, content string) *http.Request {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", "usuarios.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write([]byte(content))
+	w.Close()
+	req := httptest.NewRequest(http.MethodPost, "/users/import", &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
+
+func TestImportCSV(t *testing.T) {
+	csv := "Nombre,Correo\nana maria,ana@example.com\nal,no-es-correo\n\n"
+	req := httptest.NewRequest(http.MethodPost, "/users/import", nil)
+	rows, report, err := ImportCSV(req, strings.NewReader(csv), func() *importRowForm { return &importRowForm{} })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].Email != "ana@example.com" {
+		t.Errorf("filas validas = %+v", rows)
+	}
+	if report.Total != 2 || report.Invalid != 1 || report.Errors[0].Row != 3 {
+		t.Errorf("reporte = %+v", report)
+	}
+}
+
+func TestImportAuthorize(t *testing.T) {
+	csv := "Nombre,Correo\nana maria,ana@example.com\n"
+	factory := func() *deniedRowForm { return &deniedRowForm{} }
+
+	req := httptest.NewRequest(http.MethodPost, "/users/import", nil)
+	if _, _, err := ImportCSV(req, strings.NewReader(csv), factory); !errors.Is(err, ErrForbidden) {
+		t.Errorf("ImportCSV: se esperaba ErrForbidden, se obtuvo %v", err)
+	}
+	if _, _, err := ImportUpload(uploadRequest(t, csv), "file", factory); !errors.Is(err, ErrForbidden) {
+		t.Errorf("ImportUpload: se esperaba ErrForbidden, se obtuvo %v", err)
+	}
+}
+
+func TestImportUploadLimitsBody(t *testing.T) {
+	previous := MaxImportSize
+	MaxImportSize = 1 << 10
+	defer func() { MaxImportSize = previous }()
+
+	content := "Nombre,Correo\n" + strings.Repeat("ana maria,ana@example.com\n", (importFormOverhead/26)+100)
+	req := uploadRequest(t, content)
+	_, _, err := ImportUpload(req, "file", func() *importRowForm { return &importRowForm{} })
+	if code := validation.ErrorCode(err); code != CodeImportTooLarge {
+		t.Errorf("se esperaba %s, se obtuvo %q (%v)", CodeImportTooLarge, code, err)
+	}
+}
@@ -0,0 +1,185 @@
+package request
+
+import (
+	"log"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/donbarrigon/new-project/lib/ctxkey"
+)
+
+// LatencyBudgetRequest lo implementa el FormRequest que declara cuanto debe tardar su endpoint (SLO)
+// no corta la solicitud, al terminar se compara el tiempo con el presupuesto y se avisa a OnLatency
+// con el middleware LatencyBudget el tiempo incluye el controlador, sin el solo la lectura y la validacion
+//
+//	func (r *Search) LatencyBudget() time.Duration { return 300 * time.Millisecond }
+type LatencyBudgetRequest interface {
+	LatencyBudget() time.Duration
+}
+
+// Timings tiempos de cada fase de la solicitud
+type Timings struct {
+	Decode   time.Duration `json:"decode"`   // leer y deserializar el body
+	Validate time.Duration `json:"validate"` // hooks del FormRequest y reglas
+	Handler  time.Duration `json:"handler"`  // el resto del controlador, 0 sin el middleware LatencyBudget
+	Total    time.Duration `json:"total"`
+}
+
+// LatencyReport resultado de una solicitud con presupuesto de latencia
+type LatencyReport struct {
+	Request  string        `json:"request"` // tipo del FormRequest
+	Method   string        `json:"method"`
+	Path     string        `json:"path"`
+	Budget   time.Duration `json:"budget"`
+	Timings  Timings       `json:"timings"`
+	Exceeded bool          `json:"exceeded"`
+}
+
+// latencyHooks funciones que reciben el reporte de cada solicitud con presupuesto
+var latencyHooks []func(req *http.Request, report LatencyReport)
+
+// OnLatency registra una funcion que recibe los tiempos de cada solicitud con presupuesto, excedido o no
+// sirve para las metricas del SLO. sin hooks los presupuestos excedidos se escriben en el log
+// se debe llamar al iniciar la aplicacion
+//
+//	request.OnLatency(func(req *http.Request, r request.LatencyReport) {
+//		histogram.WithLabelValues(r.Request).Observe(r.Timings.Total.Seconds())
+//	})
+func OnLatency(hook func(req *http.Request, report LatencyReport)) {
+	latencyHooks = append(latencyHooks, hook)
+}
+
+// LatencyKey llave del contexto con el registro de tiempos de la solicitud
+var LatencyKey = ctxkey.New[*LatencyRecorder]("latency")
+
+// LatencyRecorder guarda los tiempos de una solicitud, lo crea el middleware LatencyBudget
+// o Validate si el FormRequest tiene presupuesto y no se uso el middleware
+type LatencyRecorder struct {
+	mu       sync.Mutex
+	start    time.Time
+	budget   time.Duration
+	request  string
+	decoded  time.Time
+	timings  Timings
+	measured bool
+}
+
+// NewLatencyRecorder empieza a medir la solicitud, budget es el presupuesto si el FormRequest no declara uno
+func NewLatencyRecorder(budget time.Duration) *LatencyRecorder {
+	return &LatencyRecorder{start: time.Now(), budget: budget}
+}
+
+// Timings retorna los tiempos medidos hasta ahora
+func (r *LatencyRecorder) Timings() Timings {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.timings
+}
+
+// Finish calcula el tiempo del controlador y avisa a OnLatency, lo llama el middleware al terminar
+// sin presupuesto no se reporta nada
+func (r *LatencyRecorder) Finish(req *http.Request) {
+	r.mu.Lock()
+	total := time.Since(r.start)
+	r.timings.Total = total
+	if handler := total - r.timings.Decode - r.timings.Validate; handler > 0 {
+		r.timings.Handler = handler
+	}
+	report := r.report(req)
+	r.mu.Unlock()
+	if report.Budget > 0 {
+		emitLatency(req, report)
+	}
+}
+
+// report arma el reporte con los tiempos actuales
+func (r *LatencyRecorder) report(req *http.Request) LatencyReport {
+	return LatencyReport{
+		Request:  r.request,
+		Method:   req.Method,
+		Path:     req.URL.Path,
+		Budget:   r.budget,
+		Timings:  r.timings,
+		Exceeded: r.budget > 0 && r.timings.Total > r.budget,
+	}
+}
+
+// trackLatency retorna el registro de tiempos de la solicitud, el del middleware o uno nuevo si el FormRequest
+// tiene presupuesto. sin ninguno de los dos retorna nil y no se mide nada
+func trackLatency(request FormRequest, req *http.Request) (*http.Request, *LatencyRecorder, bool) {
+	budget := time.Duration(0)
+	if b, ok := request.(LatencyBudgetRequest); ok {
+		budget = b.LatencyBudget()
+	}
+	rec, ok := ctxkey.Get(req.Context(), LatencyKey)
+	if ok {
+		rec.mu.Lock()
+		if budget > 0 {
+			rec.budget = budget
+		}
+		rec.request = requestName(request)
+		rec.mu.Unlock()
+		return req, rec, false
+	}
+	if budget <= 0 {
+		return req, nil, false
+	}
+	rec = NewLatencyRecorder(budget)
+	rec.request = requestName(request)
+	return ctxkey.SetRequest(req, LatencyKey, rec), rec, true
+}
+
+// markDecoded marca el fin de la lectura del body, el resto de Validate es validacion
+func markDecoded(req *http.Request) {
+	if rec, ok := ctxkey.Get(req.Context(), LatencyKey); ok {
+		rec.mu.Lock()
+		rec.decoded = time.Now()
+		rec.mu.Unlock()
+	}
+}
+
+// validated guarda los tiempos de Validate, si el registro es solo de Validate (own) se reporta de una vez
+func (r *LatencyRecorder) validated(req *http.Request, start time.Time, own bool) {
+	r.mu.Lock()
+	now := time.Now()
+	decoded := r.decoded
+	if decoded.IsZero() || decoded.Before(start) {
+		decoded = now
+	}
+	r.timings.Decode += decoded.Sub(start)
+	r.timings.Validate += now.Sub(decoded)
+	if !own {
+		r.mu.Unlock()
+		return
+	}
+	r.timings.Total = now.Sub(r.start)
+	report := r.report(req)
+	r.mu.Unlock()
+	emitLatency(req, report)
+}
+
+// emitLatency avisa a los hooks o escribe en el log si se excedio el presupuesto
+func emitLatency(req *http.Request, report LatencyReport) {
+	if len(latencyHooks) == 0 {
+		if report.Exceeded {
+			log.Printf("latency_budget_exceeded request=%s method=%s path=%s budget=%s total=%s decode=%s validate=%s handler=%s",
+				report.Request, report.Method, report.Path, report.Budget, report.Timings.Total,
+				report.Timings.Decode, report.Timings.Validate, report.Timings.Handler)
+		}
+		return
+	}
+	for _, hook := range latencyHooks {
+		safeHook("OnLatency", func() { hook(req, report) })
+	}
+}
+
+// requestName nombre del tipo del FormRequest para los reportes
+func requestName(request FormRequest) string {
+	t := reflect.TypeOf(request)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.String()
+}
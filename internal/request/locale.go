@@ -0,0 +1,228 @@
+package request
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/donbarrigon/new-project/lib/ctxkey"
+	"github.com/donbarrigon/new-project/lib/validation"
+	"golang.org/x/text/language"
+)
+
+// DefaultLocale idioma que se usa cuando el request no envia Accept-Language
+var DefaultLocale = language.MustParse("es")
+
+// SupportedLocales idiomas que la aplicacion sabe responder, en orden de preferencia
+// LocaleOf y middleware.Locale eligen entre ellos segun Accept-Language, nil acepta cualquier idioma valido
+//
+//	request.SupportedLocales = []language.Tag{language.Spanish, language.MustParse("es-MX"), language.English}
+var SupportedLocales []language.Tag
+
+// maxLanguageRanges idiomas de Accept-Language que se tienen en cuenta, el resto se ignora
+const maxLanguageRanges = 32
+
+// LocaleKey llave del contexto con el idioma del request
+var LocaleKey = ctxkey.New[language.Tag]("locale")
+
+func init() {
+	// los mensajes de validacion usan el idioma del request, ver validation.RegisterMessages
+	validation.LocaleResolver = func(ctx context.Context) string {
+		if locale, ok := ctxkey.Get(ctx, LocaleKey); ok {
+			return locale.String()
+		}
+		return ""
+	}
+}
+
+// WithLocale guarda el idioma del request, reemplaza el de Accept-Language
+// lo usa el middleware que lee el idioma del usuario o del tenant
+//
+//	req = request.WithLocale(req, language.MustParse("es-MX"))
+func WithLocale(req *http.Request, locale language.Tag) *http.Request {
+	return ctxkey.SetRequest(req, LocaleKey, locale)
+}
+
+// Locale retorna el idioma guardado en el contexto o DefaultLocale
+// dentro de RulesFor el contexto ya tiene el idioma del request
+func Locale(ctx context.Context) language.Tag {
+	if locale, ok := ctxkey.Get(ctx, LocaleKey); ok {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// LocaleOf retorna el idioma del request: el de WithLocale, el que se negocia con Accept-Language
+// entre SupportedLocales o DefaultLocale
+func LocaleOf(req *http.Request) language.Tag {
+	if locale, ok := ctxkey.Get(req.Context(), LocaleKey); ok {
+		return locale
+	}
+	if locale, ok := NegotiateLocale(req.Header.Get("Accept-Language"), SupportedLocales); ok {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// LanguageRange idioma de Accept-Language con su peso, Tag es "*" para cualquier idioma
+type LanguageRange struct {
+	Tag string
+	Q   float64
+}
+
+// ParseAcceptLanguage interpreta la cabecera (es-CO, es;q=0.8, en;q=0.5, *;q=0.1) ordenada por peso
+// de mayor a menor, los del mismo peso quedan en el orden de la cabecera. los idiomas invalidos
+// y los pesos mal escritos se ignoran, los de peso 0 quedan al final (el cliente no los acepta)
+func ParseAcceptLanguage(header string) []LanguageRange {
+	var ranges []LanguageRange
+	for _, part := range strings.Split(header, ",") {
+		if len(ranges) == maxLanguageRanges {
+			break
+		}
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		q := 1.0
+		if params != "" {
+			name, value, _ := strings.Cut(strings.TrimSpace(params), "=")
+			n, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if strings.TrimSpace(name) != "q" || err != nil || n < 0 || n > 1 {
+				continue
+			}
+			q = n
+		}
+		if tag != "*" {
+			parsed, err := language.Parse(tag)
+			if err != nil {
+				continue
+			}
+			tag = parsed.String()
+		}
+		ranges = append(ranges, LanguageRange{Tag: tag, Q: q})
+	}
+	slices.SortStableFunc(ranges, func(a, b LanguageRange) int {
+		switch {
+		case a.Q > b.Q:
+			return -1
+		case a.Q < b.Q:
+			return 1
+		}
+		return 0
+	})
+	return ranges
+}
+
+// NegotiateLocale elige el idioma de supported que mejor cumple Accept-Language
+// primero busca cada idioma del cliente en orden de peso recortandolo (es-CO-x -> es-CO -> es),
+// despues acepta uno de supported con el mismo idioma base (es-CO pide y hay es-MX) y * acepta el primero
+// que el cliente no excluyo con q=0. sin supported retorna el primer idioma del cliente.
+// ok es false si ninguno coincide, en ese caso use DefaultLocale
+//
+//	locale, ok := request.NegotiateLocale("es-CO,en;q=0.5", []language.Tag{language.English, language.Spanish}) // es
+func NegotiateLocale(header string, supported []language.Tag) (language.Tag, bool) {
+	ranges := ParseAcceptLanguage(header)
+	if len(supported) == 0 {
+		for _, r := range ranges {
+			if r.Q > 0 && r.Tag != "*" {
+				return language.Make(r.Tag), true
+			}
+		}
+		return language.Und, false
+	}
+
+	var excluded []string
+	for _, r := range ranges {
+		if r.Q == 0 {
+			excluded = append(excluded, strings.ToLower(r.Tag))
+		}
+	}
+	acceptable := func(tag language.Tag) bool {
+		name := strings.ToLower(tag.String())
+		for _, ex := range excluded {
+			if ex == "*" || name == ex || strings.HasPrefix(name, ex+"-") {
+				return false
+			}
+		}
+		return true
+	}
+	find := func(name string) (language.Tag, bool) {
+		for _, tag := range supported {
+			if strings.EqualFold(tag.String(), name) && acceptable(tag) {
+				return tag, true
+			}
+		}
+		return language.Und, false
+	}
+
+	for _, r := range ranges {
+		if r.Q == 0 || r.Tag == "*" {
+			continue
+		}
+		for name := r.Tag; name != ""; {
+			if tag, ok := find(name); ok {
+				return tag, true
+			}
+			cut := strings.LastIndexByte(name, '-')
+			if cut < 0 {
+				break
+			}
+			name = name[:cut]
+		}
+	}
+	for _, r := range ranges {
+		if r.Q == 0 {
+			continue
+		}
+		if r.Tag == "*" {
+			for _, tag := range supported {
+				if acceptable(tag) {
+					return tag, true
+				}
+			}
+			continue
+		}
+		base, _ := language.Make(r.Tag).Base()
+		for _, tag := range supported {
+			if b, _ := tag.Base(); b == base && acceptable(tag) {
+				return tag, true
+			}
+		}
+	}
+	return language.Und, false
+}
+
+// RulesForRequest lo implementa el FormRequest cuyas reglas cambian segun el tenant o el idioma del request
+// RulesFor retorna las reglas que reemplazan a las del tag rules (ver validation.WithRules), nil deja las del tag
+//
+//	func (c *Customer) RulesFor(ctx context.Context) map[string]string {
+//		region, _ := request.Locale(ctx).Region() // es-MX -> MX
+//		if t, ok := tenant.FromContext(ctx); ok && t.Config["country"] != "" {
+//			region = language.MustParseRegion(t.Config["country"])
+//		}
+//		switch region.String() {
+//		case "MX":
+//			return map[string]string{"postal_code": "required|postal_code:MX", "tax_id": "required|rfc"}
+//		case "CO":
+//			return map[string]string{"postal_code": "postal_code:CO", "tax_id": "required|nit"}
+//		}
+//		return nil
+//	}
+type RulesForRequest interface {
+	RulesFor(ctx context.Context) map[string]string
+}
+
+// withRulesFor agrega al contexto las reglas de RulesFor, el contexto que recibe RulesFor tiene el idioma del request
+func withRulesFor(ctx context.Context, request FormRequest, req *http.Request) context.Context {
+	r, ok := request.(RulesForRequest)
+	if !ok {
+		return ctx
+	}
+	if !ctxkey.Has(ctx, LocaleKey) {
+		ctx = ctxkey.Set(ctx, LocaleKey, LocaleOf(req))
+	}
+	return validation.WithRules(ctx, r.RulesFor(ctx))
+}
@@ -0,0 +1,137 @@
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// Optional campo que distingue entre no enviado, enviado como null y enviado con valor
+// se usa en los PATCH para actualizar solo lo que el cliente envio
+//
+//	type UpdateUser struct {
+//		request.Request
+//		Name request.Optional[string] `json:"name" rules:"sometimes|required|min:3"`
+//		Bio  request.Optional[string] `json:"bio"`
+//	}
+//
+//	if req.Bio.Set {
+//		user.Bio = req.Bio.Value // "" si vino null
+//	}
+type Optional[T any] struct {
+	Value T
+	Set   bool // el cliente envio la llave
+	Null  bool // el cliente la envio como null
+}
+
+// Some crea un Optional con valor
+func Some[T any](value T) Optional[T] {
+	return Optional[T]{Value: value, Set: true}
+}
+
+// UnmarshalJSON solo se llama si la llave viene en el json, asi se sabe que se envio
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		var zero T
+		o.Value = zero
+		o.Null = true
+		return nil
+	}
+	o.Null = false
+	return json.Unmarshal(data, &o.Value)
+}
+
+// MarshalJSON serializa el valor o null
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.Set || o.Null {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.Value)
+}
+
+// IsZero el campo no se envio o vino null, lo usa la regla required
+func (o Optional[T]) IsZero() bool {
+	return !o.Set || o.Null
+}
+
+// Get retorna el valor y si se envio con valor
+func (o Optional[T]) Get() (T, bool) {
+	return o.Value, o.Set && !o.Null
+}
+
+// ValidationValue las reglas se ejecutan sobre el valor, null se valida como nil
+func (o Optional[T]) ValidationValue() (any, bool) {
+	if o.Null {
+		return nil, o.Set
+	}
+	return o.Value, o.Set
+}
+
+// providedHolder lo implementa Request para guardar que campos vinieron en el body
+type providedHolder interface {
+	setProvidedBody(body []byte)
+}
+
+// Provided indica si el cliente envio el campo en el body, acepta notacion de puntos (address.city)
+// a diferencia de revisar el valor distingue entre "name": null, "name": "" y no enviar name
+func (r *Request) Provided(field string) bool {
+	r.loadProvided()
+	return r.provided[field]
+}
+
+// ProvidedFields retorna los campos que vinieron en el body ordenados, los anidados en notacion de puntos
+func (r *Request) ProvidedFields() []string {
+	r.loadProvided()
+	fields := make([]string, 0, len(r.provided))
+	for f := range r.provided {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func (r *Request) setProvidedBody(body []byte) {
+	r.providedBody = body
+	r.provided = nil
+}
+
+// loadProvided calcula los campos enviados solo la primera vez que se necesitan
+func (r *Request) loadProvided() {
+	if r.provided != nil {
+		return
+	}
+	r.provided = map[string]bool{}
+	var object map[string]json.RawMessage
+	if json.Unmarshal(r.providedBody, &object) == nil {
+		collectProvided(r.provided, "", object)
+	}
+}
+
+// collectProvided agrega las llaves del objeto y de sus objetos anidados
+func collectProvided(provided map[string]bool, prefix string, object map[string]json.RawMessage) {
+	for key, raw := range object {
+		provided[prefix+key] = true
+		raw = bytes.TrimSpace(raw)
+		if len(raw) > 0 && raw[0] == '{' {
+			var nested map[string]json.RawMessage
+			if json.Unmarshal(raw, &nested) == nil {
+				collectProvided(provided, prefix+key+".", nested)
+			}
+		}
+	}
+}
+
+// OnlyProvided filtra values dejando solo las llaves que el cliente envio
+// sirve para armar el update de la base de datos en un PATCH
+//
+//	orm.Update(user, req.OnlyProvided(map[string]any{"name": req.Name.Value, "bio": req.Bio.Value}))
+func (r *Request) OnlyProvided(values map[string]any) map[string]any {
+	out := make(map[string]any, len(values))
+	for k, v := range values {
+		if r.Provided(k) {
+			out[k] = v
+		}
+	}
+	return out
+}
@@ -0,0 +1,80 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/donbarrigon/new-project/lib/jsonpatch"
+)
+
+// ErrUnsupportedPatch el Content-Type no es un formato de patch soportado (se responde 415)
+var ErrUnsupportedPatch = errors.New("el Content-Type debe ser application/merge-patch+json o application/json-patch+json")
+
+// ValidatePatch aplica el patch del body sobre current y valida el resultado con el FormRequest
+// application/merge-patch+json (RFC 7396) mezcla el body sobre el recurso
+// application/json-patch+json (RFC 6902) aplica la lista de operaciones
+// current es el recurso actual (el modelo o su representacion) y se serializa con encoding/json
+// el FormRequest queda con el recurso completo ya modificado, listo para guardar
+//
+//	err := request.ValidatePatch(&req, user, ctx.Request)
+//	switch {
+//	case errors.Is(err, request.ErrUnsupportedPatch): // 415
+//	case errors.Is(err, jsonpatch.ErrTestFailed):     // 409
+//	case errors.Is(err, jsonpatch.ErrInvalidPatch):   // 400
+//	}
+func ValidatePatch(request FormRequest, current any, req *http.Request) error {
+	req, latency, own := trackLatency(request, req)
+	if len(validationHooks) == 0 && latency == nil {
+		return validatePatch(request, current, req)
+	}
+	start := time.Now()
+	err := validatePatch(request, current, req)
+	if latency != nil {
+		latency.validated(req, start, own)
+	}
+	notifyValidation(req, request, err, start)
+	return err
+}
+
+// validatePatch ejecuta ValidatePatch sin avisar a los hooks de OnValidation
+func validatePatch(request FormRequest, current any, req *http.Request) (err error) {
+	defer recoverPanic(&err)
+
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if mediaType != jsonpatch.MergePatchType && mediaType != jsonpatch.JSONPatchType {
+		return ErrUnsupportedPatch
+	}
+
+	if err := checkThrottle(request, req); err != nil {
+		return err
+	}
+	if err := checkAuthorize(request, req); err != nil {
+		return err
+	}
+
+	patch, err := ReadBody(req)
+	if err != nil {
+		return classify(ErrDecode, err)
+	}
+	if patch, err = rewriteBody(req, patch); err != nil {
+		return classify(ErrDecode, err)
+	}
+	document, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+
+	var patched []byte
+	if mediaType == jsonpatch.MergePatchType {
+		patched, err = jsonpatch.MergePatch(document, patch)
+	} else {
+		patched, err = jsonpatch.Apply(document, patch)
+	}
+	if err != nil {
+		return classify(ErrDecode, err)
+	}
+	return validateDecoded(request, patched, req)
+}
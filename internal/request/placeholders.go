@@ -0,0 +1,47 @@
+package request
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/donbarrigon/new-project/lib/ctxkey"
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// WithPlaceholder agrega un valor para los marcadores {name...} de las reglas de los FormRequest del request
+//
+//	req = request.WithPlaceholder(req, "tenant", tenant)
+//	Slug string `json:"slug" rules:"unique:projects,slug,tenant_id:{tenant.id}"`
+func WithPlaceholder(req *http.Request, name string, value any) *http.Request {
+	return req.WithContext(validation.WithPlaceholder(req.Context(), name, value))
+}
+
+// AuthKey llave del contexto con el usuario autenticado
+var AuthKey = ctxkey.New[any]("auth")
+
+// WithAuth guarda el usuario autenticado en el contexto y para los marcadores {auth...}
+// lo usa el middleware AuthPlaceholders, user puede ser un map o un struct
+//
+//	Email string `json:"email" rules:"required|email|unique:users,email,except:{auth.id}"`
+func WithAuth(req *http.Request, user any) *http.Request {
+	req = ctxkey.SetRequest(req, AuthKey, user)
+	return WithPlaceholder(req, "auth", user)
+}
+
+// Auth retorna el usuario autenticado que guardo WithAuth
+func Auth(ctx context.Context) (any, bool) {
+	return ctxkey.Get(ctx, AuthKey)
+}
+
+// AuthID retorna el id del usuario autenticado (campo id del map o del struct), "" si no hay usuario
+func AuthID(ctx context.Context) string {
+	if !ctxkey.Has(ctx, AuthKey) {
+		return ""
+	}
+	id, ok := validation.Placeholder(ctx, "auth.id")
+	if !ok || id == nil {
+		return ""
+	}
+	return fmt.Sprint(id)
+}
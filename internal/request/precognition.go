@@ -0,0 +1,54 @@
+package request
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/donbarrigon/new-project/lib/ctxkey"
+)
+
+// Precognition (el protocolo de laravel precognition) valida en vivo los campos que el usuario esta editando
+// con las mismas reglas del servidor, el cliente envia el formulario con las cabeceras
+//
+//	Precognition: true
+//	Precognition-Validate-Only: email,address.city
+//
+// y solo se ejecutan las reglas de esos campos (sin la cabecera de campos se valida todo).
+// Una solicitud precognitiva es de solo validacion: middleware.DryRun responde 204 con Precognition-Success: true
+// o 422 con los errores, sin llegar al controlador. las cabeceras solo cuentan cuando middleware.DryRun marco
+// la solicitud con WithPrecognition: en una ruta sin el middleware se valida todo el FormRequest
+
+const (
+	// PrecognitionHeader cabecera que marca la solicitud como precognitiva
+	PrecognitionHeader = "Precognition"
+	// PrecognitionFieldsHeader cabecera con los campos a validar separados por comas
+	PrecognitionFieldsHeader = "Precognition-Validate-Only"
+	// PrecognitionSuccessHeader cabecera de la respuesta cuando los campos son validos
+	PrecognitionSuccessHeader = "Precognition-Success"
+)
+
+// IsPrecognitive indica si la solicitud es precognitiva
+func IsPrecognitive(req *http.Request) bool {
+	return strings.EqualFold(req.Header.Get(PrecognitionHeader), "true")
+}
+
+// precognitionKey campos de la solicitud precognitiva que atiende middleware.DryRun
+var precognitionKey = ctxkey.New[[]string]("precognition_fields")
+
+// WithPrecognition guarda en el contexto los campos que pidio validar el cliente (Precognition-Validate-Only)
+// lo hace middleware.DryRun con las solicitudes precognitivas, sin la cabecera de campos se valida todo
+func WithPrecognition(req *http.Request) *http.Request {
+	var fields []string
+	for _, f := range strings.Split(req.Header.Get(PrecognitionFieldsHeader), ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, f)
+		}
+	}
+	return ctxkey.SetRequest(req, precognitionKey, fields)
+}
+
+// PrecognitionFields retorna los campos a validar de la solicitud marcada con WithPrecognition
+// nil si no esta marcada o si el cliente pidio todos, las cabeceras solas no cuentan
+func PrecognitionFields(req *http.Request) []string {
+	return ctxkey.Value(req.Context(), precognitionKey)
+}
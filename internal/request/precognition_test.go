@@ -0,0 +1,40 @@
+package request
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type precognitionForm struct {
+	hooks
+	Name  string `json:"name" rules:"required"`
+	Email string `json:"email" rules:"required|email"`
+}
+
+func TestPrecognitionIgnoresClientHeadersWithoutMiddleware(t *testing.T) {
+	newReq := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"name":"ana","email":"no-es-correo"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(PrecognitionHeader, "true")
+		req.Header.Set(PrecognitionFieldsHeader, "name")
+		return req
+	}
+
+	req := newReq()
+	if fields := PrecognitionFields(req); fields != nil {
+		t.Errorf("sin el middleware no debe haber campos precognitivos, se obtuvo %v", fields)
+	}
+	if err := Validate(&precognitionForm{}, req); err == nil {
+		t.Errorf("una ruta normal debe validar todos los campos aunque el cliente envie %s", PrecognitionFieldsHeader)
+	}
+
+	req = WithPrecognition(newReq())
+	if fields := PrecognitionFields(req); len(fields) != 1 || fields[0] != "name" {
+		t.Errorf("se esperaba [name], se obtuvo %v", fields)
+	}
+	if err := Validate(&precognitionForm{}, req); err != nil {
+		t.Errorf("la solicitud marcada solo debe validar name, se obtuvo %v", err)
+	}
+}
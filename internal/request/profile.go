@@ -0,0 +1,197 @@
+package request
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/donbarrigon/new-project/lib/ctxkey"
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// Perfil de las solicitudes lentas: el middleware SlowRequests mide cada fase de Validate (leer el body,
+// deserializar, cada hook del FormRequest, las reglas de cada campo) y el controlador, y si la solicitud
+// supera el umbral avisa a OnSlowRequest con el detalle. las fases propias del controlador se miden con Span
+//
+//	func Export(ctx *controller.Context) {
+//		defer request.Span(ctx.Request.Context(), "generar csv")()
+//	}
+
+// Phase tiempo de una fase de la solicitud
+type Phase struct {
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration"`
+}
+
+// SlowRequestReport detalle de una solicitud que supero el umbral
+type SlowRequestReport struct {
+	RequestID string        `json:"request_id,omitempty"`
+	Request   string        `json:"request,omitempty"` // tipo del FormRequest
+	Method    string        `json:"method"`
+	Path      string        `json:"path"`
+	Threshold time.Duration `json:"threshold"`
+	Total     time.Duration `json:"total"`
+	Phases    []Phase       `json:"phases"` // fases en orden, la suma es el total
+	Rules     []Phase       `json:"rules"`  // tiempo de las reglas de cada campo, de mayor a menor
+}
+
+// slowRequestHooks funciones que reciben el detalle de las solicitudes lentas
+var slowRequestHooks []func(req *http.Request, report SlowRequestReport)
+
+// OnSlowRequest registra una funcion que recibe el detalle de cada solicitud que supero el umbral
+// sin hooks el detalle se escribe en el log. se debe llamar al iniciar la aplicacion
+//
+//	request.OnSlowRequest(func(req *http.Request, r request.SlowRequestReport) {
+//		logger.Warn("slow_request", "path", r.Path, "total", r.Total, "phases", r.Phases)
+//	})
+func OnSlowRequest(hook func(req *http.Request, report SlowRequestReport)) {
+	slowRequestHooks = append(slowRequestHooks, hook)
+}
+
+// ProfileKey llave del contexto con el perfil de la solicitud
+var ProfileKey = ctxkey.New[*Profile]("profile")
+
+// Profile guarda las fases de una solicitud, lo crea el middleware SlowRequests
+// cada marca cierra la fase que empezo en la marca anterior
+type Profile struct {
+	mu      sync.Mutex
+	start   time.Time
+	last    time.Time
+	request string
+	phases  []Phase
+	rules   map[string]time.Duration
+}
+
+// NewProfile empieza a medir la solicitud
+func NewProfile() *Profile {
+	now := time.Now()
+	return &Profile{start: now, last: now}
+}
+
+// profileOf retorna el perfil de la solicitud, nil si no se esta midiendo
+func profileOf(req *http.Request) *Profile {
+	p, _ := ctxkey.Get(req.Context(), ProfileKey)
+	return p
+}
+
+// mark cierra la fase name con el tiempo desde la marca anterior, con p nil no hace nada
+func (p *Profile) mark(name string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	now := time.Now()
+	p.phases = append(p.phases, Phase{Name: name, Duration: now.Sub(p.last)})
+	p.last = now
+	p.mu.Unlock()
+}
+
+// setRequest guarda el tipo del FormRequest que se valida, con p nil no hace nada
+func (p *Profile) setRequest(request FormRequest) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.request = requestName(request)
+	p.mu.Unlock()
+}
+
+// rule suma el tiempo de las reglas de un campo, lo llama validation.WithFieldTimer
+func (p *Profile) rule(field string, elapsed time.Duration) {
+	p.mu.Lock()
+	if p.rules == nil {
+		p.rules = map[string]time.Duration{}
+	}
+	p.rules[field] += elapsed
+	p.mu.Unlock()
+}
+
+// withRuleTimer agrega al contexto de las reglas la medicion por campo si la solicitud tiene perfil
+func withRuleTimer(ctx context.Context, req *http.Request) context.Context {
+	if p := profileOf(req); p != nil {
+		return validation.WithFieldTimer(ctx, p.rule)
+	}
+	return ctx
+}
+
+// Span mide una fase del controlador, se llama con defer. la fase anterior (lo que paso desde la ultima
+// marca) queda como "handler"
+//
+//	defer request.Span(ctx.Request.Context(), "consultar inventario")()
+func Span(ctx context.Context, name string) func() {
+	p, ok := ctxkey.Get(ctx, ProfileKey)
+	if !ok {
+		return func() {}
+	}
+	p.mark("handler")
+	return func() { p.mark(name) }
+}
+
+// Finish cierra la ultima fase como "handler" y avisa a OnSlowRequest si la solicitud tardo mas que threshold
+func (p *Profile) Finish(req *http.Request, threshold time.Duration) {
+	p.mark("handler")
+	p.mu.Lock()
+	total := p.last.Sub(p.start)
+	if total < threshold {
+		p.mu.Unlock()
+		return
+	}
+	report := SlowRequestReport{
+		RequestID: RequestID(req.Context()),
+		Request:   p.request,
+		Method:    req.Method,
+		Path:      req.URL.Path,
+		Threshold: threshold,
+		Total:     total,
+		Phases:    mergePhases(p.phases),
+	}
+	for field, elapsed := range p.rules {
+		report.Rules = append(report.Rules, Phase{Name: field, Duration: elapsed})
+	}
+	p.mu.Unlock()
+	sort.Slice(report.Rules, func(i, j int) bool { return report.Rules[i].Duration > report.Rules[j].Duration })
+	emitSlowRequest(req, report)
+}
+
+// mergePhases junta las fases seguidas con el mismo nombre (varios Span en el controlador)
+// y quita las que no tardaron nada
+func mergePhases(phases []Phase) []Phase {
+	out := make([]Phase, 0, len(phases))
+	for _, s := range phases {
+		if s.Duration <= 0 {
+			continue
+		}
+		if n := len(out); n > 0 && out[n-1].Name == s.Name {
+			out[n-1].Duration += s.Duration
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// emitSlowRequest avisa a los hooks o escribe el detalle en el log
+func emitSlowRequest(req *http.Request, report SlowRequestReport) {
+	if len(slowRequestHooks) == 0 {
+		var b strings.Builder
+		for _, s := range report.Phases {
+			b.WriteString(" " + s.Name + "=" + s.Duration.String())
+		}
+		for i, s := range report.Rules {
+			if i == 5 {
+				break
+			}
+			b.WriteString(" rule:" + s.Name + "=" + s.Duration.String())
+		}
+		log.Printf("slow_request request_id=%s request=%s method=%s path=%s threshold=%s total=%s%s",
+			report.RequestID, report.Request, report.Method, report.Path, report.Threshold, report.Total, b.String())
+		return
+	}
+	for _, hook := range slowRequestHooks {
+		safeHook("OnSlowRequest", func() { hook(req, report) })
+	}
+}
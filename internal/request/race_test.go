@@ -0,0 +1,6 @@
+//go:build race
+
+package request
+
+// raceEnabled el detector de carreras agrega allocations, TestValidateAllocBudget no se mide con -race
+const raceEnabled = true
@@ -1,11 +1,18 @@
 package request
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
+	"mime"
 	"net/http"
 	"reflect"
+	"time"
+
+	"github.com/donbarrigon/new-project/lib/jsonapi"
+	"github.com/donbarrigon/new-project/lib/validation"
 )
 
 type FormRequest interface {
@@ -13,11 +20,131 @@ type FormRequest interface {
 	WithValidator() error        // se debe implementar, Propósito: Permite añadir lógica adicional después de preparar el validador pero antes de que se realice la validación.
 }
 
+// ParallelRequest lo implementa el FormRequest que quiere validar sus campos en paralelo
+// ValidationWorkers retorna la cantidad maxima de goroutines que se usan para validar
+// util cuando las reglas son costosas (unique en la base de datos, verificaciones remotas)
+//
+//	func (u *User) ValidationWorkers() int { return 4 }
+type ParallelRequest interface {
+	ValidationWorkers() int
+}
+
+// UseNumber si es true los numeros del body se decodifican como json.Number en lugar de float64
+// evita perder precision en enteros grandes y montos de dinero en los campos de tipo any o json.Number
+// y las reglas decimal y max_digits trabajan sobre el numero exacto que envio el cliente
+var UseNumber = false
+
+// NumberRequest lo implementa el FormRequest que quiere decodificar los numeros como json.Number
+// sin tener que activar UseNumber para toda la aplicacion
+//
+//	func (p *Payment) UseNumber() bool { return true }
+type NumberRequest interface {
+	UseNumber() bool
+}
+
+// JSONAPIRequest lo implementa el FormRequest que siempre recibe documentos JSON:API
+// sin implementarla se detecta por el Content-Type application/vnd.api+json
+type JSONAPIRequest interface {
+	JSONAPI() bool
+}
+
+// validatedHooks funciones que se llaman cada vez que un FormRequest pasa la validacion
+var validatedHooks []func(req *http.Request, request FormRequest)
+
+// OnValidated registra una funcion que se llama despues de validar con exito cada FormRequest
+// se debe llamar al iniciar la aplicacion, la usa la auditoria para saber que datos se validaron
+func OnValidated(hook func(req *http.Request, request FormRequest)) {
+	validatedHooks = append(validatedHooks, hook)
+}
+
+// validationHooks funciones que se llaman al terminar cada Validate o ValidatePatch, con exito o con error
+var validationHooks []func(req *http.Request, request FormRequest, err error, elapsed time.Duration)
+
+// OnValidation registra una funcion que se llama al terminar de validar cada FormRequest con el resultado y la duracion
+// se debe llamar al iniciar la aplicacion, la usa la barra de depuracion para mostrar lo que se valido
+func OnValidation(hook func(req *http.Request, request FormRequest, err error, elapsed time.Duration)) {
+	validationHooks = append(validationHooks, hook)
+}
+
+// notifyValidation avisa a los hooks de OnValidation
+func notifyValidation(req *http.Request, request FormRequest, err error, start time.Time) {
+	elapsed := time.Since(start)
+	for _, hook := range validationHooks {
+		safeHook("OnValidation", func() { hook(req, request, err, elapsed) })
+	}
+}
+
+// rawBodyHolder lo implementa Request para que Validate le entregue el body original
+type rawBodyHolder interface {
+	setRawBody(body []byte)
+}
+
+// contextHolder lo implementa Request para que los hooks del FormRequest tengan el contexto del request
+type contextHolder interface {
+	setContext(ctx context.Context)
+}
+
 // Implementación de FormRequest para un struct
+// embebala en sus requests para tener acceso al body original
+//
+//	type StripeWebhook struct {
+//		request.Request
+//		Type string `json:"type" rules:"required"`
+//	}
 type Request struct {
+	ctx          context.Context
+	rawBody      []byte
+	providedBody []byte          // body json (ya aplanado si era JSON:API) para calcular los campos enviados
+	provided     map[string]bool // campos enviados, se calcula la primera vez que se pide
+	warnings     validation.ValidationErrors
+}
+
+// RawBody retorna los bytes exactos que se recibieron en el body
+// sirve para verificar firmas HMAC (webhooks de Stripe, GitHub, etc) que se calculan sobre el body sin modificar
+// no lo modifique, es el mismo slice que se uso para deserializar
+func (r *Request) RawBody() []byte {
+	return r.rawBody
+}
+
+func (r *Request) setRawBody(body []byte) {
+	r.rawBody = body
+}
+
+// Context retorna el contexto del request que se esta validando (usuario, tenant, feature flags)
+// sirve en PrepareForValidation y WithValidator que no reciben el request, antes de Validate es context.Background()
+//
+//	func (r *Checkout) WithValidator() error {
+//		if feature.Enabled(r.Context(), "checkout_v2") { ... }
+//	}
+func (r *Request) Context() context.Context {
+	if r.ctx == nil {
+		return context.Background()
+	}
+	return r.ctx
+}
+
+func (r *Request) setContext(ctx context.Context) {
+	r.ctx = ctx
 }
 
 func Validate(request FormRequest, req *http.Request) error {
+	req, latency, own := trackLatency(request, req)
+	if len(validationHooks) == 0 && latency == nil {
+		return validateRequest(request, req)
+	}
+	start := time.Now()
+	err := validateRequest(request, req)
+	if latency != nil {
+		latency.validated(req, start, own)
+	}
+	notifyValidation(req, request, err, start)
+	return err
+}
+
+// validateRequest ejecuta Validate sin avisar a los hooks de OnValidation
+// los errores salen con su clase (ErrDecode, ErrValidation...) y los panicos como *PanicError
+func validateRequest(request FormRequest, req *http.Request) (err error) {
+	defer recoverPanic(&err)
 
 	// Usar reflect para validar que se trabaja con el tipo especifico y obtener el tipo de request y deserializar en el tipo real
 	requestValue := reflect.ValueOf(request)
@@ -25,35 +152,254 @@ func Validate(request FormRequest, req *http.Request) error {
 		return errors.New("se espera un puntero al tipo que implementa FormRequest")
 	}
 
-	// Leer el cuerpo de la solicitud
-	body, err := io.ReadAll(req.Body)
+	// lo que paso antes de Validate es del controlador o de los middlewares
+	profile := profileOf(req)
+	profile.setRequest(request)
+	profile.mark("handler")
+
+	// Revisar el limite de solicitudes del FormRequest antes de leer el body
+	if err := checkThrottle(request, req); err != nil {
+		return err
+	}
+	profile.mark("throttle")
+	if err := checkAuthorize(request, req); err != nil {
+		return err
+	}
+	profile.mark("authorize")
+
+	// Leer el cuerpo de la solicitud solo si tiene, los GET y DELETE normalmente no tienen
+	// el body queda disponible para volver a leerse despues de validar
+	body, err := ReadBody(req)
 	if err != nil {
+		return classify(ErrDecode, err)
+	}
+	if holder, ok := request.(rawBodyHolder); ok {
+		holder.setRawBody(body)
+	}
+	profile.mark("read_body")
+
+	// Normalizar el body con los BodyRewriter de la ruta (clientes viejos)
+	if body, err = rewriteBody(req, body); err != nil {
+		return classify(ErrDecode, err)
+	}
+	return validateBody(request, body, req)
+}
+
+// validateBody ejecuta todo el proceso de validacion sobre un body ya leido
+// lo usan Validate y ValidateBatch (cada elemento del lote es un body)
+func validateBody(request FormRequest, body []byte, req *http.Request) error {
+
+	// Los documentos JSON:API se aplanan y los errores guardan el pointer de cada campo
+	if isJSONAPI(request, req) && len(bytes.TrimSpace(body)) > 0 {
+		flat, relationships, err := jsonapi.Flatten(body)
+		if err != nil {
+			return classify(ErrDecode, validation.WithCode(CodeInvalidJSON, err))
+		}
+		err = validateDecoded(request, flat, req)
+		var verrs validation.ValidationErrors
+		if errors.As(err, &verrs) {
+			return classify(ErrValidation, &jsonapi.ValidationError{Errors: verrs, Relationships: relationships, Codes: validation.FieldCodes(err)})
+		}
 		return err
 	}
-	defer req.Body.Close()
+	return validateDecoded(request, body, req)
+}
+
+// validateDecoded deserializa el body y ejecuta los hooks y las reglas
+func validateDecoded(request FormRequest, body []byte, req *http.Request) error {
+	profile := profileOf(req)
+
+	// Avisar de los campos obsoletos que todavia envia el cliente, con los nombres que envio
+	checkDeprecated(request, body, req)
+
+	// Cambiar los nombres anteriores de los campos (tag alias) por los actuales
+	body, err := applyAliases(request, body)
+	if err != nil {
+		return classify(ErrDecode, err)
+	}
+	if body, err = coerceWeak(request, body); err != nil {
+		return classify(ErrDecode, err)
+	}
 
-	// Deserializar el JSON en el struct
-	if err := json.Unmarshal(body, request); err != nil {
+	if holder, ok := request.(providedHolder); ok {
+		holder.setProvidedBody(body)
+	}
+
+	// Deserializar el JSON en el struct (o en el slice), un body con solo espacios se trata como vacio
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := checkBodyShape(request, body); err != nil {
+			return classify(ErrDecode, err)
+		}
+		if err := decodeJSON(body, request); err != nil {
+			return classify(ErrDecode, err)
+		}
+		if err := checkSliceLength(request); err != nil {
+			return classify(ErrDecode, err)
+		}
+		setElementsProvided(request, body)
+	}
+
+	// Rechazar los bots (honeypot y tiempo de llenado) antes de las reglas costosas
+	if err := checkSpam(request); err != nil {
 		return err
 	}
 
-	// Preparar el request antes de validar
+	// Llenar los campos que vienen de la url (tags query y path)
+	if err := bindParams(request, req); err != nil {
+		return classify(ErrDecode, err)
+	}
+
+	// Descifrar los campos con el tag encrypted para que las reglas vean el texto plano
+	if err := decryptFields(request); err != nil {
+		return classify(nil, err)
+	}
+
+	// Limpiar el html de los campos con el tag sanitize
+	sanitizeFields(request)
+
+	// Interpretar las fechas en la zona horaria del cliente y dejarlas en UTC
+	if err := resolveDateTimes(request, req); err != nil {
+		return classify(nil, err)
+	}
+
+	markDecoded(req)
+	profile.mark("decode")
+
+	// Preparar el request antes de validar, con el contexto del request para los hooks
+	if holder, ok := request.(contextHolder); ok {
+		holder.setContext(req.Context())
+	}
 	if err := request.PrepareForValidation(); err != nil {
-		return err
+		return classify(ErrHook, err)
 	}
+	profile.mark("PrepareForValidation")
 
 	// Añadir lógica adicional después de preparar el validador
 	if err := request.WithValidator(); err != nil {
-		return err
+		return classify(ErrHook, err)
+	}
+	profile.mark("WithValidator")
+
+	// Validar el request con las reglas de validación del tag rules
+	req, versions := withVersionHolder(request, req)
+	err = staleVersion(request, req, versions, validateRules(request, req))
+	profile.mark("rules")
+	if err != nil {
+		return classify(nil, err)
 	}
 
-	// Validar el request con las reglas de validación
-	// if err := validation.Struct(request, request.Rules()); err != nil {
-	// 	return err
-	// }
+	// Avisar a los interesados (auditoria, metricas) que el request es valido
+	// si solo se esta validando no hay nada que registrar
+	if len(validatedHooks) > 0 && !IsDryRun(req) {
+		for _, hook := range validatedHooks {
+			safeHook("OnValidated", func() { hook(req, request) })
+		}
+		profile.mark("OnValidated")
+	}
 
 	// Si no hay errores, parsear los parámetros de la URL
 	//r.ParseQuery(req)
 
 	return nil
 }
+
+// validateRules ejecuta las reglas en paralelo si el request lo pide o en orden si no,
+// en las solicitudes precognitivas solo se validan los campos que pidio el cliente
+func validateRules(request FormRequest, req *http.Request) error {
+	ctx := req.Context()
+	if fields := PrecognitionFields(req); fields != nil {
+		ctx = validation.WithOnly(ctx, fields...)
+	}
+	ctx = withRulesFor(ctx, request, req)
+	ctx = withTimezone(ctx, request, req)
+	ctx = withRuleTimer(ctx, req)
+	// las advertencias solo se recogen si el FormRequest tiene donde guardarlas y reglas warn
+	holder, ok := request.(warningsHolder)
+	if ok && (validation.HasWarnings(request) || validation.HasSkipBreakers()) {
+		ctx = validation.WithWarnings(ctx)
+		defer func() { holder.setWarnings(validation.Warnings(ctx)) }()
+	}
+	if isSliceRequest(request) {
+		return validation.SliceContext(ctx, request)
+	}
+	if p, ok := request.(ParallelRequest); ok {
+		if workers := p.ValidationWorkers(); workers > 1 {
+			return validation.StructParallel(ctx, request, workers)
+		}
+	}
+	return validation.StructContext(ctx, request)
+}
+
+// hasBody indica si vale la pena leer el body
+// Content-Length 0 o sin body no se lee, si es -1 (chunked) no se sabe y toca leerlo
+func hasBody(req *http.Request) bool {
+	if req.Body == nil || req.Body == http.NoBody {
+		return false
+	}
+	return req.ContentLength != 0
+}
+
+// Warmup prepara por adelantado todo lo que Validate necesita de cada FormRequest
+// (reglas, metadata de los structs y campos de la url) para que el primer request no pague ese costo
+// se llama al iniciar la aplicacion, retorna error si algun request usa una regla que no existe
+//
+//	if err := request.Warmup(&request.User{}); err != nil {
+//		log.Fatal(err)
+//	}
+func Warmup(requests ...FormRequest) error {
+	values := make([]any, 0, len(requests))
+	for _, r := range requests {
+		t := reflect.TypeOf(r)
+		if t == nil || t.Kind() != reflect.Ptr {
+			return errors.New("warmup: se espera un puntero a un struct (o a un slice de structs) que implementa FormRequest")
+		}
+		st, ok := structType(t)
+		if !ok {
+			return errors.New("warmup: se espera un puntero a un struct (o a un slice de structs) que implementa FormRequest")
+		}
+		getBindFields(st)
+		getDateTimeFields(st)
+		getTagFields(st, "encrypted")
+		getTagFields(st, "sanitize")
+		getDeprecatedFields(st)
+		getAliasFields(st)
+		hasWeakFields(st)
+		values = append(values, r)
+	}
+	return validation.Warmup(values...)
+}
+
+// decodeJSON deserializa el body en el request
+// si se pidio json.Number se usa un Decoder porque json.Unmarshal no tiene esa opcion
+func decodeJSON(body []byte, request FormRequest) error {
+	useNumber := UseNumber
+	if n, ok := request.(NumberRequest); ok {
+		useNumber = n.UseNumber()
+	}
+	if !useNumber {
+		if err := json.Unmarshal(body, request); err != nil {
+			return decodeError(body, err)
+		}
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(request); err != nil {
+		return decodeError(body, err)
+	}
+	// igual que json.Unmarshal no se permite basura despues del json
+	if _, err := decoder.Token(); err != io.EOF {
+		return validation.WithCode(CodeInvalidJSON, errors.New("el body tiene datos despues del json"))
+	}
+	return nil
+}
+
+// isJSONAPI indica si el body es un documento JSON:API
+func isJSONAPI(request FormRequest, req *http.Request) bool {
+	if j, ok := request.(JSONAPIRequest); ok {
+		return j.JSONAPI()
+	}
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	return mediaType == jsonapi.MediaType
+}
@@ -0,0 +1,246 @@
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// allocBudget cantidad maxima de allocations por llamada a Validate en cada escenario
+// si un cambio en el decode o en la validacion los supera el test falla
+// si se mejora el rendimiento baje los valores para que no se pierda la mejora
+var allocBudget = map[string]float64{
+	"small":      14,
+	"large":      640,
+	"deep":       26,
+	"many_rules": 42,
+}
+
+// hooks implementacion vacia de FormRequest para los structs de prueba
+type hooks struct{}
+
+func (hooks) PrepareForValidation() error { return nil }
+func (hooks) WithValidator() error        { return nil }
+
+type smallRequest struct {
+	hooks
+	Name  string `json:"name" rules:"required|min:3|max:10"`
+	Email string `json:"email" rules:"required|email"`
+	Age   int    `json:"age" rules:"min:18"`
+}
+
+type largeItem struct {
+	Sku   string  `json:"sku"`
+	Name  string  `json:"name"`
+	Qty   int     `json:"qty"`
+	Price float64 `json:"price"`
+}
+
+type largeRequest struct {
+	hooks
+	Customer string      `json:"customer" rules:"required|min:3"`
+	Email    string      `json:"email" rules:"required|email"`
+	Items    []largeItem `json:"items"`
+}
+
+type deepLevel5 struct {
+	Value string `json:"value" rules:"required|min:3"`
+}
+
+type deepLevel4 struct {
+	Name  string     `json:"name" rules:"required"`
+	Child deepLevel5 `json:"child"`
+}
+
+type deepLevel3 struct {
+	Name  string     `json:"name" rules:"required"`
+	Child deepLevel4 `json:"child"`
+}
+
+type deepLevel2 struct {
+	Name  string      `json:"name" rules:"required"`
+	Child *deepLevel3 `json:"child"`
+}
+
+type deepRequest struct {
+	hooks
+	Name  string     `json:"name" rules:"required"`
+	Child deepLevel2 `json:"child"`
+}
+
+type manyRulesRequest struct {
+	hooks
+	Field01 string  `json:"field01" rules:"required|min:2|max:50"`
+	Field02 string  `json:"field02" rules:"required|min:2|max:50"`
+	Field03 string  `json:"field03" rules:"required|min:2|max:50"`
+	Field04 string  `json:"field04" rules:"required|min:2|max:50"`
+	Field05 string  `json:"field05" rules:"required|min:2|max:50"`
+	Field06 string  `json:"field06" rules:"required|min:2|max:50"`
+	Field07 string  `json:"field07" rules:"required|min:2|max:50"`
+	Field08 string  `json:"field08" rules:"required|min:2|max:50"`
+	Field09 string  `json:"field09" rules:"required|min:2|max:50"`
+	Field10 string  `json:"field10" rules:"required|min:2|max:50"`
+	Email1  string  `json:"email1" rules:"required|email|max:100"`
+	Email2  string  `json:"email2" rules:"required|email|max:100"`
+	Number1 int     `json:"number1" rules:"required|min:1|max:1000"`
+	Number2 int     `json:"number2" rules:"required|min:1|max:1000"`
+	Number3 float64 `json:"number3" rules:"required|min:1|max:1000"`
+	Number4 float64 `json:"number4" rules:"required|min:1|max:1000"`
+}
+
+var (
+	smallBody = []byte(`{"name":"Juan","email":"juan@example.com","age":30}`)
+	deepBody  = []byte(`{"name":"a","child":{"name":"b","child":{"name":"c","child":{"name":"d","child":{"value":"abcdef"}}}}}`)
+	largeBody = buildLargeBody(500)
+	manyBody  = buildManyRulesBody()
+)
+
+func buildLargeBody(n int) []byte {
+	items := make([]largeItem, n)
+	for i := range items {
+		items[i] = largeItem{Sku: fmt.Sprintf("SKU-%05d", i), Name: "Producto de prueba", Qty: i + 1, Price: 19.99}
+	}
+	body, _ := json.Marshal(map[string]any{
+		"customer": "Cliente de prueba",
+		"email":    "cliente@example.com",
+		"items":    items,
+	})
+	return body
+}
+
+func buildManyRulesBody() []byte {
+	data := map[string]any{
+		"email1":  "uno@example.com",
+		"email2":  "dos@example.com",
+		"number1": 10,
+		"number2": 20,
+		"number3": 30.5,
+		"number4": 40.5,
+	}
+	for i := 1; i <= 10; i++ {
+		data[fmt.Sprintf("field%02d", i)] = strings.Repeat("x", i+2)
+	}
+	body, _ := json.Marshal(data)
+	return body
+}
+
+// scenarios escenarios compartidos por los benchmarks y el test de allocations
+var scenarios = []struct {
+	name string
+	body []byte
+	form func() FormRequest
+}{
+	{"small", smallBody, func() FormRequest { return &smallRequest{} }},
+	{"large", largeBody, func() FormRequest { return &largeRequest{} }},
+	{"deep", deepBody, func() FormRequest { return &deepRequest{} }},
+	{"many_rules", manyBody, func() FormRequest { return &manyRulesRequest{} }},
+}
+
+// newBenchRequest crea el request una sola vez y en cada llamada solo se reemplaza el body
+// asi se mide Validate y no la creacion del *http.Request
+func newBenchRequest() *http.Request {
+	return httptest.NewRequest(http.MethodPost, "/", nil)
+}
+
+func runValidate(req *http.Request, body []byte, form FormRequest) error {
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	return Validate(form, req)
+}
+
+func TestScenariosAreValid(t *testing.T) {
+	for _, sc := range scenarios {
+		if err := runValidate(newBenchRequest(), sc.body, sc.form()); err != nil {
+			t.Errorf("%s: se esperaba un request valido: %v", sc.name, err)
+		}
+	}
+}
+
+func TestValidateAllocBudget(t *testing.T) {
+	if raceEnabled {
+		t.Skip("el detector de carreras cambia las allocations")
+	}
+	for _, sc := range scenarios {
+		req := newBenchRequest()
+		// se calienta el cache de reglas y metadata antes de medir
+		if err := runValidate(req, sc.body, sc.form()); err != nil {
+			t.Fatalf("%s: %v", sc.name, err)
+		}
+		allocs := testing.AllocsPerRun(100, func() {
+			_ = runValidate(req, sc.body, sc.form())
+		})
+		if budget := allocBudget[sc.name]; allocs > budget {
+			t.Errorf("%s: %.0f allocations por llamada superan el presupuesto de %.0f", sc.name, allocs, budget)
+		}
+	}
+}
+
+func BenchmarkValidate(b *testing.B) {
+	for _, sc := range scenarios {
+		b.Run(sc.name, func(b *testing.B) {
+			req := newBenchRequest()
+			b.ReportAllocs()
+			b.SetBytes(int64(len(sc.body)))
+			for i := 0; i < b.N; i++ {
+				if err := runValidate(req, sc.body, sc.form()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+type numberRequest struct {
+	hooks
+	Amount json.Number `json:"amount"`
+}
+
+func (*numberRequest) UseNumber() bool { return true }
+
+func TestDecodeJSONTrailingData(t *testing.T) {
+	cases := []struct {
+		body string
+		ok   bool
+	}{
+		{`{"amount":1}`, true},
+		{"{\"amount\":1}\n  ", true},
+		{`{"amount":1}}`, false},
+		{`{"amount":1}]`, false},
+		{`{"amount":1} {"amount":2}`, false},
+		{`{"amount":1} x`, false},
+	}
+	for _, c := range cases {
+		for _, form := range []FormRequest{&numberRequest{}, &smallRequest{}} {
+			err := decodeJSON([]byte(c.body), form)
+			if (err == nil) != c.ok {
+				t.Errorf("%T %q: se obtuvo %v, se esperaba ok=%v", form, c.body, err, c.ok)
+			}
+		}
+	}
+}
+
+type panicHookForm struct {
+	hooks
+	Name string `json:"name" rules:"required"`
+}
+
+func init() {
+	OnValidated(func(r *http.Request, fr FormRequest) {
+		if _, ok := fr.(*panicHookForm); ok {
+			panic("hook roto")
+		}
+	})
+}
+
+func TestOnValidatedPanicDoesNotFailValidate(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"name":"ana"}`))
+	req.Header.Set("Content-Type", "application/json")
+	if err := Validate(&panicHookForm{}, req); err != nil {
+		t.Errorf("el panico de un hook no debe cambiar el resultado, se obtuvo %v", err)
+	}
+}
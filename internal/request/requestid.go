@@ -0,0 +1,25 @@
+package request
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/donbarrigon/new-project/lib/ctxkey"
+)
+
+// RequestIDHeader cabecera con el id de la solicitud, se respeta la que envia el proxy o el cliente
+var RequestIDHeader = "X-Request-Id"
+
+// RequestIDKey llave del contexto con el id de la solicitud que asigna el middleware RequestID
+var RequestIDKey = ctxkey.New[string]("request_id")
+
+// WithRequestID guarda el id de la solicitud en el contexto
+func WithRequestID(req *http.Request, id string) *http.Request {
+	return ctxkey.SetRequest(req, RequestIDKey, id)
+}
+
+// RequestID retorna el id de la solicitud, "" si el middleware RequestID no se uso
+// sirve para los logs, la auditoria y las respuestas de error
+func RequestID(ctx context.Context) string {
+	return ctxkey.Value(ctx, RequestIDKey)
+}
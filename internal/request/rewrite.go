@@ -0,0 +1,114 @@
+package request
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// BodyRewriter transforma el body antes de deserializarlo en el FormRequest
+// sirve para normalizar los payloads de clientes viejos sin tocar los handlers ni los FormRequest
+type BodyRewriter func(body []byte) ([]byte, error)
+
+// rewritersKey llave del contexto donde se guardan los BodyRewriter de la ruta
+type rewritersKey struct{}
+
+// WithBodyRewriter agrega los BodyRewriter al request, Validate, ValidateBatch y ValidatePatch
+// los aplican en orden sobre el body leido. Normalmente se usa con el middleware RewriteBody en la ruta
+// RawBody sigue retornando el body original para verificar firmas
+func WithBodyRewriter(req *http.Request, rewriters ...BodyRewriter) *http.Request {
+	if len(rewriters) == 0 {
+		return req
+	}
+	current, _ := req.Context().Value(rewritersKey{}).([]BodyRewriter)
+	all := make([]BodyRewriter, 0, len(current)+len(rewriters))
+	all = append(append(all, current...), rewriters...)
+	return req.WithContext(context.WithValue(req.Context(), rewritersKey{}, all))
+}
+
+// rewriteBody aplica los BodyRewriter del request, el body vacio no se transforma
+func rewriteBody(req *http.Request, body []byte) ([]byte, error) {
+	rewriters, _ := req.Context().Value(rewritersKey{}).([]BodyRewriter)
+	if len(rewriters) == 0 || len(bytes.TrimSpace(body)) == 0 {
+		return body, nil
+	}
+	// los rewriters no deben modificar el body original, es el que retorna RawBody
+	body = bytes.Clone(body)
+	for _, rw := range rewriters {
+		var err error
+		if body, err = rw(body); err != nil {
+			return nil, err
+		}
+	}
+	return body, nil
+}
+
+// UnwrapEnvelope saca el objeto que viene dentro de una llave ({"data": {...}} -> {...})
+// si el body no tiene la llave se deja igual, asi los clientes nuevos pueden enviar el objeto directo
+//
+//	middleware.RewriteBody(request.UnwrapEnvelope("data"))
+func UnwrapEnvelope(key string) BodyRewriter {
+	return func(body []byte) ([]byte, error) {
+		var envelope map[string]json.RawMessage
+		if json.Unmarshal(body, &envelope) != nil {
+			return body, nil
+		}
+		if inner, ok := envelope[key]; ok {
+			return inner, nil
+		}
+		return body, nil
+	}
+}
+
+// StringBooleans convierte los campos que llegan como "true", "false", "1" o "0" en booleanos json
+// solo revisa los campos indicados del primer nivel (o de cada elemento si el body es un array)
+//
+//	middleware.RewriteBody(request.StringBooleans("active", "newsletter"))
+func StringBooleans(fields ...string) BodyRewriter {
+	return func(body []byte) ([]byte, error) {
+		var value any
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err != nil {
+			// el error de sintaxis lo reporta la deserializacion normal
+			return body, nil
+		}
+		changed := false
+		convert := func(obj map[string]any) {
+			for _, field := range fields {
+				s, ok := obj[field].(string)
+				if !ok {
+					continue
+				}
+				switch s {
+				case "true", "1":
+					obj[field], changed = true, true
+				case "false", "0":
+					obj[field], changed = false, true
+				}
+			}
+		}
+		switch v := value.(type) {
+		case map[string]any:
+			convert(v)
+		case []any:
+			for _, item := range v {
+				if obj, ok := item.(map[string]any); ok {
+					convert(obj)
+				}
+			}
+		}
+		if !changed {
+			return body, nil
+		}
+		out, err := json.Marshal(value)
+		if err != nil {
+			return nil, validation.WithCode(CodeRewriteFailed, fmt.Errorf("no se pudo reescribir el body: %w", err))
+		}
+		return out, nil
+	}
+}
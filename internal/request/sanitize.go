@@ -0,0 +1,39 @@
+package request
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/donbarrigon/new-project/lib/sanitize"
+)
+
+// sanitizeFields limpia el html de los campos con el tag sanitize antes de validar
+// el valor del tag son las politicas o etiquetas permitidas igual que en la regla safe_html,
+// sin valor se quita todo el html
+//
+//	type CreatePost struct {
+//		Title string `json:"title" rules:"required|max:120" sanitize:""`
+//		Body  string `json:"body" rules:"required" sanitize:"links,h2,h3"`
+//	}
+func sanitizeFields(request FormRequest) {
+	eachTarget(request, func(rv reflect.Value, prefix string) error {
+		for _, f := range getTagFields(rv.Type(), "sanitize") {
+			fv, err := rv.FieldByIndexErr(f.index)
+			if err != nil {
+				continue
+			}
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			policy := sanitize.Strict
+			if f.key != "" {
+				policy = sanitize.Combine(strings.Split(f.key, ",")...)
+			}
+			fv.SetString(sanitize.HTML(policy, fv.String()))
+		}
+		return nil
+	})
+}
@@ -0,0 +1,53 @@
+package request
+
+import (
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// OptionsRequest lo implementa el FormRequest con opciones que no se conocen al compilar (categorias de la base de datos)
+// las llaves son los nombres de los campos en notacion de puntos, reemplazan las del tag options
+//
+//	func (p *CreateProduct) FieldOptions() map[string][]validation.Option {
+//		return map[string][]validation.Option{"category_id": categoryOptions()}
+//	}
+type OptionsRequest interface {
+	FieldOptions() map[string][]validation.Option
+}
+
+// Schema describe los campos del FormRequest para que un panel de administracion construya el formulario
+// (ver validation.Schema), se puede exponer en una ruta junto al endpoint que recibe el formulario
+//
+//	{Path: "/products/schema", Methods: AllowMethods(GET), Handler: func(ctx *controller.Context) {
+//		fields, err := request.Schema(&CreateProduct{})
+//		...
+//		ctx.ResponseJSON(http.StatusOK, fields)
+//	}}
+func Schema(request FormRequest) ([]validation.FieldSchema, error) {
+	fields, err := validation.Schema(request)
+	if err != nil {
+		return nil, err
+	}
+	if o, ok := request.(OptionsRequest); ok {
+		setOptions(fields, "", o.FieldOptions())
+	}
+	return fields, nil
+}
+
+// setOptions reemplaza las opciones de los campos, prefix es el nombre del objeto padre
+func setOptions(fields []validation.FieldSchema, prefix string, options map[string][]validation.Option) {
+	for i := range fields {
+		name := fields[i].Name
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		if opts, ok := options[name]; ok {
+			fields[i].Options = opts
+			if fields[i].Input == "text" || fields[i].Input == "number" {
+				fields[i].Input = "select"
+			}
+		}
+		if fields[i].Type == "object" {
+			setOptions(fields[i].Fields, name, options)
+		}
+	}
+}
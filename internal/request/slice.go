@@ -0,0 +1,126 @@
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// Los FormRequest pueden ser un slice de structs para los endpoints de creacion masiva
+// el body debe ser un array json y cada elemento se valida con las reglas de su struct (*.campo),
+// los errores se nombran con el indice del elemento (0.email)
+//
+//	type CreateUsers []User
+//
+//	func (u *CreateUsers) PrepareForValidation() error { return nil }
+//	func (u *CreateUsers) WithValidator() error        { return nil }
+//
+//	var users request.CreateUsers
+//	if err := request.Validate(&users, ctx.Request); err != nil { ... }
+//
+// a diferencia de Batch es todo o nada: si un elemento es invalido el request completo es invalido
+// los tags query y path no aplican a los elementos, la cantidad maxima de elementos es MaxBatchItems
+// los elementos que embeben Request saben que campos envio el cliente en su objeto (Provided, sometimes)
+
+// eachTarget llama fn con el struct del FormRequest o con cada elemento si es un slice
+// prefix es el indice del elemento para los nombres de los errores ("0."), los elementos nulos se omiten
+func eachTarget(request FormRequest, fn func(rv reflect.Value, prefix string) error) error {
+	rv := reflect.ValueOf(request).Elem()
+	switch rv.Kind() {
+	case reflect.Struct:
+		return fn(rv, "")
+	case reflect.Slice:
+		for i := 0; i < rv.Len(); i++ {
+			ev := rv.Index(i)
+			if ev.Kind() == reflect.Ptr {
+				if ev.IsNil() {
+					continue
+				}
+				ev = ev.Elem()
+			}
+			if ev.Kind() != reflect.Struct {
+				continue
+			}
+			if err := fn(ev, strconv.Itoa(i)+"."); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isSliceRequest indica si el FormRequest es un slice
+func isSliceRequest(request FormRequest) bool {
+	return reflect.ValueOf(request).Elem().Kind() == reflect.Slice
+}
+
+// structType retorna el struct del FormRequest o el de sus elementos si es un slice
+func structType(t reflect.Type) (reflect.Type, bool) {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t, t.Kind() == reflect.Struct
+}
+
+// checkBodyShape verifica que el body sea un array si el FormRequest es un slice y un objeto si no
+// asi el cliente recibe un error claro y no el de encoding/json
+func checkBodyShape(request FormRequest, body []byte) error {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 || bytes.Equal(body, []byte("null")) {
+		return nil
+	}
+	if isSliceRequest(request) {
+		if body[0] != '[' {
+			return validation.WithCode(CodeBodyShape, errors.New("el body debe ser un array de elementos"))
+		}
+		return nil
+	}
+	if body[0] == '[' && reflect.ValueOf(request).Elem().Kind() == reflect.Struct {
+		return validation.WithCode(CodeBodyShape, errors.New("el body debe ser un objeto json, no un array"))
+	}
+	return nil
+}
+
+var providedHolderType = reflect.TypeOf((*providedHolder)(nil)).Elem()
+
+// setElementsProvided entrega a cada elemento que embebe Request su objeto del array
+// asi Provided y la regla sometimes funcionan por elemento como en un FormRequest solo
+func setElementsProvided(request FormRequest, body []byte) {
+	rv := reflect.ValueOf(request).Elem()
+	if rv.Kind() != reflect.Slice || rv.Len() == 0 {
+		return
+	}
+	elemType := rv.Type().Elem()
+	if elemType.Kind() != reflect.Ptr {
+		elemType = reflect.PointerTo(elemType)
+	}
+	if !elemType.Implements(providedHolderType) {
+		return
+	}
+	var raws []json.RawMessage
+	if json.Unmarshal(body, &raws) != nil {
+		return
+	}
+	for i := 0; i < rv.Len() && i < len(raws); i++ {
+		ev := rv.Index(i)
+		if ev.Kind() != reflect.Ptr {
+			ev = ev.Addr()
+		} else if ev.IsNil() {
+			continue
+		}
+		ev.Interface().(providedHolder).setProvidedBody(raws[i])
+	}
+}
+
+// checkSliceLength limita la cantidad de elementos de un FormRequest slice
+func checkSliceLength(request FormRequest) error {
+	if rv := reflect.ValueOf(request).Elem(); rv.Kind() == reflect.Slice && rv.Len() > MaxBatchItems {
+		return validation.WithCode(CodeTooManyItems, fmt.Errorf("el body no puede tener más de %d elementos", MaxBatchItems))
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package request
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+type patchItem struct {
+	Request
+	ID   int    `json:"id" rules:"required"`
+	Name string `json:"name" rules:"sometimes|required|min:3"`
+}
+
+type patchItems []patchItem
+
+func (p *patchItems) PrepareForValidation() error { return nil }
+func (p *patchItems) WithValidator() error        { return nil }
+
+func TestSliceElementsUsePresence(t *testing.T) {
+	cases := []struct {
+		name   string
+		body   string
+		errors []string
+	}{
+		{"sin name", `[{"id":1},{"id":2}]`, nil},
+		{"name valido", `[{"id":1,"name":"ana"}]`, nil},
+		{"name corto", `[{"id":1},{"id":2,"name":"x"}]`, []string{"1.name"}},
+		{"name vacio", `[{"id":1,"name":""}]`, []string{"0.name"}},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodPatch, "/items", strings.NewReader(c.body))
+		req.Header.Set("Content-Type", "application/json")
+		var items patchItems
+		err := Validate(&items, req)
+
+		var verrs validation.ValidationErrors
+		if len(c.errors) == 0 {
+			if err != nil {
+				t.Errorf("%s: no se esperaban errores, se obtuvo %v", c.name, err)
+			}
+			continue
+		}
+		if !errors.As(err, &verrs) {
+			t.Fatalf("%s: se esperaban errores de validacion, se obtuvo %v", c.name, err)
+		}
+		for _, field := range c.errors {
+			if !verrs.Has(field) {
+				t.Errorf("%s: se esperaba error en %s, se obtuvo %v", c.name, field, verrs)
+			}
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPatch, "/items", strings.NewReader(`[{"id":1},{"id":2,"name":"eva"}]`))
+	req.Header.Set("Content-Type", "application/json")
+	var items patchItems
+	if err := Validate(&items, req); err != nil {
+		t.Fatal(err)
+	}
+	if items[0].Provided("name") || !items[1].Provided("name") {
+		t.Errorf("Provided debe responder por elemento: %v %v", items[0].ProvidedFields(), items[1].ProvidedFields())
+	}
+}
@@ -0,0 +1,102 @@
+package request
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/donbarrigon/new-project/lib/crypt"
+)
+
+// Proteccion contra spam para formularios publicos (contacto, registro, comentarios) con el tag spam
+// se revisa apenas se deserializa el body, antes de las reglas que consultan la base de datos
+//
+//	type ContactForm struct {
+//		Name     string `json:"name" rules:"required"`
+//		Website  string `json:"website" spam:"honeypot"`       // campo oculto con css, los bots lo llenan
+//		RenderAt string `json:"rendered_at" spam:"timestamp:3s"` // request.FormTimestamp() al mostrar el formulario
+//	}
+//
+// honeypot: el campo debe llegar vacio. timestamp:min[,max]: el campo debe tener el token firmado
+// de FormTimestamp y el formulario se debe enviar despues de min (una persona no lo llena en 1 segundo)
+// y antes de max (2h si no se indica). El error no dice que fallo para no darle pistas al bot
+
+// ErrSpam se usa con errors.Is para saber si la solicitud se rechazo como spam
+var ErrSpam = errors.New("la solicitud fue rechazada")
+
+// FormTimestampMaxAge tiempo maximo por defecto entre que se muestra el formulario y se envia
+var FormTimestampMaxAge = 2 * time.Hour
+
+// FormTimestamp genera el token con la hora actual para el campo spam:"timestamp" del formulario
+// el token va cifrado con crypt.Default, el cliente no lo puede falsificar
+func FormTimestamp() (string, error) {
+	if crypt.Default == nil {
+		return "", ErrCryptNotConfigured
+	}
+	return crypt.Default.EncryptString("form:" + strconv.FormatInt(time.Now().UnixMilli(), 10))
+}
+
+// checkSpam revisa los campos con el tag spam del FormRequest
+func checkSpam(request FormRequest) error {
+	return eachTarget(request, func(rv reflect.Value, prefix string) error {
+		for _, f := range getTagFields(rv.Type(), "spam") {
+			fv, err := rv.FieldByIndexErr(f.index)
+			if err != nil {
+				continue
+			}
+			value := ""
+			if fv.Kind() == reflect.Ptr {
+				if !fv.IsNil() {
+					value = fv.Elem().String()
+				}
+			} else {
+				value = fv.String()
+			}
+
+			rule, params, _ := strings.Cut(f.key, ":")
+			switch rule {
+			case "honeypot":
+				if value != "" {
+					return ErrSpam
+				}
+			case "timestamp":
+				if !validFormTimestamp(value, params) {
+					return ErrSpam
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// validFormTimestamp verifica el token de FormTimestamp con la edad minima y maxima "3s,1h"
+func validFormTimestamp(token string, params string) bool {
+	if token == "" || crypt.Default == nil {
+		return false
+	}
+	minAge, maxAge := time.Duration(0), FormTimestampMaxAge
+	minParam, maxParam, _ := strings.Cut(params, ",")
+	if d, err := time.ParseDuration(minParam); err == nil {
+		minAge = d
+	}
+	if d, err := time.ParseDuration(maxParam); err == nil {
+		maxAge = d
+	}
+
+	plain, err := crypt.Default.DecryptString(token)
+	if err != nil {
+		return false
+	}
+	ms, ok := strings.CutPrefix(plain, "form:")
+	if !ok {
+		return false
+	}
+	n, err := strconv.ParseInt(ms, 10, 64)
+	if err != nil {
+		return false
+	}
+	age := time.Since(time.UnixMilli(n))
+	return age >= minAge && age <= maxAge
+}
@@ -0,0 +1,146 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Throttle limite de solicitudes que declara un FormRequest: Limit solicitudes por Window para cada Key
+type Throttle struct {
+	Limit  int
+	Window time.Duration
+	// Key identifica a quien se limita (usuario, ip, cuenta), si es nil se usa la ip del cliente
+	Key func(req *http.Request) string
+}
+
+// ThrottledRequest lo implementa el FormRequest que limita cuantas veces se puede enviar
+// el limite se define junto al request que protege y Validate lo revisa antes de leer el body
+//
+//	func (r *ForgotPassword) Throttle() request.Throttle {
+//		return request.Throttle{Limit: 5, Window: time.Hour, Key: func(req *http.Request) string {
+//			return request.ClientIP(req)
+//		}}
+//	}
+type ThrottledRequest interface {
+	Throttle() Throttle
+}
+
+// RateLimiter cuenta las solicitudes por llave, implementelo para compartir el limite entre instancias (redis)
+type RateLimiter interface {
+	// Allow cuenta una solicitud y retorna cuantas quedan en la ventana y en cuanto se reinicia
+	Allow(key string, limit int, window time.Duration) (remaining int, reset time.Duration, ok bool)
+}
+
+// DefaultRateLimiter limitador que usan los FormRequest, en memoria para una sola instancia
+var DefaultRateLimiter RateLimiter = NewMemoryRateLimiter()
+
+// ErrTooManyRequests se usa con errors.Is para saber si el request se rechazo por el limite
+var ErrTooManyRequests = errors.New("demasiadas solicitudes")
+
+// ThrottleError el FormRequest supero su limite, RetryAfter es cuanto debe esperar el cliente
+type ThrottleError struct {
+	Limit      int
+	RetryAfter time.Duration
+}
+
+func (e *ThrottleError) Error() string {
+	return fmt.Sprintf("%s, intente de nuevo en %d segundos", ErrTooManyRequests.Error(), retrySeconds(e.RetryAfter))
+}
+
+func (e *ThrottleError) Is(target error) bool {
+	return target == ErrTooManyRequests
+}
+
+// RetryAfterSeconds segundos para la cabecera Retry-After
+func (e *ThrottleError) RetryAfterSeconds() int {
+	return retrySeconds(e.RetryAfter)
+}
+
+func retrySeconds(d time.Duration) int {
+	return max(1, int((d+time.Second-1)/time.Second))
+}
+
+// throttledKey marca el request cuyo limite ya se conto (middleware.Throttle) para no contarlo dos veces
+type throttledKey struct{}
+
+// CheckThrottle cuenta la solicitud si el FormRequest declara un limite y retorna *ThrottleError si lo supero
+// el request que retorna queda marcado para que Validate no la vuelva a contar, lo usa middleware.Throttle
+func CheckThrottle(request FormRequest, req *http.Request) (*http.Request, error) {
+	if err := checkThrottle(request, req); err != nil {
+		return req, err
+	}
+	return req.WithContext(context.WithValue(req.Context(), throttledKey{}, true)), nil
+}
+
+// checkThrottle cuenta la solicitud a menos que ya se haya contado en el middleware
+func checkThrottle(request FormRequest, req *http.Request) error {
+	if req.Context().Value(throttledKey{}) != nil {
+		return nil
+	}
+	t, ok := request.(ThrottledRequest)
+	if !ok {
+		return nil
+	}
+	throttle := t.Throttle()
+	if throttle.Limit <= 0 || throttle.Window <= 0 {
+		return nil
+	}
+	key := ClientIP(req)
+	if throttle.Key != nil {
+		key = throttle.Key(req)
+	}
+	key = reflect.TypeOf(request).String() + ":" + key
+
+	if _, reset, ok := DefaultRateLimiter.Allow(key, throttle.Limit, throttle.Window); !ok {
+		return &ThrottleError{Limit: throttle.Limit, RetryAfter: reset}
+	}
+	return nil
+}
+
+// MemoryRateLimiter limitador de ventana fija en memoria
+type MemoryRateLimiter struct {
+	mu      sync.Mutex
+	windows map[string]*rateWindow
+}
+
+type rateWindow struct {
+	count int
+	reset time.Time
+}
+
+// NewMemoryRateLimiter crea el limitador en memoria
+func NewMemoryRateLimiter() *MemoryRateLimiter {
+	return &MemoryRateLimiter{windows: make(map[string]*rateWindow)}
+}
+
+func (l *MemoryRateLimiter) Allow(key string, limit int, window time.Duration) (int, time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+
+	// las ventanas vencidas se limpian de vez en cuando para que el map no crezca sin limite
+	if len(l.windows) > 10000 {
+		for k, w := range l.windows {
+			if now.After(w.reset) {
+				delete(l.windows, k)
+			}
+		}
+	}
+
+	w, ok := l.windows[key]
+	if !ok || now.After(w.reset) {
+		w = &rateWindow{reset: now.Add(window)}
+		l.windows[key] = w
+	}
+	reset := w.reset.Sub(now)
+	if w.count >= limit {
+		return 0, reset, false
+	}
+	w.count++
+	return limit - w.count, reset, true
+}
@@ -0,0 +1,222 @@
+package request
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/internal/orm"
+)
+
+// Estados de UpsertResult
+const (
+	UpsertCreated = string(orm.Created)
+	UpsertUpdated = string(orm.Updated)
+	UpsertFailed  = "failed"
+)
+
+// UpsertConfig configuracion de BulkUpsert
+type UpsertConfig[T FormRequest] struct {
+	Table string   // tabla donde se guardan los registros
+	Keys  []string // columnas que identifican el registro, deben tener un indice unico (sku, email)
+	New   func() T // crea el FormRequest de cada elemento
+	// Row columnas y valores del elemento, nil usa los campos json del FormRequest (solo valores escalares)
+	Row func(item T) map[string]any
+	// Chunk filas por consulta, 500 si es cero
+	Chunk int
+	// Mode BestEffort guarda los validos y reporta los demas, Atomic no guarda nada si algun elemento
+	// es invalido o esta repetido y guarda todo en una transaccion (la de UnitOfWork si hay una)
+	Mode BatchMode
+}
+
+// UpsertResult resultado de un elemento de BulkUpsert
+type UpsertResult struct {
+	Index       int    `json:"index"`
+	Status      string `json:"status"` // created, updated o failed
+	DuplicateOf *int   `json:"duplicate_of,omitempty"`
+	Error       string `json:"error,omitempty"`
+	Code        string `json:"code,omitempty"`
+	Errors      any    `json:"errors,omitempty"`
+}
+
+// BulkUpsert endpoint que recibe un array de registros para crear o actualizar segun Keys
+// cada elemento se valida con su FormRequest (ValidateBatch), los que repiten las llaves de uno anterior
+// del mismo lote fallan con duplicate_of y los demas se guardan con orm.Upsert en lotes de Chunk filas
+// responde el estado de cada elemento: 200 si todos se guardaron, 207 si alguno fallo
+// y 422 en Atomic si algun elemento es invalido. si la base de datos falla y no se guardo ningun elemento
+// (o en Atomic) responde 500, los errores del body o del FormRequest tienen el status de ErrorStatus
+//
+//	{Path: "/products/bulk", Methods: AllowMethods(PUT), Handler: request.BulkUpsert(request.UpsertConfig[*request.Product]{
+//		Table: "products", Keys: []string{"sku"},
+//		New:   func() *request.Product { return &request.Product{} },
+//	})}
+//
+// no hay un constructor de consultas en orm, la persistencia es orm.Upsert sobre orm.Conn
+func BulkUpsert[T FormRequest](cfg UpsertConfig[T]) controller.ControllerFunc {
+	if cfg.Row == nil {
+		cfg.Row = jsonRow[T]
+	}
+	return func(ctx *controller.Context) {
+		items, invalid, err := ValidateBatch(ctx.Request, cfg.New)
+		if err != nil {
+			responseBatchErr(ctx, err)
+			return
+		}
+
+		results := make([]UpsertResult, len(items))
+		var rows []map[string]any
+		var indexes []int
+		seen := make(map[string]int, len(items))
+		failed := 0
+		for i, item := range items {
+			results[i] = UpsertResult{Index: i}
+			if err, ok := invalid[i]; ok {
+				results[i] = upsertError(i, err)
+				failed++
+				continue
+			}
+			row := cfg.Row(item)
+			key := orm.RowKey(row, cfg.Keys)
+			if first, ok := seen[key]; ok {
+				results[i].Status = UpsertFailed
+				results[i].DuplicateOf = &first
+				results[i].Error = fmt.Sprintf("el elemento repite el registro del elemento %d", first)
+				failed++
+				continue
+			}
+			seen[key] = i
+			rows = append(rows, row)
+			indexes = append(indexes, i)
+		}
+
+		if cfg.Mode == Atomic && failed > 0 {
+			ctx.ResponseError(http.StatusUnprocessableEntity, fmt.Sprintf("%d elementos del lote no son válidos", failed), failedResults(results))
+			return
+		}
+
+		if cfg.Mode == Atomic {
+			err = atomicUpsert(ctx.Request.Context(), cfg, rows, indexes, results)
+		} else {
+			err = bestEffortUpsert(ctx.Request.Context(), cfg, rows, indexes, results)
+		}
+		counts := map[string]int{}
+		for _, result := range results {
+			counts[result.Status]++
+		}
+		if err != nil {
+			log.Printf("bulk upsert %s: %v", cfg.Table, err)
+			if cfg.Mode == Atomic || counts[UpsertCreated]+counts[UpsertUpdated] == 0 {
+				ctx.ResponseError(http.StatusInternalServerError, "no se pudo guardar el lote", nil)
+				return
+			}
+		}
+		status := http.StatusOK
+		if counts[UpsertFailed] > 0 {
+			status = http.StatusMultiStatus
+		}
+		ctx.ResponseJSON(status, map[string]any{
+			"results": results,
+			"created": counts[UpsertCreated],
+			"updated": counts[UpsertUpdated],
+			"failed":  counts[UpsertFailed],
+		})
+	}
+}
+
+// bestEffortUpsert guarda lote por lote, si un lote falla sus elementos quedan failed y se sigue con el siguiente
+// retorna el ultimo error para el log
+func bestEffortUpsert[T FormRequest](ctx context.Context, cfg UpsertConfig[T], rows []map[string]any, indexes []int, results []UpsertResult) error {
+	chunk := cfg.Chunk
+	if chunk <= 0 {
+		chunk = 500
+	}
+	var last error
+	for start := 0; start < len(rows); start += chunk {
+		end := min(start+chunk, len(rows))
+		actions, err := orm.Upsert(ctx, cfg.Table, cfg.Keys, rows[start:end], chunk)
+		for j, i := range indexes[start:end] {
+			if err != nil {
+				results[i].Status = UpsertFailed
+				results[i].Error = "no se pudo guardar el elemento"
+				continue
+			}
+			results[i].Status = string(actions[j])
+		}
+		if err != nil {
+			last = err
+		}
+	}
+	return last
+}
+
+// atomicUpsert guarda todo en la transaccion del contexto o en una propia
+func atomicUpsert[T FormRequest](ctx context.Context, cfg UpsertConfig[T], rows []map[string]any, indexes []int, results []UpsertResult) error {
+	var tx *sql.Tx
+	if _, ok := orm.Tx(ctx); !ok {
+		var err error
+		if tx, err = orm.Begin(ctx, nil); err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		ctx = orm.WithTx(ctx, tx)
+	}
+	actions, err := orm.Upsert(ctx, cfg.Table, cfg.Keys, rows, cfg.Chunk)
+	if err != nil {
+		return err
+	}
+	if tx != nil {
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	for j, i := range indexes {
+		results[i].Status = string(actions[j])
+	}
+	return nil
+}
+
+// jsonRow columnas del elemento segun sus tags json, los enteros quedan int64 (un id grande no pierde precision)
+func jsonRow[T FormRequest](item T) map[string]any {
+	row := map[string]any{}
+	data, err := json.Marshal(item)
+	if err != nil {
+		return row
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	dec.Decode(&row)
+	for column, value := range row {
+		n, ok := value.(json.Number)
+		if !ok {
+			continue
+		}
+		if i, err := n.Int64(); err == nil {
+			row[column] = i
+		} else if f, err := n.Float64(); err == nil {
+			row[column] = f
+		}
+	}
+	return row
+}
+
+// upsertError convierte el error de validacion de un elemento en su resultado
+func upsertError(index int, err error) UpsertResult {
+	result := batchError(index, err)
+	return UpsertResult{Index: index, Status: UpsertFailed, Error: result.Error, Code: result.Code, Errors: result.Errors}
+}
+
+// failedResults solo los elementos que fallaron
+func failedResults(results []UpsertResult) []UpsertResult {
+	var out []UpsertResult
+	for _, result := range results {
+		if result.Status == UpsertFailed {
+			out = append(out, result)
+		}
+	}
+	return out
+}
@@ -0,0 +1,27 @@
+package request
+
+import (
+	"testing"
+
+	"github.com/donbarrigon/new-project/internal/orm"
+)
+
+type upsertForm struct {
+	hooks
+	ID    int64   `json:"id"`
+	SKU   string  `json:"sku"`
+	Price float64 `json:"price"`
+}
+
+func TestJSONRowKeepsIntegers(t *testing.T) {
+	row := jsonRow(&upsertForm{ID: 9007199254740993, SKU: "A-1", Price: 10.5})
+	if id, ok := row["id"].(int64); !ok || id != 9007199254740993 {
+		t.Errorf("id = %T %v, se esperaba el int64 sin perder precisión", row["id"], row["id"])
+	}
+	if price, ok := row["price"].(float64); !ok || price != 10.5 {
+		t.Errorf("price = %T %v, se esperaba 10.5", row["price"], row["price"])
+	}
+	if orm.RowKey(row, []string{"id"}) != orm.RowKey(map[string]any{"id": int64(9007199254740993)}, []string{"id"}) {
+		t.Errorf("la llave de la fila debe ser la misma que la de la base de datos")
+	}
+}
@@ -0,0 +1,244 @@
+package request
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"github.com/donbarrigon/new-project/internal/orm"
+	"github.com/donbarrigon/new-project/lib/ctxkey"
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// Concurrencia optimista: el cliente envia la version del recurso que leyo (cabecera If-Match o campo version)
+// y la regla current_version la compara con la actual, si otro la cambio mientras tanto Validate retorna
+// *StaleVersionError en lugar de sobrescribir los cambios del otro
+//
+//	type UpdateOrder struct {
+//		ID      int    `json:"id" path:"id"`
+//		Version string `json:"version" header:"If-Match" rules:"required|current_version:orders"`
+//		Status  string `json:"status" rules:"required"`
+//	}
+//
+//	err := request.Validate(&form, ctx.Request)
+//	var stale *request.StaleVersionError
+//	if errors.As(err, &stale) {
+//		// 412 con If-Match, 409 con el campo: {"code": "REQ_STALE_VERSION", "errors": {"field": "version", "given": "4", "current": "5"}}
+//		// los demas errores de validacion de la solicitud van en stale.Errors
+//		res := controller.NewErrorResponse(stale.Status(), "El pedido cambió, vuelva a cargarlo", stale)
+//		res.Errors = stale
+//		ctx.ResponseJSON(stale.Status(), res)
+//	}
+//
+// la respuesta de lectura envia la version con ctx.Writer.Header().Set("ETag", request.VersionETag(order.Version))
+// con el middleware UnitOfWork la fila queda bloqueada (FOR UPDATE) hasta el commit y nadie la cambia en medio
+
+// ErrStaleVersion la version que envio el cliente ya no es la actual
+var ErrStaleVersion = errors.New("el recurso cambió desde que se leyó")
+
+// CodeStaleVersion codigo de la regla current_version y de ErrStaleVersion
+const CodeStaleVersion = "REQ_STALE_VERSION"
+
+// StaleVersionError la version que envio el cliente no es la actual
+type StaleVersionError struct {
+	Field        string `json:"field"`
+	Given        string `json:"given"`
+	Current      string `json:"current"`
+	Precondition bool   `json:"-"` // la version vino en If-Match
+	// Errors todos los errores de validacion de la solicitud, incluido el de la version
+	Errors validation.ValidationErrors `json:"errors,omitempty"`
+}
+
+func (e *StaleVersionError) Error() string {
+	return fmt.Sprintf("%s: se envió la versión %s y la actual es %s", ErrStaleVersion.Error(), e.Given, e.Current)
+}
+
+func (e *StaleVersionError) Is(target error) bool {
+	return target == ErrStaleVersion
+}
+
+// Status 412 Precondition Failed si la version vino en If-Match, 409 Conflict si vino en el body
+func (e *StaleVersionError) Status() int {
+	if e.Precondition {
+		return http.StatusPreconditionFailed
+	}
+	return http.StatusConflict
+}
+
+// VersionSource retorna la version actual del recurso, "" si no existe (la regla no falla y el controlador responde 404)
+type VersionSource func(ctx context.Context, resource string, id string) (string, error)
+
+// CurrentVersion fuente de las versiones de la regla current_version, por defecto la columna version de la
+// tabla resource con orm.Conn (la transaccion de la solicitud si la hay). reemplacela para otras fuentes
+//
+//	request.CurrentVersion = func(ctx context.Context, resource, id string) (string, error) {
+//		return redis.HGet(ctx, resource+":"+id, "version").Result()
+//	}
+var CurrentVersion VersionSource = sqlVersion
+
+// sqlVersion lee la version de la base de datos, dentro de una transaccion bloquea la fila hasta el commit
+// el recurso va en la consulta, por eso debe ser un identificador (orders, ventas.orders)
+func sqlVersion(ctx context.Context, resource string, id string) (string, error) {
+	if !orm.IsSQL() {
+		return "", orm.ErrNoSQL
+	}
+	if err := validation.Identifier(resource); err != nil {
+		return "", fmt.Errorf("request: el recurso '%s' de current_version no es válido: %w", resource, err)
+	}
+	query := fmt.Sprintf("SELECT version FROM %s WHERE id = ?", resource)
+	if _, ok := orm.Tx(ctx); ok {
+		query += " FOR UPDATE"
+	}
+	var version sql.NullString
+	err := orm.Conn(ctx).QueryRowContext(ctx, query, id).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return version.String, err
+}
+
+// VersionETag valor de la cabecera ETag para la version, el cliente la devuelve en If-Match
+func VersionETag(version any) string {
+	return `"` + fmt.Sprint(version) + `"`
+}
+
+func init() {
+	validation.RegisterRule("current_version", currentVersionRule)
+	validation.RegisterRuleCode("current_version", CodeStaleVersion)
+	validation.RegisterErrorCode(ErrStaleVersion, CodeStaleVersion, "el recurso cambió desde que el cliente lo leyó (409 o 412)")
+	validation.ServerRules["current_version"] = true
+}
+
+// currentVersionRule compara el valor con la version actual (current_version:orders o current_version:orders,order_id)
+// el segundo parametro es el campo con el id del recurso, id si no se indica, o un valor como {path.id}
+func currentVersionRule(f *validation.Field) error {
+	if len(f.Params) == 0 {
+		return errors.New("la regla current_version requiere el recurso (current_version:orders)")
+	}
+	id := "id"
+	if len(f.Params) > 1 {
+		id = f.Params[1]
+	}
+	if value, ok := f.Data[id]; ok {
+		id = fmt.Sprint(value)
+	}
+	current, err := CurrentVersion(f.Context, f.Params[0], id)
+	if err != nil {
+		return validation.Hard(err)
+	}
+	given := fmt.Sprint(f.Value)
+	if current == "" || current == given {
+		return nil
+	}
+	if holder, ok := ctxkey.Get(f.Context, versionKey); ok {
+		holder.mu.Lock()
+		holder.stale = &StaleVersionError{Field: f.Name, Given: given, Current: current}
+		holder.mu.Unlock()
+	}
+	return fmt.Errorf("el recurso cambió, la versión actual es %s", current)
+}
+
+// versionKey llave del contexto donde la regla deja la version actual
+var versionKey = ctxkey.New[*versionHolder]("stale_version")
+
+type versionHolder struct {
+	mu    sync.Mutex
+	stale *StaleVersionError
+}
+
+// versionRules cache de los tipos que usan la regla current_version
+var versionRules sync.Map
+
+// withVersionHolder prepara el contexto para la regla si el FormRequest la usa, si no retorna el mismo request
+// las reglas de RulesFor y de validation.WithRules se conocen al validar, con ellas siempre se prepara
+func withVersionHolder(request FormRequest, req *http.Request) (*http.Request, *versionHolder) {
+	t := reflect.TypeOf(request)
+	uses, ok := versionRules.Load(t)
+	if !ok {
+		uses = usesRule(t, "current_version", map[reflect.Type]bool{})
+		versionRules.Store(t, uses)
+	}
+	if !uses.(bool) && !overridesRule(request, req, "current_version") {
+		return req, nil
+	}
+	holder := &versionHolder{}
+	return ctxkey.SetRequest(req, versionKey, holder), holder
+}
+
+// usesRule indica si algun campo del tipo (o de sus structs y slices) tiene la regla
+func usesRule(t reflect.Type, rule string, visiting map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || visiting[t] {
+		return false
+	}
+	visiting[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if hasRule(field.Tag.Get("rules"), rule) {
+			return true
+		}
+		if usesRule(field.Type, rule, visiting) {
+			return true
+		}
+	}
+	return false
+}
+
+// overridesRule indica si las reglas que reemplazan a las del tag pueden tener la regla
+func overridesRule(request FormRequest, req *http.Request, rule string) bool {
+	if _, ok := request.(RulesForRequest); ok {
+		return true
+	}
+	for _, rules := range validation.RulesOf(req.Context()) {
+		if hasRule(rules, rule) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasRule indica si las reglas tienen la regla, en cualquiera de las formas de validation.ParseRules
+// ("required|current_version:orders", "required,current_version=orders")
+func hasRule(rules string, rule string) bool {
+	if rules == "" {
+		return false
+	}
+	for _, r := range validation.ParseRules(rules) {
+		if r.Name == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// staleVersion convierte el error de la regla en *StaleVersionError, Precondition si el campo se lleno con If-Match
+// los errores de validacion de los demas campos no se pierden, quedan en Errors
+func staleVersion(request FormRequest, req *http.Request, holder *versionHolder, err error) error {
+	if holder == nil || err == nil {
+		return err
+	}
+	holder.mu.Lock()
+	stale := holder.stale
+	holder.mu.Unlock()
+	if stale == nil {
+		return err
+	}
+	var errs validation.ValidationErrors
+	if errors.As(err, &errs) {
+		stale.Errors = errs
+	}
+	if rv := reflect.ValueOf(request).Elem(); rv.Kind() == reflect.Struct {
+		for _, bf := range getBindFields(rv.Type()) {
+			if bf.source == "header" && bf.name == stale.Field && req.Header.Get(bf.key) != "" {
+				stale.Precondition = true
+			}
+		}
+	}
+	return stale
+}
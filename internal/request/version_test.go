@@ -0,0 +1,84 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type staleForm struct {
+	hooks
+	ID      string `json:"id"`
+	Version string `json:"version" rules:"required,current_version:orders"`
+	Status  string `json:"status" rules:"required"`
+}
+
+func TestStaleVersionKeepsOtherErrors(t *testing.T) {
+	previous := CurrentVersion
+	defer func() { CurrentVersion = previous }()
+	CurrentVersion = func(ctx context.Context, resource string, id string) (string, error) {
+		return "5", nil
+	}
+
+	req := httptest.NewRequest(http.MethodPut, "/orders/1", strings.NewReader(`{"id":"1","version":"4"}`))
+	req.Header.Set("Content-Type", "application/json")
+	err := Validate(&staleForm{}, req)
+	var stale *StaleVersionError
+	if !errors.As(err, &stale) {
+		t.Fatalf("se esperaba *StaleVersionError, se obtuvo %v", err)
+	}
+	if stale.Given != "4" || stale.Current != "5" {
+		t.Errorf("versiones = %s/%s, se esperaba 4/5", stale.Given, stale.Current)
+	}
+	if !stale.Errors.Has("status") || !stale.Errors.Has("version") {
+		t.Errorf("se esperaban los errores de status y version, se obtuvo %v", stale.Errors)
+	}
+}
+
+func TestUsesRule(t *testing.T) {
+	type item struct {
+		Version string `rules:"required,current_version=orders"`
+	}
+	type list struct {
+		Items []item
+	}
+	cases := []struct {
+		name string
+		t    reflect.Type
+		want bool
+	}{
+		{"pipe", reflect.TypeOf(staleForm{}), true},
+		{"comas en un slice", reflect.TypeOf(list{}), true},
+		{"sin la regla", reflect.TypeOf(dryRunForm{}), false},
+	}
+	for _, c := range cases {
+		if got := usesRule(c.t, "current_version", map[reflect.Type]bool{}); got != c.want {
+			t.Errorf("%s: usesRule = %v, se esperaba %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestForVersion(t *testing.T) {
+	type v1Form struct{ dryRunForm }
+	RegisterVersion("test.create", "v1", func() FormRequest { return &v1Form{} })
+	RegisterVersion("test.create", "v3", func() FormRequest { return &dryRunForm{} })
+
+	cases := map[string]string{
+		"/v1/users": "*request.v1Form",
+		"/v2/users": "*request.v1Form",
+		"/v3/users": "*request.dryRunForm",
+	}
+	for target, want := range cases {
+		fr := ForVersion("test.create", httptest.NewRequest(http.MethodPost, target, nil))
+		if got := reflect.TypeOf(fr).String(); got != want {
+			t.Errorf("ForVersion(%s) = %s, se esperaba %s", target, got, want)
+		}
+	}
+	if fr := ForVersion("test.missing", httptest.NewRequest(http.MethodPost, "/v1/users", nil)); fr != nil {
+		t.Errorf("se esperaba nil sin requests registrados, se obtuvo %T", fr)
+	}
+}
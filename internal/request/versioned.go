@@ -0,0 +1,64 @@
+package request
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/donbarrigon/new-project/internal/versioning"
+)
+
+// versionedRequest FormRequest registrado para una version
+type versionedRequest struct {
+	version string
+	factory func() FormRequest
+}
+
+var (
+	versioned   = map[string][]versionedRequest{}
+	versionedMu sync.RWMutex
+)
+
+// RegisterVersion registra el FormRequest que valida la accion name en la version indicada
+// cada version puede tener sus propios campos y reglas sin duplicar el controlador
+//
+//	request.RegisterVersion("user.create", "v1", func() request.FormRequest { return &UserV1{} })
+//	request.RegisterVersion("user.create", "v2", func() request.FormRequest { return &User{} })
+func RegisterVersion(name string, version string, factory func() FormRequest) {
+	versionedMu.Lock()
+	defer versionedMu.Unlock()
+	version = versioning.Normalize(version)
+	list := versioned[name]
+	for i := range list {
+		if list[i].version == version {
+			list[i].factory = factory
+			return
+		}
+	}
+	versioned[name] = append(list, versionedRequest{version: version, factory: factory})
+}
+
+// ForVersion crea el FormRequest de la accion name para la version del request
+// si la version no tiene uno propio se usa el de la version anterior mas cercana, asi una version
+// nueva solo registra los requests que cambian. Retorna nil si no hay ninguno registrado
+//
+//	req := request.ForVersion("user.create", ctx.Request)
+//	if err := request.Validate(req, ctx.Request); err != nil { ... }
+func ForVersion(name string, req *http.Request) FormRequest {
+	version := versioning.FromRequest(req)
+
+	versionedMu.RLock()
+	defer versionedMu.RUnlock()
+	var best *versionedRequest
+	for i, vr := range versioned[name] {
+		if versioning.Compare(vr.version, version) > 0 {
+			continue
+		}
+		if best == nil || versioning.Compare(vr.version, best.version) > 0 {
+			best = &versioned[name][i]
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	return best.factory()
+}
@@ -0,0 +1,30 @@
+package request
+
+import (
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// Las reglas del tag warn (ver validation.WithWarnings) no hacen fallar Validate, sus mensajes quedan en el FormRequest
+// que embebe Request y el controlador los devuelve en la respuesta
+//
+//	type CreateCustomer struct {
+//		request.Request
+//		Phone string `json:"phone" warn:"required" warn_message:"el teléfono será obligatorio desde la versión 3"`
+//	}
+//
+//	if err := request.Validate(&req, ctx.Request); err != nil { ... }
+//	ctx.ResponseWithWarnings(http.StatusCreated, customer, req.Warnings())
+
+// warningsHolder lo implementa Request para guardar las advertencias de la validacion
+type warningsHolder interface {
+	setWarnings(warnings validation.ValidationErrors)
+}
+
+// Warnings retorna las advertencias de la ultima validacion, nil si no hubo
+func (r *Request) Warnings() validation.ValidationErrors {
+	return r.warnings
+}
+
+func (r *Request) setWarnings(warnings validation.ValidationErrors) {
+	r.warnings = warnings
+}
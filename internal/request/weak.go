@@ -0,0 +1,223 @@
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// Tipado flexible: los clientes viejos envian los numeros como texto ("42") o los textos como numeros (un codigo 123)
+// y encoding/json falla con "cannot unmarshal string into Go struct field". Con el tipado flexible el body se
+// convierte al tipo de cada campo antes de deserializar y si el valor no se puede convertir ("abc" en un int)
+// el error es de validacion en ese campo
+//
+//	request.WeakTyping = true // para toda la aplicacion
+//
+//	func (r *LegacyOrder) WeakTyping() bool { return true } // para un FormRequest
+//
+//	type Order struct {
+//		Quantity int    `json:"quantity" weak:"true"` // solo un campo, en un struct aplica a todos sus campos
+//		Code     string `json:"code" weak:"true"`
+//	}
+//
+// un texto vacio en un campo numerico se toma como null (la regla required lo reporta si es obligatorio)
+
+// WeakTyping si es true todos los FormRequest convierten texto y numeros al tipo de cada campo
+var WeakTyping = false
+
+// WeakTypedRequest lo implementa el FormRequest que quiere el tipado flexible sin activarlo para toda la aplicacion
+type WeakTypedRequest interface {
+	WeakTyping() bool
+}
+
+// weakTyper lo implementa Optional para que se convierta al tipo de su valor
+type weakTyper interface {
+	weakType() reflect.Type
+}
+
+func (o Optional[T]) weakType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+var (
+	jsonNumberType  = reflect.TypeOf(json.Number(""))
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	weakTyperType   = reflect.TypeOf((*weakTyper)(nil)).Elem()
+	timeType        = reflect.TypeOf(time.Time{})
+)
+
+// weakCache indica por tipo de struct si algun campo tiene el tag weak
+var weakCache sync.Map
+
+func hasWeakFields(t reflect.Type) bool {
+	if cached, ok := weakCache.Load(t); ok {
+		return cached.(bool)
+	}
+	found := buildHasWeak(t, map[reflect.Type]bool{})
+	weakCache.Store(t, found)
+	return found
+}
+
+func buildHasWeak(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	visiting[t] = true
+	defer delete(visiting, t)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Tag.Get("weak") == "true" {
+			return true
+		}
+		ft := derefType(field.Type)
+		for ft.Kind() == reflect.Slice || ft.Kind() == reflect.Array {
+			ft = derefType(ft.Elem())
+		}
+		if ft.Kind() == reflect.Struct && !visiting[ft] && buildHasWeak(ft, visiting) {
+			return true
+		}
+	}
+	return false
+}
+
+// coerceWeak convierte los valores del body al tipo de los campos si el FormRequest usa tipado flexible
+func coerceWeak(request FormRequest, body []byte) ([]byte, error) {
+	all := WeakTyping
+	if w, ok := request.(WeakTypedRequest); ok {
+		all = w.WeakTyping()
+	}
+	st, ok := structType(reflect.TypeOf(request))
+	if !ok || (!all && !hasWeakFields(st)) || len(bytes.TrimSpace(body)) == 0 {
+		return body, nil
+	}
+
+	var value any
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		// el error de sintaxis lo reporta la deserializacion normal
+		return body, nil
+	}
+	var errs validation.CodedErrors
+	changed := false
+	value = coerceValue(value, reflect.TypeOf(request).Elem(), all, "", &errs, &changed)
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+	if !changed {
+		return body, nil
+	}
+	return json.Marshal(value)
+}
+
+// coerceValue recorre el valor json con el tipo de Go, weak indica si el valor se puede convertir
+func coerceValue(value any, t reflect.Type, weak bool, path string, errs *validation.CodedErrors, changed *bool) any {
+	t = derefType(t)
+	if t.Implements(weakTyperType) {
+		t = derefType(reflect.Zero(t).Interface().(weakTyper).weakType())
+	}
+
+	switch {
+	case t == jsonNumberType:
+		return coerceNumber(value, weak, false, path, errs, changed)
+	case t == timeType || t.Implements(unmarshalerType) || reflect.PointerTo(t).Implements(unmarshalerType):
+		return value
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return value
+		}
+		coerceStruct(object, t, weak, path, errs, changed)
+		return object
+	case reflect.Slice, reflect.Array:
+		items, ok := value.([]any)
+		if !ok || t.Elem().Kind() == reflect.Uint8 {
+			return value
+		}
+		for i, item := range items {
+			items[i] = coerceValue(item, t.Elem(), weak, joinField(path, strconv.Itoa(i)), errs, changed)
+		}
+		return items
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return coerceNumber(value, weak, true, path, errs, changed)
+	case reflect.Float32, reflect.Float64:
+		return coerceNumber(value, weak, false, path, errs, changed)
+	case reflect.String:
+		if n, ok := value.(json.Number); ok && weak {
+			*changed = true
+			return n.String()
+		}
+	}
+	return value
+}
+
+// coerceStruct convierte los campos del objeto, los structs embebidos se aplanan como en encoding/json
+func coerceStruct(object map[string]any, t reflect.Type, weak bool, path string, errs *validation.CodedErrors, changed *bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldWeak := weak || field.Tag.Get("weak") == "true"
+		if field.Anonymous && field.Tag.Get("json") == "" && derefType(field.Type).Kind() == reflect.Struct {
+			coerceStruct(object, derefType(field.Type), fieldWeak, path, errs, changed)
+			continue
+		}
+		name := fieldName(field)
+		if field.Tag.Get("json") == "-" {
+			continue
+		}
+		if value, ok := object[name]; ok && value != nil {
+			object[name] = coerceValue(value, field.Type, fieldWeak, joinField(path, name), errs, changed)
+		}
+	}
+}
+
+// coerceNumber convierte el texto en numero, integer exige un entero
+func coerceNumber(value any, weak bool, integer bool, path string, errs *validation.CodedErrors, changed *bool) any {
+	s, ok := value.(string)
+	if !ok || !weak {
+		return value
+	}
+	*changed = true
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	if integer {
+		if _, err := strconv.ParseInt(s, 10, 64); err != nil {
+			if _, err := strconv.ParseUint(s, 10, 64); err != nil {
+				errs.Add(path, validation.CodeType, "debe ser un número entero")
+				return value
+			}
+		}
+		return json.Number(s)
+	}
+	if _, err := strconv.ParseFloat(s, 64); err != nil {
+		errs.Add(path, validation.CodeType, "debe ser un número")
+		return value
+	}
+	return json.Number(s)
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// joinField une la ruta del campo con notacion de puntos
+func joinField(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,169 @@
+package response
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"iter"
+	"net/http"
+	"strconv"
+)
+
+// ExcelContentType media type de los archivos .xlsx
+const ExcelContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// las partes fijas del archivo xlsx, solo cambia la hoja con los datos
+var excelParts = []struct{ name, body string }{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Hoja1" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+// Excel escribe las filas como un archivo .xlsx con una sola hoja
+// los numeros se guardan como numeros para que se puedan sumar y ordenar, lo demas como texto
+//
+//	err := response.Excel(ctx.Writer, "ventas.xlsx", columns, sales.Cursor(ctx.Request.Context()))
+func Excel[T any](w http.ResponseWriter, filename string, columns []Column[T], rows iter.Seq2[T, error]) error {
+	var (
+		zw    *zip.Writer
+		sheet *bufio.Writer
+	)
+	rowNum := 0
+	start := func() error {
+		setDownloadHeaders(w, ExcelContentType, filename)
+		w.WriteHeader(http.StatusOK)
+
+		zw = zip.NewWriter(w)
+		for _, part := range excelParts {
+			f, err := zw.Create(part.name)
+			if err != nil {
+				return err
+			}
+			if _, err := io.WriteString(f, part.body); err != nil {
+				return err
+			}
+		}
+		f, err := zw.Create("xl/worksheets/sheet1.xml")
+		if err != nil {
+			return err
+		}
+		sheet = bufio.NewWriter(f)
+		sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+		headers := make([]any, len(columns))
+		for i, col := range columns {
+			headers[i] = col.Header
+		}
+		return writeExcelRow(sheet, &rowNum, headers)
+	}
+
+	values := make([]any, len(columns))
+	for row, err := range rows {
+		if err != nil {
+			if zw != nil {
+				sheet.Flush()
+				zw.Flush()
+			}
+			return err
+		}
+		if zw == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		for i, col := range columns {
+			values[i] = col.Value(row)
+		}
+		if err := writeExcelRow(sheet, &rowNum, values); err != nil {
+			return err
+		}
+		if (rowNum-1)%FlushEvery == 0 {
+			if err := sheet.Flush(); err != nil {
+				return err
+			}
+			if err := zw.Flush(); err != nil {
+				return err
+			}
+			if f, ok := w.(http.Flusher); ok {
+				f.Flush()
+			}
+		}
+	}
+	if zw == nil {
+		if err := start(); err != nil {
+			return err
+		}
+	}
+	sheet.WriteString(`</sheetData></worksheet>`)
+	if err := sheet.Flush(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// writeExcelRow escribe una fila de la hoja, las celdas de texto van en linea (inlineStr)
+func writeExcelRow(w *bufio.Writer, rowNum *int, values []any) error {
+	*rowNum++
+	w.WriteString(`<row r="`)
+	w.WriteString(strconv.Itoa(*rowNum))
+	w.WriteString(`">`)
+	for i, value := range values {
+		ref := excelColumn(i) + strconv.Itoa(*rowNum)
+		if n, ok := excelNumber(value); ok {
+			w.WriteString(`<c r="` + ref + `"><v>` + n + `</v></c>`)
+			continue
+		}
+		s := cellString(value)
+		if s == "" {
+			continue
+		}
+		w.WriteString(`<c r="` + ref + `" t="inlineStr"><is><t xml:space="preserve">`)
+		if err := xml.EscapeText(w, []byte(s)); err != nil {
+			return err
+		}
+		w.WriteString(`</t></is></c>`)
+	}
+	_, err := w.WriteString(`</row>`)
+	return err
+}
+
+// excelColumn nombre de la columna en la hoja: 0 -> A, 25 -> Z, 26 -> AA
+func excelColumn(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// excelNumber retorna el numero como texto si el valor es numerico
+func excelNumber(value any) (string, bool) {
+	switch v := value.(type) {
+	case int:
+		return strconv.Itoa(v), true
+	case int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return cellString(v), true
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	}
+	return "", false
+}
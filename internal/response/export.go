@@ -0,0 +1,185 @@
+package response
+
+import (
+	"encoding/csv"
+	"fmt"
+	"iter"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Los reportes se escriben mientras se recorren las filas, sin cargar toda la coleccion en memoria
+// las filas llegan en un iter.Seq2 (un cursor de la base de datos, una paginacion) y cada FlushEvery filas
+// se envian al cliente
+//
+//	columns := []response.Column[model.User]{
+//		{Header: "ID", Value: func(u model.User) any { return u.ID }},
+//		{Header: "Nombre", Value: func(u model.User) any { return u.Name }},
+//		{Header: "Creado", Value: func(u model.User) any { return u.CreatedAt }},
+//	}
+//	if err := response.CSV(ctx.Writer, "usuarios.csv", columns, users.Cursor(ctx.Request.Context())); err != nil {
+//		log.Println(err)
+//	}
+//
+// si el iterador falla antes de la primera fila no se escribe nada y se puede responder el error,
+// si falla despues la respuesta ya empezo y el archivo queda incompleto
+
+// FlushEvery cantidad de filas que se escriben antes de enviarlas al cliente
+var FlushEvery = 500
+
+// WriteBOM si es true el CSV empieza con el BOM de UTF-8 para que Excel muestre bien las tildes
+var WriteBOM = true
+
+// EscapeFormulas si es true las celdas que empiezan con = + - @ se escriben con una comilla al inicio
+// evita que una hoja de calculo ejecute formulas que vienen de los datos de los usuarios (CSV injection)
+var EscapeFormulas = true
+
+// TimeFormat formato con el que se escriben las fechas
+var TimeFormat = time.RFC3339
+
+// Column columna del reporte: el titulo y como obtener el valor de cada fila
+type Column[T any] struct {
+	Header string
+	Value  func(row T) any
+}
+
+// CSV escribe las filas como un archivo CSV que el navegador descarga con el nombre filename
+func CSV[T any](w http.ResponseWriter, filename string, columns []Column[T], rows iter.Seq2[T, error]) error {
+	cw := csv.NewWriter(w)
+	record := make([]string, len(columns))
+	started := false
+	start := func() error {
+		started = true
+		setDownloadHeaders(w, "text/csv; charset=utf-8", filename)
+		w.WriteHeader(http.StatusOK)
+		if WriteBOM {
+			if _, err := w.Write([]byte("\uFEFF")); err != nil {
+				return err
+			}
+		}
+		for i, col := range columns {
+			record[i] = escapeCell(col.Header)
+		}
+		return cw.Write(record)
+	}
+
+	count := 0
+	for row, err := range rows {
+		if err != nil {
+			cw.Flush()
+			return err
+		}
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		for i, col := range columns {
+			record[i] = escapeCell(cellString(col.Value(row)))
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+		if count++; count%FlushEvery == 0 {
+			if err := flush(w, cw); err != nil {
+				return err
+			}
+		}
+	}
+	if !started {
+		if err := start(); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// setDownloadHeaders define el tipo del archivo y el nombre con el que se descarga
+// el nombre se codifica (filename*) si tiene caracteres que no son ascii
+func setDownloadHeaders(w http.ResponseWriter, contentType string, filename string) {
+	header := w.Header()
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("Cache-Control", "no-store")
+}
+
+// flush envia al cliente lo que se ha escrito
+func flush(w http.ResponseWriter, cw *csv.Writer) error {
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return err
+	}
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+// cellString convierte el valor de una celda en texto
+func cellString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format(TimeFormat)
+	case *time.Time:
+		if v == nil || v.IsZero() {
+			return ""
+		}
+		return v.Format(TimeFormat)
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	case fmt.Stringer:
+		return v.String()
+	case error:
+		return v.Error()
+	}
+	return fmt.Sprint(value)
+}
+
+// escapeCell agrega una comilla a las celdas que una hoja de calculo interpretaria como formula
+func escapeCell(s string) string {
+	if !EscapeFormulas || s == "" {
+		return s
+	}
+	switch s[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		// los numeros negativos no son formulas
+		if s[0] == '-' && isNumber(s) {
+			return s
+		}
+		return "'" + s
+	}
+	return s
+}
+
+// isNumber indica si el texto es un numero (-12.5)
+func isNumber(s string) bool {
+	s = strings.TrimPrefix(s, "-")
+	if s == "" {
+		return false
+	}
+	dot := false
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+		case r == '.' && !dot:
+			dot = true
+		default:
+			return false
+		}
+	}
+	return true
+}
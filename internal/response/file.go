@@ -0,0 +1,202 @@
+package response
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/donbarrigon/new-project/lib/storage"
+)
+
+// Archivos de los discos con soporte de rangos (Range, If-Range), ETag y Last-Modified: el navegador o el gestor
+// de descargas reanuda un archivo grande donde quedo y los videos se pueden adelantar sin descargarlos completos
+//
+//	func ShowInvoice(ctx *controller.Context) {
+//		err := response.Download(ctx.Writer, ctx.Request, nil, "invoices/"+id+".pdf", "factura-"+id+".pdf")
+//		if errors.Is(err, storage.ErrNotFound) {
+//			ctx.ResponseError(http.StatusNotFound, "La factura no existe", nil)
+//		}
+//	}
+//
+// los rangos funcionan si Open del disco retorna un io.Seeker (storage.Local) o si el disco implementa
+// storage.RangeReader, con otros discos el archivo se envia completo. un disco que envuelve a otro (tenant.Disk)
+// y retorna errors.ErrUnsupported en OpenRange responde los rangos leyendo el archivo desde el principio
+
+// File envia el archivo para mostrarlo en el navegador (imagenes, pdf, video), disk nil usa storage.Default
+// retorna storage.ErrNotFound si no existe, en ese caso no se escribio nada y se puede responder 404
+func File(w http.ResponseWriter, r *http.Request, disk storage.Disk, filePath string) error {
+	return serveFile(w, r, disk, filePath, "inline", path.Base(filePath))
+}
+
+// Download envia el archivo como descarga con el nombre filename, "" usa el nombre del archivo en el disco
+func Download(w http.ResponseWriter, r *http.Request, disk storage.Disk, filePath string, filename string) error {
+	if filename == "" {
+		filename = path.Base(filePath)
+	}
+	return serveFile(w, r, disk, filePath, "attachment", filename)
+}
+
+// serveFile arma las cabeceras y delega los rangos y las condiciones en http.ServeContent
+func serveFile(w http.ResponseWriter, r *http.Request, disk storage.Disk, filePath string, disposition string, filename string) error {
+	if disk == nil {
+		disk = storage.Default
+	}
+	ctx := r.Context()
+	info, err := stat(ctx, disk, filePath)
+	if err != nil {
+		return err
+	}
+	rc, err := disk.Open(ctx, filePath)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	header := w.Header()
+	if contentType := mime.TypeByExtension(path.Ext(filename)); contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	header.Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": filename}))
+	header.Set("X-Content-Type-Options", "nosniff")
+	if etag := fileETag(info); etag != "" {
+		header.Set("ETag", etag)
+	}
+
+	if rs, ok := rc.(io.ReadSeeker); ok {
+		http.ServeContent(w, r, filename, info.ModTime, rs)
+		return nil
+	}
+	if ranger, ok := disk.(storage.RangeReader); ok {
+		// cada rango se pide al disco, no se descarga el archivo completo
+		rc.Close()
+		rs := &rangeSeeker{ctx: ctx, disk: disk, ranger: ranger, path: filePath, size: info.Size}
+		defer rs.Close()
+		http.ServeContent(w, r, filename, info.ModTime, rs)
+		return nil
+	}
+
+	// el disco no permite rangos, se envia completo
+	header.Set("Accept-Ranges", "none")
+	if !info.ModTime.IsZero() {
+		header.Set("Last-Modified", info.ModTime.UTC().Format(http.TimeFormat))
+	}
+	// comparacion debil: Compress envia el ETag con W/ y el cliente lo devuelve asi
+	if etag := header.Get("ETag"); etag != "" && strings.TrimPrefix(r.Header.Get("If-None-Match"), "W/") == strings.TrimPrefix(etag, "W/") {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+	if header.Get("Content-Type") == "" {
+		header.Set("Content-Type", "application/octet-stream")
+	}
+	header.Set("Content-Length", strconv.FormatInt(info.Size, 10))
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		io.Copy(w, rc)
+	}
+	return nil
+}
+
+// stat retorna el tamaño y la fecha del archivo, sin storage.Statter solo el tamaño
+func stat(ctx context.Context, disk storage.Disk, filePath string) (storage.FileInfo, error) {
+	if statter, ok := disk.(storage.Statter); ok {
+		info, err := statter.Stat(ctx, filePath)
+		if !errors.Is(err, errors.ErrUnsupported) {
+			return info, err
+		}
+	}
+	size, err := disk.Size(ctx, filePath)
+	return storage.FileInfo{Size: size}, err
+}
+
+// fileETag el ETag del disco o uno con el tamaño y la fecha de modificacion, "" si no hay fecha
+// debe ser fuerte (sin W/) para que If-Range permita reanudar la descarga
+func fileETag(info storage.FileInfo) string {
+	if info.ETag != "" {
+		return info.ETag
+	}
+	if info.ModTime.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf(`"%x-%x"`, info.Size, info.ModTime.UnixNano())
+}
+
+// rangeSeeker io.ReadSeeker sobre un storage.RangeReader, abre el archivo desde la posicion en la primera lectura
+// despues de cada Seek. si el disco no puede leer rangos (errors.ErrUnsupported) abre el archivo completo
+// y descarta los bytes anteriores a la posicion, la respuesta ya empezo y no se puede cambiar a un 200
+type rangeSeeker struct {
+	ctx    context.Context
+	disk   storage.Disk
+	ranger storage.RangeReader // nil si el disco no lee rangos
+	path   string
+	size   int64
+	offset int64
+	body   io.ReadCloser
+}
+
+func (s *rangeSeeker) Read(p []byte) (int, error) {
+	if s.offset >= s.size {
+		return 0, io.EOF
+	}
+	if s.body == nil {
+		body, err := s.open()
+		if err != nil {
+			return 0, err
+		}
+		s.body = body
+	}
+	n, err := s.body.Read(p)
+	s.offset += int64(n)
+	return n, err
+}
+
+// open abre el archivo desde la posicion con OpenRange o con Open si el disco no lee rangos
+func (s *rangeSeeker) open() (io.ReadCloser, error) {
+	if s.ranger != nil {
+		body, err := s.ranger.OpenRange(s.ctx, s.path, s.offset, s.size-s.offset)
+		if !errors.Is(err, errors.ErrUnsupported) {
+			return body, err
+		}
+		s.ranger = nil
+	}
+	body, err := s.disk.Open(s.ctx, s.path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.CopyN(io.Discard, body, s.offset); err != nil {
+		body.Close()
+		return nil, err
+	}
+	return body, nil
+}
+
+func (s *rangeSeeker) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += s.offset
+	case io.SeekEnd:
+		offset += s.size
+	}
+	if offset < 0 {
+		return 0, errors.New("posición negativa")
+	}
+	if offset != s.offset {
+		s.Close()
+		s.offset = offset
+	}
+	return offset, nil
+}
+
+func (s *rangeSeeker) Close() error {
+	if s.body == nil {
+		return nil
+	}
+	err := s.body.Close()
+	s.body = nil
+	return err
+}
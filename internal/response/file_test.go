@@ -0,0 +1,83 @@
+package response
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/donbarrigon/new-project/internal/tenant"
+	"github.com/donbarrigon/new-project/lib/storage"
+)
+
+// memDisk disco en memoria, Open no retorna un io.Seeker asi que los rangos dependen de OpenRange
+type memDisk struct {
+	files map[string][]byte
+}
+
+func (d *memDisk) Put(ctx context.Context, p string, r io.Reader) (int64, error) {
+	data, err := io.ReadAll(r)
+	d.files[p] = data
+	return int64(len(data)), err
+}
+
+func (d *memDisk) Open(ctx context.Context, p string) (io.ReadCloser, error) {
+	data, ok := d.files[p]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (d *memDisk) Size(ctx context.Context, p string) (int64, error) {
+	data, ok := d.files[p]
+	if !ok {
+		return 0, storage.ErrNotFound
+	}
+	return int64(len(data)), nil
+}
+
+func (d *memDisk) Delete(ctx context.Context, p string) error {
+	delete(d.files, p)
+	return nil
+}
+
+// memRangeDisk memDisk que lee rangos
+type memRangeDisk struct {
+	memDisk
+}
+
+func (d *memRangeDisk) OpenRange(ctx context.Context, p string, offset int64, length int64) (io.ReadCloser, error) {
+	data := d.files[p]
+	return io.NopCloser(bytes.NewReader(data[offset : offset+length])), nil
+}
+
+func TestFileRangeThroughTenantDisk(t *testing.T) {
+	const content = "abcdefghijklmnopqrstuvwxyz"
+	cases := []struct {
+		name string
+		base storage.Disk
+	}{
+		{"base sin rangos", &memDisk{files: map[string][]byte{}}},
+		{"base con rangos", &memRangeDisk{memDisk{files: map[string][]byte{}}}},
+	}
+	for _, c := range cases {
+		disk := tenant.NewDisk(c.base, "tenants")
+		ctx := tenant.WithTenant(context.Background(), &tenant.Tenant{ID: "acme"})
+		if _, err := disk.Put(ctx, "docs/abc.txt", bytes.NewReader([]byte(content))); err != nil {
+			t.Fatal(err)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/files/abc.txt", nil).WithContext(ctx)
+		req.Header.Set("Range", "bytes=2-5")
+		rec := httptest.NewRecorder()
+		if err := File(rec, req, disk, "docs/abc.txt"); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if rec.Code != http.StatusPartialContent || rec.Body.String() != "cdef" {
+			t.Errorf("%s: status %d con %q, se esperaba 206 con %q", c.name, rec.Code, rec.Body.String(), "cdef")
+		}
+	}
+}
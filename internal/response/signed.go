@@ -0,0 +1,51 @@
+package response
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/lib/crypt"
+	"github.com/donbarrigon/new-project/lib/storage"
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// Codigos de los enlaces firmados en las respuestas
+const (
+	CodeInvalidSignature = "URL_INVALID_SIGNATURE"
+	CodeExpiredSignature = "URL_EXPIRED"
+)
+
+func init() {
+	validation.RegisterErrorCode(crypt.ErrInvalidSignature, CodeInvalidSignature, "el enlace no tiene firma o se modificó")
+	validation.RegisterErrorCode(crypt.ErrExpiredSignature, CodeExpiredSignature, "el enlace firmado ya venció")
+}
+
+// SignedFiles sirve como descarga los archivos del disco en los enlaces de storage.TemporaryURL
+// prefix es la ruta donde se registra, el resto de la url es la ruta del archivo en el disco
+// la firma ya autoriza la descarga, la ruta no debe pasar por los middlewares de sesion
+//
+//	storage.Default = &storage.Local{Root: "storage/app", URL: os.Getenv("APP_URL") + "/files"}
+//	router.Handle("GET /files/", response.SignedFiles(storage.Default, "/files/"))
+func SignedFiles(disk storage.Disk, prefix string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := controller.NewContext(w, r)
+		if err := crypt.VerifyURL(r.URL); err != nil {
+			ctx.ResponseErr(http.StatusForbidden, err.Error(), err)
+			return
+		}
+		filePath, ok := strings.CutPrefix(r.URL.Path, prefix)
+		if !ok || filePath == "" {
+			ctx.ResponseError(http.StatusNotFound, "El archivo no existe", nil)
+			return
+		}
+		err := Download(w, r, disk, filePath, "")
+		switch {
+		case errors.Is(err, storage.ErrNotFound), errors.Is(err, storage.ErrInvalidPath):
+			ctx.ResponseError(http.StatusNotFound, "El archivo no existe", nil)
+		case err != nil:
+			ctx.ResponseError(http.StatusInternalServerError, "No se pudo leer el archivo", nil)
+		}
+	})
+}
@@ -1,8 +1,14 @@
 package routesmaker
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/donbarrigon/new-project/internal/contract"
 	"github.com/donbarrigon/new-project/internal/controller"
 )
 
@@ -25,9 +31,98 @@ type Route struct {
 	Methods []string
 	Handler controller.ControllerFunc
 	Name    string
+	Timeout time.Duration // tiempo maximo para responder, 0 sin limite
+	// Contract esquema de la respuesta exitosa, se revisa solo en desarrollo y pruebas (ver contract.Enabled)
+	Contract contract.Contract
+	// Preload recursos de la pagina que se anuncian con 103 Early Hints antes de los middlewares
+	Preload []controller.Preload
 }
 
 // AllowMethods funcion auxiliar para crear un slice de strings y que se vea bonito el codigo
 func AllowMethods(m ...string) []string {
 	return m
 }
+
+// namedRoutes patrones de las rutas registradas por nombre
+var (
+	namedRoutes   = map[string]string{}
+	namedRoutesMu sync.RWMutex
+)
+
+// RegisterName guarda el patron completo de la ruta (prefijo + path) con su nombre
+// lo llama HandleFuncs, asi los links se construyen con el nombre y no con la url escrita a mano
+func RegisterName(name string, pattern string) {
+	if name == "" {
+		return
+	}
+	namedRoutesMu.Lock()
+	defer namedRoutesMu.Unlock()
+	namedRoutes[name] = pattern
+}
+
+// Pattern retorna el patron de la ruta con ese nombre
+func Pattern(name string) (string, bool) {
+	namedRoutesMu.RLock()
+	defer namedRoutesMu.RUnlock()
+	pattern, ok := namedRoutes[name]
+	return pattern, ok
+}
+
+// URL construye la url de la ruta con ese nombre reemplazando los parametros {id} del patron
+// los parametros van en pares nombre, valor
+//
+//	routesmaker.URL("user-show", "id", "7") // /users/7
+func URL(name string, params ...string) (string, error) {
+	pattern, ok := Pattern(name)
+	if !ok {
+		return "", fmt.Errorf("la ruta '%s' no existe", name)
+	}
+	if len(params)%2 != 0 {
+		return "", fmt.Errorf("los parametros de la ruta '%s' deben ir en pares nombre, valor", name)
+	}
+
+	values := make(map[string]string, len(params)/2)
+	for i := 0; i < len(params); i += 2 {
+		values[params[i]] = params[i+1]
+	}
+
+	var b strings.Builder
+	rest := pattern
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			b.WriteString(rest)
+			break
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			b.WriteString(rest)
+			break
+		}
+		end += start
+		b.WriteString(rest[:start])
+
+		param := rest[start+1 : end]
+		wildcard := strings.HasSuffix(param, "...")
+		param = strings.TrimSuffix(param, "...")
+		if param == "$" {
+			rest = rest[end+1:]
+			continue
+		}
+		value, ok := values[param]
+		if !ok {
+			return "", fmt.Errorf("falta el parametro '%s' de la ruta '%s'", param, name)
+		}
+		if wildcard {
+			segments := strings.Split(value, "/")
+			for i, s := range segments {
+				segments[i] = url.PathEscape(s)
+			}
+			b.WriteString(strings.Join(segments, "/"))
+		} else {
+			b.WriteString(url.PathEscape(value))
+		}
+		rest = rest[end+1:]
+	}
+	return b.String(), nil
+}
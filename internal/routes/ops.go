@@ -0,0 +1,46 @@
+package routes
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/internal/middleware"
+	routesmaker "github.com/donbarrigon/new-project/internal/routes/maker"
+)
+
+// MountOps registra las rutas de diagnostico de produccion bajo prefix: los perfiles de net/http/pprof
+// en prefix/debug/pprof/ y las variables de expvar (memoria, contadores propios) en prefix/debug/vars
+// exponen memoria y codigo del proceso, por eso exige al menos un guard y entra en panico sin ninguno
+//
+//	MountOps("/_ops",
+//		middleware.IPAllowlist("10.0.0.0/8"),
+//		middleware.BearerToken(os.Getenv("OPS_TOKEN")),
+//	)
+//
+//	go tool pprof -http :8000 -H "Authorization: Bearer $OPS_TOKEN" https://app.example.com/_ops/debug/pprof/heap
+func MountOps(prefix string, guards ...middleware.MiddlewareFunc) {
+	if len(guards) == 0 {
+		panic("routes.MountOps: las rutas de diagnostico necesitan al menos un middleware de acceso")
+	}
+	HandleFuncs(prefix, OpsRoutes(prefix), guards...)
+}
+
+// OpsRoutes rutas de pprof y expvar, prefix se quita de la url porque pprof espera /debug/pprof/
+func OpsRoutes(prefix string) []routesmaker.Route {
+	handle := func(h http.Handler) controller.ControllerFunc {
+		h = http.StripPrefix(prefix, h)
+		return func(ctx *controller.Context) {
+			h.ServeHTTP(ctx.Writer, ctx.Request)
+		}
+	}
+	return []routesmaker.Route{
+		{Path: "/debug/pprof/", Methods: routesmaker.AllowMethods(routesmaker.GET), Handler: handle(http.HandlerFunc(pprof.Index)), Name: "ops-pprof"},
+		{Path: "/debug/pprof/cmdline", Methods: routesmaker.AllowMethods(routesmaker.GET), Handler: handle(http.HandlerFunc(pprof.Cmdline))},
+		{Path: "/debug/pprof/profile", Methods: routesmaker.AllowMethods(routesmaker.GET), Handler: handle(http.HandlerFunc(pprof.Profile))},
+		{Path: "/debug/pprof/symbol", Methods: routesmaker.AllowMethods(routesmaker.GET, routesmaker.POST), Handler: handle(http.HandlerFunc(pprof.Symbol))},
+		{Path: "/debug/pprof/trace", Methods: routesmaker.AllowMethods(routesmaker.GET), Handler: handle(http.HandlerFunc(pprof.Trace))},
+		{Path: "/debug/vars", Methods: routesmaker.AllowMethods(routesmaker.GET), Handler: handle(expvar.Handler()), Name: "ops-vars"},
+	}
+}
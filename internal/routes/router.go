@@ -2,7 +2,13 @@ package routes
 
 import (
 	"net/http"
+	"os"
+	"slices"
+	"strings"
 
+	"github.com/donbarrigon/new-project/internal/contract"
+	"github.com/donbarrigon/new-project/internal/debug"
+	"github.com/donbarrigon/new-project/internal/maintenance"
 	"github.com/donbarrigon/new-project/internal/middleware"
 	"github.com/donbarrigon/new-project/internal/pkg/user"
 )
@@ -12,9 +18,26 @@ var router = http.NewServeMux()
 
 func NewRouter() *http.ServeMux {
 
+	// modo mantenimiento, se activa con: go run ./cmd/cli down
+	down := middleware.Maintenance(maintenance.NewFileStore(""))
+
+	// inspector de solicitudes en /_debug y contratos de las respuestas, solo en desarrollo con APP_DEBUG=true
+	var dev []middleware.MiddlewareFunc
+	if os.Getenv("APP_DEBUG") == "true" {
+		contract.Enabled = true
+		store := debug.NewStore(50)
+		router.HandleFunc("/_debug", HandlerAdapter(debug.Handler(store), http.MethodGet))
+		dev = append(dev, middleware.Debug(store))
+	}
+
+	// pprof y expvar en /_ops/debug/, solo si se configura quien puede entrar (OPS_ALLOWLIST y OPS_TOKEN)
+	if allow := os.Getenv("OPS_ALLOWLIST"); allow != "" && os.Getenv("OPS_TOKEN") != "" {
+		MountOps("/_ops", middleware.IPAllowlist(strings.Split(allow, ",")...), middleware.BearerToken(os.Getenv("OPS_TOKEN")))
+	}
+
 	// rutas para pkg de usuario
-	HandleFuncs("/users", user.PublicRoutes())
-	HandleFuncs("/users", user.PrivateRoutes(), middleware.Logger, middleware.Request)
+	HandleFuncs("/users", user.PublicRoutes(), slices.Concat(dev, []middleware.MiddlewareFunc{down})...)
+	HandleFuncs("/users", user.PrivateRoutes(), slices.Concat(dev, []middleware.MiddlewareFunc{down, middleware.ClientIP, middleware.Logger, middleware.Request})...)
 
 	//rutas api standar
 	// HandleFuncs("/api/v1", ApiPublic)
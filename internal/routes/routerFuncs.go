@@ -57,14 +57,33 @@ func HandlerAdapter(handler controller.ControllerFunc, methods ...string) http.H
 func HandleFuncs(prefix string, routes []routesmaker.Route, middlewares ...middleware.MiddlewareFunc) {
 
 	for _, r := range routes {
+		// el contrato envuelve solo al controlador, las respuestas de los middlewares no se revisan
+		handler := r.Handler
+		if r.Contract != nil {
+			handler = middleware.Contract(r.Contract)(handler)
+		}
+
 		// aplicamos los middlewares
-		finalController := Use(r.Handler, middlewares...)
+		finalController := Use(handler, middlewares...)
+
+		// el timeout de la ruta envuelve todos los middlewares para que el deadline aplique a todo
+		if r.Timeout > 0 {
+			finalController = middleware.Timeout(r.Timeout)(finalController)
+		}
+
+		// los early hints van primero para que el navegador descargue mientras corren los middlewares
+		if len(r.Preload) > 0 {
+			finalController = middleware.EarlyHints(r.Preload...)(finalController)
+		}
 
 		// adapto el controller para mux
 		httpHandler := HandlerAdapter(finalController, r.Methods...)
 
 		// Finalmente registrar en el router
 		router.HandleFunc(prefix+r.Path, httpHandler)
+
+		// el nombre permite construir la url de la ruta con routesmaker.URL
+		routesmaker.RegisterName(r.Name, prefix+r.Path)
 	}
 
 }
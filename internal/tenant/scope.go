@@ -0,0 +1,186 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/donbarrigon/new-project/lib/storage"
+)
+
+// Scoped un valor por tenant que se crea la primera vez que se pide (conexiones, clientes de apis)
+// open se llama una sola vez por tenant, los siguientes Get retornan el mismo valor
+//
+//	var conns = tenant.NewScoped(func(ctx context.Context, t *tenant.Tenant) (*sql.DB, error) {
+//		return sql.Open("mysql", t.Config["dsn"])
+//	})
+//
+//	db, err := conns.Get(ctx.Request.Context())
+type Scoped[T any] struct {
+	open   func(ctx context.Context, t *Tenant) (T, error)
+	mu     sync.Mutex
+	values map[string]T
+}
+
+// NewScoped crea el valor por tenant con la funcion que lo abre
+func NewScoped[T any](open func(ctx context.Context, t *Tenant) (T, error)) *Scoped[T] {
+	return &Scoped[T]{open: open, values: map[string]T{}}
+}
+
+// Get retorna el valor del tenant del contexto, ErrMissing si el contexto no tiene tenant
+func (s *Scoped[T]) Get(ctx context.Context) (T, error) {
+	t, ok := FromContext(ctx)
+	if !ok {
+		var zero T
+		return zero, ErrMissing
+	}
+	return s.For(ctx, t)
+}
+
+// For retorna el valor del tenant, lo abre si es la primera vez
+// si open falla no se guarda y el siguiente For lo vuelve a intentar
+func (s *Scoped[T]) For(ctx context.Context, t *Tenant) (T, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if value, ok := s.values[t.ID]; ok {
+		return value, nil
+	}
+	value, err := s.open(ctx, t)
+	if err != nil {
+		return value, err
+	}
+	s.values[t.ID] = value
+	return value, nil
+}
+
+// Forget quita el valor del tenant y lo retorna para cerrarlo (tenant eliminado, cambio de dsn)
+//
+//	if db, ok := conns.Forget("acme"); ok {
+//		db.Close()
+//	}
+func (s *Scoped[T]) Forget(id string) (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.values[id]
+	delete(s.values, id)
+	return value, ok
+}
+
+// Disk disco que guarda los archivos de cada tenant en su propia carpeta (Dir/ID/ruta)
+// el tenant se lee del contexto de cada operacion, sin tenant retorna ErrMissing
+//
+//	upload.Stream(req, upload.Options{Disk: tenant.NewDisk(storage.Default, "tenants"), Dir: "avatars"})
+type Disk struct {
+	Base storage.Disk
+	Dir  string
+}
+
+// NewDisk crea el disco por tenant sobre base, si dir es "" se usa "tenants"
+func NewDisk(base storage.Disk, dir string) *Disk {
+	if dir == "" {
+		dir = "tenants"
+	}
+	return &Disk{Base: base, Dir: dir}
+}
+
+// path retorna la ruta dentro de la carpeta del tenant del contexto
+func (d *Disk) path(ctx context.Context, p string) (string, error) {
+	t, ok := FromContext(ctx)
+	if !ok {
+		return "", ErrMissing
+	}
+	if t.ID == "" || t.ID == "." || t.ID == ".." || strings.ContainsAny(t.ID, `/\`) {
+		return "", storage.ErrInvalidPath
+	}
+	return path.Join(d.Dir, t.ID, path.Clean("/"+p)), nil
+}
+
+func (d *Disk) Put(ctx context.Context, p string, r io.Reader) (int64, error) {
+	full, err := d.path(ctx, p)
+	if err != nil {
+		return 0, err
+	}
+	return d.Base.Put(ctx, full, r)
+}
+
+func (d *Disk) Open(ctx context.Context, p string) (io.ReadCloser, error) {
+	full, err := d.path(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	return d.Base.Open(ctx, full)
+}
+
+func (d *Disk) Size(ctx context.Context, p string) (int64, error) {
+	full, err := d.path(ctx, p)
+	if err != nil {
+		return 0, err
+	}
+	return d.Base.Size(ctx, full)
+}
+
+func (d *Disk) Delete(ctx context.Context, p string) error {
+	full, err := d.path(ctx, p)
+	if err != nil {
+		return err
+	}
+	return d.Base.Delete(ctx, full)
+}
+
+// Append agrega al archivo del tenant, falla si Base no implementa storage.Appender
+func (d *Disk) Append(ctx context.Context, p string, r io.Reader) (int64, error) {
+	appender, ok := d.Base.(storage.Appender)
+	if !ok {
+		return 0, errors.New("el disco no permite agregar contenido a un archivo")
+	}
+	full, err := d.path(ctx, p)
+	if err != nil {
+		return 0, err
+	}
+	return appender.Append(ctx, full, r)
+}
+
+// Stat retorna los datos del archivo del tenant, errors.ErrUnsupported si Base no implementa storage.Statter
+func (d *Disk) Stat(ctx context.Context, p string) (storage.FileInfo, error) {
+	statter, ok := d.Base.(storage.Statter)
+	if !ok {
+		return storage.FileInfo{}, errors.ErrUnsupported
+	}
+	full, err := d.path(ctx, p)
+	if err != nil {
+		return storage.FileInfo{}, err
+	}
+	return statter.Stat(ctx, full)
+}
+
+// OpenRange lee una parte del archivo del tenant, errors.ErrUnsupported si Base no implementa storage.RangeReader
+// (response.File responde los rangos leyendo el archivo completo)
+func (d *Disk) OpenRange(ctx context.Context, p string, offset int64, length int64) (io.ReadCloser, error) {
+	ranger, ok := d.Base.(storage.RangeReader)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+	full, err := d.path(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	return ranger.OpenRange(ctx, full, offset, length)
+}
+
+// TemporaryURL enlace temporal del archivo del tenant, errors.ErrUnsupported si Base no implementa storage.TemporaryURLer
+// la ruta del enlace ya incluye la carpeta del tenant, la ruta que lo sirve usa Base
+func (d *Disk) TemporaryURL(ctx context.Context, p string, expires time.Time) (string, error) {
+	t, ok := d.Base.(storage.TemporaryURLer)
+	if !ok {
+		return "", errors.ErrUnsupported
+	}
+	full, err := d.path(ctx, p)
+	if err != nil {
+		return "", err
+	}
+	return t.TemporaryURL(ctx, full, expires)
+}
@@ -0,0 +1,113 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/donbarrigon/new-project/lib/storage"
+)
+
+func TestScoped(t *testing.T) {
+	opened := map[string]int{}
+	fail := true
+	conns := NewScoped(func(ctx context.Context, tn *Tenant) (string, error) {
+		opened[tn.ID]++
+		if tn.ID == "globex" && fail {
+			return "", errors.New("sin conexion")
+		}
+		return "db-" + tn.ID, nil
+	})
+	acme := WithTenant(context.Background(), &Tenant{ID: "acme"})
+
+	for i := 0; i < 3; i++ {
+		if db, err := conns.Get(acme); err != nil || db != "db-acme" {
+			t.Fatalf("se esperaba db-acme, se obtuvo %q %v", db, err)
+		}
+	}
+	if opened["acme"] != 1 {
+		t.Errorf("open se debe llamar una vez por tenant, se llamo %d", opened["acme"])
+	}
+
+	globex := &Tenant{ID: "globex"}
+	if _, err := conns.For(context.Background(), globex); err == nil {
+		t.Errorf("se esperaba el error de open")
+	}
+	fail = false
+	if db, err := conns.For(context.Background(), globex); err != nil || db != "db-globex" || opened["globex"] != 2 {
+		t.Errorf("un open que fallo se debe reintentar, se obtuvo %q %v", db, err)
+	}
+
+	if db, ok := conns.Forget("acme"); !ok || db != "db-acme" {
+		t.Errorf("Forget debe retornar el valor del tenant, se obtuvo %q %v", db, ok)
+	}
+	if _, ok := conns.Forget("acme"); ok {
+		t.Errorf("el tenant ya no debe tener valor")
+	}
+	conns.Get(acme)
+	if opened["acme"] != 2 {
+		t.Errorf("despues de Forget se debe abrir de nuevo, se abrio %d veces", opened["acme"])
+	}
+
+	if _, err := conns.Get(context.Background()); !errors.Is(err, ErrMissing) {
+		t.Errorf("sin tenant se esperaba ErrMissing, se obtuvo %v", err)
+	}
+}
+
+func TestDiskPaths(t *testing.T) {
+	root := t.TempDir()
+	disk := NewDisk(storage.NewLocal(root), "")
+	cases := []struct {
+		name   string
+		tenant *Tenant
+		path   string
+		file   string // ruta esperada dentro de root
+		err    error
+	}{
+		{"ruta normal", &Tenant{ID: "acme"}, "docs/a.txt", "tenants/acme/docs/a.txt", nil},
+		{"no sale de la carpeta", &Tenant{ID: "acme"}, "../../globex/docs/b.txt", "tenants/acme/globex/docs/b.txt", nil},
+		{"ruta absoluta", &Tenant{ID: "acme"}, "/docs/c.txt", "tenants/acme/docs/c.txt", nil},
+		{"sin tenant", nil, "docs/a.txt", "", ErrMissing},
+		{"id vacio", &Tenant{ID: ""}, "docs/a.txt", "", storage.ErrInvalidPath},
+		{"id ..", &Tenant{ID: ".."}, "docs/a.txt", "", storage.ErrInvalidPath},
+		{"id con barra", &Tenant{ID: "acme/../globex"}, "docs/a.txt", "", storage.ErrInvalidPath},
+		{"id con barra invertida", &Tenant{ID: `acme\globex`}, "docs/a.txt", "", storage.ErrInvalidPath},
+	}
+	for _, c := range cases {
+		ctx := context.Background()
+		if c.tenant != nil {
+			ctx = WithTenant(ctx, c.tenant)
+		}
+		_, err := disk.Put(ctx, c.path, strings.NewReader(c.name))
+		if !errors.Is(err, c.err) {
+			t.Errorf("%s: se obtuvo el error %v, se esperaba %v", c.name, err, c.err)
+			continue
+		}
+		if c.err != nil {
+			continue
+		}
+		if got, err := os.ReadFile(filepath.Join(root, c.file)); err != nil || string(got) != c.name {
+			t.Errorf("%s: se esperaba el archivo en %s: %v", c.name, c.file, err)
+		}
+		rc, err := disk.Open(ctx, c.path)
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		got, _ := io.ReadAll(rc)
+		rc.Close()
+		if string(got) != c.name {
+			t.Errorf("%s: se leyo %q", c.name, got)
+		}
+	}
+
+	// un tenant no ve los archivos de otro
+	globex := WithTenant(context.Background(), &Tenant{ID: "globex"})
+	if _, err := disk.Open(globex, "docs/a.txt"); err == nil {
+		t.Errorf("globex no debe poder abrir el archivo de acme")
+	}
+}
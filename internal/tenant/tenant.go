@@ -0,0 +1,219 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/donbarrigon/new-project/lib/ctxkey"
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// Tenant cliente de la aplicacion multi-tenant, cada uno con sus datos, archivos y conexiones
+// Config guarda lo que cambia por tenant (dsn de la base de datos, bucket, plan)
+type Tenant struct {
+	ID     string            `json:"id"`
+	Name   string            `json:"name,omitempty"`
+	Domain string            `json:"domain,omitempty"`
+	Config map[string]string `json:"config,omitempty"`
+}
+
+var (
+	// ErrMissing el request no indica el tenant (sin subdominio, cabecera o segmento de la ruta)
+	ErrMissing = errors.New("no se indicó el tenant")
+	// ErrNotFound el tenant que indica el request no existe
+	ErrNotFound = errors.New("el tenant no existe")
+)
+
+// Codigos de los errores del tenant en las respuestas
+const (
+	CodeMissing  = "TENANT_MISSING"
+	CodeNotFound = "TENANT_NOT_FOUND"
+)
+
+func init() {
+	validation.RegisterErrorCode(ErrMissing, CodeMissing, "la solicitud no indica el tenant")
+	validation.RegisterErrorCode(ErrNotFound, CodeNotFound, "el tenant de la solicitud no existe")
+}
+
+// Key llave del contexto con el tenant del request
+var Key = ctxkey.New[*Tenant]("tenant")
+
+// WithTenant guarda el tenant en el contexto
+func WithTenant(ctx context.Context, t *Tenant) context.Context {
+	return ctxkey.Set(ctx, Key, t)
+}
+
+// FromContext retorna el tenant que resolvio el middleware Tenant, las reglas lo leen de f.Context
+//
+//	t, ok := tenant.FromContext(f.Context)
+func FromContext(ctx context.Context) (*Tenant, bool) {
+	t, ok := ctxkey.Get(ctx, Key)
+	return t, ok && t != nil
+}
+
+// FromRequest retorna el tenant del request
+func FromRequest(req *http.Request) (*Tenant, bool) {
+	return FromContext(req.Context())
+}
+
+// Source lee la llave del tenant del request, retorna "" si el request no la tiene
+type Source func(req *http.Request) string
+
+// Subdomain lee el tenant del subdominio: con base "example.com" acme.example.com es acme
+// el dominio base y www no son un tenant
+func Subdomain(base string) Source {
+	base = "." + strings.ToLower(strings.Trim(base, "."))
+	return func(req *http.Request) string {
+		host := strings.ToLower(req.Host)
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		sub, ok := strings.CutSuffix(host, base)
+		if !ok || sub == "" || sub == "www" || strings.Contains(sub, ".") {
+			return ""
+		}
+		return sub
+	}
+}
+
+// Header lee el tenant de una cabecera (X-Tenant: acme)
+func Header(name string) Source {
+	return func(req *http.Request) string {
+		return strings.TrimSpace(req.Header.Get(name))
+	}
+}
+
+// PathPrefix lee el tenant del segmento que sigue al prefijo: con "/t/" la ruta /t/acme/users es acme
+// las rutas se registran con el segmento, el middleware no lo quita
+func PathPrefix(prefix string) Source {
+	prefix = "/" + strings.Trim(prefix, "/") + "/"
+	if prefix == "//" {
+		prefix = "/"
+	}
+	return func(req *http.Request) string {
+		rest, ok := strings.CutPrefix(req.URL.Path, prefix)
+		if !ok {
+			return ""
+		}
+		key, _, _ := strings.Cut(rest, "/")
+		return key
+	}
+}
+
+// Store busca el tenant por la llave que envio el request, retorna ErrNotFound si no existe
+// implemente esta interfaz para buscarlo en la base de datos o en un servicio de configuracion
+type Store interface {
+	Find(ctx context.Context, key string) (*Tenant, error)
+}
+
+// MemoryStore tenants fijos, se buscan por ID o por Domain
+type MemoryStore struct {
+	mu      sync.RWMutex
+	tenants map[string]*Tenant
+}
+
+// NewMemoryStore crea el store con los tenants
+//
+//	store := tenant.NewMemoryStore(
+//		&tenant.Tenant{ID: "acme", Name: "Acme"},
+//		&tenant.Tenant{ID: "globex", Domain: "globex.com"},
+//	)
+func NewMemoryStore(tenants ...*Tenant) *MemoryStore {
+	s := &MemoryStore{tenants: make(map[string]*Tenant, len(tenants))}
+	for _, t := range tenants {
+		s.Add(t)
+	}
+	return s
+}
+
+// Add agrega o reemplaza el tenant
+func (s *MemoryStore) Add(t *Tenant) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tenants[t.ID] = t
+	if t.Domain != "" {
+		s.tenants[strings.ToLower(t.Domain)] = t
+	}
+}
+
+func (s *MemoryStore) Find(ctx context.Context, key string) (*Tenant, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if t, ok := s.tenants[key]; ok {
+		return t, nil
+	}
+	if t, ok := s.tenants[strings.ToLower(key)]; ok {
+		return t, nil
+	}
+	return nil, ErrNotFound
+}
+
+// Resolver resuelve el tenant del request probando las fuentes en orden, la primera que tenga la llave gana
+//
+//	resolver := &tenant.Resolver{
+//		Sources: []tenant.Source{tenant.Subdomain("example.com"), tenant.Header("X-Tenant")},
+//		Store:   store,
+//	}
+type Resolver struct {
+	Sources  []Source
+	Store    Store // nil usa la llave como ID sin buscarla
+	Optional bool  // sin llave el request sigue sin tenant, si es false Resolve retorna ErrMissing
+}
+
+// Resolve retorna el tenant del request, nil sin error si es Optional y el request no indica ninguno
+func (r *Resolver) Resolve(req *http.Request) (*Tenant, error) {
+	key := ""
+	for _, source := range r.Sources {
+		if key = source(req); key != "" {
+			break
+		}
+	}
+	if key == "" {
+		if r.Optional {
+			return nil, nil
+		}
+		return nil, ErrMissing
+	}
+	if r.Store == nil {
+		return &Tenant{ID: key}, nil
+	}
+	return r.Store.Find(req.Context(), key)
+}
+
+// resolveHooks funciones que preparan el contexto de cada request con su tenant
+var resolveHooks []func(ctx context.Context, t *Tenant) (context.Context, error)
+
+// OnResolve registra una funcion que se llama cuando el middleware Tenant resuelve el tenant
+// el contexto que retorna es el del request, asi se pueden guardar la conexion, el disco o la configuracion del tenant
+// si retorna error el request termina con 500. se debe llamar al iniciar la aplicacion
+//
+//	tenant.OnResolve(func(ctx context.Context, t *tenant.Tenant) (context.Context, error) {
+//		db, err := conns.For(ctx, t) // conns es un tenant.Scoped[*sql.DB]
+//		if err != nil {
+//			return ctx, err
+//		}
+//		return context.WithValue(ctx, dbKey{}, db), nil
+//	})
+func OnResolve(hook func(ctx context.Context, t *Tenant) (context.Context, error)) {
+	resolveHooks = append(resolveHooks, hook)
+}
+
+// Prepare guarda el tenant en el contexto y ejecuta los hooks de OnResolve, lo usa el middleware Tenant
+// sirve tambien para los procesos sin request (colas, comandos) que trabajan a nombre de un tenant
+func Prepare(ctx context.Context, t *Tenant) (context.Context, error) {
+	ctx = WithTenant(ctx, t)
+	for _, hook := range resolveHooks {
+		next, err := hook(ctx, t)
+		if err != nil {
+			return ctx, err
+		}
+		if next != nil {
+			ctx = next
+		}
+	}
+	return ctx, nil
+}
@@ -0,0 +1,130 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSources(t *testing.T) {
+	cases := []struct {
+		name   string
+		source Source
+		target string
+		header string
+		want   string
+	}{
+		{"subdominio", Subdomain("example.com"), "http://acme.example.com/users", "", "acme"},
+		{"subdominio con puerto", Subdomain(".Example.com."), "http://ACME.example.com:8080/users", "", "acme"},
+		{"dominio base", Subdomain("example.com"), "http://example.com/users", "", ""},
+		{"www", Subdomain("example.com"), "http://www.example.com/users", "", ""},
+		{"dos niveles", Subdomain("example.com"), "http://a.b.example.com/users", "", ""},
+		{"otro dominio", Subdomain("example.com"), "http://acme.example.org/users", "", ""},
+		{"sufijo sin punto", Subdomain("example.com"), "http://acmeexample.com/users", "", ""},
+		{"cabecera", Header("X-Tenant"), "/users", " acme ", "acme"},
+		{"sin cabecera", Header("X-Tenant"), "/users", "", ""},
+		{"prefijo", PathPrefix("/t/"), "/t/acme/users", "", "acme"},
+		{"prefijo sin barras", PathPrefix("t"), "/t/acme", "", "acme"},
+		{"prefijo raiz", PathPrefix("/"), "/acme/users", "", "acme"},
+		{"otra ruta", PathPrefix("/t/"), "/users/acme", "", ""},
+		{"prefijo sin segmento", PathPrefix("/t/"), "/t", "", ""},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest("GET", c.target, nil)
+		if c.header != "" {
+			req.Header.Set("X-Tenant", c.header)
+		}
+		if got := c.source(req); got != c.want {
+			t.Errorf("%s: se obtuvo %q, se esperaba %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore(&Tenant{ID: "acme"}, &Tenant{ID: "globex", Domain: "Globex.com"})
+	cases := []struct {
+		key  string
+		want string
+		err  error
+	}{
+		{"acme", "acme", nil},
+		{"ACME", "acme", nil},
+		{"globex.com", "globex", nil},
+		{"GLOBEX.COM", "globex", nil},
+		{"initech", "", ErrNotFound},
+		{"", "", ErrNotFound},
+	}
+	for _, c := range cases {
+		got, err := store.Find(context.Background(), c.key)
+		if !errors.Is(err, c.err) || (got != nil && got.ID != c.want) {
+			t.Errorf("%q: se obtuvo %v %v, se esperaba %q %v", c.key, got, err, c.want, c.err)
+		}
+	}
+}
+
+func TestResolver(t *testing.T) {
+	store := NewMemoryStore(&Tenant{ID: "acme", Name: "Acme"})
+	sources := []Source{Subdomain("example.com"), Header("X-Tenant")}
+	cases := []struct {
+		name     string
+		resolver *Resolver
+		host     string
+		header   string
+		want     string
+		err      error
+	}{
+		{"subdominio", &Resolver{Sources: sources, Store: store}, "acme.example.com", "", "acme", nil},
+		{"gana la primera fuente", &Resolver{Sources: sources, Store: store}, "acme.example.com", "globex", "acme", nil},
+		{"segunda fuente", &Resolver{Sources: sources, Store: store}, "example.com", "acme", "acme", nil},
+		{"no existe", &Resolver{Sources: sources, Store: store}, "globex.example.com", "", "", ErrNotFound},
+		{"sin llave", &Resolver{Sources: sources, Store: store}, "example.com", "", "", ErrMissing},
+		{"opcional sin llave", &Resolver{Sources: sources, Store: store, Optional: true}, "example.com", "", "", nil},
+		{"sin store", &Resolver{Sources: sources}, "globex.example.com", "", "globex", nil},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest("GET", "/users", nil)
+		req.Host = c.host
+		if c.header != "" {
+			req.Header.Set("X-Tenant", c.header)
+		}
+		got, err := c.resolver.Resolve(req)
+		if !errors.Is(err, c.err) {
+			t.Errorf("%s: se obtuvo el error %v, se esperaba %v", c.name, err, c.err)
+			continue
+		}
+		id := ""
+		if got != nil {
+			id = got.ID
+		}
+		if id != c.want {
+			t.Errorf("%s: se obtuvo %q, se esperaba %q", c.name, id, c.want)
+		}
+	}
+}
+
+func TestPrepare(t *testing.T) {
+	type hookKey struct{}
+	failing := errors.New("sin conexion")
+	OnResolve(func(ctx context.Context, tn *Tenant) (context.Context, error) {
+		if tn.ID == "roto" {
+			return ctx, failing
+		}
+		return context.WithValue(ctx, hookKey{}, "db-"+tn.ID), nil
+	})
+	t.Cleanup(func() { resolveHooks = resolveHooks[:len(resolveHooks)-1] })
+
+	ctx, err := Prepare(context.Background(), &Tenant{ID: "acme"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := FromContext(ctx); !ok || got.ID != "acme" || ctx.Value(hookKey{}) != "db-acme" {
+		t.Errorf("el contexto debe tener el tenant y lo que agrego el hook, se obtuvo %v %v", got, ctx.Value(hookKey{}))
+	}
+	if _, err := Prepare(context.Background(), &Tenant{ID: "roto"}); !errors.Is(err, failing) {
+		t.Errorf("se esperaba el error del hook, se obtuvo %v", err)
+	}
+	if _, ok := FromContext(WithTenant(context.Background(), nil)); ok {
+		t.Errorf("un tenant nil en el contexto no es un tenant")
+	}
+}
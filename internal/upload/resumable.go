@@ -0,0 +1,385 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/lib/storage"
+)
+
+// TusVersion version del protocolo tus que implementa Resumable
+const TusVersion = "1.0.0"
+
+// Resumable subidas reanudables con el protocolo tus (https://tus.io): el cliente crea la subida,
+// envia el archivo por partes con PATCH y si la conexion se cae pregunta con HEAD desde donde continuar
+// el disco debe implementar storage.Appender (storage.Local lo implementa)
+//
+//	uploads := &upload.Resumable{
+//		Dir:          "videos",
+//		MaxSize:      2 << 30,
+//		AllowedTypes: []string{"video/*"},
+//		OnComplete: func(ctx context.Context, file *upload.UploadedFile) error {
+//			return video.Enqueue(ctx, file.Path)
+//		},
+//	}
+//	{Path: "/uploads", Methods: AllowMethods(POST), Handler: uploads.Handler()},
+//	{Path: "/uploads/{id}", Methods: AllowMethods(HEAD, PATCH, DELETE), Handler: uploads.Handler()},
+type Resumable struct {
+	Disk         storage.Disk // storage.Default si es nil
+	Dir          string
+	MaxSize      int64
+	AllowedTypes []string
+	Scanner      Scanner // antivirus que revisa el archivo completo, DefaultScanner si es nil
+
+	// OnComplete se llama cuando llega el ultimo byte del archivo
+	OnComplete func(ctx context.Context, file *UploadedFile) error
+
+	// busy subidas que estan recibiendo una parte, una sola PATCH a la vez por subida
+	// la entrada solo existe mientras dura la PATCH
+	busyMu sync.Mutex
+	busy   map[string]bool
+}
+
+// resumableInfo estado de la subida, se guarda junto al archivo en id.info
+type resumableInfo struct {
+	Length   int64             `json:"length"`
+	Name     string            `json:"name"`
+	MimeType string            `json:"mime_type"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Handler atiende las peticiones tus segun el metodo, la ruta de las partes debe tener el parametro {id}
+func (t *Resumable) Handler() controller.ControllerFunc {
+	return func(ctx *controller.Context) {
+		ctx.Writer.Header().Set("Tus-Resumable", TusVersion)
+		if ctx.Request.Method != http.MethodOptions && ctx.Request.Header.Get("Tus-Resumable") != TusVersion {
+			ctx.Writer.Header().Set("Tus-Version", TusVersion)
+			ctx.ResponseError(http.StatusPreconditionFailed, "versión de tus no soportada", nil)
+			return
+		}
+		switch ctx.Request.Method {
+		case http.MethodOptions:
+			t.options(ctx)
+		case http.MethodPost:
+			t.create(ctx)
+		case http.MethodHead:
+			t.head(ctx)
+		case http.MethodPatch:
+			t.patch(ctx)
+		case http.MethodDelete:
+			t.terminate(ctx)
+		default:
+			ctx.ResponseError(http.StatusMethodNotAllowed, "Method not allowed", nil)
+		}
+	}
+}
+
+// File retorna el archivo de una subida que ya termino, se usa al recibir el formulario que referencia el id
+func (t *Resumable) File(ctx context.Context, id string) (*UploadedFile, error) {
+	if !validID(id) {
+		return nil, storage.ErrNotFound
+	}
+	info, err := t.info(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	size, err := t.disk().Size(ctx, t.dataPath(id))
+	if err != nil {
+		return nil, err
+	}
+	if size != info.Length {
+		return nil, errors.New("la subida no ha terminado")
+	}
+	return &UploadedFile{Field: id, Name: info.Name, Path: t.dataPath(id), Size: size, MimeType: info.MimeType, Disk: t.disk()}, nil
+}
+
+func (t *Resumable) options(ctx *controller.Context) {
+	header := ctx.Writer.Header()
+	header.Set("Tus-Version", TusVersion)
+	header.Set("Tus-Extension", "creation,termination")
+	if t.MaxSize > 0 {
+		header.Set("Tus-Max-Size", strconv.FormatInt(t.MaxSize, 10))
+	}
+	ctx.ResponseNoContent()
+}
+
+// create POST: registra la subida con su tamaño total y responde la url donde se envian las partes
+func (t *Resumable) create(ctx *controller.Context) {
+	if _, ok := t.disk().(storage.Appender); !ok {
+		ctx.ResponseError(http.StatusInternalServerError, "el disco no permite subidas reanudables", nil)
+		return
+	}
+	length, err := strconv.ParseInt(ctx.Request.Header.Get("Upload-Length"), 10, 64)
+	if err != nil || length <= 0 {
+		ctx.ResponseError(http.StatusBadRequest, "Upload-Length debe ser el tamaño del archivo en bytes", nil)
+		return
+	}
+	if t.MaxSize > 0 && length > t.MaxSize {
+		ctx.ResponseError(http.StatusRequestEntityTooLarge, fmt.Sprintf("el archivo no puede pesar más de %d bytes", t.MaxSize), nil)
+		return
+	}
+
+	metadata := parseMetadata(ctx.Request.Header.Get("Upload-Metadata"))
+	info := resumableInfo{Length: length, Name: baseName(metadata["filename"]), Metadata: metadata}
+	id := RandomName()
+	if err := t.saveInfo(ctx.Request.Context(), id, info); err != nil {
+		ctx.ResponseError(http.StatusInternalServerError, "no se pudo crear la subida", nil)
+		return
+	}
+
+	location := strings.TrimSuffix(ctx.Request.URL.Path, "/") + "/" + id
+	ctx.Writer.Header().Set("Location", location)
+	ctx.Writer.WriteHeader(http.StatusCreated)
+}
+
+// head HEAD: responde cuantos bytes ya se recibieron para continuar desde ahi
+func (t *Resumable) head(ctx *controller.Context) {
+	id := ctx.Request.PathValue("id")
+	info, offset, ok := t.state(ctx, id)
+	if !ok {
+		return
+	}
+	header := ctx.Writer.Header()
+	header.Set("Upload-Offset", strconv.FormatInt(offset, 10))
+	header.Set("Upload-Length", strconv.FormatInt(info.Length, 10))
+	header.Set("Cache-Control", "no-store")
+	ctx.Writer.WriteHeader(http.StatusOK)
+}
+
+// patch PATCH: agrega una parte del archivo, Upload-Offset debe coincidir con lo que ya se recibio
+func (t *Resumable) patch(ctx *controller.Context) {
+	if ctx.Request.Header.Get("Content-Type") != "application/offset+octet-stream" {
+		ctx.ResponseError(http.StatusUnsupportedMediaType, "el Content-Type debe ser application/offset+octet-stream", nil)
+		return
+	}
+	id := ctx.Request.PathValue("id")
+	if !validID(id) {
+		ctx.ResponseError(http.StatusNotFound, "la subida no existe", nil)
+		return
+	}
+	if _, err := t.info(ctx.Request.Context(), id); err != nil {
+		ctx.ResponseError(http.StatusNotFound, "la subida no existe", nil)
+		return
+	}
+	if !t.acquire(id) {
+		ctx.ResponseError(http.StatusConflict, "la subida ya está recibiendo otra parte", nil)
+		return
+	}
+	defer t.release(id)
+
+	// el estado se vuelve a leer con la subida tomada, otra PATCH pudo terminar antes
+	info, offset, ok := t.state(ctx, id)
+	if !ok {
+		return
+	}
+	requested, err := strconv.ParseInt(ctx.Request.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || requested != offset {
+		ctx.Writer.Header().Set("Upload-Offset", strconv.FormatInt(offset, 10))
+		ctx.ResponseError(http.StatusConflict, "Upload-Offset no coincide con lo recibido", nil)
+		return
+	}
+
+	rctx := ctx.Request.Context()
+	var body io.Reader = ctx.Request.Body
+	// el tipo se valida con la primera parte antes de escribir nada
+	if offset == 0 {
+		head := make([]byte, sniffLen)
+		n, err := io.ReadFull(body, head)
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			ctx.ResponseError(http.StatusBadRequest, "no se pudo leer la parte del archivo", nil)
+			return
+		}
+		head = head[:n]
+		info.MimeType = DetectType(head)
+		if !Allowed(info.MimeType, t.AllowedTypes) {
+			t.delete(rctx, id)
+			ctx.ResponseError(http.StatusUnsupportedMediaType, fmt.Sprintf("el tipo de archivo %s no está permitido", info.MimeType), nil)
+			return
+		}
+		if err := t.saveInfo(rctx, id, info); err != nil {
+			ctx.ResponseError(http.StatusInternalServerError, "no se pudo guardar la subida", nil)
+			return
+		}
+		body = io.MultiReader(bytes.NewReader(head), body)
+	}
+
+	// nunca se escribe mas de lo que se declaro al crear la subida
+	remaining := info.Length - offset
+	src := &meteredReader{r: body, max: remaining}
+	written, err := t.disk().(storage.Appender).Append(rctx, t.dataPath(id), src)
+	offset += written
+	ctx.Writer.Header().Set("Upload-Offset", strconv.FormatInt(offset, 10))
+	if errors.Is(err, ErrTooLarge) {
+		ctx.ResponseError(http.StatusRequestEntityTooLarge, "la parte supera el tamaño declarado del archivo", nil)
+		return
+	}
+	if err != nil {
+		// lo que alcanzo a llegar queda guardado, el cliente continua desde Upload-Offset
+		ctx.ResponseError(http.StatusInternalServerError, "no se pudo guardar la parte del archivo", nil)
+		return
+	}
+
+	if offset == info.Length {
+		file := &UploadedFile{Field: id, Name: info.Name, Path: t.dataPath(id), Size: offset, MimeType: info.MimeType, Disk: t.disk()}
+		if !t.complete(ctx, file) {
+			return
+		}
+	}
+	ctx.Writer.WriteHeader(http.StatusNoContent)
+}
+
+// complete revisa el archivo terminado con el antivirus y llama OnComplete, responde el error si algo falla
+func (t *Resumable) complete(ctx *controller.Context, file *UploadedFile) bool {
+	rctx := ctx.Request.Context()
+	scanner := t.Scanner
+	if scanner == nil {
+		scanner = DefaultScanner
+	}
+	if err := scanFiles(rctx, scanner, []*UploadedFile{file}); err != nil {
+		var infected *InfectedError
+		if errors.As(err, &infected) {
+			t.delete(rctx, file.Field)
+			ctx.ResponseError(http.StatusUnprocessableEntity, err.Error(), map[string]string{"code": infected.Code})
+			return false
+		}
+		ctx.ResponseError(http.StatusServiceUnavailable, "no se pudo revisar el archivo", nil)
+		return false
+	}
+	if t.OnComplete != nil {
+		if err := t.OnComplete(rctx, file); err != nil {
+			ctx.ResponseError(http.StatusUnprocessableEntity, err.Error(), nil)
+			return false
+		}
+	}
+	return true
+}
+
+// terminate DELETE: cancela la subida y borra lo recibido
+func (t *Resumable) terminate(ctx *controller.Context) {
+	id := ctx.Request.PathValue("id")
+	if _, _, ok := t.state(ctx, id); !ok {
+		return
+	}
+	t.delete(ctx.Request.Context(), id)
+	ctx.ResponseNoContent()
+}
+
+// acquire marca la subida como ocupada, false si otra PATCH la esta usando
+func (t *Resumable) acquire(id string) bool {
+	t.busyMu.Lock()
+	defer t.busyMu.Unlock()
+	if t.busy[id] {
+		return false
+	}
+	if t.busy == nil {
+		t.busy = map[string]bool{}
+	}
+	t.busy[id] = true
+	return true
+}
+
+// release libera la subida al terminar la PATCH
+func (t *Resumable) release(id string) {
+	t.busyMu.Lock()
+	defer t.busyMu.Unlock()
+	delete(t.busy, id)
+}
+
+// state lee el estado de la subida y responde 404 si no existe
+func (t *Resumable) state(ctx *controller.Context, id string) (resumableInfo, int64, bool) {
+	if !validID(id) {
+		ctx.ResponseError(http.StatusNotFound, "la subida no existe", nil)
+		return resumableInfo{}, 0, false
+	}
+	info, err := t.info(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.ResponseError(http.StatusNotFound, "la subida no existe", nil)
+		return resumableInfo{}, 0, false
+	}
+	offset, err := t.disk().Size(ctx.Request.Context(), t.dataPath(id))
+	if errors.Is(err, storage.ErrNotFound) {
+		offset, err = 0, nil
+	}
+	if err != nil {
+		ctx.ResponseError(http.StatusInternalServerError, "no se pudo leer la subida", nil)
+		return resumableInfo{}, 0, false
+	}
+	return info, offset, true
+}
+
+func (t *Resumable) info(ctx context.Context, id string) (resumableInfo, error) {
+	var info resumableInfo
+	r, err := t.disk().Open(ctx, t.dataPath(id)+".info")
+	if err != nil {
+		return info, err
+	}
+	defer r.Close()
+	err = json.NewDecoder(r).Decode(&info)
+	return info, err
+}
+
+func (t *Resumable) saveInfo(ctx context.Context, id string, info resumableInfo) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	_, err = t.disk().Put(ctx, t.dataPath(id)+".info", bytes.NewReader(data))
+	return err
+}
+
+func (t *Resumable) delete(ctx context.Context, id string) {
+	t.disk().Delete(ctx, t.dataPath(id))
+	t.disk().Delete(ctx, t.dataPath(id)+".info")
+}
+
+func (t *Resumable) dataPath(id string) string {
+	return path.Join(t.Dir, id)
+}
+
+func (t *Resumable) disk() storage.Disk {
+	if t.Disk == nil {
+		return storage.Default
+	}
+	return t.Disk
+}
+
+// validID los ids son los nombres aleatorios de RandomName, cualquier otra cosa no se busca en el disco
+func validID(id string) bool {
+	if len(id) != 32 {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// parseMetadata lee Upload-Metadata: pares "llave valor_en_base64" separados por comas
+func parseMetadata(header string) map[string]string {
+	metadata := map[string]string{}
+	for _, pair := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			continue
+		}
+		metadata[key] = string(decoded)
+	}
+	return metadata
+}
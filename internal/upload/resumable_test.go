@@ -0,0 +1,70 @@
+package upload
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/donbarrigon/new-project/internal/controller"
+	"github.com/donbarrigon/new-project/lib/storage"
+)
+
+// tusRequest atiende una peticion tus con el handler, id va en el parametro {id} de la ruta
+func tusRequest(t *testing.T, r *Resumable, method string, id string, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/uploads/"+id, strings.NewReader(body))
+	if id != "" {
+		req.SetPathValue("id", id)
+	}
+	req.Header.Set("Tus-Resumable", TusVersion)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	rec := httptest.NewRecorder()
+	r.Handler()(controller.NewContext(rec, req))
+	return rec
+}
+
+func TestResumablePatchDoesNotKeepLocks(t *testing.T) {
+	r := &Resumable{Disk: storage.NewLocal(t.TempDir()), Dir: "uploads"}
+	content := "hola mundo, esto es texto plano"
+
+	// ids que no existen no dejan entradas
+	for _, id := range []string{"../../etc/passwd", strings.Repeat("a", 32)} {
+		rec := tusRequest(t, r, http.MethodPatch, id, "x", map[string]string{"Content-Type": "application/offset+octet-stream", "Upload-Offset": "0"})
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("PATCH %s: status %d, se esperaba 404", id, rec.Code)
+		}
+	}
+	if len(r.busy) != 0 {
+		t.Errorf("las PATCH de subidas inexistentes dejaron %d entradas", len(r.busy))
+	}
+
+	rec := tusRequest(t, r, http.MethodPost, "", "", map[string]string{"Upload-Length": strconv.Itoa(len(content))})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST: status %d", rec.Code)
+	}
+	location := rec.Header().Get("Location")
+	id := location[strings.LastIndexByte(location, '/')+1:]
+
+	rec = tusRequest(t, r, http.MethodPatch, id, content[:10], map[string]string{"Content-Type": "application/offset+octet-stream", "Upload-Offset": "0"})
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Upload-Offset") != "10" {
+		t.Fatalf("primera parte: status %d, offset %s", rec.Code, rec.Header().Get("Upload-Offset"))
+	}
+	rec = tusRequest(t, r, http.MethodPatch, id, content[10:], map[string]string{"Content-Type": "application/offset+octet-stream", "Upload-Offset": "10"})
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Upload-Offset") != strconv.Itoa(len(content)) {
+		t.Fatalf("ultima parte: status %d, offset %s", rec.Code, rec.Header().Get("Upload-Offset"))
+	}
+	if len(r.busy) != 0 {
+		t.Errorf("la subida terminada dejo %d entradas", len(r.busy))
+	}
+
+	// mientras otra PATCH la usa se responde 409
+	r.acquire(id)
+	rec = tusRequest(t, r, http.MethodPatch, id, "x", map[string]string{"Content-Type": "application/offset+octet-stream", "Upload-Offset": strconv.Itoa(len(content))})
+	if rec.Code != http.StatusConflict {
+		t.Errorf("PATCH concurrente: status %d, se esperaba 409", rec.Code)
+	}
+	r.release(id)
+}
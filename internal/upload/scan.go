@@ -0,0 +1,171 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// Scanner revisa el contenido de un archivo subido en busca de virus o malware
+// Stream lo llama con cada archivo despues de leer todo el formulario, si alguno esta infectado
+// se borran todos los archivos del formulario y se retorna un *InfectedError
+type Scanner interface {
+	Scan(ctx context.Context, r io.Reader) (ScanResult, error)
+}
+
+// ScanResult resultado del escaneo, Signature es el nombre de la amenaza encontrada
+type ScanResult struct {
+	Clean     bool
+	Signature string
+}
+
+// DefaultScanner scanner que se usa cuando Options.Scanner es nil, por defecto no revisa nada
+//
+//	if addr := os.Getenv("CLAMAV_ADDRESS"); addr != "" {
+//		upload.DefaultScanner = upload.NewClamAV(addr)
+//	}
+var DefaultScanner Scanner = NoopScanner{}
+
+// CodeInfected codigo del error de un archivo infectado para que el cliente lo distinga de los demas
+const CodeInfected = "file_infected"
+
+// ErrInfected se usa con errors.Is para saber si la subida se rechazo por un archivo infectado
+var ErrInfected = errors.New("el archivo contiene software malicioso")
+
+// InfectedError archivo rechazado por el scanner
+// errors.As(err, &validation.ValidationErrors{}) funciona para responder 422 como cualquier error de validacion
+type InfectedError struct {
+	Field     string
+	Name      string
+	Signature string
+	Code      string
+}
+
+func (e *InfectedError) Error() string {
+	return fmt.Sprintf("%s: %s (%s)", e.Field, ErrInfected.Error(), e.Signature)
+}
+
+// Unwrap permite errors.Is(err, ErrInfected) y errors.As con validation.ValidationErrors
+func (e *InfectedError) Unwrap() []error {
+	return []error{ErrInfected, fileError(e.Field, CodeInfected, ErrInfected.Error())}
+}
+
+// NoopScanner no revisa nada, todos los archivos son limpios
+type NoopScanner struct{}
+
+func (NoopScanner) Scan(ctx context.Context, r io.Reader) (ScanResult, error) {
+	return ScanResult{Clean: true}, nil
+}
+
+// scanFiles escanea los archivos del formulario, retorna el *InfectedError del primero que este infectado
+// si el scanner falla (clamd caido) la subida se rechaza con ese error, nunca se aceptan archivos sin revisar
+func scanFiles(ctx context.Context, scanner Scanner, files []*UploadedFile) error {
+	if _, ok := scanner.(NoopScanner); ok {
+		return nil
+	}
+	for _, file := range files {
+		r, err := file.Open(ctx)
+		if err != nil {
+			return err
+		}
+		result, err := scanner.Scan(ctx, r)
+		r.Close()
+		if err != nil {
+			return fmt.Errorf("no se pudo revisar el archivo %s: %w", file.Field, err)
+		}
+		if !result.Clean {
+			return &InfectedError{Field: file.Field, Name: file.Name, Signature: result.Signature, Code: CodeInfected}
+		}
+	}
+	return nil
+}
+
+// ClamAV scanner que envia el archivo a clamd con el comando INSTREAM
+type ClamAV struct {
+	Network   string        // tcp o unix
+	Address   string        // host:puerto o ruta del socket
+	Timeout   time.Duration // tiempo maximo de todo el escaneo, 30s si es cero
+	ChunkSize int           // tamaño de cada parte que se envia, 64KB si es cero
+}
+
+// NewClamAV crea el scanner con la direccion de clamd: "localhost:3310", "tcp://clamav:3310" o "unix:///run/clamd.sock"
+func NewClamAV(address string) *ClamAV {
+	network := "tcp"
+	if path, ok := strings.CutPrefix(address, "unix://"); ok {
+		network, address = "unix", path
+	} else {
+		address = strings.TrimPrefix(address, "tcp://")
+	}
+	return &ClamAV{Network: network, Address: address}
+}
+
+func (c *ClamAV) Scan(ctx context.Context, r io.Reader) (ScanResult, error) {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, c.Network, c.Address)
+	if err != nil {
+		return ScanResult{}, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return ScanResult{}, err
+	}
+	size := c.ChunkSize
+	if size <= 0 {
+		size = 64 << 10
+	}
+	chunk := make([]byte, 4+size)
+	for {
+		n, err := io.ReadFull(r, chunk[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(chunk, uint32(n))
+			if _, werr := conn.Write(chunk[:4+n]); werr != nil {
+				return ScanResult{}, werr
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return ScanResult{}, err
+		}
+	}
+	// una parte de tamaño cero termina el archivo
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return ScanResult{}, err
+	}
+
+	reply, err := io.ReadAll(conn)
+	if err != nil {
+		return ScanResult{}, err
+	}
+	return parseClamReply(string(bytes.TrimRight(reply, "\x00\n")))
+}
+
+// parseClamReply interpreta la respuesta de clamd: "stream: OK", "stream: Eicar-Signature FOUND" o "... ERROR"
+func parseClamReply(reply string) (ScanResult, error) {
+	_, status, _ := strings.Cut(reply, ": ")
+	switch {
+	case status == "OK":
+		return ScanResult{Clean: true}, nil
+	case strings.HasSuffix(status, " FOUND"):
+		return ScanResult{Signature: strings.TrimSuffix(status, " FOUND")}, nil
+	}
+	return ScanResult{}, fmt.Errorf("respuesta de clamd inesperada: %s", reply)
+}
@@ -0,0 +1,327 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/donbarrigon/new-project/lib/storage"
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// Las subidas se escriben en el disco mientras llegan, el archivo nunca queda completo en memoria
+// el tamaño y el tipo se validan durante la lectura: un archivo muy grande se corta apenas pasa el limite
+// y el tipo se detecta con los primeros bytes, no con la extension ni el Content-Type que envia el cliente
+//
+//	form, err := upload.Stream(ctx.Request, upload.Options{
+//		Dir:          "avatars",
+//		MaxSize:      5 << 20,
+//		AllowedTypes: []string{"image/png", "image/jpeg"},
+//	})
+//	if err != nil { ... } // validation.ValidationErrors si un archivo no es valido, *InfectedError si tiene virus
+//	avatar := form.File("avatar")
+
+// MaxValueSize tamaño maximo en bytes de los campos de texto del formulario
+var MaxValueSize int64 = 1 << 20
+
+// sniffLen bytes que se leen para detectar el tipo del archivo
+const sniffLen = 512
+
+// ErrTooLarge el archivo supera el tamaño maximo
+var ErrTooLarge = errors.New("el archivo supera el tamaño máximo permitido")
+
+// Codigos de los errores de los archivos (validation.FieldCodes), CodeInfected conserva su valor de antes
+const (
+	CodeTooManyFiles = "FILE_TOO_MANY"
+	CodeEmpty        = "FILE_EMPTY"
+	CodeType         = "FILE_TYPE"
+	CodeTooLarge     = "FILE_TOO_LARGE"
+)
+
+func init() {
+	validation.RegisterCode(CodeTooManyFiles, "se enviaron más archivos de los permitidos")
+	validation.RegisterCode(CodeEmpty, "el archivo está vacío")
+	validation.RegisterCode(CodeType, "el tipo de archivo no está permitido")
+	validation.RegisterErrorCode(ErrTooLarge, CodeTooLarge, "el archivo supera el tamaño máximo")
+	validation.RegisterCode(CodeInfected, "el archivo contiene software malicioso")
+}
+
+// UploadedFile archivo que ya se guardo en el disco
+type UploadedFile struct {
+	Field    string       `json:"field"`     // campo del formulario
+	Name     string       `json:"name"`      // nombre original, sin carpetas
+	Path     string       `json:"path"`      // ruta dentro del disco
+	Size     int64        `json:"size"`      // tamaño en bytes
+	MimeType string       `json:"mime_type"` // tipo detectado con el contenido
+	Disk     storage.Disk `json:"-"`
+}
+
+// Open abre el archivo guardado para leerlo
+func (f *UploadedFile) Open(ctx context.Context) (io.ReadCloser, error) {
+	return f.Disk.Open(ctx, f.Path)
+}
+
+// Delete borra el archivo del disco
+func (f *UploadedFile) Delete(ctx context.Context) error {
+	return f.Disk.Delete(ctx, f.Path)
+}
+
+// Extension extension del archivo segun su tipo (.png), si el tipo no se conoce se usa la del nombre original
+func (f *UploadedFile) Extension() string {
+	return extension(f.MimeType, f.Name)
+}
+
+// Options configuracion de la subida
+type Options struct {
+	Disk         storage.Disk // storage.Default si es nil
+	Dir          string       // carpeta dentro del disco
+	MaxSize      int64        // tamaño maximo por archivo, 0 sin limite
+	MaxFiles     int          // cantidad maxima de archivos, 0 sin limite
+	AllowedTypes []string     // tipos permitidos (image/png, image/*), vacio permite todos
+	Scanner      Scanner      // antivirus, DefaultScanner si es nil
+
+	// Progress se llama cada vez que se escriben bytes de un archivo con el total escrito de ese archivo
+	Progress func(field string, written int64)
+}
+
+// Form campos y archivos del formulario multipart
+type Form struct {
+	Values url.Values
+	Files  []*UploadedFile
+}
+
+// File retorna el primer archivo del campo o nil
+func (f *Form) File(field string) *UploadedFile {
+	for _, file := range f.Files {
+		if file.Field == field {
+			return file
+		}
+	}
+	return nil
+}
+
+// FilesOf retorna todos los archivos del campo (input multiple)
+func (f *Form) FilesOf(field string) []*UploadedFile {
+	var files []*UploadedFile
+	for _, file := range f.Files {
+		if file.Field == field {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// Delete borra todos los archivos del formulario, se usa cuando el resto del request no es valido
+func (f *Form) Delete(ctx context.Context) {
+	for _, file := range f.Files {
+		file.Delete(ctx)
+	}
+}
+
+// Stream lee el formulario multipart y guarda los archivos en el disco mientras llegan
+// si un archivo no es valido se borran los que ya se guardaron y se retorna validation.ValidationErrors
+func Stream(req *http.Request, opts Options) (*Form, error) {
+	reader, err := req.MultipartReader()
+	if err != nil {
+		return nil, fmt.Errorf("el body debe ser un formulario multipart: %w", err)
+	}
+	disk := opts.Disk
+	if disk == nil {
+		disk = storage.Default
+	}
+
+	ctx := req.Context()
+	form := &Form{Values: url.Values{}}
+	fail := func(err error) (*Form, error) {
+		form.Delete(context.WithoutCancel(ctx))
+		return nil, err
+	}
+
+	valuesSize := int64(0)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fail(err)
+		}
+		field := part.FormName()
+		if field == "" {
+			part.Close()
+			continue
+		}
+
+		if part.FileName() == "" {
+			value, err := io.ReadAll(io.LimitReader(part, MaxValueSize-valuesSize+1))
+			part.Close()
+			if err != nil {
+				return fail(err)
+			}
+			if valuesSize += int64(len(value)); valuesSize > MaxValueSize {
+				return fail(fmt.Errorf("los campos del formulario superan %d bytes", MaxValueSize))
+			}
+			form.Values.Add(field, string(value))
+			continue
+		}
+
+		if opts.MaxFiles > 0 && len(form.Files) >= opts.MaxFiles {
+			part.Close()
+			return fail(fileError(field, CodeTooManyFiles, fmt.Sprintf("no se pueden subir más de %d archivos", opts.MaxFiles)))
+		}
+		file, err := store(ctx, disk, opts, field, part.FileName(), part)
+		part.Close()
+		if err != nil {
+			return fail(err)
+		}
+		form.Files = append(form.Files, file)
+	}
+
+	// Revisar los archivos con el antivirus, un archivo infectado rechaza todo el formulario
+	scanner := opts.Scanner
+	if scanner == nil {
+		scanner = DefaultScanner
+	}
+	if err := scanFiles(ctx, scanner, form.Files); err != nil {
+		return fail(err)
+	}
+	return form, nil
+}
+
+// store detecta el tipo con los primeros bytes, lo valida y copia el resto al disco
+func store(ctx context.Context, disk storage.Disk, opts Options, field string, filename string, r io.Reader) (*UploadedFile, error) {
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	head = head[:n]
+	if n == 0 {
+		return nil, fileError(field, CodeEmpty, "el archivo está vacío")
+	}
+
+	mimeType := DetectType(head)
+	if !Allowed(mimeType, opts.AllowedTypes) {
+		return nil, fileError(field, CodeType, fmt.Sprintf("el tipo de archivo %s no está permitido", mimeType))
+	}
+
+	file := &UploadedFile{Field: field, Name: baseName(filename), MimeType: mimeType, Disk: disk}
+	file.Path = path.Join(opts.Dir, RandomName()+file.Extension())
+
+	src := &meteredReader{r: io.MultiReader(bytes.NewReader(head), r), max: opts.MaxSize}
+	if opts.Progress != nil {
+		src.progress = func(written int64) { opts.Progress(field, written) }
+	}
+	size, err := disk.Put(ctx, file.Path, src)
+	if err != nil {
+		if errors.Is(err, ErrTooLarge) {
+			return nil, fileError(field, CodeTooLarge, fmt.Sprintf("el archivo no puede pesar más de %d bytes", opts.MaxSize))
+		}
+		return nil, err
+	}
+	file.Size = size
+	return file, nil
+}
+
+// DetectType detecta el tipo del archivo con sus primeros bytes (sin parametros como charset)
+func DetectType(head []byte) string {
+	mimeType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	return mimeType
+}
+
+// Allowed indica si el tipo esta en la lista, acepta comodines (image/*), la lista vacia permite todos
+func Allowed(mimeType string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		if a == mimeType || a == "*/*" {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(a, "/*"); ok && strings.HasPrefix(mimeType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// RandomName nombre aleatorio para guardar el archivo, nunca se usa el nombre que envia el cliente
+func RandomName() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// extension retorna la extension del tipo o la del nombre original
+func extension(mimeType string, name string) string {
+	switch mimeType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/png":
+		return ".png"
+	case "image/gif":
+		return ".gif"
+	case "image/webp":
+		return ".webp"
+	case "application/pdf":
+		return ".pdf"
+	case "application/zip":
+		return ".zip"
+	}
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, r := range ext[min(1, len(ext)):] {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9') {
+			return ""
+		}
+	}
+	if len(ext) > 10 {
+		return ""
+	}
+	return ext
+}
+
+// baseName quita las carpetas del nombre que envia el cliente (C:\fotos\a.png -> a.png)
+func baseName(name string) string {
+	name = strings.ReplaceAll(name, "\\", "/")
+	return path.Base(name)
+}
+
+// fileError error de validacion de un archivo con su codigo
+func fileError(field string, code string, message string) error {
+	var errs validation.CodedErrors
+	errs.Add(field, code, message)
+	return &errs
+}
+
+// meteredReader cuenta los bytes leidos, avisa el progreso y falla si se pasa del maximo
+type meteredReader struct {
+	r        io.Reader
+	max      int64
+	read     int64
+	progress func(written int64)
+}
+
+func (m *meteredReader) Read(p []byte) (int, error) {
+	n, err := m.r.Read(p)
+	if m.max > 0 && m.read+int64(n) > m.max {
+		// los bytes que sobran no se entregan, asi nunca se escribe mas del maximo
+		n = int(m.max - m.read)
+		m.read = m.max
+		return n, ErrTooLarge
+	}
+	m.read += int64(n)
+	if n > 0 && m.progress != nil {
+		m.progress(m.read)
+	}
+	return n, err
+}
@@ -0,0 +1,183 @@
+package versioning
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Header cabecera con la que el cliente pide una version (Api-Version: 2)
+var Header = "Api-Version"
+
+// Default version que se usa cuando el cliente no pide ninguna
+var Default = "v1"
+
+// versionKey llave del contexto donde se guarda la version del request
+type versionKey struct{}
+
+// Deprecation datos para anunciar que una version se va a retirar
+type Deprecation struct {
+	Since  time.Time // desde cuando esta obsoleta, zero si solo se marca como obsoleta
+	Sunset time.Time // cuando deja de funcionar, zero si aun no hay fecha
+	Link   string    // documentacion de la migracion
+}
+
+var (
+	deprecations   = map[string]Deprecation{}
+	deprecationsMu sync.RWMutex
+)
+
+// Deprecate marca una version como obsoleta, sus respuestas llevan las cabeceras Deprecation, Sunset y Link
+//
+//	versioning.Deprecate("v1", versioning.Deprecation{
+//		Sunset: time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC),
+//		Link:   "https://docs.example.com/migrar-a-v2",
+//	})
+func Deprecate(version string, d Deprecation) {
+	deprecationsMu.Lock()
+	defer deprecationsMu.Unlock()
+	deprecations[Normalize(version)] = d
+}
+
+// DeprecationOf retorna los datos de obsolescencia de la version
+func DeprecationOf(version string) (Deprecation, bool) {
+	deprecationsMu.RLock()
+	defer deprecationsMu.RUnlock()
+	d, ok := deprecations[Normalize(version)]
+	return d, ok
+}
+
+// FromRequest retorna la version del request
+// primero la que guardo el middleware, luego el prefijo de la ruta (/v2/users o /api/v2/users),
+// la cabecera Api-Version, el media type del Accept (application/vnd.app.v2+json o application/json; version=2)
+// y por ultimo Default
+func FromRequest(req *http.Request) string {
+	if v, ok := req.Context().Value(versionKey{}).(string); ok {
+		return v
+	}
+	return Extract(req)
+}
+
+// WithVersion guarda la version en el contexto del request
+func WithVersion(req *http.Request, version string) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), versionKey{}, Normalize(version)))
+}
+
+// Extract calcula la version a partir de la ruta, la cabecera o el media type
+func Extract(req *http.Request) string {
+	if v := fromPath(req.URL.Path); v != "" {
+		return v
+	}
+	if v := req.Header.Get(Header); v != "" {
+		if v = Normalize(v); v != "" {
+			return v
+		}
+	}
+	if v := fromMediaType(req.Header.Get("Accept")); v != "" {
+		return v
+	}
+	return Normalize(Default)
+}
+
+// fromPath busca la version en los dos primeros segmentos de la ruta
+func fromPath(path string) string {
+	segments := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 3)
+	for i := 0; i < len(segments) && i < 2; i++ {
+		if s := segments[i]; len(s) > 1 && (s[0] == 'v' || s[0] == 'V') && isVersionNumber(s[1:]) {
+			return Normalize(s)
+		}
+	}
+	return ""
+}
+
+// fromMediaType lee la version de application/vnd.app.v2+json o de application/json; version=2
+func fromMediaType(accept string) string {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		if v := params["version"]; v != "" {
+			if v = Normalize(v); v != "" {
+				return v
+			}
+		}
+		if sub, ok := strings.CutPrefix(mediaType, "application/vnd."); ok {
+			sub, _, _ = strings.Cut(sub, "+")
+			for _, s := range strings.Split(sub, ".") {
+				if len(s) > 1 && s[0] == 'v' && isVersionNumber(s[1:]) {
+					return Normalize(s)
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// Normalize deja la version como "v2" o "v2.1", retorna "" si no es una version valida
+func Normalize(version string) string {
+	version = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(version)), "v")
+	if !isVersionNumber(version) {
+		return ""
+	}
+	return "v" + version
+}
+
+// isVersionNumber acepta 2 o 2.1
+func isVersionNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, part := range strings.Split(s, ".") {
+		if _, err := strconv.Atoi(part); err != nil || strings.HasPrefix(part, "-") || strings.HasPrefix(part, "+") {
+			return false
+		}
+	}
+	return true
+}
+
+// Compare compara dos versiones, retorna -1 si a < b, 0 si son iguales y 1 si a > b
+func Compare(a string, b string) int {
+	pa := strings.Split(strings.TrimPrefix(Normalize(a), "v"), ".")
+	pb := strings.Split(strings.TrimPrefix(Normalize(b), "v"), ".")
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var na, nb int
+		if i < len(pa) {
+			na, _ = strconv.Atoi(pa[i])
+		}
+		if i < len(pb) {
+			nb, _ = strconv.Atoi(pb[i])
+		}
+		if na != nb {
+			if na < nb {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// WriteDeprecationHeaders agrega las cabeceras de obsolescencia si la version esta obsoleta
+// Deprecation (RFC 9745), Sunset (RFC 8594) y Link con rel="deprecation"
+func WriteDeprecationHeaders(header http.Header, version string) {
+	d, ok := DeprecationOf(version)
+	if !ok {
+		return
+	}
+	if d.Since.IsZero() {
+		header.Set("Deprecation", "true")
+	} else {
+		header.Set("Deprecation", "@"+strconv.FormatInt(d.Since.Unix(), 10))
+	}
+	if !d.Sunset.IsZero() {
+		header.Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if d.Link != "" {
+		header.Add("Link", "<"+d.Link+`>; rel="deprecation"`)
+	}
+}
@@ -0,0 +1,600 @@
+// Package brotli compresor brotli (RFC 7932) sin dependencias para el Content-Encoding br
+//
+// busca repeticiones con una tabla hash (LZ77 voraz, ventana de 64 KB) y codifica cada bloque con sus propios
+// codigos de Huffman. comprime menos que la libreria de referencia pero cualquier navegador lo lee
+//
+//	w := brotli.NewWriter(dst)
+//	w.Write(data)
+//	w.Close()
+package brotli
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/bits"
+)
+
+const (
+	windowBits  = 16
+	maxDistance = 1<<windowBits - 16 // distancia maxima que permite la ventana
+	blockSize   = 1 << 15            // bytes por meta-bloque
+	historySize = 1 << 15            // bytes anteriores en los que se buscan repeticiones
+	minMatch    = 4
+	maxChain    = 32 // candidatos que se revisan por posicion
+	hashBits    = 15
+)
+
+// ErrClosed se escribio despues de Close
+var ErrClosed = errors.New("brotli: el writer está cerrado")
+
+// Writer comprime lo que se escribe y lo envia a w, cumple middleware.Encoder
+// los datos se envian por bloques de 32 KB, Flush envia lo pendiente y Close termina el stream
+type Writer struct {
+	w      io.Writer
+	bw     bitWriter
+	buf    []byte // historial seguido de los datos pendientes
+	start  int    // inicio de los datos pendientes en buf
+	header bool
+	closed bool
+	err    error
+
+	head  []int32
+	chain []uint16
+}
+
+// NewWriter crea el compresor sobre w
+func NewWriter(w io.Writer) *Writer {
+	z := &Writer{}
+	z.Reset(w)
+	return z
+}
+
+// Reset descarta el estado y empieza un stream nuevo sobre w, asi el writer se reutiliza desde un pool
+func (z *Writer) Reset(w io.Writer) {
+	z.w = w
+	z.bw.reset()
+	z.buf = z.buf[:0]
+	z.start = 0
+	z.header = false
+	z.closed = false
+	z.err = nil
+}
+
+// Write guarda los datos y comprime cada bloque completo
+func (z *Writer) Write(p []byte) (int, error) {
+	if z.err != nil {
+		return 0, z.err
+	}
+	if z.closed {
+		return 0, ErrClosed
+	}
+	written := 0
+	for len(p) > 0 {
+		chunk := min(blockSize-(len(z.buf)-z.start), len(p))
+		z.buf = append(z.buf, p[:chunk]...)
+		p = p[chunk:]
+		if len(z.buf)-z.start == blockSize {
+			if err := z.writeBlock(); err != nil {
+				return written, err
+			}
+		}
+		written += chunk
+	}
+	return written, nil
+}
+
+// Flush comprime lo pendiente y lo envia completo, el cliente lo puede descomprimir sin esperar mas datos
+func (z *Writer) Flush() error {
+	if z.err != nil {
+		return z.err
+	}
+	if z.closed {
+		return ErrClosed
+	}
+	z.writeStreamHeader()
+	z.compressPending()
+	if z.bw.nbits > 0 {
+		// un meta-bloque de metadatos vacio deja el stream alineado al byte
+		z.bw.writeBits(0, 1) // ISLAST
+		z.bw.writeBits(3, 2) // MNIBBLES: metadatos
+		z.bw.writeBits(0, 1) // reservado
+		z.bw.writeBits(0, 2) // MSKIPBYTES
+		z.bw.align()
+	}
+	return z.flushBits()
+}
+
+// Close comprime lo pendiente y escribe el ultimo meta-bloque, no cierra el writer de destino
+func (z *Writer) Close() error {
+	if z.err != nil {
+		return z.err
+	}
+	if z.closed {
+		return nil
+	}
+	z.closed = true
+	z.writeStreamHeader()
+	z.compressPending()
+	z.bw.writeBits(1, 1) // ISLAST
+	z.bw.writeBits(1, 1) // ISLASTEMPTY
+	z.bw.align()
+	return z.flushBits()
+}
+
+// writeBlock comprime un bloque completo y lo envia
+func (z *Writer) writeBlock() error {
+	z.writeStreamHeader()
+	z.compressPending()
+	return z.flushBits()
+}
+
+// writeStreamHeader escribe el tamaño de la ventana una sola vez
+func (z *Writer) writeStreamHeader() {
+	if !z.header {
+		z.bw.writeBits(0, 1) // WBITS = 16
+		z.header = true
+	}
+}
+
+// compressPending codifica los datos pendientes y deja en buf solo el historial
+func (z *Writer) compressPending() {
+	if len(z.buf) > z.start {
+		z.compress()
+	}
+	if len(z.buf) > historySize {
+		z.buf = z.buf[:copy(z.buf, z.buf[len(z.buf)-historySize:])]
+	}
+	z.start = len(z.buf)
+}
+
+func (z *Writer) flushBits() error {
+	if _, err := z.w.Write(z.bw.out); err != nil {
+		z.err = err
+		return err
+	}
+	z.bw.out = z.bw.out[:0]
+	return nil
+}
+
+// command copia insert literales desde lit y luego copy bytes desde distance atras (copy 0 solo literales)
+type command struct {
+	lit      int
+	insert   int
+	copy     int
+	distance int
+}
+
+// compress busca las repeticiones de los datos pendientes y escribe el meta-bloque
+func (z *Writer) compress() {
+	buf := z.buf
+	commands := z.findCommands(buf, z.start)
+
+	var litFreq [256]int
+	var cmdFreq [704]int
+	var distFreq [64]int
+	for _, c := range commands {
+		cmdFreq[commandCode(c)]++
+		for _, b := range buf[c.lit : c.lit+c.insert] {
+			litFreq[b]++
+		}
+		if c.copy > 0 {
+			code, _, _ := distanceCode(c.distance)
+			distFreq[code]++
+		}
+	}
+	litLen := huffmanLengths(litFreq[:], 15)
+	cmdLen := huffmanLengths(cmdFreq[:], 15)
+	distLen := huffmanLengths(distFreq[:], 15)
+	litCodes := canonicalCodes(litLen)
+	cmdCodes := canonicalCodes(cmdLen)
+	distCodes := canonicalCodes(distLen)
+
+	w := &z.bw
+	before := *w
+	mlen := len(buf) - z.start
+	writeMetaBlockHeader(w, mlen)
+	w.writeBits(0, 1) // ISUNCOMPRESSED
+	w.writeBits(0, 3) // NBLTYPESL, NBLTYPESI y NBLTYPESD = 1
+	w.writeBits(0, 6) // NPOSTFIX y NDIRECT = 0
+	w.writeBits(0, 2) // modo de contexto de los literales
+	w.writeBits(0, 2) // NTREESL y NTREESD = 1
+	writePrefixCode(w, litFreq[:], litLen, 8)
+	writePrefixCode(w, cmdFreq[:], cmdLen, 10)
+	writePrefixCode(w, distFreq[:], distLen, 6)
+
+	for _, c := range commands {
+		code := commandCode(c)
+		w.writeBits(uint64(cmdCodes[code]), uint(cmdLen[code]))
+		ic, cc := lengthCode(insertLengths, c.insert), lengthCode(copyLengths, max(c.copy, 2))
+		w.writeBits(uint64(c.insert-insertLengths[ic].base), insertLengths[ic].bits)
+		w.writeBits(uint64(max(c.copy, 2)-copyLengths[cc].base), copyLengths[cc].bits)
+		for _, b := range buf[c.lit : c.lit+c.insert] {
+			w.writeBits(uint64(litCodes[b]), uint(litLen[b]))
+		}
+		if c.copy > 0 {
+			dcode, extra, nbits := distanceCode(c.distance)
+			w.writeBits(uint64(distCodes[dcode]), uint(distLen[dcode]))
+			w.writeBits(uint64(extra), nbits)
+		}
+	}
+
+	// los datos que no se repiten (imagenes, datos cifrados) crecen al comprimirlos, se envian tal cual
+	if len(w.out)-len(before.out) > mlen+8 {
+		w.out, w.acc, w.nbits = w.out[:len(before.out)], before.acc, before.nbits
+		writeMetaBlockHeader(w, mlen)
+		w.writeBits(1, 1) // ISUNCOMPRESSED
+		w.align()
+		w.out = append(w.out, buf[z.start:]...)
+	}
+}
+
+// writeMetaBlockHeader escribe ISLAST en 0 y el tamaño del meta-bloque
+func writeMetaBlockHeader(w *bitWriter, mlen int) {
+	w.writeBits(0, 1) // ISLAST
+	nibbles := uint(4)
+	for mlen-1 >= 1<<(4*nibbles) {
+		nibbles++
+	}
+	w.writeBits(uint64(nibbles-4), 2)
+	w.writeBits(uint64(mlen-1), 4*nibbles)
+}
+
+// findCommands LZ77 voraz: en cada posicion se usa la repeticion mas larga de la cadena del hash
+func (z *Writer) findCommands(buf []byte, start int) []command {
+	if z.head == nil {
+		z.head = make([]int32, 1<<hashBits)
+	}
+	for i := range z.head {
+		z.head[i] = -1
+	}
+	if cap(z.chain) < len(buf) {
+		z.chain = make([]uint16, len(buf))
+	}
+	z.chain = z.chain[:len(buf)]
+
+	insert := func(i int) {
+		h := hash(buf[i:])
+		prev := int(z.head[h])
+		z.chain[i] = 0
+		if prev >= 0 && i-prev <= 0xffff {
+			z.chain[i] = uint16(i - prev)
+		}
+		z.head[h] = int32(i)
+	}
+	for i := 0; i < start && i+minMatch <= len(buf); i++ {
+		insert(i)
+	}
+
+	var commands []command
+	lit := start
+	for pos := start; pos+minMatch <= len(buf); {
+		length, distance := z.longestMatch(buf, pos)
+		insert(pos)
+		if length < minMatch {
+			pos++
+			continue
+		}
+		commands = append(commands, command{lit: lit, insert: pos - lit, copy: length, distance: distance})
+		for i := pos + 1; i < pos+length && i+minMatch <= len(buf); i++ {
+			insert(i)
+		}
+		pos += length
+		lit = pos
+	}
+	if lit < len(buf) {
+		commands = append(commands, command{lit: lit, insert: len(buf) - lit})
+	}
+	return commands
+}
+
+// longestMatch retorna la repeticion mas larga que empieza en pos
+func (z *Writer) longestMatch(buf []byte, pos int) (int, int) {
+	best, bestDistance := 0, 0
+	candidate := int(z.head[hash(buf[pos:])])
+	for depth := 0; candidate >= 0 && depth < maxChain; depth++ {
+		distance := pos - candidate
+		if distance > maxDistance {
+			break
+		}
+		if pos+best == len(buf) {
+			break
+		}
+		if buf[candidate+best] == buf[pos+best] {
+			n := 0
+			for pos+n < len(buf) && buf[candidate+n] == buf[pos+n] {
+				n++
+			}
+			if n > best {
+				best, bestDistance = n, distance
+			}
+		}
+		step := int(z.chain[candidate])
+		if step == 0 {
+			break
+		}
+		candidate -= step
+	}
+	return best, bestDistance
+}
+
+func hash(b []byte) uint32 {
+	return (binary.LittleEndian.Uint32(b) * 0x1e35a7bd) >> (32 - hashBits)
+}
+
+// lengthRange rango de un codigo de longitud: base y bits extra
+type lengthRange struct {
+	base int
+	bits uint
+}
+
+// insertLengths y copyLengths codigos de longitud de los comandos (RFC 7932 seccion 5)
+var insertLengths = []lengthRange{
+	{0, 0}, {1, 0}, {2, 0}, {3, 0}, {4, 0}, {5, 0}, {6, 1}, {8, 1}, {10, 2}, {14, 2}, {18, 3}, {26, 3},
+	{34, 4}, {50, 4}, {66, 5}, {98, 5}, {130, 6}, {194, 7}, {322, 8}, {578, 9}, {1090, 10}, {2114, 12},
+	{6210, 14}, {22594, 24},
+}
+
+var copyLengths = []lengthRange{
+	{2, 0}, {3, 0}, {4, 0}, {5, 0}, {6, 0}, {7, 0}, {8, 0}, {9, 0}, {10, 1}, {12, 1}, {14, 2}, {18, 2},
+	{22, 3}, {30, 3}, {38, 4}, {54, 4}, {70, 5}, {102, 5}, {134, 6}, {198, 7}, {326, 8}, {582, 9},
+	{1094, 10}, {2118, 24},
+}
+
+// lengthCode codigo cuyo rango contiene n
+func lengthCode(table []lengthRange, n int) int {
+	code := len(table) - 1
+	for code > 0 && table[code].base > n {
+		code--
+	}
+	return code
+}
+
+// commandCode simbolo de insercion y copia, siempre con distancia explicita (codigos 128 en adelante)
+func commandCode(c command) int {
+	ic := lengthCode(insertLengths, c.insert)
+	cc := lengthCode(copyLengths, max(c.copy, 2))
+	cells := [3][3]int{{128, 192, 384}, {256, 320, 512}, {448, 576, 640}}
+	return cells[ic>>3][cc>>3] + (ic&7)<<3 + cc&7
+}
+
+// distanceCode simbolo de la distancia con NPOSTFIX y NDIRECT en 0 y sus bits extra
+func distanceCode(distance int) (int, int, uint) {
+	x := distance + 3
+	nbits := uint(bits.Len(uint(x)) - 2)
+	code := 16 + 2*int(nbits-1) + (x>>nbits)&1
+	return code, x & (1<<nbits - 1), nbits
+}
+
+// codeLengthOrder orden en que se envian las longitudes del codigo de longitudes
+var codeLengthOrder = [18]int{1, 2, 3, 4, 0, 5, 17, 6, 16, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// codeLengthStatic codigo fijo de las longitudes del codigo de longitudes: valor y bits
+var codeLengthStatic = [6][2]uint{{0, 2}, {7, 4}, {3, 3}, {2, 2}, {1, 2}, {15, 4}}
+
+// writePrefixCode escribe las longitudes del codigo, simple si usa 4 simbolos o menos
+func writePrefixCode(w *bitWriter, freq []int, lengths []uint8, alphabetBits uint) {
+	var used []int
+	for s, f := range freq {
+		if f > 0 {
+			used = append(used, s)
+		}
+	}
+	if len(used) <= 4 {
+		// el codigo simple lista los simbolos ordenados por longitud
+		for i := 1; i < len(used); i++ {
+			for j := i; j > 0 && lengths[used[j]] < lengths[used[j-1]]; j-- {
+				used[j], used[j-1] = used[j-1], used[j]
+			}
+		}
+		if len(used) == 0 {
+			used = []int{0}
+		}
+		w.writeBits(1, 2) // HSKIP = 1: codigo simple
+		w.writeBits(uint64(len(used)-1), 2)
+		for _, s := range used {
+			w.writeBits(uint64(s), alphabetBits)
+		}
+		if len(used) == 4 {
+			if lengths[used[0]] == 1 {
+				w.writeBits(1, 1)
+			} else {
+				w.writeBits(0, 1)
+			}
+		}
+		return
+	}
+
+	// longitudes hasta la ultima distinta de cero, las rachas de ceros con el simbolo 17
+	type item struct{ symbol, extra int }
+	var items []item
+	last := used[len(used)-1]
+	after17 := false
+	for i := 0; i <= last; {
+		if lengths[i] != 0 {
+			items = append(items, item{int(lengths[i]), 0})
+			after17 = false
+			i++
+			continue
+		}
+		run := 0
+		for i+run <= last && lengths[i+run] == 0 {
+			run++
+		}
+		i += run
+		for run > 0 {
+			// dos 17 seguidos se combinan en el decodificador, entre ellos va un 0
+			if run >= 3 && !after17 {
+				n := min(run, 10)
+				items = append(items, item{17, n - 3})
+				run -= n
+				after17 = true
+			} else {
+				items = append(items, item{0, 0})
+				run--
+				after17 = false
+			}
+		}
+	}
+
+	var clFreq [18]int
+	for _, it := range items {
+		clFreq[it.symbol]++
+	}
+	clLen := huffmanLengths(clFreq[:], 5)
+	clCodes := canonicalCodes(clLen)
+	nonZero := 0
+	for _, l := range clLen {
+		if l > 0 {
+			nonZero++
+		}
+	}
+	if nonZero == 0 {
+		// un solo simbolo: se envia con longitud 1 y el decodificador lo lee sin bits
+		for s, f := range clFreq {
+			if f > 0 {
+				clLen[s] = 1
+			}
+		}
+	}
+	lastCL := 17
+	if nonZero > 1 {
+		for clLen[codeLengthOrder[lastCL]] == 0 {
+			lastCL--
+		}
+	}
+
+	w.writeBits(0, 2) // HSKIP = 0
+	for i := 0; i <= lastCL; i++ {
+		code := codeLengthStatic[clLen[codeLengthOrder[i]]]
+		w.writeBits(uint64(code[0]), code[1])
+	}
+	for _, it := range items {
+		if nonZero > 1 {
+			w.writeBits(uint64(clCodes[it.symbol]), uint(clLen[it.symbol]))
+		}
+		if it.symbol == 17 {
+			w.writeBits(uint64(it.extra), 3)
+		}
+	}
+}
+
+// huffmanLengths longitudes de un codigo de Huffman de maximo limit bits
+// un solo simbolo usado tiene longitud 0 (el codigo simple lo envia sin bits)
+func huffmanLengths(freq []int, limit int) []uint8 {
+	lengths := make([]uint8, len(freq))
+	weights := make([]int, len(freq))
+	copy(weights, freq)
+	for {
+		type node struct {
+			weight int
+			parent int
+		}
+		var nodes []node
+		var leaves []int
+		for s, f := range weights {
+			if f > 0 {
+				leaves = append(leaves, s)
+			}
+		}
+		if len(leaves) < 2 {
+			return lengths
+		}
+		// ordena las hojas por peso y las une con dos colas (hojas y nodos internos)
+		for i := 1; i < len(leaves); i++ {
+			for j := i; j > 0 && weights[leaves[j]] < weights[leaves[j-1]]; j-- {
+				leaves[j], leaves[j-1] = leaves[j-1], leaves[j]
+			}
+		}
+		for _, s := range leaves {
+			nodes = append(nodes, node{weight: weights[s], parent: -1})
+		}
+		leaf, inner := 0, len(leaves)
+		take := func() int {
+			if leaf < len(leaves) && (inner >= len(nodes) || nodes[leaf].weight <= nodes[inner].weight) {
+				leaf++
+				return leaf - 1
+			}
+			inner++
+			return inner - 1
+		}
+		for len(nodes) < 2*len(leaves)-1 {
+			a, b := take(), take()
+			nodes = append(nodes, node{weight: nodes[a].weight + nodes[b].weight, parent: -1})
+			nodes[a].parent = len(nodes) - 1
+			nodes[b].parent = len(nodes) - 1
+		}
+		longest := 0
+		for i, s := range leaves {
+			depth := 0
+			for n := i; nodes[n].parent >= 0; n = nodes[n].parent {
+				depth++
+			}
+			lengths[s] = uint8(depth)
+			longest = max(longest, depth)
+		}
+		if longest <= limit {
+			return lengths
+		}
+		// el arbol es muy profundo: se aplanan los pesos y se vuelve a construir
+		for s := range weights {
+			if weights[s] > 0 {
+				weights[s] = (weights[s] + 1) / 2
+			}
+		}
+	}
+}
+
+// canonicalCodes codigos canonicos de las longitudes con los bits invertidos, se escriben desde el menos significativo
+func canonicalCodes(lengths []uint8) []uint16 {
+	var count [16]int
+	for _, l := range lengths {
+		count[l]++
+	}
+	count[0] = 0
+	var next [16]int
+	code := 0
+	for l := 1; l < 16; l++ {
+		code = (code + count[l-1]) << 1
+		next[l] = code
+	}
+	codes := make([]uint16, len(lengths))
+	for s, l := range lengths {
+		if l == 0 {
+			continue
+		}
+		codes[s] = bits.Reverse16(uint16(next[l])) >> (16 - l)
+		next[l]++
+	}
+	return codes
+}
+
+// bitWriter escribe bits empezando por el menos significativo
+type bitWriter struct {
+	out   []byte
+	acc   uint64
+	nbits uint
+}
+
+func (b *bitWriter) reset() {
+	b.out = b.out[:0]
+	b.acc = 0
+	b.nbits = 0
+}
+
+func (b *bitWriter) writeBits(v uint64, n uint) {
+	b.acc |= v << b.nbits
+	b.nbits += n
+	for b.nbits >= 8 {
+		b.out = append(b.out, byte(b.acc))
+		b.acc >>= 8
+		b.nbits -= 8
+	}
+}
+
+// align completa el byte con ceros
+func (b *bitWriter) align() {
+	if b.nbits > 0 {
+		b.writeBits(0, 8-b.nbits)
+	}
+}
@@ -0,0 +1,351 @@
+package brotli
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// errIncomplete el stream termino sin el ultimo meta-bloque (despues de Flush)
+var errIncomplete = errors.New("stream incompleto")
+
+// decode decodificador minimo para las pruebas: solo lo que escribe Writer (un tipo de bloque, un arbol
+// por alfabeto, NPOSTFIX y NDIRECT en 0, distancias explicitas)
+func decode(data []byte) (out []byte, err error) {
+	r := &bitReader{data: data}
+	defer func() {
+		if p := recover(); p != nil {
+			err = p.(error)
+		}
+	}()
+	if r.bits(1) != 0 {
+		return nil, errors.New("solo se soporta WBITS 16")
+	}
+	for {
+		if r.pos == 8*len(data) {
+			return out, errIncomplete
+		}
+		last := r.bits(1) == 1
+		if last && r.bits(1) == 1 {
+			return out, nil
+		}
+		nibbles := r.bits(2)
+		if nibbles == 3 {
+			if r.bits(1) != 0 || r.bits(2) != 0 {
+				return nil, errors.New("metadatos no soportados")
+			}
+			r.align()
+			continue
+		}
+		mlen := r.bits(4*(nibbles+4)) + 1
+		if !last && r.bits(1) == 1 {
+			r.align()
+			start := r.pos / 8
+			out = append(out, data[start:start+mlen]...)
+			r.pos += 8 * mlen
+			continue
+		}
+		if r.bits(3) != 0 || r.bits(6) != 0 {
+			return nil, errors.New("tipos de bloque o distancias no soportados")
+		}
+		r.bits(2) // modo de contexto
+		if r.bits(2) != 0 {
+			return nil, errors.New("mapas de contexto no soportados")
+		}
+		lit, cmd, dist := r.prefixCode(256, 8), r.prefixCode(704, 10), r.prefixCode(64, 6)
+
+		end := len(out) + mlen
+		for len(out) < end {
+			code := r.symbol(cmd)
+			if code < 128 {
+				return nil, errors.New("distancia implicita no soportada")
+			}
+			bases := [11][2]int{2: {0, 0}, 3: {0, 8}, 4: {8, 0}, 5: {8, 8}, 6: {0, 16}, 7: {16, 0}, 8: {8, 16}, 9: {16, 8}, 10: {16, 16}}[code>>6]
+			ic, cc := bases[0]+(code>>3)&7, bases[1]+code&7
+			insert := insertLengths[ic].base + r.bits(int(insertLengths[ic].bits))
+			length := copyLengths[cc].base + r.bits(int(copyLengths[cc].bits))
+			for i := 0; i < insert; i++ {
+				out = append(out, byte(r.symbol(lit)))
+			}
+			if len(out) == end {
+				break
+			}
+			d := r.symbol(dist)
+			nbits := 1 + (d-16)>>1
+			distance := (2+(d-16)&1)<<nbits - 4 + r.bits(nbits) + 1
+			if distance > len(out) || distance > maxDistance {
+				return nil, errors.New("distancia fuera de la ventana")
+			}
+			for i := 0; i < length; i++ {
+				out = append(out, out[len(out)-distance])
+			}
+		}
+		if len(out) != end {
+			return nil, errors.New("el meta-bloque no tiene el tamaño indicado")
+		}
+	}
+}
+
+type bitReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *bitReader) bits(n int) int {
+	v := 0
+	for i := 0; i < n; i++ {
+		if r.pos >= 8*len(r.data) {
+			panic(errors.New("fin inesperado del stream"))
+		}
+		v |= int(r.data[r.pos/8]>>(r.pos%8)&1) << i
+		r.pos++
+	}
+	return v
+}
+
+func (r *bitReader) align() {
+	if r.pos%8 != 0 {
+		if r.bits(8-r.pos%8) != 0 {
+			panic(errors.New("relleno distinto de cero"))
+		}
+	}
+}
+
+// code codigo canonico: simbolo por longitud y valor, single si el codigo tiene un solo simbolo sin bits
+type code struct {
+	symbols map[[2]int]int
+	single  int
+}
+
+func newCode(lengths []int) *code {
+	c := &code{symbols: map[[2]int]int{}, single: -1}
+	var count [16]int
+	used := 0
+	for s, l := range lengths {
+		count[l]++
+		if l > 0 {
+			used++
+			c.single = s
+		}
+	}
+	if used > 1 {
+		c.single = -1
+	}
+	count[0] = 0
+	var next [16]int
+	v := 0
+	for l := 1; l < 16; l++ {
+		v = (v + count[l-1]) << 1
+		next[l] = v
+	}
+	for s, l := range lengths {
+		if l > 0 {
+			c.symbols[[2]int{l, next[l]}] = s
+			next[l]++
+		}
+	}
+	return c
+}
+
+func (r *bitReader) symbol(c *code) int {
+	if c.single >= 0 {
+		return c.single
+	}
+	v := 0
+	for l := 1; l < 16; l++ {
+		v = v<<1 | r.bits(1)
+		if s, ok := c.symbols[[2]int{l, v}]; ok {
+			return s
+		}
+	}
+	panic(errors.New("código inválido"))
+}
+
+func (r *bitReader) prefixCode(size int, alphabetBits int) *code {
+	lengths := make([]int, size)
+	if r.bits(2) == 1 {
+		n := r.bits(2) + 1
+		symbols := make([]int, n)
+		for i := range symbols {
+			symbols[i] = r.bits(alphabetBits)
+		}
+		pattern := [][]int{{1}, {1, 1}, {1, 2, 2}, {2, 2, 2, 2}}[n-1]
+		if n == 4 && r.bits(1) == 1 {
+			pattern = []int{1, 2, 3, 3}
+		}
+		for i, s := range symbols {
+			lengths[s] = pattern[i]
+		}
+		return newCode(lengths)
+	}
+
+	clLengths := make([]int, 18)
+	space, used := 32, 0
+	for i := 0; i < 18 && space > 0; i++ {
+		var v int
+		switch r.bits(2) {
+		case 0:
+			v = 0
+		case 1:
+			v = 4
+		case 2:
+			v = 3
+		default:
+			if r.bits(1) == 0 {
+				v = 2
+			} else if r.bits(1) == 0 {
+				v = 1
+			} else {
+				v = 5
+			}
+		}
+		clLengths[codeLengthOrder[i]] = v
+		if v != 0 {
+			space -= 32 >> v
+			used++
+		}
+	}
+	if used != 1 && space != 0 {
+		panic(errors.New("código de longitudes incompleto"))
+	}
+	cl := newCode(clLengths)
+
+	kraft := 1 << 15
+	repeat := 0
+	for i := 0; i < size && kraft > 0; {
+		s := r.symbol(cl)
+		switch {
+		case s < 16:
+			lengths[i] = s
+			if s != 0 {
+				kraft -= 1 << 15 >> s
+			}
+			i++
+			repeat = 0
+		case s == 17:
+			old := repeat
+			if repeat > 0 {
+				repeat = (repeat - 2) << 3
+			}
+			repeat += r.bits(3) + 3
+			i += repeat - old
+		default:
+			panic(errors.New("el código 16 no se usa"))
+		}
+	}
+	if kraft != 0 {
+		panic(errors.New("código incompleto"))
+	}
+	return newCode(lengths)
+}
+
+func testInputs() map[string][]byte {
+	r := rand.New(rand.NewSource(1))
+	random := make([]byte, 100000)
+	r.Read(random)
+	words := []string{"el", "la", "pedido", "cliente", "factura", "total", "estado", "pagado", "\n"}
+	var text bytes.Buffer
+	for text.Len() < 200000 {
+		text.WriteString(words[r.Intn(len(words))])
+		text.WriteByte(' ')
+	}
+	return map[string][]byte{
+		"vacio":     nil,
+		"un byte":   []byte("a"),
+		"corto":     []byte("hola"),
+		"repetido":  bytes.Repeat([]byte("a"), 100000),
+		"json":      []byte(strings.Repeat(`{"id":1,"name":"ana","email":"ana@example.com"},`, 2000)),
+		"texto":     text.Bytes(),
+		"aleatorio": random,
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for name, in := range testInputs() {
+		for _, chunk := range []int{0, 1000, 40000} {
+			var out bytes.Buffer
+			w := NewWriter(&out)
+			if chunk == 0 {
+				w.Write(in)
+			} else {
+				for i := 0; i < len(in); i += chunk {
+					w.Write(in[i:min(i+chunk, len(in))])
+					if err := w.Flush(); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			got, err := decode(out.Bytes())
+			if err != nil {
+				t.Errorf("%s (bloques de %d): %v", name, chunk, err)
+				continue
+			}
+			if !bytes.Equal(got, in) {
+				t.Errorf("%s (bloques de %d): el resultado no coincide, %d bytes de %d", name, chunk, len(got), len(in))
+			}
+		}
+	}
+}
+
+func TestCompressionRatio(t *testing.T) {
+	inputs := testInputs()
+	cases := []struct {
+		name string
+		max  float64 // tamaño maximo respecto a la entrada
+	}{
+		{"json", 0.01},
+		{"repetido", 0.01},
+		{"texto", 0.25},
+		{"aleatorio", 1.001},
+	}
+	for _, c := range cases {
+		var out bytes.Buffer
+		w := NewWriter(&out)
+		w.Write(inputs[c.name])
+		w.Close()
+		if ratio := float64(out.Len()) / float64(len(inputs[c.name])); ratio > c.max {
+			t.Errorf("%s: se comprimio a %.3f del tamaño, se esperaba a lo sumo %.3f", c.name, ratio, c.max)
+		}
+	}
+}
+
+func TestFlushSendsPending(t *testing.T) {
+	var out bytes.Buffer
+	w := NewWriter(&out)
+	w.Write([]byte("primera parte "))
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := decode(out.Bytes())
+	if !errors.Is(err, errIncomplete) || string(got) != "primera parte " {
+		t.Errorf("despues de Flush se debe poder leer lo enviado, se obtuvo %q %v", got, err)
+	}
+
+	w.Write([]byte("segunda parte"))
+	w.Close()
+	if got, err := decode(out.Bytes()); err != nil || string(got) != "primera parte segunda parte" {
+		t.Errorf("se esperaba el texto completo, se obtuvo %q %v", got, err)
+	}
+}
+
+func TestResetAndClose(t *testing.T) {
+	var first, second bytes.Buffer
+	w := NewWriter(&first)
+	w.Write(bytes.Repeat([]byte("uno "), 10000))
+	w.Close()
+	if _, err := w.Write([]byte("x")); !errors.Is(err, ErrClosed) {
+		t.Errorf("Write despues de Close debe retornar ErrClosed, se obtuvo %v", err)
+	}
+
+	w.Reset(&second)
+	w.Write([]byte("dos"))
+	w.Close()
+	if got, err := decode(second.Bytes()); err != nil || string(got) != "dos" {
+		t.Errorf("despues de Reset el stream no debe depender del anterior, se obtuvo %q %v", got, err)
+	}
+}
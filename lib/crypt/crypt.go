@@ -0,0 +1,209 @@
+package crypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrDecrypt el valor no se pudo descifrar con ninguna de las llaves (valor alterado o llave desconocida)
+var ErrDecrypt = errors.New("el valor cifrado no es válido")
+
+// idSize bytes del identificador de la llave que va al inicio de cada valor cifrado
+const idSize = 4
+
+// Crypt cifra con AES-256-GCM usando la llave actual y descifra con la actual o con las anteriores
+// cada valor lleva el id de la llave con que se cifro, asi se pueden rotar las llaves sin perder datos
+//
+//	c, err := crypt.New(os.Getenv("APP_KEY"), strings.Split(os.Getenv("APP_PREVIOUS_KEYS"), ",")...)
+//	token, _ := c.EncryptString("4111111111111111")
+//
+// el contexto (context) ata el valor cifrado al lugar donde se usa, por ejemplo el campo y el id del registro:
+// se autentica junto con el texto cifrado y para descifrar se debe enviar el mismo, asi el valor de un campo
+// o de un registro no se puede copiar en otro. sin contexto el valor solo queda atado a la llave
+//
+//	token, _ := c.EncryptString("4111111111111111", "card", "42")
+//	number, err := c.DecryptString(token, "card", "42")
+type Crypt struct {
+	current key
+	keys    map[string]key // todas las llaves por id, incluida la actual
+}
+
+// key llave con su cifrador ya preparado
+type key struct {
+	id   string
+	aead cipher.AEAD
+	mac  []byte // llave de las firmas, derivada para no usar la misma llave en AES y en HMAC
+}
+
+// Default servicio que usan los tags encrypted, se configura al iniciar con Configure
+var Default *Crypt
+
+// New crea el servicio con la llave actual y las anteriores (solo para descifrar)
+// las llaves son de 32 bytes en hex (64 caracteres) o en base64, con o sin el prefijo "base64:"
+// las llaves vacias se ignoran
+func New(current string, previous ...string) (*Crypt, error) {
+	k, err := parseKey(current)
+	if err != nil {
+		return nil, err
+	}
+	c := &Crypt{current: k, keys: map[string]key{k.id: k}}
+	for _, p := range previous {
+		if strings.TrimSpace(p) == "" {
+			continue
+		}
+		k, err := parseKey(p)
+		if err != nil {
+			return nil, err
+		}
+		c.keys[k.id] = k
+	}
+	return c, nil
+}
+
+// Configure crea el servicio por defecto
+func Configure(current string, previous ...string) error {
+	c, err := New(current, previous...)
+	if err != nil {
+		return err
+	}
+	Default = c
+	return nil
+}
+
+// GenerateKey genera una llave nueva en el formato que recibe New
+func GenerateKey() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return "base64:" + base64.StdEncoding.EncodeToString(b)
+}
+
+// Record lo implementan los modelos y FormRequest cuyos campos encrypted se atan al registro,
+// el id se agrega al contexto del cifrado junto con el nombre del campo
+type Record interface {
+	RecordID() string
+}
+
+// FieldContext contexto del cifrado de un campo encrypted: el nombre del campo y el id del registro
+// si owner (el struct que tiene el campo o un puntero a el) implementa Record
+func FieldContext(owner any, field string) []string {
+	if r, ok := owner.(Record); ok {
+		return []string{field, r.RecordID()}
+	}
+	return []string{field}
+}
+
+// Encrypt cifra el texto, el resultado es base64 url (id de la llave + nonce + texto cifrado)
+func (c *Crypt) Encrypt(plaintext []byte, context ...string) (string, error) {
+	nonce := make([]byte, c.current.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	out := make([]byte, 0, idSize+len(nonce)+len(plaintext)+c.current.aead.Overhead())
+	out = append(out, c.current.id...)
+	out = append(out, nonce...)
+	out = c.current.aead.Seal(out, nonce, plaintext, additionalData(c.current.id, context))
+	return base64.RawURLEncoding.EncodeToString(out), nil
+}
+
+// Decrypt descifra un valor cifrado con la llave actual o con alguna de las anteriores
+// context debe ser el mismo con que se cifro
+func (c *Crypt) Decrypt(value string, context ...string) ([]byte, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(raw) < idSize {
+		return nil, ErrDecrypt
+	}
+	k, ok := c.keys[string(raw[:idSize])]
+	if !ok {
+		return nil, ErrDecrypt
+	}
+	nonceSize := k.aead.NonceSize()
+	if len(raw) < idSize+nonceSize {
+		return nil, ErrDecrypt
+	}
+	plaintext, err := k.aead.Open(nil, raw[idSize:idSize+nonceSize], raw[idSize+nonceSize:], additionalData(string(raw[:idSize]), context))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+// EncryptString cifra un texto
+func (c *Crypt) EncryptString(s string, context ...string) (string, error) {
+	return c.Encrypt([]byte(s), context...)
+}
+
+// DecryptString descifra un texto
+func (c *Crypt) DecryptString(value string, context ...string) (string, error) {
+	b, err := c.Decrypt(value, context...)
+	return string(b), err
+}
+
+// NeedsRotation indica si el valor se cifro con una llave anterior y se debe volver a cifrar
+func (c *Crypt) NeedsRotation(value string) bool {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	return err == nil && len(raw) >= idSize && string(raw[:idSize]) != c.current.id
+}
+
+// Rotate descifra el valor y lo vuelve a cifrar con la llave actual y el mismo contexto
+func (c *Crypt) Rotate(value string, context ...string) (string, error) {
+	plaintext, err := c.Decrypt(value, context...)
+	if err != nil {
+		return "", err
+	}
+	return c.Encrypt(plaintext, context...)
+}
+
+// additionalData datos autenticados del cifrado: el id de la llave y cada parte del contexto con su largo
+// para que ("ab", "c") y ("a", "bc") no den lo mismo, sin contexto es solo el id como antes
+func additionalData(id string, context []string) []byte {
+	if len(context) == 0 {
+		return []byte(id)
+	}
+	size := len(id)
+	for _, part := range context {
+		size += 4 + len(part)
+	}
+	ad := make([]byte, 0, size)
+	ad = append(ad, id...)
+	for _, part := range context {
+		ad = binary.BigEndian.AppendUint32(ad, uint32(len(part)))
+		ad = append(ad, part...)
+	}
+	return ad
+}
+
+// parseKey interpreta la llave y prepara el cifrador
+func parseKey(s string) (key, error) {
+	s = strings.TrimSpace(s)
+	var raw []byte
+	var err error
+	if b64, ok := strings.CutPrefix(s, "base64:"); ok {
+		raw, err = base64.StdEncoding.DecodeString(b64)
+	} else if len(s) == 64 {
+		raw, err = hex.DecodeString(s)
+	} else {
+		raw, err = base64.StdEncoding.DecodeString(s)
+	}
+	if err != nil || len(raw) != 32 {
+		return key{}, fmt.Errorf("crypt: la llave debe tener 32 bytes en hex o base64")
+	}
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return key{}, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return key{}, err
+	}
+	sum := sha256.Sum256(raw)
+	mac := sha256.Sum256(append([]byte("sign:"), raw...))
+	return key{id: string(sum[:idSize]), aead: aead, mac: mac[:]}, nil
+}
@@ -0,0 +1,71 @@
+package crypt
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEncryptContext(t *testing.T) {
+	c, err := New(GenerateKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := c.EncryptString("4111111111111111", "card", "42")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name    string
+		context []string
+		ok      bool
+	}{
+		{"mismo contexto", []string{"card", "42"}, true},
+		{"otro registro", []string{"card", "43"}, false},
+		{"otro campo", []string{"iban", "42"}, false},
+		{"partes corridas", []string{"card4", "2"}, false},
+		{"sin contexto", nil, false},
+	}
+	for _, c2 := range cases {
+		plain, err := c.DecryptString(token, c2.context...)
+		if c2.ok && (err != nil || plain != "4111111111111111") {
+			t.Errorf("%s: se esperaba el texto plano, se obtuvo %q %v", c2.name, plain, err)
+		}
+		if !c2.ok && !errors.Is(err, ErrDecrypt) {
+			t.Errorf("%s: se esperaba ErrDecrypt, se obtuvo %v", c2.name, err)
+		}
+	}
+
+	// sin contexto sigue funcionando como antes
+	token, _ = c.EncryptString("hola")
+	if plain, err := c.DecryptString(token); err != nil || plain != "hola" {
+		t.Errorf("se esperaba hola, se obtuvo %q %v", plain, err)
+	}
+}
+
+func TestRotateKeepsContext(t *testing.T) {
+	old := GenerateKey()
+	previous, _ := New(old)
+	token, _ := previous.EncryptString("secreto", "card", "7")
+
+	c, err := New(GenerateKey(), old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.NeedsRotation(token) {
+		t.Fatalf("el valor se cifro con la llave anterior")
+	}
+	rotated, err := c.Rotate(token, "card", "7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.NeedsRotation(rotated) {
+		t.Errorf("el valor rotado debe usar la llave actual")
+	}
+	if plain, err := c.DecryptString(rotated, "card", "7"); err != nil || plain != "secreto" {
+		t.Errorf("se esperaba secreto, se obtuvo %q %v", plain, err)
+	}
+	if _, err := c.Rotate(token, "card", "8"); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("rotar con otro contexto debe fallar, se obtuvo %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package ctxkey
+
+import (
+	"context"
+	"net/http"
+)
+
+// Valores del contexto con tipo: cada llave sabe el tipo de su valor, asi no se necesitan type assertions
+// ni llaves string que pueden chocar entre paquetes. la llave se compara por puntero, dos New con el mismo
+// nombre son llaves distintas
+//
+//	var UserKey = ctxkey.New[*model.User]("auth.user")
+//
+//	ctx = ctxkey.Set(ctx, UserKey, user)
+//	user, ok := ctxkey.Get(ctx, UserKey)
+
+// Key llave de un valor de tipo T en el contexto
+type Key[T any] struct {
+	name string
+}
+
+// New crea una llave, el nombre solo sirve para depurar
+func New[T any](name string) *Key[T] {
+	return &Key[T]{name: name}
+}
+
+// String retorna el nombre de la llave
+func (k *Key[T]) String() string {
+	return k.name
+}
+
+// Set retorna un contexto con el valor
+func Set[T any](ctx context.Context, key *Key[T], value T) context.Context {
+	return context.WithValue(ctx, key, value)
+}
+
+// Get retorna el valor y si existe
+func Get[T any](ctx context.Context, key *Key[T]) (T, bool) {
+	value, ok := ctx.Value(key).(T)
+	return value, ok
+}
+
+// Value retorna el valor o el valor cero de T si no existe
+func Value[T any](ctx context.Context, key *Key[T]) T {
+	value, _ := Get(ctx, key)
+	return value
+}
+
+// Has indica si el contexto tiene el valor
+func Has[T any](ctx context.Context, key *Key[T]) bool {
+	_, ok := Get(ctx, key)
+	return ok
+}
+
+// SetRequest retorna una copia del request con el valor en su contexto, para los middlewares
+//
+//	ctx.Request = ctxkey.SetRequest(ctx.Request, request.RequestIDKey, id)
+func SetRequest[T any](req *http.Request, key *Key[T], value T) *http.Request {
+	return req.WithContext(Set(req.Context(), key, value))
+}
@@ -0,0 +1,62 @@
+package formatter
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// tipos que acepta Coerce
+const (
+	CoerceAuto   = "auto"   // adivina el tipo [int | float64 | bool | string]
+	CoerceString = "string" // no convierte nada, util para ids con ceros a la izquierda "0123"
+	CoerceInt    = "int"
+	CoerceFloat  = "float"
+	CoerceBool   = "bool"
+)
+
+// ParseValue adivina el tipo del valor, primero int luego float64 luego bool y si no string
+// cuidado: "0123" se convierte en 123 y "true" en un bool, si eso es un problema use Coerce con CoerceString
+func ParseValue(value string) any {
+	if intValue, err := strconv.Atoi(value); err == nil {
+		// Es un int
+		return intValue
+	} else if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+		// Es un float
+		return floatValue
+	} else if boolValue, err := strconv.ParseBool(value); err == nil {
+		// Es un bool
+		return boolValue
+	}
+	// Es un string (por defecto)
+	return value
+}
+
+// Coerce convierte el valor al tipo indicado (auto, string, int, float, bool)
+// si kind es "" se usa auto
+func Coerce(value string, kind string) (any, error) {
+	switch kind {
+	case "", CoerceAuto:
+		return ParseValue(value), nil
+	case CoerceString:
+		return value, nil
+	case CoerceInt:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("el valor '%s' debe ser un número entero", value)
+		}
+		return n, nil
+	case CoerceFloat:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("el valor '%s' debe ser numérico", value)
+		}
+		return n, nil
+	case CoerceBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("el valor '%s' debe ser verdadero o falso", value)
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("tipo de conversión '%s' no soportado", kind)
+}
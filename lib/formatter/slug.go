@@ -0,0 +1,59 @@
+package formatter
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// ligatures letras que no se descomponen en una letra base y un acento
+var ligatures = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'Æ': "AE", 'œ': "oe", 'Œ': "OE",
+	'ø': "o", 'Ø': "O", 'đ': "d", 'Đ': "D", 'ł': "l", 'Ł': "L", 'þ': "th", 'Þ': "TH",
+}
+
+// Transliterate quita los acentos y reemplaza las letras especiales por su equivalente en ascii
+// "Año Ñandú Straße" -> "Ano Nandu Strasse", lo que no tiene equivalente se deja igual
+// sirve en PrepareForValidation antes de las reglas slug o username
+func Transliterate(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range norm.NFD.String(s) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if l, ok := ligatures[r]; ok {
+			b.WriteString(l)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// ToSlug convierte el texto en un slug para urls: "¡Hola Mundo! 2024" -> "hola-mundo-2024"
+// transliterado, en minusculas, con guiones entre las palabras y sin guiones al inicio o al final
+func ToSlug(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	dash := false
+	for _, r := range strings.ToLower(Transliterate(s)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	return b.String()
+}
+
+// ToUsername normaliza un nombre de usuario: sin espacios alrededor, transliterado y en minusculas
+// no quita los caracteres invalidos, eso lo reporta la regla username
+func ToUsername(s string) string {
+	return strings.ToLower(Transliterate(strings.TrimSpace(s)))
+}
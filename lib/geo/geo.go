@@ -0,0 +1,320 @@
+// Package geo parametros de los listados de mapas: el area visible (bbox), un punto con radio y un poligono
+//
+//	type ListStores struct {
+//		geo.Near                                                           // ?lat=4.711&lng=-74.072&radius=2000 (metros)
+//		BBox     *geo.BBox   `json:"bbox" query:"bbox" rules:"bbox"`       // ?bbox=-74.2,4.5,-73.9,4.8
+//		Area     geo.Polygon `json:"area" query:"area" rules:"polygon:50"` // ?area=-74.1,4.6,-74.0,4.6,-74.0,4.7
+//	}
+//
+// las coordenadas van en el orden de GeoJSON: longitud y luego latitud. bbox es oeste,sur,este,norte
+// y un poligono es la lista de vertices lng,lat,lng,lat,... (cerrar el anillo repitiendo el primero es opcional).
+// los tipos se llenan desde la url (tag query del FormRequest) y desde el json ([lng, lat] y [oeste, sur, este, norte]),
+// al llenarlos solo se revisa la forma, los rangos los revisan las reglas bbox, polygon, latitude y longitude
+package geo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MaxPolygonVertices vertices que acepta la regla polygon si no se indica otro limite
+var MaxPolygonVertices = 100
+
+// EarthRadius radio medio de la tierra en metros
+const EarthRadius = 6371008.8
+
+// Point posicion en grados
+type Point struct {
+	Lat float64
+	Lng float64
+}
+
+// Valid revisa que la latitud este entre -90 y 90 y la longitud entre -180 y 180
+func (p Point) Valid() error {
+	if math.IsNaN(p.Lat) || p.Lat < -90 || p.Lat > 90 {
+		return errors.New("la latitud debe estar entre -90 y 90")
+	}
+	if math.IsNaN(p.Lng) || p.Lng < -180 || p.Lng > 180 {
+		return errors.New("la longitud debe estar entre -180 y 180")
+	}
+	return nil
+}
+
+// MarshalJSON serializa el punto como en GeoJSON [lng, lat]
+func (p Point) MarshalJSON() ([]byte, error) {
+	return json.Marshal([2]float64{p.Lng, p.Lat})
+}
+
+// UnmarshalJSON lee el punto [lng, lat]
+func (p *Point) UnmarshalJSON(data []byte) error {
+	var c []float64
+	if err := json.Unmarshal(data, &c); err != nil || len(c) != 2 {
+		return errors.New("el punto debe ser [longitud, latitud]")
+	}
+	*p = Point{Lng: c[0], Lat: c[1]}
+	return nil
+}
+
+// Distance distancia en metros entre dos puntos (haversine)
+func Distance(a, b Point) float64 {
+	lat1, lat2 := a.Lat*math.Pi/180, b.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLng := (b.Lng - a.Lng) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// BBox area rectangular, si West es mayor que East el area cruza el antimeridiano
+type BBox struct {
+	West  float64
+	South float64
+	East  float64
+	North float64
+}
+
+// ParseBBox interpreta "oeste,sur,este,norte"
+func ParseBBox(s string) (BBox, error) {
+	c, err := parseNumbers(s, ",")
+	if err != nil || len(c) != 4 {
+		return BBox{}, fmt.Errorf("el bbox '%s' debe ser oeste,sur,este,norte", s)
+	}
+	return BBox{West: c[0], South: c[1], East: c[2], North: c[3]}, nil
+}
+
+// IsZero indica si el bbox no se lleno
+func (b BBox) IsZero() bool {
+	return b == BBox{}
+}
+
+// Valid revisa los rangos de las coordenadas y que el sur no este al norte del norte
+func (b BBox) Valid() error {
+	if err := (Point{Lat: b.South, Lng: b.West}).Valid(); err != nil {
+		return err
+	}
+	if err := (Point{Lat: b.North, Lng: b.East}).Valid(); err != nil {
+		return err
+	}
+	if b.South > b.North {
+		return errors.New("el sur del bbox no puede estar al norte del norte")
+	}
+	return nil
+}
+
+// Contains indica si el punto esta dentro del area
+func (b BBox) Contains(p Point) bool {
+	if p.Lat < b.South || p.Lat > b.North {
+		return false
+	}
+	if b.West <= b.East {
+		return p.Lng >= b.West && p.Lng <= b.East
+	}
+	return p.Lng >= b.West || p.Lng <= b.East
+}
+
+// String retorna el bbox como en la url
+func (b BBox) String() string {
+	return joinNumbers(",", b.West, b.South, b.East, b.North)
+}
+
+// MarshalJSON serializa el bbox como en GeoJSON [oeste, sur, este, norte]
+func (b BBox) MarshalJSON() ([]byte, error) {
+	return json.Marshal([4]float64{b.West, b.South, b.East, b.North})
+}
+
+// UnmarshalJSON lee el bbox [oeste, sur, este, norte] o el texto de la url
+func (b *BBox) UnmarshalJSON(data []byte) error {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		return b.FromRequestValue(s)
+	}
+	var c []float64
+	if err := json.Unmarshal(data, &c); err != nil || len(c) != 4 {
+		return errors.New("el bbox debe ser [oeste, sur, este, norte]")
+	}
+	*b = BBox{West: c[0], South: c[1], East: c[2], North: c[3]}
+	return nil
+}
+
+// FromRequestValue llena el bbox desde la url (request.ParamUnmarshaler)
+func (b *BBox) FromRequestValue(value string) error {
+	parsed, err := ParseBBox(value)
+	if err != nil {
+		return err
+	}
+	*b = parsed
+	return nil
+}
+
+// Polygon vertices del anillo exterior sin repetir el primero al final
+type Polygon []Point
+
+// ParsePolygon interpreta "lng,lat,lng,lat,lng,lat", si el ultimo vertice repite el primero se quita
+// (la url no acepta ; como separador, net/url descarta los parametros que lo tienen)
+func ParsePolygon(s string) (Polygon, error) {
+	c, err := parseNumbers(s, ",")
+	if err != nil || len(c)%2 != 0 {
+		return nil, fmt.Errorf("el polígono '%s' debe ser una lista de longitud,latitud", s)
+	}
+	p := make(Polygon, 0, len(c)/2)
+	for i := 0; i < len(c); i += 2 {
+		p = append(p, Point{Lng: c[i], Lat: c[i+1]})
+	}
+	return p.open(), nil
+}
+
+// open quita el vertice que cierra el anillo
+func (p Polygon) open() Polygon {
+	if len(p) > 1 && p[0] == p[len(p)-1] {
+		return p[:len(p)-1]
+	}
+	return p
+}
+
+// Valid revisa los rangos de los vertices, que tenga al menos 3 distintos y como maximo max (0 sin limite)
+func (p Polygon) Valid(max int) error {
+	if max > 0 && len(p) > max {
+		return fmt.Errorf("el polígono no puede tener más de %d vértices", max)
+	}
+	distinct := map[Point]bool{}
+	for i, v := range p {
+		if err := v.Valid(); err != nil {
+			return fmt.Errorf("vértice %d: %w", i, err)
+		}
+		distinct[v] = true
+	}
+	if len(distinct) < 3 {
+		return errors.New("el polígono debe tener al menos 3 vértices distintos")
+	}
+	return nil
+}
+
+// Ring vertices con el anillo cerrado, como lo esperan GeoJSON y las bases de datos
+func (p Polygon) Ring() []Point {
+	if len(p) == 0 {
+		return nil
+	}
+	return append(append([]Point{}, p...), p[0])
+}
+
+// Contains indica si el punto esta dentro del poligono (ray casting sobre lng/lat, para areas pequeñas)
+func (p Polygon) Contains(pt Point) bool {
+	inside := false
+	for i, j := 0, len(p)-1; i < len(p); j, i = i, i+1 {
+		a, b := p[i], p[j]
+		if (a.Lat > pt.Lat) != (b.Lat > pt.Lat) && pt.Lng < (b.Lng-a.Lng)*(pt.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lng {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// String retorna el poligono como en la url
+func (p Polygon) String() string {
+	numbers := make([]float64, 0, len(p)*2)
+	for _, v := range p {
+		numbers = append(numbers, v.Lng, v.Lat)
+	}
+	return joinNumbers(",", numbers...)
+}
+
+// UnmarshalJSON lee los vertices [[lng, lat], ...] o el texto de la url
+func (p *Polygon) UnmarshalJSON(data []byte) error {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		return p.FromRequestValue(s)
+	}
+	var points []Point
+	if err := json.Unmarshal(data, &points); err != nil {
+		return errors.New("el polígono debe ser una lista de [longitud, latitud]")
+	}
+	*p = Polygon(points).open()
+	return nil
+}
+
+// FromRequestValue llena el poligono desde la url (request.ParamUnmarshaler)
+func (p *Polygon) FromRequestValue(value string) error {
+	parsed, err := ParsePolygon(value)
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
+
+// Near punto con radio en metros, se embebe en el FormRequest y se llena con ?lat=&lng=&radius=
+// los tres se envian juntos o ninguno, el radio maximo es 100 km
+type Near struct {
+	Lat    *float64 `json:"lat" query:"lat" rules:"required_with:lng,radius|latitude"`
+	Lng    *float64 `json:"lng" query:"lng" rules:"required_with:lat,radius|longitude"`
+	Radius *float64 `json:"radius" query:"radius" rules:"required_with:lat,lng|min:1|max:100000"`
+}
+
+// Circle centro y radio de Near, false si no se envio
+func (n Near) Circle() (Circle, bool) {
+	if n.Lat == nil || n.Lng == nil || n.Radius == nil {
+		return Circle{}, false
+	}
+	return Circle{Center: Point{Lat: *n.Lat, Lng: *n.Lng}, Radius: *n.Radius}, true
+}
+
+// Circle area circular, Radius en metros
+type Circle struct {
+	Center Point
+	Radius float64
+}
+
+// Contains indica si el punto esta a menos de Radius del centro
+func (c Circle) Contains(p Point) bool {
+	return Distance(c.Center, p) <= c.Radius
+}
+
+// BBox rectangulo que contiene el circulo, sirve para filtrar con un indice antes de calcular la distancia
+func (c Circle) BBox() BBox {
+	dLat := c.Radius / EarthRadius * 180 / math.Pi
+	south, north := math.Max(-90, c.Center.Lat-dLat), math.Min(90, c.Center.Lat+dLat)
+	if south == -90 || north == 90 {
+		return BBox{West: -180, South: south, East: 180, North: north}
+	}
+	dLng := dLat / math.Cos(c.Center.Lat*math.Pi/180)
+	if dLng >= 180 {
+		return BBox{West: -180, South: south, East: 180, North: north}
+	}
+	return BBox{West: wrap(c.Center.Lng - dLng), South: south, East: wrap(c.Center.Lng + dLng), North: north}
+}
+
+// wrap deja la longitud entre -180 y 180
+func wrap(lng float64) float64 {
+	switch {
+	case lng < -180:
+		return lng + 360
+	case lng > 180:
+		return lng - 360
+	}
+	return lng
+}
+
+// parseNumbers separa y convierte los numeros
+func parseNumbers(s string, sep string) ([]float64, error) {
+	parts := strings.Split(s, sep)
+	out := make([]float64, len(parts))
+	for i, part := range parts {
+		n, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+			return nil, fmt.Errorf("'%s' no es un número", part)
+		}
+		out[i] = n
+	}
+	return out, nil
+}
+
+func joinNumbers(sep string, numbers ...float64) string {
+	parts := make([]string, len(numbers))
+	for i, n := range numbers {
+		parts[i] = strconv.FormatFloat(n, 'f', -1, 64)
+	}
+	return strings.Join(parts, sep)
+}
@@ -0,0 +1,232 @@
+package geo
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	bogota := Point{Lat: 4.711, Lng: -74.0721}
+	medellin := Point{Lat: 6.2442, Lng: -75.5812}
+	if d := Distance(bogota, medellin); math.Abs(d-240000) > 5000 {
+		t.Errorf("se esperaban unos 240 km entre Bogotá y Medellín, se obtuvo %.0f m", d)
+	}
+	if d := Distance(bogota, bogota); d != 0 {
+		t.Errorf("la distancia a si mismo debe ser 0, se obtuvo %f", d)
+	}
+	// cruzando el antimeridiano
+	if d := Distance(Point{Lng: 179.5}, Point{Lng: -179.5}); math.Abs(d-111195) > 100 {
+		t.Errorf("se esperaba 1 grado en el ecuador, se obtuvo %.0f m", d)
+	}
+}
+
+func TestPointValid(t *testing.T) {
+	cases := []struct {
+		p  Point
+		ok bool
+	}{
+		{Point{Lat: 0, Lng: 0}, true},
+		{Point{Lat: 90, Lng: 180}, true},
+		{Point{Lat: -90.1, Lng: 0}, false},
+		{Point{Lat: 0, Lng: 180.1}, false},
+		{Point{Lat: math.NaN(), Lng: 0}, false},
+	}
+	for _, c := range cases {
+		if err := c.p.Valid(); (err == nil) != c.ok {
+			t.Errorf("Valid(%v) = %v, se esperaba ok=%v", c.p, err, c.ok)
+		}
+	}
+}
+
+func TestParseBBox(t *testing.T) {
+	b, err := ParseBBox("-74.2, 4.5,-73.9,4.8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b != (BBox{West: -74.2, South: 4.5, East: -73.9, North: 4.8}) {
+		t.Errorf("bbox inesperado %+v", b)
+	}
+	if b.String() != "-74.2,4.5,-73.9,4.8" {
+		t.Errorf("se esperaba el formato de la url, se obtuvo %s", b.String())
+	}
+	for _, s := range []string{"", "1,2,3", "1,2,3,4,5", "a,2,3,4", "NaN,1,2,3", "Inf,1,2,3"} {
+		if _, err := ParseBBox(s); err == nil {
+			t.Errorf("ParseBBox(%q) debe fallar", s)
+		}
+	}
+}
+
+func TestBBoxValid(t *testing.T) {
+	cases := []struct {
+		b  BBox
+		ok bool
+	}{
+		{BBox{West: -74.2, South: 4.5, East: -73.9, North: 4.8}, true},
+		{BBox{West: 170, South: -10, East: -170, North: 10}, true},
+		{BBox{West: -74, South: 5, East: -73, North: 4}, false},
+		{BBox{West: -181, South: 0, East: 0, North: 1}, false},
+		{BBox{West: 0, South: 0, East: 1, North: 91}, false},
+	}
+	for _, c := range cases {
+		if err := c.b.Valid(); (err == nil) != c.ok {
+			t.Errorf("Valid(%+v) = %v, se esperaba ok=%v", c.b, err, c.ok)
+		}
+	}
+}
+
+func TestBBoxContains(t *testing.T) {
+	b := BBox{West: -74.2, South: 4.5, East: -73.9, North: 4.8}
+	if !b.Contains(Point{Lat: 4.6, Lng: -74}) {
+		t.Errorf("el punto esta dentro del bbox")
+	}
+	if b.Contains(Point{Lat: 4.6, Lng: -73}) || b.Contains(Point{Lat: 5, Lng: -74}) {
+		t.Errorf("el punto esta fuera del bbox")
+	}
+
+	antimeridian := BBox{West: 170, South: -10, East: -170, North: 10}
+	for _, lng := range []float64{175, 180, -180, -175} {
+		if !antimeridian.Contains(Point{Lng: lng}) {
+			t.Errorf("la longitud %v esta dentro del bbox que cruza el antimeridiano", lng)
+		}
+	}
+	if antimeridian.Contains(Point{Lng: 0}) {
+		t.Errorf("la longitud 0 esta fuera del bbox que cruza el antimeridiano")
+	}
+}
+
+func TestBBoxJSON(t *testing.T) {
+	var b BBox
+	if err := json.Unmarshal([]byte(`[-74.2,4.5,-73.9,4.8]`), &b); err != nil {
+		t.Fatal(err)
+	}
+	var s BBox
+	if err := json.Unmarshal([]byte(`"-74.2,4.5,-73.9,4.8"`), &s); err != nil {
+		t.Fatal(err)
+	}
+	if b != s {
+		t.Errorf("el array y el texto deben dar el mismo bbox, %+v y %+v", b, s)
+	}
+	if data, _ := json.Marshal(b); string(data) != `[-74.2,4.5,-73.9,4.8]` {
+		t.Errorf("se esperaba [oeste, sur, este, norte], se obtuvo %s", data)
+	}
+	if err := json.Unmarshal([]byte(`[1,2,3]`), &b); err == nil {
+		t.Errorf("un bbox con 3 numeros debe fallar")
+	}
+}
+
+func TestParsePolygon(t *testing.T) {
+	cases := []struct {
+		in   string
+		want int
+	}{
+		{"-74.1,4.6,-74.0,4.6,-74.0,4.7", 3},
+		{"-74.1,4.6,-74.0,4.6,-74.0,4.7,-74.1,4.6", 3},
+		{"-74.1,4.6", 1},
+	}
+	for _, c := range cases {
+		p, err := ParsePolygon(c.in)
+		if err != nil {
+			t.Errorf("ParsePolygon(%q): %v", c.in, err)
+			continue
+		}
+		if len(p) != c.want {
+			t.Errorf("ParsePolygon(%q) tiene %d vértices, se esperaban %d", c.in, len(p), c.want)
+		}
+	}
+	for _, s := range []string{"", "1,2,3", "1,a"} {
+		if _, err := ParsePolygon(s); err == nil {
+			t.Errorf("ParsePolygon(%q) debe fallar", s)
+		}
+	}
+}
+
+func TestPolygonValid(t *testing.T) {
+	square := Polygon{{Lng: 0, Lat: 0}, {Lng: 1, Lat: 0}, {Lng: 1, Lat: 1}, {Lng: 0, Lat: 1}}
+	cases := []struct {
+		name string
+		p    Polygon
+		max  int
+		ok   bool
+	}{
+		{"valido", square, 0, true},
+		{"limite", square, 4, true},
+		{"excede el limite", square, 3, false},
+		{"pocos vertices", square[:2], 0, false},
+		{"vertices repetidos", Polygon{{Lng: 0}, {Lng: 1}, {Lng: 0}, {Lng: 1}}, 0, false},
+		{"fuera de rango", Polygon{{Lng: 0}, {Lng: 1}, {Lng: 1, Lat: 91}}, 0, false},
+	}
+	for _, c := range cases {
+		if err := c.p.Valid(c.max); (err == nil) != c.ok {
+			t.Errorf("%s: Valid = %v, se esperaba ok=%v", c.name, err, c.ok)
+		}
+	}
+}
+
+func TestPolygonRingAndContains(t *testing.T) {
+	square := Polygon{{Lng: 0, Lat: 0}, {Lng: 2, Lat: 0}, {Lng: 2, Lat: 2}, {Lng: 0, Lat: 2}}
+	ring := square.Ring()
+	if len(ring) != 5 || ring[0] != ring[4] {
+		t.Errorf("el anillo debe cerrarse repitiendo el primer vértice, se obtuvo %v", ring)
+	}
+	if len(square) != 4 {
+		t.Errorf("Ring no debe modificar el polígono")
+	}
+	if !square.Contains(Point{Lng: 1, Lat: 1}) {
+		t.Errorf("el centro esta dentro del polígono")
+	}
+	if square.Contains(Point{Lng: 3, Lat: 1}) || square.Contains(Point{Lng: 1, Lat: -1}) {
+		t.Errorf("el punto esta fuera del polígono")
+	}
+	if square.String() != "0,0,2,0,2,2,0,2" {
+		t.Errorf("se esperaba el formato de la url, se obtuvo %s", square.String())
+	}
+
+	var p Polygon
+	if err := json.Unmarshal([]byte(`[[0,0],[2,0],[2,2],[0,2],[0,0]]`), &p); err != nil {
+		t.Fatal(err)
+	}
+	if len(p) != 4 {
+		t.Errorf("el json debe quitar el vértice que cierra el anillo, se obtuvo %v", p)
+	}
+}
+
+func TestNearCircle(t *testing.T) {
+	if _, ok := (Near{}).Circle(); ok {
+		t.Errorf("sin lat, lng y radius no hay circulo")
+	}
+	lat, lng, radius := 4.711, -74.0721, 2000.0
+	c, ok := Near{Lat: &lat, Lng: &lng, Radius: &radius}.Circle()
+	if !ok || c.Center != (Point{Lat: lat, Lng: lng}) || c.Radius != radius {
+		t.Fatalf("circulo inesperado %+v", c)
+	}
+	if !c.Contains(Point{Lat: 4.72, Lng: -74.0721}) {
+		t.Errorf("un punto a 1 km esta dentro del circulo de 2 km")
+	}
+	if c.Contains(Point{Lat: 4.75, Lng: -74.0721}) {
+		t.Errorf("un punto a 4 km esta fuera del circulo de 2 km")
+	}
+}
+
+func TestCircleBBox(t *testing.T) {
+	c := Circle{Center: Point{Lat: 4.711, Lng: -74.0721}, Radius: 2000}
+	b := c.BBox()
+	if b.Valid() != nil || !b.Contains(c.Center) {
+		t.Errorf("el bbox debe contener el centro, se obtuvo %+v", b)
+	}
+	for _, p := range []Point{{Lat: 4.711, Lng: -74.09}, {Lat: 4.729, Lng: -74.0721}} {
+		if c.Contains(p) && !b.Contains(p) {
+			t.Errorf("el bbox debe contener los puntos del circulo, %v quedo fuera de %+v", p, b)
+		}
+	}
+
+	wrap := Circle{Center: Point{Lng: 179.99}, Radius: 5000}.BBox()
+	if wrap.West <= wrap.East || !wrap.Contains(Point{Lng: -179.99}) {
+		t.Errorf("el bbox debe cruzar el antimeridiano, se obtuvo %+v", wrap)
+	}
+
+	pole := Circle{Center: Point{Lat: 89.99}, Radius: 5000}.BBox()
+	if pole.West != -180 || pole.East != 180 || pole.North != 90 {
+		t.Errorf("cerca del polo el bbox debe cubrir todas las longitudes, se obtuvo %+v", pole)
+	}
+}
@@ -0,0 +1,124 @@
+package ids
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseULID(t *testing.T) {
+	cases := []struct {
+		s    string
+		ok   bool
+		want string // "" igual a s
+	}{
+		{"01ARZ3NDEKTSV4RRFFQ69G5FAV", true, ""},
+		{"01arz3ndektsv4rrffq69g5fav", true, "01ARZ3NDEKTSV4RRFFQ69G5FAV"},
+		{"00000000000000000000000000", true, ""},
+		{"7ZZZZZZZZZZZZZZZZZZZZZZZZZ", true, ""},
+		{"80000000000000000000000000", false, ""},
+		{"01ARZ3NDEKTSV4RRFFQ69G5FA", false, ""},
+		{"01ARZ3NDEKTSV4RRFFQ69G5FAVV", false, ""},
+		{"01ARZ3NDEKTSV4RRFFQ69G5FAU", false, ""},
+		{"01ARZ3NDEKTSV4RRFFQ69G5FA-", false, ""},
+		{"", false, ""},
+	}
+	for _, c := range cases {
+		id, err := ParseULID(c.s)
+		if (err == nil) != c.ok {
+			t.Errorf("%q: se obtuvo %v, se esperaba ok=%v", c.s, err, c.ok)
+			continue
+		}
+		want := c.want
+		if want == "" {
+			want = c.s
+		}
+		if c.ok && id.String() != want {
+			t.Errorf("%q: String() = %s, se esperaba %s", c.s, id, want)
+		}
+	}
+
+	// los 10 primeros caracteres son los milisegundos en base32
+	id, _ := ParseULID("01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	if got := id.Time().UnixMilli(); got != 1469922850259 {
+		t.Errorf("se esperaba el tiempo 1469922850259, se obtuvo %d", got)
+	}
+}
+
+func TestNewULID(t *testing.T) {
+	at := time.Date(2026, 10, 15, 9, 30, 0, 123e6, time.UTC)
+	a, b := NewULIDAt(at), NewULIDAt(at.Add(time.Millisecond))
+	if !a.Time().Equal(at) {
+		t.Errorf("se esperaba %v, se obtuvo %v", at, a.Time())
+	}
+	if a.String() >= b.String() {
+		t.Errorf("los ULID deben ordenarse por tiempo: %s >= %s", a, b)
+	}
+	if a == NewULIDAt(at) || a.IsZero() {
+		t.Errorf("la parte aleatoria debe cambiar en cada ULID")
+	}
+
+	var out struct {
+		ID ULID `json:"id"`
+	}
+	raw, _ := json.Marshal(map[string]ULID{"id": a})
+	if err := json.Unmarshal(raw, &out); err != nil || out.ID != a {
+		t.Errorf("el ULID debe ir y volver por json, se obtuvo %s %v", raw, err)
+	}
+	if err := out.ID.FromRequestValue("x"); err == nil {
+		t.Errorf("se esperaba error con un ULID invalido en la url")
+	}
+}
+
+func TestParseKSUID(t *testing.T) {
+	cases := []struct {
+		s  string
+		ok bool
+	}{
+		{"0ujtsYcgvSTl8PAuAdqWYSMnLOv", true},
+		{"000000000000000000000000000", true},
+		{maxKSUID, true},
+		{"aWgEPTl1tmebfsQzFP4bxwgy80W", false},
+		{"zzzzzzzzzzzzzzzzzzzzzzzzzzz", false},
+		{"0ujtsYcgvSTl8PAuAdqWYSMnLO", false},
+		{"0ujtsYcgvSTl8PAuAdqWYSMnLOv0", false},
+		{"0ujtsYcgvSTl8PAuAdqWYSMnLO_", false},
+		{"", false},
+	}
+	for _, c := range cases {
+		id, err := ParseKSUID(c.s)
+		if (err == nil) != c.ok {
+			t.Errorf("%q: se obtuvo %v, se esperaba ok=%v", c.s, err, c.ok)
+			continue
+		}
+		if c.ok && id.String() != c.s {
+			t.Errorf("%q: String() = %s", c.s, id)
+		}
+	}
+
+	// ejemplo de segmentio/ksuid
+	id, _ := ParseKSUID("0ujtsYcgvSTl8PAuAdqWYSMnLOv")
+	if got := id.Time().Unix(); got != 1507608047 {
+		t.Errorf("se esperaba el tiempo 1507608047, se obtuvo %d", got)
+	}
+	if payload := strings.ToUpper(hex.EncodeToString(id[4:])); payload != "B5A1CD34B5F99D1154FB6853345C9735" {
+		t.Errorf("payload inesperado %s", payload)
+	}
+}
+
+func TestNewKSUID(t *testing.T) {
+	at := time.Date(2026, 10, 15, 9, 30, 0, 0, time.UTC)
+	a, b := NewKSUIDAt(at), NewKSUIDAt(at.Add(time.Second))
+	if !a.Time().Equal(at) {
+		t.Errorf("se esperaba %v, se obtuvo %v", at, a.Time())
+	}
+	if a.String() >= b.String() {
+		t.Errorf("los KSUID deben ordenarse por tiempo: %s >= %s", a, b)
+	}
+	parsed, err := ParseKSUID(a.String())
+	if err != nil || parsed != a {
+		t.Errorf("el KSUID debe ir y volver como texto, se obtuvo %s %v", parsed, err)
+	}
+}
@@ -0,0 +1,111 @@
+package ids
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// ksuidEpoch los segundos de un KSUID se cuentan desde 2014-05-13 16:53:20 UTC
+const ksuidEpoch = 1400000000
+
+// base62 alfabeto de KSUID
+const base62 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// maxKSUID el KSUID mas grande, los textos mayores no caben en 20 bytes
+const maxKSUID = "aWgEPTl1tmebfsQzFP4bxwgy80V"
+
+// KSUID identificador de 160 bits: 32 bits de tiempo en segundos y 128 aleatorios
+type KSUID [20]byte
+
+// NewKSUID crea un KSUID con la hora actual
+func NewKSUID() KSUID {
+	return NewKSUIDAt(time.Now())
+}
+
+// NewKSUIDAt crea un KSUID con la hora indicada
+func NewKSUIDAt(t time.Time) KSUID {
+	var id KSUID
+	binary.BigEndian.PutUint32(id[:4], uint32(t.Unix()-ksuidEpoch))
+	rand.Read(id[4:])
+	return id
+}
+
+// ParseKSUID interpreta los 27 caracteres base62 del KSUID
+func ParseKSUID(s string) (KSUID, error) {
+	var id KSUID
+	if len(s) != 27 {
+		return id, fmt.Errorf("el KSUID '%s' debe tener 27 caracteres", s)
+	}
+	if s > maxKSUID {
+		return id, fmt.Errorf("el KSUID '%s' no es válido", s)
+	}
+	n := new(big.Int)
+	for i := 0; i < len(s); i++ {
+		v := base62Value(s[i])
+		if v < 0 {
+			return id, fmt.Errorf("el KSUID '%s' tiene caracteres no válidos", s)
+		}
+		n.Mul(n, big.NewInt(62))
+		n.Add(n, big.NewInt(int64(v)))
+	}
+	n.FillBytes(id[:])
+	return id, nil
+}
+
+// base62Value valor del caracter en base62, -1 si no es valido
+func base62Value(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case c >= 'A' && c <= 'Z':
+		return int(c-'A') + 10
+	case c >= 'a' && c <= 'z':
+		return int(c-'a') + 36
+	}
+	return -1
+}
+
+// String retorna el KSUID en base62 con ceros a la izquierda
+func (id KSUID) String() string {
+	n := new(big.Int).SetBytes(id[:])
+	out := []byte("000000000000000000000000000")
+	base, mod := big.NewInt(62), new(big.Int)
+	for i := len(out) - 1; i >= 0 && n.Sign() > 0; i-- {
+		n.DivMod(n, base, mod)
+		out[i] = base62[mod.Int64()]
+	}
+	return string(out)
+}
+
+// Time retorna la hora en que se creo el KSUID
+func (id KSUID) Time() time.Time {
+	return time.Unix(int64(binary.BigEndian.Uint32(id[:4]))+ksuidEpoch, 0)
+}
+
+// IsZero indica si el KSUID no se lleno
+func (id KSUID) IsZero() bool {
+	return id == KSUID{}
+}
+
+// MarshalText serializa el KSUID como texto en el json
+func (id KSUID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText lee el KSUID desde el json
+func (id *KSUID) UnmarshalText(b []byte) error {
+	parsed, err := ParseKSUID(string(b))
+	if err != nil {
+		return err
+	}
+	*id = parsed
+	return nil
+}
+
+// FromRequestValue llena el KSUID desde la url (request.ParamUnmarshaler)
+func (id *KSUID) FromRequestValue(value string) error {
+	return id.UnmarshalText([]byte(value))
+}
@@ -0,0 +1,133 @@
+// Package ids identificadores ordenables por tiempo: ULID y KSUID
+//
+//	id := ids.NewULID()            // 01HZX3K8Q4V6N9F2B7C1D5E8GA
+//	parsed, err := ids.ParseULID(s)
+//
+// los tipos se serializan como texto en el json y se llenan desde la url y los tags query/path del FormRequest
+package ids
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// crockford alfabeto base32 de Crockford que usa ULID (sin I, L, O, U)
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// crockfordValues valor de cada caracter, -1 si no es valido. acepta minusculas
+var crockfordValues = func() [256]int8 {
+	var values [256]int8
+	for i := range values {
+		values[i] = -1
+	}
+	for i := 0; i < len(crockford); i++ {
+		values[crockford[i]] = int8(i)
+		values[crockford[i]|0x20] = int8(i)
+	}
+	return values
+}()
+
+// ULID identificador de 128 bits: 48 bits de tiempo en milisegundos y 80 aleatorios
+type ULID [16]byte
+
+// NewULID crea un ULID con la hora actual
+func NewULID() ULID {
+	return NewULIDAt(time.Now())
+}
+
+// NewULIDAt crea un ULID con la hora indicada
+func NewULIDAt(t time.Time) ULID {
+	var id ULID
+	ms := uint64(t.UnixMilli())
+	id[0], id[1], id[2] = byte(ms>>40), byte(ms>>32), byte(ms>>24)
+	id[3], id[4], id[5] = byte(ms>>16), byte(ms>>8), byte(ms)
+	rand.Read(id[6:])
+	return id
+}
+
+// ParseULID interpreta los 26 caracteres del ULID sin importar mayusculas
+func ParseULID(s string) (ULID, error) {
+	var id ULID
+	if len(s) != 26 {
+		return id, fmt.Errorf("el ULID '%s' debe tener 26 caracteres", s)
+	}
+	// 26 caracteres de 5 bits son 130 bits, el primero no puede pasar de 7 para que quepa en 128
+	if crockfordValues[s[0]] > 7 {
+		return id, fmt.Errorf("el ULID '%s' no es válido", s)
+	}
+	var bits uint64
+	var n uint
+	out := 15
+	for i := len(s) - 1; i >= 0; i-- {
+		v := crockfordValues[s[i]]
+		if v < 0 {
+			return ULID{}, fmt.Errorf("el ULID '%s' tiene caracteres no válidos", s)
+		}
+		bits |= uint64(v) << n
+		n += 5
+		for n >= 8 && out >= 0 {
+			id[out] = byte(bits)
+			out--
+			bits >>= 8
+			n -= 8
+		}
+	}
+	if out >= 0 {
+		id[out] = byte(bits)
+	}
+	return id, nil
+}
+
+// String retorna el ULID en base32 de Crockford en mayusculas
+func (id ULID) String() string {
+	var out [26]byte
+	var bits uint64
+	var n uint
+	pos := 25
+	for i := 15; i >= 0; i-- {
+		bits |= uint64(id[i]) << n
+		n += 8
+		for n >= 5 {
+			out[pos] = crockford[bits&31]
+			pos--
+			bits >>= 5
+			n -= 5
+		}
+	}
+	out[0] = crockford[bits&31]
+	return string(out[:])
+}
+
+// Time retorna la hora en que se creo el ULID
+func (id ULID) Time() time.Time {
+	var b [8]byte
+	copy(b[2:], id[:6])
+	return time.UnixMilli(int64(binary.BigEndian.Uint64(b[:])))
+}
+
+// IsZero indica si el ULID no se lleno
+func (id ULID) IsZero() bool {
+	return id == ULID{}
+}
+
+// MarshalText serializa el ULID como texto en el json
+func (id ULID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText lee el ULID desde el json
+func (id *ULID) UnmarshalText(b []byte) error {
+	parsed, err := ParseULID(string(b))
+	if err != nil {
+		return err
+	}
+	*id = parsed
+	return nil
+}
+
+// FromRequestValue llena el ULID desde la url (request.ParamUnmarshaler)
+func (id *ULID) FromRequestValue(value string) error {
+	return id.UnmarshalText([]byte(value))
+}
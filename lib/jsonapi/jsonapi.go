@@ -0,0 +1,225 @@
+package jsonapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// MediaType Content-Type de los documentos JSON:API
+const MediaType = "application/vnd.api+json"
+
+// Document documento JSON:API de respuesta
+type Document struct {
+	Data     any               `json:"data,omitempty"`
+	Errors   []ErrorObject     `json:"errors,omitempty"`
+	Meta     map[string]any    `json:"meta,omitempty"`
+	Links    map[string]string `json:"links,omitempty"`
+	Included []Resource        `json:"included,omitempty"`
+}
+
+// Resource objeto de recurso
+type Resource struct {
+	Type          string                  `json:"type"`
+	ID            string                  `json:"id,omitempty"`
+	Attributes    any                     `json:"attributes,omitempty"`
+	Relationships map[string]Relationship `json:"relationships,omitempty"`
+	Links         map[string]string       `json:"links,omitempty"`
+}
+
+// Relationship relacion de un recurso, Data es un Identifier, []Identifier o nil
+type Relationship struct {
+	Data  any               `json:"data"`
+	Links map[string]string `json:"links,omitempty"`
+}
+
+// Identifier identifica un recurso relacionado
+type Identifier struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// ErrorObject error en formato JSON:API
+type ErrorObject struct {
+	Status string       `json:"status,omitempty"`
+	Code   string       `json:"code,omitempty"`
+	Title  string       `json:"title,omitempty"`
+	Detail string       `json:"detail,omitempty"`
+	Source *ErrorSource `json:"source,omitempty"`
+}
+
+// ErrorSource indica que parte del documento causo el error
+type ErrorSource struct {
+	Pointer   string `json:"pointer,omitempty"`   // JSON Pointer dentro del body (/data/attributes/name)
+	Parameter string `json:"parameter,omitempty"` // parametro de la url
+}
+
+// NewResource crea un recurso con sus atributos
+func NewResource(typ string, id string, attributes any) Resource {
+	return Resource{Type: typ, ID: id, Attributes: attributes}
+}
+
+// ValidationError errores de validacion de un documento JSON:API
+// guarda que campos eran relaciones para construir el pointer de cada error
+// errors.As(err, &validation.ValidationErrors{}) sigue funcionando gracias a Unwrap
+// Codes son los codigos de cada mensaje (validation.FieldCodes), van en el miembro code de cada error object
+type ValidationError struct {
+	Errors        validation.ValidationErrors
+	Relationships map[string]bool
+	Codes         map[string][]string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Errors.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Errors
+}
+
+// Objects convierte los errores en error objects con el pointer y el codigo de cada campo
+func (e *ValidationError) Objects() []ErrorObject {
+	return validationObjects(e.Errors, e.Relationships, e.Codes)
+}
+
+// ValidationObjects convierte los errores de validacion en error objects ordenados por campo
+func ValidationObjects(errs validation.ValidationErrors, relationships map[string]bool) []ErrorObject {
+	return validationObjects(errs, relationships, nil)
+}
+
+func validationObjects(errs validation.ValidationErrors, relationships map[string]bool, codes map[string][]string) []ErrorObject {
+	fields := make([]string, 0, len(errs))
+	for field := range errs {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var objects []ErrorObject
+	for _, field := range fields {
+		for i, msg := range errs[field] {
+			var code string
+			if i < len(codes[field]) {
+				code = codes[field][i]
+			}
+			objects = append(objects, ErrorObject{
+				Status: "422",
+				Code:   code,
+				Title:  "Invalid Attribute",
+				Detail: msg,
+				Source: &ErrorSource{Pointer: Pointer(field, relationships)},
+			})
+		}
+	}
+	return objects
+}
+
+// Pointer retorna el JSON Pointer del campo dentro del documento original
+// id -> /data/id, una relacion -> /data/relationships/author, lo demas -> /data/attributes/address/street
+func Pointer(field string, relationships map[string]bool) string {
+	if field == "id" {
+		return "/data/id"
+	}
+	root, _, _ := strings.Cut(field, ".")
+	if relationships[root] {
+		return "/data/relationships" + validation.ToPointer(field)
+	}
+	return "/data/attributes" + validation.ToPointer(field)
+}
+
+// ErrorsFrom convierte cualquier error en error objects
+func ErrorsFrom(status int, err error) []ErrorObject {
+	var jerr *ValidationError
+	if errors.As(err, &jerr) {
+		return jerr.Objects()
+	}
+	var verrs validation.ValidationErrors
+	if errors.As(err, &verrs) {
+		return validationObjects(verrs, nil, validation.FieldCodes(err))
+	}
+	return []ErrorObject{{Status: strconv.Itoa(status), Code: validation.ErrorCode(err), Detail: err.Error()}}
+}
+
+// Flatten convierte un documento JSON:API en un objeto plano para deserializar y validar
+// {"data":{"id":"1","attributes":{"name":"x"},"relationships":{"role":{"data":{"type":"roles","id":"2"}}}}}
+// queda {"id":"1","name":"x","role":"2"}, las relaciones a muchos quedan como un array de ids
+// retorna los nombres de las relaciones para construir los pointers de los errores
+func Flatten(body []byte) ([]byte, map[string]bool, error) {
+	var doc struct {
+		Data *struct {
+			Type          string                     `json:"type"`
+			ID            json.RawMessage            `json:"id"`
+			Attributes    map[string]json.RawMessage `json:"attributes"`
+			Relationships map[string]struct {
+				Data json.RawMessage `json:"data"`
+			} `json:"relationships"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, nil, err
+	}
+	if doc.Data == nil {
+		return nil, nil, errors.New("el documento JSON:API debe tener el miembro data")
+	}
+
+	flat := make(map[string]json.RawMessage, len(doc.Data.Attributes)+len(doc.Data.Relationships)+1)
+	for k, v := range doc.Data.Attributes {
+		flat[k] = v
+	}
+	if len(doc.Data.ID) > 0 {
+		flat["id"] = doc.Data.ID
+	}
+
+	relationships := make(map[string]bool, len(doc.Data.Relationships))
+	for name, rel := range doc.Data.Relationships {
+		relationships[name] = true
+		ids, err := relationshipIDs(rel.Data)
+		if err != nil {
+			return nil, nil, err
+		}
+		flat[name] = ids
+	}
+
+	out, err := json.Marshal(flat)
+	return out, relationships, err
+}
+
+// relationshipIDs deja solo los ids de la relacion: null, "id" o ["id", ...]
+func relationshipIDs(data json.RawMessage) (json.RawMessage, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return json.RawMessage("null"), nil
+	}
+	if data[0] == '[' {
+		var list []struct {
+			ID json.RawMessage `json:"id"`
+		}
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, err
+		}
+		ids := make([]json.RawMessage, len(list))
+		for i, item := range list {
+			ids[i] = orNull(item.ID)
+		}
+		return json.Marshal(ids)
+	}
+	var one struct {
+		ID json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal(data, &one); err != nil {
+		return nil, err
+	}
+	return orNull(one.ID), nil
+}
+
+// orNull retorna null si el id no vino en el documento
+func orNull(id json.RawMessage) json.RawMessage {
+	if len(id) == 0 {
+		return json.RawMessage("null")
+	}
+	return id
+}
@@ -0,0 +1,378 @@
+package jsonpatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Content-Type de cada formato de patch
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+var (
+	// ErrInvalidPatch el patch no es valido o apunta a una ruta que no existe
+	ErrInvalidPatch = errors.New("el patch no es válido")
+	// ErrTestFailed una operacion test no se cumplio, el recurso cambio (se responde 409)
+	ErrTestFailed = errors.New("la operación test del patch no se cumplió")
+)
+
+// Operation operacion de JSON Patch (RFC 6902)
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// MergePatch aplica un JSON Merge Patch (RFC 7396) al documento
+// las llaves con null se eliminan, los objetos se mezclan y lo demas se reemplaza
+func MergePatch(document []byte, patch []byte) ([]byte, error) {
+	doc, err := decode(document)
+	if err != nil {
+		return nil, err
+	}
+	p, err := decode(patch)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	return json.Marshal(merge(doc, p))
+}
+
+// merge mezcla el patch sobre el documento
+func merge(doc any, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	d, ok := doc.(map[string]any)
+	if !ok {
+		d = map[string]any{}
+	}
+	for key, value := range p {
+		if value == nil {
+			delete(d, key)
+			continue
+		}
+		d[key] = merge(d[key], value)
+	}
+	return d
+}
+
+// Apply aplica un JSON Patch (RFC 6902) al documento
+// las operaciones se aplican en orden y si alguna falla no se aplica ninguna
+func Apply(document []byte, patch []byte) ([]byte, error) {
+	doc, err := decode(document)
+	if err != nil {
+		return nil, err
+	}
+	var ops []Operation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, fmt.Errorf("%w: debe ser un array de operaciones", ErrInvalidPatch)
+	}
+
+	for i, op := range ops {
+		if doc, err = applyOperation(doc, op); err != nil {
+			if errors.Is(err, ErrTestFailed) {
+				return nil, fmt.Errorf("%w (operación %d: %s)", ErrTestFailed, i, op.Path)
+			}
+			return nil, fmt.Errorf("%w: operación %d (%s %s): %v", ErrInvalidPatch, i, op.Op, op.Path, err)
+		}
+	}
+	return json.Marshal(doc)
+}
+
+// applyOperation aplica una operacion y retorna el documento resultante
+func applyOperation(doc any, op Operation) (any, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if len(op.Value) == 0 {
+			return nil, errors.New("falta value")
+		}
+		value, err := decode(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		switch op.Op {
+		case "add":
+			return add(doc, path, value)
+		case "replace":
+			if _, err := get(doc, path); err != nil {
+				return nil, err
+			}
+			if doc, err = remove(doc, path); err != nil {
+				return nil, err
+			}
+			return add(doc, path, value)
+		default:
+			current, err := get(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			if !equal(current, value) {
+				return nil, ErrTestFailed
+			}
+			return doc, nil
+		}
+
+	case "remove":
+		return remove(doc, path)
+
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := get(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "move" {
+			if isPrefix(from, path) && len(from) < len(path) {
+				return nil, errors.New("no se puede mover un valor dentro de si mismo")
+			}
+			if doc, err = remove(doc, from); err != nil {
+				return nil, err
+			}
+		} else {
+			value = deepCopy(value)
+		}
+		return add(doc, path, value)
+	}
+	return nil, fmt.Errorf("operación '%s' no soportada", op.Op)
+}
+
+// decode deserializa conservando los numeros exactos
+func decode(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// parsePointer convierte un JSON Pointer (RFC 6901) en sus segmentos
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("la ruta '%s' debe empezar con /", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		t = strings.ReplaceAll(t, "~1", "/")
+		tokens[i] = strings.ReplaceAll(t, "~0", "~")
+	}
+	return tokens, nil
+}
+
+// get retorna el valor en la ruta
+func get(doc any, path []string) (any, error) {
+	current := doc
+	for _, token := range path {
+		switch node := current.(type) {
+		case map[string]any:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("la ruta /%s no existe", strings.Join(path, "/"))
+			}
+			current = value
+		case []any:
+			i, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			current = node[i]
+		default:
+			return nil, fmt.Errorf("la ruta /%s no existe", strings.Join(path, "/"))
+		}
+	}
+	return current, nil
+}
+
+// add agrega o reemplaza el valor en la ruta, en los arrays inserta en la posicion ("-" al final)
+func add(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return update(doc, path, func(parent any, token string) (any, error) {
+		switch node := parent.(type) {
+		case map[string]any:
+			node[token] = value
+			return node, nil
+		case []any:
+			i, err := arrayIndex(token, len(node), true)
+			if err != nil {
+				return nil, err
+			}
+			node = append(node, nil)
+			copy(node[i+1:], node[i:])
+			node[i] = value
+			return node, nil
+		}
+		return nil, errors.New("el destino no es un objeto ni un array")
+	})
+}
+
+// remove elimina el valor en la ruta
+func remove(doc any, path []string) (any, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+	return update(doc, path, func(parent any, token string) (any, error) {
+		switch node := parent.(type) {
+		case map[string]any:
+			if _, ok := node[token]; !ok {
+				return nil, fmt.Errorf("la ruta /%s no existe", strings.Join(path, "/"))
+			}
+			delete(node, token)
+			return node, nil
+		case []any:
+			i, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			return append(node[:i], node[i+1:]...), nil
+		}
+		return nil, errors.New("el destino no es un objeto ni un array")
+	})
+}
+
+// update baja hasta el padre de la ruta, aplica fn y vuelve a asignar los contenedores modificados
+// (agregar a un slice puede cambiar su direccion)
+func update(node any, path []string, fn func(parent any, token string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return fn(node, path[0])
+	}
+	token := path[0]
+	switch n := node.(type) {
+	case map[string]any:
+		child, ok := n[token]
+		if !ok {
+			return nil, fmt.Errorf("la ruta /%s no existe", strings.Join(path, "/"))
+		}
+		updated, err := update(child, path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		n[token] = updated
+		return n, nil
+	case []any:
+		i, err := arrayIndex(token, len(n), false)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := update(n[i], path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		n[i] = updated
+		return n, nil
+	}
+	return nil, fmt.Errorf("la ruta /%s no existe", strings.Join(path, "/"))
+}
+
+// arrayIndex valida el indice del array, "-" solo se permite al agregar
+func arrayIndex(token string, length int, adding bool) (int, error) {
+	if token == "-" && adding {
+		return length, nil
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || (token != "0" && strings.HasPrefix(token, "0")) {
+		return 0, fmt.Errorf("indice '%s' inválido", token)
+	}
+	max := length - 1
+	if adding {
+		max = length
+	}
+	if i > max {
+		return 0, fmt.Errorf("indice %d fuera de rango", i)
+	}
+	return i, nil
+}
+
+// isPrefix indica si a es prefijo de b
+func isPrefix(a []string, b []string) bool {
+	if len(a) > len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// equal compara dos valores json, los numeros se comparan por su valor (1 y 1.0 son iguales)
+func equal(a any, b any) bool {
+	na, okA := a.(json.Number)
+	nb, okB := b.(json.Number)
+	if okA && okB {
+		fa, errA := na.Float64()
+		fb, errB := nb.Float64()
+		if errA == nil && errB == nil {
+			return fa == fb
+		}
+		return na == nb
+	}
+	switch va := a.(type) {
+	case map[string]any:
+		vb, ok := b.(map[string]any)
+		if !ok || len(va) != len(vb) {
+			return false
+		}
+		for k, v := range va {
+			w, ok := vb[k]
+			if !ok || !equal(v, w) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		vb, ok := b.([]any)
+		if !ok || len(va) != len(vb) {
+			return false
+		}
+		for i := range va {
+			if !equal(va[i], vb[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// deepCopy copia objetos y arrays para que copy no comparta memoria con el original
+func deepCopy(v any) any {
+	switch node := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(node))
+		for k, value := range node {
+			out[k] = deepCopy(value)
+		}
+		return out
+	case []any:
+		out := make([]any, len(node))
+		for i, value := range node {
+			out[i] = deepCopy(value)
+		}
+		return out
+	}
+	return v
+}
@@ -0,0 +1,139 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// sameJSON compara dos documentos sin importar el orden de las claves
+func sameJSON(t *testing.T, got []byte, want string) bool {
+	t.Helper()
+	var a, b any
+	if err := json.Unmarshal(got, &a); err != nil {
+		t.Fatalf("resultado inválido %s: %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(want), &b); err != nil {
+		t.Fatalf("esperado inválido %s: %v", want, err)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func TestMergePatch(t *testing.T) {
+	cases := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+	}{
+		{"reemplaza", `{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"agrega", `{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{"null elimina", `{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{"anidado", `{"a":{"b":1,"c":2}}`, `{"a":{"b":null,"d":3}}`, `{"a":{"c":2,"d":3}}`},
+		{"array se reemplaza", `{"a":[1,2]}`, `{"a":[3]}`, `{"a":[3]}`},
+		{"sobre un valor que no es objeto", `{"a":"b"}`, `{"a":{"c":1}}`, `{"a":{"c":1}}`},
+		{"patch que no es objeto", `{"a":"b"}`, `["c"]`, `["c"]`},
+	}
+	for _, c := range cases {
+		got, err := MergePatch([]byte(c.doc), []byte(c.patch))
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if !sameJSON(t, got, c.want) {
+			t.Errorf("%s: se esperaba %s, se obtuvo %s", c.name, c.want, got)
+		}
+	}
+
+	// los numeros grandes no pasan por float64
+	if got, _ := MergePatch([]byte(`{"id":9007199254740993}`), []byte(`{"n":1}`)); string(got) != `{"id":9007199254740993,"n":1}` {
+		t.Errorf("se esperaba el id exacto, se obtuvo %s", got)
+	}
+	if _, err := MergePatch([]byte(`{}`), []byte(`{`)); !errors.Is(err, ErrInvalidPatch) {
+		t.Errorf("un patch mal formado debe retornar ErrInvalidPatch, se obtuvo %v", err)
+	}
+}
+
+func TestApply(t *testing.T) {
+	cases := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+	}{
+		{"add en objeto", `{"a":1}`, `[{"op":"add","path":"/b","value":2}]`, `{"a":1,"b":2}`},
+		{"add en array", `{"a":[1,3]}`, `[{"op":"add","path":"/a/1","value":2}]`, `{"a":[1,2,3]}`},
+		{"add al final", `{"a":[1]}`, `[{"op":"add","path":"/a/-","value":2}]`, `{"a":[1,2]}`},
+		{"add raiz", `{"a":1}`, `[{"op":"add","path":"","value":[1]}]`, `[1]`},
+		{"remove", `{"a":1,"b":2}`, `[{"op":"remove","path":"/a"}]`, `{"b":2}`},
+		{"remove en array", `{"a":[1,2,3]}`, `[{"op":"remove","path":"/a/1"}]`, `{"a":[1,3]}`},
+		{"replace", `{"a":{"b":1}}`, `[{"op":"replace","path":"/a/b","value":"x"}]`, `{"a":{"b":"x"}}`},
+		{"move", `{"a":{"b":1},"c":{}}`, `[{"op":"move","from":"/a/b","path":"/c/d"}]`, `{"a":{},"c":{"d":1}}`},
+		{"copy", `{"a":{"b":[1]}}`, `[{"op":"copy","from":"/a/b","path":"/c"}]`, `{"a":{"b":[1]},"c":[1]}`},
+		{"test", `{"a":1}`, `[{"op":"test","path":"/a","value":1.0}]`, `{"a":1}`},
+		{"puntero escapado", `{"a/b":1,"c~d":2}`, `[{"op":"remove","path":"/a~1b"},{"op":"replace","path":"/c~0d","value":3}]`, `{"c~d":3}`},
+		{"en orden", `{}`, `[{"op":"add","path":"/a","value":[]},{"op":"add","path":"/a/-","value":1}]`, `{"a":[1]}`},
+	}
+	for _, c := range cases {
+		got, err := Apply([]byte(c.doc), []byte(c.patch))
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if !sameJSON(t, got, c.want) {
+			t.Errorf("%s: se esperaba %s, se obtuvo %s", c.name, c.want, got)
+		}
+	}
+}
+
+func TestApplyCopyDoesNotShare(t *testing.T) {
+	got, err := Apply([]byte(`{"a":{"b":1}}`), []byte(`[
+		{"op":"copy","from":"/a","path":"/c"},
+		{"op":"replace","path":"/c/b","value":2}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameJSON(t, got, `{"a":{"b":1},"c":{"b":2}}`) {
+		t.Errorf("copy no debe compartir el valor con el original, se obtuvo %s", got)
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		doc   string
+		patch string
+		want  error
+	}{
+		{"no es array", `{}`, `{"op":"add"}`, ErrInvalidPatch},
+		{"operacion desconocida", `{}`, `[{"op":"merge","path":"/a"}]`, ErrInvalidPatch},
+		{"sin value", `{}`, `[{"op":"add","path":"/a"}]`, ErrInvalidPatch},
+		{"ruta sin /", `{}`, `[{"op":"add","path":"a","value":1}]`, ErrInvalidPatch},
+		{"remove inexistente", `{}`, `[{"op":"remove","path":"/a"}]`, ErrInvalidPatch},
+		{"replace inexistente", `{}`, `[{"op":"replace","path":"/a","value":1}]`, ErrInvalidPatch},
+		{"indice fuera de rango", `{"a":[1]}`, `[{"op":"add","path":"/a/2","value":1}]`, ErrInvalidPatch},
+		{"indice con cero a la izquierda", `{"a":[1,2]}`, `[{"op":"remove","path":"/a/01"}]`, ErrInvalidPatch},
+		{"- fuera de add", `{"a":[1]}`, `[{"op":"remove","path":"/a/-"}]`, ErrInvalidPatch},
+		{"mover dentro de si mismo", `{"a":{"b":{}}}`, `[{"op":"move","from":"/a","path":"/a/b/c"}]`, ErrInvalidPatch},
+		{"test distinto", `{"a":1}`, `[{"op":"test","path":"/a","value":2}]`, ErrTestFailed},
+		{"test tipo distinto", `{"a":"1"}`, `[{"op":"test","path":"/a","value":1}]`, ErrTestFailed},
+	}
+	for _, c := range cases {
+		if _, err := Apply([]byte(c.doc), []byte(c.patch)); !errors.Is(err, c.want) {
+			t.Errorf("%s: se esperaba %v, se obtuvo %v", c.name, c.want, err)
+		}
+	}
+}
+
+func TestApplyIsAtomic(t *testing.T) {
+	doc := []byte(`{"a":1}`)
+	got, err := Apply(doc, []byte(`[{"op":"add","path":"/b","value":2},{"op":"test","path":"/a","value":2}]`))
+	if !errors.Is(err, ErrTestFailed) || got != nil {
+		t.Errorf("si una operación falla no se debe retornar documento, se obtuvo %s, %v", got, err)
+	}
+	if string(doc) != `{"a":1}` {
+		t.Errorf("el documento original no se debe modificar, se obtuvo %s", doc)
+	}
+}
@@ -0,0 +1,377 @@
+package sanitize
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"sync"
+)
+
+// Policy etiquetas y atributos permitidos, todo lo demas se elimina
+// los atributos on* (onclick, onerror) y las urls con esquemas que no estan en URLSchemes nunca se permiten
+type Policy struct {
+	Tags       map[string][]string // etiqueta -> atributos permitidos
+	URLSchemes []string            // esquemas permitidos en href y src (http, https, mailto)
+}
+
+// ViolationError contenido que la politica no permite, lo retorna Check
+type ViolationError struct {
+	Reason string
+}
+
+func (e *ViolationError) Error() string {
+	return e.Reason
+}
+
+var (
+	// Strict no permite ninguna etiqueta, solo texto
+	Strict = &Policy{}
+
+	// Basic formato de texto sin enlaces ni imagenes
+	Basic = &Policy{Tags: map[string][]string{
+		"b": nil, "strong": nil, "i": nil, "em": nil, "u": nil, "s": nil, "br": nil, "p": nil,
+		"ul": nil, "ol": nil, "li": nil, "blockquote": nil, "code": nil, "pre": nil,
+	}}
+
+	// Links Basic mas enlaces
+	Links = Basic.With(&Policy{
+		Tags:       map[string][]string{"a": {"href", "title"}},
+		URLSchemes: []string{"http", "https", "mailto"},
+	})
+
+	// Rich contenido de un editor: titulos, imagenes y tablas
+	Rich = Links.With(&Policy{Tags: map[string][]string{
+		"h1": nil, "h2": nil, "h3": nil, "h4": nil, "h5": nil, "h6": nil, "hr": nil, "span": nil, "div": nil,
+		"img":   {"src", "alt", "title", "width", "height"},
+		"table": nil, "thead": nil, "tbody": nil, "tr": nil, "th": {"colspan", "rowspan"}, "td": {"colspan", "rowspan"},
+	}})
+)
+
+// policies registro de politicas por nombre, las usa la regla safe_html
+var policies = map[string]*Policy{
+	"strict": Strict,
+	"basic":  Basic,
+	"links":  Links,
+	"rich":   Rich,
+}
+
+// RegisterPolicy agrega o reemplaza una politica en el registro
+// se debe llamar al iniciar la aplicacion
+func RegisterPolicy(name string, p *Policy) {
+	policies[name] = p
+}
+
+// Lookup retorna la politica registrada con el nombre
+func Lookup(name string) (*Policy, bool) {
+	p, ok := policies[name]
+	return p, ok
+}
+
+// With retorna una politica nueva con las etiquetas, atributos y esquemas de las dos
+func (p *Policy) With(other *Policy) *Policy {
+	out := &Policy{Tags: make(map[string][]string, len(p.Tags)+len(other.Tags))}
+	for _, src := range []*Policy{p, other} {
+		for tag, attrs := range src.Tags {
+			out.Tags[tag] = append(append([]string{}, out.Tags[tag]...), attrs...)
+		}
+		out.URLSchemes = append(out.URLSchemes, src.URLSchemes...)
+	}
+	return out
+}
+
+// HTML limpia el texto con la politica, lo que no esta permitido se elimina y el texto se escapa
+//
+//	post.Body = sanitize.HTML(sanitize.Links, post.Body)
+func HTML(p *Policy, s string) string {
+	out, _ := p.run(s, false)
+	return out
+}
+
+// Check retorna un ViolationError con lo primero que la politica no permite
+func (p *Policy) Check(s string) error {
+	_, err := p.run(s, true)
+	return err
+}
+
+// HasHTML indica si el texto tiene etiquetas, comentarios o declaraciones html
+func HasHTML(s string) bool {
+	return Strict.Check(s) != nil
+}
+
+// rawTextTags etiquetas cuyo contenido se elimina completo, no solo la etiqueta
+var rawTextTags = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "embed": true,
+	"noscript": true, "textarea": true, "title": true, "xmp": true, "template": true, "noembed": true, "noframes": true,
+}
+
+// voidTags etiquetas que no se cierran
+var voidTags = map[string]bool{"br": true, "hr": true, "img": true, "wbr": true}
+
+// urlAttrs atributos que contienen urls
+var urlAttrs = map[string]bool{
+	"href": true, "src": true, "cite": true, "action": true, "formaction": true,
+	"poster": true, "background": true, "xlink:href": true,
+}
+
+// run recorre el html, si check es true se detiene en la primera violacion
+func (p *Policy) run(s string, check bool) (string, error) {
+	var b strings.Builder
+	var open []string
+	violation := func(format string, args ...any) error {
+		return &ViolationError{Reason: fmt.Sprintf(format, args...)}
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c != '<' {
+			if c == '>' {
+				b.WriteString("&gt;")
+			} else {
+				b.WriteByte(c)
+			}
+			i++
+			continue
+		}
+
+		rest := s[i:]
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			if check {
+				return "", violation("los comentarios html no están permitidos")
+			}
+			end := strings.Index(rest[4:], "-->")
+			if end < 0 {
+				i = len(s)
+			} else {
+				i += 4 + end + 3
+			}
+
+		case len(rest) > 1 && (rest[1] == '!' || rest[1] == '?'):
+			if check {
+				return "", violation("las declaraciones html no están permitidas")
+			}
+			end := strings.IndexByte(rest, '>')
+			if end < 0 {
+				i = len(s)
+			} else {
+				i += end + 1
+			}
+
+		case len(rest) > 2 && rest[1] == '/' && isLetter(rest[2]):
+			name, _, n, ok := parseTag(rest[2:])
+			if !ok {
+				b.WriteString("&lt;")
+				i++
+				continue
+			}
+			i += 2 + n
+			if _, allowed := p.Tags[name]; !allowed {
+				if check {
+					return "", violation("la etiqueta <%s> no está permitida", name)
+				}
+				continue
+			}
+			// solo se cierra si esta abierta, asi el html resultante queda balanceado
+			for j := len(open) - 1; j >= 0; j-- {
+				if open[j] == name {
+					closeTags(&b, open[j:])
+					open = open[:j]
+					break
+				}
+			}
+
+		case len(rest) > 1 && isLetter(rest[1]):
+			name, attrs, n, ok := parseTag(rest[1:])
+			if !ok {
+				b.WriteString("&lt;")
+				i++
+				continue
+			}
+			i += 1 + n
+			allowedAttrs, allowed := p.Tags[name]
+			if !allowed {
+				if check {
+					return "", violation("la etiqueta <%s> no está permitida", name)
+				}
+				if rawTextTags[name] {
+					i += skipRawText(s[i:], name)
+				}
+				continue
+			}
+
+			b.WriteString("<" + name)
+			for _, a := range attrs {
+				if !contains(allowedAttrs, a.name) || strings.HasPrefix(a.name, "on") {
+					if check {
+						return "", violation("el atributo %s de <%s> no está permitido", a.name, name)
+					}
+					continue
+				}
+				if urlAttrs[a.name] && !p.safeURL(a.value) {
+					if check {
+						return "", violation("la url del atributo %s de <%s> no está permitida", a.name, name)
+					}
+					continue
+				}
+				b.WriteString(" " + a.name + `="` + html.EscapeString(a.value) + `"`)
+			}
+			b.WriteString(">")
+			if !voidTags[name] {
+				open = append(open, name)
+			}
+
+		default:
+			// un < que no abre una etiqueta es texto
+			b.WriteString("&lt;")
+			i++
+		}
+	}
+
+	closeTags(&b, open)
+	return b.String(), nil
+}
+
+// attr atributo de una etiqueta con el valor ya decodificado
+type attr struct {
+	name  string
+	value string
+}
+
+// parseTag lee el nombre y los atributos hasta el > y retorna cuantos bytes consumio
+// si la etiqueta no se cierra ok es false y se trata como texto
+func parseTag(s string) (name string, attrs []attr, n int, ok bool) {
+	i := 0
+	for i < len(s) && isNameChar(s[i]) {
+		i++
+	}
+	name = strings.ToLower(s[:i])
+
+	for i < len(s) {
+		for i < len(s) && (isSpace(s[i]) || s[i] == '/') {
+			i++
+		}
+		if i >= len(s) {
+			break
+		}
+		if s[i] == '>' {
+			return name, attrs, i + 1, true
+		}
+
+		start := i
+		for i < len(s) && !isSpace(s[i]) && s[i] != '=' && s[i] != '>' && s[i] != '/' {
+			i++
+		}
+		a := attr{name: strings.ToLower(s[start:i])}
+		for i < len(s) && isSpace(s[i]) {
+			i++
+		}
+		if i < len(s) && s[i] == '=' {
+			i++
+			for i < len(s) && isSpace(s[i]) {
+				i++
+			}
+			if i < len(s) && (s[i] == '"' || s[i] == '\'') {
+				quote := s[i]
+				end := strings.IndexByte(s[i+1:], quote)
+				if end < 0 {
+					return name, nil, 0, false
+				}
+				a.value = s[i+1 : i+1+end]
+				i += end + 2
+			} else {
+				start := i
+				for i < len(s) && !isSpace(s[i]) && s[i] != '>' {
+					i++
+				}
+				a.value = s[start:i]
+			}
+			a.value = html.UnescapeString(a.value)
+		}
+		if a.name != "" {
+			attrs = append(attrs, a)
+		}
+	}
+	return name, nil, 0, false
+}
+
+// skipRawText salta el contenido de script, style, etc hasta su etiqueta de cierre
+func skipRawText(s string, name string) int {
+	lower := strings.ToLower(s)
+	end := strings.Index(lower, "</"+name)
+	if end < 0 {
+		return len(s)
+	}
+	gt := strings.IndexByte(s[end:], '>')
+	if gt < 0 {
+		return len(s)
+	}
+	return end + gt + 1
+}
+
+// safeURL indica si la url es relativa o usa un esquema permitido
+// se quitan los espacios y caracteres de control que los navegadores ignoran (java\tscript:)
+func (p *Policy) safeURL(value string) bool {
+	clean := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, value)
+	colon := strings.IndexByte(clean, ':')
+	if colon < 0 || strings.ContainsAny(clean[:colon], "/?#") {
+		return true
+	}
+	scheme := strings.ToLower(clean[:colon])
+	return contains(p.URLSchemes, scheme)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// closeTags cierra las etiquetas abiertas de la ultima a la primera
+func closeTags(b *strings.Builder, open []string) {
+	for i := len(open) - 1; i >= 0; i-- {
+		b.WriteString("</" + open[i] + ">")
+	}
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isNameChar(c byte) bool {
+	return isLetter(c) || c >= '0' && c <= '9' || c == '-' || c == ':'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// cache de politicas combinadas por la regla safe_html
+var combined sync.Map
+
+// Combine une las politicas registradas con esos nombres, los nombres que no son politicas
+// se toman como etiquetas permitidas sin atributos ("basic,h2,h3")
+func Combine(names ...string) *Policy {
+	key := strings.Join(names, ",")
+	if cached, ok := combined.Load(key); ok {
+		return cached.(*Policy)
+	}
+	out := &Policy{Tags: map[string][]string{}}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if p, ok := policies[name]; ok {
+			out = out.With(p)
+		} else if name != "" {
+			out.Tags[strings.ToLower(name)] = nil
+		}
+	}
+	combined.Store(key, out)
+	return out
+}
@@ -0,0 +1,114 @@
+package sanitize
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHTML(t *testing.T) {
+	cases := []struct {
+		name   string
+		policy *Policy
+		in     string
+		want   string
+	}{
+		{"texto", Strict, "hola mundo", "hola mundo"},
+		{"strict quita etiquetas", Strict, "<b>hola</b>", "hola"},
+		{"script completo", Basic, "a<script>alert(1)</script>b", "ab"},
+		{"script en mayusculas", Basic, "a<SCRIPT>alert(1)</ScRiPt>b", "ab"},
+		{"comentario", Basic, "a<!-- x -->b", "ab"},
+		{"declaracion", Basic, "<!DOCTYPE html>a", "a"},
+		{"permitida", Basic, "<b>hola</b>", "<b>hola</b>"},
+		{"atributo no permitido", Basic, `<p class="x">a</p>`, "<p>a</p>"},
+		{"on* nunca", Rich, `<img src="a.png" onerror="alert(1)">`, `<img src="a.png">`},
+		{"enlace", Links, `<a href="https://x.co" title="x">a</a>`, `<a href="https://x.co" title="x">a</a>`},
+		{"javascript", Links, `<a href="javascript:alert(1)">a</a>`, "<a>a</a>"},
+		{"javascript con tab", Links, "<a href=\"java\tscript:alert(1)\">a</a>", "<a>a</a>"},
+		{"javascript con entidades", Links, `<a href="&#106;avascript:alert(1)">a</a>`, "<a>a</a>"},
+		{"url relativa", Links, `<a href="/users?a=b">a</a>`, `<a href="/users?a=b">a</a>`},
+		{"valor escapado", Links, `<a title='"x"'>a</a>`, `<a title="&#34;x&#34;">a</a>`},
+		{"cierra las abiertas", Basic, "<b><i>a", "<b><i>a</i></b>"},
+		{"cierre sin abrir", Basic, "a</b>", "a"},
+		{"cierre cruzado", Basic, "<b><i>a</b>c</i>", "<b><i>a</i></b>c"},
+		{"void", Basic, "a<br>b", "a<br>b"},
+		{"< como texto", Strict, "1 < 2 > 0", "1 &lt; 2 &gt; 0"},
+		{"etiqueta sin cerrar", Strict, `<a href="x`, `&lt;a href="x`},
+	}
+	for _, c := range cases {
+		if got := HTML(c.policy, c.in); got != c.want {
+			t.Errorf("%s: se esperaba %q, se obtuvo %q", c.name, c.want, got)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	cases := []struct {
+		policy *Policy
+		in     string
+		ok     bool
+	}{
+		{Strict, "texto plano", true},
+		{Strict, "<b>a</b>", false},
+		{Basic, "<b>a</b>", true},
+		{Basic, "<!-- x -->", false},
+		{Basic, `<p style="x">a</p>`, false},
+		{Links, `<a href="https://x.co">a</a>`, true},
+		{Links, `<a href="data:text/html,x">a</a>`, false},
+		{Links, `<a onclick="x">a</a>`, false},
+	}
+	for _, c := range cases {
+		err := c.policy.Check(c.in)
+		if (err == nil) != c.ok {
+			t.Errorf("Check(%q) = %v, se esperaba ok=%v", c.in, err, c.ok)
+		}
+		var violation *ViolationError
+		if err != nil && !errors.As(err, &violation) {
+			t.Errorf("Check(%q) debe retornar un ViolationError, se obtuvo %T", c.in, err)
+		}
+	}
+}
+
+func TestHasHTML(t *testing.T) {
+	cases := map[string]bool{
+		"hola":          false,
+		"1 < 2":         false,
+		"<b>a</b>":      true,
+		"a <!-- x -->":  true,
+		"<?xml?>":       true,
+		"correo <a@b.c": false,
+	}
+	for in, want := range cases {
+		if got := HasHTML(in); got != want {
+			t.Errorf("HasHTML(%q) = %v, se esperaba %v", in, got, want)
+		}
+	}
+}
+
+func TestCombine(t *testing.T) {
+	p := Combine("basic", "h2")
+	if _, ok := p.Tags["h2"]; !ok {
+		t.Errorf("los nombres que no son politicas se deben tomar como etiquetas")
+	}
+	if _, ok := p.Tags["b"]; !ok {
+		t.Errorf("se esperaban las etiquetas de basic")
+	}
+	if _, ok := p.Tags["a"]; ok {
+		t.Errorf("basic no permite enlaces")
+	}
+	if Combine("basic", "h2") != p {
+		t.Errorf("se esperaba la politica en cache")
+	}
+
+	links := Combine("links")
+	if got := HTML(links, `<a href="mailto:a@b.co">a</a>`); got != `<a href="mailto:a@b.co">a</a>` {
+		t.Errorf("se esperaban los esquemas de links, se obtuvo %q", got)
+	}
+}
+
+func TestWithDoesNotModify(t *testing.T) {
+	base := &Policy{Tags: map[string][]string{"a": {"href"}}}
+	_ = base.With(&Policy{Tags: map[string][]string{"a": {"title"}}})
+	if len(base.Tags["a"]) != 1 {
+		t.Errorf("With no debe modificar la politica original, se obtuvo %v", base.Tags["a"])
+	}
+}
@@ -0,0 +1,380 @@
+// Package search interpreta la sintaxis de busqueda del parametro q de los listados
+//
+//	q=laptop "16 GB" status:active price:100..500 (brand:acme OR brand:globex) -refurbished
+//
+// palabras y "frases" buscan en el texto, campo:valor y campo:"frase" en un campo, AND es implicito
+// entre terminos, OR une alternativas (se evalua despues de AND), NOT o - niega y los parentesis agrupan.
+// los rangos son campo:10..20 (inclusivo), campo:10.. o campo:..20 (abiertos, * tambien) y campo:>=10,
+// campo:>10, campo:<20, campo:<=20. AND, OR y NOT solo son operadores en mayusculas
+//
+// el resultado es un arbol (Node) que las reglas search_fields y search_ranges restringen y que
+// orm.SearchSQL y orm.SearchFilter traducen a la base de datos. los campos de tipo Query se llenan desde
+// el json y la url (tag query del FormRequest)
+package search
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limites de la busqueda, una consulta mas grande se rechaza antes de llegar a la base de datos
+var (
+	MaxLength = 512 // caracteres
+	MaxTerms  = 20  // terminos y rangos
+	MaxDepth  = 8   // parentesis anidados
+)
+
+// ErrSyntax la busqueda no se pudo interpretar, los errores de Parse lo envuelven
+var ErrSyntax = errors.New("la búsqueda no es válida")
+
+// Kind tipo de nodo del arbol
+type Kind string
+
+const (
+	And   Kind = "and"
+	Or    Kind = "or"
+	Not   Kind = "not"
+	Term  Kind = "term"  // palabra o frase, en el texto (Field vacio) o en un campo
+	Range Kind = "range" // campo con limites
+)
+
+// Node nodo del arbol de la busqueda
+type Node struct {
+	Kind     Kind    `json:"kind"`
+	Field    string  `json:"field,omitempty"`
+	Value    string  `json:"value,omitempty"`  // Term
+	Phrase   bool    `json:"phrase,omitempty"` // Term entre comillas
+	Min      string  `json:"min,omitempty"`    // Range, vacio sin limite inferior
+	Max      string  `json:"max,omitempty"`    // Range, vacio sin limite superior
+	MinOpen  bool    `json:"min_open,omitempty"`
+	MaxOpen  bool    `json:"max_open,omitempty"` // el limite no se incluye (> y <)
+	Children []*Node `json:"children,omitempty"` // And, Or y Not (uno)
+}
+
+// Query busqueda interpretada, la zero value es una busqueda vacia
+type Query struct {
+	Raw  string
+	Root *Node // nil si la busqueda esta vacia
+}
+
+// fieldName nombres de campo aceptados antes de los dos puntos
+var fieldName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// Parse interpreta la busqueda, una busqueda vacia no es un error
+func Parse(s string) (Query, error) {
+	if utf8.RuneCountInString(s) > MaxLength {
+		return Query{}, fmt.Errorf("%w: tiene más de %d caracteres", ErrSyntax, MaxLength)
+	}
+	tokens, err := tokenize(s)
+	if err != nil {
+		return Query{}, err
+	}
+	if len(tokens) == 0 {
+		return Query{Raw: s}, nil
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.or(0)
+	if err != nil {
+		return Query{}, err
+	}
+	if p.pos < len(p.tokens) {
+		return Query{}, fmt.Errorf("%w: sobra '%s' en la posición %d", ErrSyntax, p.tokens[p.pos].text, p.tokens[p.pos].at)
+	}
+	if p.terms > MaxTerms {
+		return Query{}, fmt.Errorf("%w: tiene más de %d términos", ErrSyntax, MaxTerms)
+	}
+	return Query{Raw: s, Root: root}, nil
+}
+
+// MustParse igual que Parse pero entra en panico si la busqueda no es valida, para constantes
+func MustParse(s string) Query {
+	q, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// IsZero indica si la busqueda esta vacia
+func (q Query) IsZero() bool {
+	return q.Root == nil
+}
+
+// String retorna la busqueda como la envio el cliente
+func (q Query) String() string {
+	return q.Raw
+}
+
+// MarshalText serializa la busqueda como texto en el json
+func (q Query) MarshalText() ([]byte, error) {
+	return []byte(q.Raw), nil
+}
+
+// UnmarshalText lee la busqueda desde el json
+func (q *Query) UnmarshalText(b []byte) error {
+	parsed, err := Parse(string(b))
+	if err != nil {
+		return err
+	}
+	*q = parsed
+	return nil
+}
+
+// FromRequestValue llena la busqueda desde la url (request.ParamUnmarshaler)
+func (q *Query) FromRequestValue(value string) error {
+	return q.UnmarshalText([]byte(value))
+}
+
+// Walk recorre los nodos en orden, si fn retorna false no entra en los hijos del nodo
+func (q Query) Walk(fn func(n *Node) bool) {
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		if n == nil || !fn(n) {
+			return
+		}
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+	walk(q.Root)
+}
+
+// Fields campos usados en la busqueda sin repetir, en el orden en que aparecen
+func (q Query) Fields() []string {
+	var fields []string
+	q.Walk(func(n *Node) bool {
+		if n.Field != "" && !slices.Contains(fields, n.Field) {
+			fields = append(fields, n.Field)
+		}
+		return true
+	})
+	return fields
+}
+
+// token pieza de la busqueda
+type token struct {
+	kind   Kind // And, Or, Not, Term o "" para los parentesis
+	text   string
+	field  string
+	phrase bool
+	at     int // posicion en la busqueda para los errores
+}
+
+// tokenize separa la busqueda en operadores, parentesis y terminos
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(s) {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, token{text: string(c), at: i})
+			i++
+		case c == '-' && i+1 < len(s) && !strings.ContainsRune(" \t\n\r)", rune(s[i+1])):
+			tokens = append(tokens, token{kind: Not, text: "-", at: i})
+			i++
+		case c == '"':
+			value, end, err := readPhrase(s, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: Term, text: value, phrase: true, at: i})
+			i = end
+		default:
+			start := i
+			for i < len(s) && !strings.ContainsRune(" \t\n\r()\"", rune(s[i])) {
+				i++
+			}
+			word := s[start:i]
+			switch word {
+			case "AND":
+				tokens = append(tokens, token{kind: And, text: word, at: start})
+				continue
+			case "OR":
+				tokens = append(tokens, token{kind: Or, text: word, at: start})
+				continue
+			case "NOT":
+				tokens = append(tokens, token{kind: Not, text: word, at: start})
+				continue
+			}
+			t := token{kind: Term, text: word, at: start}
+			if field, value, ok := strings.Cut(word, ":"); ok && fieldName.MatchString(field) {
+				t.field, t.text = field, value
+				if value == "" && i < len(s) && s[i] == '"' {
+					phrase, end, err := readPhrase(s, i)
+					if err != nil {
+						return nil, err
+					}
+					t.text, t.phrase = phrase, true
+					i = end
+				}
+				if t.text == "" {
+					return nil, fmt.Errorf("%w: el campo %s no tiene valor", ErrSyntax, field)
+				}
+			}
+			tokens = append(tokens, t)
+		}
+	}
+	return tokens, nil
+}
+
+// readPhrase lee la frase entre comillas que empieza en start, \" es una comilla dentro de la frase
+// retorna la frase y la posicion despues de la comilla de cierre
+func readPhrase(s string, start int) (string, int, error) {
+	var b strings.Builder
+	for i := start + 1; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && s[i+1] == '"':
+			b.WriteByte('"')
+			i++
+		case s[i] == '"':
+			return b.String(), i + 1, nil
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", 0, fmt.Errorf("%w: falta cerrar las comillas de la posición %d", ErrSyntax, start)
+}
+
+// parser descenso recursivo: or = and {OR and}, and = unary {[AND] unary}, unary = {NOT} primary
+type parser struct {
+	tokens []token
+	pos    int
+	terms  int
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *parser) or(depth int) (*Node, error) {
+	left, err := p.and(depth)
+	if err != nil {
+		return nil, err
+	}
+	node := left
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind != Or {
+			return node, nil
+		}
+		p.pos++
+		right, err := p.and(depth)
+		if err != nil {
+			return nil, err
+		}
+		node = join(Or, node, right)
+	}
+}
+
+func (p *parser) and(depth int) (*Node, error) {
+	node, err := p.unary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind == Or || t.text == ")" {
+			return node, nil
+		}
+		if t.kind == And {
+			p.pos++
+		}
+		right, err := p.unary(depth)
+		if err != nil {
+			return nil, err
+		}
+		node = join(And, node, right)
+	}
+}
+
+func (p *parser) unary(depth int) (*Node, error) {
+	t, ok := p.peek()
+	if ok && t.kind == Not {
+		p.pos++
+		child, err := p.unary(depth)
+		if err != nil {
+			return nil, err
+		}
+		return &Node{Kind: Not, Children: []*Node{child}}, nil
+	}
+	return p.primary(depth)
+}
+
+func (p *parser) primary(depth int) (*Node, error) {
+	t, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("%w: termina con un operador", ErrSyntax)
+	}
+	p.pos++
+	switch {
+	case t.text == "(" && t.kind == "":
+		if depth+1 > MaxDepth {
+			return nil, fmt.Errorf("%w: tiene más de %d paréntesis anidados", ErrSyntax, MaxDepth)
+		}
+		node, err := p.or(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if closing, ok := p.peek(); !ok || closing.text != ")" || closing.kind != "" {
+			return nil, fmt.Errorf("%w: falta cerrar el paréntesis de la posición %d", ErrSyntax, t.at)
+		}
+		p.pos++
+		return node, nil
+	case t.kind == Term:
+		p.terms++
+		return term(t)
+	}
+	return nil, fmt.Errorf("%w: no se esperaba '%s' en la posición %d", ErrSyntax, t.text, t.at)
+}
+
+// term convierte el token en un Term o en un Range si el valor de un campo es un rango
+func term(t token) (*Node, error) {
+	if t.field == "" || t.phrase {
+		return &Node{Kind: Term, Field: t.field, Value: t.text, Phrase: t.phrase}, nil
+	}
+	value := t.text
+	node := &Node{Kind: Range, Field: t.field}
+	switch {
+	case strings.HasPrefix(value, ">="):
+		node.Min = value[2:]
+	case strings.HasPrefix(value, "<="):
+		node.Max = value[2:]
+	case strings.HasPrefix(value, ">"):
+		node.Min, node.MinOpen = value[1:], true
+	case strings.HasPrefix(value, "<"):
+		node.Max, node.MaxOpen = value[1:], true
+	case strings.Contains(value, ".."):
+		node.Min, node.Max, _ = strings.Cut(value, "..")
+		node.Min = strings.TrimPrefix(node.Min, "*")
+		node.Max = strings.TrimSuffix(node.Max, "*")
+		if node.Min == "" && node.Max == "" {
+			return nil, fmt.Errorf("%w: el rango de %s no tiene límites", ErrSyntax, t.field)
+		}
+		return node, nil
+	default:
+		return &Node{Kind: Term, Field: t.field, Value: value}, nil
+	}
+	if node.Min == "" && node.Max == "" {
+		return nil, fmt.Errorf("%w: el campo %s no tiene valor", ErrSyntax, t.field)
+	}
+	return node, nil
+}
+
+// join une dos nodos con el operador, si alguno ya es del mismo operador se aplana
+func join(kind Kind, left, right *Node) *Node {
+	if left.Kind == kind {
+		left.Children = append(left.Children, right)
+		return left
+	}
+	return &Node{Kind: kind, Children: []*Node{left, right}}
+}
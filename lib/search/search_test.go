@@ -0,0 +1,150 @@
+package search
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// render arbol en una linea para comparar: (and a b), (not a), campo:valor, "frase", campo[min..max)
+func render(n *Node) string {
+	if n == nil {
+		return ""
+	}
+	switch n.Kind {
+	case And, Or, Not:
+		parts := []string{string(n.Kind)}
+		for _, c := range n.Children {
+			parts = append(parts, render(c))
+		}
+		return "(" + strings.Join(parts, " ") + ")"
+	case Range:
+		open, close := "[", "]"
+		if n.MinOpen {
+			open = "("
+		}
+		if n.MaxOpen {
+			close = ")"
+		}
+		return n.Field + open + n.Min + ".." + n.Max + close
+	}
+	value := n.Value
+	if n.Phrase {
+		value = `"` + value + `"`
+	}
+	if n.Field != "" {
+		return n.Field + ":" + value
+	}
+	return value
+}
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		q    string
+		want string
+	}{
+		// vacias
+		{"", ""},
+		{"  \t ", ""},
+		// precedencia: AND implicito antes que OR, los parentesis agrupan
+		{"laptop", "laptop"},
+		{"a b", "(and a b)"},
+		{"a AND b c", "(and a b c)"},
+		{"a b OR c", "(or (and a b) c)"},
+		{"a OR b c", "(or a (and b c))"},
+		{"a OR b OR c", "(or a b c)"},
+		{"(a OR b) c", "(and (or a b) c)"},
+		{"-a NOT b", "(and (not a) (not b))"},
+		{"NOT NOT a", "(not (not a))"},
+		{"-(a OR b)", "(not (or a b))"},
+		{"or and not", "(and or and not)"},
+		// comillas
+		{`"16 GB"`, `"16 GB"`},
+		{`"dice \"hola\""`, `"dice "hola""`},
+		{`name:"ana maria" x`, `(and name:"ana maria" x)`},
+		{`"a OR b"`, `"a OR b"`},
+		// campos y rangos
+		{"status:active", "status:active"},
+		{"brand.name:acme", "brand.name:acme"},
+		{"price:100..500", "price[100..500]"},
+		{"price:10..", "price[10..]"},
+		{"price:*..20", "price[..20]"},
+		{"price:>=10", "price[10..]"},
+		{"price:>10", "price(10..]"},
+		{"price:<=20", "price[..20]"},
+		{"price:<20", "price[..20)"},
+		{`price:">10"`, `price:">10"`},
+		// lo que no es un campo valido es una palabra
+		{"1x:foo", "1x:foo"},
+		{"a-b", "a-b"},
+		{"time:10:30", "time:10:30"},
+	}
+	for _, c := range cases {
+		q, err := Parse(c.q)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", c.q, err)
+			continue
+		}
+		if got := render(q.Root); got != c.want {
+			t.Errorf("Parse(%q) = %s, se esperaba %s", c.q, got, c.want)
+		}
+		if q.IsZero() != (c.want == "") || q.String() != c.q {
+			t.Errorf("Parse(%q): IsZero %v, String %q", c.q, q.IsZero(), q.String())
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := map[string]string{
+		"comillas sin cerrar":    `"abc`,
+		"frase de campo abierta": `name:"abc`,
+		"parentesis sin cerrar":  "(a OR b",
+		"parentesis sin abrir":   "a)",
+		"parentesis vacios":      "()",
+		"termina en OR":          "a OR",
+		"termina en NOT":         "a NOT",
+		"empieza con AND":        "AND a",
+		"campo sin valor":        "status:",
+		"campo con frase vacia":  `status:""`,
+		"rango sin limites":      "price:..",
+		"rango sin limite":       "price:>=",
+		"muy larga":              strings.Repeat("a", MaxLength+1),
+		"muchos terminos":        strings.Repeat("a ", MaxTerms+1),
+		"muy anidada":            strings.Repeat("(", MaxDepth+1) + "a" + strings.Repeat(")", MaxDepth+1),
+	}
+	for name, q := range cases {
+		if _, err := Parse(q); !errors.Is(err, ErrSyntax) {
+			t.Errorf("%s: Parse(%q) = %v, se esperaba ErrSyntax", name, q, err)
+		}
+	}
+	if _, err := Parse(strings.Repeat("(", MaxDepth) + "a" + strings.Repeat(")", MaxDepth)); err != nil {
+		t.Errorf("%d parentesis anidados deben ser validos: %v", MaxDepth, err)
+	}
+}
+
+func TestQueryFieldsAndJSON(t *testing.T) {
+	q := MustParse(`status:active (brand:acme OR brand:globex) price:1..2 -status:draft laptop`)
+	if got, want := q.Fields(), []string{"status", "brand", "price"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Fields() = %v, se esperaba %v", got, want)
+	}
+
+	var body struct {
+		Q Query `json:"q"`
+	}
+	if err := json.Unmarshal([]byte(`{"q":"a OR b"}`), &body); err != nil || render(body.Q.Root) != "(or a b)" {
+		t.Errorf("se esperaba (or a b), se obtuvo %s %v", render(body.Q.Root), err)
+	}
+	out, _ := json.Marshal(body)
+	if string(out) != `{"q":"a OR b"}` {
+		t.Errorf("se esperaba el texto original, se obtuvo %s", out)
+	}
+	if err := json.Unmarshal([]byte(`{"q":"a OR"}`), &body); !errors.Is(err, ErrSyntax) {
+		t.Errorf("se esperaba ErrSyntax desde el json, se obtuvo %v", err)
+	}
+	var fromURL Query
+	if err := fromURL.FromRequestValue("price:>5"); err != nil || render(fromURL.Root) != "price(5..]" {
+		t.Errorf("FromRequestValue: %s %v", render(fromURL.Root), err)
+	}
+}
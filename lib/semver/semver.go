@@ -0,0 +1,207 @@
+// Package semver versiones semanticas (semver 2.0.0) y restricciones de version
+//
+//	v, err := semver.Parse("1.4.2-beta.1+build.5")
+//	ok, err := semver.Satisfies(v, ">=1.2.0", "<2.0.0")
+//
+// los campos de tipo Version se llenan desde el json, la url y los tags query/path del FormRequest
+package semver
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// pattern expresion oficial de semver.org
+var pattern = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+// Version version semantica MAJOR.MINOR.PATCH con prerelease y build opcionales
+type Version struct {
+	Major      uint64
+	Minor      uint64
+	Patch      uint64
+	Prerelease string // beta.1
+	Build      string // no cuenta al comparar
+}
+
+// Parse interpreta la version, no acepta el prefijo v ni versiones incompletas (1.2)
+func Parse(s string) (Version, error) {
+	m := pattern.FindStringSubmatch(s)
+	if m == nil {
+		return Version{}, fmt.Errorf("la versión '%s' no es una versión semántica válida (1.2.3)", s)
+	}
+	var v Version
+	var err error
+	if v.Major, err = strconv.ParseUint(m[1], 10, 64); err != nil {
+		return Version{}, fmt.Errorf("la versión '%s' es demasiado grande", s)
+	}
+	if v.Minor, err = strconv.ParseUint(m[2], 10, 64); err != nil {
+		return Version{}, fmt.Errorf("la versión '%s' es demasiado grande", s)
+	}
+	if v.Patch, err = strconv.ParseUint(m[3], 10, 64); err != nil {
+		return Version{}, fmt.Errorf("la versión '%s' es demasiado grande", s)
+	}
+	v.Prerelease, v.Build = m[4], m[5]
+	return v, nil
+}
+
+// MustParse igual que Parse pero entra en panico si la version no es valida, para constantes
+func MustParse(s string) Version {
+	v, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// String retorna la version en su formato canonico
+func (v Version) String() string {
+	s := strconv.FormatUint(v.Major, 10) + "." + strconv.FormatUint(v.Minor, 10) + "." + strconv.FormatUint(v.Patch, 10)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// IsZero indica si la version no se lleno (0.0.0 sin prerelease)
+func (v Version) IsZero() bool {
+	return v == Version{}
+}
+
+// Compare retorna -1, 0 o 1 segun la precedencia de semver, el build no cuenta
+func (v Version) Compare(o Version) int {
+	if c := compareUint(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+	return comparePrerelease(v.Prerelease, o.Prerelease)
+}
+
+// MarshalText serializa la version como texto en el json
+func (v Version) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText lee la version desde el json
+func (v *Version) UnmarshalText(b []byte) error {
+	parsed, err := Parse(string(b))
+	if err != nil {
+		return err
+	}
+	*v = parsed
+	return nil
+}
+
+// FromRequestValue llena la version desde la url (request.ParamUnmarshaler)
+func (v *Version) FromRequestValue(value string) error {
+	return v.UnmarshalText([]byte(value))
+}
+
+// Satisfies indica si la version cumple todas las restricciones
+//
+//	>=1.2.0  >1.2.0  <=2.0.0  <2.0.0  =1.2.3  !=1.2.3  1.2.3
+//	^1.2.3   mismo major (>=1.2.3 <2.0.0), en 0.x mismo minor
+//	~1.2.3   mismo minor (>=1.2.3 <1.3.0)
+//
+// una restriccion mal escrita retorna error
+func Satisfies(v Version, constraints ...string) (bool, error) {
+	for _, c := range constraints {
+		ok, err := satisfies(v, strings.TrimSpace(c))
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// satisfies evalua una restriccion
+func satisfies(v Version, constraint string) (bool, error) {
+	op := ""
+	for _, prefix := range []string{">=", "<=", "!=", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(constraint, prefix) {
+			op = prefix
+			break
+		}
+	}
+	target, err := Parse(strings.TrimSpace(constraint[len(op):]))
+	if err != nil {
+		return false, errors.New("la restricción de versión '" + constraint + "' no es válida")
+	}
+	c := v.Compare(target)
+	switch op {
+	case ">=":
+		return c >= 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	case "<":
+		return c < 0, nil
+	case "!=":
+		return c != 0, nil
+	case "^":
+		if c < 0 {
+			return false, nil
+		}
+		if target.Major == 0 {
+			return v.Major == 0 && v.Minor == target.Minor, nil
+		}
+		return v.Major == target.Major, nil
+	case "~":
+		return c >= 0 && v.Major == target.Major && v.Minor == target.Minor, nil
+	}
+	return c == 0, nil
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// comparePrerelease una version sin prerelease es mayor que una con prerelease (1.0.0 > 1.0.0-beta)
+// los identificadores se comparan uno a uno: los numericos como numeros y son menores que los de texto
+func comparePrerelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.ParseUint(as[i], 10, 64)
+		bn, bErr := strconv.ParseUint(bs[i], 10, 64)
+		switch {
+		case aErr == nil && bErr == nil:
+			if c := compareUint(an, bn); c != 0 {
+				return c
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return compareUint(uint64(len(as)), uint64(len(bs)))
+}
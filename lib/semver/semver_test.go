@@ -0,0 +1,112 @@
+package semver
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		s    string
+		ok   bool
+		want Version
+	}{
+		{"0.0.0", true, Version{}},
+		{"1.2.3", true, Version{Major: 1, Minor: 2, Patch: 3}},
+		{"1.4.2-beta.1+build.5", true, Version{Major: 1, Minor: 4, Patch: 2, Prerelease: "beta.1", Build: "build.5"}},
+		{"1.0.0-0.3.7", true, Version{Major: 1, Prerelease: "0.3.7"}},
+		{"1.0.0-x-y-z.--", true, Version{Major: 1, Prerelease: "x-y-z.--"}},
+		{"1.0.0+20130313144700", true, Version{Major: 1, Build: "20130313144700"}},
+		{"18446744073709551615.0.0", true, Version{Major: 18446744073709551615}},
+		{"18446744073709551616.0.0", false, Version{}},
+		{"v1.2.3", false, Version{}},
+		{"1.2", false, Version{}},
+		{"1.2.3.4", false, Version{}},
+		{"01.2.3", false, Version{}},
+		{"1.2.3-01", false, Version{}},
+		{"1.2.3-", false, Version{}},
+		{"1.2.3+", false, Version{}},
+		{"1.2.3-beta..1", false, Version{}},
+		{" 1.2.3", false, Version{}},
+		{"", false, Version{}},
+	}
+	for _, c := range cases {
+		v, err := Parse(c.s)
+		if (err == nil) != c.ok {
+			t.Errorf("%q: se obtuvo %v, se esperaba ok=%v", c.s, err, c.ok)
+			continue
+		}
+		if v != c.want {
+			t.Errorf("%q: se obtuvo %+v, se esperaba %+v", c.s, v, c.want)
+		}
+		if c.ok && v.String() != c.s {
+			t.Errorf("%q: String() = %s", c.s, v)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	// orden del ejemplo de semver.org
+	ordered := []string{"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.1.0", "2.0.0", "10.0.0"}
+	versions := make([]Version, len(ordered))
+	for i := range ordered {
+		versions[len(ordered)-1-i] = MustParse(ordered[i])
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Compare(versions[j]) < 0 })
+	for i, v := range versions {
+		if v.String() != ordered[i] {
+			t.Errorf("posicion %d: se obtuvo %s, se esperaba %s", i, v, ordered[i])
+		}
+	}
+	if c := MustParse("1.0.0+a").Compare(MustParse("1.0.0+b")); c != 0 {
+		t.Errorf("el build no cuenta al comparar, se obtuvo %d", c)
+	}
+}
+
+func TestSatisfies(t *testing.T) {
+	cases := []struct {
+		version     string
+		constraints []string
+		want        bool
+	}{
+		{"1.4.2", []string{">=1.2.0", "<2.0.0"}, true},
+		{"2.0.0", []string{">=1.2.0", "<2.0.0"}, false},
+		{"2.0.0-rc.1", []string{"<2.0.0"}, true},
+		{"1.2.3", []string{"1.2.3"}, true},
+		{"1.2.3", []string{"=1.2.3"}, true},
+		{"1.2.3+build", []string{"=1.2.3"}, true},
+		{"1.2.4", []string{"!=1.2.3"}, true},
+		{"1.2.3", []string{">1.2.3"}, false},
+		{"1.2.3", []string{"<=1.2.3"}, true},
+		{"1.9.0", []string{"^1.2.3"}, true},
+		{"1.2.2", []string{"^1.2.3"}, false},
+		{"2.0.0", []string{"^1.2.3"}, false},
+		{"0.2.9", []string{"^0.2.3"}, true},
+		{"0.3.0", []string{"^0.2.3"}, false},
+		{"1.2.9", []string{"~1.2.3"}, true},
+		{"1.3.0", []string{"~1.2.3"}, false},
+		{"1.2.3", []string{" >= 1.0.0 "}, true},
+		{"1.2.3", nil, true},
+	}
+	for _, c := range cases {
+		got, err := Satisfies(MustParse(c.version), c.constraints...)
+		if err != nil || got != c.want {
+			t.Errorf("%s %v: se obtuvo %v %v, se esperaba %v", c.version, c.constraints, got, err, c.want)
+		}
+	}
+
+	for _, bad := range []string{">=1.2", "^v1.0.0", ">>1.0.0", ""} {
+		if _, err := Satisfies(MustParse("1.0.0"), bad); err == nil {
+			t.Errorf("%q: se esperaba error con la restriccion mal escrita", bad)
+		}
+	}
+}
+
+func TestMustParsePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("MustParse debe entrar en panico con una version invalida")
+		}
+	}()
+	MustParse("1.2")
+}
@@ -0,0 +1,241 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/donbarrigon/new-project/lib/crypt"
+)
+
+// Disk lugar donde se guardan los archivos (carpeta local, bucket)
+// las rutas usan / y son relativas a la raiz del disco
+type Disk interface {
+	// Put guarda todo el contenido del reader en la ruta, reemplaza el archivo si existe
+	Put(ctx context.Context, path string, r io.Reader) (int64, error)
+	// Open abre el archivo para leerlo
+	Open(ctx context.Context, path string) (io.ReadCloser, error)
+	// Size retorna el tamaño del archivo en bytes
+	Size(ctx context.Context, path string) (int64, error)
+	// Delete borra el archivo, no falla si no existe
+	Delete(ctx context.Context, path string) error
+}
+
+// Appender lo implementa el disco que puede agregar contenido al final de un archivo
+// lo necesitan las subidas reanudables, un disco sin Appender solo recibe archivos completos
+type Appender interface {
+	Append(ctx context.Context, path string, r io.Reader) (int64, error)
+}
+
+// FileInfo datos del archivo para responder con cache y rangos (ETag, Last-Modified)
+type FileInfo struct {
+	Size    int64
+	ModTime time.Time
+	ETag    string // el del disco si lo tiene (S3), "" lo calcula la respuesta con el tamaño y la fecha
+}
+
+// Statter lo implementa el disco que conoce la fecha de modificacion del archivo
+// retorna errors.ErrUnsupported si no la puede consultar (un disco que envuelve a otro sin Stat)
+type Statter interface {
+	Stat(ctx context.Context, path string) (FileInfo, error)
+}
+
+// RangeReader lo implementa el disco que puede leer una parte del archivo sin descargarlo todo (Range de S3)
+// con el las descargas se pueden reanudar aunque Open no retorne un io.Seeker
+type RangeReader interface {
+	OpenRange(ctx context.Context, path string, offset int64, length int64) (io.ReadCloser, error)
+}
+
+// TemporaryURLer lo implementa el disco que puede crear enlaces de descarga que vencen
+// el cliente descarga directo del disco (url prefirmada de S3) o de la ruta firmada de la aplicacion
+type TemporaryURLer interface {
+	TemporaryURL(ctx context.Context, path string, expires time.Time) (string, error)
+}
+
+// TemporaryURL crea un enlace de descarga del archivo que vence en ttl
+// el controlador autoriza al usuario y responde el enlace, la descarga no pasa por el controlador
+//
+//	link, err := storage.TemporaryURL(ctx.Request.Context(), storage.Default, "invoices/7.pdf", 10*time.Minute)
+//	ctx.ResponseJSON(http.StatusOK, map[string]string{"url": link})
+func TemporaryURL(ctx context.Context, disk Disk, path string, ttl time.Duration) (string, error) {
+	t, ok := disk.(TemporaryURLer)
+	if !ok {
+		return "", fmt.Errorf("el disco no crea enlaces temporales: %w", errors.ErrUnsupported)
+	}
+	return t.TemporaryURL(ctx, path, time.Now().Add(ttl))
+}
+
+// ErrNotFound el archivo no existe en el disco
+var ErrNotFound = errors.New("el archivo no existe")
+
+// ErrInvalidPath la ruta sale de la raiz del disco (../)
+var ErrInvalidPath = errors.New("la ruta del archivo no es válida")
+
+// Default disco que se usa cuando no se indica otro
+var Default Disk = NewLocal("storage/app")
+
+// Local disco en una carpeta del servidor
+type Local struct {
+	Root string
+	Perm fs.FileMode // permisos de los archivos nuevos, 0640 si es cero
+	// URL base de la ruta que sirve los enlaces temporales (response.SignedFiles), "" no crea enlaces
+	URL string
+}
+
+// NewLocal crea el disco en la carpeta root, la carpeta se crea al guardar el primer archivo
+func NewLocal(root string) *Local {
+	return &Local{Root: root}
+}
+
+// fullPath convierte la ruta del disco en la ruta del sistema sin dejar salir de la raiz
+func (l *Local) fullPath(path string) (string, error) {
+	clean := filepath.Clean("/" + filepath.FromSlash(path))
+	if clean == string(filepath.Separator) || strings.Contains(path, "\x00") {
+		return "", ErrInvalidPath
+	}
+	return filepath.Join(l.Root, clean), nil
+}
+
+func (l *Local) perm() fs.FileMode {
+	if l.Perm == 0 {
+		return 0o640
+	}
+	return l.Perm
+}
+
+// Put escribe en un archivo temporal y lo renombra al final, un error a la mitad no deja un archivo incompleto
+func (l *Local) Put(ctx context.Context, path string, r io.Reader) (int64, error) {
+	full, err := l.fullPath(path)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(full), 0o750); err != nil {
+		return 0, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(full), ".upload-*")
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(tmp, &contextReader{ctx: ctx, r: r})
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), l.perm())
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), full)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return n, err
+	}
+	return n, nil
+}
+
+// Append agrega el contenido al final del archivo y lo crea si no existe
+func (l *Local) Append(ctx context.Context, path string, r io.Reader) (int64, error) {
+	full, err := l.fullPath(path)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(full), 0o750); err != nil {
+		return 0, err
+	}
+	f, err := os.OpenFile(full, os.O_WRONLY|os.O_APPEND|os.O_CREATE, l.perm())
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(f, &contextReader{ctx: ctx, r: r})
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return n, err
+}
+
+func (l *Local) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	full, err := l.fullPath(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(full)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (l *Local) Size(ctx context.Context, path string) (int64, error) {
+	full, err := l.fullPath(path)
+	if err != nil {
+		return 0, err
+	}
+	info, err := os.Stat(full)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+func (l *Local) Stat(ctx context.Context, path string) (FileInfo, error) {
+	full, err := l.fullPath(path)
+	if err != nil {
+		return FileInfo{}, err
+	}
+	info, err := os.Stat(full)
+	if errors.Is(err, fs.ErrNotExist) {
+		return FileInfo{}, ErrNotFound
+	}
+	if err != nil {
+		return FileInfo{}, err
+	}
+	return FileInfo{Size: info.Size(), ModTime: info.ModTime()}, nil
+}
+
+// TemporaryURL firma la url del archivo en la ruta URL con la llave de la aplicacion (crypt.SignURL)
+// el archivo debe existir, asi no se entregan enlaces que responden 404
+func (l *Local) TemporaryURL(ctx context.Context, path string, expires time.Time) (string, error) {
+	if l.URL == "" {
+		return "", fmt.Errorf("el disco %s no tiene URL para los enlaces temporales: %w", l.Root, errors.ErrUnsupported)
+	}
+	if _, err := l.Size(ctx, path); err != nil {
+		return "", err
+	}
+	clean := strings.TrimPrefix(filepath.ToSlash(filepath.Clean("/"+filepath.FromSlash(path))), "/")
+	escaped := (&url.URL{Path: clean}).EscapedPath()
+	return crypt.SignURL(strings.TrimRight(l.URL, "/")+"/"+escaped, expires)
+}
+
+func (l *Local) Delete(ctx context.Context, path string) error {
+	full, err := l.fullPath(path)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(full); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("no se pudo borrar el archivo: %w", err)
+	}
+	return nil
+}
+
+// contextReader deja de leer cuando el contexto termina (el cliente cancelo la subida)
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
@@ -0,0 +1,182 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// Reglas sobre todos los elementos de un array (facturas, pedidos)
+//
+//	type CreateInvoice struct {
+//		Total float64 `json:"total" rules:"required|min:0"`
+//		Items []Item  `json:"items" rules:"required|count:1,100|sum:amount,total"`
+//		Addrs []Addr  `json:"addresses" rules:"at_least_one:is_billing|exactly_one:is_default"`
+//	}
+//
+//	sum:campo,total          la suma de campo en los elementos es igual al campo total o a un numero (sum:percent,100)
+//	count:min,max            cantidad de elementos entre min y max, count:3 exactamente 3
+//	at_least_one:campo,valor algun elemento tiene campo igual a valor (por defecto true)
+//	exactly_one:campo,valor  un solo elemento tiene campo igual a valor
+//
+// los campos de los elementos pueden usar notacion de puntos (price.amount)
+// las reglas son implicitas: un array vacio o que no se envio tiene 0 elementos y suma 0,
+// asi count:1,100 y at_least_one fallan aunque el cliente omita el array
+
+// las reglas se registran en init porque leen los structs de los elementos con getStructMeta,
+// que a su vez usa el registro de reglas
+func init() {
+	RegisterImplicitRule("sum", sumRule)
+	RegisterImplicitRule("count", countRule)
+	RegisterImplicitRule("at_least_one", atLeastOneRule)
+	RegisterImplicitRule("exactly_one", exactlyOneRule)
+}
+
+// sumTolerance diferencia maxima al comparar sumas de decimales (0.1 + 0.2 = 0.3)
+const sumTolerance = 1e-6
+
+// arrayElements retorna los elementos del array, false si el valor no es un array
+// nil (el campo no se envio) es un array sin elementos
+func arrayElements(value any) ([]any, bool) {
+	if value == nil {
+		return nil, true
+	}
+	if v, ok := value.([]any); ok {
+		return v, true
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+	out := make([]any, rv.Len())
+	for i := range out {
+		out[i] = rv.Index(i).Interface()
+	}
+	return out, true
+}
+
+// elementField busca el campo dentro de un elemento que puede ser un objeto json o un struct
+func elementField(elem any, field string) any {
+	if m, ok := elem.(map[string]any); ok {
+		return lookup(m, field)
+	}
+	rv := reflect.ValueOf(elem)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() == reflect.Struct {
+		return lookup(structToMap(rv, getStructMeta(rv.Type())), field)
+	}
+	return nil
+}
+
+// sumRule el total puede ser otro campo o un numero
+func sumRule(f *Field) error {
+	if len(f.Params) < 2 {
+		return errors.New("la regla sum requiere el campo a sumar y el total (sum:amount,total)")
+	}
+	items, ok := arrayElements(f.Value)
+	if !ok {
+		return errors.New("el campo debe ser un array")
+	}
+	var sum float64
+	for i, item := range items {
+		value := elementField(item, f.Params[0])
+		if value == nil {
+			continue
+		}
+		n, ok := coordinate(value)
+		if !ok {
+			return fmt.Errorf("%s del elemento %d debe ser numérico", f.Params[0], i)
+		}
+		sum += n
+	}
+	total, err := strconv.ParseFloat(f.Params[1], 64)
+	if err != nil {
+		var ok bool
+		if total, ok = coordinate(lookup(f.Data, f.Params[1])); !ok {
+			return fmt.Errorf("%s debe ser numérico", f.Params[1])
+		}
+	}
+	if math.Abs(sum-total) > sumTolerance {
+		return fmt.Errorf("la suma de %s (%s) debe ser igual a %s", f.Params[0], strconv.FormatFloat(math.Round(sum/sumTolerance)*sumTolerance, 'f', -1, 64), strconv.FormatFloat(total, 'f', -1, 64))
+	}
+	return nil
+}
+
+// countRule count:min,max o count:n
+func countRule(f *Field) error {
+	if len(f.Params) == 0 {
+		return errors.New("la regla count requiere la cantidad (count:1,10)")
+	}
+	items, ok := arrayElements(f.Value)
+	if !ok {
+		return errors.New("el campo debe ser un array")
+	}
+	min, err := strconv.Atoi(f.Params[0])
+	if err != nil {
+		return fmt.Errorf("el mínimo de la regla count no es válido: %w", err)
+	}
+	max := min
+	if len(f.Params) > 1 {
+		if max, err = strconv.Atoi(f.Params[1]); err != nil {
+			return fmt.Errorf("el máximo de la regla count no es válido: %w", err)
+		}
+	}
+	switch {
+	case min == max && len(items) != min:
+		return fmt.Errorf("debe tener %d elementos", min)
+	case len(items) < min || len(items) > max:
+		return fmt.Errorf("debe tener entre %d y %d elementos", min, max)
+	}
+	return nil
+}
+
+// countMatching cuenta los elementos con el campo igual al valor de los parametros (true por defecto)
+func countMatching(f *Field, rule string) (int, error) {
+	if len(f.Params) == 0 {
+		return 0, fmt.Errorf("la regla %s requiere el campo (%s:is_default)", rule, rule)
+	}
+	items, ok := arrayElements(f.Value)
+	if !ok {
+		return 0, errors.New("el campo debe ser un array")
+	}
+	values := f.Params[1:]
+	if len(values) == 0 {
+		values = []string{"true"}
+	}
+	count := 0
+	for _, item := range items {
+		if matchesAny(elementField(item, f.Params[0]), values) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func atLeastOneRule(f *Field) error {
+	count, err := countMatching(f, "at_least_one")
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("al menos un elemento debe tener %s", f.Params[0])
+	}
+	return nil
+}
+
+func exactlyOneRule(f *Field) error {
+	count, err := countMatching(f, "exactly_one")
+	if err != nil {
+		return err
+	}
+	if count != 1 {
+		return fmt.Errorf("exactamente un elemento debe tener %s, hay %d", f.Params[0], count)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+)
+
+type aggregateItem struct {
+	Amount float64 `json:"amount"`
+}
+
+type aggregateAddr struct {
+	IsBilling bool `json:"is_billing"`
+	IsDefault bool `json:"is_default"`
+}
+
+type aggregateInvoice struct {
+	Total float64         `json:"total"`
+	Items []aggregateItem `json:"items" rules:"count:1,100|sum:amount,total"`
+	Addrs []aggregateAddr `json:"addresses" rules:"at_least_one:is_billing|exactly_one:is_default"`
+}
+
+func TestAggregateRules(t *testing.T) {
+	addrs := []aggregateAddr{{IsBilling: true, IsDefault: true}, {}}
+	cases := []struct {
+		name   string
+		inv    aggregateInvoice
+		failed []string
+	}{
+		{"valido", aggregateInvoice{Total: 30, Items: []aggregateItem{{10}, {20}}, Addrs: addrs}, nil},
+		{"arrays nil", aggregateInvoice{Total: 100}, []string{"items", "addresses"}},
+		{"arrays vacios", aggregateInvoice{Total: 100, Items: []aggregateItem{}, Addrs: []aggregateAddr{}}, []string{"items", "addresses"}},
+		{"suma distinta", aggregateInvoice{Total: 31, Items: []aggregateItem{{10}, {20}}, Addrs: addrs}, []string{"items"}},
+		{"sin facturacion", aggregateInvoice{Total: 10, Items: []aggregateItem{{10}}, Addrs: []aggregateAddr{{IsDefault: true}}}, []string{"addresses"}},
+		{"dos por defecto", aggregateInvoice{Total: 10, Items: []aggregateItem{{10}}, Addrs: []aggregateAddr{{IsBilling: true, IsDefault: true}, {IsDefault: true}}}, []string{"addresses"}},
+	}
+	for _, c := range cases {
+		err := Struct(&c.inv)
+		if len(c.failed) == 0 {
+			if err != nil {
+				t.Errorf("%s: se esperaba nil, se obtuvo %v", c.name, err)
+			}
+			continue
+		}
+		var errs ValidationErrors
+		if !errors.As(err, &errs) {
+			t.Errorf("%s: se esperaban errores de validacion, se obtuvo %v", c.name, err)
+			continue
+		}
+		for _, field := range c.failed {
+			if !errs.Has(field) {
+				t.Errorf("%s: se esperaba error en %s, se obtuvo %v", c.name, field, errs)
+			}
+		}
+		if len(errs) != len(c.failed) {
+			t.Errorf("%s: se esperaban errores en %v, se obtuvo %v", c.name, c.failed, errs)
+		}
+	}
+}
+
+func TestAggregateRulesMap(t *testing.T) {
+	rules := map[string]string{"items": "count:1,10|sum:amount,100"}
+	cases := []struct {
+		name string
+		data map[string]any
+		ok   bool
+	}{
+		{"sin el campo", map[string]any{}, false},
+		{"null", map[string]any{"items": nil}, false},
+		{"vacio", map[string]any{"items": []any{}}, false},
+		{"valido", map[string]any{"items": []any{map[string]any{"amount": 60.0}, map[string]any{"amount": 40.0}}}, true},
+		{"no es array", map[string]any{"items": "x"}, false},
+	}
+	for _, c := range cases {
+		if err := Map(c.data, rules); (err == nil) != c.ok {
+			t.Errorf("%s: se obtuvo %v, se esperaba ok=%v", c.name, err, c.ok)
+		}
+	}
+
+	// sum:percent,0 sobre un array vacio suma 0
+	if err := Map(map[string]any{}, map[string]string{"items": "sum:percent,0"}); err != nil {
+		t.Errorf("un array que no se envio suma 0, se obtuvo %v", err)
+	}
+}
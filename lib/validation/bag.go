@@ -0,0 +1,127 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+)
+
+// ErrorBag errores de varios FormRequests agrupados por nombre (como las bolsas de errores de laravel)
+// para los endpoints que validan varias partes y responden todos los errores juntos
+//
+//	bag := validation.NewErrorBag()
+//	if err := bag.Put("billing", request.Validate(&billing, ctx.Request)); err != nil {
+//		return nil, err // el error no es de validacion (json mal formado, throttle)
+//	}
+//	if err := bag.Put("shipping", request.Validate(&shipping, ctx.Request)); err != nil {
+//		return nil, err
+//	}
+//	if err := bag.Err(); err != nil {
+//		return nil, err // 422 {"billing": {"name": [...]}, "shipping": {"zip": [...]}}
+//	}
+type ErrorBag map[string]ValidationErrors
+
+// NewErrorBag crea una bolsa de errores vacia
+func NewErrorBag() ErrorBag {
+	return make(ErrorBag)
+}
+
+// Put guarda los errores de validacion de err en la bolsa name
+// retorna err si no es un error de validacion para que el llamador lo maneje, nil en los demas casos
+func (b ErrorBag) Put(name string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) {
+		return err
+	}
+	if b[name] == nil {
+		b[name] = make(ValidationErrors, len(verrs))
+	}
+	for field, msgs := range verrs {
+		b[name][field] = append(b[name][field], msgs...)
+	}
+	return nil
+}
+
+// Add agrega un mensaje al campo de la bolsa name
+func (b ErrorBag) Add(name string, field string, message string) {
+	if b[name] == nil {
+		b[name] = make(ValidationErrors)
+	}
+	b[name].Add(field, message)
+}
+
+// Bag retorna los errores de la bolsa name, vacia si no tiene
+func (b ErrorBag) Bag(name string) ValidationErrors {
+	if errs, ok := b[name]; ok {
+		return errs
+	}
+	return ValidationErrors{}
+}
+
+// Has indica si la bolsa name tiene errores
+func (b ErrorBag) Has(name string) bool {
+	return len(b[name]) > 0
+}
+
+// Any indica si alguna bolsa tiene errores
+func (b ErrorBag) Any() bool {
+	for _, errs := range b {
+		if len(errs) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Err retorna la bolsa como error si tiene errores, nil si no
+func (b ErrorBag) Err() error {
+	if !b.Any() {
+		return nil
+	}
+	return b
+}
+
+// Error implementa la interfaz error
+func (b ErrorBag) Error() string {
+	names := make([]string, 0, len(b))
+	for name, errs := range b {
+		if len(errs) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var s strings.Builder
+	s.WriteString("errores de validación:")
+	for _, name := range names {
+		fields := make([]string, 0, len(b[name]))
+		for field := range b[name] {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			s.WriteString("\n")
+			s.WriteString(name)
+			s.WriteString(".")
+			s.WriteString(field)
+			s.WriteString(": ")
+			s.WriteString(strings.Join(b[name][field], ", "))
+		}
+	}
+	return s.String()
+}
+
+// MarshalJSON serializa solo las bolsas con errores, los campos de cada una segun ErrorKeys
+func (b ErrorBag) MarshalJSON() ([]byte, error) {
+	out := make(map[string]ValidationErrors, len(b))
+	for name, errs := range b {
+		if len(errs) > 0 {
+			out[name] = errs
+		}
+	}
+	return json.Marshal(out)
+}
@@ -0,0 +1,150 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Reglas para archivos que llegan dentro del json en base64 o como data URI
+//
+//	Payload string `json:"payload" rules:"base64"`                                    // estandar, base64:url para el alfabeto de urls
+//	Avatar  string `json:"avatar" rules:"required|base64_image:max_kb=500,mimes=png,jpg"`
+//	Logo    string `json:"logo" rules:"base64_image:mimes=png,webp"`                   // tambien data:image/png;base64,...
+//
+// el tamaño se calcula con el texto antes de decodificar y el tipo se detecta con el contenido,
+// no con lo que dice el data URI, asi un archivo gigante o disfrazado no llega a memoria ni al storage.
+// despues de validar use DecodeBase64Image para obtener los bytes
+
+// MaxBase64ImageKB limite de base64_image cuando la regla no tiene max_kb
+var MaxBase64ImageKB = 2048
+
+// DecodeBase64 decodifica el texto en base64 con o sin relleno (=), url usa el alfabeto de urls (- y _)
+func DecodeBase64(value string, url bool) ([]byte, error) {
+	enc := base64.StdEncoding
+	if url {
+		enc = base64.URLEncoding
+	}
+	if !strings.HasSuffix(value, "=") && len(value)%4 != 0 {
+		enc = enc.WithPadding(base64.NoPadding)
+	}
+	data, err := enc.DecodeString(value)
+	if err != nil {
+		return nil, errors.New("el valor no es base64 válido")
+	}
+	return data, nil
+}
+
+// DecodeBase64Image decodifica la imagen (base64 o data URI) y verifica el tamaño y el tipo
+// maxKB 0 no limita el tamaño, mimes son extensiones o tipos (png, jpg, image/webp), vacio acepta cualquier imagen
+// retorna los bytes y el tipo detectado con el contenido
+func DecodeBase64Image(value string, maxKB int, mimes ...string) ([]byte, string, error) {
+	declared := ""
+	if rest, ok := strings.CutPrefix(value, "data:"); ok {
+		header, payload, found := strings.Cut(rest, ",")
+		if !found || !strings.HasSuffix(header, ";base64") {
+			return nil, "", errors.New("el data URI debe estar en base64 (data:image/png;base64,...)")
+		}
+		declared, _, _ = strings.Cut(header, ";")
+		value = payload
+	}
+
+	// el tamaño decodificado es 3/4 del texto, se revisa antes de reservar la memoria
+	if maxKB > 0 && base64.StdEncoding.DecodedLen(len(value)) > maxKB*1024+2 {
+		return nil, "", fmt.Errorf("la imagen no puede pesar más de %d KB", maxKB)
+	}
+	data, err := DecodeBase64(value, false)
+	if err != nil {
+		return nil, "", err
+	}
+	if maxKB > 0 && len(data) > maxKB*1024 {
+		return nil, "", fmt.Errorf("la imagen no puede pesar más de %d KB", maxKB)
+	}
+
+	detected, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	if !strings.HasPrefix(detected, "image/") {
+		return nil, "", errors.New("el contenido no es una imagen")
+	}
+	if declared != "" && declared != detected {
+		return nil, "", fmt.Errorf("el data URI dice %s pero el contenido es %s", declared, detected)
+	}
+	if len(mimes) > 0 && !mimeAllowed(detected, mimes) {
+		return nil, "", fmt.Errorf("la imagen debe ser de tipo %s", strings.Join(mimes, ", "))
+	}
+	// los formatos que decodifica la libreria estandar se leen para asegurar que no es un archivo corrupto
+	switch detected {
+	case "image/png", "image/jpeg", "image/gif":
+		if _, _, err := image.DecodeConfig(bytes.NewReader(data)); err != nil {
+			return nil, "", errors.New("la imagen está dañada")
+		}
+	}
+	return data, detected, nil
+}
+
+// mimeAllowed compara el tipo con la lista de extensiones o tipos
+func mimeAllowed(detected string, mimes []string) bool {
+	for _, m := range mimes {
+		m = strings.ToLower(strings.TrimSpace(m))
+		if !strings.Contains(m, "/") {
+			m, _, _ = strings.Cut(mime.TypeByExtension("."+m), ";")
+		}
+		if m == detected {
+			return true
+		}
+	}
+	return false
+}
+
+// keyParams interpreta parametros clave=valor, los que no tienen clave se agregan a la anterior
+// max_kb=500,mimes=png,jpg -> {max_kb: [500], mimes: [png, jpg]}
+func keyParams(params []string) map[string][]string {
+	out := make(map[string][]string, len(params))
+	last := ""
+	for _, p := range params {
+		if key, value, ok := strings.Cut(p, "="); ok {
+			last = strings.TrimSpace(key)
+			out[last] = append(out[last], strings.TrimSpace(value))
+			continue
+		}
+		out[last] = append(out[last], strings.TrimSpace(p))
+	}
+	return out
+}
+
+// base64Rule con el parametro url usa el alfabeto de urls
+func base64Rule(f *Field) error {
+	s, ok := f.Value.(string)
+	if !ok {
+		return errors.New("el valor debe ser texto en base64")
+	}
+	_, err := DecodeBase64(s, len(f.Params) > 0 && f.Params[0] == "url")
+	return err
+}
+
+// base64ImageRule parametros max_kb (por defecto MaxBase64ImageKB) y mimes
+func base64ImageRule(f *Field) error {
+	s, ok := f.Value.(string)
+	if !ok {
+		return errors.New("la imagen debe ser texto en base64")
+	}
+	params := keyParams(f.Params)
+	maxKB := MaxBase64ImageKB
+	if v := params["max_kb"]; len(v) > 0 {
+		n, err := strconv.Atoi(v[0])
+		if err != nil {
+			return fmt.Errorf("el parámetro max_kb de base64_image no es válido: %w", err)
+		}
+		maxKB = n
+	}
+	_, _, err := DecodeBase64Image(s, maxKB, params["mimes"]...)
+	return err
+}
@@ -0,0 +1,229 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Circuit breaker para las reglas que consultan sistemas externos (base de datos, captcha, apis de exists)
+// si la regla falla varias veces seguidas (HardError o timeout) el circuito se abre y durante Cooldown la regla
+// no se ejecuta: se salta con una advertencia o falla de una vez, asi una dependencia caida no hace lenta
+// cada solicitud. pasado Cooldown se deja pasar una llamada de prueba, si funciona el circuito se cierra
+//
+//	validation.Protect("captcha", validation.NewBreaker(5, 30*time.Second, validation.FallbackFail))
+//	validation.Protect("unique", &validation.Breaker{Threshold: 3, Cooldown: time.Minute, Timeout: 2 * time.Second})
+
+// Fallback que hace la regla cuando su dependencia falla o el circuito esta abierto
+type Fallback int
+
+const (
+	// FallbackFail la validacion termina con un HardError (ErrBreakerOpen si el circuito esta abierto)
+	FallbackFail Fallback = iota
+	// FallbackSkip la regla se da por valida y se agrega una advertencia al campo (ver WithWarnings)
+	FallbackSkip
+)
+
+// BreakerState estado del circuito
+type BreakerState int
+
+const (
+	BreakerClosed   BreakerState = iota // la regla se ejecuta normal
+	BreakerOpen                         // la regla no se ejecuta, se usa el Fallback
+	BreakerHalfOpen                     // se esta probando si la dependencia volvio
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return "closed"
+}
+
+// ErrBreakerOpen la regla no se ejecuto porque su dependencia esta fallando
+var ErrBreakerOpen = errors.New("el servicio de validación no está disponible")
+
+// Breaker circuito de una regla, se comparte entre todas las solicitudes
+type Breaker struct {
+	Threshold int           // fallas seguidas para abrir el circuito, 5 si es cero
+	Cooldown  time.Duration // tiempo abierto antes de la llamada de prueba, 30s si es cero
+	Timeout   time.Duration // tiempo maximo de cada llamada, cuenta como falla, 0 sin limite
+	Fallback  Fallback
+
+	// OnStateChange se llama cuando el circuito cambia de estado (para logs y metricas)
+	OnStateChange func(rule string, from BreakerState, to BreakerState)
+
+	mu       sync.Mutex
+	rule     string
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewBreaker crea el circuito
+func NewBreaker(threshold int, cooldown time.Duration, fallback Fallback) *Breaker {
+	return &Breaker{Threshold: threshold, Cooldown: cooldown, Fallback: fallback}
+}
+
+// Protect envuelve una regla registrada con el circuito, se debe llamar al iniciar la aplicacion
+// despues de registrar la regla
+func Protect(rule string, b *Breaker) error {
+	fn, ok := ruleFuncs[rule]
+	if !ok {
+		return fmt.Errorf("la regla '%s' no existe", rule)
+	}
+	b.mu.Lock()
+	b.rule = rule
+	if b.Fallback == FallbackSkip {
+		skipBreakers = true
+	}
+	b.mu.Unlock()
+	ruleFuncs[rule] = b.Wrap(fn)
+	return nil
+}
+
+// skipBreakers alguna regla se protegio con FallbackSkip
+var skipBreakers bool
+
+// HasSkipBreakers indica si alguna regla se protegio con FallbackSkip y puede dejar advertencias
+// aunque el struct no tenga reglas warn
+func HasSkipBreakers() bool {
+	return skipBreakers
+}
+
+// State retorna el estado actual del circuito
+func (b *Breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Wrap retorna la regla protegida por el circuito
+// si la regla entra en panico cuenta como falla (la llamada de prueba no queda abierta) y se retorna
+// el HardError de ErrRulePanic como en cualquier otra regla
+func (b *Breaker) Wrap(fn RuleFunc) RuleFunc {
+	return func(f *Field) (err error) {
+		if !b.allow() {
+			return b.fallback(f, ErrBreakerOpen)
+		}
+		failed := true
+		defer func() {
+			if r := recover(); r != nil {
+				err = &HardError{Err: fmt.Errorf("%s: %w (%v)", b.rule, ErrRulePanic, r)}
+			}
+			b.record(failed)
+		}()
+		failed, err = b.call(fn, f)
+		if failed {
+			return b.fallback(f, err)
+		}
+		return err
+	}
+}
+
+// call ejecuta la regla con el Timeout, failed indica que fallo la dependencia y no el valor
+func (b *Breaker) call(fn RuleFunc, f *Field) (bool, error) {
+	parent := f.Context
+	if b.Timeout > 0 {
+		ctx, cancel := context.WithTimeout(parent, b.Timeout)
+		defer cancel()
+		f.Context = ctx
+		defer func() { f.Context = parent }()
+	}
+	err := fn(f)
+	if parent.Err() != nil {
+		// la solicitud se cancelo, no es culpa de la dependencia
+		return false, err
+	}
+	if f.Context.Err() != nil {
+		return true, fmt.Errorf("la regla tardó más de %s", b.Timeout)
+	}
+	var hard *HardError
+	return errors.As(err, &hard), err
+}
+
+// allow indica si la llamada puede pasar, con el circuito abierto solo pasa la de prueba despues de Cooldown
+func (b *Breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.cooldown() {
+			return false
+		}
+		b.setState(BreakerHalfOpen)
+		b.probing = true
+		return true
+	case BreakerHalfOpen:
+		// solo una llamada de prueba a la vez
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	}
+	return true
+}
+
+// record actualiza el circuito con el resultado de la llamada
+func (b *Breaker) record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if !failed {
+		b.failures = 0
+		if b.state != BreakerClosed {
+			b.setState(BreakerClosed)
+		}
+		return
+	}
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold() {
+		b.openedAt = time.Now()
+		if b.state != BreakerOpen {
+			b.setState(BreakerOpen)
+		}
+	}
+}
+
+// setState cambia el estado y avisa a OnStateChange, se llama con el mutex tomado
+func (b *Breaker) setState(to BreakerState) {
+	from := b.state
+	b.state = to
+	if b.OnStateChange != nil {
+		b.OnStateChange(b.rule, from, to)
+	}
+}
+
+// fallback aplica el Fallback con el error de la dependencia
+func (b *Breaker) fallback(f *Field, err error) error {
+	if b.Fallback == FallbackSkip {
+		addWarning(f.Context, f.Name, "no se pudo validar el campo: "+err.Error())
+		return nil
+	}
+	var hard *HardError
+	if errors.As(err, &hard) {
+		return err
+	}
+	return &HardError{Err: err}
+}
+
+func (b *Breaker) threshold() int {
+	if b.Threshold <= 0 {
+		return 5
+	}
+	return b.Threshold
+}
+
+func (b *Breaker) cooldown() time.Duration {
+	if b.Cooldown <= 0 {
+		return 30 * time.Second
+	}
+	return b.Cooldown
+}
@@ -0,0 +1,48 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBreakerPanicDoesNotLeaveProbeOpen(t *testing.T) {
+	b := NewBreaker(1, time.Millisecond, FallbackFail)
+	b.rule = "panica"
+	panics := true
+	rule := b.Wrap(func(f *Field) error {
+		if panics {
+			panic("sin conexion")
+		}
+		return nil
+	})
+	field := func() *Field { return &Field{Name: "captcha", Context: context.Background()} }
+
+	err := rule(field())
+	var hard *HardError
+	if !errors.As(err, &hard) || !errors.Is(err, ErrRulePanic) {
+		t.Fatalf("se esperaba un HardError con ErrRulePanic, se obtuvo %v", err)
+	}
+	if b.State() != BreakerOpen {
+		t.Fatalf("el panico debe contar como falla, estado %s", b.State())
+	}
+
+	// la llamada de prueba tambien entra en panico y no debe dejar el circuito bloqueado
+	time.Sleep(2 * time.Millisecond)
+	if err := rule(field()); !errors.Is(err, ErrRulePanic) {
+		t.Fatalf("la llamada de prueba debia ejecutar la regla, se obtuvo %v", err)
+	}
+	if b.probing {
+		t.Fatalf("la llamada de prueba quedo abierta despues del panico")
+	}
+
+	panics = false
+	time.Sleep(2 * time.Millisecond)
+	if err := rule(field()); err != nil {
+		t.Fatalf("se esperaba que la regla pasara, se obtuvo %v", err)
+	}
+	if b.State() != BreakerClosed {
+		t.Errorf("el circuito debia cerrarse, estado %s", b.State())
+	}
+}
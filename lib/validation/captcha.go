@@ -0,0 +1,158 @@
+package validation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Regla captcha: verifica en el servidor el token que genero el widget del cliente
+// el proveedor se configura una vez por ambiente, en las pruebas se reemplaza por CaptchaStub
+//
+//	validation.ConfigureCaptcha(os.Getenv("CAPTCHA_DRIVER"), os.Getenv("CAPTCHA_SECRET"))
+//
+//	Token string `json:"cf-turnstile-response" rules:"captcha"`
+//
+// si el proveedor no responde la regla retorna un HardError, no se acepta el formulario sin verificar
+
+// CaptchaVerifier verifica un token de captcha
+type CaptchaVerifier interface {
+	Verify(ctx context.Context, token string) (bool, error)
+}
+
+// Captcha verificador que usa la regla captcha, nil si no se configuro
+var Captcha CaptchaVerifier
+
+// ErrCaptchaNotConfigured se uso la regla captcha sin configurar un proveedor
+var ErrCaptchaNotConfigured = errors.New("captcha: no se configuró el proveedor")
+
+// URLs de verificacion de los proveedores
+const (
+	RecaptchaURL = "https://www.google.com/recaptcha/api/siteverify"
+	HCaptchaURL  = "https://api.hcaptcha.com/siteverify"
+	TurnstileURL = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+)
+
+// CaptchaProvider proveedor con el protocolo siteverify (reCAPTCHA, hCaptcha y Turnstile usan el mismo)
+type CaptchaProvider struct {
+	URL      string
+	Secret   string
+	Timeout  time.Duration // 5s si es cero
+	MinScore float64       // solo reCAPTCHA v3: puntaje minimo entre 0 y 1, 0 no lo revisa
+	Client   *http.Client
+}
+
+// NewRecaptcha crea el proveedor de Google reCAPTCHA (v2 o v3 con MinScore)
+func NewRecaptcha(secret string) *CaptchaProvider {
+	return &CaptchaProvider{URL: RecaptchaURL, Secret: secret}
+}
+
+// NewHCaptcha crea el proveedor de hCaptcha
+func NewHCaptcha(secret string) *CaptchaProvider {
+	return &CaptchaProvider{URL: HCaptchaURL, Secret: secret}
+}
+
+// NewTurnstile crea el proveedor de Cloudflare Turnstile
+func NewTurnstile(secret string) *CaptchaProvider {
+	return &CaptchaProvider{URL: TurnstileURL, Secret: secret}
+}
+
+// Verify envia el token al proveedor y retorna si es valido
+func (p *CaptchaProvider) Verify(ctx context.Context, token string) (bool, error) {
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	form := url.Values{"secret": {p.Secret}, "response": {token}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("captcha: el proveedor respondio %d", res.StatusCode)
+	}
+
+	var body struct {
+		Success bool     `json:"success"`
+		Score   *float64 `json:"score"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return false, fmt.Errorf("captcha: respuesta no válida: %w", err)
+	}
+	if !body.Success {
+		return false, nil
+	}
+	if p.MinScore > 0 && body.Score != nil && *body.Score < p.MinScore {
+		return false, nil
+	}
+	return true, nil
+}
+
+// CaptchaStub verificador para pruebas y desarrollo, siempre responde Pass sin llamar a nadie
+type CaptchaStub struct {
+	Pass bool
+}
+
+func (s CaptchaStub) Verify(ctx context.Context, token string) (bool, error) {
+	return s.Pass, nil
+}
+
+// ConfigureCaptcha configura Captcha segun el driver: recaptcha, hcaptcha, turnstile,
+// pass o fail (CaptchaStub para pruebas). Un driver vacio deja la regla sin configurar
+func ConfigureCaptcha(driver string, secret string) error {
+	switch strings.ToLower(driver) {
+	case "":
+		Captcha = nil
+	case "recaptcha":
+		Captcha = NewRecaptcha(secret)
+	case "hcaptcha":
+		Captcha = NewHCaptcha(secret)
+	case "turnstile":
+		Captcha = NewTurnstile(secret)
+	case "pass":
+		Captcha = CaptchaStub{Pass: true}
+	case "fail":
+		Captcha = CaptchaStub{Pass: false}
+	default:
+		return fmt.Errorf("captcha: driver desconocido %s", driver)
+	}
+	return nil
+}
+
+// captchaRule verifica el token con el proveedor configurado
+func captchaRule(f *Field) error {
+	token, _ := f.Value.(string)
+	if token == "" {
+		return errors.New("el captcha es obligatorio")
+	}
+	if Captcha == nil {
+		return Hard(ErrCaptchaNotConfigured)
+	}
+	ok, err := Captcha.Verify(f.Context, token)
+	if err != nil {
+		return Hard(err)
+	}
+	if !ok {
+		return errors.New("el captcha no es válido, intente de nuevo")
+	}
+	return nil
+}
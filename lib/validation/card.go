@@ -0,0 +1,235 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Reglas para los formularios de pago
+//
+//	Number string `json:"number" rules:"required|credit_card:visa,mastercard"`
+//	Expiry string `json:"expiry" rules:"required|card_expiry"`          // 12/27 o 12/2027
+//	CVV    string `json:"cvv" rules:"required|cvv:number"`              // 4 digitos si number es amex
+//	Month  string `json:"month" rules:"required|card_expiry:year"`      // o el mes y el año en campos separados
+//
+// los campos con credit_card o cvv son sensibles aunque no tengan el tag sensitive (PCI DSS):
+// su valor se quita de los mensajes de error y Redact y Dump los enmascaran.
+// los mensajes de estas reglas nunca incluyen el numero
+
+// CardBrand patron de los numeros de una franquicia
+type CardBrand struct {
+	Name    string
+	Pattern *regexp.Regexp // prefijo y longitud del numero sin espacios
+}
+
+// cardBrands franquicias en el orden en que se detectan, las de prefijos mas especificos van primero
+var cardBrands = []CardBrand{
+	{Name: "amex", Pattern: regexp.MustCompile(`^3[47]\d{13}$`)},
+	{Name: "diners", Pattern: regexp.MustCompile(`^3(0[0-5]|[689]\d)\d{11,16}$`)},
+	{Name: "jcb", Pattern: regexp.MustCompile(`^35(2[89]|[3-8]\d)\d{12,15}$`)},
+	{Name: "visa", Pattern: regexp.MustCompile(`^4(\d{12}|\d{15}|\d{18})$`)},
+	{Name: "mastercard", Pattern: regexp.MustCompile(`^(5[1-5]\d{2}|222[1-9]|22[3-9]\d|2[3-6]\d{2}|27[01]\d|2720)\d{12}$`)},
+	{Name: "discover", Pattern: regexp.MustCompile(`^(6011|65\d{2}|64[4-9]\d)\d{12,15}$`)},
+	{Name: "unionpay", Pattern: regexp.MustCompile(`^62\d{14,17}$`)},
+	{Name: "maestro", Pattern: regexp.MustCompile(`^(5018|5020|5038|6304|6759|676[1-3])\d{8,15}$`)},
+}
+
+// RegisterCardBrand agrega una franquicia que se detecta antes que las demas (elo, hipercard, tarjetas propias)
+// se debe llamar al iniciar la aplicacion
+//
+//	validation.RegisterCardBrand("hipercard", regexp.MustCompile(`^606282\d{10}$`))
+func RegisterCardBrand(name string, pattern *regexp.Regexp) {
+	cardBrands = append([]CardBrand{{Name: name, Pattern: pattern}}, cardBrands...)
+}
+
+// sensitiveRules reglas que vuelven sensible el campo
+var sensitiveRules = map[string]bool{
+	"credit_card": true,
+	"cvv":         true,
+}
+
+// hasSensitiveRule indica si las reglas incluyen alguna regla de datos de tarjeta
+func hasSensitiveRule(rules []Rule) bool {
+	for _, r := range rules {
+		if sensitiveRules[r.Name] {
+			return true
+		}
+	}
+	return false
+}
+
+// cardDigits quita los espacios y guiones del numero
+func cardDigits(value string) (string, bool) {
+	var b strings.Builder
+	for _, r := range value {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ', r == '-':
+		default:
+			return "", false
+		}
+	}
+	return b.String(), true
+}
+
+// Luhn verifica el digito de control del numero (mod 10)
+func Luhn(digits string) bool {
+	if len(digits) < 2 {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if d < 0 || d > 9 {
+			return false
+		}
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// DetectCardBrand retorna la franquicia del numero (visa, mastercard, amex...) o "" si no se reconoce
+func DetectCardBrand(number string) string {
+	digits, ok := cardDigits(number)
+	if !ok {
+		return ""
+	}
+	for _, brand := range cardBrands {
+		if brand.Pattern.MatchString(digits) {
+			return brand.Name
+		}
+	}
+	return ""
+}
+
+// CreditCard valida el numero de la tarjeta con Luhn, si se indican franquicias el numero debe ser de alguna de ellas
+func CreditCard(number string, brands ...string) error {
+	digits, ok := cardDigits(number)
+	if !ok || len(digits) < 12 || len(digits) > 19 {
+		return errors.New("el número de tarjeta debe tener entre 12 y 19 dígitos")
+	}
+	if !Luhn(digits) {
+		return errors.New("el número de tarjeta no es válido")
+	}
+	if len(brands) == 0 {
+		return nil
+	}
+	brand := DetectCardBrand(digits)
+	for _, b := range brands {
+		if strings.EqualFold(b, brand) {
+			return nil
+		}
+	}
+	return fmt.Errorf("solo se aceptan tarjetas %s", strings.Join(brands, ", "))
+}
+
+// MaskCardNumber deja visibles solo los ultimos 4 digitos (**** 4242) para mostrar o guardar en logs
+func MaskCardNumber(number string) string {
+	digits, _ := cardDigits(number)
+	if len(digits) < 4 {
+		return Mask
+	}
+	return "**** " + digits[len(digits)-4:]
+}
+
+// CardExpiry valida que la fecha de vencimiento (MM/YY o MM/YYYY) no haya pasado
+// la tarjeta vence al terminar el mes, no se aceptan fechas a mas de 20 años
+func CardExpiry(value string) error {
+	month, year, ok := strings.Cut(strings.ReplaceAll(value, " ", ""), "/")
+	if !ok {
+		return errors.New("la fecha de vencimiento debe tener el formato MM/AA")
+	}
+	return cardExpiry(month, year)
+}
+
+// cardExpiry valida el mes y el año de vencimiento por separado
+func cardExpiry(month string, year string) error {
+	m, err := strconv.Atoi(month)
+	if err != nil || len(month) > 2 || m < 1 || m > 12 {
+		return errors.New("el mes de vencimiento no es válido")
+	}
+	y, err := strconv.Atoi(year)
+	if err != nil || (len(year) != 2 && len(year) != 4) {
+		return errors.New("el año de vencimiento no es válido")
+	}
+	if len(year) == 2 {
+		y += 2000
+	}
+	today := now()
+	expires := time.Date(y, time.Month(m)+1, 1, 0, 0, 0, 0, today.Location())
+	if !today.Before(expires) {
+		return errors.New("la tarjeta está vencida")
+	}
+	if y > today.Year()+20 {
+		return errors.New("el año de vencimiento no es válido")
+	}
+	return nil
+}
+
+// CVV valida el codigo de seguridad: 4 digitos para amex, 3 para las demas, sin franquicia se acepta 3 o 4
+func CVV(value string, brand string) error {
+	size := len(value)
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return errors.New("el código de seguridad solo puede tener números")
+		}
+	}
+	switch {
+	case brand == "amex" && size != 4:
+		return errors.New("el código de seguridad debe tener 4 dígitos")
+	case brand != "" && brand != "amex" && size != 3:
+		return errors.New("el código de seguridad debe tener 3 dígitos")
+	case size != 3 && size != 4:
+		return errors.New("el código de seguridad debe tener 3 o 4 dígitos")
+	}
+	return nil
+}
+
+// creditCardRule los parametros son las franquicias aceptadas (credit_card:visa,mastercard)
+func creditCardRule(f *Field) error {
+	s, ok := f.Value.(string)
+	if !ok {
+		return errors.New("el número de tarjeta debe ser texto")
+	}
+	return CreditCard(s, f.Params...)
+}
+
+// cardExpiryRule sin parametros el valor es MM/YY, con un parametro el valor es el mes y el parametro el campo del año
+func cardExpiryRule(f *Field) error {
+	s := fmt.Sprint(f.Value)
+	if len(f.Params) == 0 {
+		return CardExpiry(s)
+	}
+	year := lookup(f.Data, f.Params[0])
+	if isEmpty(year) {
+		return errors.New("el año de vencimiento es obligatorio")
+	}
+	return cardExpiry(s, fmt.Sprint(year))
+}
+
+// cvvRule el parametro es el campo con el numero de la tarjeta para saber cuantos digitos debe tener
+func cvvRule(f *Field) error {
+	s, ok := f.Value.(string)
+	if !ok {
+		return errors.New("el código de seguridad debe ser texto")
+	}
+	brand := ""
+	if len(f.Params) > 0 {
+		number, _ := lookup(f.Data, f.Params[0]).(string)
+		brand = DetectCardBrand(number)
+	}
+	return CVV(s, brand)
+}
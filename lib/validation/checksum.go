@@ -0,0 +1,116 @@
+package validation
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+// Reglas para sumas de verificacion: el formato del hash y que el contenido coincida con el hash declarado
+//
+//	type UploadChunk struct {
+//		Data   []byte `json:"data" rules:"required|checksum:sha256,digest"` // el json ya decodifica el base64
+//		Digest string `json:"digest" rules:"required|sha256"`
+//	}
+//
+//	Content string `json:"content" rules:"checksum:md5,content_md5,from_base64"` // content en base64, se decodifica antes
+//
+// el hash declarado puede estar en hexadecimal o en base64 (como Content-MD5 y Digest de http)
+
+// hashes algoritmos disponibles para las reglas de checksum
+var hashes = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// RegisterHash agrega un algoritmo para checksum y una regla con su nombre que valida el formato del hash
+// se debe llamar al iniciar la aplicacion
+//
+//	validation.RegisterHash("sha3_256", sha3.New256)
+func RegisterHash(name string, fn func() hash.Hash) {
+	hashes[name] = fn
+	RegisterRule(name, hashFormatRule(name))
+}
+
+// Checksum verifica que el hash del contenido sea el declarado (hexadecimal o base64)
+func Checksum(algorithm string, content []byte, expected string) error {
+	newHash, ok := hashes[algorithm]
+	if !ok {
+		return fmt.Errorf("el algoritmo '%s' no existe", algorithm)
+	}
+	h := newHash()
+	h.Write(content)
+	sum := h.Sum(nil)
+	declared, err := decodeDigest(expected, h.Size())
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(sum, declared) != 1 {
+		return errors.New("el contenido no coincide con la suma de verificación")
+	}
+	return nil
+}
+
+// decodeDigest interpreta el hash en hexadecimal o base64 y verifica que tenga el tamaño del algoritmo
+func decodeDigest(digest string, size int) ([]byte, error) {
+	digest = strings.TrimSpace(digest)
+	if len(digest) == size*2 {
+		if b, err := hex.DecodeString(digest); err == nil {
+			return b, nil
+		}
+	}
+	if b, err := base64.StdEncoding.DecodeString(digest); err == nil && len(b) == size {
+		return b, nil
+	}
+	return nil, fmt.Errorf("la suma de verificación debe tener %d caracteres hexadecimales o estar en base64", size*2)
+}
+
+// hashFormatRule regla que valida que el valor sea un hash del algoritmo (md5, sha256)
+func hashFormatRule(algorithm string) RuleFunc {
+	return func(f *Field) error {
+		s, ok := f.Value.(string)
+		if !ok {
+			return errors.New("la suma de verificación debe ser texto")
+		}
+		_, err := decodeDigest(s, hashes[algorithm]().Size())
+		return err
+	}
+}
+
+// checksumRule parametros: algoritmo, campo con el hash y from_base64 si el valor se debe decodificar antes
+func checksumRule(f *Field) error {
+	if len(f.Params) < 2 {
+		return errors.New("la regla checksum requiere el algoritmo y el campo con la suma (checksum:sha256,digest)")
+	}
+	var content []byte
+	switch v := f.Value.(type) {
+	case []byte:
+		content = v
+	case string:
+		content = []byte(v)
+		if len(f.Params) > 2 && f.Params[2] == "from_base64" {
+			decoded, err := DecodeBase64(v, false)
+			if err != nil {
+				return err
+			}
+			content = decoded
+		}
+	default:
+		return errors.New("el contenido debe ser texto o bytes")
+	}
+	expected, _ := lookup(f.Data, f.Params[1]).(string)
+	if expected == "" {
+		return fmt.Errorf("falta la suma de verificación en %s", f.Params[1])
+	}
+	return Checksum(f.Params[0], content, expected)
+}
@@ -0,0 +1,243 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Codigos de error: identificadores estables para que los clientes no dependan del texto de los mensajes
+// cada regla tiene su codigo (required -> VAL_REQUIRED, email -> VAL_EMAIL) y los errores del request el suyo
+// (REQ_BODY_TOO_LARGE, REQ_FORBIDDEN). las respuestas de error los incluyen:
+//
+//	{
+//	    "status": "error",
+//	    "message": "los datos no son válidos",
+//	    "code": "VAL_INVALID",
+//	    "errors": {"email": ["el campo es obligatorio"]},
+//	    "codes": {"email": ["VAL_REQUIRED"]}
+//	}
+//
+// los codigos de un campo estan en el mismo orden que sus mensajes. Catalog retorna todos los codigos con su
+// descripcion para publicarlos (cli codes) y los paquetes registran los suyos al iniciar
+//
+//	validation.RegisterCode("ORD_OUT_OF_STOCK", "el producto no tiene existencias")
+//	validation.RegisterErrorCode(ErrOutOfStock, "ORD_OUT_OF_STOCK", "el producto no tiene existencias")
+//	validation.RegisterRuleCode("nit", "VAL_TAX_ID") // si el codigo de la regla no debe ser VAL_NIT
+
+const (
+	// CodeInvalid codigo general de una respuesta con errores de validacion
+	CodeInvalid = "VAL_INVALID"
+	// CodeUnknownRule el tag usa una regla que no esta registrada
+	CodeUnknownRule = "VAL_UNKNOWN_RULE"
+	// CodeNullElement un elemento de un FormRequest slice es null
+	CodeNullElement = "VAL_NULL_ELEMENT"
+	// CodeType el valor no es del tipo del campo (texto en un numero)
+	CodeType = "VAL_TYPE"
+	// CodeUnavailable la regla no se pudo ejecutar porque su dependencia esta fallando (ver Breaker)
+	CodeUnavailable = "VAL_UNAVAILABLE"
+)
+
+// CodeInfo un codigo del catalogo con su descripcion
+type CodeInfo struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
+
+var (
+	codesMu     sync.RWMutex
+	codeCatalog = map[string]string{
+		CodeInvalid:     "los datos no son válidos, el detalle está en codes",
+		CodeUnknownRule: "el campo usa una regla que no existe",
+		CodeNullElement: "el elemento no puede ser nulo",
+		CodeType:        "el valor no es del tipo esperado",
+		CodeUnavailable: "el servicio que valida el campo no está disponible",
+	}
+	ruleCodes  = map[string]string{}
+	errorCodes = []errorCode{{target: ErrBreakerOpen, code: CodeUnavailable}}
+)
+
+// errorCode codigo de los errores que son (errors.Is) target
+type errorCode struct {
+	target error
+	code   string
+}
+
+// RegisterCode agrega un codigo al catalogo, se debe llamar al iniciar la aplicacion
+func RegisterCode(code string, description string) {
+	codesMu.Lock()
+	defer codesMu.Unlock()
+	codeCatalog[code] = description
+}
+
+// RegisterRuleCode cambia el codigo de una regla, por defecto es VAL_ y el nombre en mayusculas
+func RegisterRuleCode(rule string, code string) {
+	codesMu.Lock()
+	defer codesMu.Unlock()
+	ruleCodes[rule] = code
+}
+
+// RegisterErrorCode asigna el codigo a los errores que son target (errors.Is) y lo agrega al catalogo
+func RegisterErrorCode(target error, code string, description string) {
+	codesMu.Lock()
+	defer codesMu.Unlock()
+	codeCatalog[code] = description
+	errorCodes = append(errorCodes, errorCode{target: target, code: code})
+}
+
+// RuleCode retorna el codigo de la regla (required -> VAL_REQUIRED)
+func RuleCode(rule string) string {
+	codesMu.RLock()
+	code, ok := ruleCodes[rule]
+	codesMu.RUnlock()
+	if ok {
+		return code
+	}
+	return "VAL_" + strings.ToUpper(rule)
+}
+
+// Catalog retorna los codigos registrados y los de todas las reglas ordenados por codigo
+func Catalog() []CodeInfo {
+	codesMu.RLock()
+	all := make(map[string]string, len(codeCatalog)+len(ruleFuncs))
+	for code, description := range codeCatalog {
+		all[code] = description
+	}
+	codesMu.RUnlock()
+	for rule := range ruleFuncs {
+		code := RuleCode(rule)
+		if _, ok := all[code]; !ok {
+			all[code] = "el valor no cumple la regla " + rule
+		}
+	}
+
+	out := make([]CodeInfo, 0, len(all))
+	for code, description := range all {
+		out = append(out, CodeInfo{Code: code, Description: description})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Code < out[j].Code })
+	return out
+}
+
+// codedError error con su codigo, lo crea WithCode
+type codedError struct {
+	code string
+	err  error
+}
+
+func (e *codedError) Error() string     { return e.err.Error() }
+func (e *codedError) Unwrap() error     { return e.err }
+func (e *codedError) ErrorCode() string { return e.code }
+
+// WithCode agrega el codigo al error, nil si err es nil
+//
+//	return validation.WithCode("REQ_BODY_SHAPE", errors.New("el body debe ser un objeto json"))
+func WithCode(code string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &codedError{code: code, err: err}
+}
+
+// ErrorCode retorna el codigo del error: el de WithCode (o de un error con el metodo ErrorCode),
+// el registrado con RegisterErrorCode, VAL_INVALID para los errores de validacion y "" si no tiene
+func ErrorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	var coded interface{ ErrorCode() string }
+	if errors.As(err, &coded) {
+		return coded.ErrorCode()
+	}
+	codesMu.RLock()
+	defer codesMu.RUnlock()
+	for _, ec := range errorCodes {
+		if errors.Is(err, ec.target) {
+			return ec.code
+		}
+	}
+	var verrs ValidationErrors
+	var bag ErrorBag
+	if errors.As(err, &verrs) || errors.As(err, &bag) {
+		return CodeInvalid
+	}
+	return ""
+}
+
+// CodedErrors errores de validacion con los codigos de cada campo
+// errors.As(err, &validation.ValidationErrors{}) sigue funcionando gracias a Unwrap
+//
+//	var errs validation.CodedErrors
+//	errs.Add("age", validation.CodeType, "debe ser un número")
+//	return errs.Err()
+type CodedErrors struct {
+	Errors ValidationErrors
+	Codes  map[string][]string
+}
+
+// NewCodedErrors une los errores con sus codigos
+// solo quedan los codigos de los campos con errores y uno por mensaje
+func NewCodedErrors(errs ValidationErrors, codes map[string][]string) *CodedErrors {
+	out := &CodedErrors{Errors: errs}
+	for field, msgs := range errs {
+		fieldCodes := codes[field]
+		if len(fieldCodes) > len(msgs) {
+			fieldCodes = fieldCodes[:len(msgs)]
+		}
+		if len(fieldCodes) == 0 {
+			continue
+		}
+		if out.Codes == nil {
+			out.Codes = make(map[string][]string, len(errs))
+		}
+		out.Codes[field] = fieldCodes
+	}
+	return out
+}
+
+// Add agrega el mensaje con su codigo al campo
+func (e *CodedErrors) Add(field string, code string, message string) {
+	e.Errors = addError(e.Errors, field, message)
+	if e.Codes == nil {
+		e.Codes = make(map[string][]string)
+	}
+	e.Codes[field] = append(e.Codes[field], code)
+}
+
+// Err retorna los errores o nil si no hay
+// retorna una copia para que la variable no escape al heap cuando no hay errores
+func (e *CodedErrors) Err() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	out := *e
+	return &out
+}
+
+func (e *CodedErrors) Error() string     { return e.Errors.Error() }
+func (e *CodedErrors) Unwrap() error     { return e.Errors }
+func (e *CodedErrors) ErrorCode() string { return CodeInvalid }
+
+// MarshalJSON serializa solo los mensajes, los codigos van aparte en la respuesta
+func (e *CodedErrors) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.Errors)
+}
+
+// FieldCodes retorna los codigos por campo de los errores de validacion, nil si err no los tiene
+func FieldCodes(err error) map[string][]string {
+	var coded *CodedErrors
+	if errors.As(err, &coded) {
+		return coded.Codes
+	}
+	return nil
+}
+
+// codeAt retorna el codigo del mensaje i del campo, VAL_INVALID si el mensaje no tiene codigo
+func codeAt(codes map[string][]string, field string, i int) string {
+	if i < len(codes[field]) {
+		return codes[field][i]
+	}
+	return CodeInvalid
+}
@@ -0,0 +1,151 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// DateLayouts formatos que aceptan las reglas de fechas cuando el valor es un string
+// los formatos sin zona horaria se interpretan en UTC
+var DateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// now se puede reemplazar en las pruebas
+var now = time.Now
+
+// LocationResolver retorna la zona horaria de la validacion desde el contexto, nil usa UTC
+// today, tomorrow y yesterday empiezan a la medianoche de esa zona, request la llena con la zona del cliente
+var LocationResolver func(ctx context.Context) *time.Location
+
+// utcTimer lo cumple time.Time y cualquier tipo que lo embeba (request.DateTime)
+type utcTimer interface {
+	UTC() time.Time
+}
+
+// After valida que la fecha sea posterior a la fecha limite
+func After(value time.Time, limit time.Time) error {
+	if !value.After(limit) {
+		return fmt.Errorf("la fecha debe ser posterior a %s", limit.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// Before valida que la fecha sea anterior a la fecha limite
+func Before(value time.Time, limit time.Time) error {
+	if !value.Before(limit) {
+		return fmt.Errorf("la fecha debe ser anterior a %s", limit.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// afterRule after:now, after:tomorrow, after:2026-01-01 o after:otro_campo
+func afterRule(f *Field) error {
+	value, limit, skip, err := dateOperands(f, "after")
+	if skip || err != nil {
+		return err
+	}
+	return After(value, limit)
+}
+
+// beforeRule before:now, before:2026-01-01 o before:otro_campo
+func beforeRule(f *Field) error {
+	value, limit, skip, err := dateOperands(f, "before")
+	if skip || err != nil {
+		return err
+	}
+	return Before(value, limit)
+}
+
+// dateOperands convierte el valor y el parametro de la regla a time.Time
+// skip es true si el campo no tiene valor, eso lo valida required
+func dateOperands(f *Field, rule string) (value time.Time, limit time.Time, skip bool, err error) {
+	if len(f.Params) == 0 {
+		return value, limit, false, fmt.Errorf("la regla %s requiere un parametro", rule)
+	}
+	if f.Value == nil {
+		return value, limit, true, nil
+	}
+	value, ok := toTime(f.Value)
+	if !ok {
+		return value, limit, false, fmt.Errorf("el valor debe ser una fecha válida")
+	}
+	if value.IsZero() {
+		return value, limit, true, nil
+	}
+	limit, ok = dateParam(f.Context, f.Params[0], f.Data)
+	if !ok {
+		return value, limit, false, fmt.Errorf("parametro inválido para la regla %s: %s", rule, f.Params[0])
+	}
+	return value, limit, false, nil
+}
+
+// dateParam resuelve el parametro de la regla: now, today, tomorrow, yesterday, otro campo o una fecha
+// los dias se calculan en la zona de LocationResolver y se comparan en UTC
+func dateParam(ctx context.Context, param string, data map[string]any) (time.Time, bool) {
+	var days int
+	switch param {
+	case "now":
+		return now().UTC(), true
+	case "today":
+	case "tomorrow":
+		days = 1
+	case "yesterday":
+		days = -1
+	default:
+		if v := lookup(data, param); v != nil {
+			return toTime(v)
+		}
+		return toTime(param)
+	}
+	n := now().In(location(ctx))
+	return time.Date(n.Year(), n.Month(), n.Day()+days, 0, 0, 0, 0, n.Location()).UTC(), true
+}
+
+// location zona horaria de la validacion
+func location(ctx context.Context) *time.Location {
+	if LocationResolver != nil && ctx != nil {
+		if loc := LocationResolver(ctx); loc != nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+// toTime convierte el valor a time.Time en UTC
+func toTime(value any) (time.Time, bool) {
+	switch v := value.(type) {
+	case *time.Time:
+		if v == nil {
+			return time.Time{}, true
+		}
+		return v.UTC(), true
+	case utcTimer:
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+			return time.Time{}, true
+		}
+		return v.UTC(), true
+	case string:
+		return ParseDate(v)
+	}
+	return time.Time{}, false
+}
+
+// ParseDate interpreta el string con los formatos de DateLayouts
+func ParseDate(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range DateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
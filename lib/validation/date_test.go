@@ -0,0 +1,54 @@
+package validation
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDateParamUsesValidationLocation(t *testing.T) {
+	bogota, err := time.LoadLocation("America/Bogota")
+	if err != nil {
+		t.Skip("no hay base de zonas horarias:", err)
+	}
+	previousNow, previousResolver := now, LocationResolver
+	t.Cleanup(func() { now, LocationResolver = previousNow, previousResolver })
+	// en UTC ya es 15 de octubre, en Bogota sigue siendo 14
+	now = func() time.Time { return time.Date(2026, 10, 15, 3, 0, 0, 0, time.UTC) }
+
+	type locKey struct{}
+	LocationResolver = func(ctx context.Context) *time.Location {
+		loc, _ := ctx.Value(locKey{}).(*time.Location)
+		return loc
+	}
+
+	cases := []struct {
+		name  string
+		ctx   context.Context
+		param string
+		want  time.Time
+	}{
+		{"today utc", context.Background(), "today", time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)},
+		{"tomorrow utc", context.Background(), "tomorrow", time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)},
+		{"today bogota", context.WithValue(context.Background(), locKey{}, bogota), "today", time.Date(2026, 10, 14, 5, 0, 0, 0, time.UTC)},
+		{"tomorrow bogota", context.WithValue(context.Background(), locKey{}, bogota), "tomorrow", time.Date(2026, 10, 15, 5, 0, 0, 0, time.UTC)},
+		{"yesterday bogota", context.WithValue(context.Background(), locKey{}, bogota), "yesterday", time.Date(2026, 10, 13, 5, 0, 0, 0, time.UTC)},
+		{"now", context.WithValue(context.Background(), locKey{}, bogota), "now", time.Date(2026, 10, 15, 3, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		got, ok := dateParam(c.ctx, c.param, nil)
+		if !ok || !got.Equal(c.want) || got.Location() != time.UTC {
+			t.Errorf("%s: se obtuvo %v, se esperaba %v", c.name, got, c.want)
+		}
+	}
+
+	// before:tomorrow con el 15 a mediodia: en UTC aun es mañana, en Bogota ya es hoy
+	f := &Field{Name: "due", Value: "2026-10-15T12:00:00-05:00", Params: []string{"tomorrow"}, Context: context.Background()}
+	if err := beforeRule(f); err != nil {
+		t.Errorf("en UTC la fecha es anterior a mañana: %v", err)
+	}
+	f.Context = context.WithValue(context.Background(), locKey{}, bogota)
+	if err := beforeRule(f); err == nil {
+		t.Errorf("en Bogota la fecha no es anterior a mañana")
+	}
+}
@@ -0,0 +1,119 @@
+package validation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// distinct_in_payload verifica que el valor no se repita entre los elementos del mismo request
+// en los FormRequest que son un slice (creacion masiva) y en los campos con * del Validator (items.*.sku)
+//
+//	type CreateProducts []struct {
+//		SKU       string `json:"sku" rules:"required|distinct_in_payload"`
+//		Warehouse int    `json:"warehouse"`
+//		Code      string `json:"code" rules:"distinct_in_payload:warehouse,ignore_case"`
+//	}
+//
+// los parametros son otros campos del elemento que forman la llave junto con el campo (code + warehouse)
+// e ignore_case para comparar sin mayusculas. el error va en el elemento repetido y dice cual fue el primero:
+// "3.sku": "el valor ya está en el elemento 0". los valores vacios no se comparan
+
+// distinctInPayloadRule en los slices la regla se evalua en checkDistinct con todos los elementos,
+// en el Validator compara con los elementos anteriores del mismo patron
+func distinctInPayloadRule(f *Field) error {
+	if !strings.Contains(f.pattern, "*") {
+		return nil
+	}
+	prefix, field := splitLast(f.Name)
+	key := distinctKey(f.Data, prefix, field, f.Params)
+	for _, name := range expandWildcards(f.Data, f.pattern) {
+		if name == f.Name {
+			return nil
+		}
+		otherPrefix, _ := splitLast(name)
+		if distinctKey(f.Data, otherPrefix, field, f.Params) == key {
+			return fmt.Errorf("el valor ya está en %s", otherPrefix)
+		}
+	}
+	return nil
+}
+
+// checkDistinct compara los elementos de un slice con las reglas distinct_in_payload
+// elements son los datos de cada elemento, nil para los elementos que no se validaron
+func checkDistinct(errs *CodedErrors, elements []map[string]any, fields []fieldRules) {
+	for _, fr := range fields {
+		for _, r := range fr.rules {
+			if r.Name != "distinct_in_payload" {
+				continue
+			}
+			prefix, field := splitLast(fr.name)
+			seen := make(map[string]int, len(elements))
+			for i, data := range elements {
+				if data == nil {
+					continue
+				}
+				key := distinctKey(data, prefix, field, r.Params)
+				if key == "" {
+					continue
+				}
+				if first, ok := seen[key]; ok {
+					errs.Add(strconv.Itoa(i)+"."+fr.name, RuleCode(r.Name), fmt.Sprintf("el valor ya está en el elemento %d", first))
+					continue
+				}
+				seen[key] = i
+			}
+		}
+	}
+}
+
+// hasDistinct indica si algun campo tiene la regla distinct_in_payload
+func hasDistinct(fields []fieldRules) bool {
+	for _, fr := range fields {
+		for _, r := range fr.rules {
+			if r.Name == "distinct_in_payload" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// distinctKey arma la llave con el campo y los campos de los parametros, "" si el campo esta vacio
+func distinctKey(data map[string]any, prefix string, field string, params []string) string {
+	value := lookup(data, joinPath(prefix, field))
+	if isEmpty(value) {
+		return ""
+	}
+	ignoreCase := false
+	parts := []string{fmt.Sprint(value)}
+	for _, p := range params {
+		if p == "ignore_case" {
+			ignoreCase = true
+			continue
+		}
+		parts = append(parts, fmt.Sprint(lookup(data, joinPath(prefix, p))))
+	}
+	key := strings.Join(parts, "\x00")
+	if ignoreCase {
+		key = strings.ToLower(key)
+	}
+	return key
+}
+
+// splitLast separa el nombre en el elemento y el campo (items.3.sku -> items.3, sku)
+func splitLast(name string) (string, string) {
+	i := strings.LastIndex(name, ".")
+	if i < 0 {
+		return "", name
+	}
+	return name[:i], name[i+1:]
+}
+
+// joinPath une el prefijo y el campo con un punto si hay prefijo
+func joinPath(prefix string, field string) string {
+	if prefix == "" {
+		return field
+	}
+	return prefix + "." + field
+}
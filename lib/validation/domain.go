@@ -0,0 +1,172 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Reglas para los identificadores que van en urls y para los nombres de dominio
+//
+//	Slug     string `json:"slug" rules:"required|slug"`                 // mi-primer-post
+//	Username string `json:"username" rules:"required|username"`         // con la configuracion de Usernames
+//	Handle   string `json:"handle" rules:"username:3,15"`               // o con otra longitud
+//	Host     string `json:"host" rules:"hostname"`                      // localhost, db-1, api.example.com
+//	Domain   string `json:"domain" rules:"fqdn"`                        // api.example.com
+//
+// para aceptar lo que escribe el usuario normalice en PrepareForValidation con formatter.ToSlug o formatter.ToUsername
+//
+//	func (r *CreatePost) PrepareForValidation() error {
+//		if r.Slug == "" {
+//			r.Slug = formatter.ToSlug(r.Title)
+//		}
+//		return nil
+//	}
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Slug valida que el valor sea un slug: minusculas, numeros y guiones entre las palabras
+func Slug(value string) error {
+	if !slugPattern.MatchString(value) {
+		return errors.New("el valor solo puede tener minúsculas, números y guiones entre palabras")
+	}
+	return nil
+}
+
+// UsernameOptions configuracion de la regla username
+type UsernameOptions struct {
+	Min      int
+	Max      int
+	Pattern  *regexp.Regexp // caracteres permitidos
+	Reserved []string       // nombres que no se pueden usar, sin importar mayusculas
+}
+
+// Usernames configuracion de la regla username, se cambia al iniciar la aplicacion
+//
+//	validation.Usernames.Reserved = append(validation.Usernames.Reserved, "soporte", "ventas")
+var Usernames = UsernameOptions{
+	Min:     3,
+	Max:     30,
+	Pattern: regexp.MustCompile(`^[a-z0-9]+([._-][a-z0-9]+)*$`),
+	Reserved: []string{
+		"admin", "administrator", "root", "system", "support", "help", "api", "www", "mail",
+		"null", "undefined", "me", "settings", "login", "logout", "register", "signup",
+		"administrador", "soporte", "ayuda", "sistema", "usuario",
+	},
+}
+
+// Username valida el nombre de usuario con la longitud, los caracteres y los nombres reservados de opts
+func Username(value string, opts UsernameOptions) error {
+	size := len([]rune(value))
+	if size < opts.Min || (opts.Max > 0 && size > opts.Max) {
+		return fmt.Errorf("el nombre de usuario debe tener entre %d y %d caracteres", opts.Min, opts.Max)
+	}
+	if opts.Pattern != nil && !opts.Pattern.MatchString(value) {
+		return errors.New("el nombre de usuario tiene caracteres no permitidos")
+	}
+	for _, reserved := range opts.Reserved {
+		if strings.EqualFold(value, reserved) {
+			return errors.New("el nombre de usuario no está disponible")
+		}
+	}
+	return nil
+}
+
+// Hostname valida un nombre de host (RFC 1123): etiquetas de letras, numeros y guiones separadas por puntos
+// acepta nombres de una sola etiqueta (localhost, db-1)
+func Hostname(value string) error {
+	if _, err := hostLabels(value); err != nil {
+		return err
+	}
+	return nil
+}
+
+// FQDN valida un nombre de dominio completo (api.example.com), el punto final es opcional
+// el dominio de primer nivel no puede ser solo numeros para no aceptar direcciones ip
+func FQDN(value string) error {
+	labels, err := hostLabels(strings.TrimSuffix(value, "."))
+	if err != nil {
+		return err
+	}
+	if len(labels) < 2 {
+		return errors.New("el dominio debe tener al menos dos partes (example.com)")
+	}
+	tld := labels[len(labels)-1]
+	if _, err := strconv.Atoi(tld); err == nil || len(tld) < 2 {
+		return errors.New("el dominio de primer nivel no es válido")
+	}
+	return nil
+}
+
+// hostLabels separa y valida las etiquetas del nombre de host
+func hostLabels(value string) ([]string, error) {
+	if value == "" || len(value) > 253 {
+		return nil, errors.New("el nombre de host debe tener entre 1 y 253 caracteres")
+	}
+	labels := strings.Split(value, ".")
+	for _, label := range labels {
+		if len(label) == 0 || len(label) > 63 {
+			return nil, errors.New("cada parte del nombre de host debe tener entre 1 y 63 caracteres")
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return nil, errors.New("las partes del nombre de host no pueden empezar ni terminar con guion")
+		}
+		for i := 0; i < len(label); i++ {
+			c := label[i]
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-') {
+				return nil, errors.New("el nombre de host solo puede tener letras, números, guiones y puntos")
+			}
+		}
+	}
+	return labels, nil
+}
+
+func slugRule(f *Field) error {
+	s, ok := f.Value.(string)
+	if !ok {
+		return errors.New("el slug debe ser texto")
+	}
+	return Slug(s)
+}
+
+// usernameRule los parametros cambian la longitud de Usernames (username:3,15)
+func usernameRule(f *Field) error {
+	s, ok := f.Value.(string)
+	if !ok {
+		return errors.New("el nombre de usuario debe ser texto")
+	}
+	opts := Usernames
+	if len(f.Params) > 0 {
+		min, err := strconv.Atoi(f.Params[0])
+		if err != nil {
+			return fmt.Errorf("la longitud mínima de la regla username no es válida: %w", err)
+		}
+		opts.Min = min
+	}
+	if len(f.Params) > 1 {
+		max, err := strconv.Atoi(f.Params[1])
+		if err != nil {
+			return fmt.Errorf("la longitud máxima de la regla username no es válida: %w", err)
+		}
+		opts.Max = max
+	}
+	return Username(s, opts)
+}
+
+func hostnameRule(f *Field) error {
+	s, ok := f.Value.(string)
+	if !ok {
+		return errors.New("el nombre de host debe ser texto")
+	}
+	return Hostname(s)
+}
+
+func fqdnRule(f *Field) error {
+	s, ok := f.Value.(string)
+	if !ok {
+		return errors.New("el dominio debe ser texto")
+	}
+	return FQDN(s)
+}
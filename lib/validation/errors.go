@@ -0,0 +1,119 @@
+package validation
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// ValidationErrors errores de validacion agrupados por campo
+//
+//	{
+//	    "email": ["el campo es obligatorio", "invalid email format"],
+//	    "name": ["la longitud del texto 2 es menor que la longitud mínima permitida de 3"]
+//	}
+type ValidationErrors map[string][]string
+
+// Add agrega un mensaje de error al campo
+func (e ValidationErrors) Add(field string, message string) {
+	e[field] = append(e[field], message)
+}
+
+// Has indica si el campo tiene errores
+func (e ValidationErrors) Has(field string) bool {
+	return len(e[field]) > 0
+}
+
+// First retorna el primer error del campo o "" si no tiene
+func (e ValidationErrors) First(field string) string {
+	if msgs := e[field]; len(msgs) > 0 {
+		return msgs[0]
+	}
+	return ""
+}
+
+// Error implementa la interfaz error
+func (e ValidationErrors) Error() string {
+	fields := make([]string, 0, len(e))
+	for field := range e {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var b strings.Builder
+	b.WriteString("errores de validación:")
+	for _, field := range fields {
+		b.WriteString("\n")
+		b.WriteString(field)
+		b.WriteString(": ")
+		b.WriteString(strings.Join(e[field], ", "))
+	}
+	return b.String()
+}
+
+// KeyFormat como se nombran los campos al serializar ValidationErrors a json
+type KeyFormat int
+
+const (
+	// DotKeys notacion de puntos {"items.2.qty": [...]}
+	DotKeys KeyFormat = iota
+	// PointerKeys JSON Pointer (RFC 6901) {"/items/2/qty": [...]}, el formato que esperan las herramientas de OpenAPI
+	PointerKeys
+	// BothKeys lista con los dos nombres [{"field": "items.2.qty", "pointer": "/items/2/qty", "messages": [...]}]
+	BothKeys
+)
+
+// ErrorKeys formato de los nombres de los campos en las respuestas, se configura al iniciar la aplicacion
+var ErrorKeys = DotKeys
+
+// FieldError errores de un campo con sus dos nombres, es el formato de BothKeys
+type FieldError struct {
+	Field    string   `json:"field"`
+	Pointer  string   `json:"pointer"`
+	Messages []string `json:"messages"`
+}
+
+// ToPointer convierte la notacion de puntos en un JSON Pointer (items.2.qty -> /items/2/qty)
+// escapa ~ y / de cada segmento segun RFC 6901
+func ToPointer(field string) string {
+	if field == "" {
+		return ""
+	}
+	var b strings.Builder
+	for _, part := range strings.Split(field, ".") {
+		b.WriteByte('/')
+		part = strings.ReplaceAll(part, "~", "~0")
+		b.WriteString(strings.ReplaceAll(part, "/", "~1"))
+	}
+	return b.String()
+}
+
+// Pointers retorna los errores con los campos como JSON Pointer
+func (e ValidationErrors) Pointers() map[string][]string {
+	out := make(map[string][]string, len(e))
+	for field, msgs := range e {
+		out[ToPointer(field)] = msgs
+	}
+	return out
+}
+
+// Fields retorna los errores con los dos nombres de cada campo ordenados por campo
+func (e ValidationErrors) Fields() []FieldError {
+	out := make([]FieldError, 0, len(e))
+	for field, msgs := range e {
+		out = append(out, FieldError{Field: field, Pointer: ToPointer(field), Messages: msgs})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Field < out[j].Field })
+	return out
+}
+
+// MarshalJSON serializa los errores segun ErrorKeys
+func (e ValidationErrors) MarshalJSON() ([]byte, error) {
+	switch ErrorKeys {
+	case PointerKeys:
+		return json.Marshal(e.Pointers())
+	case BothKeys:
+		return json.Marshal(e.Fields())
+	}
+	return json.Marshal(map[string][]string(e))
+}
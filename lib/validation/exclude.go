@@ -0,0 +1,120 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Las reglas exclude quitan el campo de los datos validados en lugar de validarlo:
+// Validator.Validated no lo incluye y en los structs el campo queda en su valor cero,
+// asi un campo que no aplica no termina en una asignacion masiva
+//
+//	Discount float64 `json:"discount" rules:"exclude_unless:role,admin|min:0"`
+//	Company  string  `json:"company" rules:"exclude_if:type,person|required"`
+//	Internal string  `json:"internal" rules:"exclude"`
+//
+// cuando el campo se excluye no se ejecuta ninguna de sus otras reglas
+
+// excludeRule las reglas exclude no validan nada, se evaluan en isExcluded
+func excludeRule(f *Field) error {
+	return nil
+}
+
+// hasExclude indica si las reglas incluyen alguna regla exclude
+func hasExclude(rules []Rule) bool {
+	for _, r := range rules {
+		switch r.Name {
+		case "exclude", "exclude_if", "exclude_unless":
+			return true
+		}
+	}
+	return false
+}
+
+// isExcluded evalua las reglas exclude del campo con los datos
+func isExcluded(data map[string]any, rules []Rule) bool {
+	for _, r := range rules {
+		switch r.Name {
+		case "exclude":
+			return true
+		case "exclude_if":
+			if len(r.Params) >= 2 && matchesAny(lookup(data, r.Params[0]), r.Params[1:]) {
+				return true
+			}
+		case "exclude_unless":
+			if len(r.Params) >= 2 && !matchesAny(lookup(data, r.Params[0]), r.Params[1:]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// excludedFields retorna los campos que se excluyen con los datos actuales
+func excludedFields(data map[string]any, fields []fieldRules) []string {
+	var out []string
+	for _, fr := range fields {
+		if fr.exclude && isExcluded(data, fr.rules) {
+			out = append(out, fr.name)
+		}
+	}
+	return out
+}
+
+// matchesAny compara el valor con los parametros como texto, null coincide con un valor nulo
+func matchesAny(value any, params []string) bool {
+	s := "null"
+	if value != nil {
+		s = fmt.Sprint(value)
+	}
+	for _, p := range params {
+		if p == s {
+			return true
+		}
+	}
+	return false
+}
+
+// excludeStruct deja en su valor cero los campos excluidos del struct
+func excludeStruct(rv reflect.Value, meta *structMeta, data map[string]any) {
+	for _, name := range excludedFields(data, meta.rules) {
+		zeroField(rv, meta, name)
+	}
+}
+
+// zeroField busca el campo por su nombre en notacion de puntos y lo deja en su valor cero
+func zeroField(rv reflect.Value, meta *structMeta, name string) {
+	if fv, ok := findField(rv, meta, name); ok {
+		fv.Set(reflect.Zero(fv.Type()))
+	}
+}
+
+// findField busca el campo del struct por su nombre en notacion de puntos
+// retorna false si el campo no existe, no se puede cambiar o un struct intermedio es nil
+func findField(rv reflect.Value, meta *structMeta, name string) (reflect.Value, bool) {
+	key, rest, nested := strings.Cut(name, ".")
+	for _, sf := range meta.fields {
+		if sf.name != key {
+			continue
+		}
+		fv, err := rv.FieldByIndexErr(sf.index)
+		if err != nil || !fv.CanSet() {
+			return reflect.Value{}, false
+		}
+		if !nested {
+			return fv, true
+		}
+		if sf.nested == nil {
+			return reflect.Value{}, false
+		}
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				return reflect.Value{}, false
+			}
+			fv = fv.Elem()
+		}
+		return findField(fv, sf.nested, rest)
+	}
+	return reflect.Value{}, false
+}
@@ -0,0 +1,190 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Exportar las reglas de los structs para validar en el navegador con las mismas restricciones del servidor
+// (vee-validate, zod, yup o un validador propio), se genera un json o un archivo typescript
+//
+//	out, err := validation.ExportTypeScript(map[string]any{
+//		"createUser": &request.User{},
+//	})
+//	os.WriteFile("web/src/rules.ts", out, 0o644)
+//
+// cada campo tiene el nombre en notacion de puntos, el tipo (string, integer, number, boolean, date, array, object)
+// y sus reglas con los parametros. No se exportan las reglas que solo se pueden evaluar en el servidor (ServerRules)
+// ni las que tienen marcadores {auth.id} porque su valor se conoce en cada solicitud
+
+// ServerRules reglas que no se exportan al cliente, la aplicacion agrega las suyas (unique, exists)
+//
+//	validation.ServerRules["unique"] = true
+var ServerRules = map[string]bool{
+	"captcha":             true,
+	"checksum":            true,
+	"distinct_in_payload": true,
+	"exclude":             true,
+	"exclude_if":          true,
+	"exclude_unless":      true,
+}
+
+// ExportedField reglas de un campo para el cliente
+type ExportedField struct {
+	Name  string         `json:"name"`
+	Type  string         `json:"type"`
+	Rules []ExportedRule `json:"rules"`
+}
+
+// ExportedRule una regla con sus parametros
+type ExportedRule struct {
+	Rule   string   `json:"rule"`
+	Params []string `json:"params,omitempty"`
+}
+
+// Export retorna las reglas de los campos del struct (o de los elementos de un slice de structs)
+func Export(v any) ([]ExportedField, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("export: se espera un struct o un slice de structs y se recibió %T", v)
+	}
+
+	meta := getStructMeta(t)
+	fields := make([]ExportedField, 0, len(meta.rules))
+	for _, fr := range meta.rules {
+		field := ExportedField{Name: fr.name, Type: clientType(fieldType(t, meta, fr.name)), Rules: []ExportedRule{}}
+		for _, r := range fr.rules {
+			if ServerRules[r.Name] || hasPlaceholder(r.Params) {
+				continue
+			}
+			field.Rules = append(field.Rules, ExportedRule{Rule: r.Name, Params: r.Params})
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// ExportJSON exporta las reglas de cada struct con su nombre, { "createUser": [campos...] }
+func ExportJSON(forms map[string]any) ([]byte, error) {
+	out := make(map[string][]ExportedField, len(forms))
+	for name, v := range forms {
+		fields, err := Export(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		out[name] = fields
+	}
+	return json.MarshalIndent(out, "", "  ")
+}
+
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+
+// tsIdentifier nombres validos para las constantes de typescript
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// ExportTypeScript exporta las reglas como un modulo typescript con una constante por struct
+//
+//	export const createUser: FieldRules[] = [ ... ];
+func ExportTypeScript(forms map[string]any) ([]byte, error) {
+	names := make([]string, 0, len(forms))
+	for name := range forms {
+		if !tsIdentifier.MatchString(name) {
+			return nil, fmt.Errorf("export: '%s' no es un nombre válido para typescript", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	buf.WriteString("// Generado con validation.ExportTypeScript, no se debe editar a mano\n\n")
+	buf.WriteString("export type Rule = { rule: string; params?: string[] };\n")
+	buf.WriteString("export type FieldRules = { name: string; type: string; rules: Rule[] };\n")
+	for _, name := range names {
+		fields, err := Export(forms[name])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		data, err := json.MarshalIndent(fields, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&buf, "\nexport const %s: FieldRules[] = %s;\n", name, data)
+	}
+	return buf.Bytes(), nil
+}
+
+// fieldType busca el tipo del campo en notacion de puntos, nil si no lo encuentra
+func fieldType(t reflect.Type, meta *structMeta, name string) reflect.Type {
+	part, rest, nested := strings.Cut(name, ".")
+	for _, sf := range meta.fields {
+		if sf.name != part {
+			continue
+		}
+		ft := t.FieldByIndex(sf.index).Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if !nested {
+			return ft
+		}
+		if sf.nested == nil {
+			return nil
+		}
+		return fieldType(ft, sf.nested, rest)
+	}
+	return nil
+}
+
+// clientType nombre del tipo para el cliente
+func clientType(t reflect.Type) string {
+	if t == nil {
+		return "any"
+	}
+	if t == timeType {
+		return "date"
+	}
+	if t == rawMessageType {
+		return "any"
+	}
+	if t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		return "string"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		// encoding/json envia los []byte en base64
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return "string"
+		}
+		return "array"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	}
+	return "any"
+}
+
+// hasPlaceholder indica si algun parametro tiene marcadores {nombre}
+func hasPlaceholder(params []string) bool {
+	for _, p := range params {
+		if strings.Contains(p, "{") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,324 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/donbarrigon/new-project/lib/geo"
+)
+
+// Reglas para colores y datos geograficos
+//
+//	Color    string          `json:"color" rules:"hex_color"`              // #fff, #ffcc00, #ffcc0080
+//	Lat      float64         `json:"lat" rules:"required|latitude"`        // -90 a 90
+//	Lng      float64         `json:"lng" rules:"required|longitude"`       // -180 a 180
+//	Area     json.RawMessage `json:"area" rules:"required|geojson:Polygon,MultiPolygon"`
+//	View     *geo.BBox       `json:"bbox" query:"bbox" rules:"bbox"`       // -74.2,4.5,-73.9,4.8
+//	Zone     geo.Polygon     `json:"zone" query:"zone" rules:"polygon:50"` // lng,lat,lng,lat,lng,lat
+//
+// latitude y longitude aceptan numeros, punteros a numeros o texto numerico ("4.7110"),
+// bbox y polygon aceptan los tipos de lib/geo, el texto de la url o los arrays del json,
+// geojson acepta el objeto json, texto con el json o json.RawMessage y valida la forma de las coordenadas (RFC 7946)
+
+var hexColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{4}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+
+// HexColor valida un color hexadecimal con # de 3, 4, 6 u 8 digitos (los de 4 y 8 tienen transparencia)
+func HexColor(value string) error {
+	if !hexColorPattern.MatchString(value) {
+		return errors.New("el color debe ser hexadecimal (#rgb o #rrggbb)")
+	}
+	return nil
+}
+
+// Latitude valida que la latitud este entre -90 y 90
+func Latitude(value float64) error {
+	if value < -90 || value > 90 {
+		return errors.New("la latitud debe estar entre -90 y 90")
+	}
+	return nil
+}
+
+// Longitude valida que la longitud este entre -180 y 180
+func Longitude(value float64) error {
+	if value < -180 || value > 180 {
+		return errors.New("la longitud debe estar entre -180 y 180")
+	}
+	return nil
+}
+
+// geometryTypes tipos de geometria de GeoJSON
+var geometryTypes = map[string]bool{
+	"Point": true, "MultiPoint": true, "LineString": true, "MultiLineString": true,
+	"Polygon": true, "MultiPolygon": true, "GeometryCollection": true,
+}
+
+// coordinateDepth niveles de arrays hasta las posiciones segun el tipo de geometria
+var coordinateDepth = map[string]int{"Point": 0, "MultiPoint": 1, "LineString": 1, "MultiLineString": 2, "Polygon": 2, "MultiPolygon": 3}
+
+// GeoJSON valida un objeto GeoJSON ya decodificado (geometria, Feature o FeatureCollection)
+// si se indican tipos el objeto debe ser de alguno de ellos
+func GeoJSON(value map[string]any, types ...string) error {
+	kind, _ := value["type"].(string)
+	if len(types) > 0 && !slices.Contains(types, kind) {
+		return fmt.Errorf("el GeoJSON debe ser de tipo %s", strings.Join(types, ", "))
+	}
+	return checkGeoJSON(value, "")
+}
+
+// checkGeoJSON valida el objeto, path es la ubicacion dentro del documento para los mensajes (features.2.geometry)
+func checkGeoJSON(value map[string]any, path string) error {
+	kind, _ := value["type"].(string)
+	switch kind {
+	case "FeatureCollection":
+		features, ok := value["features"].([]any)
+		if !ok {
+			return geoError(path, "features debe ser un array")
+		}
+		for i, feature := range features {
+			m, ok := feature.(map[string]any)
+			if !ok || m["type"] != "Feature" {
+				return geoError(path, fmt.Sprintf("features.%d debe ser un Feature", i))
+			}
+			if err := checkGeoJSON(m, geoPath(path, "features."+strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "Feature":
+		geometry, ok := value["geometry"]
+		if !ok {
+			return geoError(path, "el Feature debe tener geometry")
+		}
+		if geometry == nil {
+			return nil
+		}
+		m, ok := geometry.(map[string]any)
+		if !ok || !geometryTypes[fmt.Sprint(m["type"])] {
+			return geoError(path, "geometry debe ser una geometría")
+		}
+		return checkGeoJSON(m, geoPath(path, "geometry"))
+	case "GeometryCollection":
+		geometries, ok := value["geometries"].([]any)
+		if !ok {
+			return geoError(path, "geometries debe ser un array")
+		}
+		for i, geometry := range geometries {
+			m, ok := geometry.(map[string]any)
+			if !ok || !geometryTypes[fmt.Sprint(m["type"])] {
+				return geoError(path, fmt.Sprintf("geometries.%d debe ser una geometría", i))
+			}
+			if err := checkGeoJSON(m, geoPath(path, "geometries."+strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if !geometryTypes[kind] {
+		return geoError(path, fmt.Sprintf("el tipo '%s' no es un tipo de GeoJSON", kind))
+	}
+	coordinates, ok := value["coordinates"].([]any)
+	if !ok {
+		return geoError(path, "coordinates debe ser un array")
+	}
+	if err := checkCoordinates(kind, coordinates, coordinateDepth[kind]); err != nil {
+		return geoError(path, err.Error())
+	}
+	return nil
+}
+
+// checkCoordinates recorre las coordenadas hasta las posiciones y valida las reglas de cada tipo
+func checkCoordinates(kind string, coordinates []any, depth int) error {
+	if depth == 0 {
+		return checkPosition(coordinates)
+	}
+	if depth == 1 {
+		switch {
+		case kind == "LineString" || kind == "MultiLineString":
+			if len(coordinates) < 2 {
+				return errors.New("una línea debe tener al menos 2 posiciones")
+			}
+		case kind == "Polygon" || kind == "MultiPolygon":
+			if len(coordinates) < 4 {
+				return errors.New("un anillo del polígono debe tener al menos 4 posiciones")
+			}
+		}
+	}
+	for _, c := range coordinates {
+		inner, ok := c.([]any)
+		if !ok {
+			return errors.New("las coordenadas no tienen la forma del tipo de geometría")
+		}
+		if err := checkCoordinates(kind, inner, depth-1); err != nil {
+			return err
+		}
+	}
+	// los anillos de un poligono son cerrados: la primera y la ultima posicion son iguales
+	if depth == 1 && (kind == "Polygon" || kind == "MultiPolygon") {
+		if fmt.Sprint(coordinates[0]) != fmt.Sprint(coordinates[len(coordinates)-1]) {
+			return errors.New("un anillo del polígono debe terminar en la misma posición en que empieza")
+		}
+	}
+	return nil
+}
+
+// checkPosition valida una posicion [longitud, latitud] con la altura opcional
+func checkPosition(position []any) error {
+	if len(position) < 2 || len(position) > 3 {
+		return errors.New("una posición debe ser [longitud, latitud] o [longitud, latitud, altura]")
+	}
+	values := make([]float64, len(position))
+	for i, p := range position {
+		n, ok := toFloat64(p)
+		if !ok {
+			return errors.New("las coordenadas deben ser números")
+		}
+		values[i] = n
+	}
+	if err := Longitude(values[0]); err != nil {
+		return err
+	}
+	return Latitude(values[1])
+}
+
+// geoPath agrega la llave a la ubicacion dentro del documento
+func geoPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// geoError antepone la ubicacion al mensaje si el error no esta en la raiz
+func geoError(path string, message string) error {
+	if path == "" {
+		return errors.New(message)
+	}
+	return fmt.Errorf("%s: %s", path, message)
+}
+
+func hexColorRule(f *Field) error {
+	s, ok := f.Value.(string)
+	if !ok {
+		return errors.New("el color debe ser texto")
+	}
+	return HexColor(s)
+}
+
+func latitudeRule(f *Field) error {
+	n, ok := coordinate(f.Value)
+	if !ok {
+		return errors.New("la latitud debe ser un número")
+	}
+	return Latitude(n)
+}
+
+func longitudeRule(f *Field) error {
+	n, ok := coordinate(f.Value)
+	if !ok {
+		return errors.New("la longitud debe ser un número")
+	}
+	return Longitude(n)
+}
+
+// coordinate convierte el valor a numero, acepta texto numerico
+func coordinate(value any) (float64, bool) {
+	if s, ok := value.(string); ok {
+		n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		return n, err == nil
+	}
+	return toFloat64(value)
+}
+
+// geojsonRule los parametros son los tipos permitidos (geojson:Point,Polygon)
+func geojsonRule(f *Field) error {
+	var raw []byte
+	switch v := f.Value.(type) {
+	case map[string]any:
+		return GeoJSON(v, f.Params...)
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	case json.RawMessage:
+		raw = v
+	default:
+		var err error
+		if raw, err = json.Marshal(v); err != nil {
+			return errors.New("el valor no es un GeoJSON válido")
+		}
+	}
+	var m map[string]any
+	if err := json.Unmarshal(raw, &m); err != nil || m == nil {
+		return errors.New("el valor no es un GeoJSON válido")
+	}
+	return GeoJSON(m, f.Params...)
+}
+
+// bboxValue retorna el bbox interpretado
+func bboxValue(value any) (geo.BBox, error) {
+	switch v := value.(type) {
+	case geo.BBox:
+		return v, nil
+	case *geo.BBox:
+		return *v, nil
+	case string:
+		return geo.ParseBBox(v)
+	case []any, []float64:
+		raw, _ := json.Marshal(v)
+		var b geo.BBox
+		err := json.Unmarshal(raw, &b)
+		return b, err
+	}
+	return geo.BBox{}, errors.New("el bbox debe ser oeste,sur,este,norte")
+}
+
+// bboxRule area oeste,sur,este,norte con coordenadas validas, el oeste mayor que el este cruza el antimeridiano
+func bboxRule(f *Field) error {
+	b, err := bboxValue(f.Value)
+	if err != nil {
+		return err
+	}
+	if b.IsZero() {
+		return nil
+	}
+	return b.Valid()
+}
+
+// polygonRule el parametro opcional es el maximo de vertices (polygon:50), geo.MaxPolygonVertices si se omite
+func polygonRule(f *Field) error {
+	max := geo.MaxPolygonVertices
+	if len(f.Params) > 0 {
+		n, err := strconv.Atoi(f.Params[0])
+		if err != nil {
+			return fmt.Errorf("el máximo de vértices '%s' no es un número", f.Params[0])
+		}
+		max = n
+	}
+	var p geo.Polygon
+	switch v := f.Value.(type) {
+	case geo.Polygon:
+		p = v
+	case *geo.Polygon:
+		p = *v
+	case string:
+		parsed, err := geo.ParsePolygon(v)
+		if err != nil {
+			return err
+		}
+		p = parsed
+	case []any:
+		raw, _ := json.Marshal(v)
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return err
+		}
+	default:
+		return errors.New("el polígono debe ser una lista de longitud,latitud")
+	}
+	return p.Valid(max)
+}
@@ -0,0 +1,57 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/donbarrigon/new-project/lib/sanitize"
+)
+
+// NoHTML valida que el texto no tenga etiquetas, comentarios ni declaraciones html
+func NoHTML(value string) error {
+	if sanitize.HasHTML(value) {
+		return errors.New("el texto no debe contener html")
+	}
+	return nil
+}
+
+// SafeHTML valida que el html solo use lo que permite la politica
+// para limpiar el valor en lugar de rechazarlo use el tag sanitize del FormRequest o sanitize.HTML
+func SafeHTML(value string, policy *sanitize.Policy) error {
+	if err := policy.Check(value); err != nil {
+		return fmt.Errorf("el html no es seguro: %v", err)
+	}
+	return nil
+}
+
+// noHTMLRule adapta NoHTML al registro de reglas
+//
+//	Name string `json:"name" rules:"required|no_html"`
+func noHTMLRule(f *Field) error {
+	if f.Value == nil {
+		return nil
+	}
+	s, ok := f.Value.(string)
+	if !ok {
+		return errors.New("el valor debe ser texto")
+	}
+	return NoHTML(s)
+}
+
+// safeHTMLRule adapta SafeHTML al registro de reglas, los parametros son politicas registradas
+// (strict, basic, links, rich o las de sanitize.RegisterPolicy) o etiquetas sueltas
+//
+//	Body string `json:"body" rules:"required|safe_html:links,h2,h3"`
+func safeHTMLRule(f *Field) error {
+	if f.Value == nil {
+		return nil
+	}
+	s, ok := f.Value.(string)
+	if !ok {
+		return errors.New("el valor debe ser texto")
+	}
+	if len(f.Params) == 0 {
+		return errors.New("la regla safe_html requiere un parametro")
+	}
+	return SafeHTML(s, sanitize.Combine(f.Params...))
+}
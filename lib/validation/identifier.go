@@ -0,0 +1,176 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// identifierPattern nombre de columna o tabla.columna, solo letras, numeros y guion bajo
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,63}(\.[A-Za-z_][A-Za-z0-9_]{0,63})?$`)
+
+// Identifier valida que el valor sea un identificador sql seguro (name, users.created_at)
+// no alcanza para armar un ORDER BY con lo que envia el cliente, para eso use Columns con la lista de columnas permitidas
+func Identifier(value string) error {
+	if !identifierPattern.MatchString(value) {
+		return errors.New("el valor debe ser un identificador válido (letras, números y guion bajo)")
+	}
+	return nil
+}
+
+// identifierRule adapta Identifier al registro de reglas
+//
+//	Group string `json:"group" query:"group" rules:"identifier"`
+func identifierRule(f *Field) error {
+	if f.Value == nil {
+		return nil
+	}
+	s, ok := f.Value.(string)
+	if !ok {
+		return errors.New("el valor debe ser texto")
+	}
+	if s == "" {
+		return nil
+	}
+	return Identifier(s)
+}
+
+// SortField columna y direccion de un parametro de ordenamiento ya validado
+type SortField struct {
+	Column string
+	Desc   bool
+}
+
+// Order direccion para mongo (1 ascendente, -1 descendente)
+func (s SortField) Order() int {
+	if s.Desc {
+		return -1
+	}
+	return 1
+}
+
+// SQL columna con su direccion para un ORDER BY ("created_at DESC")
+// la columna viene de la lista blanca, no del cliente, por eso se puede concatenar
+func (s SortField) SQL() string {
+	if s.Desc {
+		return s.Column + " DESC"
+	}
+	return s.Column + " ASC"
+}
+
+// ColumnRule lista blanca de columnas para los parametros de la url que terminan en un ORDER BY
+// o en la seleccion de columnas, las columnas que no estan en la lista se rechazan
+//
+//	validation.Columns("name", "email", "created_at").Sortable().Multiple().Register("user_sort")
+//
+//	Sort string `query:"sort" rules:"user_sort"` // ?sort=-created_at,name
+type ColumnRule struct {
+	columns  map[string]string // nombre publico -> columna real
+	sortable bool
+	multiple bool
+}
+
+// Columns crea la regla con las columnas permitidas, "publico:columna" permite exponer otro nombre
+//
+//	validation.Columns("name", "created:created_at")
+func Columns(columns ...string) *ColumnRule {
+	r := &ColumnRule{columns: make(map[string]string, len(columns))}
+	for _, c := range columns {
+		public, column, found := strings.Cut(c, ":")
+		if !found {
+			column = public
+		}
+		if Identifier(column) != nil {
+			panic(fmt.Sprintf("validation: la columna '%s' no es un identificador válido", column))
+		}
+		r.columns[public] = column
+	}
+	return r
+}
+
+// Sortable acepta la direccion como prefijo "-name" o sufijo "name:desc" / "name:asc"
+func (r *ColumnRule) Sortable() *ColumnRule {
+	r.sortable = true
+	return r
+}
+
+// Multiple acepta varias columnas separadas por coma
+func (r *ColumnRule) Multiple() *ColumnRule {
+	r.multiple = true
+	return r
+}
+
+// Register registra la regla con el nombre indicado
+// se debe llamar al iniciar la aplicacion, igual que RegisterRule
+func (r *ColumnRule) Register(name string) *ColumnRule {
+	RegisterRule(name, r.Rule)
+	return r
+}
+
+// Rule valida el campo, se puede registrar directamente con RegisterRule
+func (r *ColumnRule) Rule(f *Field) error {
+	if f.Value == nil {
+		return nil
+	}
+	s, ok := f.Value.(string)
+	if !ok {
+		return errors.New("el valor debe ser texto")
+	}
+	if s == "" {
+		return nil
+	}
+	_, err := r.Parse(s)
+	return err
+}
+
+// Parse valida el valor y retorna las columnas reales con su direccion
+// el controlador arma el ORDER BY con el resultado y nunca con el texto que envio el cliente
+//
+//	fields, err := userSort.Parse(req.Sort)
+//	for _, f := range fields {
+//		sort = append(sort, bson.E{Key: f.Column, Value: f.Order()})
+//	}
+func (r *ColumnRule) Parse(value string) ([]SortField, error) {
+	parts := []string{value}
+	if r.multiple {
+		parts = strings.Split(value, ",")
+	}
+
+	fields := make([]SortField, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		var desc bool
+		if r.sortable {
+			if rest, ok := strings.CutPrefix(part, "-"); ok {
+				part, desc = rest, true
+			} else if name, dir, found := strings.Cut(part, ":"); found {
+				switch strings.ToLower(dir) {
+				case "asc":
+				case "desc":
+					desc = true
+				default:
+					return nil, fmt.Errorf("la dirección '%s' no es válida, use asc o desc", dir)
+				}
+				part = name
+			}
+		}
+		column, ok := r.columns[part]
+		if !ok {
+			return nil, fmt.Errorf("la columna '%s' no está permitida, use: %s", part, r.allowed())
+		}
+		fields = append(fields, SortField{Column: column, Desc: desc})
+	}
+	return fields, nil
+}
+
+// allowed lista las columnas publicas para el mensaje de error
+func (r *ColumnRule) allowed() string {
+	names := make([]string, 0, len(r.columns))
+	for name := range r.columns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,71 @@
+package validation
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/donbarrigon/new-project/lib/ids"
+	"github.com/donbarrigon/new-project/lib/semver"
+)
+
+// Reglas para versiones semanticas e identificadores ordenables por tiempo
+//
+//	AppVersion string         `json:"app_version" rules:"required|semver:>=2.1.0,<3.0.0"`
+//	Release    semver.Version `json:"release" rules:"semver:^1.0.0"`
+//	RequestID  string         `json:"request_id" rules:"ulid"`
+//	EventID    ids.KSUID      `json:"event_id" query:"event" rules:"required|ksuid"`
+//
+// las restricciones de semver son las de semver.Satisfies y se deben cumplir todas.
+// los campos pueden ser texto o los tipos de lib/semver y lib/ids, que el json y la url ya llenan interpretados
+
+// textValue retorna el valor como texto, los tipos que se serializan como texto (ids.ULID) se convierten
+func textValue(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case encoding.TextMarshaler:
+		b, err := v.MarshalText()
+		return string(b), err == nil
+	}
+	return "", false
+}
+
+// semverRule los parametros son restricciones de version (semver:>=1.2.0,<2.0.0)
+func semverRule(f *Field) error {
+	s, ok := textValue(f.Value)
+	if !ok {
+		return errors.New("la versión debe ser texto")
+	}
+	v, err := semver.Parse(s)
+	if err != nil {
+		return err
+	}
+	ok, err = semver.Satisfies(v, f.Params...)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("la versión %s no cumple %s", s, strings.Join(f.Params, ", "))
+	}
+	return nil
+}
+
+func ulidRule(f *Field) error {
+	s, ok := textValue(f.Value)
+	if !ok {
+		return errors.New("el ULID debe ser texto")
+	}
+	_, err := ids.ParseULID(s)
+	return err
+}
+
+func ksuidRule(f *Field) error {
+	s, ok := textValue(f.Value)
+	if !ok {
+		return errors.New("el KSUID debe ser texto")
+	}
+	_, err := ids.ParseKSUID(s)
+	return err
+}
@@ -0,0 +1,34 @@
+package latam
+
+import "errors"
+
+// cuitPrefixes tipos de CUIT/CUIL: personas (20, 23, 24, 27) y empresas (30, 33, 34)
+var cuitPrefixes = map[string]bool{"20": true, "23": true, "24": true, "27": true, "30": true, "33": true, "34": true}
+
+// CUIT valida la Clave Única de Identificación Tributaria (o el CUIL) de Argentina: 20-12345678-6
+func CUIT(value string) error {
+	cuit, ok := digits(value)
+	if !ok || len(cuit) != 11 {
+		return errors.New("el CUIT debe tener 11 dígitos")
+	}
+	if !cuitPrefixes[cuit[:2]] {
+		return errors.New("el tipo del CUIT no es válido")
+	}
+	weights := [10]int{5, 4, 3, 2, 7, 6, 5, 4, 3, 2}
+	sum := 0
+	for i, w := range weights {
+		sum += int(cuit[i]-'0') * w
+	}
+	check := 11 - sum%11
+	switch check {
+	case 11:
+		check = 0
+	case 10:
+		// la AFIP no asigna numeros con resto 10, les cambia el tipo (23, 33)
+		return errors.New("el dígito verificador del CUIT no es válido")
+	}
+	if int(cuit[10]-'0') != check {
+		return errors.New("el dígito verificador del CUIT no es válido")
+	}
+	return nil
+}
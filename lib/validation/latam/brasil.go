@@ -0,0 +1,26 @@
+package latam
+
+import "errors"
+
+// CPF valida el Cadastro de Pessoas Físicas de Brasil: 123.456.789-09
+func CPF(value string) error {
+	cpf, ok := digits(value)
+	if !ok || len(cpf) != 11 {
+		return errors.New("el CPF debe tener 11 dígitos")
+	}
+	if allSame(cpf) {
+		return errors.New("el CPF no es válido")
+	}
+	// los dos digitos verificadores: el primero con los 9 digitos y el segundo con los 10
+	for n := 9; n <= 10; n++ {
+		sum := 0
+		for i := 0; i < n; i++ {
+			sum += int(cpf[i]-'0') * (n + 1 - i)
+		}
+		check := sum * 10 % 11 % 10
+		if int(cpf[n]-'0') != check {
+			return errors.New("el dígito verificador del CPF no es válido")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package latam
+
+import (
+	"errors"
+	"strings"
+)
+
+// RUT valida el Rol Único Tributario (o RUN) de Chile: 12.345.678-5, el digito verificador puede ser K
+func RUT(value string) error {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if value == "" {
+		return errors.New("el RUT no es válido")
+	}
+	body, ok := digits(value[:len(value)-1])
+	if !ok || len(body) < 7 || len(body) > 8 {
+		return errors.New("el RUT debe tener entre 7 y 8 dígitos y el dígito verificador")
+	}
+	sum, weight := 0, 2
+	for i := len(body) - 1; i >= 0; i-- {
+		sum += int(body[i]-'0') * weight
+		weight++
+		if weight > 7 {
+			weight = 2
+		}
+	}
+	var check byte
+	switch r := 11 - sum%11; r {
+	case 11:
+		check = '0'
+	case 10:
+		check = 'K'
+	default:
+		check = byte('0' + r)
+	}
+	if value[len(value)-1] != check {
+		return errors.New("el dígito verificador del RUT no es válido")
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package latam
+
+import "errors"
+
+// nitWeights pesos de la DIAN para cada digito desde la derecha
+var nitWeights = [15]int{3, 7, 13, 17, 19, 23, 29, 37, 41, 43, 47, 53, 59, 67, 71}
+
+// NIT valida el Número de Identificación Tributaria de Colombia con el digito de verificacion: 900.123.456-8
+func NIT(value string) error {
+	nit, ok := digits(value)
+	if !ok || len(nit) < 6 || len(nit) > 16 {
+		return errors.New("el NIT debe tener entre 5 y 15 dígitos y el dígito de verificación")
+	}
+	body := nit[:len(nit)-1]
+	sum := 0
+	for i := 0; i < len(body); i++ {
+		sum += int(body[len(body)-1-i]-'0') * nitWeights[i]
+	}
+	check := sum % 11
+	if check > 1 {
+		check = 11 - check
+	}
+	if int(nit[len(nit)-1]-'0') != check {
+		return errors.New("el dígito de verificación del NIT no es válido")
+	}
+	return nil
+}
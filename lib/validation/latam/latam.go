@@ -0,0 +1,64 @@
+// Package latam reglas de validacion para los documentos de identificacion tributaria de latinoamerica
+// con la verificacion del digito de control. no se registran solas, se activan al iniciar la aplicacion:
+//
+//	latam.Register()
+//
+//	type CreateCustomer struct {
+//		RFC  string `json:"rfc" rules:"required|rfc"`    // México, persona fisica o moral
+//		CURP string `json:"curp" rules:"curp"`           // México
+//		CUIT string `json:"cuit" rules:"cuit"`           // Argentina, CUIT o CUIL
+//		CPF  string `json:"cpf" rules:"cpf"`             // Brasil
+//		RUT  string `json:"rut" rules:"rut"`             // Chile
+//		NIT  string `json:"nit" rules:"nit"`             // Colombia, con el digito de verificacion
+//	}
+//
+// los valores se aceptan con los separadores habituales de cada pais (20-12345678-6, 123.456.789-09, 12.345.678-5)
+package latam
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+// Register agrega las reglas rfc, curp, cuit, cpf, rut y nit al registro de validation
+func Register() {
+	validation.RegisterRule("rfc", stringRule(RFC))
+	validation.RegisterRule("curp", stringRule(CURP))
+	validation.RegisterRule("cuit", stringRule(CUIT))
+	validation.RegisterRule("cpf", stringRule(CPF))
+	validation.RegisterRule("rut", stringRule(RUT))
+	validation.RegisterRule("nit", stringRule(NIT))
+}
+
+// stringRule adapta una funcion de validacion de texto a una regla
+func stringRule(fn func(string) error) validation.RuleFunc {
+	return func(f *validation.Field) error {
+		s, ok := f.Value.(string)
+		if !ok {
+			return errors.New("el documento debe ser texto")
+		}
+		return fn(s)
+	}
+}
+
+// digits quita los separadores (puntos, guiones, espacios y barras), retorna false si queda algo que no es un digito
+func digits(value string) (string, bool) {
+	var b strings.Builder
+	for _, r := range value {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '.', r == '-', r == ' ', r == '/':
+		default:
+			return "", false
+		}
+	}
+	return b.String(), true
+}
+
+// allSame indica si todos los digitos son iguales (111.111.111-11 pasa el digito de control pero no es valido)
+func allSame(s string) bool {
+	return strings.Count(s, s[:1]) == len(s)
+}
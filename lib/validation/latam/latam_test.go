@@ -0,0 +1,73 @@
+package latam
+
+import (
+	"testing"
+
+	"github.com/donbarrigon/new-project/lib/validation"
+)
+
+func TestDocuments(t *testing.T) {
+	cases := []struct {
+		name  string
+		fn    func(string) error
+		valid []string
+		wrong []string
+	}{
+		{
+			"RFC", RFC,
+			[]string{"SAT970701NN3", "GODE561231GR8", "gode-561231-gr8", "XAXX010101000", "XEXX010101000"},
+			[]string{"", "SAT970701NN4", "GODE561231GR9", "GODE563112GR8", "GODE5612310GR8", "GO1E561231GR8", "SAT970230NN3"},
+		},
+		{
+			"CURP", CURP,
+			[]string{"MAHJ280603MSPRRV09", "GORS850101HDFNNR09", " mahj280603msprrv09 "},
+			[]string{"", "MAHJ280603MSPRRV08", "MAHJ281303MSPRRV09", "MAHJ280603MXXRRV09", "MAHJ280603ZSPRRV09", "MAHJ280603MSPRRV0"},
+		},
+		{
+			"CUIT", CUIT,
+			[]string{"20-12345678-6", "20123456786", "30-50001091-2", "27-28033514-8", "33-69345023-9"},
+			[]string{"", "20-12345678-7", "21-12345678-6", "20-1234567-6", "20-12345678-66", "20-1234567A-6", "20-12345679-0"},
+		},
+		{
+			"CPF", CPF,
+			[]string{"529.982.247-25", "52998224725", "111.444.777-35", "123.456.789-09"},
+			[]string{"", "529.982.247-24", "529.982.247-15", "111.111.111-11", "000.000.000-00", "529.982.247-2", "529,982,247-25"},
+		},
+		{
+			"RUT", RUT,
+			[]string{"12.345.678-5", "11.111.111-1", "8.765.432-K", "8.765.432-k", "6265837-1"},
+			[]string{"", "12.345.678-4", "8.765.432-0", "123.456-0", "123.456.789-0", "12.345.67A-5"},
+		},
+		{
+			"NIT", NIT,
+			[]string{"800.197.268-4", "8001972684", "890.903.938-8", "900.123.456-8"},
+			[]string{"", "800.197.268-5", "890.903.938-0", "1234", "12345678901234567", "800.197.2A8-4"},
+		},
+	}
+	for _, c := range cases {
+		for _, v := range c.valid {
+			if err := c.fn(v); err != nil {
+				t.Errorf("%s %q: se esperaba valido, se obtuvo %v", c.name, v, err)
+			}
+		}
+		for _, v := range c.wrong {
+			if c.fn(v) == nil {
+				t.Errorf("%s %q: se esperaba invalido", c.name, v)
+			}
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register()
+	type customer struct {
+		RFC string `json:"rfc" rules:"required|rfc"`
+		NIT any    `json:"nit" rules:"nit"`
+	}
+	if err := validation.Struct(&customer{RFC: "SAT970701NN3", NIT: "800.197.268-4"}); err != nil {
+		t.Errorf("se esperaba nil, se obtuvo %v", err)
+	}
+	if err := validation.Struct(&customer{RFC: "SAT970701NN4", NIT: 8001972684}); err == nil {
+		t.Errorf("se esperaban errores en rfc y nit")
+	}
+}
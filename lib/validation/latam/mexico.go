@@ -0,0 +1,95 @@
+package latam
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	rfcPattern  = regexp.MustCompile(`^([A-ZÑ&]{3,4})(\d{6})([A-Z\d]{2})([A\d])$`)
+	curpPattern = regexp.MustCompile(`^[A-Z][AEIOUX][A-Z]{2}(\d{6})[HMX](AS|BC|BS|CC|CL|CM|CS|CH|DF|DG|GT|GR|HG|JC|MC|MN|MS|NT|NL|OC|PL|QT|QR|SP|SL|SR|TC|TS|TL|VZ|YN|ZS|NE)[B-DF-HJ-NP-TV-Z]{3}[A-Z\d]\d$`)
+)
+
+// rfcGeneric RFC genericos del SAT para el publico en general y los extranjeros
+var rfcGeneric = map[string]bool{"XAXX010101000": true, "XEXX010101000": true}
+
+// RFC valida el Registro Federal de Contribuyentes de México
+// 13 caracteres para las personas fisicas y 12 para las morales, con la fecha y el digito verificador
+func RFC(value string) error {
+	rfc := strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(value))
+	if rfcGeneric[rfc] {
+		return nil
+	}
+	m := rfcPattern.FindStringSubmatch(rfc)
+	if m == nil {
+		return errors.New("el RFC no tiene un formato válido")
+	}
+	if !validDate(m[2]) {
+		return errors.New("la fecha del RFC no es válida")
+	}
+	// las personas morales se completan con un espacio al inicio para calcular el digito
+	runes := []rune(rfc)
+	if len(runes) == 12 {
+		runes = append([]rune{' '}, runes...)
+	}
+	sum := 0
+	for i, r := range runes[:12] {
+		sum += runeIndex(rfcAlphabet, r) * (13 - i)
+	}
+	check := '0'
+	switch mod := sum % 11; {
+	case mod == 1:
+		check = 'A'
+	case mod > 1:
+		check = rune('0' + 11 - mod)
+	}
+	if runes[12] != check {
+		return errors.New("el dígito verificador del RFC no es válido")
+	}
+	return nil
+}
+
+// rfcAlphabet valor de cada caracter para el digito del RFC (su posicion)
+var rfcAlphabet = []rune("0123456789ABCDEFGHIJKLMN&OPQRSTUVWXYZ Ñ")
+
+// CURP valida la Clave Única de Registro de Población de México con la fecha, el estado y el digito verificador
+func CURP(value string) error {
+	curp := strings.ToUpper(strings.TrimSpace(value))
+	m := curpPattern.FindStringSubmatch(curp)
+	if m == nil {
+		return errors.New("la CURP no tiene un formato válido")
+	}
+	if !validDate(m[1]) {
+		return errors.New("la fecha de la CURP no es válida")
+	}
+	sum := 0
+	for i, r := range curp[:17] {
+		sum += runeIndex(curpAlphabet, r) * (18 - i)
+	}
+	check := (10 - sum%10) % 10
+	if int(curp[17]-'0') != check {
+		return errors.New("el dígito verificador de la CURP no es válido")
+	}
+	return nil
+}
+
+// curpAlphabet valor de cada caracter para el digito de la CURP
+var curpAlphabet = []rune("0123456789ABCDEFGHIJKLMNÑOPQRSTUVWXYZ")
+
+// runeIndex posicion del caracter en el alfabeto, el regexp ya garantiza que existe
+func runeIndex(alphabet []rune, r rune) int {
+	for i, a := range alphabet {
+		if a == r {
+			return i
+		}
+	}
+	return 0
+}
+
+// validDate indica si AAMMDD es una fecha que existe
+func validDate(s string) bool {
+	_, err := time.Parse("060102", s)
+	return err == nil
+}
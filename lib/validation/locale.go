@@ -0,0 +1,173 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+)
+
+// Reglas para los campos de ubicacion e idioma con los estandares ISO
+//
+//	Country  string `json:"country" rules:"required|country_code"`        // CO, US (country_code:alpha3 para COL, USA)
+//	Currency string `json:"currency" rules:"required|currency"`           // COP, USD
+//	Locale   string `json:"locale" rules:"language"`                      // es, es-CO, pt-BR
+//	Timezone string `json:"timezone" rules:"timezone"`                    // America/Bogota
+//	Postal   string `json:"postal_code" rules:"postal_code:country"`      // segun el pais del campo country
+//	Zip      string `json:"zip" rules:"postal_code:US"`                   // o de un pais fijo
+
+// CountryCode valida un codigo de pais ISO 3166-1 alfa-2 (CO) o alfa-3 (COL) en mayusculas
+func CountryCode(value string, alpha3 bool) error {
+	size := 2
+	if alpha3 {
+		size = 3
+	}
+	if len(value) != size || strings.ToUpper(value) != value {
+		return fmt.Errorf("el código de país debe tener %d letras mayúsculas (ISO 3166-1)", size)
+	}
+	region, err := language.ParseRegion(value)
+	if err != nil || !region.IsCountry() {
+		return fmt.Errorf("el código de país '%s' no existe", value)
+	}
+	return nil
+}
+
+// Currency valida un codigo de moneda ISO 4217 en mayusculas (USD, COP, EUR)
+func Currency(value string) error {
+	if len(value) != 3 || strings.ToUpper(value) != value {
+		return errors.New("el código de moneda debe tener 3 letras mayúsculas (ISO 4217)")
+	}
+	if _, err := currency.ParseISO(value); err != nil {
+		return fmt.Errorf("la moneda '%s' no existe", value)
+	}
+	return nil
+}
+
+// Language valida una etiqueta de idioma BCP 47 (es, es-CO, zh-Hant-TW)
+func Language(value string) error {
+	if _, err := language.Parse(value); err != nil {
+		return fmt.Errorf("el idioma '%s' no es válido (BCP 47)", value)
+	}
+	return nil
+}
+
+// timezones cache de las zonas horarias validas, LoadLocation lee el disco en cada llamada
+var timezones sync.Map
+
+// Timezone valida un nombre de zona horaria IANA (America/Bogota, UTC)
+func Timezone(value string) error {
+	if _, ok := timezones.Load(value); ok {
+		return nil
+	}
+	// Local depende del servidor, no es una zona que el cliente pueda enviar
+	if value == "" || value == "Local" {
+		return fmt.Errorf("la zona horaria '%s' no existe", value)
+	}
+	if _, err := time.LoadLocation(value); err != nil {
+		return fmt.Errorf("la zona horaria '%s' no existe", value)
+	}
+	timezones.Store(value, true)
+	return nil
+}
+
+// postalCodes formato del codigo postal por pais, los paises que no estan se validan con un formato general
+var postalCodes = map[string]*regexp.Regexp{
+	"AR": regexp.MustCompile(`^([A-Z]\d{4}[A-Z]{3}|\d{4})$`),
+	"AU": regexp.MustCompile(`^\d{4}$`),
+	"BR": regexp.MustCompile(`^\d{5}-?\d{3}$`),
+	"CA": regexp.MustCompile(`^[ABCEGHJ-NPRSTVXY]\d[ABCEGHJ-NPRSTV-Z] ?\d[ABCEGHJ-NPRSTV-Z]\d$`),
+	"CH": regexp.MustCompile(`^\d{4}$`),
+	"CL": regexp.MustCompile(`^\d{7}$`),
+	"CO": regexp.MustCompile(`^\d{6}$`),
+	"CR": regexp.MustCompile(`^\d{5}$`),
+	"DE": regexp.MustCompile(`^\d{5}$`),
+	"EC": regexp.MustCompile(`^\d{6}$`),
+	"ES": regexp.MustCompile(`^(0[1-9]|[1-4]\d|5[0-2])\d{3}$`),
+	"FR": regexp.MustCompile(`^\d{5}$`),
+	"GB": regexp.MustCompile(`^[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}$`),
+	"IN": regexp.MustCompile(`^[1-9]\d{5}$`),
+	"IT": regexp.MustCompile(`^\d{5}$`),
+	"JP": regexp.MustCompile(`^\d{3}-?\d{4}$`),
+	"MX": regexp.MustCompile(`^\d{5}$`),
+	"NL": regexp.MustCompile(`^\d{4} ?[A-Z]{2}$`),
+	"PE": regexp.MustCompile(`^\d{5}$`),
+	"PT": regexp.MustCompile(`^\d{4}-\d{3}$`),
+	"US": regexp.MustCompile(`^\d{5}(-\d{4})?$`),
+	"UY": regexp.MustCompile(`^\d{5}$`),
+	"VE": regexp.MustCompile(`^\d{4}$`),
+}
+
+// genericPostalCode formato para los paises sin uno propio
+var genericPostalCode = regexp.MustCompile(`^[A-Z0-9][A-Z0-9 -]{1,8}[A-Z0-9]$`)
+
+// PostalCode valida el codigo postal con el formato del pais (ISO 3166-1 alfa-2)
+func PostalCode(value string, country string) error {
+	pattern, ok := postalCodes[strings.ToUpper(country)]
+	if !ok {
+		pattern = genericPostalCode
+	}
+	if !pattern.MatchString(strings.ToUpper(strings.TrimSpace(value))) {
+		return fmt.Errorf("el código postal '%s' no es válido para %s", value, country)
+	}
+	return nil
+}
+
+// RegisterPostalCode agrega o reemplaza el formato del codigo postal de un pais
+// se debe llamar al iniciar la aplicacion
+func RegisterPostalCode(country string, pattern *regexp.Regexp) {
+	postalCodes[strings.ToUpper(country)] = pattern
+}
+
+func countryCodeRule(f *Field) error {
+	s, ok := f.Value.(string)
+	if !ok {
+		return errors.New("el código de país debe ser texto")
+	}
+	return CountryCode(s, len(f.Params) > 0 && f.Params[0] == "alpha3")
+}
+
+func currencyRule(f *Field) error {
+	s, ok := f.Value.(string)
+	if !ok {
+		return errors.New("la moneda debe ser texto")
+	}
+	return Currency(s)
+}
+
+func languageRule(f *Field) error {
+	s, ok := f.Value.(string)
+	if !ok {
+		return errors.New("el idioma debe ser texto")
+	}
+	return Language(s)
+}
+
+func timezoneRule(f *Field) error {
+	s, ok := f.Value.(string)
+	if !ok {
+		return errors.New("la zona horaria debe ser texto")
+	}
+	return Timezone(s)
+}
+
+// postalCodeRule el parametro es un pais (postal_code:CO) o el campo que tiene el pais (postal_code:country)
+// si el campo del pais esta vacio se usa el formato general
+func postalCodeRule(f *Field) error {
+	s, ok := f.Value.(string)
+	if !ok {
+		return errors.New("el código postal debe ser texto")
+	}
+	if len(f.Params) == 0 {
+		return PostalCode(s, "")
+	}
+	country := f.Params[0]
+	if CountryCode(country, false) != nil {
+		country, _ = lookup(f.Data, f.Params[0]).(string)
+	}
+	return PostalCode(s, country)
+}
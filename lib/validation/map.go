@@ -0,0 +1,46 @@
+package validation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// Map valida un map (esquemas dinamicos, json de terceros) con la misma sintaxis de reglas del tag rules
+// las reglas se indican por campo en notacion de puntos y * recorre los arrays
+//
+//	err := validation.Map(payload, map[string]string{
+//		"event":           "required",
+//		"data.amount":     "required|decimal:0,2",
+//		"data.items.*.id": "required",
+//	})
+func Map(data map[string]any, rules map[string]string) error {
+	return MapContext(context.Background(), data, rules)
+}
+
+// MapContext igual que Map pero las reglas reciben el contexto en Field.Context
+func MapContext(ctx context.Context, data map[string]any, rules map[string]string) error {
+	return New(data, rules, nil).WithContext(ctx).Err()
+}
+
+// JSON valida un documento json con las reglas, los numeros se conservan exactos como json.Number
+// retorna error si el documento no es un objeto
+//
+//	err := validation.JSON(body, map[string]string{"id": "required|ulid", "type": "required|slug"})
+func JSON(data []byte, rules map[string]string) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var m map[string]any
+	if err := decoder.Decode(&m); err != nil {
+		return errors.New("el documento debe ser un objeto json")
+	}
+	return Map(m, rules)
+}
+
+// Value valida un solo valor con las reglas, los errores quedan en el campo "value"
+//
+//	if err := validation.Value(os.Args[1], "required|email"); err != nil { ... }
+func Value(value any, rules string) error {
+	return Map(map[string]any{"value": value}, map[string]string{"value": rules})
+}
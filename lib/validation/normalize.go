@@ -0,0 +1,101 @@
+package validation
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Las reglas normalizadoras ademas de validar cambian el valor por su forma canonica con Field.Replace
+// (phone:CO,e164 deja el numero como +573001234567). el nuevo valor queda en los datos que ven las demas reglas,
+// en Validator.Validated y en el campo del struct si es del mismo tipo
+//
+//	validation.RegisterNormalizer("upper", func(f *validation.Field) error {
+//		s, _ := f.Value.(string)
+//		f.Replace(strings.ToUpper(s))
+//		return nil
+//	})
+
+// normalizers reglas que pueden cambiar el valor del campo
+var normalizers = map[string]bool{
+	"phone": true,
+}
+
+// RegisterNormalizer registra una regla explicita que puede cambiar el valor con Field.Replace
+// se debe llamar al iniciar la aplicacion
+func RegisterNormalizer(name string, fn RuleFunc) {
+	RegisterRule(name, fn)
+	normalizers[name] = true
+}
+
+// Replace cambia el valor del campo en los datos que se validan
+// solo tiene efecto en los structs si la regla se registro con RegisterNormalizer
+func (f *Field) Replace(value any) {
+	f.Value = value
+	if f.replace != nil {
+		f.replace(f.Name, value)
+		return
+	}
+	setPath(f.Data, f.Name, value)
+}
+
+// hasNormalizer indica si las reglas incluyen alguna regla normalizadora
+func hasNormalizer(rules []Rule) bool {
+	for _, r := range rules {
+		if normalizers[r.Name] {
+			return true
+		}
+	}
+	return false
+}
+
+// setPath cambia el valor de la ruta en notacion de puntos, los objetos intermedios deben existir
+func setPath(data map[string]any, name string, value any) {
+	var current any = data
+	for {
+		key, rest, nested := strings.Cut(name, ".")
+		if !nested {
+			switch c := current.(type) {
+			case map[string]any:
+				c[key] = value
+			case []any:
+				if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(c) {
+					c[i] = value
+				}
+			}
+			return
+		}
+		next, ok := child(current, key)
+		if !ok {
+			return
+		}
+		current, name = next, rest
+	}
+}
+
+// normalizeStruct copia al struct los valores que cambiaron las reglas normalizadoras
+// el valor solo se copia si es del mismo tipo base que el campo (string a string, string a *string)
+func normalizeStruct(rv reflect.Value, meta *structMeta, data map[string]any) {
+	for _, fr := range meta.rules {
+		if !fr.normalize {
+			continue
+		}
+		value := reflect.ValueOf(lookup(data, fr.name))
+		if !value.IsValid() {
+			continue
+		}
+		fv, ok := findField(rv, meta, fr.name)
+		if !ok {
+			continue
+		}
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+		if value.Kind() == fv.Kind() && value.Type().ConvertibleTo(fv.Type()) {
+			fv.Set(value.Convert(fv.Type()))
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Decimal valida que el numero tenga entre min y max decimales
+// se cuenta sobre la representacion exacta ("10.50" tiene 2 decimales aunque valga lo mismo que 10.5)
+func Decimal(value string, min int, max int) error {
+	_, decimals, ok := decimalParts(value)
+	if !ok {
+		return fmt.Errorf("el valor '%s' no es un número válido", value)
+	}
+	if decimals < min || decimals > max {
+		if min == max {
+			return fmt.Errorf("el número debe tener %d decimales", min)
+		}
+		return fmt.Errorf("el número debe tener entre %d y %d decimales", min, max)
+	}
+	return nil
+}
+
+// MaxDigits valida que el numero no tenga mas de max digitos contando enteros y decimales
+func MaxDigits(value string, max int) error {
+	integers, decimals, ok := decimalParts(value)
+	if !ok {
+		return fmt.Errorf("el valor '%s' no es un número válido", value)
+	}
+	if integers+decimals > max {
+		return fmt.Errorf("el número no debe tener más de %d dígitos", max)
+	}
+	return nil
+}
+
+// decimalRule decimal:2 exactamente 2 decimales, decimal:0,4 entre 0 y 4 decimales
+func decimalRule(f *Field) error {
+	if len(f.Params) == 0 {
+		return fmt.Errorf("la regla decimal requiere un parametro")
+	}
+	min, err := strconv.Atoi(f.Params[0])
+	if err != nil {
+		return fmt.Errorf("parametro inválido para la regla decimal: %s", f.Params[0])
+	}
+	max := min
+	if len(f.Params) > 1 {
+		if max, err = strconv.Atoi(f.Params[1]); err != nil {
+			return fmt.Errorf("parametro inválido para la regla decimal: %s", f.Params[1])
+		}
+	}
+	s, ok := numberString(f.Value)
+	if !ok {
+		return fmt.Errorf("el valor debe ser numérico")
+	}
+	return Decimal(s, min, max)
+}
+
+// maxDigitsRule max_digits:10
+func maxDigitsRule(f *Field) error {
+	if len(f.Params) == 0 {
+		return fmt.Errorf("la regla max_digits requiere un parametro")
+	}
+	max, err := strconv.Atoi(f.Params[0])
+	if err != nil {
+		return fmt.Errorf("parametro inválido para la regla max_digits: %s", f.Params[0])
+	}
+	s, ok := numberString(f.Value)
+	if !ok {
+		return fmt.Errorf("el valor debe ser numérico")
+	}
+	return MaxDigits(s, max)
+}
+
+// numberString retorna la representacion exacta del numero
+// json.Number y string se usan tal cual, los float64 pierden los ceros a la derecha
+// por eso para dinero se recomienda decodificar los numeros como json.Number
+func numberString(value any) (string, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		return v.String(), true
+	case string:
+		return v, true
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	}
+	if n, ok := toFloat64(value); ok {
+		return strconv.FormatFloat(n, 'f', -1, 64), true
+	}
+	return "", false
+}
+
+// maxDecimalExponent exponente mas grande que acepta la notacion cientifica, float64 no pasa de 1e308
+// y con un exponente sin limite un valor como 1e999999999 haria contar digitos que nadie envia
+const maxDecimalExponent = 1000
+
+// decimalParts cuenta los digitos enteros (sin ceros a la izquierda) y decimales del numero
+// acepta notacion cientifica (1.5e3) como la que puede venir en un json.Number, los digitos que agrega
+// el exponente se cuentan sin armar el numero
+func decimalParts(s string) (integers int, decimals int, ok bool) {
+	s = strings.TrimLeft(strings.TrimSpace(s), "+-")
+	mantissa, expStr, hasExp := strings.Cut(strings.ToLower(s), "e")
+	exp := 0
+	if hasExp {
+		var err error
+		if exp, err = strconv.Atoi(expStr); err != nil || exp > maxDecimalExponent || exp < -maxDecimalExponent {
+			return 0, 0, false
+		}
+	}
+
+	intPart, fracPart, _ := strings.Cut(mantissa, ".")
+	if intPart == "" && fracPart == "" || !isDigits(intPart) || !isDigits(fracPart) {
+		return 0, 0, false
+	}
+
+	// mover el punto segun el exponente
+	digits := intPart + fracPart
+	point := len(intPart) + exp
+	switch {
+	case point >= len(digits):
+		// todos los digitos quedan enteros seguidos de point-len(digits) ceros
+		if significant := len(strings.TrimLeft(digits, "0")); significant > 0 {
+			integers = significant + point - len(digits)
+		}
+	case point > 0:
+		integers = len(strings.TrimLeft(digits[:point], "0"))
+		decimals = len(digits) - point
+	default:
+		// todos los digitos quedan decimales despues de -point ceros
+		decimals = len(digits) - point
+	}
+	return integers, decimals, true
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
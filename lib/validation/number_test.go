@@ -0,0 +1,50 @@
+package validation
+
+import "testing"
+
+func TestDecimalParts(t *testing.T) {
+	cases := []struct {
+		value    string
+		integers int
+		decimals int
+		ok       bool
+	}{
+		{"10.50", 2, 2, true},
+		{"-0.001", 0, 3, true},
+		{"007", 1, 0, true},
+		{"1.5e3", 4, 0, true},
+		{"1.2345e2", 3, 2, true},
+		{"12.5e-1", 1, 2, true},
+		{"1.5e-3", 0, 4, true},
+		{"0e5", 0, 0, true},
+		{"1e1000", 1001, 0, true},
+		{"1e999999999", 0, 0, false},
+		{"1e-999999999", 0, 0, false},
+		{"1e99999999999999999999", 0, 0, false},
+		{"abc", 0, 0, false},
+		{".", 0, 0, false},
+	}
+	for _, c := range cases {
+		integers, decimals, ok := decimalParts(c.value)
+		if integers != c.integers || decimals != c.decimals || ok != c.ok {
+			t.Errorf("decimalParts(%s) = %d, %d, %v, se esperaba %d, %d, %v", c.value, integers, decimals, ok, c.integers, c.decimals, c.ok)
+		}
+	}
+}
+
+func TestMaxDigitsHugeExponent(t *testing.T) {
+	allocs := testing.AllocsPerRun(10, func() {
+		if MaxDigits("1e999999999", 10) == nil {
+			t.Fatal("un exponente enorme no debe ser un número válido")
+		}
+	})
+	if allocs > 5 {
+		t.Errorf("MaxDigits con un exponente enorme hizo %.0f allocs", allocs)
+	}
+	if err := MaxDigits("1e20", 10); err == nil {
+		t.Errorf("1e20 tiene 21 dígitos, se esperaba un error")
+	}
+	if err := Decimal("1.5e-1", 2, 2); err != nil {
+		t.Errorf("1.5e-1 tiene 2 decimales: %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+)
+
+// RuleObject regla con configuracion y estado propio (como los rule objects de laravel)
+// para reglas que no caben en una funcion: listas de valores, dependencias (repositorios, clientes http),
+// o mensajes que dependen de lo que se valido. en el mensaje :attribute se reemplaza por el nombre del campo
+//
+//	type MinWords struct{ Min int }
+//
+//	func (r *MinWords) Passes(value any) bool {
+//		s, _ := value.(string)
+//		return len(strings.Fields(s)) >= r.Min
+//	}
+//
+//	func (r *MinWords) Message() string { return fmt.Sprintf("el texto debe tener al menos %d palabras", r.Min) }
+type RuleObject interface {
+	Passes(value any) bool
+	Message() string
+}
+
+// DataAwareRule lo implementa la regla que necesita todos los datos que se validan
+// (comparar con otro campo, reglas que dependen de un tipo enviado en el mismo payload)
+// SetData se llama antes de Passes con los datos en el mismo formato que Field.Data
+type DataAwareRule interface {
+	SetData(data map[string]any)
+}
+
+// FieldAwareRule lo implementa la regla que necesita saber que campo valida (para el mensaje o para buscar en los datos)
+type FieldAwareRule interface {
+	SetField(name string)
+}
+
+// ImplicitRule lo implementa la regla que tambien se ejecuta cuando el campo no se envio o esta vacio
+// sin ella Passes solo se llama si hay un valor, igual que las reglas registradas con RegisterRule
+type ImplicitRule interface {
+	Implicit() bool
+}
+
+// RuleFactory crea la regla con los parametros del tag (min_words:5 -> ["5"])
+// se llama en cada validacion, asi el estado de una validacion no se mezcla con el de otra
+type RuleFactory func(params []string) (RuleObject, error)
+
+// RegisterRuleObject registra una regla que se crea con la factory en cada validacion
+//
+//	validation.RegisterRuleObject("min_words", func(params []string) (validation.RuleObject, error) {
+//		if len(params) == 0 {
+//			return nil, errors.New("la regla min_words requiere un parametro")
+//		}
+//		n, err := strconv.Atoi(params[0])
+//		return &MinWords{Min: n}, err
+//	})
+//
+//	Bio string `json:"bio" rules:"min_words:5"`
+func RegisterRuleObject(name string, factory RuleFactory) {
+	// el motor siempre la ejecuta, checkObject decide segun ImplicitRule
+	RegisterImplicitRule(name, ObjectRule(factory))
+}
+
+// ObjectRule adapta una RuleFactory a una RuleFunc
+func ObjectRule(factory RuleFactory) RuleFunc {
+	return func(f *Field) error {
+		rule, err := factory(f.Params)
+		if err != nil {
+			return err
+		}
+		return checkObject(rule, f)
+	}
+}
+
+// Object adapta una regla ya configurada a una RuleFunc
+// si la regla es implicita registrela con RegisterImplicitRule
+// la misma instancia se reutiliza en todas las validaciones, si guarda estado en SetData use RegisterRuleObject
+//
+//	validation.RegisterRule("not_reserved", validation.Object(&NotIn{Values: reserved}))
+func Object(rule RuleObject) RuleFunc {
+	return func(f *Field) error {
+		return checkObject(rule, f)
+	}
+}
+
+// checkObject entrega los datos y el campo a la regla y la ejecuta
+func checkObject(rule RuleObject, f *Field) error {
+	if isEmpty(f.Value) {
+		if r, ok := rule.(ImplicitRule); !ok || !r.Implicit() {
+			return nil
+		}
+	}
+	if r, ok := rule.(FieldAwareRule); ok {
+		r.SetField(f.Name)
+	}
+	if r, ok := rule.(DataAwareRule); ok {
+		r.SetData(f.Data)
+	}
+	if rule.Passes(f.Value) {
+		return nil
+	}
+	message := rule.Message()
+	if message == "" {
+		message = "el valor no es válido"
+	}
+	return errors.New(strings.ReplaceAll(message, ":attribute", f.Name))
+}
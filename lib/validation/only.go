@@ -0,0 +1,82 @@
+package validation
+
+import (
+	"context"
+	"strings"
+)
+
+// Validacion parcial: solo se ejecutan las reglas de los campos indicados (validacion en vivo de un formulario,
+// el usuario va llenando el campo email y el frontend pregunta solo por email)
+//
+//	ctx = validation.WithOnly(ctx, "email", "address")
+//	err := validation.StructContext(ctx, &user)
+//
+// un campo incluye a sus campos anidados (address valida address.city y address.zip)
+// y en los slices el indice incluye todo el elemento (2 valida todos los campos del elemento 2, 2.sku solo sku)
+
+// onlyKey llave del contexto con los campos que se validan
+type onlyKey struct{}
+
+// WithOnly limita la validacion a los campos indicados, sin campos se validan todos
+func WithOnly(ctx context.Context, fields ...string) context.Context {
+	if len(fields) == 0 {
+		return context.WithValue(ctx, onlyKey{}, []string(nil))
+	}
+	return context.WithValue(ctx, onlyKey{}, fields)
+}
+
+// Only retorna los campos a los que se limito la validacion, nil si se validan todos
+func Only(ctx context.Context) []string {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(onlyKey{}).([]string)
+	return fields
+}
+
+// selected indica si el campo se debe validar, only nil valida todos
+func selected(only []string, name string) bool {
+	if only == nil {
+		return true
+	}
+	for _, f := range only {
+		if name == f || strings.HasPrefix(name, f+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// onlyElement limita el contexto a los campos del elemento index de un slice
+// retorna false si no se pidio ningun campo del elemento
+func onlyElement(ctx context.Context, only []string, index string) (context.Context, bool) {
+	if only == nil {
+		return ctx, true
+	}
+	var fields []string
+	for _, f := range only {
+		if f == index {
+			return WithOnly(ctx), true
+		}
+		if rest, ok := strings.CutPrefix(f, index+"."); ok {
+			fields = append(fields, rest)
+		}
+	}
+	if fields == nil {
+		return ctx, false
+	}
+	return WithOnly(ctx, fields...), true
+}
+
+// filterSelected quita los errores de los campos que no se pidieron
+func filterSelected(errs ValidationErrors, only []string) ValidationErrors {
+	if only == nil {
+		return errs
+	}
+	for field := range errs {
+		if !selected(only, field) {
+			delete(errs, field)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,97 @@
+package validation
+
+import (
+	"context"
+	"sort"
+)
+
+// Reglas por contexto: reemplazan las del tag rules de algunos campos sin crear otro tipo de struct
+// (el codigo postal y el nit cambian segun el pais del tenant o el idioma del cliente)
+//
+//	ctx = validation.WithRules(ctx, map[string]string{
+//		"postal_code": "required|postal_code:MX",
+//		"tax_id":      "required|rfc",
+//		"phone":       "", // sin reglas
+//	})
+//	err := validation.StructContext(ctx, &customer)
+//
+// los campos se nombran en notacion de puntos (address.zip), un campo sin tag rules tambien se puede agregar
+
+// rulesKey llave del contexto con las reglas que reemplazan a las del tag
+type rulesKey struct{}
+
+// WithRules reemplaza las reglas de los campos indicados, "" deja el campo sin reglas
+func WithRules(ctx context.Context, rules map[string]string) context.Context {
+	if len(rules) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, rulesKey{}, rules)
+}
+
+// RulesOf retorna las reglas que reemplazan a las del tag, nil si no hay
+func RulesOf(ctx context.Context) map[string]string {
+	if ctx == nil {
+		return nil
+	}
+	rules, _ := ctx.Value(rulesKey{}).(map[string]string)
+	return rules
+}
+
+// overrideMeta retorna la metadata con las reglas del contexto, sin reglas en el contexto retorna la misma
+func overrideMeta(ctx context.Context, meta *structMeta) *structMeta {
+	overrides := RulesOf(ctx)
+	if overrides == nil {
+		return meta
+	}
+	out := *meta
+	out.rules = make([]fieldRules, 0, len(meta.rules)+len(overrides))
+	used := make(map[string]bool, len(overrides))
+	for _, fr := range meta.rules {
+		tag, ok := overrides[fr.name]
+		if !ok {
+			out.rules = append(out.rules, fr)
+			continue
+		}
+		used[fr.name] = true
+		if rules := ParseRules(tag); len(rules) > 0 {
+			out.rules = append(out.rules, overrideRules(fr.name, rules, fr.sensitive))
+		}
+	}
+
+	// los campos que no tenian reglas se agregan en orden para que los errores sean estables
+	added := make([]string, 0, len(overrides)-len(used))
+	for name := range overrides {
+		if !used[name] {
+			added = append(added, name)
+		}
+	}
+	sort.Strings(added)
+	for _, name := range added {
+		if rules := ParseRules(overrides[name]); len(rules) > 0 {
+			out.rules = append(out.rules, overrideRules(name, rules, isSensitiveName(meta.sensitive, name)))
+		}
+	}
+	return &out
+}
+
+// overrideRules crea las reglas del campo con las banderas que dependen de las reglas
+func overrideRules(name string, rules []Rule, sensitive bool) fieldRules {
+	return fieldRules{
+		name:      name,
+		rules:     rules,
+		sometimes: hasSometimes(rules),
+		sensitive: sensitive || hasSensitiveRule(rules),
+		exclude:   hasExclude(rules),
+		normalize: hasNormalizer(rules),
+	}
+}
+
+// isSensitiveName indica si el campo esta entre los sensibles del struct
+func isSensitiveName(sensitive []string, name string) bool {
+	for _, s := range sensitive {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
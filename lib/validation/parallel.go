@@ -0,0 +1,100 @@
+package validation
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// StructParallel valida el struct igual que StructContext pero cada campo se valida en su propia goroutine
+// usando un pool de maximo workers goroutines.
+// sirve para los FormRequest con reglas costosas (consultas a la base de datos, apis remotas)
+// los campos son independientes entre si y las reglas de un mismo campo se siguen ejecutando en orden
+// si una regla retorna un error Hard se cancela el contexto de los demas campos y se retorna ese error
+func StructParallel(ctx context.Context, v any, workers int) error {
+	rv, meta, err := structValue(v)
+	if err != nil {
+		return err
+	}
+	meta = overrideMeta(ctx, meta)
+	if len(meta.rules)+len(meta.warnings) == 0 {
+		return nil
+	}
+	data := structToMap(rv, meta)
+	present := presenceFunc(v, data)
+	if err := collectWarnings(ctx, data, meta.warnings, present); err != nil {
+		return err
+	}
+	err = validateParallel(ctx, data, meta.rules, present, workers)
+	normalizeStruct(rv, meta, data)
+	excludeStruct(rv, meta, data)
+	return err
+}
+
+// validateParallel ejecuta las reglas de cada campo en el pool de goroutines
+func validateParallel(ctx context.Context, data map[string]any, fields []fieldRules, present func(string) bool, workers int) error {
+	if workers < 1 {
+		workers = 1
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(workers)
+
+	var mu sync.Mutex
+	var errs CodedErrors
+	// los valores de Replace se escriben al terminar, las demas goroutines estan leyendo data
+	replaced := map[string]any{}
+	replace := func(name string, value any) {
+		mu.Lock()
+		replaced[name] = value
+		mu.Unlock()
+	}
+
+	only := Only(ctx)
+	timer := fieldTimer(ctx)
+	for _, fr := range fields {
+		// si algun campo fallo con un error grave no se lanzan mas
+		if gctx.Err() != nil {
+			break
+		}
+		if !selected(only, fr.name) {
+			continue
+		}
+		if fr.sometimes && !present(fr.name) {
+			continue
+		}
+		if fr.exclude && isExcluded(data, fr.rules) {
+			continue
+		}
+		g.Go(func() error {
+			f := &Field{Name: fr.name, Value: lookup(data, fr.name), Data: data, Context: gctx, sensitive: fr.sensitive, present: present, replace: replace}
+			fieldErrs, err := applyTimed(nil, f, fr.rules, timer)
+			if err != nil {
+				return err
+			}
+			if len(fieldErrs) > 0 {
+				mu.Lock()
+				for field, msgs := range fieldErrs {
+					for i, msg := range msgs {
+						errs.Add(field, codeAt(f.codes, field, i), msg)
+					}
+				}
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	for name, value := range replaced {
+		setPath(data, name, value)
+	}
+	// el contexto del request se pudo cancelar (el cliente cerro la conexion)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return errs.Err()
+}
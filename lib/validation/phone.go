@@ -0,0 +1,194 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Regla para numeros de telefono con los metadatos de cada pais (como libphonenumber pero solo lo necesario):
+// el indicativo, el prefijo de marcacion nacional y el formato del numero nacional
+//
+//	Phone  string `json:"phone" rules:"required|phone:CO"`          // 300 123 4567, +57 300 123 4567
+//	Mobile string `json:"mobile" rules:"phone:MX,US,e164"`          // se guarda como +525512345678
+//	Tel    string `json:"tel" rules:"phone:country"`                // segun el pais del campo country
+//	Intl   string `json:"intl" rules:"phone"`                       // cualquier pais, debe empezar con +
+//
+// los numeros sin + o 00 se interpretan como nacionales de los paises de la regla en orden
+// con el parametro e164 el valor validado se reemplaza por el numero en formato E.164 (ver Field.Replace)
+
+// PhoneRegion metadatos de numeracion de un pais
+type PhoneRegion struct {
+	Code    string         // indicativo del pais sin + (57)
+	Trunk   string         // prefijo para marcar dentro del pais que no va en el formato internacional (0)
+	Pattern *regexp.Regexp // formato del numero nacional sin el prefijo
+}
+
+// phoneRegions metadatos por pais (ISO 3166-1 alfa-2)
+// los paises del plan de numeracion norteamericano (indicativo 1) comparten el formato
+var phoneRegions = map[string]PhoneRegion{
+	"AR": {Code: "54", Trunk: "0", Pattern: regexp.MustCompile(`^9?[1-9]\d{9}$`)},
+	"BO": {Code: "591", Trunk: "0", Pattern: regexp.MustCompile(`^[2-7]\d{7}$`)},
+	"BR": {Code: "55", Trunk: "0", Pattern: regexp.MustCompile(`^[1-9]{2}(9\d{8}|[2-5]\d{7})$`)},
+	"CA": {Code: "1", Trunk: "1", Pattern: regexp.MustCompile(`^[2-9]\d{2}[2-9]\d{6}$`)},
+	"CL": {Code: "56", Pattern: regexp.MustCompile(`^[2-9]\d{8}$`)},
+	"CO": {Code: "57", Pattern: regexp.MustCompile(`^(3\d{9}|60[1-8]\d{7})$`)},
+	"CR": {Code: "506", Pattern: regexp.MustCompile(`^[2-8]\d{7}$`)},
+	"CU": {Code: "53", Trunk: "0", Pattern: regexp.MustCompile(`^[2-7]\d{5,7}$`)},
+	"DE": {Code: "49", Trunk: "0", Pattern: regexp.MustCompile(`^[1-9]\d{5,13}$`)},
+	"DO": {Code: "1", Trunk: "1", Pattern: regexp.MustCompile(`^8[024]9[2-9]\d{6}$`)},
+	"EC": {Code: "593", Trunk: "0", Pattern: regexp.MustCompile(`^(9\d{8}|[2-7]\d{7})$`)},
+	"ES": {Code: "34", Pattern: regexp.MustCompile(`^[6-9]\d{8}$`)},
+	"FR": {Code: "33", Trunk: "0", Pattern: regexp.MustCompile(`^[1-9]\d{8}$`)},
+	"GB": {Code: "44", Trunk: "0", Pattern: regexp.MustCompile(`^[1-9]\d{8,9}$`)},
+	"GT": {Code: "502", Pattern: regexp.MustCompile(`^[2-7]\d{7}$`)},
+	"HN": {Code: "504", Pattern: regexp.MustCompile(`^[2389]\d{7}$`)},
+	"IT": {Code: "39", Pattern: regexp.MustCompile(`^(0\d{5,10}|3\d{8,9})$`)},
+	"MX": {Code: "52", Pattern: regexp.MustCompile(`^[1-9]\d{9}$`)},
+	"NI": {Code: "505", Pattern: regexp.MustCompile(`^[2578]\d{7}$`)},
+	"PA": {Code: "507", Pattern: regexp.MustCompile(`^(6\d{7}|[2-9]\d{6})$`)},
+	"PE": {Code: "51", Trunk: "0", Pattern: regexp.MustCompile(`^(9\d{8}|[1-8]\d{7})$`)},
+	"PR": {Code: "1", Trunk: "1", Pattern: regexp.MustCompile(`^(787|939)[2-9]\d{6}$`)},
+	"PT": {Code: "351", Pattern: regexp.MustCompile(`^[29]\d{8}$`)},
+	"PY": {Code: "595", Trunk: "0", Pattern: regexp.MustCompile(`^(9\d{8}|[2-8]\d{6,8})$`)},
+	"SV": {Code: "503", Pattern: regexp.MustCompile(`^[267]\d{7}$`)},
+	"US": {Code: "1", Trunk: "1", Pattern: regexp.MustCompile(`^[2-9]\d{2}[2-9]\d{6}$`)},
+	"UY": {Code: "598", Trunk: "0", Pattern: regexp.MustCompile(`^[249]\d{7}$`)},
+	"VE": {Code: "58", Trunk: "0", Pattern: regexp.MustCompile(`^[24]\d{9}$`)},
+}
+
+// RegisterPhoneRegion agrega o reemplaza los metadatos de numeracion de un pais
+// se debe llamar al iniciar la aplicacion
+//
+//	validation.RegisterPhoneRegion("JP", validation.PhoneRegion{Code: "81", Trunk: "0", Pattern: regexp.MustCompile(`^[1-9]\d{8,9}$`)})
+func RegisterPhoneRegion(country string, region PhoneRegion) {
+	phoneRegions[strings.ToUpper(country)] = region
+}
+
+// Phone valida el numero de telefono y lo retorna en formato E.164 (+573001234567)
+// countries son los paises permitidos, vacio permite cualquier pais pero el numero debe ser internacional
+// se aceptan espacios, guiones, puntos, barras y parentesis como separadores
+func Phone(value string, countries ...string) (string, error) {
+	digits, international, err := phoneDigits(value)
+	if err != nil {
+		return "", err
+	}
+	if len(countries) == 0 && !international {
+		return "", errors.New("el teléfono debe incluir el indicativo del país (+57)")
+	}
+	upper := make([]string, len(countries))
+	for i, c := range countries {
+		upper[i] = strings.ToUpper(c)
+	}
+	countries = upper
+
+	if international {
+		// el indicativo tiene de 1 a 3 digitos y ningun indicativo es prefijo de otro
+		for size := 1; size <= 3 && size < len(digits); size++ {
+			code, national := digits[:size], digits[size:]
+			for country, region := range phoneRegions {
+				if region.Code != code || !phoneAllowed(country, countries) {
+					continue
+				}
+				// los celulares de mexico se marcaban con un 1 despues del indicativo (+52 1 55...)
+				if country == "MX" && len(national) == 11 && national[0] == '1' {
+					national = national[1:]
+				}
+				if region.Pattern.MatchString(national) {
+					return "+" + code + national, nil
+				}
+			}
+		}
+		return "", fmt.Errorf("el teléfono '%s' no es válido", value)
+	}
+
+	for _, country := range countries {
+		region, ok := phoneRegions[country]
+		if !ok {
+			continue
+		}
+		national := digits
+		if region.Trunk != "" && strings.HasPrefix(national, region.Trunk) && !region.Pattern.MatchString(national) {
+			national = national[len(region.Trunk):]
+		}
+		if region.Pattern.MatchString(national) {
+			return "+" + region.Code + national, nil
+		}
+	}
+	return "", fmt.Errorf("el teléfono '%s' no es válido para %s", value, strings.Join(countries, ", "))
+}
+
+// phoneDigits quita los separadores y el prefijo internacional (+ o 00)
+func phoneDigits(value string) (string, bool, error) {
+	value = strings.TrimSpace(value)
+	international := false
+	if strings.HasPrefix(value, "+") {
+		international = true
+		value = value[1:]
+	}
+	var b strings.Builder
+	for _, r := range value {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ', r == '-', r == '.', r == '/', r == '(', r == ')':
+		default:
+			return "", false, errors.New("el teléfono solo puede tener números y separadores")
+		}
+	}
+	digits := b.String()
+	if !international && strings.HasPrefix(digits, "00") {
+		international = true
+		digits = digits[2:]
+	}
+	// E.164 permite maximo 15 digitos con el indicativo
+	if len(digits) < 4 || len(digits) > 15 {
+		return "", false, errors.New("el teléfono debe tener entre 4 y 15 dígitos")
+	}
+	return digits, international, nil
+}
+
+// phoneAllowed indica si el pais esta en la lista, una lista vacia permite todos
+func phoneAllowed(country string, countries []string) bool {
+	if len(countries) == 0 {
+		return true
+	}
+	for _, c := range countries {
+		if c == country {
+			return true
+		}
+	}
+	return false
+}
+
+// phoneRule los parametros son paises (phone:MX,US) o el campo que tiene el pais (phone:country)
+// con e164 el valor se reemplaza por el numero normalizado
+func phoneRule(f *Field) error {
+	s, ok := f.Value.(string)
+	if !ok {
+		return errors.New("el teléfono debe ser texto")
+	}
+	normalize := false
+	countries := make([]string, 0, len(f.Params))
+	for _, p := range f.Params {
+		switch {
+		case p == "e164":
+			normalize = true
+		case CountryCode(strings.ToUpper(p), false) == nil:
+			countries = append(countries, p)
+		default:
+			if country, _ := lookup(f.Data, p).(string); country != "" {
+				countries = append(countries, country)
+			}
+		}
+	}
+	e164, err := Phone(s, countries...)
+	if err != nil {
+		return err
+	}
+	if normalize {
+		f.Replace(e164)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Los parametros de las reglas pueden tener marcadores {nombre.campo} que se reemplazan en cada validacion
+// con valores del contexto, asi las reglas que excluyen al usuario autenticado no necesitan codigo propio
+//
+//	Email string `json:"email" rules:"required|email|unique:users,email,except:{auth.id}"`
+//	Slug  string `json:"slug" rules:"unique:projects,slug,tenant_id:{tenant.id}"`
+//
+//	ctx = validation.WithPlaceholder(ctx, "auth", map[string]any{"id": 42})
+//
+// (unique y exists las registra el paquete internal/request sobre la base de datos)
+// el valor puede ser un map o un struct (se busca por el nombre json), si no existe el marcador queda vacio:
+// un usuario no autenticado no excluye a nadie
+
+// placeholdersKey llave del contexto con los valores de los marcadores
+type placeholdersKey struct{}
+
+// WithPlaceholder agrega un valor para los marcadores {name...} de los parametros de las reglas
+func WithPlaceholder(ctx context.Context, name string, value any) context.Context {
+	values, _ := ctx.Value(placeholdersKey{}).(map[string]any)
+	next := make(map[string]any, len(values)+1)
+	for k, v := range values {
+		next[k] = v
+	}
+	next[name] = value
+	return context.WithValue(ctx, placeholdersKey{}, next)
+}
+
+// Placeholder retorna el valor del marcador (auth.id) y si existe
+func Placeholder(ctx context.Context, name string) (any, bool) {
+	values, _ := ctx.Value(placeholdersKey{}).(map[string]any)
+	root, path, nested := strings.Cut(name, ".")
+	value, ok := values[root]
+	if !ok || !nested {
+		return value, ok
+	}
+	value = elementField(value, path)
+	return value, value != nil
+}
+
+// resolveParams reemplaza los marcadores de los parametros
+// sin marcadores retorna el mismo slice para no reservar memoria en cada regla
+func resolveParams(ctx context.Context, params []string) []string {
+	found := false
+	for _, p := range params {
+		if strings.Contains(p, "{") {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return params
+	}
+	out := make([]string, len(params))
+	for i, p := range params {
+		out[i] = replacePlaceholders(ctx, p)
+	}
+	return out
+}
+
+// replacePlaceholders reemplaza cada {nombre} del parametro por su valor
+func replacePlaceholders(ctx context.Context, param string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(param, "{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(param[start:], "}")
+		if end < 0 {
+			break
+		}
+		b.WriteString(param[:start])
+		if value, ok := Placeholder(ctx, param[start+1:start+end]); ok && value != nil {
+			b.WriteString(fmt.Sprint(value))
+		}
+		param = param[start+end+1:]
+	}
+	b.WriteString(param)
+	return b.String()
+}
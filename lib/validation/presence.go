@@ -0,0 +1,105 @@
+package validation
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Presence lo implementa el struct que sabe que campos envio el cliente (request.Request)
+// la regla sometimes la usa para no validar los campos que no vinieron en un PATCH
+type Presence interface {
+	Provided(field string) bool
+}
+
+// OptionalValuer lo implementan los tipos que distinguen entre no enviado y enviado (request.Optional)
+// las reglas se ejecutan sobre value y si provided es false el campo se trata como ausente
+type OptionalValuer interface {
+	ValidationValue() (value any, provided bool)
+}
+
+var optionalType = reflect.TypeOf((*OptionalValuer)(nil)).Elem()
+
+// sometimesRule no valida nada, validate salta las reglas del campo si no se envio
+//
+//	Name string `json:"name" rules:"sometimes|required|min:3"`
+func sometimesRule(f *Field) error {
+	return nil
+}
+
+// hasSometimes indica si las reglas incluyen sometimes
+func hasSometimes(rules []Rule) bool {
+	for _, r := range rules {
+		if r.Name == "sometimes" {
+			return true
+		}
+	}
+	return false
+}
+
+// presenceFunc retorna la funcion que indica si un campo se envio
+// si el struct implementa Presence se le pregunta, si no se mira si la llave existe en los datos
+func presenceFunc(v any, data map[string]any) func(string) bool {
+	if p, ok := v.(Presence); ok {
+		return func(field string) bool {
+			// un Optional sin valor no se envio aunque el struct diga otra cosa
+			return p.Provided(field) && keyExists(data, field)
+		}
+	}
+	return func(field string) bool {
+		return keyExists(data, field)
+	}
+}
+
+// keyExists indica si la llave existe en los datos usando notacion de puntos
+func keyExists(data map[string]any, name string) bool {
+	var current any = data
+	for {
+		key, rest, nested := strings.Cut(name, ".")
+		value, ok := child(current, key)
+		if !ok {
+			return false
+		}
+		if !nested {
+			return true
+		}
+		current, name = value, rest
+	}
+}
+
+// child retorna el valor de la llave en un objeto o del indice en un array ([]any de un json)
+func child(container any, key string) (any, bool) {
+	switch c := container.(type) {
+	case map[string]any:
+		value, ok := c[key]
+		return value, ok
+	case []any:
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i >= len(c) {
+			return nil, false
+		}
+		return c[i], true
+	case nil:
+		return nil, false
+	}
+	// maps y slices con otros tipos (map[string]string, []map[string]any) que se arman en go y no vienen de un json
+	rv := reflect.ValueOf(container)
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		v := rv.MapIndex(reflect.ValueOf(key).Convert(rv.Type().Key()))
+		if !v.IsValid() {
+			return nil, false
+		}
+		return v.Interface(), true
+	case reflect.Slice, reflect.Array:
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i >= rv.Len() {
+			return nil, false
+		}
+		return rv.Index(i).Interface(), true
+	}
+	return nil, false
+}
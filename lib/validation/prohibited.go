@@ -0,0 +1,107 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Las reglas prohibited fallan cuando el cliente envia un campo que no debe enviar
+// en lugar de ignorarlo en silencio (para quitarlo sin error use exclude_if)
+//
+//	ID       string  `json:"id" rules:"prohibited"`
+//	Discount float64 `json:"discount" rules:"prohibited_unless:is_admin,true"`
+//	Reason   string  `json:"reason" rules:"prohibited_if:status,active,pending"`
+//	Password string  `json:"password" rules:"missing_with:token,code"`
+//	Lng      *float64 `json:"lng" rules:"required_with:lat"`
+//
+// required_with es la contraparte: el campo es obligatorio si otro se envio con valor.
+// prohibited acepta el campo vacio (null o ""), missing_with no acepta ni la llave
+// en los structs el campo cuenta como enviado segun Presence (request.Request), si el struct no la implementa
+// use un puntero o request.Optional, un int en cero no esta vacio y haria fallar prohibited
+
+// Provided indica si el cliente envio el campo, con la misma logica de la regla sometimes
+// sirve para las reglas propias que dependen de la presencia de otro campo
+func (f *Field) Provided(name string) bool {
+	if f.present != nil {
+		return f.present(name)
+	}
+	return keyExists(f.Data, name)
+}
+
+// prohibitedRule el campo no se puede enviar con un valor
+func prohibitedRule(f *Field) error {
+	if f.Provided(f.Name) && !isEmpty(f.Value) {
+		return errors.New("el campo no está permitido")
+	}
+	return nil
+}
+
+// prohibitedIfRule el campo no se puede enviar si el otro campo tiene alguno de los valores
+//
+//	prohibited_if:status,active,pending
+func prohibitedIfRule(f *Field) error {
+	if len(f.Params) < 2 {
+		return errors.New("la regla prohibited_if requiere el campo y al menos un valor")
+	}
+	if !matchesAny(lookup(f.Data, f.Params[0]), f.Params[1:]) {
+		return nil
+	}
+	if f.Provided(f.Name) && !isEmpty(f.Value) {
+		return fmt.Errorf("el campo no está permitido cuando %s es %s", f.Params[0], strings.Join(f.Params[1:], ", "))
+	}
+	return nil
+}
+
+// prohibitedUnlessRule el campo solo se puede enviar si el otro campo tiene alguno de los valores
+//
+//	prohibited_unless:is_admin,true
+func prohibitedUnlessRule(f *Field) error {
+	if len(f.Params) < 2 {
+		return errors.New("la regla prohibited_unless requiere el campo y al menos un valor")
+	}
+	if matchesAny(lookup(f.Data, f.Params[0]), f.Params[1:]) {
+		return nil
+	}
+	if f.Provided(f.Name) && !isEmpty(f.Value) {
+		return fmt.Errorf("el campo solo está permitido cuando %s es %s", f.Params[0], strings.Join(f.Params[1:], ", "))
+	}
+	return nil
+}
+
+// requiredWithRule el campo es obligatorio si se envio alguno de los otros campos con un valor
+// (lat, lng y radius van juntos o ninguno), es implicita: se ejecuta aunque el campo no venga
+//
+//	required_with:lng,radius
+func requiredWithRule(f *Field) error {
+	if len(f.Params) == 0 {
+		return errors.New("la regla required_with requiere al menos un campo")
+	}
+	if !isEmpty(f.Value) {
+		return nil
+	}
+	for _, other := range f.Params {
+		if !isEmpty(lookup(f.Data, other)) {
+			return fmt.Errorf("el campo es obligatorio cuando se envía %s", other)
+		}
+	}
+	return nil
+}
+
+// missingWithRule la llave no puede venir si se envio alguno de los otros campos
+//
+//	missing_with:token,code
+func missingWithRule(f *Field) error {
+	if len(f.Params) == 0 {
+		return errors.New("la regla missing_with requiere al menos un campo")
+	}
+	if !f.Provided(f.Name) {
+		return nil
+	}
+	for _, other := range f.Params {
+		if f.Provided(other) {
+			return fmt.Errorf("el campo no se puede enviar junto con %s", other)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,389 @@
+package validation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Rule es una regla de validación ya interpretada
+// "min:3" -> Rule{Name: "min", Params: ["3"]}
+type Rule struct {
+	Name   string
+	Params []string
+}
+
+// Field es lo que recibe cada regla al momento de ejecutarse
+type Field struct {
+	Name   string         // nombre del campo en notacion de puntos (address.city)
+	Value  any            // valor del campo
+	Params []string       // parametros de la regla
+	Data   map[string]any // todos los datos que se estan validando, para reglas que comparan con otros campos
+
+	// Context contexto de la validacion, las reglas que consultan sistemas externos (base de datos, apis)
+	// deben respetarlo para dejar de trabajar cuando se cancela
+	Context context.Context
+
+	sensitive bool              // el valor se quita de los mensajes de error
+	messages  map[string]string // mensajes personalizados del Validator
+	pattern   string            // nombre con * del que salio el campo
+	present   func(string) bool // indica si el cliente envio un campo, ver Provided
+	replace   func(string, any) // guarda el valor de Replace, nil lo escribe directo en Data
+
+	codes map[string][]string // codigos de los errores de cada campo en el orden de los mensajes
+}
+
+// RuleFunc es la firma de las funciones que implementan una regla
+type RuleFunc func(f *Field) error
+
+// HardError error grave de una regla, detiene toda la validacion y se retorna tal cual
+// se usa cuando la regla no pudo validar (ej: la base de datos no responde) y no cuando el valor es invalido
+type HardError struct {
+	Err error
+}
+
+func (e *HardError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *HardError) Unwrap() error {
+	return e.Err
+}
+
+// Hard marca el error de una regla como grave
+//
+//	if err := db.QueryRowContext(f.Context, query, f.Value).Scan(&count); err != nil {
+//		return validation.Hard(err)
+//	}
+func Hard(err error) error {
+	return &HardError{Err: err}
+}
+
+// ruleFuncs registro de las reglas disponibles
+var ruleFuncs = map[string]RuleFunc{
+	"required": requiredRule,
+	"min":      minRule,
+	"max":      maxRule,
+	"email":    emailRule,
+
+	"decimal":    decimalRule,
+	"max_digits": maxDigitsRule,
+
+	"after":  afterRule,
+	"before": beforeRule,
+
+	"sometimes": sometimesRule,
+
+	"no_html":   noHTMLRule,
+	"safe_html": safeHTMLRule,
+
+	"identifier": identifierRule,
+
+	"exclude":        excludeRule,
+	"exclude_if":     excludeRule,
+	"exclude_unless": excludeRule,
+
+	"prohibited":        prohibitedRule,
+	"prohibited_if":     prohibitedIfRule,
+	"prohibited_unless": prohibitedUnlessRule,
+	"missing_with":      missingWithRule,
+	"required_with":     requiredWithRule,
+
+	"captcha": captchaRule,
+
+	"country_code": countryCodeRule,
+	"currency":     currencyRule,
+	"language":     languageRule,
+	"timezone":     timezoneRule,
+	"postal_code":  postalCodeRule,
+
+	"phone": phoneRule,
+
+	"credit_card": creditCardRule,
+	"card_expiry": cardExpiryRule,
+	"cvv":         cvvRule,
+
+	"slug":     slugRule,
+	"username": usernameRule,
+	"hostname": hostnameRule,
+	"fqdn":     fqdnRule,
+
+	"hex_color": hexColorRule,
+	"latitude":  latitudeRule,
+	"longitude": longitudeRule,
+	"geojson":   geojsonRule,
+
+	"semver": semverRule,
+	"ulid":   ulidRule,
+	"ksuid":  ksuidRule,
+
+	"bbox":    bboxRule,
+	"polygon": polygonRule,
+
+	"search_fields": searchFieldsRule,
+	"search_ranges": searchRangesRule,
+
+	"base64":       base64Rule,
+	"base64_image": base64ImageRule,
+
+	"checksum": checksumRule,
+	"md5":      hashFormatRule("md5"),
+	"sha1":     hashFormatRule("sha1"),
+	"sha256":   hashFormatRule("sha256"),
+	"sha512":   hashFormatRule("sha512"),
+
+	"distinct_in_payload": distinctInPayloadRule,
+}
+
+// implicitRules reglas que se ejecutan aunque el campo no se envie o este vacio
+// las demas se saltan cuando el valor esta vacio (ver isEmpty): email o min sobre un campo opcional vacio
+// no deben fallar, si el campo es obligatorio eso lo dice required
+var implicitRules = map[string]bool{
+	"required": true,
+
+	"prohibited":        true,
+	"prohibited_if":     true,
+	"prohibited_unless": true,
+	"missing_with":      true,
+	"required_with":     true,
+
+	"captcha": true,
+}
+
+// parsedRules cache de las reglas ya interpretadas para no procesar el mismo string en cada request
+var parsedRules sync.Map
+
+// RegisterRule agrega o reemplaza una regla en el registro
+// se debe llamar al iniciar la aplicacion, no es seguro llamarla mientras se atienden requests
+// la regla es explicita: no se ejecuta si el valor esta vacio, para las que si use RegisterImplicitRule
+func RegisterRule(name string, fn RuleFunc) {
+	ruleFuncs[name] = fn
+	delete(implicitRules, name)
+}
+
+// RegisterImplicitRule registra una regla que tambien se ejecuta cuando el campo no se envio o esta vacio
+// (required_if, accepted, reglas que ponen un valor por defecto)
+//
+//	validation.RegisterImplicitRule("required_if", requiredIf)
+func RegisterImplicitRule(name string, fn RuleFunc) {
+	RegisterRule(name, fn)
+	implicitRules[name] = true
+}
+
+// isEmpty indica si el valor se considera vacio: nil, texto vacio, array u objeto sin elementos
+// el cero y false no estan vacios, son valores que el cliente envio
+func isEmpty(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []any:
+		return len(v) == 0
+	case map[string]any:
+		return len(v) == 0
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return rv.IsNil()
+	case reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	}
+	return false
+}
+
+// ParseRules interpreta un string de reglas como "required|min:3|max:10"
+// las reglas se pueden separar por pipe o por comas y los parametros por : o =
+func ParseRules(s string) []Rule {
+	if cached, ok := parsedRules.Load(s); ok {
+		return cached.([]Rule)
+	}
+
+	// si las reglas estan separadas por pipe
+	parts := strings.Split(s, "|")
+	commas := len(parts) == 1
+	if commas {
+		// o por si las reglas estan separadas por comas
+		parts = strings.Split(s, ",")
+	}
+
+	rules := make([]Rule, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		// separadas por comas "decimal:0,2" es una sola regla: lo que no es una regla es otro parametro de la anterior
+		// incluso con : o = ("base64_image:max_kb=500,mimes=png" o "unique:users,email,except:{auth.id}")
+		if commas && len(rules) > 0 && len(rules[len(rules)-1].Params) > 0 {
+			candidate := part
+			if i := strings.IndexAny(part, ":="); i >= 0 {
+				candidate = part[:i]
+			}
+			if _, isRule := ruleFuncs[strings.TrimSpace(candidate)]; !isRule {
+				last := &rules[len(rules)-1]
+				last.Params = append(last.Params, part)
+				continue
+			}
+		}
+		name, params, found := strings.Cut(part, ":")
+		if !found {
+			name, params, found = strings.Cut(part, "=")
+		}
+		rule := Rule{Name: strings.TrimSpace(name)}
+		if found {
+			rule.Params = strings.Split(params, ",")
+		}
+		rules = append(rules, rule)
+	}
+
+	parsedRules.Store(s, rules)
+	return rules
+}
+
+// apply ejecuta las reglas sobre un campo y agrega los errores encontrados
+// si el valor esta vacio solo se ejecutan las reglas implicitas (required)
+// el map de errores se crea solo cuando hay un error para no gastar memoria cuando todo es valido
+// si una regla retorna un HardError o se cancela el contexto se detiene y retorna ese error
+func apply(errs ValidationErrors, f *Field, rules []Rule) (ValidationErrors, error) {
+	empty := isEmpty(f.Value)
+	for _, rule := range rules {
+		if err := f.Context.Err(); err != nil {
+			return errs, err
+		}
+		fn, ok := ruleFuncs[rule.Name]
+		if !ok {
+			errs = addError(errs, f.Name, fmt.Sprintf("la regla '%s' no existe", rule.Name))
+			f.addCode(CodeUnknownRule)
+			continue
+		}
+		if empty && !implicitRules[rule.Name] {
+			continue
+		}
+		f.Params = resolveParams(f.Context, rule.Params)
+		if err := callRule(fn, f, rule.Name); err != nil {
+			var hard *HardError
+			if errors.As(err, &hard) {
+				return errs, hard
+			}
+			// la regla fallo porque se cancelo el contexto, no porque el valor sea invalido
+			if ctxErr := f.Context.Err(); ctxErr != nil {
+				return errs, ctxErr
+			}
+			message := err.Error()
+			if custom, ok := f.customMessage(rule.Name); ok {
+				message = custom
+			} else if translated, ok := f.translatedMessage(rule.Name); ok {
+				message = translated
+			}
+			// el valor se quita de cualquier mensaje, tambien de los traducidos y personalizados
+			if f.sensitive {
+				message = redactMessage(message, f.Value)
+			}
+			errs = addError(errs, f.Name, message)
+			f.addCode(RuleCode(rule.Name))
+		}
+	}
+	return errs, nil
+}
+
+// ErrRulePanic una regla entro en panico, el error es un HardError (errors.Is(err, validation.ErrRulePanic))
+var ErrRulePanic = errors.New("la regla entró en pánico")
+
+// callRule ejecuta la regla, si entra en panico el panico se retorna como HardError y detiene la validacion
+// asi una regla con un bug no tumba el servidor (en StructParallel corre en otra goroutine)
+func callRule(fn RuleFunc, f *Field, name string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &HardError{Err: fmt.Errorf("%s: %w (%v)", name, ErrRulePanic, r)}
+		}
+	}()
+	return fn(f)
+}
+
+// addCode guarda el codigo del ultimo error del campo, el map se crea solo cuando hay errores
+func (f *Field) addCode(code string) {
+	if f.codes == nil {
+		f.codes = make(map[string][]string)
+	}
+	f.codes[f.Name] = append(f.codes[f.Name], code)
+}
+
+// addError agrega el error creando el map si es nesesario
+func addError(errs ValidationErrors, field string, message string) ValidationErrors {
+	if errs == nil {
+		errs = make(ValidationErrors)
+	}
+	errs.Add(field, message)
+	return errs
+}
+
+// requiredRule adapta Required al registro de reglas
+func requiredRule(f *Field) error {
+	return Required(f.Value)
+}
+
+// minRule adapta Min al registro de reglas
+func minRule(f *Field) error {
+	if len(f.Params) == 0 {
+		return fmt.Errorf("la regla min requiere un parametro")
+	}
+	if s, ok := f.Value.(string); ok {
+		return Min(s, f.Params[0])
+	}
+	n, ok := toFloat64(f.Value)
+	if !ok {
+		return fmt.Errorf("el valor debe ser numérico o texto")
+	}
+	return Min(n, f.Params[0])
+}
+
+// maxRule adapta Max al registro de reglas
+func maxRule(f *Field) error {
+	if len(f.Params) == 0 {
+		return fmt.Errorf("la regla max requiere un parametro")
+	}
+	if s, ok := f.Value.(string); ok {
+		return Max(s, f.Params[0])
+	}
+	n, ok := toFloat64(f.Value)
+	if !ok {
+		return fmt.Errorf("el valor debe ser numérico o texto")
+	}
+	return Max(n, f.Params[0])
+}
+
+// emailRule adapta Email al registro de reglas
+func emailRule(f *Field) error {
+	s, ok := f.Value.(string)
+	if !ok {
+		return fmt.Errorf("el valor debe ser texto")
+	}
+	return Email(s)
+}
+
+// toFloat64 convierte cualquier valor numerico a float64, los punteros de los campos opcionales (*float64) se siguen
+func toFloat64(value any) (float64, bool) {
+	if n, ok := value.(json.Number); ok {
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
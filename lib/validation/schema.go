@@ -0,0 +1,232 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/donbarrigon/new-project/lib/formatter"
+)
+
+// Esquema de formulario para que los paneles de administracion y los generadores de formularios
+// construyan el formulario a partir del struct sin repetir los campos ni las restricciones
+//
+//	type CreateProduct struct {
+//		Name     string   `json:"name" label:"Nombre" rules:"required|min:3|max:80"`
+//		Status   string   `json:"status" label:"Estado" options:"draft:Borrador,published:Publicado" rules:"required"`
+//		Price    float64  `json:"price" label:"Precio" rules:"required|min:0"`
+//		Color    string   `json:"color" rules:"hex_color"`
+//		Summary  string   `json:"summary" input:"textarea" help:"Se muestra en el listado"`
+//		Variants []Variant `json:"variants" rules:"count:1,10"`
+//	}
+//
+//	fields, err := validation.Schema(&CreateProduct{})
+//
+// tags que se leen ademas de json y rules:
+//
+//	label   nombre visible, sin el tag se arma con el nombre del campo (first_name -> First name)
+//	options opciones de un select, valor:etiqueta separados por comas o solo los valores (s,m,l)
+//	input   tipo de control cuando el que se deduce no sirve (textarea, password, hidden)
+//	help    texto de ayuda debajo del campo
+//
+// las restricciones salen de las reglas: min y max son longitud en los textos, valor en los numeros
+// y cantidad de elementos en los arrays
+
+// FieldSchema descripcion de un campo del formulario
+type FieldSchema struct {
+	Name        string         `json:"name"`
+	Label       string         `json:"label"`
+	Type        string         `json:"type"`
+	Input       string         `json:"input"`
+	Required    bool           `json:"required"`
+	Help        string         `json:"help,omitempty"`
+	Options     []Option       `json:"options,omitempty"`
+	Constraints map[string]any `json:"constraints,omitempty"`
+	Rules       []ExportedRule `json:"rules,omitempty"`
+	// Fields campos de los elementos cuando el campo es un array de objetos, o del objeto anidado
+	Fields []FieldSchema `json:"fields,omitempty"`
+}
+
+// Option una opcion de un select
+type Option struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+}
+
+// inputRules control del formulario segun las reglas del campo
+var inputRules = map[string]string{
+	"email":       "email",
+	"phone":       "tel",
+	"hex_color":   "color",
+	"credit_card": "text",
+	"cvv":         "password",
+	"latitude":    "number",
+	"longitude":   "number",
+}
+
+// Schema describe los campos del struct (o de los elementos de un slice de structs) para construir un formulario
+func Schema(v any) ([]FieldSchema, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("schema: se espera un struct o un slice de structs y se recibió %T", v)
+	}
+	return structSchema(t, getStructMeta(t), map[reflect.Type]bool{}), nil
+}
+
+// structSchema describe los campos de un struct, visiting evita la recursion infinita
+func structSchema(t reflect.Type, meta *structMeta, visiting map[reflect.Type]bool) []FieldSchema {
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	sensitive := make(map[string]bool, len(meta.sensitive))
+	for _, s := range meta.sensitive {
+		sensitive[s] = true
+	}
+
+	fields := make([]FieldSchema, 0, len(meta.fields))
+	for _, sf := range meta.fields {
+		field := t.FieldByIndex(sf.index)
+		ft := field.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		fs := FieldSchema{
+			Name:    sf.name,
+			Label:   field.Tag.Get("label"),
+			Type:    clientType(ft),
+			Help:    field.Tag.Get("help"),
+			Options: parseOptions(field.Tag.Get("options")),
+		}
+		if fs.Label == "" {
+			fs.Label = humanize(sf.name)
+		}
+		for _, r := range sf.rules {
+			if r.Name == "required" {
+				fs.Required = true
+			}
+			if !ServerRules[r.Name] && !hasPlaceholder(r.Params) {
+				fs.Rules = append(fs.Rules, ExportedRule{Rule: r.Name, Params: r.Params})
+			}
+		}
+		fs.Constraints = constraints(fs.Type, sf.rules)
+
+		switch {
+		case sf.nested != nil:
+			fs.Fields = structSchema(ft, sf.nested, visiting)
+		case fs.Type == "array" && isNestedStruct(elemType(ft)) && !visiting[elemType(ft)]:
+			et := elemType(ft)
+			fs.Fields = structSchema(et, getStructMeta(et), visiting)
+		}
+
+		fs.Input = field.Tag.Get("input")
+		if fs.Input == "" {
+			fs.Input = inputType(fs, sensitive[sf.name])
+		}
+		fields = append(fields, fs)
+	}
+	return fields
+}
+
+// elemType tipo de los elementos de un slice sin punteros
+func elemType(t reflect.Type) reflect.Type {
+	et := t.Elem()
+	for et.Kind() == reflect.Ptr {
+		et = et.Elem()
+	}
+	return et
+}
+
+// inputType deduce el control del formulario por las opciones, las reglas y el tipo
+func inputType(fs FieldSchema, sensitive bool) string {
+	if len(fs.Options) > 0 {
+		if fs.Type == "array" {
+			return "multiselect"
+		}
+		return "select"
+	}
+	if sensitive {
+		return "password"
+	}
+	for _, r := range fs.Rules {
+		if input, ok := inputRules[r.Rule]; ok {
+			return input
+		}
+	}
+	switch fs.Type {
+	case "boolean":
+		return "checkbox"
+	case "integer", "number":
+		return "number"
+	case "date":
+		return "datetime"
+	case "array":
+		return "list"
+	case "object":
+		return "group"
+	}
+	return "text"
+}
+
+// constraints traduce min, max y count a restricciones segun el tipo del campo
+func constraints(kind string, rules []Rule) map[string]any {
+	out := map[string]any{}
+	for _, r := range rules {
+		if len(r.Params) == 0 {
+			continue
+		}
+		switch r.Name {
+		case "min", "max":
+			switch kind {
+			case "string":
+				out[r.Name+"_length"] = formatter.ParseValue(r.Params[0])
+			case "array":
+				out[r.Name+"_items"] = formatter.ParseValue(r.Params[0])
+			default:
+				out[r.Name] = formatter.ParseValue(r.Params[0])
+			}
+		case "count":
+			out["min_items"] = formatter.ParseValue(r.Params[0])
+			out["max_items"] = formatter.ParseValue(r.Params[len(r.Params)-1])
+		case "max_digits":
+			out["max_digits"] = formatter.ParseValue(r.Params[0])
+		case "decimal":
+			out["decimals"] = formatter.ParseValue(r.Params[len(r.Params)-1])
+		case "after", "before":
+			out[r.Name] = r.Params[0]
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// parseOptions interpreta el tag options: valor:etiqueta separados por comas
+func parseOptions(tag string) []Option {
+	if tag == "" {
+		return nil
+	}
+	parts := strings.Split(tag, ",")
+	options := make([]Option, 0, len(parts))
+	for _, p := range parts {
+		value, label, found := strings.Cut(strings.TrimSpace(p), ":")
+		if !found {
+			label = value
+		}
+		options = append(options, Option{Value: value, Label: label})
+	}
+	return options
+}
+
+// humanize arma la etiqueta con el nombre del campo (first_name -> First name)
+func humanize(name string) string {
+	s := strings.ReplaceAll(name, "_", " ")
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
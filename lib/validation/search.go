@@ -0,0 +1,72 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/donbarrigon/new-project/lib/search"
+)
+
+// Reglas para el parametro de busqueda (lib/search)
+//
+//	Q search.Query `json:"q" query:"q" rules:"search_fields:name,status,price,brand|search_ranges:price,created_at"`
+//
+// search_fields son los campos que se pueden usar con campo:valor (las palabras sin campo siempre se aceptan)
+// y search_ranges los que aceptan rangos. el campo puede ser texto o search.Query, que la url ya llena interpretada
+
+// searchValue retorna la busqueda interpretada
+func searchValue(value any) (search.Query, error) {
+	switch v := value.(type) {
+	case search.Query:
+		return v, nil
+	case *search.Query:
+		if v == nil {
+			return search.Query{}, nil
+		}
+		return *v, nil
+	}
+	s, ok := textValue(value)
+	if !ok {
+		return search.Query{}, errors.New("la búsqueda debe ser texto")
+	}
+	return search.Parse(s)
+}
+
+// searchFieldsRule los parametros son los campos permitidos (search_fields:name,status)
+func searchFieldsRule(f *Field) error {
+	q, err := searchValue(f.Value)
+	if err != nil {
+		return err
+	}
+	var invalid []string
+	for _, field := range q.Fields() {
+		if !slices.Contains(f.Params, field) {
+			invalid = append(invalid, field)
+		}
+	}
+	if len(invalid) > 0 {
+		return fmt.Errorf("no se puede buscar por %s, los campos permitidos son %s", strings.Join(invalid, ", "), strings.Join(f.Params, ", "))
+	}
+	return nil
+}
+
+// searchRangesRule los parametros son los campos que aceptan rangos (search_ranges:price,created_at)
+func searchRangesRule(f *Field) error {
+	q, err := searchValue(f.Value)
+	if err != nil {
+		return err
+	}
+	var invalid []string
+	q.Walk(func(n *search.Node) bool {
+		if n.Kind == search.Range && !slices.Contains(f.Params, n.Field) && !slices.Contains(invalid, n.Field) {
+			invalid = append(invalid, n.Field)
+		}
+		return true
+	})
+	if len(invalid) > 0 {
+		return fmt.Errorf("el campo %s no acepta rangos", strings.Join(invalid, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Mask valor con el que se reemplazan los datos sensibles en errores, logs y dumps
+const Mask = "[REDACTED]"
+
+// Hider lo implementan los structs que declaran sus campos sensibles en un metodo en lugar de un tag
+// los nombres van en notacion de puntos, se lee una sola vez por tipo (como el $hidden de laravel)
+//
+//	func (r *User) Hidden() []string { return []string{"password", "card.number"} }
+type Hider interface {
+	Hidden() []string
+}
+
+var hiderType = reflect.TypeOf((*Hider)(nil)).Elem()
+
+// isSensitive indica si el campo tiene el tag sensitive
+//
+//	Password string `json:"password" rules:"required|min:8" sensitive:""`
+func isSensitive(field reflect.StructField) bool {
+	tag, ok := field.Tag.Lookup("sensitive")
+	return ok && tag != "false"
+}
+
+// hiddenFields retorna los campos que declara el metodo Hidden del tipo
+func hiddenFields(t reflect.Type) []string {
+	if !reflect.PointerTo(t).Implements(hiderType) {
+		return nil
+	}
+	return reflect.New(t).Interface().(Hider).Hidden()
+}
+
+// SensitiveFields retorna los campos sensibles del struct (tag sensitive y Hidden) en notacion de puntos
+func SensitiveFields(v any) []string {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	return getStructMeta(t).sensitive
+}
+
+// Redact convierte v en un map (segun su json) con los campos sensibles enmascarados
+// sirve para logs y dumps de depuracion, si v no es un objeto json retorna nil
+func Redact(v any) map[string]any {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var m map[string]any
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil
+	}
+	for _, field := range SensitiveFields(v) {
+		mask(m, field)
+	}
+	return m
+}
+
+// Unredactable lo que retorna Dump cuando v no es un objeto json y no se pueden enmascarar sus campos
+const Unredactable = "[unredactable]"
+
+// Dump retorna v como json indentado con los campos sensibles enmascarados
+// si v no es un objeto json retorna Unredactable, imprimirlo con %+v mostraria los datos sensibles
+//
+//	log.Printf("request: %s", validation.Dump(&req))
+func Dump(v any) string {
+	m := Redact(v)
+	if m == nil {
+		return Unredactable
+	}
+	out, _ := json.MarshalIndent(m, "", "  ")
+	return string(out)
+}
+
+// mask reemplaza el valor del campo si existe, en los arrays se enmascara el campo de cada elemento
+// (items.card_number o items.*.card_number)
+func mask(data map[string]any, name string) {
+	key, rest, nested := strings.Cut(name, ".")
+	value, ok := data[key]
+	if !ok || value == nil {
+		return
+	}
+	if !nested {
+		data[key] = Mask
+		return
+	}
+	switch value := value.(type) {
+	case map[string]any:
+		mask(value, rest)
+	case []any:
+		rest = strings.TrimPrefix(rest, "*.")
+		for _, item := range value {
+			if m, ok := item.(map[string]any); ok {
+				mask(m, rest)
+			}
+		}
+	}
+}
+
+// redactMessage quita el valor del campo del mensaje de error
+// las reglas suelen incluirlo ("el valor 'x' no es un número válido") y no debe llegar al cliente ni a los logs
+func redactMessage(message string, value any) string {
+	if value == nil {
+		return message
+	}
+	s := fmt.Sprint(value)
+	if s == "" {
+		return message
+	}
+	return strings.ReplaceAll(message, s, Mask)
+}
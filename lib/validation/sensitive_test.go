@@ -0,0 +1,62 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+)
+
+type sensitiveItem struct {
+	SKU        string `json:"sku"`
+	CardNumber string `json:"card_number" sensitive:""`
+}
+
+type sensitiveOrder struct {
+	Email    string          `json:"email"`
+	Password string          `json:"password" sensitive:""`
+	Items    []sensitiveItem `json:"items"`
+	Payments []*struct {
+		Token string `json:"token"`
+	} `json:"payments"`
+}
+
+func (o *sensitiveOrder) Hidden() []string { return []string{"payments.token"} }
+
+func TestRedactMasksArrays(t *testing.T) {
+	order := &sensitiveOrder{
+		Email:    "ana@example.com",
+		Password: "secreto123",
+		Items:    []sensitiveItem{{SKU: "A1", CardNumber: "4111111111111111"}, {SKU: "B2", CardNumber: "5500000000000004"}},
+		Payments: []*struct {
+			Token string `json:"token"`
+		}{{Token: "tok_abc"}},
+	}
+	out := Dump(order)
+	for _, secret := range []string{"secreto123", "4111111111111111", "5500000000000004", "tok_abc"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("el dump no debe contener %q:\n%s", secret, out)
+		}
+	}
+	for _, visible := range []string{"ana@example.com", "A1", "B2"} {
+		if !strings.Contains(out, visible) {
+			t.Errorf("el dump debe contener %q:\n%s", visible, out)
+		}
+	}
+}
+
+func TestDumpUnredactable(t *testing.T) {
+	cases := []struct {
+		name string
+		v    any
+	}{
+		{"slice", []sensitiveOrder{{Password: "secreto123"}}},
+		{"no serializable", struct {
+			Password string `json:"password" sensitive:""`
+			Fn       func()
+		}{Password: "secreto123", Fn: func() {}}},
+	}
+	for _, c := range cases {
+		if got := Dump(c.v); got != Unredactable {
+			t.Errorf("%s: se esperaba %s, se obtuvo %s", c.name, Unredactable, got)
+		}
+	}
+}
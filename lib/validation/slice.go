@@ -0,0 +1,107 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+)
+
+// Slice valida cada elemento de un slice de structs con las reglas del tag rules del elemento
+// las reglas se comportan como *.campo: los errores se nombran con el indice (0.email, 3.address.city)
+// y las reglas que comparan con otro campo (after:starts_at) lo buscan dentro del mismo elemento
+//
+//	type CreateUsers []User
+//	err := validation.Slice(&users)
+func Slice(v any) error {
+	return SliceContext(context.Background(), v)
+}
+
+// SliceContext igual que Slice pero las reglas reciben el contexto en Field.Context
+func SliceContext(ctx context.Context, v any) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return errors.New("no se puede validar un puntero nulo")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return errors.New("se espera un slice para validar")
+	}
+	elemType := rv.Type().Elem()
+	for elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return errors.New("se espera un slice de structs para validar")
+	}
+	meta := overrideMeta(ctx, getStructMeta(elemType))
+
+	var errs CodedErrors
+	// los datos de cada elemento se guardan solo si hay que compararlos entre si
+	var elements []map[string]any
+	if hasDistinct(meta.rules) {
+		elements = make([]map[string]any, rv.Len())
+	}
+	only := Only(ctx)
+	for i := 0; i < rv.Len(); i++ {
+		index := strconv.Itoa(i)
+		elemCtx, ok := onlyElement(ctx, only, index)
+		if !ok && elements == nil {
+			continue
+		}
+		ev := rv.Index(i)
+		for ev.Kind() == reflect.Ptr {
+			if ev.IsNil() {
+				break
+			}
+			ev = ev.Elem()
+		}
+		if ev.Kind() == reflect.Ptr {
+			errs.Add(index, CodeNullElement, "el elemento no puede ser nulo")
+			continue
+		}
+		if len(meta.rules)+len(meta.warnings) == 0 {
+			continue
+		}
+
+		data := structToMap(ev, meta)
+		if elements != nil {
+			elements[i] = data
+		}
+		elem := ev.Interface()
+		if ev.CanAddr() {
+			elem = ev.Addr().Interface()
+		}
+		// los elementos que no se pidieron solo se leen para compararlos con distinct_in_payload
+		if !ok {
+			continue
+		}
+		present := presenceFunc(elem, data)
+		if len(meta.warnings) > 0 {
+			if err := collectWarnings(withWarningPrefix(elemCtx, index+"."), data, meta.warnings, present); err != nil {
+				return err
+			}
+		}
+		err := validate(elemCtx, data, meta.rules, present)
+		normalizeStruct(ev, meta, data)
+		excludeStruct(ev, meta, data)
+		var itemErrs ValidationErrors
+		if errors.As(err, &itemErrs) {
+			codes := FieldCodes(err)
+			for field, msgs := range itemErrs {
+				for i, msg := range msgs {
+					errs.Add(index+"."+field, codeAt(codes, field, i), msg)
+				}
+			}
+		} else if err != nil {
+			return err
+		}
+	}
+	if elements != nil {
+		checkDistinct(&errs, elements, meta.rules)
+		return NewCodedErrors(filterSelected(errs.Errors, only), errs.Codes).Err()
+	}
+	return errs.Err()
+}
@@ -0,0 +1,324 @@
+package validation
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/donbarrigon/new-project/lib/formatter"
+)
+
+// structField informacion de un campo del struct que se necesita para validarlo
+type structField struct {
+	index  []int       // posicion del campo dentro del struct (puede ser de un struct embebido)
+	name   string      // nombre del campo segun el tag json
+	rules  []Rule      // reglas del tag rules
+	nested *structMeta // si el campo es un struct se valida por dentro
+	// optional el campo es un OptionalValuer, si no se envio no se agrega a los datos
+	optional bool
+}
+
+// structMeta informacion de un tipo de struct, se calcula una sola vez por tipo
+type structMeta struct {
+	fields []structField
+	rules  []fieldRules // reglas de todos los campos incluidos los anidados en notacion de puntos
+	// sensitive campos sensibles (tag sensitive o Hidden) en notacion de puntos
+	sensitive []string
+	// warnings reglas del tag warn, no hacen fallar la validacion (ver WithWarnings)
+	warnings []fieldRules
+}
+
+// fieldRules reglas de un campo en notacion de puntos
+type fieldRules struct {
+	name      string
+	rules     []Rule
+	sometimes bool   // solo se valida si el campo se envio
+	sensitive bool   // el valor no se muestra en los mensajes de error
+	pattern   string // nombre con * del que salio el campo (items.*.name), para buscar los mensajes
+	exclude   bool   // tiene reglas exclude, se evaluan antes de validar
+	normalize bool   // tiene reglas que cambian el valor (phone:CO,e164)
+	message   string // mensaje del tag warn_message, reemplaza los de las reglas de advertencia
+}
+
+// structCache cache de la metadata de los structs por tipo
+var structCache sync.Map
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	marshalerType     = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Struct valida un struct usando las reglas del tag `rules`
+// los campos se nombran segun el tag json y los structs anidados se validan con notacion de puntos
+//
+//	type User struct {
+//		Name  string `json:"name" rules:"required|min:3|max:10"`
+//		Email string `json:"email" rules:"required|email"`
+//	}
+func Struct(v any) error {
+	return StructContext(context.Background(), v)
+}
+
+// StructContext igual que Struct pero las reglas reciben el contexto en Field.Context
+func StructContext(ctx context.Context, v any) error {
+	rv, meta, err := structValue(v)
+	if err != nil {
+		return err
+	}
+	meta = overrideMeta(ctx, meta)
+	if len(meta.rules)+len(meta.warnings) == 0 {
+		return nil
+	}
+	data := structToMap(rv, meta)
+	present := presenceFunc(v, data)
+	if err := collectWarnings(ctx, data, meta.warnings, present); err != nil {
+		return err
+	}
+	err = validate(ctx, data, meta.rules, present)
+	normalizeStruct(rv, meta, data)
+	excludeStruct(rv, meta, data)
+	return err
+}
+
+// structValue obtiene el valor del struct (sin punteros) y su metadata
+func structValue(v any) (reflect.Value, *structMeta, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return rv, nil, errors.New("no se puede validar un puntero nulo")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return rv, nil, errors.New("se espera un struct para validar")
+	}
+	return rv, getStructMeta(rv.Type()), nil
+}
+
+// validate ejecuta las reglas de cada campo sobre los datos
+// present indica si el cliente envio el campo, se usa con la regla sometimes
+func validate(ctx context.Context, data map[string]any, fields []fieldRules, present func(string) bool) error {
+	return validateWith(ctx, data, fields, present, nil)
+}
+
+// validateWith igual que validate pero con mensajes personalizados por "campo.regla" o "regla"
+func validateWith(ctx context.Context, data map[string]any, fields []fieldRules, present func(string) bool, messages map[string]string) error {
+	var errs ValidationErrors
+	var err error
+	f := &Field{Data: data, Context: ctx, messages: messages, present: present}
+	only := Only(ctx)
+	timer := fieldTimer(ctx)
+	for _, fr := range fields {
+		if !selected(only, fr.name) {
+			continue
+		}
+		if fr.sometimes && !present(fr.name) {
+			continue
+		}
+		if fr.exclude && isExcluded(data, fr.rules) {
+			continue
+		}
+		f.Name = fr.name
+		f.Value = lookup(data, fr.name)
+		f.sensitive = fr.sensitive
+		f.pattern = fr.pattern
+		if errs, err = applyTimed(errs, f, fr.rules, timer); err != nil {
+			return err
+		}
+	}
+	if len(errs) > 0 {
+		return NewCodedErrors(errs, f.codes)
+	}
+	return nil
+}
+
+// lookup busca un valor en los datos usando notacion de puntos, los indices de los arrays son numeros (items.0.name)
+func lookup(data map[string]any, name string) any {
+	var current any = data
+	for name != "" {
+		var key string
+		key, name, _ = strings.Cut(name, ".")
+		value, ok := child(current, key)
+		if !ok {
+			return nil
+		}
+		current = value
+	}
+	return current
+}
+
+// getStructMeta retorna la metadata del tipo desde el cache o la calcula
+func getStructMeta(t reflect.Type) *structMeta {
+	if cached, ok := structCache.Load(t); ok {
+		return cached.(*structMeta)
+	}
+	meta := buildStructMeta(t, nil, map[reflect.Type]bool{})
+	structCache.Store(t, meta)
+	return meta
+}
+
+// buildStructMeta analiza el struct y sus campos
+// visiting evita la recursion infinita con tipos que se referencian a si mismos (type Node struct { Child *Node })
+func buildStructMeta(t reflect.Type, index []int, visiting map[reflect.Type]bool) *structMeta {
+	meta := &structMeta{}
+	visiting[t] = true
+	defer delete(visiting, t)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		// los campos privados se ignoran
+		if !field.IsExported() {
+			continue
+		}
+
+		fieldIndex := append(append([]int{}, index...), i)
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		// los structs embebidos se aplanan igual que lo hace encoding/json
+		if field.Anonymous && fieldType.Kind() == reflect.Struct && field.Tag.Get("json") == "" && !visiting[fieldType] {
+			embedded := buildStructMeta(fieldType, fieldIndex, visiting)
+			meta.fields = append(meta.fields, embedded.fields...)
+			meta.rules = append(meta.rules, embedded.rules...)
+			meta.sensitive = append(meta.sensitive, embedded.sensitive...)
+			meta.warnings = append(meta.warnings, embedded.warnings...)
+			continue
+		}
+
+		name := jsonName(field)
+		if name == "-" {
+			continue
+		}
+
+		sf := structField{
+			index:    fieldIndex,
+			name:     name,
+			rules:    ParseRules(field.Tag.Get("rules")),
+			optional: field.Type.Implements(optionalType),
+		}
+		if isSensitive(field) || hasSensitiveRule(sf.rules) {
+			meta.sensitive = append(meta.sensitive, name)
+		}
+		if len(sf.rules) > 0 {
+			meta.rules = append(meta.rules, fieldRules{name: name, rules: sf.rules, sometimes: hasSometimes(sf.rules), exclude: hasExclude(sf.rules), normalize: hasNormalizer(sf.rules)})
+		}
+		if warn := ParseRules(field.Tag.Get("warn")); len(warn) > 0 {
+			meta.warnings = append(meta.warnings, fieldRules{name: name, rules: warn, message: field.Tag.Get("warn_message")})
+		}
+
+		if isNestedStruct(fieldType) && !visiting[fieldType] {
+			sf.nested = buildStructMeta(fieldType, nil, visiting)
+			for _, fr := range sf.nested.rules {
+				meta.rules = append(meta.rules, fieldRules{name: name + "." + fr.name, rules: fr.rules, sometimes: fr.sometimes, sensitive: fr.sensitive, exclude: fr.exclude, normalize: fr.normalize})
+			}
+			for _, s := range sf.nested.sensitive {
+				meta.sensitive = append(meta.sensitive, name+"."+s)
+			}
+			for _, fr := range sf.nested.warnings {
+				meta.warnings = append(meta.warnings, fieldRules{name: name + "." + fr.name, rules: fr.rules, message: fr.message})
+			}
+		}
+		// de los slices de structs solo se toman los campos sensibles (items.*.card_number) para enmascararlos
+		if elem := sliceElem(fieldType); elem != nil && !visiting[elem] {
+			for _, s := range buildStructMeta(elem, nil, visiting).sensitive {
+				meta.sensitive = append(meta.sensitive, name+".*."+s)
+			}
+		}
+
+		meta.fields = append(meta.fields, sf)
+	}
+
+	meta.sensitive = append(meta.sensitive, hiddenFields(t)...)
+	for i := range meta.rules {
+		for _, s := range meta.sensitive {
+			if meta.rules[i].name == s {
+				meta.rules[i].sensitive = true
+			}
+		}
+	}
+	for i := range meta.warnings {
+		for _, s := range meta.sensitive {
+			if meta.warnings[i].name == s {
+				meta.warnings[i].sensitive = true
+			}
+		}
+	}
+	return meta
+}
+
+// jsonName retorna el nombre del campo segun el tag json o en snake_case si no tiene
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		name = formatter.ToSnakeCase(field.Name)
+	}
+	return name
+}
+
+// isNestedStruct indica si el tipo es un struct que se debe validar por dentro
+// time.Time y los tipos que se serializan solos no se consideran structs anidados
+func isNestedStruct(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t == timeType {
+		return false
+	}
+	for _, m := range []reflect.Type{marshalerType, textMarshalerType} {
+		if t.Implements(m) || reflect.PointerTo(t).Implements(m) {
+			return false
+		}
+	}
+	return true
+}
+
+// sliceElem retorna el struct de los elementos si t es un slice o array de structs, nil si no lo es
+func sliceElem(t reflect.Type) reflect.Type {
+	if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+		return nil
+	}
+	elem := t.Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if !isNestedStruct(elem) {
+		return nil
+	}
+	return elem
+}
+
+// structToMap convierte el struct en un map usando los nombres del tag json
+func structToMap(rv reflect.Value, meta *structMeta) map[string]any {
+	data := make(map[string]any, len(meta.fields))
+	for _, sf := range meta.fields {
+		fv, err := rv.FieldByIndexErr(sf.index)
+		if err != nil {
+			// struct embebido nulo
+			continue
+		}
+		if sf.nested != nil {
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					data[sf.name] = nil
+					continue
+				}
+				fv = fv.Elem()
+			}
+			data[sf.name] = structToMap(fv, sf.nested)
+			continue
+		}
+		if sf.optional {
+			if value, provided := fv.Interface().(OptionalValuer).ValidationValue(); provided {
+				data[sf.name] = value
+			}
+			continue
+		}
+		data[sf.name] = fv.Interface()
+	}
+	return data
+}
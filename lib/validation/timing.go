@@ -0,0 +1,44 @@
+package validation
+
+import (
+	"context"
+	"time"
+)
+
+// Tiempo de las reglas por campo: para encontrar la regla lenta de un FormRequest (unique sin indice,
+// una verificacion remota). la funcion se llama al terminar las reglas de cada campo
+//
+//	ctx = validation.WithFieldTimer(ctx, func(field string, elapsed time.Duration) {
+//		log.Printf("%s %s", field, elapsed)
+//	})
+//	err := validation.StructContext(ctx, &user)
+//
+// con StructParallel la funcion se llama desde varias goroutines
+
+// fieldTimerKey llave del contexto con la funcion que recibe los tiempos
+type fieldTimerKey struct{}
+
+// WithFieldTimer mide las reglas de cada campo de las validaciones que usen el contexto
+func WithFieldTimer(ctx context.Context, timer func(field string, elapsed time.Duration)) context.Context {
+	return context.WithValue(ctx, fieldTimerKey{}, timer)
+}
+
+// fieldTimer retorna la funcion del contexto, nil si no se pidio medir
+func fieldTimer(ctx context.Context) func(field string, elapsed time.Duration) {
+	if ctx == nil {
+		return nil
+	}
+	timer, _ := ctx.Value(fieldTimerKey{}).(func(field string, elapsed time.Duration))
+	return timer
+}
+
+// applyTimed igual que apply pero avisa al timer cuanto tardaron las reglas del campo
+func applyTimed(errs ValidationErrors, f *Field, rules []Rule, timer func(field string, elapsed time.Duration)) (ValidationErrors, error) {
+	if timer == nil {
+		return apply(errs, f, rules)
+	}
+	start := time.Now()
+	errs, err := apply(errs, f, rules)
+	timer(f.Name, time.Since(start))
+	return errs, err
+}
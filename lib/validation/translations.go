@@ -0,0 +1,77 @@
+package validation
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// Mensajes por idioma: los mensajes de las reglas estan en español, RegisterMessages agrega los de otro idioma
+// por regla ("required") o por campo y regla ("email.unique") y se usan cuando el idioma de la validacion
+// es ese (es-CO busca es-CO y luego es). los mensajes del Validator (New) tienen prioridad y en los campos
+// sensibles el valor se quita tambien del mensaje traducido (:params puede incluirlo, not_in:1234)
+//
+//	validation.RegisterMessages("en", map[string]string{
+//		"required": "the :attribute field is required",
+//		"min":      "the :attribute field must be at least :params",
+//	})
+//
+// :attribute es el nombre del campo y :params los parametros de la regla separados por coma.
+// el idioma lo da LocaleResolver, request lo llena con el idioma de la solicitud (middleware.Locale)
+
+var (
+	translations   = map[string]map[string]string{}
+	translationsMu sync.RWMutex
+)
+
+// LocaleResolver retorna el idioma de la validacion (en, es-CO) desde el contexto, "" usa los mensajes en español
+// solo se consulta cuando una regla falla
+var LocaleResolver func(ctx context.Context) string
+
+// RegisterMessages agrega o reemplaza los mensajes de un idioma, se debe llamar al iniciar la aplicacion
+func RegisterMessages(locale string, messages map[string]string) {
+	translationsMu.Lock()
+	defer translationsMu.Unlock()
+	locale = strings.ToLower(locale)
+	if translations[locale] == nil {
+		translations[locale] = make(map[string]string, len(messages))
+	}
+	for key, message := range messages {
+		translations[locale][key] = message
+	}
+}
+
+// translatedMessage busca el mensaje de la regla en el idioma de la validacion
+func (f *Field) translatedMessage(rule string) (string, bool) {
+	if LocaleResolver == nil || f.Context == nil {
+		return "", false
+	}
+	locale := strings.ToLower(LocaleResolver(f.Context))
+	if locale == "" {
+		return "", false
+	}
+	translationsMu.RLock()
+	defer translationsMu.RUnlock()
+	for locale != "" {
+		if messages, ok := translations[locale]; ok {
+			message, ok := messages[f.Name+"."+rule]
+			if !ok && f.pattern != "" {
+				message, ok = messages[f.pattern+"."+rule]
+			}
+			if !ok {
+				message, ok = messages[rule]
+			}
+			if ok {
+				message = strings.ReplaceAll(message, ":attribute", f.Name)
+				return strings.ReplaceAll(message, ":params", strings.Join(f.Params, ", ")), true
+			}
+		}
+		// es-CO -> es
+		cut := strings.LastIndexByte(locale, '-')
+		if cut < 0 {
+			break
+		}
+		locale = locale[:cut]
+	}
+	return "", false
+}
@@ -0,0 +1,55 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type translatedPin struct {
+	Pin  string `json:"pin" rules:"test_differs:1234" sensitive:""`
+	Code string `json:"code" rules:"test_differs:9999"`
+}
+
+func TestTranslatedMessagesRedactSensitiveValues(t *testing.T) {
+	RegisterRule("test_differs", func(f *Field) error {
+		if f.Value == f.Params[0] {
+			return errors.New("el valor " + f.Params[0] + " no esta permitido")
+		}
+		return nil
+	})
+	RegisterMessages("xx-test", map[string]string{"test_differs": "the :attribute can not be :params"})
+
+	previous := LocaleResolver
+	t.Cleanup(func() { LocaleResolver = previous })
+	type localeKey struct{}
+	LocaleResolver = func(ctx context.Context) string {
+		locale, _ := ctx.Value(localeKey{}).(string)
+		return locale
+	}
+
+	cases := []struct {
+		name   string
+		locale string
+		pin    string
+		code   string
+	}{
+		{"español", "", "", ""},
+		{"traducido", "xx-test", "the pin can not be " + Mask, "the code can not be 9999"},
+	}
+	for _, c := range cases {
+		ctx := context.WithValue(context.Background(), localeKey{}, c.locale)
+		err := StructContext(ctx, &translatedPin{Pin: "1234", Code: "9999"})
+		var verrs ValidationErrors
+		if !errors.As(err, &verrs) {
+			t.Fatalf("%s: se esperaban errores de validacion, se obtuvo %v", c.name, err)
+		}
+		if strings.Contains(verrs.First("pin"), "1234") {
+			t.Errorf("%s: el valor sensible aparece en el mensaje: %s", c.name, verrs.First("pin"))
+		}
+		if c.locale != "" && (verrs.First("pin") != c.pin || verrs.First("code") != c.code) {
+			t.Errorf("%s: mensajes %v", c.name, verrs)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package validation
 import (
 	"errors"
 	"fmt"
+	"reflect"
 	"regexp"
 	"strings"
 	"time"
@@ -27,12 +28,12 @@ func Required(value any) error {
 		}
 	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
 		// Números diferentes cero son válidos
-		if v != 0 {
+		if reflect.ValueOf(v).IsZero() {
 			return errors.New("el campo es obligatorio")
 		}
 	case float32, float64:
 		// Números decimales diferentes de cero siempre válidos
-		if v != 0 {
+		if reflect.ValueOf(v).IsZero() {
 			return errors.New("el campo es obligatorio")
 		}
 	case bool:
@@ -130,12 +131,13 @@ func Max[T AllowTypes, U AllowTypes](value T, max U) error {
 	return nil
 }
 
+// emailRegex expresión regular para validar el formato del email
+// se compila una sola vez al iniciar y no en cada validación
+var emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+
 // Email valida si un campo tiene un formato de correo electrónico válido
 func Email(value string) error {
-	// Expresión regular para validar el formato del email
-	regex := `^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`
-	matched, _ := regexp.MatchString(regex, value)
-	if !matched {
+	if !emailRegex.MatchString(value) {
 		return errors.New("invalid email format")
 	}
 	return nil
@@ -0,0 +1,241 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Validator valida datos que no vienen de un *http.Request (argumentos de la cli, mensajes de una cola,
+// archivos de configuracion) con la misma sintaxis de reglas del tag rules
+//
+//	v := validation.New(payload, map[string]string{
+//		"email":        "required|email",
+//		"items.*.qty":  "required|min:1",
+//	}, map[string]string{
+//		"email.required": "el correo es obligatorio",
+//		"min":            ":attribute es muy pequeño",
+//	})
+//	if v.Fails() {
+//		log.Println(v.Errors())
+//	}
+//
+// los nombres usan notacion de puntos y * recorre todos los elementos de un array u objeto
+// los mensajes se buscan por "campo.regla" (o "items.*.qty.min") y luego por "regla", :attribute se reemplaza por el nombre del campo
+type Validator struct {
+	ctx      context.Context
+	data     map[string]any
+	rules    map[string]string
+	messages map[string]string
+
+	ran   bool
+	err   error
+	errs  ValidationErrors
+	codes map[string][]string
+}
+
+// New crea el validador, las reglas se ejecutan la primera vez que se pide el resultado
+func New(data map[string]any, rules map[string]string, messages map[string]string) *Validator {
+	return &Validator{ctx: context.Background(), data: data, rules: rules, messages: messages}
+}
+
+// WithContext cambia el contexto que reciben las reglas en Field.Context
+func (v *Validator) WithContext(ctx context.Context) *Validator {
+	v.ctx = ctx
+	v.ran = false
+	return v
+}
+
+// Passes indica si los datos son validos
+func (v *Validator) Passes() bool {
+	return v.Err() == nil
+}
+
+// Fails indica si los datos no son validos
+func (v *Validator) Fails() bool {
+	return !v.Passes()
+}
+
+// Errors retorna los errores por campo, nil si los datos son validos
+func (v *Validator) Errors() ValidationErrors {
+	v.run()
+	return v.errs
+}
+
+// Codes retorna los codigos de los errores por campo en el orden de los mensajes, nil si los datos son validos
+func (v *Validator) Codes() map[string][]string {
+	v.run()
+	return v.codes
+}
+
+// Err retorna los errores como *CodedErrors (errors.As con ValidationErrors funciona) o el error grave de una regla
+// (HardError, contexto cancelado)
+func (v *Validator) Err() error {
+	v.run()
+	if v.err != nil {
+		return v.err
+	}
+	if len(v.errs) > 0 {
+		return &CodedErrors{Errors: v.errs, Codes: v.codes}
+	}
+	return nil
+}
+
+// Validated retorna solo los campos que tienen reglas con la misma forma de los datos
+// lo que el cliente envio sin estar en las reglas y los campos excluidos (exclude_if) no se incluyen
+func (v *Validator) Validated() (map[string]any, error) {
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+	out := make(map[string]any)
+	for _, fr := range v.fieldRules() {
+		if fr.exclude && isExcluded(v.data, fr.rules) {
+			continue
+		}
+		copyPath(out, v.data, strings.Split(fr.name, "."))
+	}
+	return out, nil
+}
+
+// run ejecuta las reglas una sola vez
+func (v *Validator) run() {
+	if v.ran {
+		return
+	}
+	v.ran = true
+	v.err, v.errs, v.codes = nil, nil, nil
+
+	err := validateWith(v.ctx, v.data, v.fieldRules(), func(field string) bool { return keyExists(v.data, field) }, v.messages)
+	var errs ValidationErrors
+	if errors.As(err, &errs) {
+		v.errs, v.codes = errs, FieldCodes(err)
+	} else {
+		v.err = err
+	}
+}
+
+// fieldRules interpreta las reglas y expande los * con los datos
+func (v *Validator) fieldRules() []fieldRules {
+	names := make([]string, 0, len(v.rules))
+	for name := range v.rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var fields []fieldRules
+	for _, name := range names {
+		rules := ParseRules(v.rules[name])
+		for _, expanded := range expandWildcards(v.data, name) {
+			fields = append(fields, fieldRules{name: expanded, rules: rules, sometimes: hasSometimes(rules), sensitive: hasSensitiveRule(rules), pattern: name, exclude: hasExclude(rules), normalize: hasNormalizer(rules)})
+		}
+	}
+	return fields
+}
+
+// expandWildcards reemplaza cada * por los indices o llaves que existen en los datos
+// items.*.name con dos items -> items.0.name, items.1.name
+func expandWildcards(data map[string]any, name string) []string {
+	if !strings.Contains(name, "*") {
+		return []string{name}
+	}
+	prefixes := []string{""}
+	for _, segment := range strings.Split(name, ".") {
+		var next []string
+		for _, prefix := range prefixes {
+			if segment != "*" {
+				next = append(next, prefix+segment+".")
+				continue
+			}
+			var container any = data
+			if prefix != "" {
+				container = lookup(data, strings.TrimSuffix(prefix, "."))
+			}
+			for _, key := range childKeys(container) {
+				next = append(next, prefix+key+".")
+			}
+		}
+		prefixes = next
+	}
+	for i := range prefixes {
+		prefixes[i] = strings.TrimSuffix(prefixes[i], ".")
+	}
+	return prefixes
+}
+
+// childKeys retorna los indices de un array o las llaves ordenadas de un objeto
+func childKeys(container any) []string {
+	rv := reflect.ValueOf(container)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		keys := make([]string, rv.Len())
+		for i := range keys {
+			keys[i] = strconv.Itoa(i)
+		}
+		return keys
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		keys := make([]string, 0, rv.Len())
+		for _, k := range rv.MapKeys() {
+			keys = append(keys, k.String())
+		}
+		sort.Strings(keys)
+		return keys
+	}
+	return nil
+}
+
+// copyPath copia el valor de la ruta de src a dst creando los objetos y arrays intermedios
+func copyPath(dst any, src any, path []string) any {
+	key := path[0]
+	value, ok := child(src, key)
+	if !ok {
+		return dst
+	}
+	if len(path) > 1 {
+		inner, _ := child(dst, key)
+		if inner == nil {
+			switch v := value.(type) {
+			case map[string]any:
+				inner = make(map[string]any, len(v))
+			case []any:
+				inner = make([]any, len(v))
+			}
+		}
+		// los tipos que no son de un json (map[string]string) se copian completos
+		if inner != nil {
+			value = copyPath(inner, value, path[1:])
+		}
+	}
+	switch d := dst.(type) {
+	case map[string]any:
+		d[key] = value
+	case []any:
+		if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(d) {
+			d[i] = value
+		}
+	}
+	return dst
+}
+
+// customMessage busca el mensaje de la regla: "campo.regla", "patron.regla" (items.*.name.required) y luego "regla"
+func (f *Field) customMessage(rule string) (string, bool) {
+	if len(f.messages) == 0 {
+		return "", false
+	}
+	message, ok := f.messages[f.Name+"."+rule]
+	if !ok && f.pattern != "" {
+		message, ok = f.messages[f.pattern+"."+rule]
+	}
+	if !ok {
+		message, ok = f.messages[rule]
+	}
+	if !ok {
+		return "", false
+	}
+	return strings.ReplaceAll(message, ":attribute", f.Name), true
+}
@@ -0,0 +1,39 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Warmup calcula por adelantado la metadata y las reglas de los structs
+// asi el primer request de cada endpoint no paga el costo de analizar el struct
+// ademas verifica que todas las reglas existan, un error de escritura en el tag se detecta al iniciar y no en produccion
+// las reglas personalizadas se deben registrar con RegisterRule antes de llamarla
+func Warmup(values ...any) error {
+	var unknown []string
+	for _, v := range values {
+		t := reflect.TypeOf(v)
+		for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice) {
+			// de los slices (ver Slice) se prepara el struct de los elementos
+			t = t.Elem()
+		}
+		if t == nil || t.Kind() != reflect.Struct {
+			return fmt.Errorf("warmup: se espera un struct o un slice de structs y se recibió %T", v)
+		}
+
+		meta := getStructMeta(t)
+		for _, fr := range append(meta.rules[:len(meta.rules):len(meta.rules)], meta.warnings...) {
+			for _, rule := range fr.rules {
+				if _, ok := ruleFuncs[rule.Name]; !ok {
+					unknown = append(unknown, fmt.Sprintf("%s.%s: %s", t.Name(), fr.name, rule.Name))
+				}
+			}
+		}
+	}
+
+	if len(unknown) > 0 {
+		return fmt.Errorf("warmup: reglas que no existen:\n%s", strings.Join(unknown, "\n"))
+	}
+	return nil
+}
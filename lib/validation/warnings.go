@@ -0,0 +1,130 @@
+package validation
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// Advertencias: reglas que no hacen fallar la validacion, sus mensajes se devuelven aparte para avisar al cliente
+// de cambios que vienen ("el campo será obligatorio") o de datos de baja calidad
+//
+//	type CreateCustomer struct {
+//		Email string `json:"email" rules:"required|email"`
+//		Phone string `json:"phone" warn:"required" warn_message:"el teléfono será obligatorio desde la versión 3"`
+//		Bio   string `json:"bio" warn:"min:20"`
+//	}
+//
+//	ctx = validation.WithWarnings(ctx)
+//	err := validation.StructContext(ctx, &customer)
+//	warnings := validation.Warnings(ctx) // {"phone": ["el teléfono será obligatorio desde la versión 3"]}
+//
+// sin WithWarnings las reglas del tag warn no se ejecutan
+
+// warningsKey llave del contexto con las advertencias
+type warningsKey struct{}
+
+// warnings advertencias de una validacion, prefix se antepone a los campos (elementos de un slice)
+type warnings struct {
+	mu     *sync.Mutex
+	errs   *ValidationErrors
+	prefix string
+}
+
+// WithWarnings prepara el contexto para recibir las advertencias de las validaciones que lo usen
+func WithWarnings(ctx context.Context) context.Context {
+	var errs ValidationErrors
+	return context.WithValue(ctx, warningsKey{}, warnings{mu: &sync.Mutex{}, errs: &errs})
+}
+
+// Warnings retorna las advertencias de las validaciones que usaron el contexto, nil si no hubo
+func Warnings(ctx context.Context) ValidationErrors {
+	w, ok := ctx.Value(warningsKey{}).(warnings)
+	if !ok {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return *w.errs
+}
+
+// HasWarnings indica si el struct (o los elementos del slice) tiene reglas de advertencia
+func HasWarnings(v any) bool {
+	t := reflect.TypeOf(v)
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return false
+	}
+	return len(getStructMeta(t).warnings) > 0
+}
+
+// withWarningPrefix las advertencias del contexto se guardan con el prefijo (2. para el elemento 2 de un slice)
+func withWarningPrefix(ctx context.Context, prefix string) context.Context {
+	w, ok := ctx.Value(warningsKey{}).(warnings)
+	if !ok {
+		return ctx
+	}
+	w.prefix += prefix
+	return context.WithValue(ctx, warningsKey{}, w)
+}
+
+// collectWarnings ejecuta las reglas de advertencia y guarda los mensajes en el contexto
+// no hace nada si no hay reglas o si el contexto no se preparo con WithWarnings
+func collectWarnings(ctx context.Context, data map[string]any, fields []fieldRules, present func(string) bool) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	w, ok := ctx.Value(warningsKey{}).(warnings)
+	if !ok {
+		return nil
+	}
+	only := Only(ctx)
+	var found ValidationErrors
+	var err error
+	f := &Field{Data: data, Context: ctx, present: present}
+	for _, fr := range fields {
+		if !selected(only, fr.name) {
+			continue
+		}
+		f.Name = fr.name
+		f.Value = lookup(data, fr.name)
+		f.sensitive = fr.sensitive
+		var fieldWarns ValidationErrors
+		if fieldWarns, err = apply(nil, f, fr.rules); err != nil {
+			return err
+		}
+		for field, msgs := range fieldWarns {
+			// warn_message reemplaza los mensajes de las reglas
+			if fr.message != "" {
+				msgs = []string{fr.message}
+			}
+			for _, msg := range msgs {
+				found = addError(found, w.prefix+field, msg)
+			}
+		}
+	}
+	if len(found) == 0 {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for field, msgs := range found {
+		for _, msg := range msgs {
+			*w.errs = addError(*w.errs, field, msg)
+		}
+	}
+	return nil
+}
+
+// addWarning agrega una advertencia al campo desde una regla, no hace nada sin WithWarnings
+func addWarning(ctx context.Context, field string, message string) {
+	w, ok := ctx.Value(warningsKey{}).(warnings)
+	if !ok {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	*w.errs = addError(*w.errs, w.prefix+field, message)
+}